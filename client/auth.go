@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Introspection is the RFC 7662 introspection response of a token.
+// Only Active is set for the expired, revoked or invalid tokens.
+type Introspection struct {
+	Active   bool   `json:"active"`
+	Scope    string `json:"scope"`
+	Subject  string `json:"sub"`
+	Username string `json:"username"`
+	Expiry   int64  `json:"exp"`
+	IssuedAt int64  `json:"iat"`
+	Issuer   string `json:"iss"`
+	Audience string `json:"aud"`
+}
+
+// IntrospectToken checks whether the id/access token is active by the client credentials of the introspection
+func (c *Client) IntrospectToken(ctx context.Context, clientID, clientSecret, token string) (Introspection, error) {
+	var introspection Introspection
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/auth/introspect", nil, strings.NewReader(url.Values{"token": []string{token}}.Encode()))
+	if err != nil {
+		return introspection, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(clientID, clientSecret)
+
+	code, body, err := c.send(req)
+	if err != nil {
+		return introspection, err
+	}
+
+	// the introspection responds in RFC 7662 format rather than jsend
+	if code != http.StatusOK {
+		var resp struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(body, &resp)
+		return introspection, &Error{StatusCode: code, Status: statusFail, Message: resp.Error}
+	}
+
+	if err = json.Unmarshal(body, &introspection); err != nil {
+		return introspection, errors.Wrap(err, fmt.Sprintf("cannot decode introspection: %s", string(body)))
+	}
+	return introspection, nil
+}
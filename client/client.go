@@ -0,0 +1,179 @@
+// Package client provides a typed client consuming the endpoints of go-api.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	statusSuccess = "success"
+	statusFail    = "fail"
+	statusError   = "error"
+)
+
+// Option configures the Client
+type Option func(*Client)
+
+// WithHTTPClient makes the Client send the requests by the custom http client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAccessToken makes the Client carry the token in `Authorization` header of the requests
+func WithAccessToken(token string) Option {
+	return func(c *Client) {
+		c.accessToken = token
+	}
+}
+
+// Client sends the requests to go-api under the base URL, e.g. `https://go-api.twreporter.org`
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	accessToken string
+}
+
+// New returns a Client of go-api under baseURL
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Meta is the pagination of the records in the response
+type Meta struct {
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// ListOptions paginates and sorts the records, the zero values are left to the server defaults
+type ListOptions struct {
+	Offset int
+	Limit  int
+	// Sort is the field sorted by, e.g. `-published_date` for descending order
+	Sort string
+}
+
+func (o ListOptions) values() url.Values {
+	v := url.Values{}
+	if o.Offset > 0 {
+		v.Set("offset", fmt.Sprint(o.Offset))
+	}
+	if o.Limit > 0 {
+		v.Set("limit", fmt.Sprint(o.Limit))
+	}
+	if o.Sort != "" {
+		v.Set("sort", o.Sort)
+	}
+	return v
+}
+
+// Error is returned if go-api responds `fail` or `error`, or a non-2xx status code
+type Error struct {
+	StatusCode int
+	// Status is `fail` for the invalid requests, and `error` for the server errors
+	Status  string
+	Message string
+	// Data explains the fields failed, e.g. `{"slug": "Cannot find the topic from the slug"}`
+	Data map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("go-api responds %d(%s): %s", e.StatusCode, e.Status, e.Message)
+	}
+	return fmt.Sprintf("go-api responds %d(%s): %v", e.StatusCode, e.Status, e.Data)
+}
+
+// IsNotFound reports whether the err is a 404 responded by go-api
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == http.StatusNotFound
+}
+
+// envelope is the jsend response of go-api
+type envelope struct {
+	Status  string          `json:"status"`
+	Data    json.RawMessage `json:"data"`
+	Message string          `json:"message"`
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("cannot create request(%s %s)", method, u))
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if c.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	}
+	return req, nil
+}
+
+// send sends the request and returns the response body
+func (c *Client) send(req *http.Request) (int, []byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, fmt.Sprintf("cannot send request(%s %s)", req.Method, req.URL))
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, errors.Wrap(err, fmt.Sprintf("cannot read response of request(%s %s)", req.Method, req.URL))
+	}
+	return resp.StatusCode, body, nil
+}
+
+// do sends the request and decodes the data of the jsend response into v
+func (c *Client) do(req *http.Request, v interface{}) error {
+	code, body, err := c.send(req)
+	if err != nil {
+		return err
+	}
+
+	var env envelope
+	if err = json.Unmarshal(body, &env); err != nil {
+		return &Error{StatusCode: code, Status: statusError, Message: fmt.Sprintf("cannot decode response: %s", string(body))}
+	}
+
+	if code < http.StatusOK || code >= http.StatusMultipleChoices || env.Status != statusSuccess {
+		e := &Error{StatusCode: code, Status: env.Status, Message: env.Message}
+		if env.Status == statusFail {
+			json.Unmarshal(env.Data, &e.Data)
+		}
+		return e
+	}
+
+	if v == nil {
+		return nil
+	}
+	if err = json.Unmarshal(env.Data, v); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("cannot decode data of request(%s %s)", req.Method, req.URL))
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/controllers"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/internal/news"
+	"twreporter.org/go-api/utils"
+)
+
+type mockNewsV2Storage struct {
+	topics []news.Topic
+	err    error
+}
+
+func (m *mockNewsV2Storage) GetFullPosts(ctx context.Context, q *news.Query) ([]news.Post, error) {
+	return nil, m.err
+}
+
+func (m *mockNewsV2Storage) GetMetaOfPosts(ctx context.Context, q *news.Query) ([]news.MetaOfPost, error) {
+	return nil, m.err
+}
+
+func (m *mockNewsV2Storage) GetFullTopics(ctx context.Context, q *news.Query) ([]news.Topic, error) {
+	var topics = make([]news.Topic, 0)
+	for _, topic := range m.topics {
+		if topic.Slug == q.Filter.Slug {
+			topic.Full = true
+			topics = append(topics, topic)
+		}
+	}
+	return topics, m.err
+}
+
+func (m *mockNewsV2Storage) GetMetaOfTopics(ctx context.Context, q *news.Query) ([]news.MetaOfTopic, error) {
+	var topics = make([]news.MetaOfTopic, 0)
+	for _, topic := range m.topics {
+		if q.Filter.Slug == "" || topic.Slug == q.Filter.Slug {
+			topics = append(topics, topic.MetaOfTopic)
+		}
+	}
+
+	if q.Filter.Slug != "" {
+		return topics, m.err
+	}
+
+	if q.Offset >= len(topics) {
+		return []news.MetaOfTopic{}, m.err
+	}
+	end := q.Offset + q.Limit
+	if end > len(topics) {
+		end = len(topics)
+	}
+	return topics[q.Offset:end], m.err
+}
+
+func (m *mockNewsV2Storage) GetPostCount(ctx context.Context, q *news.Query) (int, error) {
+	return 0, m.err
+}
+
+func (m *mockNewsV2Storage) GetTopicCount(ctx context.Context, q *news.Query) (int, error) {
+	return len(m.topics), m.err
+}
+
+// newTestServer serves the handlers of the topics and the introspection
+func newTestServer(s *mockNewsV2Storage) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+
+	nc := controllers.NewNewsV2Controller(s)
+	engine.GET("/v2/topics", nc.GetTopics)
+	engine.GET("/v2/topics/:slug", nc.GetATopic)
+
+	mc := controllers.NewMembershipController(nil)
+	engine.POST("/v1/auth/introspect", func(c *gin.Context) {
+		code, body, _ := mc.IntrospectToken(c)
+		c.JSON(code, body)
+	})
+	return httptest.NewServer(engine)
+}
+
+func helperSetConf() func() {
+	defaultConf := globals.Conf
+	globals.Conf.News.TopicPageTimeout = 5 * time.Second
+	globals.Conf.App.JwtSecret = "test-secret"
+	globals.Conf.App.JwtIssuer = "test-issuer"
+	globals.Conf.App.JwtAudience = "test-audience"
+	globals.Conf.App.IntrospectionClientID = "test-client"
+	globals.Conf.App.IntrospectionClientSecret = "test-client-secret"
+
+	return func() {
+		globals.Conf = defaultConf
+	}
+}
+
+func TestGetTopics(t *testing.T) {
+	defer helperSetConf()()
+
+	var topics []news.Topic
+	for _, slug := range []string{"topic-1", "topic-2", "topic-3"} {
+		var topic news.Topic
+		topic.Slug = slug
+		topic.Headline = slug + " headline"
+		topics = append(topics, topic)
+	}
+
+	server := newTestServer(&mockNewsV2Storage{topics: topics})
+	defer server.Close()
+	c := New(server.URL+"/", WithHTTPClient(server.Client()))
+
+	t.Run("Given a page", func(t *testing.T) {
+		list, err := c.GetTopics(context.Background(), ListOptions{Offset: 1, Limit: 1})
+		if err != nil {
+			t.Fatalf("expect no error, but got %v", err)
+		}
+		if len(list.Records) != 1 || list.Records[0].Slug != "topic-2" {
+			t.Errorf("expect topic-2, but got %v", list.Records)
+		}
+		if list.Meta != (Meta{Total: 3, Offset: 1, Limit: 1}) {
+			t.Errorf("expect meta of the page, but got %v", list.Meta)
+		}
+	})
+
+	t.Run("Given a server error", func(t *testing.T) {
+		server := newTestServer(&mockNewsV2Storage{err: errors.New("mock error")})
+		defer server.Close()
+
+		_, err := New(server.URL).GetTopics(context.Background(), ListOptions{})
+		var e *Error
+		if !errors.As(err, &e) || e.StatusCode != http.StatusInternalServerError || e.Status != statusError || e.Message != "Unexpected error." {
+			t.Errorf("expect an Error of 500, but got %v", err)
+		}
+	})
+
+	t.Run("Given a canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := c.GetTopics(ctx, ListOptions{}); errors.Cause(err) == nil || errors.Cause(err) == err {
+			t.Errorf("expect an error wrapping the cancellation, but got %v", err)
+		}
+	})
+}
+
+func TestGetATopic(t *testing.T) {
+	defer helperSetConf()()
+
+	var topic news.Topic
+	topic.Slug = "topic-1"
+	topic.Headline = "topic-1 headline"
+
+	server := newTestServer(&mockNewsV2Storage{topics: []news.Topic{topic}})
+	defer server.Close()
+	c := New(server.URL, WithHTTPClient(server.Client()))
+
+	t.Run("Given the meta of a topic", func(t *testing.T) {
+		got, err := c.GetATopic(context.Background(), "topic-1", false)
+		if err != nil {
+			t.Fatalf("expect no error, but got %v", err)
+		}
+		if got.Slug != "topic-1" || got.Full || got.Headline != "" {
+			t.Errorf("expect the meta of topic-1, but got %v", got)
+		}
+	})
+
+	t.Run("Given the full topic", func(t *testing.T) {
+		got, err := c.GetATopic(context.Background(), "topic-1", true)
+		if err != nil {
+			t.Fatalf("expect no error, but got %v", err)
+		}
+		if got.Slug != "topic-1" || !got.Full || got.Headline != "topic-1 headline" {
+			t.Errorf("expect the full topic-1, but got %v", got)
+		}
+	})
+
+	t.Run("Given a topic not found", func(t *testing.T) {
+		_, err := c.GetATopic(context.Background(), "not-found", false)
+		if !IsNotFound(err) {
+			t.Fatalf("expect not found, but got %v", err)
+		}
+
+		e := err.(*Error)
+		if e.Status != statusFail || e.Data["slug"] != "Cannot find the topic from the slug" {
+			t.Errorf("expect the failed field, but got %v", e.Data)
+		}
+	})
+}
+
+func TestIntrospectToken(t *testing.T) {
+	defer helperSetConf()()
+
+	server := newTestServer(&mockNewsV2Storage{})
+	defer server.Close()
+	c := New(server.URL, WithHTTPClient(server.Client()))
+
+	idToken, _ := utils.RetrieveV2IDToken(1, "user@twreporter.org", "first", "last", 60)
+
+	t.Run("Given an active token", func(t *testing.T) {
+		got, err := c.IntrospectToken(context.Background(), "test-client", "test-client-secret", idToken)
+		if err != nil {
+			t.Fatalf("expect no error, but got %v", err)
+		}
+		if !got.Active || got.Subject != "1" || got.Username != "user@twreporter.org" || got.Scope != "id_token" || got.Expiry == 0 {
+			t.Errorf("expect the active token of user 1, but got %v", got)
+		}
+	})
+
+	t.Run("Given an invalid token", func(t *testing.T) {
+		got, err := c.IntrospectToken(context.Background(), "test-client", "test-client-secret", "invalid-token")
+		if err != nil {
+			t.Fatalf("expect no error, but got %v", err)
+		}
+		if got != (Introspection{}) {
+			t.Errorf("expect an inactive token, but got %v", got)
+		}
+	})
+
+	t.Run("Given invalid client credentials", func(t *testing.T) {
+		_, err := c.IntrospectToken(context.Background(), "test-client", "wrong-secret", idToken)
+		var e *Error
+		if !errors.As(err, &e) || e.StatusCode != http.StatusUnauthorized || e.Message != "invalid_client" {
+			t.Errorf("expect an Error of 401, but got %v", err)
+		}
+	})
+}
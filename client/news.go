@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"twreporter.org/go-api/internal/news"
+)
+
+// MetaOfTopic is the brief information of a topic
+type MetaOfTopic = news.MetaOfTopic
+
+// Topic is the full information of a topic
+type Topic = news.Topic
+
+// TopicList is a page of the topics
+type TopicList struct {
+	Records []MetaOfTopic `json:"records"`
+	Meta    Meta          `json:"meta"`
+}
+
+// GetTopics returns the topics paginated and sorted by opts
+func (c *Client) GetTopics(ctx context.Context, opts ListOptions) (TopicList, error) {
+	var list TopicList
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/v2/topics", opts.values(), nil)
+	if err != nil {
+		return list, err
+	}
+
+	err = c.do(req, &list)
+	return list, err
+}
+
+// GetATopic returns the topic of the slug, only the fields of MetaOfTopic are filled unless full is true.
+// IsNotFound reports true for the error if there is no topic of the slug.
+func (c *Client) GetATopic(ctx context.Context, slug string, full bool) (Topic, error) {
+	var topic Topic
+
+	var query url.Values
+	if full {
+		query = url.Values{"full": []string{"true"}}
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/v2/topics/"+url.PathEscape(slug), query, nil)
+	if err != nil {
+		return topic, err
+	}
+
+	err = c.do(req, &topic)
+	return topic, err
+}
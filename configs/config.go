@@ -23,9 +23,41 @@ app:
     port: '8080'
     domain: localhost
     jwt_secret: secret_token
+    jwt_secret_id: "" # random key ID of jwt_secret as the kid header of the HS256 tokens, the tokens carry no kid if empty
+    jwt_previous_secrets: {} # rotated HS256 secrets by their key IDs, which only verify the tokens signed before the rotation
     jwt_expiration: 604800
     jwt_issuer: 'http://testtest.twreporter.org:8080' # used for issuer claim
     jwt_audience: 'http://testtest.twreporter.org:8080' # used for audience claim
+    user_agent: 'twreporter-go-api/1.0' # used for outbound http requests
+    jwt_signing_method: HS256 # HS256 or RS256, only RS256 keys are published in JWKS
+    jwt_private_key_path: "" # PEM encoded RSA private key for RS256, required in production and staging, generated at startup in development
+    jwt_previous_private_key_paths: [] # rotated RS256 keys, oldest first, which only verify the tokens signed before the rotation
+    jwt_encryption_key: "" # base64 encoded 32 bytes key encrypting the id/access tokens by JWE(A256GCM), not encrypted if empty
+    jwt_previous_encryption_keys: [] # rotated encryption keys, which only decrypt the tokens issued before the rotation
+    introspection_client_id: "" # provide your own client ID for token introspection
+    introspection_client_secret: "" # provide your own client secret for token introspection
+    internal_client_id: "" # provide your own client ID for the internal services, e.g. the batch users lookup
+    internal_client_secret: "" # provide your own client secret for the internal services
+    trusted_proxies: [] # IPs or CIDRs of the proxies(load balancers) whose X-Forwarded-For header is trusted
+    geoip_database_path: "" # MaxMind GeoLite2 Country database(.mmdb) resolving the client countries, the geo-restriction is disabled if empty
+    request_log_min_latency: 0 # milliseconds, the requests completed faster are not logged
+    trailing_slash: redirect # redirect or rewrite the paths with trailing slash to the canonical routes
+    route_timeouts: # 504 is responded if the route takes longer, 0 means no timeout
+        search: 10s
+        export: 10m
+    max_sse_connections: 100 # maximum concurrent Server-Sent Events streams, 0 means unlimited
+    meta_numbers_as_strings: false # serialize the numbers of the pagination meta as strings, e.g. "total": "100"
+    v1_sunset: '2021-06-30' # when the v1 routes superseded by v2 are going to be removed, responded in Sunset header
+    log_settings:
+        oauth_sample_rate: 1 # log 1 in N successful oauth logins, the failures are always logged
+        deprecation_sample_rate: 100 # log 1 in N calls of the deprecated routes
+        redacted_headers: # the values of the headers are redacted in the request logs
+            - Authorization
+            - Cookie
+        redacted_params: # the values of the query params are redacted in the request logs
+            - token
+            - code
+            - access_token
 email:
     smtp:
         username: no-reply@t-reporters.org
@@ -48,17 +80,44 @@ db:
         password: test_membership
         address: 127.0.0.1
         port: '3306'
+        max_open_conns: 0 # 0 means unlimited
+        max_idle_conns: 2
     mongo:
         url: 'mongodb://localhost:27017/plate'
         dbname: plate
         timeout: 5
+        pool_limit: 4096 # maximum number of sockets per server
+        query_timeout: 10 # seconds, 0 means no timeout
+    redis:
+        address: "" # e.g. localhost:6379, empty means no cache
+        password: ""
+        db: 0
+    read_only_retry_after: 30 # seconds, Retry-After of the writes rejected by the read-only database
 oauth:
+    redirect_status: 307 # status redirecting back to the destination after authentication, 302, 303 or 307
+    allow_auto_register: true # create the users signing in by oauth for the first time, otherwise they are rejected
     facebook:
         id: "" # provide your own facebook oauth ID
         secret: "" # provide your own facebook oauth secret
+        scopes: # the scopes requested, which are all required to be granted
+            - public_profile
+            - email
     google:
         id: "" # provide your own ID
         secret: "" # provide your own secret
+    linkedin:
+        id: "" # provide your own linkedin oauth ID
+        secret: "" # provide your own linkedin oauth secret
+    apple:
+        client_id: "" # provide your own apple services ID
+        team_id: "" # provide your own apple developer team ID
+        key_id: "" # provide your own sign in with apple key ID
+        private_key_path: "" # provide the path of your own sign in with apple private key(.p8)
+    avatar: # the resized variants of facebook avatars stored in S3, disabled if bucket is empty
+        bucket: ""
+        aws_region: us-west-2
+        base_url: "" # the public URL serving the bucket, e.g. https://avatars.twreporter.org
+        download_timeout: 10s
 donation:
     card_secret_key: test_card_secret_key
     tappay_url: 'https://sandbox.tappaysdk.com/tpc/payment/pay-by-prime'
@@ -75,6 +134,14 @@ news:
     post_page_timeout: 5s
     topic_page_timeout: 5s
     index_page_timeout: 5s
+    trending_topics_window: 24h # default window of the trending topics
+    trending_topics_max_window: 168h # longest window of the trending topics
+    print_template_path: "" # template of the printer-friendly posts, empty means post-print.tmpl in the html template directory
+    moderator_emails: [] # notified of the content reports of the readers
+    push: # push notifications of the published posts to the mobile apps by FCM, disabled if credentials_path is empty
+        credentials_path: "" # the service account key(json) of the firebase project
+        project_id: "" # empty means the project of the service account
+        timeout: 10s
 `)
 
 type ConfYaml struct {
@@ -103,6 +170,54 @@ type AppConfig struct {
 	JwtExpiration int    `yaml:"jwt_expiration"`
 	JwtIssuer     string `yaml:"jwt_issuer"`
 	JwtAudience   string `yaml:"jwt_audience"`
+	UserAgent     string `yaml:"user_agent"`
+
+	JwtSecretID        string            `yaml:"jwt_secret_id"`
+	JwtPreviousSecrets map[string]string `yaml:"jwt_previous_secrets"`
+
+	JwtSigningMethod  string `yaml:"jwt_signing_method"`
+	JwtPrivateKeyPath string `yaml:"jwt_private_key_path"`
+
+	JwtPreviousPrivateKeyPaths []string `yaml:"jwt_previous_private_key_paths"`
+
+	JwtEncryptionKey          string   `yaml:"jwt_encryption_key"`
+	JwtPreviousEncryptionKeys []string `yaml:"jwt_previous_encryption_keys"`
+
+	IntrospectionClientID     string `yaml:"introspection_client_id"`
+	IntrospectionClientSecret string `yaml:"introspection_client_secret"`
+
+	InternalClientID     string `yaml:"internal_client_id"`
+	InternalClientSecret string `yaml:"internal_client_secret"`
+
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	GeoIPDatabasePath string `yaml:"geoip_database_path"`
+
+	RequestLogMinLatency int `yaml:"request_log_min_latency"`
+
+	TrailingSlash string `yaml:"trailing_slash"`
+
+	RouteTimeouts RouteTimeoutsConfig `yaml:"route_timeouts"`
+
+	MaxSSEConnections int `yaml:"max_sse_connections"`
+
+	MetaNumbersAsStrings bool `yaml:"meta_numbers_as_strings"`
+
+	V1Sunset time.Time `yaml:"v1_sunset"`
+
+	LogSettings LogSettingsConfig `yaml:"log_settings"`
+}
+
+type LogSettingsConfig struct {
+	OAuthSampleRate       int      `yaml:"oauth_sample_rate"`
+	DeprecationSampleRate int      `yaml:"deprecation_sample_rate"`
+	RedactedHeaders       []string `yaml:"redacted_headers"`
+	RedactedParams        []string `yaml:"redacted_params"`
+}
+
+type RouteTimeoutsConfig struct {
+	Search time.Duration `yaml:"search"`
+	Export time.Duration `yaml:"export"`
 }
 
 type EmailConfig struct {
@@ -131,6 +246,9 @@ type AmazonConfig struct {
 type DBConfig struct {
 	MySQL MySQLConfig `yaml:"mysql"`
 	Mongo MongoConfig `yaml:"mongo"`
+	Redis RedisConfig `yaml:"redis"`
+
+	ReadOnlyRetryAfter int `yaml:"read_only_retry_after"`
 }
 
 type MySQLConfig struct {
@@ -139,29 +257,67 @@ type MySQLConfig struct {
 	Password string `yaml:"password"`
 	Address  string `yaml:"address"`
 	Port     string `yaml:"port"`
+
+	MaxOpenConns int `yaml:"max_open_conns"`
+	MaxIdleConns int `yaml:"max_idle_conns"`
 }
 
 type MongoConfig struct {
 	URL     string `yaml:"url"`
 	DBname  string `yaml:"dbname"`
 	Timeout int    `yaml:"timeout"`
+
+	PoolLimit    int `yaml:"pool_limit"`
+	QueryTimeout int `yaml:"query_timeout"`
+}
+
+type RedisConfig struct {
+	Address  string `yaml:"address"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
 }
 
 type OauthConfig struct {
+	RedirectStatus    int  `yaml:"redirect_status"`
+	AllowAutoRegister bool `yaml:"allow_auto_register"`
+
 	Facebook FacebookConfig `yaml:"facebook"`
 	Google   GoogleConfig   `yaml:"google"`
+	LinkedIn LinkedInConfig `yaml:"linkedin"`
+	Apple    AppleConfig    `yaml:"apple"`
+	Avatar   AvatarConfig   `yaml:"avatar"`
 }
 
 type FacebookConfig struct {
+	ID     string   `yaml:"id"`
+	Secret string   `yaml:"secret"`
+	Scopes []string `yaml:"scopes"`
+}
+
+type GoogleConfig struct {
 	ID     string `yaml:"id"`
 	Secret string `yaml:"secret"`
 }
 
-type GoogleConfig struct {
+type LinkedInConfig struct {
 	ID     string `yaml:"id"`
 	Secret string `yaml:"secret"`
 }
 
+type AppleConfig struct {
+	ClientID       string `yaml:"client_id"`
+	TeamID         string `yaml:"team_id"`
+	KeyID          string `yaml:"key_id"`
+	PrivateKeyPath string `yaml:"private_key_path"`
+}
+
+type AvatarConfig struct {
+	Bucket          string        `yaml:"bucket"`
+	AwsRegion       string        `yaml:"aws_region"`
+	BaseURL         string        `yaml:"base_url"`
+	DownloadTimeout time.Duration `yaml:"download_timeout"`
+}
+
 type DonationConfig struct {
 	CardSecretKey          string `yaml:"card_secret_key"`
 	TapPayURL              string `yaml:"tappay_url"`
@@ -186,6 +342,21 @@ type NewsConfig struct {
 	PostPageTimeout  time.Duration `yaml:"post_page_timeout"`
 	TopicPageTimeout time.Duration `yaml:"topic_page_timeout"`
 	IndexPageTimeout time.Duration `yaml:"index_page_timeout"`
+
+	TrendingTopicsWindow    time.Duration `yaml:"trending_topics_window"`
+	TrendingTopicsMaxWindow time.Duration `yaml:"trending_topics_max_window"`
+
+	PrintTemplatePath string `yaml:"print_template_path"`
+
+	ModeratorEmails []string `yaml:"moderator_emails"`
+
+	Push PushConfig `yaml:"push"`
+}
+
+type PushConfig struct {
+	CredentialsPath string        `yaml:"credentials_path"`
+	ProjectID       string        `yaml:"project_id"`
+	Timeout         time.Duration `yaml:"timeout"`
 }
 
 func init() {
@@ -206,9 +377,33 @@ func buildConf() ConfYaml {
 	conf.App.Port = viper.GetString("app.port")
 	conf.App.Domain = viper.GetString("app.domain")
 	conf.App.JwtSecret = viper.GetString("app.jwt_secret")
+	conf.App.JwtSecretID = viper.GetString("app.jwt_secret_id")
+	conf.App.JwtPreviousSecrets = viper.GetStringMapString("app.jwt_previous_secrets")
 	conf.App.JwtExpiration = viper.GetInt("app.jwt_expiration")
 	conf.App.JwtAudience = viper.GetString("app.jwt_audience")
 	conf.App.JwtIssuer = viper.GetString("app.jwt_issuer")
+	conf.App.UserAgent = viper.GetString("app.user_agent")
+	conf.App.JwtSigningMethod = viper.GetString("app.jwt_signing_method")
+	conf.App.JwtPrivateKeyPath = viper.GetString("app.jwt_private_key_path")
+	conf.App.JwtPreviousPrivateKeyPaths = viper.GetStringSlice("app.jwt_previous_private_key_paths")
+	conf.App.JwtEncryptionKey = viper.GetString("app.jwt_encryption_key")
+	conf.App.JwtPreviousEncryptionKeys = viper.GetStringSlice("app.jwt_previous_encryption_keys")
+	conf.App.IntrospectionClientID = viper.GetString("app.introspection_client_id")
+	conf.App.IntrospectionClientSecret = viper.GetString("app.introspection_client_secret")
+	conf.App.InternalClientID = viper.GetString("app.internal_client_id")
+	conf.App.InternalClientSecret = viper.GetString("app.internal_client_secret")
+	conf.App.TrustedProxies = viper.GetStringSlice("app.trusted_proxies")
+	conf.App.RequestLogMinLatency = viper.GetInt("app.request_log_min_latency")
+	conf.App.TrailingSlash = viper.GetString("app.trailing_slash")
+	conf.App.RouteTimeouts.Search = viper.GetDuration("app.route_timeouts.search")
+	conf.App.RouteTimeouts.Export = viper.GetDuration("app.route_timeouts.export")
+	conf.App.MaxSSEConnections = viper.GetInt("app.max_sse_connections")
+	conf.App.MetaNumbersAsStrings = viper.GetBool("app.meta_numbers_as_strings")
+	conf.App.V1Sunset = viper.GetTime("app.v1_sunset")
+	conf.App.LogSettings.OAuthSampleRate = viper.GetInt("app.log_settings.oauth_sample_rate")
+	conf.App.LogSettings.DeprecationSampleRate = viper.GetInt("app.log_settings.deprecation_sample_rate")
+	conf.App.LogSettings.RedactedHeaders = viper.GetStringSlice("app.log_settings.redacted_headers")
+	conf.App.LogSettings.RedactedParams = viper.GetStringSlice("app.log_settings.redacted_params")
 
 	// Cors
 	conf.Cors.AllowOrigins = viper.GetStringSlice("cors.allow_origins")
@@ -219,11 +414,22 @@ func buildConf() ConfYaml {
 	conf.DB.MySQL.Address = viper.GetString("db.mysql.address")
 	conf.DB.MySQL.Port = viper.GetString("db.mysql.port")
 	conf.DB.MySQL.User = viper.GetString("db.mysql.user")
+	conf.DB.MySQL.MaxOpenConns = viper.GetInt("db.mysql.max_open_conns")
+	conf.DB.MySQL.MaxIdleConns = viper.GetInt("db.mysql.max_idle_conns")
 
 	// DB - Mongo
 	conf.DB.Mongo.DBname = viper.GetString("db.mongo.dbname")
 	conf.DB.Mongo.URL = viper.GetString("db.mongo.url")
 	conf.DB.Mongo.Timeout = viper.GetInt("db.mongo.timeout")
+	conf.DB.Mongo.PoolLimit = viper.GetInt("db.mongo.pool_limit")
+	conf.DB.Mongo.QueryTimeout = viper.GetInt("db.mongo.query_timeout")
+
+	// DB - Redis
+	conf.DB.Redis.Address = viper.GetString("db.redis.address")
+	conf.DB.Redis.Password = viper.GetString("db.redis.password")
+	conf.DB.Redis.DB = viper.GetInt("db.redis.db")
+
+	conf.DB.ReadOnlyRetryAfter = viper.GetInt("db.read_only_retry_after")
 
 	// Email - Amazon
 	conf.Email.Amazon.SenderAddress = viper.GetString("email.amazon.sender_address")
@@ -241,14 +447,35 @@ func buildConf() ConfYaml {
 	conf.Email.SMTP.FeedbackEmail = viper.GetString("email.smtp.feedback_email")
 	conf.Email.SMTP.FeedbackName = viper.GetString("email.smtp.feedback_name")
 
+	// Oauth
+	conf.Oauth.RedirectStatus = viper.GetInt("oauth.redirect_status")
+	conf.Oauth.AllowAutoRegister = viper.GetBool("oauth.allow_auto_register")
+
 	// Oauth - Facebook
 	conf.Oauth.Facebook.ID = viper.GetString("oauth.facebook.id")
 	conf.Oauth.Facebook.Secret = viper.GetString("oauth.facebook.secret")
+	conf.Oauth.Facebook.Scopes = viper.GetStringSlice("oauth.facebook.scopes")
 
 	// Oauth - Google
 	conf.Oauth.Google.ID = viper.GetString("oauth.google.id")
 	conf.Oauth.Google.Secret = viper.GetString("oauth.google.secret")
 
+	// Oauth - LinkedIn
+	conf.Oauth.LinkedIn.ID = viper.GetString("oauth.linkedin.id")
+	conf.Oauth.LinkedIn.Secret = viper.GetString("oauth.linkedin.secret")
+
+	// Oauth - Apple
+	conf.Oauth.Apple.ClientID = viper.GetString("oauth.apple.client_id")
+	conf.Oauth.Apple.TeamID = viper.GetString("oauth.apple.team_id")
+	conf.Oauth.Apple.KeyID = viper.GetString("oauth.apple.key_id")
+	conf.Oauth.Apple.PrivateKeyPath = viper.GetString("oauth.apple.private_key_path")
+
+	// Oauth - Avatar
+	conf.Oauth.Avatar.Bucket = viper.GetString("oauth.avatar.bucket")
+	conf.Oauth.Avatar.AwsRegion = viper.GetString("oauth.avatar.aws_region")
+	conf.Oauth.Avatar.BaseURL = viper.GetString("oauth.avatar.base_url")
+	conf.Oauth.Avatar.DownloadTimeout = viper.GetDuration("oauth.avatar.download_timeout")
+
 	// TapPay
 	conf.Donation.CardSecretKey = viper.GetString("donation.card_secret_key")
 	conf.Donation.TapPayURL = viper.GetString("donation.tappay_url")
@@ -268,6 +495,13 @@ func buildConf() ConfYaml {
 	conf.News.PostPageTimeout = viper.GetDuration("news.post_page_timeout")
 	conf.News.TopicPageTimeout = viper.GetDuration("news.topic_page_timeout")
 	conf.News.IndexPageTimeout = viper.GetDuration("news.index_page_timeout")
+	conf.News.TrendingTopicsWindow = viper.GetDuration("news.trending_topics_window")
+	conf.News.TrendingTopicsMaxWindow = viper.GetDuration("news.trending_topics_max_window")
+	conf.News.PrintTemplatePath = viper.GetString("news.print_template_path")
+	conf.News.ModeratorEmails = viper.GetStringSlice("news.moderator_emails")
+	conf.News.Push.CredentialsPath = viper.GetString("news.push.credentials_path")
+	conf.News.Push.ProjectID = viper.GetString("news.push.project_id")
+	conf.News.Push.Timeout = viper.GetDuration("news.push.timeout")
 	return conf
 }
 
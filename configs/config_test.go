@@ -2,8 +2,10 @@ package configs_test
 
 import (
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"twreporter.org/go-api/configs"
@@ -42,3 +44,95 @@ func TestLoadConf(t *testing.T) {
 		})
 	})
 }
+
+func TestValidate(t *testing.T) {
+	// the environment variables set by the other tests overwrite the default config
+	for _, env := range os.Environ() {
+		if key := strings.SplitN(env, "=", 2)[0]; strings.HasPrefix(key, "GOAPI_") {
+			value := os.Getenv(key)
+			os.Unsetenv(key)
+			defer os.Setenv(key, value)
+		}
+	}
+
+	cases := []struct {
+		name       string
+		modify     func(conf *configs.ConfYaml)
+		wantFields []string
+	}{
+		{
+			name:   "Given the default config",
+			modify: func(conf *configs.ConfYaml) {},
+		},
+		{
+			name: "Given the invalid fields",
+			modify: func(conf *configs.ConfYaml) {
+				conf.App.Port = "80a"
+				conf.App.JwtIssuer = "testtest.twreporter.org"
+				conf.DB.Mongo.URL = "http://localhost:27017/plate"
+				conf.DB.MySQL.Name = ""
+				conf.Oauth.RedirectStatus = 301
+			},
+			wantFields: []string{"app.port", "app.jwt_issuer", "db.mysql.name", "db.mongo.url", "oauth.redirect_status"},
+		},
+		{
+			name: "Given the previous secret of the current key ID",
+			modify: func(conf *configs.ConfYaml) {
+				conf.App.JwtSecretID = "current"
+				conf.App.JwtPreviousSecrets = map[string]string{"current": "previous-secret"}
+			},
+			wantFields: []string{"app.jwt_previous_secrets.current"},
+		},
+		{
+			name: "Given the default secrets in production",
+			modify: func(conf *configs.ConfYaml) {
+				conf.Environment = "production"
+			},
+			wantFields: []string{"app.jwt_secret", "oauth.facebook.id", "oauth.facebook.secret", "oauth.google.id", "oauth.google.secret"},
+		},
+		{
+			name: "Given the secrets in production",
+			modify: func(conf *configs.ConfYaml) {
+				conf.Environment = "production"
+				conf.App.JwtSecret = strings.Repeat("s", 32)
+				conf.Oauth.Facebook.ID, conf.Oauth.Facebook.Secret = "mock-id", "mock-secret"
+				conf.Oauth.Google.ID, conf.Oauth.Google.Secret = "mock-id", "mock-secret"
+			},
+		},
+		{
+			name: "Given RS256 without the private key in production",
+			modify: func(conf *configs.ConfYaml) {
+				conf.Environment = "production"
+				conf.App.JwtSecret = strings.Repeat("s", 32)
+				conf.App.JwtSigningMethod = "RS256"
+				conf.App.JwtPreviousPrivateKeyPaths = []string{""}
+				conf.Oauth.Facebook.ID, conf.Oauth.Facebook.Secret = "mock-id", "mock-secret"
+				conf.Oauth.Google.ID, conf.Oauth.Google.Secret = "mock-id", "mock-secret"
+			},
+			wantFields: []string{"app.jwt_private_key_path", "app.jwt_previous_private_key_paths[0]"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf, err := configs.LoadDefaultConf()
+			assert.Nil(t, err)
+			tc.modify(&conf)
+
+			err = conf.Validate()
+			if len(tc.wantFields) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+
+			validationErr, ok := errors.Cause(err).(*configs.ValidationError)
+			if !assert.True(t, ok, "expect ValidationError, but got %v", err) {
+				return
+			}
+			assert.Equal(t, len(tc.wantFields), len(validationErr.Fields), validationErr.Error())
+			for _, field := range tc.wantFields {
+				assert.Contains(t, validationErr.Error(), field+" ")
+			}
+		})
+	}
+}
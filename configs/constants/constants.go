@@ -9,6 +9,12 @@ const (
 	// Google ...
 	Google = "Google"
 
+	// LinkedIn ...
+	LinkedIn = "LinkedIn"
+
+	// Apple ...
+	Apple = "Apple"
+
 	/* Gender Types */
 
 	// GenderMale ...
@@ -0,0 +1,162 @@
+package configs
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// minJwtSecretLength is the minimum length of app.jwt_secret in production and staging
+const minJwtSecretLength = 32
+
+// ValidationError lists the invalid fields of the config, keyed in the yaml path, e.g. `app.port`
+type ValidationError struct {
+	Fields []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid config: %s", strings.Join(e.Fields, "; "))
+}
+
+type confValidator struct {
+	fields []string
+}
+
+func (v *confValidator) addf(key string, format string, args ...interface{}) {
+	v.fields = append(v.fields, fmt.Sprintf("%s %s", key, fmt.Sprintf(format, args...)))
+}
+
+func (v *confValidator) required(key, value string) {
+	if value == "" {
+		v.addf(key, "is required")
+	}
+}
+
+func (v *confValidator) url(key, value string, schemes ...string) {
+	if value == "" {
+		return
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		v.addf(key, "should be an absolute URL, but got %q", value)
+		return
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return
+		}
+	}
+	v.addf(key, "should be a %s URL, but got %q", strings.Join(schemes, " or "), value)
+}
+
+func (v *confValidator) port(key, value string) {
+	if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+		v.addf(key, "should be a port between 1 and 65535, but got %q", value)
+	}
+}
+
+func (v *confValidator) atLeast(key string, value int, min int) {
+	if value < min {
+		v.addf(key, "should be at least %d, but got %d", min, value)
+	}
+}
+
+func (v *confValidator) oneOf(key, value string, values ...string) {
+	for _, allowed := range values {
+		if value == allowed {
+			return
+		}
+	}
+	v.addf(key, "should be one of %s, but got %q", strings.Join(values, ", "), value)
+}
+
+// Validate checks the required fields are given, the URLs are valid and the numbers are in the reasonable ranges.
+// The secrets, i.e. app.jwt_secret, the RS256 key and the credentials of facebook and google oauth, are only required in production and staging,
+// so that the default config still works in development.
+// *ValidationError listing all the invalid fields is returned.
+func (conf ConfYaml) Validate() error {
+	v := &confValidator{}
+
+	v.oneOf("app.protocol", conf.App.Protocol, "http", "https")
+	v.required("app.host", conf.App.Host)
+	v.port("app.port", conf.App.Port)
+	v.required("app.domain", conf.App.Domain)
+	v.required("app.jwt_secret", conf.App.JwtSecret)
+	for id, secret := range conf.App.JwtPreviousSecrets {
+		v.required(fmt.Sprintf("app.jwt_previous_secrets.%s", id), secret)
+		if id == conf.App.JwtSecretID {
+			v.addf(fmt.Sprintf("app.jwt_previous_secrets.%s", id), "should not be the key ID of app.jwt_secret")
+		}
+	}
+	v.atLeast("app.jwt_expiration", conf.App.JwtExpiration, 1)
+	v.url("app.jwt_issuer", conf.App.JwtIssuer, "http", "https")
+	v.url("app.jwt_audience", conf.App.JwtAudience, "http", "https")
+	v.oneOf("app.jwt_signing_method", conf.App.JwtSigningMethod, "HS256", "RS256")
+	for i, path := range conf.App.JwtPreviousPrivateKeyPaths {
+		v.required(fmt.Sprintf("app.jwt_previous_private_key_paths[%d]", i), path)
+	}
+	v.oneOf("app.trailing_slash", conf.App.TrailingSlash, "redirect", "rewrite")
+	v.atLeast("app.request_log_min_latency", conf.App.RequestLogMinLatency, 0)
+	v.atLeast("app.max_sse_connections", conf.App.MaxSSEConnections, 0)
+	if conf.App.V1Sunset.IsZero() {
+		v.addf("app.v1_sunset", "should be a date, e.g. 2021-06-30")
+	}
+	v.atLeast("app.log_settings.oauth_sample_rate", conf.App.LogSettings.OAuthSampleRate, 1)
+	v.atLeast("app.log_settings.deprecation_sample_rate", conf.App.LogSettings.DeprecationSampleRate, 1)
+
+	v.required("db.mysql.name", conf.DB.MySQL.Name)
+	v.required("db.mysql.user", conf.DB.MySQL.User)
+	v.required("db.mysql.address", conf.DB.MySQL.Address)
+	v.port("db.mysql.port", conf.DB.MySQL.Port)
+	v.atLeast("db.mysql.max_open_conns", conf.DB.MySQL.MaxOpenConns, 0)
+	v.atLeast("db.mysql.max_idle_conns", conf.DB.MySQL.MaxIdleConns, 0)
+
+	v.required("db.mongo.url", conf.DB.Mongo.URL)
+	v.url("db.mongo.url", conf.DB.Mongo.URL, "mongodb", "mongodb+srv")
+	v.required("db.mongo.dbname", conf.DB.Mongo.DBname)
+	v.atLeast("db.mongo.timeout", conf.DB.Mongo.Timeout, 1)
+	v.atLeast("db.mongo.pool_limit", conf.DB.Mongo.PoolLimit, 0)
+	v.atLeast("db.mongo.query_timeout", conf.DB.Mongo.QueryTimeout, 0)
+	v.atLeast("db.redis.db", conf.DB.Redis.DB, 0)
+	v.atLeast("db.read_only_retry_after", conf.DB.ReadOnlyRetryAfter, 0)
+
+	switch conf.Oauth.RedirectStatus {
+	case 302, 303, 307:
+		// omit intentionally
+	default:
+		v.addf("oauth.redirect_status", "should be 302, 303 or 307, but got %d", conf.Oauth.RedirectStatus)
+	}
+	v.url("oauth.avatar.base_url", conf.Oauth.Avatar.BaseURL, "http", "https")
+
+	v.url("donation.tappay_url", conf.Donation.TapPayURL, "https")
+	v.url("donation.tappay_record_url", conf.Donation.TapPayRecordURL, "https")
+	v.url("donation.line_pay_product_image_url", conf.Donation.LinePayProductImageUrl, "http", "https")
+
+	if conf.News.TrendingTopicsWindow <= 0 || conf.News.TrendingTopicsWindow > conf.News.TrendingTopicsMaxWindow {
+		v.addf("news.trending_topics_window", "should be positive and not longer than news.trending_topics_max_window(%s), but got %s",
+			conf.News.TrendingTopicsMaxWindow, conf.News.TrendingTopicsWindow)
+	}
+
+	if conf.Environment == "production" || conf.Environment == "staging" {
+		if len(conf.App.JwtSecret) < minJwtSecretLength {
+			v.addf("app.jwt_secret", "should be at least %d characters", minJwtSecretLength)
+		}
+		v.required("oauth.facebook.id", conf.Oauth.Facebook.ID)
+		v.required("oauth.facebook.secret", conf.Oauth.Facebook.Secret)
+		v.required("oauth.google.id", conf.Oauth.Google.ID)
+		v.required("oauth.google.secret", conf.Oauth.Google.Secret)
+		// the generated key is not shared by the instances, and is lost on restart
+		if conf.App.JwtSigningMethod == "RS256" {
+			v.required("app.jwt_private_key_path", conf.App.JwtPrivateKeyPath)
+		}
+	}
+
+	if len(v.fields) > 0 {
+		return errors.WithStack(&ValidationError{Fields: v.fields})
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/auth0/go-jwt-middleware"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -212,6 +213,11 @@ func (mc *MembershipController) ActivateV2(c *gin.Context) {
 		return
 	}
 
+	if user.Blocked {
+		err = errors.New(fmt.Sprintf("user(id: %d) is blocked", user.ID))
+		return
+	}
+
 	// Create id token for jwt endpoint retrival
 	idToken, err := utils.RetrieveV2IDToken(user.ID, user.Email.ValueOrZero(), user.FirstName.ValueOrZero(), user.LastName.ValueOrZero(), idTokenExpiration)
 	if nil != err {
@@ -250,6 +256,10 @@ func (mc *MembershipController) TokenDispatch(c *gin.Context) (int, gin.H, error
 		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"req.Headers.Cookies.id_token": err.Error()}}, nil
 	}
 
+	if idToken, err = utils.DecryptToken(idToken); err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"req.Headers.Cookies.id_token": err.Error()}}, nil
+	}
+
 	if _, _, err = new(jwt.Parser).ParseUnverified(idToken, claims); err != nil {
 		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"req.Headers.Cookies.id_token": err.Error()}}, nil
 	}
@@ -259,6 +269,12 @@ func (mc *MembershipController) TokenDispatch(c *gin.Context) (int, gin.H, error
 		return http.StatusInternalServerError, gin.H{"status": "error", "message": "cannot get user data"}, err
 	}
 
+	if user.Blocked {
+		return http.StatusForbidden, gin.H{"status": "fail", "error": models.ErrCodeAccountBlocked, "data": gin.H{
+			"req.Headers.Cookies.id_token": "the user of the token is blocked",
+		}}, nil
+	}
+
 	accessToken, err = utils.RetrieveV2AccessToken(user.ID, user.Email.ValueOrZero(), acccessTokenExpiration)
 	if err != nil {
 		return http.StatusInternalServerError, gin.H{"status": "error", "message": "Error occurs during generating access_token JWT"}, err
@@ -269,7 +285,10 @@ func (mc *MembershipController) TokenDispatch(c *gin.Context) (int, gin.H, error
 	}}, nil
 }
 
-// TokenInvalidate deletes the id_token stored in the client side
+// TokenInvalidate deletes the id_token stored in the client side,
+// and revokes the id_token along with the access token of the Authorization header if any.
+// Note that the denylist of the revoked tokens is in the memory of each process,
+// the other instances still accept the tokens until they expire.
 func (mc *MembershipController) TokenInvalidate(c *gin.Context) {
 	const signInPage = "https://accounts.twreporter.org/signin"
 	var defaultDomain = globals.Conf.App.Domain
@@ -277,6 +296,13 @@ func (mc *MembershipController) TokenInvalidate(c *gin.Context) {
 	cookieName := "id_token"
 	invalidateExp := -1
 
+	if idToken, err := c.Cookie(cookieName); err == nil && idToken != "" {
+		utils.RevokeToken(idToken)
+	}
+	if accessToken, err := jwtmiddleware.FromAuthHeader(c.Request); err == nil && accessToken != "" {
+		utils.RevokeToken(accessToken)
+	}
+
 	destination := c.Query("destination")
 
 	if destination == "" {
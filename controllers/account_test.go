@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/utils"
+)
+
+func TestTokenInvalidate(t *testing.T) {
+	defer helperSetIntrospectionConf()()
+
+	idToken, _ := utils.RetrieveV2IDToken(3, "logout@twreporter.org", "first", "last", 60)
+	accessToken, _ := utils.RetrieveV2AccessToken(3, "logout@twreporter.org", 60)
+
+	// the tokens are cached once validated
+	for _, token := range []string{idToken, accessToken} {
+		if _, err := utils.ParseToken(token, jwt.MapClaims{}, globals.Conf.App.JwtSecret); err != nil {
+			t.Fatalf("expect the token valid before logout, but got %v", err)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	mc := NewMembershipController(nil)
+	engine.GET("/v2/logout", mc.TokenInvalidate)
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/logout?destination=https://www.twreporter.org", nil)
+	req.AddCookie(&http.Cookie{Name: "id_token", Value: idToken})
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp := httptest.NewRecorder()
+	engine.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusTemporaryRedirect {
+		t.Errorf("expect status %d, but got %d", http.StatusTemporaryRedirect, resp.Code)
+	}
+
+	for _, token := range []string{idToken, accessToken} {
+		if _, err := utils.ParseToken(token, jwt.MapClaims{}, globals.Conf.App.JwtSecret); errors.Cause(err) != utils.ErrTokenRevoked {
+			t.Errorf("expect the token revoked after logout, but got %v", err)
+		}
+	}
+}
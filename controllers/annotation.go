@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+// maxAnnotationNoteLength is the maximum number of characters of the annotation note
+const maxAnnotationNoteLength = 2000
+
+type annotationReqBody struct {
+	ParagraphIndex *int   `json:"paragraph_index" binding:"required"`
+	Note           string `json:"note" binding:"required"`
+}
+
+type annotationPatchReqBody struct {
+	ParagraphIndex *int    `json:"paragraph_index"`
+	Note           *string `json:"note"`
+}
+
+// NewAnnotationController returns an AnnotationController with the storage of posts and annotations
+func NewAnnotationController(ns postGetter, s storage.AnnotationStorage) *AnnotationController {
+	return &AnnotationController{NewsStorage: ns, Storage: s}
+}
+
+// AnnotationController manages the editorial notes of the paragraphs of the posts, e.g. the fact-checks
+type AnnotationController struct {
+	NewsStorage postGetter
+	Storage     storage.AnnotationStorage
+}
+
+// GetAnnotationsOfAPost returns the annotations of the published post in the order of the paragraphs.
+// They are responded apart from the post, so that the frontend overlays them on the content.
+func (ac *AnnotationController) GetAnnotationsOfAPost(c *gin.Context) (int, gin.H, error) {
+	slug := c.Param("slug")
+	post, err := ac.NewsStorage.GetPublishedPostBySlug(slug)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return postNotFoundResponse(slug)
+		}
+		return toResponse(err)
+	}
+
+	if failBody, available := checkPostCountry(c, slug, post.RestrictedCountries); !available {
+		return restrictedPostResponse(failBody)
+	}
+
+	annotations, err := ac.Storage.GetAnnotationsOfAPost(slug)
+	if err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": emptyIfNil(annotations)}}, nil
+}
+
+// CreateAnnotation annotates the paragraph of the post of `:slug` by the authenticated editor
+func (ac *AnnotationController) CreateAnnotation(c *gin.Context) (int, gin.H, error) {
+	var reqBody annotationReqBody
+
+	if failData, valid := bindRequestJSONBody(c, &reqBody); !valid {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	if failData, valid := validateAnnotationNote(reqBody.Note); !valid {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	slug := c.Param("slug")
+	post, err := ac.NewsStorage.GetPostBySlug(slug)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{"slug": fmt.Sprintf("cannot find the post(slug: %s)", slug)}}, nil
+		}
+		return toResponse(err)
+	}
+
+	if failData, valid := validateParagraphIndex(post, *reqBody.ParagraphIndex); !valid {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	authorID, _ := strconv.ParseUint(fmt.Sprint(c.Request.Context().Value(globals.AuthUserIDProperty)), 10, 64)
+
+	annotation, err := ac.Storage.CreateAnnotation(models.Annotation{
+		PostSlug:       slug,
+		ParagraphIndex: *reqBody.ParagraphIndex,
+		Note:           reqBody.Note,
+		AuthorID:       uint(authorID),
+	})
+	if err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusCreated, gin.H{"status": "success", "data": annotation}, nil
+}
+
+// UpdateAnnotation updates the note or moves the annotation of `:id` to another paragraph of the same post
+func (ac *AnnotationController) UpdateAnnotation(c *gin.Context) (int, gin.H, error) {
+	var reqBody annotationPatchReqBody
+
+	id := c.Param("id")
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil || (reqBody.Note == nil && reqBody.ParagraphIndex == nil) {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": "should be {\"note\": \"...\"} or {\"paragraph_index\": 0}",
+		}}, nil
+	}
+
+	fields := bson.M{}
+	if reqBody.Note != nil {
+		if failData, valid := validateAnnotationNote(*reqBody.Note); !valid {
+			return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+		}
+		fields["note"] = *reqBody.Note
+	}
+
+	if reqBody.ParagraphIndex != nil {
+		annotation, err := ac.Storage.GetAnnotation(id)
+		if err != nil {
+			return annotationResponse(err, id)
+		}
+
+		post, err := ac.NewsStorage.GetPostBySlug(annotation.PostSlug)
+		if err != nil {
+			return toResponse(err)
+		}
+
+		if failData, valid := validateParagraphIndex(post, *reqBody.ParagraphIndex); !valid {
+			return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+		}
+		fields["paragraphIndex"] = *reqBody.ParagraphIndex
+	}
+
+	annotation, err := ac.Storage.UpdateAnnotation(id, fields)
+	if err != nil {
+		return annotationResponse(err, id)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": annotation}, nil
+}
+
+// DeleteAnnotation removes the annotation of `:id`
+func (ac *AnnotationController) DeleteAnnotation(c *gin.Context) (int, gin.H, error) {
+	id := c.Param("id")
+
+	if err := ac.Storage.DeleteAnnotation(id); err != nil {
+		return annotationResponse(err, id)
+	}
+
+	return http.StatusNoContent, gin.H{}, nil
+}
+
+// annotationResponse responds 404 if the annotation is not found
+func annotationResponse(err error, id string) (int, gin.H, error) {
+	if storage.IsNotFound(err) {
+		return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{"id": fmt.Sprintf("cannot find the annotation(id: %s)", id)}}, nil
+	}
+	return toResponse(err)
+}
+
+// validateAnnotationNote checks the note is neither blank nor too long
+func validateAnnotationNote(note string) (gin.H, bool) {
+	if strings.TrimSpace(note) == "" {
+		return gin.H{"note": "cannot be empty"}, false
+	}
+	if utf8.RuneCountInString(note) > maxAnnotationNoteLength {
+		return gin.H{"note": fmt.Sprintf("should be at most %d characters", maxAnnotationNoteLength)}, false
+	}
+	return nil, true
+}
+
+// validateParagraphIndex checks the index refers to a paragraph of the content of the post
+func validateParagraphIndex(post models.Post, index int) (gin.H, bool) {
+	var paragraphs int
+	if post.Content != nil {
+		paragraphs = len(post.Content.APIData)
+	}
+
+	if paragraphs == 0 {
+		return gin.H{"paragraph_index": fmt.Sprintf("post(slug: %s) has no paragraphs to annotate", post.Slug)}, false
+	}
+	if index < 0 || index >= paragraphs {
+		return gin.H{"paragraph_index": fmt.Sprintf("should be between 0 and %d", paragraphs-1)}, false
+	}
+	return nil, true
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockAnnotationStorage struct {
+	annotations map[string]models.Annotation
+}
+
+func (s *mockAnnotationStorage) GetAnnotationsOfAPost(slug string) ([]models.Annotation, error) {
+	var annotations []models.Annotation
+	for _, annotation := range s.annotations {
+		if annotation.PostSlug == slug {
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations, nil
+}
+
+func (s *mockAnnotationStorage) GetAnnotation(id string) (models.Annotation, error) {
+	if annotation, ok := s.annotations[id]; ok {
+		return annotation, nil
+	}
+	return models.Annotation{}, errors.WithStack(storage.ErrMgoNotFound)
+}
+
+func (s *mockAnnotationStorage) CreateAnnotation(annotation models.Annotation) (models.Annotation, error) {
+	annotation.ID = bson.NewObjectId()
+	s.annotations[annotation.ID.Hex()] = annotation
+	return annotation, nil
+}
+
+func (s *mockAnnotationStorage) UpdateAnnotation(id string, fields bson.M) (models.Annotation, error) {
+	annotation, err := s.GetAnnotation(id)
+	if err != nil {
+		return annotation, err
+	}
+	if note, ok := fields["note"]; ok {
+		annotation.Note = note.(string)
+	}
+	if index, ok := fields["paragraphIndex"]; ok {
+		annotation.ParagraphIndex = index.(int)
+	}
+	s.annotations[id] = annotation
+	return annotation, nil
+}
+
+func (s *mockAnnotationStorage) DeleteAnnotation(id string) error {
+	if _, ok := s.annotations[id]; !ok {
+		return errors.WithStack(storage.ErrMgoNotFound)
+	}
+	delete(s.annotations, id)
+	return nil
+}
+
+// annotatedPosts are the posts of three paragraphs and without content
+var annotatedPosts = mockPostGetter{
+	"mock-post":       {Slug: "mock-post", Content: &models.ContentBody{APIData: []bson.M{{}, {}, {}}}},
+	"empty-post":      {Slug: "empty-post"},
+	"published-post":  {Slug: "published-post", State: "published"},
+	"restricted-post": {Slug: "restricted-post", State: "published", RestrictedCountries: []string{"CN"}},
+}
+
+func TestGetAnnotationsOfAPost(t *testing.T) {
+	s := &mockAnnotationStorage{annotations: map[string]models.Annotation{
+		"5edf118c3e631f0600198935": {PostSlug: "mock-post", Note: "draft note"},
+		"5edf118c3e631f0600198936": {PostSlug: "published-post", Note: "published note"},
+		"5edf118c3e631f0600198937": {PostSlug: "restricted-post", Note: "restricted note"},
+	}}
+	ac := NewAnnotationController(annotatedPosts, s)
+
+	cases := []struct {
+		name      string
+		slug      string
+		country   string
+		wantCode  int
+		wantCount int
+	}{
+		{name: "Given a published post", slug: "published-post", wantCode: http.StatusOK, wantCount: 1},
+		{name: "Given a draft", slug: "mock-post", wantCode: http.StatusNotFound},
+		{name: "Given a post restricted in the client country", slug: "restricted-post", country: "CN", wantCode: http.StatusUnavailableForLegalReasons},
+		{name: "Given a post restricted in the other countries", slug: "restricted-post", country: "TW", wantCode: http.StatusOK, wantCount: 1},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts/"+tc.slug+"/annotations", nil)
+			c.Params = gin.Params{{Key: "slug", Value: tc.slug}}
+			c.Set(globals.ClientCountryProperty, tc.country)
+
+			code, body, _ := ac.GetAnnotationsOfAPost(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			if records := body["data"].(gin.H)["records"].([]models.Annotation); len(records) != tc.wantCount {
+				t.Errorf("expect %d annotations, but got %d", tc.wantCount, len(records))
+			}
+		})
+	}
+}
+
+func TestCreateAnnotation(t *testing.T) {
+	cases := []struct {
+		name     string
+		slug     string
+		body     string
+		wantCode int
+	}{
+		{name: "Given a note of the first paragraph", slug: "mock-post", body: `{"paragraph_index":0,"note":"the figure is from 2019"}`, wantCode: http.StatusCreated},
+		{name: "Given a note of the last paragraph", slug: "mock-post", body: `{"paragraph_index":2,"note":"verified"}`, wantCode: http.StatusCreated},
+		{name: "Given no paragraph index", slug: "mock-post", body: `{"note":"verified"}`, wantCode: http.StatusBadRequest},
+		{name: "Given a paragraph out of range", slug: "mock-post", body: `{"paragraph_index":3,"note":"verified"}`, wantCode: http.StatusBadRequest},
+		{name: "Given a negative paragraph index", slug: "mock-post", body: `{"paragraph_index":-1,"note":"verified"}`, wantCode: http.StatusBadRequest},
+		{name: "Given a blank note", slug: "mock-post", body: `{"paragraph_index":0,"note":"  "}`, wantCode: http.StatusBadRequest},
+		{name: "Given the note too long", slug: "mock-post", body: `{"paragraph_index":0,"note":"` + strings.Repeat("長", maxAnnotationNoteLength+1) + `"}`, wantCode: http.StatusBadRequest},
+		{name: "Given a post without content", slug: "empty-post", body: `{"paragraph_index":0,"note":"verified"}`, wantCode: http.StatusBadRequest},
+		{name: "Given the post not found", slug: "unknown-post", body: `{"paragraph_index":0,"note":"verified"}`, wantCode: http.StatusNotFound},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockAnnotationStorage{annotations: map[string]models.Annotation{}}
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/posts/"+tc.slug+"/annotations", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), globals.AuthUserIDProperty, float64(7)))
+			c.Params = gin.Params{{Key: "slug", Value: tc.slug}}
+
+			code, body, _ := NewAnnotationController(annotatedPosts, s).CreateAnnotation(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d(%v)", tc.wantCode, code, body)
+			}
+			if code != http.StatusCreated {
+				return
+			}
+
+			annotation := body["data"].(models.Annotation)
+			if annotation.PostSlug != tc.slug || annotation.AuthorID != 7 {
+				t.Errorf("expect the annotation of %s by author 7, but got %+v", tc.slug, annotation)
+			}
+		})
+	}
+}
+
+func TestUpdateAnnotation(t *testing.T) {
+	const id = "5edf118c3e631f0600198935"
+
+	cases := []struct {
+		name      string
+		id        string
+		body      string
+		wantCode  int
+		wantNote  string
+		wantIndex int
+	}{
+		{name: "Given a new note", id: id, body: `{"note":"updated"}`, wantCode: http.StatusOK, wantNote: "updated", wantIndex: 1},
+		{name: "Given another paragraph", id: id, body: `{"paragraph_index":0}`, wantCode: http.StatusOK, wantNote: "verified", wantIndex: 0},
+		{name: "Given a paragraph out of range", id: id, body: `{"paragraph_index":5}`, wantCode: http.StatusBadRequest},
+		{name: "Given an empty body", id: id, body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "Given the annotation not found", id: "5edf118c3e631f0600198936", body: `{"note":"updated"}`, wantCode: http.StatusNotFound},
+		{name: "Given the annotation not found when moving", id: "5edf118c3e631f0600198936", body: `{"paragraph_index":0}`, wantCode: http.StatusNotFound},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockAnnotationStorage{annotations: map[string]models.Annotation{
+				id: {ID: bson.ObjectIdHex(id), PostSlug: "mock-post", ParagraphIndex: 1, Note: "verified"},
+			}}
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPatch, "/v1/admin/annotations/"+tc.id, strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: tc.id}}
+
+			code, body, _ := NewAnnotationController(annotatedPosts, s).UpdateAnnotation(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d(%v)", tc.wantCode, code, body)
+			}
+			if code != http.StatusOK {
+				return
+			}
+
+			annotation := body["data"].(models.Annotation)
+			if annotation.Note != tc.wantNote || annotation.ParagraphIndex != tc.wantIndex {
+				t.Errorf("expect note %q of paragraph %d, but got %+v", tc.wantNote, tc.wantIndex, annotation)
+			}
+		})
+	}
+}
+
+func TestDeleteAnnotation(t *testing.T) {
+	const id = "5edf118c3e631f0600198935"
+
+	s := &mockAnnotationStorage{annotations: map[string]models.Annotation{
+		id: {ID: bson.ObjectIdHex(id), PostSlug: "mock-post"},
+	}}
+	ac := NewAnnotationController(annotatedPosts, s)
+
+	gin.SetMode(gin.TestMode)
+	for _, wantCode := range []int{http.StatusNoContent, http.StatusNotFound} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodDelete, "/v1/admin/annotations/"+id, nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+
+		if code, _, _ := ac.DeleteAnnotation(c); code != wantCode {
+			t.Errorf("expect status %d, but got %d", wantCode, code)
+		}
+	}
+}
@@ -7,24 +7,24 @@ import (
 	"twreporter.org/go-api/models"
 )
 
+// authorListParams binds the list params of the authors
+var authorListParams = ListParamsBinder{
+	DefaultLimit: 20,
+	DefaultSort:  "updatedAt",
+	SortFields:   []string{"updatedAt", "name"},
+}
+
 // GetAuthors receive HTTP GET method request, and return the authors.
 // `limit`, `offset` and `sort` are the url query params,
 // which define the rule we retrieve authors from storage.
 func (nc *NewsController) GetAuthors(c *gin.Context) (int, gin.H, error) {
-	const defaultLimit = 20
-	const defaultSort = "updatedAt"
 	var authors []models.FullAuthor
 	var err error
 	var total int
 
-	_, _, limit, offset, sort, _ := nc.GetQueryParam(c)
-
-	if limit == 0 {
-		limit = defaultLimit
-	}
-
-	if sort == "" {
-		sort = defaultSort
+	err, _, limit, offset, sort, _ := nc.GetQueryParam(c, authorListParams)
+	if _, ok := err.(*ListParamError); ok {
+		return listParamsFailResponse(err)
 	}
 
 	authors, total, err = nc.Storage.GetFullAuthors(limit, offset, sort)
@@ -37,11 +37,7 @@ func (nc *NewsController) GetAuthors(c *gin.Context) (int, gin.H, error) {
 		"status": "success",
 		"data": gin.H{
 			"records": authors,
-			"meta": models.MetaOfResponse{
-				Total:  total,
-				Offset: offset,
-				Limit:  limit,
-			},
+			"meta":    newMetaOfResponse(total, offset, limit),
 		},
 	}, nil
 }
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -10,7 +11,75 @@ import (
 	"twreporter.org/go-api/models"
 )
 
-// GetBookmarksOfAUser given userID this func will list all the bookmarks belongs to the user
+// maxBookmarkImport is the maximum number of the slugs imported at once
+const maxBookmarkImport = 100
+
+// bookmarkExpandTopic expands the bookmarks with the topics of their slugs
+const bookmarkExpandTopic = "topic"
+
+const (
+	// maxBookmarkTitleLength and maxBookmarkDescLength are the sizes of the columns of the bookmark
+	maxBookmarkTitleLength = 100
+	maxBookmarkDescLength  = 250
+)
+
+type topicsBySlugsGetter interface {
+	GetMetaOfTopicsBySlugs([]string) ([]models.Topic, error)
+}
+
+type postsBySlugsGetter interface {
+	GetPublishedPostsBySlugs([]string) ([]models.Post, error)
+}
+
+// bookmarkWithTopic is the bookmark expanded with its topic
+type bookmarkWithTopic struct {
+	models.Bookmark
+	// Topic is null unless the bookmark is of a topic which still exists
+	Topic *models.Topic `json:"topic"`
+	// Dangling is true if the topic no longer exists, which is only listed if `include_dangling` is true
+	Dangling bool `json:"dangling,omitempty"`
+}
+
+// expandBookmarksWithTopics fetches the topics of the topic bookmarks at once and keeps the order of the bookmarks.
+// The other bookmarks are kept without topics, while the topic bookmarks whose topics no longer exist
+// are omitted unless includeDangling is true.
+func (mc *MembershipController) expandBookmarksWithTopics(bookmarks []models.Bookmark, includeDangling bool) ([]bookmarkWithTopic, error) {
+	var expanded = make([]bookmarkWithTopic, 0, len(bookmarks))
+
+	var slugs []string
+	for _, bookmark := range bookmarks {
+		if bookmark.Type == models.BookmarkTypeTopic {
+			slugs = append(slugs, bookmark.Slug)
+		}
+	}
+
+	topicsBySlug := make(map[string]*models.Topic, len(slugs))
+	if len(slugs) > 0 {
+		topics, err := mc.TopicStorage.GetMetaOfTopicsBySlugs(slugs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range topics {
+			topicsBySlug[topics[i].Slug] = &topics[i]
+		}
+	}
+
+	for _, bookmark := range bookmarks {
+		if bookmark.Type != models.BookmarkTypeTopic {
+			expanded = append(expanded, bookmarkWithTopic{Bookmark: bookmark})
+			continue
+		}
+		topic, ok := topicsBySlug[bookmark.Slug]
+		if !ok && !includeDangling {
+			continue
+		}
+		expanded = append(expanded, bookmarkWithTopic{Bookmark: bookmark, Topic: topic, Dangling: !ok})
+	}
+	return expanded, nil
+}
+
+// GetBookmarksOfAUser given userID this func will list all the bookmarks belongs to the user.
+// The topic bookmarks are expanded with the metadata of their topics if `expand=topic` is given.
 func (mc *MembershipController) GetBookmarksOfAUser(c *gin.Context) (int, gin.H, error) {
 	var err error
 	var bookmarks []models.Bookmark
@@ -41,10 +110,36 @@ func (mc *MembershipController) GetBookmarksOfAUser(c *gin.Context) (int, gin.H,
 		limit = 10
 	}
 
+	expand := c.Query("expand")
+	if expand != "" && expand != bookmarkExpandTopic {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"expand": fmt.Sprintf("should be %s", bookmarkExpandTopic),
+		}}, nil
+	}
+
+	var includeDangling bool
+	if value, ok := c.GetQuery("include_dangling"); ok {
+		if includeDangling, err = strconv.ParseBool(value); err != nil {
+			return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+				"include_dangling": "should be true or false",
+			}}, nil
+		}
+	}
+
 	if bookmarks, total, err = mc.Storage.GetBookmarksOfAUser(userID, limit, offset); err != nil {
 		return toResponse(err)
 	}
 
+	// the bookmarks are paginated before the expansion, so the page could be shorter than limit
+	// if the dangling topic bookmarks are omitted
+	if expand == bookmarkExpandTopic {
+		expanded, err := mc.expandBookmarksWithTopics(bookmarks, includeDangling)
+		if err != nil {
+			return toResponse(err)
+		}
+		return http.StatusOK, gin.H{"status": "ok", "records": expanded, "meta": newMetaOfResponse(total, offset, limit)}, nil
+	}
+
 	// TODO The response JSON should be like
 	//	{
 	//		"status": "success",
@@ -53,11 +148,7 @@ func (mc *MembershipController) GetBookmarksOfAUser(c *gin.Context) (int, gin.H,
 	//			"records": bookmarks
 	//		}
 	//	}
-	return http.StatusOK, gin.H{"status": "ok", "records": bookmarks, "meta": models.MetaOfResponse{
-		Total:  total,
-		Offset: offset,
-		Limit:  limit,
-	}}, nil
+	return http.StatusOK, gin.H{"status": "ok", "records": bookmarks, "meta": newMetaOfResponse(total, offset, limit)}, nil
 }
 
 // DeleteABookmarkOfAUser given userID and bookmarkHref, this func will remove the relationship between user and bookmark
@@ -72,6 +163,17 @@ func (mc *MembershipController) DeleteABookmarkOfAUser(c *gin.Context) (int, gin
 	return http.StatusNoContent, gin.H{}, nil
 }
 
+// DeleteBookmarksOfAUser given userID, this func will remove the relationships between user and all of the bookmarks
+func (mc *MembershipController) DeleteBookmarksOfAUser(c *gin.Context) (int, gin.H, error) {
+	userID := c.Param("userID")
+
+	if err := mc.Storage.DeleteBookmarksOfAUser(userID); err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusNoContent, gin.H{}, nil
+}
+
 // CreateABookmarkOfAUser given userID and bookmark POST body, this func will try to create bookmark record in the bookmarks table,
 // and build the relationship between bookmark and user
 func (mc *MembershipController) CreateABookmarkOfAUser(c *gin.Context) (int, gin.H, error) {
@@ -97,6 +199,95 @@ func (mc *MembershipController) CreateABookmarkOfAUser(c *gin.Context) (int, gin
 	return http.StatusCreated, gin.H{"status": "ok", "record": bookmark}, nil
 }
 
+// ImportBookmarksOfAUser given userID and the slugs of the published posts under the host in POST body,
+// this func will build the relationships between the user and the bookmarks of the posts at once,
+// and return whether each of the slugs is added, skipped or invalid.
+// The bookmarks of the posts not bookmarked by anyone yet are created from the posts,
+// while the slugs without published posts are invalid.
+func (mc *MembershipController) ImportBookmarksOfAUser(c *gin.Context) (int, gin.H, error) {
+	var body struct {
+		Host  string   `json:"host" form:"host" binding:"required"`
+		Slugs []string `json:"slugs" form:"slugs" binding:"required"`
+	}
+
+	if err := c.Bind(&body); err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": "host and slugs are required",
+		}}, nil
+	}
+
+	if len(body.Slugs) == 0 || len(body.Slugs) > maxBookmarkImport {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"slugs": fmt.Sprintf("should contain 1 to %d slugs", maxBookmarkImport),
+		}}, nil
+	}
+
+	posts, err := mc.PostStorage.GetPublishedPostsBySlugs(body.Slugs)
+	if err != nil {
+		return toResponse(err)
+	}
+
+	var postsBySlug = make(map[string]models.Post, len(posts))
+	for _, post := range posts {
+		postsBySlug[post.Slug] = post
+	}
+
+	var bookmarks []models.Bookmark
+	for _, slug := range body.Slugs {
+		if post, ok := postsBySlug[slug]; ok {
+			bookmarks = append(bookmarks, newBookmarkOfPost(body.Host, post))
+		}
+	}
+
+	imported, err := mc.Storage.ImportBookmarksOfAUser(c.Param("userID"), bookmarks)
+	if err != nil {
+		return toResponse(err)
+	}
+
+	// the imported results are in the order of the valid slugs
+	var results = make([]models.BookmarkImportResult, 0, len(body.Slugs))
+	for _, slug := range body.Slugs {
+		if _, ok := postsBySlug[slug]; !ok {
+			results = append(results, models.BookmarkImportResult{Slug: slug, Status: models.BookmarkImportInvalid})
+			continue
+		}
+		results = append(results, imported[0])
+		imported = imported[1:]
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": results}, nil
+}
+
+// newBookmarkOfPost returns the article bookmark of the post under the host,
+// which is created if no one has bookmarked the post yet
+func newBookmarkOfPost(host string, post models.Post) models.Bookmark {
+	bookmark := models.Bookmark{
+		Slug:  post.Slug,
+		Title: truncateRunes(post.Title, maxBookmarkTitleLength),
+		Desc:  truncateRunes(post.OgDescription, maxBookmarkDescLength),
+		Host:  host,
+		Type:  models.BookmarkTypeArticle,
+	}
+	if post.HeroImage != nil {
+		bookmark.Thumbnail = post.HeroImage.ResizedTargets.Mobile.URL
+		if bookmark.Thumbnail == "" {
+			bookmark.Thumbnail = post.HeroImage.URL
+		}
+	}
+	if !post.PublishedDate.IsZero() {
+		bookmark.PubDate = uint(post.PublishedDate.Unix())
+	}
+	return bookmark
+}
+
+// truncateRunes truncates s to at most n runes
+func truncateRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
 func (mc *MembershipController) parseBookmarkPOSTBody(c *gin.Context) (models.Bookmark, error) {
 	var bm models.Bookmark
 
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockBookmarkStorage struct {
+	storage.MembershipStorage
+	bookmarks []models.Bookmark
+}
+
+func (m *mockBookmarkStorage) GetBookmarksOfAUser(userID string, limit, offset int) ([]models.Bookmark, int, error) {
+	total := len(m.bookmarks)
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return m.bookmarks[offset:end], total, nil
+}
+
+type mockTopicsBySlugsGetter struct {
+	topics []models.Topic
+	calls  [][]string
+}
+
+func (m *mockTopicsBySlugsGetter) GetMetaOfTopicsBySlugs(slugs []string) ([]models.Topic, error) {
+	m.calls = append(m.calls, slugs)
+
+	var found []models.Topic
+	for _, topic := range m.topics {
+		for _, slug := range slugs {
+			if topic.Slug == slug {
+				found = append(found, topic)
+			}
+		}
+	}
+	return found, nil
+}
+
+func TestGetBookmarksOfAUserExpandTopic(t *testing.T) {
+	s := &mockBookmarkStorage{bookmarks: []models.Bookmark{
+		{ID: 1, Slug: "topic-a", Type: models.BookmarkTypeTopic},
+		{ID: 2, Slug: "removed-topic", Type: models.BookmarkTypeTopic},
+		{ID: 3, Slug: "an-article", Type: models.BookmarkTypeArticle},
+		{ID: 4, Slug: "topic-b", Type: models.BookmarkTypeTopic},
+		{ID: 5, Slug: "topic-c", Type: models.BookmarkTypeTopic},
+	}}
+
+	cases := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantIDs   []uint
+		wantMeta  models.MetaOfResponse
+		wantCalls int
+	}{
+		{
+			name:      "Given expand=topic",
+			query:     "expand=topic",
+			wantCode:  http.StatusOK,
+			wantIDs:   []uint{1, 3, 4, 5},
+			wantMeta:  models.MetaOfResponse{Total: 5, Offset: 0, Limit: 10},
+			wantCalls: 1,
+		},
+		{
+			name:      "Given expand=topic and include_dangling=true",
+			query:     "expand=topic&include_dangling=true",
+			wantCode:  http.StatusOK,
+			wantIDs:   []uint{1, 2, 3, 4, 5},
+			wantMeta:  models.MetaOfResponse{Total: 5, Offset: 0, Limit: 10},
+			wantCalls: 1,
+		},
+		{
+			name:      "Given expand=topic and pagination",
+			query:     "expand=topic&limit=2&offset=1",
+			wantCode:  http.StatusOK,
+			wantIDs:   []uint{3},
+			wantMeta:  models.MetaOfResponse{Total: 5, Offset: 1, Limit: 2},
+			wantCalls: 1,
+		},
+		{
+			name:     "Given expand=topic and a page without topic bookmarks",
+			query:    "expand=topic&limit=1&offset=2",
+			wantCode: http.StatusOK,
+			wantIDs:  []uint{3},
+			wantMeta: models.MetaOfResponse{Total: 5, Offset: 2, Limit: 1},
+		},
+		{name: "Given an unknown expand", query: "expand=author", wantCode: http.StatusBadRequest},
+		{name: "Given an invalid include_dangling", query: "expand=topic&include_dangling=maybe", wantCode: http.StatusBadRequest},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := &mockTopicsBySlugsGetter{topics: []models.Topic{
+				{Slug: "topic-c", Title: "Topic C"},
+				{Slug: "topic-a", Title: "Topic A"},
+				{Slug: "topic-b", Title: "Topic B"},
+			}}
+			mc := NewMembershipController(s)
+			mc.TopicStorage = ts
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/users/1/bookmarks?"+tc.query, nil)
+			c.Params = gin.Params{{Key: "userID", Value: "1"}}
+
+			code, body, _ := mc.GetBookmarksOfAUser(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			if len(ts.calls) != tc.wantCalls {
+				t.Fatalf("expect topics fetched in %d calls, but got %d calls", tc.wantCalls, len(ts.calls))
+			}
+
+			records := body["records"].([]bookmarkWithTopic)
+			if len(records) != len(tc.wantIDs) {
+				t.Fatalf("expect %d records, but got %d", len(tc.wantIDs), len(records))
+			}
+			for i, record := range records {
+				if record.ID != tc.wantIDs[i] {
+					t.Errorf("expect record %d to be bookmark %d, but got %d", i, tc.wantIDs[i], record.ID)
+				}
+				if record.Type != models.BookmarkTypeTopic {
+					if record.Topic != nil || record.Dangling {
+						t.Errorf("expect bookmark %d of type %s kept without the topic", record.ID, record.Type)
+					}
+					continue
+				}
+				if record.Dangling != (record.Topic == nil) {
+					t.Errorf("expect bookmark %d flagged dangling iff the topic is missing", record.ID)
+				}
+				if record.Topic != nil && record.Topic.Slug != record.Slug {
+					t.Errorf("expect bookmark %d joined with topic %s, but got %s", record.ID, record.Slug, record.Topic.Slug)
+				}
+			}
+
+			meta := body["meta"].(models.MetaOfResponse)
+			if meta.Total != tc.wantMeta.Total || meta.Offset != tc.wantMeta.Offset || meta.Limit != tc.wantMeta.Limit {
+				t.Errorf("expect meta %+v, but got %+v", tc.wantMeta, meta)
+			}
+		})
+	}
+}
+
+type mockBookmarkImportStorage struct {
+	storage.MembershipStorage
+	imported []models.Bookmark
+}
+
+func (m *mockBookmarkImportStorage) ImportBookmarksOfAUser(userID string, bookmarks []models.Bookmark) ([]models.BookmarkImportResult, error) {
+	m.imported = bookmarks
+
+	var results []models.BookmarkImportResult
+	for _, bookmark := range bookmarks {
+		results = append(results, models.BookmarkImportResult{Slug: bookmark.Slug, Status: models.BookmarkImportAdded})
+	}
+	return results, nil
+}
+
+type mockPostsBySlugsGetter struct {
+	posts []models.Post
+}
+
+func (m mockPostsBySlugsGetter) GetPublishedPostsBySlugs(slugs []string) ([]models.Post, error) {
+	return m.posts, nil
+}
+
+func TestImportBookmarksOfAUser(t *testing.T) {
+	publishedDate := time.Date(2020, time.June, 8, 16, 0, 0, 0, time.UTC)
+	s := &mockBookmarkImportStorage{}
+	mc := NewMembershipController(s)
+	mc.PostStorage = mockPostsBySlugsGetter{posts: []models.Post{
+		{
+			Slug:          "post-a",
+			Title:         "post a",
+			OgDescription: "description of post a",
+			HeroImage:     &models.Image{URL: "https://mockhost/origin.jpg", ResizedTargets: models.ResizedTargets{Mobile: models.ImageAsset{URL: "https://mockhost/mobile.jpg"}}},
+			PublishedDate: publishedDate,
+		},
+		{Slug: "post-b", Title: strings.Repeat("長", 120)},
+	}}
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Params = gin.Params{{Key: "userID", Value: "1"}}
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/users/1/bookmarks/import", strings.NewReader(`{"host":"mockhost","slugs":["post-b","unpublished-post","post-a"]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	code, body, _ := mc.ImportBookmarksOfAUser(c)
+	if code != http.StatusOK {
+		t.Fatalf("expect status %d, but got %d", http.StatusOK, code)
+	}
+
+	want := []models.BookmarkImportResult{
+		{Slug: "post-b", Status: models.BookmarkImportAdded},
+		{Slug: "unpublished-post", Status: models.BookmarkImportInvalid},
+		{Slug: "post-a", Status: models.BookmarkImportAdded},
+	}
+	if got := body["data"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expect results %v, but got %v", want, got)
+	}
+
+	if len(s.imported) != 2 {
+		t.Fatalf("expect the bookmarks of the published posts imported, but got %v", s.imported)
+	}
+	if got := s.imported[0]; len([]rune(got.Title)) != maxBookmarkTitleLength || got.Type != models.BookmarkTypeArticle {
+		t.Errorf("expect the article bookmark with the title truncated, but got %+v", got)
+	}
+	wantBookmark := models.Bookmark{
+		Slug:      "post-a",
+		Title:     "post a",
+		Desc:      "description of post a",
+		Host:      "mockhost",
+		Thumbnail: "https://mockhost/mobile.jpg",
+		PubDate:   uint(publishedDate.Unix()),
+		Type:      models.BookmarkTypeArticle,
+	}
+	if got := s.imported[1]; !reflect.DeepEqual(got, wantBookmark) {
+		t.Errorf("expect bookmark %+v, but got %+v", wantBookmark, got)
+	}
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"twreporter.org/go-api/globals"
+)
+
+// maxPurgeKeys is the maximum number of keys purged in a request
+const maxPurgeKeys = 100
+
+var (
+	// cacheKeyAllowlist restricts the purgeable keys to the listed posts cached by `storage.CachedNewsStorage`,
+	// e.g. `news:posts:meta:<hash>`
+	cacheKeyAllowlist = regexp.MustCompile(`^news:posts:[A-Za-z0-9_-]+(:[A-Za-z0-9_-]+)*$`)
+	// cachePatternAllowlist only allows a trailing wildcard in the namespace, e.g. `news:posts:*`,
+	// the other glob-style characters of Redis are rejected.
+	cachePatternAllowlist = regexp.MustCompile(`^news:posts:([A-Za-z0-9_-]+:)*[A-Za-z0-9_-]*\*$`)
+)
+
+type cachePurger interface {
+	DeleteKeys([]string) (int64, error)
+	DeleteKeysByPattern(string) (int64, error)
+}
+
+// NewCacheController ...
+// The storage is nil if the cache is not enabled.
+func NewCacheController(s cachePurger) *CacheController {
+	return &CacheController{Storage: s}
+}
+
+// CacheController purges the cached content after editors publish or update it
+type CacheController struct {
+	Storage cachePurger
+}
+
+type cachePurgeReqBody struct {
+	Keys    []string `json:"keys"`
+	Pattern string   `json:"pattern"`
+}
+
+// validateCachePurge returns the fail data of the request body, which is nil if the request body is valid
+func validateCachePurge(reqBody cachePurgeReqBody) gin.H {
+	if (len(reqBody.Keys) == 0) == (reqBody.Pattern == "") {
+		return gin.H{"req.Body": "should contain either keys or pattern"}
+	}
+
+	if reqBody.Pattern != "" {
+		if !cachePatternAllowlist.MatchString(reqBody.Pattern) {
+			return gin.H{"pattern": fmt.Sprintf("%s is not an allowed pattern", reqBody.Pattern)}
+		}
+		return nil
+	}
+
+	if len(reqBody.Keys) > maxPurgeKeys {
+		return gin.H{"keys": fmt.Sprintf("should contain at most %d keys", maxPurgeKeys)}
+	}
+
+	for _, key := range reqBody.Keys {
+		if !cacheKeyAllowlist.MatchString(key) {
+			return gin.H{"keys": fmt.Sprintf("%s is not an allowed key", key)}
+		}
+	}
+	return nil
+}
+
+// PurgeCache deletes the cached keys, or the keys matching the pattern.
+// Every purge is logged with the admin requesting it.
+func (cc *CacheController) PurgeCache(c *gin.Context) (int, gin.H, error) {
+	var err error
+	var deleted int64
+	var reqBody cachePurgeReqBody
+
+	if cc.Storage == nil {
+		return http.StatusServiceUnavailable, gin.H{"status": "error", "message": "cache is not enabled"}, nil
+	}
+
+	if err = c.ShouldBindJSON(&reqBody); err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": fmt.Sprintf("should be {\"keys\": [...]} or {\"pattern\": \"...\"}. %s", err.Error()),
+		}}, nil
+	}
+
+	if failData := validateCachePurge(reqBody); failData != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	if reqBody.Pattern != "" {
+		deleted, err = cc.Storage.DeleteKeysByPattern(reqBody.Pattern)
+	} else {
+		deleted, err = cc.Storage.DeleteKeys(reqBody.Keys)
+	}
+
+	entry := log.WithFields(log.Fields{
+		"keys":    reqBody.Keys,
+		"pattern": reqBody.Pattern,
+		"deleted": deleted,
+		"user_id": c.Request.Context().Value(globals.AuthUserIDProperty),
+	})
+
+	if err != nil {
+		entry.Error("fail to purge cache")
+		return toResponse(err)
+	}
+
+	entry.Info("purge cache")
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{"deleted": deleted}}, nil
+}
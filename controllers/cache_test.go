@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockCachePurger struct {
+	keys    []string
+	pattern string
+}
+
+func (m *mockCachePurger) DeleteKeys(keys []string) (int64, error) {
+	m.keys = keys
+	return int64(len(keys)), nil
+}
+
+func (m *mockCachePurger) DeleteKeysByPattern(pattern string) (int64, error) {
+	m.pattern = pattern
+	return 3, nil
+}
+
+func TestValidateCachePurge(t *testing.T) {
+	cases := []struct {
+		name    string
+		reqBody cachePurgeReqBody
+		valid   bool
+	}{
+		{name: "Given allowed keys", reqBody: cachePurgeReqBody{Keys: []string{"news:posts:meta:abc123", "news:posts:full:def456"}}, valid: true},
+		{name: "Given allowed pattern", reqBody: cachePurgeReqBody{Pattern: "news:posts:*"}, valid: true},
+		{name: "Given allowed pattern with prefix", reqBody: cachePurgeReqBody{Pattern: "news:posts:meta:*"}, valid: true},
+		{name: "Given neither keys nor pattern", reqBody: cachePurgeReqBody{}, valid: false},
+		{name: "Given both keys and pattern", reqBody: cachePurgeReqBody{Keys: []string{"news:posts:meta:abc123"}, Pattern: "news:posts:*"}, valid: false},
+		{name: "Given key out of the namespaces", reqBody: cachePurgeReqBody{Keys: []string{"news:posts:meta:abc123", "session:abc"}}, valid: false},
+		{name: "Given key of the namespace not cached", reqBody: cachePurgeReqBody{Keys: []string{"post:slug1"}}, valid: false},
+		{name: "Given pattern of the namespace not cached", reqBody: cachePurgeReqBody{Pattern: "topic:*"}, valid: false},
+		{name: "Given key with wildcard", reqBody: cachePurgeReqBody{Keys: []string{"news:posts:*"}}, valid: false},
+		{name: "Given pattern matching every key", reqBody: cachePurgeReqBody{Pattern: "*"}, valid: false},
+		{name: "Given pattern with wildcard in the middle", reqBody: cachePurgeReqBody{Pattern: "news:posts:*:meta"}, valid: false},
+		{name: "Given pattern with character class", reqBody: cachePurgeReqBody{Pattern: "news:posts:[a-z]*"}, valid: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if failData := validateCachePurge(tc.reqBody); (failData == nil) != tc.valid {
+				t.Errorf("validateCachePurge(%+v) = %v, want valid %v", tc.reqBody, failData, tc.valid)
+			}
+		})
+	}
+}
+
+func TestPurgeCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(cc *CacheController, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/v1/admin/cache/purge", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		status, resp, _ := cc.PurgeCache(c)
+		c.JSON(status, resp)
+		return w
+	}
+
+	s := &mockCachePurger{}
+	cc := NewCacheController(s)
+
+	if w := serve(cc, `{"keys": ["news:posts:meta:abc123", "news:posts:full:def456"]}`); w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(s.keys) != 2 {
+		t.Errorf("expected 2 keys purged, got %v", s.keys)
+	}
+
+	if w := serve(cc, `{"pattern": "news:posts:*"}`); w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if s.pattern != "news:posts:*" {
+		t.Errorf("expected pattern news:posts:* purged, got %s", s.pattern)
+	}
+
+	if w := serve(NewCacheController(&mockCachePurger{}), `{"pattern": "*"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if w := serve(NewCacheController(nil), `{"keys": ["news:posts:meta:abc123"]}`); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+const (
+	// maxReportDetailsLength is the maximum number of characters of the report details
+	maxReportDetailsLength = 500
+	// maxReportsPerUserPerPost is the maximum number of reports of a post by a user
+	maxReportsPerUserPerPost = 3
+)
+
+// reportReasons are the reasons the readers can report the content for
+var reportReasons = []string{models.ReportReasonMisinformation, models.ReportReasonHarassment, models.ReportReasonSpam, models.ReportReasonOther}
+
+// contentReportListParams binds the list params of the content reports, which are always sorted by createdAt descendingly
+var contentReportListParams = ListParamsBinder{
+	DefaultLimit: 10,
+	MaxLimit:     100,
+}
+
+type postGetter interface {
+	GetPostBySlug(string) (models.Post, error)
+	GetPublishedPostBySlug(string) (models.Post, error)
+}
+
+type contentReportReqBody struct {
+	Reason  string `json:"reason" binding:"required"`
+	Details string `json:"details"`
+}
+
+type contentReportMailReqBody struct {
+	Email    string `json:"email" binding:"required"`
+	Slug     string `json:"slug" binding:"required"`
+	Title    string `json:"title"`
+	Reason   string `json:"reason" binding:"required"`
+	Details  string `json:"details"`
+	UserID   uint   `json:"user_id"`
+	ReportID string `json:"report_id"`
+}
+
+// NewContentReportController returns a ContentReportController with the storage of posts and content reports
+func NewContentReportController(ns postGetter, s storage.ContentReportStorage) *ContentReportController {
+	return &ContentReportController{NewsStorage: ns, Storage: s}
+}
+
+// ContentReportController handles the problematic content flagged by the readers
+type ContentReportController struct {
+	NewsStorage postGetter
+	Storage     storage.ContentReportStorage
+}
+
+// CreateContentReport stores the report of the published post by the authenticated user, and notifies the moderators by email.
+// A user reports a post at most `maxReportsPerUserPerPost` times, otherwise 429 is responded.
+func (crc *ContentReportController) CreateContentReport(c *gin.Context) (int, gin.H, error) {
+	var reqBody contentReportReqBody
+
+	if failData, valid := bindRequestJSONBody(c, &reqBody); !valid {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	if !containsString(reportReasons, reqBody.Reason) {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"reason": "should be misinformation, harassment, spam or other"}}, nil
+	}
+
+	if utf8.RuneCountInString(reqBody.Details) > maxReportDetailsLength {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"details": fmt.Sprintf("should be at most %d characters", maxReportDetailsLength)}}, nil
+	}
+
+	slug := c.Param("slug")
+	post, err := crc.NewsStorage.GetPublishedPostBySlug(slug)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{"slug": fmt.Sprintf("cannot find the post(slug: %s)", slug)}}, nil
+		}
+		return toResponse(err)
+	}
+
+	userID, _ := strconv.ParseUint(fmt.Sprint(c.Request.Context().Value(globals.AuthUserIDProperty)), 10, 64)
+
+	report, err := crc.Storage.CreateContentReport(models.ContentReport{
+		PostSlug: slug,
+		UserID:   uint(userID),
+		Reason:   reqBody.Reason,
+		Details:  reqBody.Details,
+	}, maxReportsPerUserPerPost)
+	if errors.Cause(err) == storage.ErrTooManyReports {
+		return http.StatusTooManyRequests, gin.H{"status": "fail", "data": gin.H{
+			"slug": fmt.Sprintf("should not be reported more than %d times", maxReportsPerUserPerPost),
+		}}, nil
+	}
+	if err != nil {
+		return toResponse(err)
+	}
+
+	go sendContentReportMail(report, post)
+
+	return http.StatusCreated, gin.H{"status": "success", "data": report}, nil
+}
+
+// GetContentReports returns the reports of `status` url query param, which is pending by default,
+// sorted by createdAt descendingly and paginated by `limit` and `offset`.
+func (crc *ContentReportController) GetContentReports(c *gin.Context) (int, gin.H, error) {
+	status := c.DefaultQuery("status", models.ReportStatusPending)
+	if status != models.ReportStatusPending && status != models.ReportStatusResolved {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"status": "should be pending or resolved"}}, nil
+	}
+
+	params, err := contentReportListParams.BindListParams(c)
+	if err != nil {
+		return listParamsFailResponse(err)
+	}
+
+	reports, total, err := crc.Storage.GetContentReports(status, params.Limit, params.Offset)
+	if err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"records": emptyIfNil(reports),
+		"meta":    newMetaOfResponse(total, params.Offset, params.Limit),
+	}}, nil
+}
+
+// sendContentReportMail notifies the configured moderators of the report
+func sendContentReportMail(report models.ContentReport, post models.Post) {
+	for _, email := range globals.Conf.News.ModeratorEmails {
+		reqBody := contentReportMailReqBody{
+			Email:    email,
+			Slug:     report.PostSlug,
+			Title:    post.Title,
+			Reason:   report.Reason,
+			Details:  report.Details,
+			UserID:   report.UserID,
+			ReportID: report.ID.Hex(),
+		}
+
+		if err := postMailServiceEndpoint(reqBody, fmt.Sprintf("http://localhost:%s/v1/%s", globals.LocalhostPort, globals.SendContentReportRoutePath)); err != nil {
+			logError(errors.Wrap(err, fmt.Sprintf("fail to send content report of post(slug: %s) to %s", report.PostSlug, email)))
+		}
+	}
+}
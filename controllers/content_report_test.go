@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockPostGetter map[string]models.Post
+
+func (m mockPostGetter) GetPostBySlug(slug string) (models.Post, error) {
+	if post, ok := m[slug]; ok {
+		return post, nil
+	}
+	return models.Post{}, storage.ErrMgoNotFound
+}
+
+func (m mockPostGetter) GetPublishedPostBySlug(slug string) (models.Post, error) {
+	if post, ok := m[slug]; ok && post.State == "published" {
+		return post, nil
+	}
+	return models.Post{}, storage.ErrMgoNotFound
+}
+
+type mockContentReportStorage struct {
+	reports []models.ContentReport
+}
+
+func (s *mockContentReportStorage) CreateContentReport(report models.ContentReport, max int) (models.ContentReport, error) {
+	count := 0
+	for _, r := range s.reports {
+		if r.UserID == report.UserID && r.PostSlug == report.PostSlug {
+			count++
+		}
+	}
+	if count >= max {
+		return models.ContentReport{}, storage.ErrTooManyReports
+	}
+	report.Status = models.ReportStatusPending
+	s.reports = append(s.reports, report)
+	return report, nil
+}
+
+func (s *mockContentReportStorage) GetContentReports(status string, limit int, offset int) ([]models.ContentReport, int, error) {
+	var reports []models.ContentReport
+	for _, report := range s.reports {
+		if report.Status == status {
+			reports = append(reports, report)
+		}
+	}
+	return reports, len(reports), nil
+}
+
+func TestCreateContentReport(t *testing.T) {
+	posts := mockPostGetter{
+		"mock-post":  {Slug: "mock-post", Title: "mock title", State: "published"},
+		"mock-draft": {Slug: "mock-draft", Title: "mock draft", State: "draft"},
+	}
+
+	cases := []struct {
+		name      string
+		slug      string
+		body      string
+		reported  int
+		wantCode  int
+		wantSaved int
+	}{
+		{name: "Given a report", slug: "mock-post", body: `{"reason":"misinformation","details":"the date is wrong"}`, wantCode: http.StatusCreated, wantSaved: 1},
+		{name: "Given a report without details", slug: "mock-post", body: `{"reason":"spam"}`, wantCode: http.StatusCreated, wantSaved: 1},
+		{name: "Given the third report of the user", slug: "mock-post", body: `{"reason":"other"}`, reported: 2, wantCode: http.StatusCreated, wantSaved: 3},
+		{name: "Given the fourth report of the user", slug: "mock-post", body: `{"reason":"other"}`, reported: 3, wantCode: http.StatusTooManyRequests, wantSaved: 3},
+		{name: "Given an unknown reason", slug: "mock-post", body: `{"reason":"boring"}`, wantCode: http.StatusBadRequest},
+		{name: "Given no reason", slug: "mock-post", body: `{"details":"..."}`, wantCode: http.StatusBadRequest},
+		{name: "Given the details too long", slug: "mock-post", body: `{"reason":"other","details":"` + strings.Repeat("長", 501) + `"}`, wantCode: http.StatusBadRequest},
+		{name: "Given the post not found", slug: "unknown-post", body: `{"reason":"spam"}`, wantCode: http.StatusNotFound},
+		{name: "Given the post not published", slug: "mock-draft", body: `{"reason":"spam"}`, wantCode: http.StatusNotFound},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockContentReportStorage{}
+			for i := 0; i < tc.reported; i++ {
+				s.reports = append(s.reports, models.ContentReport{PostSlug: tc.slug, UserID: 1})
+			}
+			crc := NewContentReportController(posts, s)
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			req := httptest.NewRequest(http.MethodPost, "/v1/posts/"+tc.slug+"/report", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req.WithContext(context.WithValue(req.Context(), globals.AuthUserIDProperty, uint(1)))
+			c.Params = gin.Params{{Key: "slug", Value: tc.slug}}
+
+			code, body, _ := crc.CreateContentReport(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d, %v", tc.wantCode, code, body)
+			}
+			if tc.wantSaved > 0 && len(s.reports) != tc.wantSaved {
+				t.Errorf("expect %d reports saved, but got %d", tc.wantSaved, len(s.reports))
+			}
+			if tc.wantCode == http.StatusCreated && s.reports[len(s.reports)-1].UserID != 1 {
+				t.Errorf("expect the report by the user, but got %v", s.reports[len(s.reports)-1])
+			}
+		})
+	}
+}
+
+func TestGetContentReports(t *testing.T) {
+	s := &mockContentReportStorage{reports: []models.ContentReport{
+		{PostSlug: "mock-post", Status: models.ReportStatusPending},
+		{PostSlug: "mock-post", Status: models.ReportStatusResolved},
+	}}
+	crc := NewContentReportController(mockPostGetter{}, s)
+
+	cases := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantTotal int
+	}{
+		{name: "Given no status", query: "", wantCode: http.StatusOK, wantTotal: 1},
+		{name: "Given resolved status", query: "?status=resolved", wantCode: http.StatusOK, wantTotal: 1},
+		{name: "Given an unknown status", query: "?status=deleted", wantCode: http.StatusBadRequest},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/admin/reports"+tc.query, nil)
+
+			code, body, _ := crc.GetContentReports(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			if meta := body["data"].(gin.H)["meta"].(models.MetaOfResponse); meta.Total != tc.wantTotal {
+				t.Errorf("expect total %d, but got %d", tc.wantTotal, meta.Total)
+			}
+		})
+	}
+}
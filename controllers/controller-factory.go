@@ -2,9 +2,12 @@ package controllers
 
 import (
 	"fmt"
+	"html/template"
 	"os"
 
+	"github.com/go-redis/redis"
 	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"gopkg.in/mgo.v2"
 	"twreporter.org/go-api/globals"
@@ -20,16 +23,31 @@ type ControllerFactory struct {
 	mgoSession  *mgo.Session
 	mailService services.MailService
 	mongoClient *mongo.Client
+	redisClient *redis.Client
 }
 
 // GetOAuthController returns OAuth struct
 func (cf *ControllerFactory) GetOAuthController(oauthType string) (oauth *OAuth) {
 	gs := storage.NewGormStorage(cf.gormDB)
-	oauth = &OAuth{Storage: gs}
-	if oauthType == globals.GoogleOAuth {
+	oauth = &OAuth{Storage: gs, logSampler: utils.NewLogSampler(globals.Conf.App.LogSettings.OAuthSampleRate)}
+	switch oauthType {
+	case globals.GoogleOAuth:
 		oauth.InitGoogleConfig()
-	} else {
+	case globals.LinkedInOAuth:
+		oauth.InitLinkedInConfig()
+	case globals.AppleOAuth:
+		oauth.InitAppleConfig()
+	default:
 		oauth.InitFacebookConfig()
+
+		if conf := globals.Conf.Oauth.Avatar; conf.Bucket != "" {
+			store, err := services.NewS3AvatarStore(conf)
+			if err != nil {
+				logError(errors.WithMessage(err, "avatar variants are disabled"))
+				return oauth
+			}
+			oauth.avatarService = services.NewAvatarService(utils.NewHTTPClient(conf.DownloadTimeout), store)
+		}
 	}
 
 	return oauth
@@ -38,13 +56,183 @@ func (cf *ControllerFactory) GetOAuthController(oauthType string) (oauth *OAuth)
 // GetMembershipController returns *MembershipController struct
 func (cf *ControllerFactory) GetMembershipController() *MembershipController {
 	gs := storage.NewGormStorage(cf.gormDB)
-	return NewMembershipController(gs)
+	mc := NewMembershipController(gs)
+	ms := storage.NewMongoStorage(cf.mgoSession)
+	mc.TopicStorage = ms
+	mc.PostStorage = ms
+	return mc
+}
+
+// getNewsStorage returns the news storage, which is cached if redis client is provided
+func (cf *ControllerFactory) getNewsStorage() storage.NewsStorage {
+	ms := storage.NewMongoStorage(cf.mgoSession)
+	if cf.redisClient != nil {
+		return storage.NewCachedNewsStorage(ms, cf.redisClient)
+	}
+	return ms
 }
 
 // GetNewsController returns *NewsController struct
 func (cf *ControllerFactory) GetNewsController() *NewsController {
-	ms := storage.NewMongoStorage(cf.mgoSession)
-	return NewNewsController(ms)
+	return NewNewsController(cf.getNewsStorage())
+}
+
+// GetPostStateController returns *PostStateController struct,
+// which sends the push notifications if `news.push.credentials_path` is provided
+func (cf *ControllerFactory) GetPostStateController() *PostStateController {
+	gs := storage.NewGormStorage(cf.gormDB)
+	psc := NewPostStateController(cf.getNewsStorage(), gs)
+
+	if conf := globals.Conf.News.Push; conf.CredentialsPath != "" {
+		fcm, err := services.NewFCMService(conf)
+		if err != nil {
+			logError(errors.WithMessage(err, "push notifications are disabled"))
+			return psc
+		}
+		psc.PushService, psc.PushStorage = fcm, gs
+	}
+
+	return psc
+}
+
+// GetReadingHistoryController returns *ReadingHistoryController struct
+func (cf *ControllerFactory) GetReadingHistoryController() *ReadingHistoryController {
+	return NewReadingHistoryController(cf.getNewsStorage(), storage.NewGormStorage(cf.gormDB))
+}
+
+// GetPushController returns *PushController struct
+func (cf *ControllerFactory) GetPushController() *PushController {
+	return NewPushController(storage.NewGormStorage(cf.gormDB))
+}
+
+// GetPostDuplicateController returns *PostDuplicateController struct
+func (cf *ControllerFactory) GetPostDuplicateController() *PostDuplicateController {
+	return NewPostDuplicateController(cf.getNewsStorage())
+}
+
+// GetPostMergeController returns *PostMergeController struct,
+// the posts are merged by the client supporting MongoDB transactions
+func (cf *ControllerFactory) GetPostMergeController() *PostMergeController {
+	pmc := NewPostMergeController(storage.NewMongoV2Storage(cf.mongoClient), storage.NewGormStorage(cf.gormDB))
+	pmc.Cache = cf.getPostsCacheInvalidator()
+	return pmc
+}
+
+// getPostsCacheInvalidator returns the invalidator of the cached listed posts,
+// which is nil if redis client is not provided
+func (cf *ControllerFactory) getPostsCacheInvalidator() postsCacheInvalidator {
+	if cf.redisClient == nil {
+		return nil
+	}
+	return storage.NewCachedNewsStorage(storage.NewMongoStorage(cf.mgoSession), cf.redisClient)
+}
+
+// GetTopicPostsController returns *TopicPostsController struct
+func (cf *ControllerFactory) GetTopicPostsController() *TopicPostsController {
+	return NewTopicPostsController(cf.getNewsStorage())
+}
+
+// GetPostImportController returns *PostImportController struct
+func (cf *ControllerFactory) GetPostImportController() *PostImportController {
+	pic := NewPostImportController(storage.NewMongoV2Storage(cf.mongoClient))
+	pic.Cache = cf.getPostsCacheInvalidator()
+	return pic
+}
+
+// GetPostExportController returns *PostExportController struct
+func (cf *ControllerFactory) GetPostExportController() *PostExportController {
+	return NewPostExportController(storage.NewMongoStorage(cf.mgoSession))
+}
+
+// GetPostPrintController returns *PostPrintController struct
+func (cf *ControllerFactory) GetPostPrintController() *PostPrintController {
+	templatePath := globals.Conf.News.PrintTemplatePath
+
+	if templatePath == "" {
+		templatePath = fmt.Sprintf("%s/post-print.tmpl", getTemplateDir())
+	}
+
+	return NewPostPrintController(cf.getNewsStorage(), template.Must(template.ParseFiles(templatePath)))
+}
+
+// GetPostCitationController returns *PostCitationController struct
+func (cf *ControllerFactory) GetPostCitationController() *PostCitationController {
+	return NewPostCitationController(cf.getNewsStorage())
+}
+
+// GetPostPaywallController returns *PostPaywallController struct
+func (cf *ControllerFactory) GetPostPaywallController() *PostPaywallController {
+	gs := storage.NewGormStorage(cf.gormDB)
+	return NewPostPaywallController(cf.getNewsStorage(), gs, gs)
+}
+
+// GetPostFootnotesController returns *PostFootnotesController struct,
+// only the footnotes are read from the database without the content of the posts
+func (cf *ControllerFactory) GetPostFootnotesController() *PostFootnotesController {
+	return NewPostFootnotesController(storage.NewMongoStorage(cf.mgoSession))
+}
+
+// GetPostShareCountsController returns *PostShareCountsController struct,
+// the share counts are read from the database directly since they are polled frequently
+func (cf *ControllerFactory) GetPostShareCountsController() *PostShareCountsController {
+	return NewPostShareCountsController(storage.NewMongoStorage(cf.mgoSession))
+}
+
+// GetSubscriptionController returns *SubscriptionController struct
+func (cf *ControllerFactory) GetSubscriptionController() *SubscriptionController {
+	return NewSubscriptionController(storage.NewGormStorage(cf.gormDB))
+}
+
+// GetUserAdminController returns *UserAdminController struct
+func (cf *ControllerFactory) GetUserAdminController() *UserAdminController {
+	return NewUserAdminController(storage.NewGormStorage(cf.gormDB))
+}
+
+// GetCorrectionController returns *CorrectionController struct
+func (cf *ControllerFactory) GetCorrectionController() *CorrectionController {
+	return NewCorrectionController(cf.getNewsStorage(), storage.NewMongoStorage(cf.mgoSession))
+}
+
+// GetAnnotationController returns *AnnotationController struct
+func (cf *ControllerFactory) GetAnnotationController() *AnnotationController {
+	return NewAnnotationController(cf.getNewsStorage(), storage.NewMongoStorage(cf.mgoSession))
+}
+
+// GetContentReportController returns *ContentReportController struct
+func (cf *ControllerFactory) GetContentReportController() *ContentReportController {
+	return NewContentReportController(cf.getNewsStorage(), storage.NewMongoStorage(cf.mgoSession))
+}
+
+// GetPostVersionController returns *PostVersionController struct
+func (cf *ControllerFactory) GetPostVersionController() *PostVersionController {
+	return NewPostVersionController(storage.NewMongoV2Storage(cf.mongoClient))
+}
+
+// GetPostEventsController returns *PostEventsController struct
+func (cf *ControllerFactory) GetPostEventsController() *PostEventsController {
+	return NewPostEventsController(storage.NewMongoV2Storage(cf.mongoClient), globals.Conf.App.MaxSSEConnections)
+}
+
+// GetCacheController returns *CacheController struct
+func (cf *ControllerFactory) GetCacheController() *CacheController {
+	if cf.redisClient == nil {
+		return NewCacheController(nil)
+	}
+	return NewCacheController(storage.NewCacheStorage(cf.redisClient))
+}
+
+// GetRecommendationController returns *RecommendationController struct,
+// the popular posts are always recommended if the cache is not enabled
+func (cf *ControllerFactory) GetRecommendationController() *RecommendationController {
+	if cf.redisClient == nil {
+		return NewRecommendationController(nil, cf.getNewsStorage())
+	}
+	return NewRecommendationController(storage.NewRecommendationCache(cf.redisClient), cf.getNewsStorage())
+}
+
+// GetHealthController returns *HealthController struct
+func (cf *ControllerFactory) GetHealthController() *HealthController {
+	return NewHealthController(storage.NewMongoV2Storage(cf.mongoClient))
 }
 
 func (cf *ControllerFactory) GetNewsV2Controller() *newsV2Controller {
@@ -57,15 +245,22 @@ func (cf *ControllerFactory) GetMailController() *MailController {
 
 	contrl = NewMailController(cf.mailService, nil)
 
+	templateDir := getTemplateDir()
+
+	contrl.LoadTemplateFiles(fmt.Sprintf("%s/signin.tmpl", templateDir), fmt.Sprintf("%s/success-donation.tmpl", templateDir), fmt.Sprintf("%s/post-state-change.tmpl", templateDir), fmt.Sprintf("%s/content-report.tmpl", templateDir))
+
+	return contrl
+}
+
+// getTemplateDir returns the directory of html templates
+func getTemplateDir() string {
 	templateDir := os.Getenv("GOAPI_HTML_TEMPLATE_DIR")
 
 	if templateDir == "" {
 		templateDir = utils.GetProjectRoot() + "/template"
 	}
 
-	contrl.LoadTemplateFiles(fmt.Sprintf("%s/signin.tmpl", templateDir), fmt.Sprintf("%s/success-donation.tmpl", templateDir))
-
-	return contrl
+	return templateDir
 }
 
 // GetMailService returns MailService it holds
@@ -84,11 +279,13 @@ func (cf *ControllerFactory) GetGormDB() *gorm.DB {
 }
 
 // NewControllerFactory generate *ControllerFactory struct
-func NewControllerFactory(gormDB *gorm.DB, mgoSession *mgo.Session, mailSvc services.MailService, client *mongo.Client) *ControllerFactory {
+// redisClient is optional, the news are not cached if it is nil.
+func NewControllerFactory(gormDB *gorm.DB, mgoSession *mgo.Session, mailSvc services.MailService, client *mongo.Client, redisClient *redis.Client) *ControllerFactory {
 	return &ControllerFactory{
 		gormDB:      gormDB,
 		mgoSession:  mgoSession,
 		mailService: mailSvc,
 		mongoClient: client,
+		redisClient: redisClient,
 	}
 }
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+// maxCorrectionDescriptionLength is the maximum number of characters of the correction description
+const maxCorrectionDescriptionLength = 2000
+
+type correctionReqBody struct {
+	Description string     `json:"description" binding:"required"`
+	Severity    string     `json:"severity" binding:"required"`
+	IssuedAt    *time.Time `json:"issued_at"`
+	CorrectedAt *time.Time `json:"corrected_at"`
+}
+
+type correctionPatchReqBody struct {
+	Description *string    `json:"description"`
+	Severity    *string    `json:"severity"`
+	IssuedAt    *time.Time `json:"issued_at"`
+	CorrectedAt *time.Time `json:"corrected_at"`
+}
+
+// NewCorrectionController returns a CorrectionController with the storage of posts and corrections
+func NewCorrectionController(ns postGetter, s storage.CorrectionStorage) *CorrectionController {
+	return &CorrectionController{NewsStorage: ns, Storage: s}
+}
+
+// CorrectionController manages the published corrections, i.e. errata, of the posts
+type CorrectionController struct {
+	NewsStorage postGetter
+	Storage     storage.CorrectionStorage
+}
+
+// GetCorrectionsOfAPost returns the corrections of the post, the latest issued come first.
+// The records are empty if the post is never corrected.
+func (cc *CorrectionController) GetCorrectionsOfAPost(c *gin.Context) (int, gin.H, error) {
+	corrections, err := cc.Storage.GetCorrectionsOfAPost(c.Param("slug"))
+	if err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": emptyIfNil(corrections)}}, nil
+}
+
+// CreateCorrection files the correction of the post of `:slug`, which is issued now if `issued_at` is not provided
+func (cc *CorrectionController) CreateCorrection(c *gin.Context) (int, gin.H, error) {
+	var reqBody correctionReqBody
+
+	if failData, valid := bindRequestJSONBody(c, &reqBody); !valid {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	if failData, valid := validateCorrectionDescription(reqBody.Description); !valid {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+	if failData, valid := validateCorrectionSeverity(reqBody.Severity); !valid {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	slug := c.Param("slug")
+	if _, err := cc.NewsStorage.GetPostBySlug(slug); err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{"slug": fmt.Sprintf("cannot find the post(slug: %s)", slug)}}, nil
+		}
+		return toResponse(err)
+	}
+
+	correction := models.Correction{
+		PostSlug:    slug,
+		Description: reqBody.Description,
+		Severity:    reqBody.Severity,
+		CorrectedAt: reqBody.CorrectedAt,
+	}
+	if reqBody.IssuedAt != nil {
+		correction.IssuedAt = *reqBody.IssuedAt
+	}
+
+	correction, err := cc.Storage.CreateCorrection(correction)
+	if err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusCreated, gin.H{"status": "success", "data": correction}, nil
+}
+
+// UpdateCorrection updates the fields provided of the correction of `:id`, e.g. `corrected_at` once the post is corrected
+func (cc *CorrectionController) UpdateCorrection(c *gin.Context) (int, gin.H, error) {
+	var reqBody correctionPatchReqBody
+
+	id := c.Param("id")
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil ||
+		(reqBody.Description == nil && reqBody.Severity == nil && reqBody.IssuedAt == nil && reqBody.CorrectedAt == nil) {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": "should be at least one of description, severity, issued_at and corrected_at",
+		}}, nil
+	}
+
+	fields := bson.M{}
+	if reqBody.Description != nil {
+		if failData, valid := validateCorrectionDescription(*reqBody.Description); !valid {
+			return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+		}
+		fields["description"] = *reqBody.Description
+	}
+	if reqBody.Severity != nil {
+		if failData, valid := validateCorrectionSeverity(*reqBody.Severity); !valid {
+			return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+		}
+		fields["severity"] = *reqBody.Severity
+	}
+	if reqBody.IssuedAt != nil {
+		fields["issuedAt"] = *reqBody.IssuedAt
+	}
+	if reqBody.CorrectedAt != nil {
+		fields["correctedAt"] = *reqBody.CorrectedAt
+	}
+
+	correction, err := cc.Storage.UpdateCorrection(id, fields)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{"id": fmt.Sprintf("cannot find the correction(id: %s)", id)}}, nil
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": correction}, nil
+}
+
+// validateCorrectionDescription checks the description is neither blank nor too long
+func validateCorrectionDescription(description string) (gin.H, bool) {
+	if strings.TrimSpace(description) == "" {
+		return gin.H{"description": "cannot be empty"}, false
+	}
+	if utf8.RuneCountInString(description) > maxCorrectionDescriptionLength {
+		return gin.H{"description": fmt.Sprintf("should be at most %d characters", maxCorrectionDescriptionLength)}, false
+	}
+	return nil, true
+}
+
+// validateCorrectionSeverity checks the severity is one of minor, major and retraction
+func validateCorrectionSeverity(severity string) (gin.H, bool) {
+	switch severity {
+	case models.CorrectionSeverityMinor, models.CorrectionSeverityMajor, models.CorrectionSeverityRetraction:
+		return nil, true
+	}
+	return gin.H{"severity": fmt.Sprintf("should be one of %s, %s and %s",
+		models.CorrectionSeverityMinor, models.CorrectionSeverityMajor, models.CorrectionSeverityRetraction)}, false
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockCorrectionStorage struct {
+	corrections map[string]models.Correction
+}
+
+func (s *mockCorrectionStorage) GetCorrectionsOfAPost(slug string) ([]models.Correction, error) {
+	var corrections []models.Correction
+	for _, correction := range s.corrections {
+		if correction.PostSlug == slug {
+			corrections = append(corrections, correction)
+		}
+	}
+	return corrections, nil
+}
+
+func (s *mockCorrectionStorage) CreateCorrection(correction models.Correction) (models.Correction, error) {
+	correction.ID = bson.NewObjectId()
+	if correction.IssuedAt.IsZero() {
+		correction.IssuedAt = time.Now()
+	}
+	s.corrections[correction.ID.Hex()] = correction
+	return correction, nil
+}
+
+func (s *mockCorrectionStorage) UpdateCorrection(id string, fields bson.M) (models.Correction, error) {
+	correction, ok := s.corrections[id]
+	if !ok {
+		return correction, errors.WithStack(storage.ErrMgoNotFound)
+	}
+	if description, ok := fields["description"]; ok {
+		correction.Description = description.(string)
+	}
+	if severity, ok := fields["severity"]; ok {
+		correction.Severity = severity.(string)
+	}
+	if correctedAt, ok := fields["correctedAt"]; ok {
+		t := correctedAt.(time.Time)
+		correction.CorrectedAt = &t
+	}
+	s.corrections[id] = correction
+	return correction, nil
+}
+
+func TestGetCorrectionsOfAPost(t *testing.T) {
+	s := &mockCorrectionStorage{corrections: map[string]models.Correction{
+		"5edf118c3e631f0600198935": {PostSlug: "corrected-post", Severity: models.CorrectionSeverityMinor, Description: "the name is misspelled"},
+	}}
+
+	cases := []struct {
+		name     string
+		slug     string
+		wantSize int
+	}{
+		{name: "Given a corrected post", slug: "corrected-post", wantSize: 1},
+		{name: "Given a post never corrected", slug: "mock-post", wantSize: 0},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts/"+tc.slug+"/corrections", nil)
+			c.Params = gin.Params{{Key: "slug", Value: tc.slug}}
+
+			code, body, _ := NewCorrectionController(annotatedPosts, s).GetCorrectionsOfAPost(c)
+			if code != http.StatusOK {
+				t.Fatalf("expect status %d, but got %d", http.StatusOK, code)
+			}
+
+			records, ok := body["data"].(gin.H)["records"].([]models.Correction)
+			if !ok || records == nil || len(records) != tc.wantSize {
+				t.Errorf("expect %d corrections in a non-nil array, but got %#v", tc.wantSize, body["data"])
+			}
+		})
+	}
+}
+
+func TestCreateCorrection(t *testing.T) {
+	cases := []struct {
+		name     string
+		slug     string
+		body     string
+		wantCode int
+	}{
+		{name: "Given a minor correction", slug: "mock-post", body: `{"description":"the name is misspelled","severity":"minor"}`, wantCode: http.StatusCreated},
+		{name: "Given a retraction issued before", slug: "mock-post", body: `{"description":"the report is withdrawn","severity":"retraction","issued_at":"2020-06-08T16:00:00Z"}`, wantCode: http.StatusCreated},
+		{name: "Given an unknown severity", slug: "mock-post", body: `{"description":"the name is misspelled","severity":"trivial"}`, wantCode: http.StatusBadRequest},
+		{name: "Given no severity", slug: "mock-post", body: `{"description":"the name is misspelled"}`, wantCode: http.StatusBadRequest},
+		{name: "Given a blank description", slug: "mock-post", body: `{"description":"  ","severity":"major"}`, wantCode: http.StatusBadRequest},
+		{name: "Given the description too long", slug: "mock-post", body: `{"description":"` + strings.Repeat("長", maxCorrectionDescriptionLength+1) + `","severity":"major"}`, wantCode: http.StatusBadRequest},
+		{name: "Given the post not found", slug: "unknown-post", body: `{"description":"the name is misspelled","severity":"minor"}`, wantCode: http.StatusNotFound},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockCorrectionStorage{corrections: map[string]models.Correction{}}
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/posts/"+tc.slug+"/corrections", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "slug", Value: tc.slug}}
+
+			code, body, _ := NewCorrectionController(annotatedPosts, s).CreateCorrection(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d(%v)", tc.wantCode, code, body)
+			}
+			if code != http.StatusCreated {
+				return
+			}
+
+			correction := body["data"].(models.Correction)
+			if correction.PostSlug != tc.slug || correction.IssuedAt.IsZero() {
+				t.Errorf("expect the issued correction of %s, but got %+v", tc.slug, correction)
+			}
+		})
+	}
+}
+
+func TestUpdateCorrection(t *testing.T) {
+	const id = "5edf118c3e631f0600198935"
+
+	cases := []struct {
+		name         string
+		id           string
+		body         string
+		wantCode     int
+		wantSeverity string
+	}{
+		{name: "Given the correction escalated", id: id, body: `{"severity":"major"}`, wantCode: http.StatusOK, wantSeverity: models.CorrectionSeverityMajor},
+		{name: "Given the post corrected", id: id, body: `{"corrected_at":"2020-06-09T08:00:00Z"}`, wantCode: http.StatusOK, wantSeverity: models.CorrectionSeverityMinor},
+		{name: "Given an unknown severity", id: id, body: `{"severity":"trivial"}`, wantCode: http.StatusBadRequest},
+		{name: "Given an empty body", id: id, body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "Given the correction not found", id: "5edf118c3e631f0600198936", body: `{"severity":"major"}`, wantCode: http.StatusNotFound},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockCorrectionStorage{corrections: map[string]models.Correction{
+				id: {ID: bson.ObjectIdHex(id), PostSlug: "mock-post", Severity: models.CorrectionSeverityMinor, Description: "the name is misspelled"},
+			}}
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPatch, "/v1/admin/corrections/"+tc.id, strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: tc.id}}
+
+			code, body, _ := NewCorrectionController(annotatedPosts, s).UpdateCorrection(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d(%v)", tc.wantCode, code, body)
+			}
+			if code != http.StatusOK {
+				return
+			}
+
+			if correction := body["data"].(models.Correction); correction.Severity != tc.wantSeverity {
+				t.Errorf("expect severity %s, but got %+v", tc.wantSeverity, correction)
+			}
+		})
+	}
+}
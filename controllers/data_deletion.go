@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/utils"
+)
+
+const facebookDataDeletionPath = "/v1/webhooks/facebook/data-deletion"
+
+// HandleFacebookDataDeletion receives the data deletion request of the Facebook user.
+// The `signed_request` is verified by the app secret, and then the deletion is scheduled.
+// The status url and the confirmation code are responded as Facebook requires.
+// See https://developers.facebook.com/docs/development/create-an-app/app-dashboard/data-deletion-callback
+func (mc *MembershipController) HandleFacebookDataDeletion(c *gin.Context) (int, gin.H, error) {
+	signedRequest, err := utils.ParseFacebookSignedRequest(c.PostForm("signed_request"), globals.Conf.Oauth.Facebook.Secret)
+	if err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"signed_request": err.Error(),
+		}}, nil
+	}
+
+	b, err := utils.GenerateRandomBytes(16)
+	if err != nil {
+		return toResponse(err)
+	}
+
+	req := models.DataDeletionRequest{
+		ConfirmationCode: hex.EncodeToString(b),
+		Type:             globals.FacebookOAuth,
+		AId:              signedRequest.UserID,
+		Status:           models.DataDeletionStatusPending,
+	}
+
+	if err = mc.Storage.Create(&req); err != nil {
+		return toResponse(err)
+	}
+
+	go mc.deleteUserData(req)
+
+	return http.StatusOK, gin.H{
+		"url": fmt.Sprintf("%s://%s:%s%s/%s",
+			globals.Conf.App.Protocol,
+			globals.Conf.App.Host,
+			globals.Conf.App.Port,
+			facebookDataDeletionPath,
+			req.ConfirmationCode,
+		),
+		"confirmation_code": req.ConfirmationCode,
+	}, nil
+}
+
+// deleteUserData deletes the user data requested and records the result in the request
+func (mc *MembershipController) deleteUserData(req models.DataDeletionRequest) {
+	status := models.DataDeletionStatusCompleted
+	if err := mc.Storage.DeleteUserDataByOAuth(req.Type, req.AId); err != nil {
+		logError(errors.WithMessage(err, fmt.Sprintf("fail to delete user data of data deletion request(code: %s)", req.ConfirmationCode)))
+		status = models.DataDeletionStatusFailed
+	}
+
+	if err, _ := mc.Storage.UpdateByConditions(map[string]interface{}{"id": req.ID}, &models.DataDeletionRequest{Status: status}); err != nil {
+		logError(errors.WithMessage(err, fmt.Sprintf("fail to update status of data deletion request(code: %s)", req.ConfirmationCode)))
+	}
+}
+
+// GetDataDeletionStatus returns the status of the data deletion request by its confirmation code
+func (mc *MembershipController) GetDataDeletionStatus(c *gin.Context) (int, gin.H, error) {
+	var req models.DataDeletionRequest
+
+	if err := mc.Storage.GetByConditions(map[string]interface{}{"confirmation_code": c.Param("code")}, &req); err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": req}, nil
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+// maxDeviceTokenLength is the maximum length of the FCM registration token
+const maxDeviceTokenLength = 255
+
+// NewPushController returns a PushController with the push storage
+func NewPushController(s storage.PushStorage) *PushController {
+	return &PushController{Storage: s}
+}
+
+// PushController manages the device tokens and the category subscriptions of the push notifications
+type PushController struct {
+	Storage storage.PushStorage
+}
+
+type deviceTokenReqBody struct {
+	Token    string `json:"token" binding:"required"`
+	Platform string `json:"platform" binding:"required"`
+}
+
+type categorySubscriptionReqBody struct {
+	CategoryID string `json:"category_id" binding:"required"`
+}
+
+// RegisterDeviceToken registers the FCM registration token of the mobile app signed in by the user
+func (pc *PushController) RegisterDeviceToken(c *gin.Context) (int, gin.H, error) {
+	var reqBody deviceTokenReqBody
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": fmt.Sprintf("should be {\"token\": \"...\", \"platform\": \"android\"}. %s", err.Error()),
+		}}, nil
+	}
+
+	if len(reqBody.Token) > maxDeviceTokenLength {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"token": fmt.Sprintf("should be at most %d characters", maxDeviceTokenLength),
+		}}, nil
+	}
+
+	switch reqBody.Platform {
+	case models.DeviceTokenPlatformAndroid, models.DeviceTokenPlatformIOS:
+		// omit intentionally
+	default:
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"platform": fmt.Sprintf("should be %s or %s", models.DeviceTokenPlatformAndroid, models.DeviceTokenPlatformIOS),
+		}}, nil
+	}
+
+	userID, _ := strconv.ParseUint(c.Param("userID"), 10, 0)
+
+	deviceToken, err := pc.Storage.SaveDeviceToken(models.DeviceToken{
+		UserID:   uint(userID),
+		Token:    reqBody.Token,
+		Platform: reqBody.Platform,
+	})
+	if err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusCreated, gin.H{"status": "success", "data": deviceToken}, nil
+}
+
+// GetCategorySubscriptionsOfAUser returns the categories of which the user is notified
+func (pc *PushController) GetCategorySubscriptionsOfAUser(c *gin.Context) (int, gin.H, error) {
+	subs, err := pc.Storage.GetCategorySubscriptionsOfAUser(c.Param("userID"))
+	if err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": subs}, nil
+}
+
+// CreateCategorySubscription notifies the user of the posts published in the category
+func (pc *PushController) CreateCategorySubscription(c *gin.Context) (int, gin.H, error) {
+	var reqBody categorySubscriptionReqBody
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil || !bson.IsObjectIdHex(reqBody.CategoryID) {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"category_id": "should be the id of the category, e.g. 5edf118c3e631f0600198935",
+		}}, nil
+	}
+
+	userID, _ := strconv.ParseUint(c.Param("userID"), 10, 0)
+
+	sub, err := pc.Storage.CreateCategorySubscription(models.CategorySubscription{
+		UserID:     uint(userID),
+		CategoryID: reqBody.CategoryID,
+	})
+	if err != nil {
+		if storage.IsConflict(err) {
+			return http.StatusConflict, gin.H{"status": "fail", "data": gin.H{
+				"category_id": fmt.Sprintf("category(id: %s) is subscribed already", reqBody.CategoryID),
+			}}, nil
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusCreated, gin.H{"status": "success", "data": sub}, nil
+}
+
+// DeleteCategorySubscription stops notifying the user of the posts published in the category
+func (pc *PushController) DeleteCategorySubscription(c *gin.Context) (int, gin.H, error) {
+	categoryID := c.Param("categoryID")
+
+	if err := pc.Storage.DeleteCategorySubscription(c.Param("userID"), categoryID); err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{
+				"categoryID": fmt.Sprintf("category(id: %s) is not subscribed", categoryID),
+			}}, nil
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusNoContent, gin.H{}, nil
+}
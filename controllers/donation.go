@@ -28,6 +28,7 @@ import (
 	"twreporter.org/go-api/globals"
 	"twreporter.org/go-api/models"
 	"twreporter.org/go-api/storage"
+	"twreporter.org/go-api/utils"
 )
 
 const (
@@ -1131,12 +1132,12 @@ func getPayMethodID(payMethod string) int {
 func getProxyHttpClient() *http.Client {
 	const defaultRequestTimeout = 45 * time.Second
 
-	client := &http.Client{Timeout: defaultRequestTimeout}
+	client := utils.NewHTTPClient(defaultRequestTimeout)
 
 	// Prior to route through proxy for http request if a proxy server is configured
 	if len(globals.Conf.Donation.ProxyServer) > 0 {
 		proxyUrl, _ := url.Parse(globals.Conf.Donation.ProxyServer)
-		client.Transport = &http.Transport{Proxy: http.ProxyURL(proxyUrl)}
+		client.Transport = utils.NewOutboundTransport(&http.Transport{Proxy: http.ProxyURL(proxyUrl)})
 	}
 
 	return client
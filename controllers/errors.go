@@ -7,25 +7,49 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 
+	"twreporter.org/go-api/models"
 	"twreporter.org/go-api/storage"
 )
 
-func toResponse(err error) (int, gin.H, error) {
+// toAppError classifies the storage error into AppError
+func toAppError(err error) *models.AppError {
 	cause := errors.Cause(err)
 
-	// For legacy storage errors, the NotFound and Conflict error type is responsed with status "error" if no explicit handlers in controller layer.
-	// Try to migrate these errors to status "fail".
-	// TODO: adjust client side errors
+	if appErr, ok := cause.(*models.AppError); ok {
+		return appErr
+	}
+
 	switch {
 	case storage.IsNotFound(err):
-		return http.StatusNotFound, gin.H{"status": "error", "message": fmt.Sprintf("record not found. %s", cause.Error())}, nil
+		return &models.AppError{Code: models.ErrCodeRecordNotFound, StatusCode: http.StatusNotFound, Message: fmt.Sprintf("record not found. %s", cause.Error()), Err: err}
 	case storage.IsConflict(err):
-		return http.StatusConflict, gin.H{"status": "error", "message": fmt.Sprintf("record is already existed. %s", cause.Error())}, nil
+		return &models.AppError{Code: models.ErrCodeRecordConflict, StatusCode: http.StatusConflict, Message: fmt.Sprintf("record is already existed. %s", cause.Error()), Err: err}
+	case storage.IsTimeout(err):
+		return &models.AppError{Code: models.ErrCodeUpstreamTimeout, StatusCode: http.StatusGatewayTimeout, Message: "Query upstream server timeout.", Err: err}
+	case storage.IsReadOnly(err):
+		return storage.NewReadOnlyError(err)
 	default:
 		// omit itentionally
 	}
 
-	return http.StatusInternalServerError, gin.H{"status": "error", "message": fmt.Sprintf("internal server error. %s", cause.Error())}, nil
+	return &models.AppError{Code: models.ErrCodeInternal, StatusCode: http.StatusInternalServerError, Message: fmt.Sprintf("internal server error. %s", cause.Error()), Err: err}
+}
+
+// responseError returns the AppError to the response wrapper only if it needs extra response headers
+func responseError(appErr *models.AppError) error {
+	if appErr.RetryAfter > 0 {
+		return appErr
+	}
+	return nil
+}
+
+func toResponse(err error) (int, gin.H, error) {
+	// For legacy storage errors, the NotFound and Conflict error type is responsed with status "error" if no explicit handlers in controller layer.
+	// Try to migrate these errors to status "fail".
+	// TODO: adjust client side errors
+	appErr := toAppError(err)
+
+	return appErr.StatusCode, gin.H{"status": "error", "code": appErr.Code, "message": appErr.Message}, responseError(appErr)
 }
 
 func toPostResponse(err error) (int, gin.H, error) {
@@ -36,14 +60,7 @@ func toPostResponse(err error) (int, gin.H, error) {
 	// Try to migrate these errors to `status`:"fail" or "error" and replace `error`field with corresponding `data` or `message` field
 	// adhered to jsend payload.
 	// TODO: adjust error payloads
-	switch {
-	case storage.IsNotFound(err):
-		return http.StatusNotFound, gin.H{"status": fmt.Sprintf("record not found. %s", cause.Error()), "error": cause.Error()}, nil
-	case storage.IsConflict(err):
-		return http.StatusConflict, gin.H{"status": fmt.Sprintf("record is already existed. %s", cause.Error()), "error": cause.Error()}, nil
-	default:
-		// omit itentionally
-	}
+	appErr := toAppError(err)
 
-	return http.StatusInternalServerError, gin.H{"status": fmt.Sprintf("internal server error. %s", cause.Error()), "error": cause.Error()}, nil
+	return appErr.StatusCode, gin.H{"status": appErr.Message, "code": appErr.Code, "error": cause.Error()}, responseError(appErr)
 }
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+func TestToResponseCode(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{
+			name:   "Given record not found",
+			err:    errors.Wrap(storage.ErrRecordNotFound, "get user(id: 1) error"),
+			status: http.StatusNotFound,
+			code:   models.ErrCodeRecordNotFound,
+		},
+		{
+			name:   "Given duplicate entry",
+			err:    errors.WithStack(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}),
+			status: http.StatusConflict,
+			code:   models.ErrCodeRecordConflict,
+		},
+		{
+			name:   "Given query timeout",
+			err:    errors.WithStack(storage.ErrQueryTimeout),
+			status: http.StatusGatewayTimeout,
+			code:   models.ErrCodeUpstreamTimeout,
+		},
+		{
+			name:   "Given read-only database",
+			err:    errors.WithStack(&mgo.LastError{Code: 10107, Err: "not master"}),
+			status: http.StatusServiceUnavailable,
+			code:   models.ErrCodeServiceReadOnly,
+		},
+		{
+			name:   "Given unexpected error",
+			err:    errors.New("connection refused"),
+			status: http.StatusInternalServerError,
+			code:   models.ErrCodeInternal,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, respond := range []func(error) (int, gin.H, error){toResponse, toPostResponse} {
+				status, body, _ := respond(tc.err)
+				if status != tc.status {
+					t.Errorf("expected status %d, got %d", tc.status, status)
+				}
+				if body["code"] != tc.code {
+					t.Errorf("expected code %s, got %v", tc.code, body["code"])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateStateCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var err error
+	engine := gin.New()
+	engine.Use(sessions.Sessions("go-api-session", cookie.NewStore([]byte("secret"))))
+	engine.GET("/callback", func(c *gin.Context) {
+		err = validateState(c, "forged-state")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/callback", nil)
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	if appErr, ok := errors.Cause(err).(*models.AppError); !ok || appErr.Code != models.ErrCodeOAuthStateInvalid {
+		t.Errorf("expected %s error, got %v", models.ErrCodeOAuthStateInvalid, err)
+	}
+}
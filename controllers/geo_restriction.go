@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+)
+
+// checkPostCountry checks the post of the slug is available in the client country set by middlewares.SetClientCountry.
+// The fail body of 451 is returned if the post is restricted in the client country.
+// Since the responses of the restricted posts vary with the client country, they are made private to CDN.
+func checkPostCountry(c *gin.Context, slug string, restricted []string) (gin.H, bool) {
+	if len(restricted) == 0 {
+		return nil, true
+	}
+
+	setPrivateCache(c)
+	if isRestrictedCountry(restricted, c.GetString(globals.ClientCountryProperty)) {
+		return gin.H{"status": "fail", "data": gin.H{
+			"slug": fmt.Sprintf("post(slug: %s) is unavailable in your country", slug),
+		}}, false
+	}
+	return nil, true
+}
+
+// restrictedPostResponse responds 451 with the fail body of checkPostCountry
+func restrictedPostResponse(failBody gin.H) (int, gin.H, error) {
+	return http.StatusUnavailableForLegalReasons, failBody, nil
+}
+
+// stripRestrictedContents removes the content of the listed posts restricted in the client country,
+// so that they are listed as the meta of posts only.
+func stripRestrictedContents(c *gin.Context, posts []models.Post) {
+	for i := range posts {
+		if _, available := checkPostCountry(c, posts[i].Slug, posts[i].RestrictedCountries); !available {
+			posts[i].Content = nil
+			posts[i].Full = false
+		}
+	}
+}
+
+// setPrivateCache keeps the max-age of the Cache-Control set by the route, but disallows the shared caches
+func setPrivateCache(c *gin.Context) {
+	cacheControl := c.Writer.Header().Get("Cache-Control")
+	switch {
+	case cacheControl == "":
+		c.Header("Cache-Control", "private,max-age=900")
+	case strings.Contains(cacheControl, "public"):
+		c.Header("Cache-Control", strings.Replace(cacheControl, "public", "private", 1))
+	}
+}
+
+// isRestrictedCountry checks the country is in the restricted countries case-insensitively.
+// The unresolved country, i.e. empty string, is never restricted.
+func isRestrictedCountry(restricted []string, country string) bool {
+	if country == "" {
+		return false
+	}
+	for _, r := range restricted {
+		if strings.EqualFold(strings.TrimSpace(r), country) {
+			return true
+		}
+	}
+	return false
+}
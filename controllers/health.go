@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// healthCheckTimeout bounds the ping, so that the health check fails fast if MongoDB hangs
+const healthCheckTimeout = 2 * time.Second
+
+type pinger interface {
+	Ping(context.Context) error
+}
+
+// NewHealthController returns a HealthController checking the connection of the storage
+func NewHealthController(s pinger) *HealthController {
+	return &HealthController{Storage: s}
+}
+
+// HealthController serves the health check of go-api for the load balancers and the orchestrators
+type HealthController struct {
+	Storage pinger
+}
+
+// Check responds 200 if MongoDB is reachable, otherwise 503.
+func (hc *HealthController) Check(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	if err := hc.Storage.Ping(ctx); err != nil {
+		logError(errors.WithMessage(err, "health check fails"))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
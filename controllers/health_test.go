@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockPinger struct {
+	err      error
+	deadline time.Time
+}
+
+func (m *mockPinger) Ping(ctx context.Context) error {
+	m.deadline, _ = ctx.Deadline()
+	return m.err
+}
+
+func TestHealthCheck(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{name: "Given MongoDB is reachable", wantCode: http.StatusOK},
+		{name: "Given the ping fails", err: errors.New("server selection timeout"), wantCode: http.StatusServiceUnavailable},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &mockPinger{err: tc.err}
+			engine := gin.New()
+			engine.GET("/health", NewHealthController(p).Check)
+
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			if resp.Code != tc.wantCode {
+				t.Errorf("expect status %d, but got %d", tc.wantCode, resp.Code)
+			}
+			if p.deadline.IsZero() || p.deadline.After(time.Now().Add(healthCheckTimeout)) {
+				t.Errorf("expect the ping bounded by %s, but got deadline %v", healthCheckTimeout, p.deadline)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -25,20 +26,20 @@ type IndexPageQueryStruct struct {
 }
 
 // _GetIndexPageContent ...
-func (nc *NewsController) _GetIndexPageContent(part IndexPageQueryStruct) (interface{}, error) {
+func (nc *NewsController) _GetIndexPageContent(ctx context.Context, part IndexPageQueryStruct) (interface{}, error) {
 	var entities interface{}
 	var err error
 	if part.ResourceType == "topics" {
 		if part.Full {
-			entities, _, err = nc.Storage.GetFullTopics(part.MongoQuery, part.Limit, part.Offset, part.Sort, nil)
+			entities, _, err = nc.Storage.GetFullTopics(ctx, part.MongoQuery, part.Limit, part.Offset, part.Sort, nil)
 		} else {
-			entities, _, err = nc.Storage.GetMetaOfTopics(part.MongoQuery, part.Limit, part.Offset, part.Sort, nil)
+			entities, _, err = nc.Storage.GetMetaOfTopics(ctx, part.MongoQuery, part.Limit, part.Offset, part.Sort, nil)
 		}
 	} else {
 		if part.Full {
-			entities, _, err = nc.Storage.GetFullPosts(part.MongoQuery, part.Limit, part.Offset, part.Sort, nil)
+			entities, _, err = nc.Storage.GetFullPosts(ctx, part.MongoQuery, part.Limit, part.Offset, part.Sort, nil)
 		} else {
-			entities, _, err = nc.Storage.GetMetaOfPosts(part.MongoQuery, part.Limit, part.Offset, part.Sort, nil)
+			entities, _, err = nc.Storage.GetMetaOfPosts(ctx, part.MongoQuery, part.Limit, part.Offset, part.Sort, nil)
 		}
 	}
 
@@ -50,14 +51,14 @@ func (nc *NewsController) _GetIndexPageContent(part IndexPageQueryStruct) (inter
 }
 
 // _GetContentConcurrently ...
-func (nc *NewsController) _GetContentConcurrently(parts map[string]IndexPageQueryStruct) map[string]interface{} {
+func (nc *NewsController) _GetContentConcurrently(ctx context.Context, parts map[string]IndexPageQueryStruct) map[string]interface{} {
 	var ch = make(chan map[string]interface{}, len(parts))
 	var rtn = make(map[string]interface{})
 
 	for name, part := range parts {
 		// concurrently get the sections
 		go func(name string, part IndexPageQueryStruct) {
-			entities, err := nc._GetIndexPageContent(part)
+			entities, err := nc._GetIndexPageContent(ctx, part)
 			if err == nil {
 				ch <- map[string]interface{}{name: entities}
 			}
@@ -165,9 +166,10 @@ func (nc *NewsController) GetIndexPageContents(c *gin.Context) {
 		},
 	}
 
-	go func(parts map[string]IndexPageQueryStruct) {
-		ch <- nc._GetContentConcurrently(parts)
-	}(parts)
+	// the sections still being fetched are aborted once the request is done
+	go func(ctx context.Context, parts map[string]IndexPageQueryStruct) {
+		ch <- nc._GetContentConcurrently(ctx, parts)
+	}(c.Request.Context(), parts)
 	select {
 	// read the section content from channel
 	case rtn = <-ch:
@@ -210,9 +212,10 @@ func (nc *NewsController) GetCategoriesPosts(c *gin.Context) {
 		}
 	}
 
-	go func(parts map[string]IndexPageQueryStruct) {
-		ch <- nc._GetContentConcurrently(parts)
-	}(parts)
+	// the sections still being fetched are aborted once the request is done
+	go func(ctx context.Context, parts map[string]IndexPageQueryStruct) {
+		ch <- nc._GetContentConcurrently(ctx, parts)
+	}(c.Request.Context(), parts)
 	select {
 	// read the section content from channel
 	case rtn = <-ch:
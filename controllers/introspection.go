@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/utils"
+)
+
+// validateClientCredentials checks the client credentials carried by
+// either HTTP Basic authentication or `client_id`/`client_secret` form parameters.
+func validateClientCredentials(c *gin.Context) bool {
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		clientID = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+
+	return utils.ValidateIntrospectionCredentials(clientID, clientSecret)
+}
+
+// introspectToken returns the RFC 7662 introspection response of the id/access token.
+// `{"active": false}` is returned for expired, revoked or invalid tokens without any details.
+func introspectToken(tokenString string) gin.H {
+	const verifyRequired = true
+	var inactive = gin.H{"active": false}
+
+	if tokenString == "" {
+		return inactive
+	}
+
+	tokenString, err := utils.DecryptToken(tokenString)
+	if err != nil {
+		return inactive
+	}
+
+	parsedClaims, err := utils.ParseToken(tokenString, jwt.MapClaims{}, globals.Conf.App.JwtSecret)
+	if err != nil {
+		return inactive
+	}
+
+	claims := parsedClaims.(jwt.MapClaims)
+	if !claims.VerifyAudience(globals.Conf.App.JwtAudience, verifyRequired) ||
+		!claims.VerifyIssuer(globals.Conf.App.JwtIssuer, verifyRequired) {
+		return inactive
+	}
+
+	var scope string
+	switch claims["sub"] {
+	case utils.IDTokenSubject, utils.AccessTokenSubject:
+		scope = strings.ToLower(claims["sub"].(string))
+	default:
+		return inactive
+	}
+
+	resp := gin.H{
+		"active": true,
+		"scope":  scope,
+		"exp":    claims["exp"],
+		"iat":    claims["iat"],
+		"iss":    claims["iss"],
+		"aud":    claims["aud"],
+	}
+
+	if userID, ok := claims["user_id"]; ok {
+		resp["sub"] = fmt.Sprint(userID)
+	}
+
+	if email, ok := claims["email"].(string); ok && email != "" {
+		resp["username"] = email
+	}
+
+	return resp
+}
+
+// IntrospectToken handles the token introspection request(RFC 7662) from external services.
+// The request must be authenticated by the configured client credentials.
+func (mc *MembershipController) IntrospectToken(c *gin.Context) (int, gin.H, error) {
+	if !validateClientCredentials(c) {
+		c.Header("WWW-Authenticate", `Basic realm="introspect"`)
+		return http.StatusUnauthorized, gin.H{"error": "invalid_client"}, nil
+	}
+
+	return http.StatusOK, introspectToken(c.PostForm("token")), nil
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/utils"
+)
+
+func helperSetIntrospectionConf() func() {
+	defaultApp := globals.Conf.App
+	globals.Conf.App.JwtSecret = "test-secret"
+	globals.Conf.App.JwtIssuer = "test-issuer"
+	globals.Conf.App.JwtAudience = "test-audience"
+	globals.Conf.App.IntrospectionClientID = "test-client"
+	globals.Conf.App.IntrospectionClientSecret = "test-client-secret"
+
+	return func() {
+		globals.Conf.App = defaultApp
+	}
+}
+
+func TestIntrospectToken(t *testing.T) {
+	defer helperSetIntrospectionConf()()
+
+	idToken, _ := utils.RetrieveV2IDToken(1, "user@twreporter.org", "first", "last", 60)
+	expiredToken, _ := utils.RetrieveV2AccessToken(1, "user@twreporter.org", -60)
+	revokedToken, _ := utils.RetrieveV2AccessToken(2, "revoked@twreporter.org", 60)
+	utils.RevokeToken(revokedToken)
+
+	inactive := gin.H{"active": false}
+	cases := []struct {
+		name  string
+		token string
+		want  gin.H
+	}{
+		{
+			name:  "Given an invalid token",
+			token: "invalid-token",
+			want:  inactive,
+		},
+		{
+			name:  "Given an expired token",
+			token: expiredToken,
+			want:  inactive,
+		},
+		{
+			name:  "Given a revoked token",
+			token: revokedToken,
+			want:  inactive,
+		},
+		{
+			name:  "Given no token",
+			token: "",
+			want:  inactive,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := introspectToken(tc.token); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected response %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("Given a valid token", func(t *testing.T) {
+		got := introspectToken(idToken)
+		if got["active"] != true || got["sub"] != "1" || got["username"] != "user@twreporter.org" || got["scope"] != "id_token" {
+			t.Errorf("unexpected response %v", got)
+		}
+		if got["exp"] == nil || got["iat"] == nil {
+			t.Errorf("expected exp and iat in response, got %v", got)
+		}
+	})
+}
+
+func TestIntrospectTokenClientCredentials(t *testing.T) {
+	defer helperSetIntrospectionConf()()
+
+	cases := []struct {
+		name     string
+		username string
+		password string
+		want     int
+	}{
+		{
+			name: "Given no client credentials",
+			want: http.StatusUnauthorized,
+		},
+		{
+			name:     "Given invalid client credentials",
+			username: "test-client",
+			password: "invalid-secret",
+			want:     http.StatusUnauthorized,
+		},
+		{
+			name:     "Given valid client credentials",
+			username: "test-client",
+			password: "test-client-secret",
+			want:     http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodPost, "/v1/auth/introspect", strings.NewReader(url.Values{"token": {"invalid-token"}}.Encode()))
+			c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tc.username != "" {
+				c.Request.SetBasicAuth(tc.username, tc.password)
+			}
+
+			mc := &MembershipController{}
+			if statusCode, _, _ := mc.IntrospectToken(c); statusCode != tc.want {
+				t.Errorf("expected status code %d, got %d", tc.want, statusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/utils"
+)
+
+// JWKSController serves the public keys verifying the JWT issued by go-api
+type JWKSController struct{}
+
+// Retrieve responds the JSON Web Key Set(RFC 7517).
+// The key set is empty if the JWT is signed by HS256,
+// set `app.jwt_signing_method` to RS256 to let external services verify the JWT.
+func (jc JWKSController) Retrieve(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.GetJWKS())
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// ListParams are the url query params of the list endpoints
+type ListParams struct {
+	Limit  int
+	Offset int
+	// Sort is the comma separated fields, which are prefixed with `-` for descending order, e.g. `-publishedDate,updatedAt`
+	Sort string
+	Full bool
+	// Fields are the fields of the records requested, all the fields are returned if it is empty
+	Fields []string
+	// Since and Until bound the records by time, they are zero if not given
+	Since time.Time
+	Until time.Time
+}
+
+// ListParamError is returned by BindListParams if the param is invalid
+type ListParamError struct {
+	Param   string
+	Message string
+}
+
+func (e *ListParamError) Error() string {
+	return fmt.Sprintf("%s %s", e.Param, e.Message)
+}
+
+// ListParamsBinder binds the ListParams with the defaults and constraints of the endpoint
+type ListParamsBinder struct {
+	// DefaultLimit is used if neither `limit` nor `perPage` is given
+	DefaultLimit int
+	// MaxLimit clamps the limit, it is unlimited if zero
+	MaxLimit    int
+	DefaultSort string
+	// SortFields are the fields allowed in `sort`
+	SortFields []string
+	// Fields are the fields allowed in `fields`, which is ignored if the endpoint selects no fields
+	Fields []string
+}
+
+// BindListParams parses `limit`, `offset`, `sort`, `full`, `fields`, `since` and `until` url query params.
+// The records are paginated by `offset` and `limit`, or by `page`(starting from 1) and `perPage` alternatively,
+// and the limit is clamped to MaxLimit. `since` and `until` are RFC 3339 timestamps,
+// and `updatedSince` is accepted as `since` for the legacy clients.
+// *ListParamError is returned if any param is invalid.
+func (b ListParamsBinder) BindListParams(c *gin.Context) (ListParams, error) {
+	var err error
+	var hasLimit, hasOffset bool
+	params := ListParams{Sort: b.DefaultSort}
+
+	if params.Limit, hasLimit, err = parseNonNegativeQuery(c, "limit"); err != nil {
+		return ListParams{}, err
+	}
+	if params.Offset, hasOffset, err = parseNonNegativeQuery(c, "offset"); err != nil {
+		return ListParams{}, err
+	}
+
+	page, hasPage, err := parseNonNegativeQuery(c, "page")
+	if err != nil {
+		return ListParams{}, err
+	}
+	perPage, hasPerPage, err := parseNonNegativeQuery(c, "perPage")
+	if err != nil {
+		return ListParams{}, err
+	}
+
+	if hasPage || hasPerPage {
+		if page < 1 {
+			page = 1
+		}
+		if perPage == 0 {
+			perPage = params.Limit
+		}
+		if perPage == 0 {
+			perPage = b.DefaultLimit
+		}
+
+		if (hasLimit && params.Limit != perPage) || (hasOffset && params.Offset != (page-1)*perPage) {
+			return ListParams{}, &ListParamError{Param: "page", Message: errConflictingPagination.Error()}
+		}
+		params.Limit, params.Offset = perPage, (page-1)*perPage
+	}
+
+	if params.Limit == 0 {
+		params.Limit = b.DefaultLimit
+	}
+	if b.MaxLimit > 0 && params.Limit > b.MaxLimit {
+		params.Limit = b.MaxLimit
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		for _, field := range strings.Split(sort, ",") {
+			if !containsString(b.SortFields, strings.TrimPrefix(field, "-")) {
+				return ListParams{}, &ListParamError{Param: "sort", Message: fmt.Sprintf("should sort by %s", strings.Join(b.SortFields, ", "))}
+			}
+		}
+		params.Sort = sort
+	}
+
+	if full, ok := c.GetQuery("full"); ok {
+		if params.Full, err = strconv.ParseBool(full); err != nil {
+			return ListParams{}, &ListParamError{Param: "full", Message: "should be true or false"}
+		}
+	}
+
+	if fields := c.Query("fields"); fields != "" && len(b.Fields) > 0 {
+		for _, field := range strings.Split(fields, ",") {
+			if !containsString(b.Fields, field) {
+				return ListParams{}, &ListParamError{Param: "fields", Message: fmt.Sprintf("should be in %s", strings.Join(b.Fields, ", "))}
+			}
+			params.Fields = append(params.Fields, field)
+		}
+	}
+
+	for _, t := range []struct {
+		params []string
+		value  *time.Time
+	}{
+		{[]string{"since", "updatedSince"}, &params.Since},
+		{[]string{"until"}, &params.Until},
+	} {
+		for _, param := range t.params {
+			value, ok := c.GetQuery(param)
+			if !ok {
+				continue
+			}
+			if *t.value, err = time.Parse(time.RFC3339, value); err != nil {
+				return ListParams{}, &ListParamError{Param: param, Message: "should be a RFC 3339 timestamp, e.g. 2020-06-08T16:00:00Z"}
+			}
+			break
+		}
+	}
+
+	if !params.Since.IsZero() && !params.Until.IsZero() && params.Since.After(params.Until) {
+		return ListParams{}, &ListParamError{Param: "until", Message: "should not be before since"}
+	}
+
+	return params, nil
+}
+
+// parseNonNegativeQuery parses the url query param as a non-negative integer, and reports whether it is given
+func parseNonNegativeQuery(c *gin.Context, param string) (int, bool, error) {
+	value, ok := c.GetQuery(param)
+	if !ok {
+		return 0, false, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, true, &ListParamError{Param: param, Message: "should be a non-negative integer"}
+	}
+	return n, true, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// selectFields keeps only the fields of the records in their JSON representations, e.g. `slug` and `title`,
+// and the records are returned as they are if no fields are given
+func selectFields(records interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return records, nil
+	}
+
+	b, err := json.Marshal(records)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var all []map[string]json.RawMessage
+	if err = json.Unmarshal(b, &all); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	selected := make([]map[string]json.RawMessage, 0, len(all))
+	for _, record := range all {
+		s := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := record[field]; ok {
+				s[field] = value
+			}
+		}
+		selected = append(selected, s)
+	}
+	return selected, nil
+}
+
+// listParamsFailResponse responds 400 with the invalid param of the list
+func listParamsFailResponse(err error) (int, gin.H, error) {
+	if paramErr, ok := err.(*ListParamError); ok {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{paramErr.Param: paramErr.Message}}, nil
+	}
+	return toResponse(err)
+}
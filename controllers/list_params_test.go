@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindListParams(t *testing.T) {
+	binder := ListParamsBinder{
+		DefaultLimit: 10,
+		MaxLimit:     50,
+		DefaultSort:  "-publishedDate",
+		SortFields:   []string{"publishedDate", "updatedAt"},
+		Fields:       []string{"slug", "title"},
+	}
+	since := time.Date(2020, time.June, 8, 16, 0, 0, 0, time.UTC)
+	until := time.Date(2020, time.June, 9, 16, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		query     string
+		want      ListParams
+		wantParam string
+	}{
+		{name: "Given no params", query: "", want: ListParams{Limit: 10, Sort: "-publishedDate"}},
+		{name: "Given offset and limit", query: "offset=20&limit=5", want: ListParams{Limit: 5, Offset: 20, Sort: "-publishedDate"}},
+		{name: "Given zero limit", query: "limit=0", want: ListParams{Limit: 10, Sort: "-publishedDate"}},
+		{name: "Given limit over the max", query: "limit=1000", want: ListParams{Limit: 50, Sort: "-publishedDate"}},
+		{name: "Given page and perPage", query: "page=3&perPage=5", want: ListParams{Limit: 5, Offset: 10, Sort: "-publishedDate"}},
+		{name: "Given page only", query: "page=2", want: ListParams{Limit: 10, Offset: 10, Sort: "-publishedDate"}},
+		{name: "Given page with limit", query: "page=2&limit=5", want: ListParams{Limit: 5, Offset: 5, Sort: "-publishedDate"}},
+		{name: "Given page and offset agreed", query: "page=2&perPage=5&offset=5", want: ListParams{Limit: 5, Offset: 5, Sort: "-publishedDate"}},
+		{name: "Given sort", query: "sort=updatedAt,-publishedDate", want: ListParams{Limit: 10, Sort: "updatedAt,-publishedDate"}},
+		{name: "Given full", query: "full=true", want: ListParams{Limit: 10, Sort: "-publishedDate", Full: true}},
+		{name: "Given fields", query: "fields=slug,title", want: ListParams{Limit: 10, Sort: "-publishedDate", Fields: []string{"slug", "title"}}},
+		{name: "Given since and until", query: "since=2020-06-08T16:00:00Z&until=2020-06-09T16:00:00Z", want: ListParams{Limit: 10, Sort: "-publishedDate", Since: since, Until: until}},
+		{name: "Given updatedSince", query: "updatedSince=2020-06-08T16:00:00Z", want: ListParams{Limit: 10, Sort: "-publishedDate", Since: since}},
+		{name: "Given negative limit", query: "limit=-1", wantParam: "limit"},
+		{name: "Given non-numeric offset", query: "offset=abc", wantParam: "offset"},
+		{name: "Given non-numeric page", query: "page=first", wantParam: "page"},
+		{name: "Given conflicting offset", query: "page=2&perPage=5&offset=0", wantParam: "page"},
+		{name: "Given conflicting limit", query: "perPage=5&limit=10", wantParam: "page"},
+		{name: "Given sort by unknown field", query: "sort=-title", wantParam: "sort"},
+		{name: "Given invalid full", query: "full=yes", wantParam: "full"},
+		{name: "Given unknown field", query: "fields=slug,content", wantParam: "fields"},
+		{name: "Given invalid since", query: "since=yesterday", wantParam: "since"},
+		{name: "Given invalid updatedSince", query: "updatedSince=yesterday", wantParam: "updatedSince"},
+		{name: "Given since after until", query: "since=2020-06-09T16:00:00Z&until=2020-06-08T16:00:00Z", wantParam: "until"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/topics?"+tc.query, nil)
+
+			params, err := binder.BindListParams(c)
+			if tc.wantParam != "" {
+				paramErr, ok := err.(*ListParamError)
+				if !ok || paramErr.Param != tc.wantParam {
+					t.Fatalf("expect invalid %s, but got %v", tc.wantParam, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expect no error, but got %v", err)
+			}
+			if !reflect.DeepEqual(params, tc.want) {
+				t.Errorf("expect %+v, but got %+v", tc.want, params)
+			}
+		})
+	}
+}
+
+func TestListParamsFailResponse(t *testing.T) {
+	code, body, _ := listParamsFailResponse(&ListParamError{Param: "sort", Message: "should sort by updatedAt"})
+	if code != http.StatusBadRequest {
+		t.Fatalf("expect status %d, but got %d", http.StatusBadRequest, code)
+	}
+	if data := body["data"].(gin.H); data["sort"] != "should sort by updatedAt" {
+		t.Errorf("expect the invalid sort responded, but got %v", body)
+	}
+}
+
+func TestBindListParamsWithoutFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts?fields=slug", nil)
+
+	params, err := ListParamsBinder{DefaultLimit: 10}.BindListParams(c)
+	if err != nil {
+		t.Fatalf("expect fields ignored by the endpoint selecting no fields, but got %v", err)
+	}
+	if params.Fields != nil {
+		t.Errorf("expect no fields, but got %v", params.Fields)
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	records := []struct {
+		Slug  string `json:"slug"`
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}{
+		{Slug: "mock-slug", Title: "mock title", Body: "mock body"},
+	}
+
+	got, err := selectFields(records, []string{"slug", "title"})
+	if err != nil {
+		t.Fatalf("expect no error, but got %v", err)
+	}
+	b, _ := json.Marshal(got)
+	if want := `[{"slug":"mock-slug","title":"mock title"}]`; string(b) != want {
+		t.Errorf("expect %s, but got %s", want, b)
+	}
+
+	if got, _ := selectFields(records, nil); !reflect.DeepEqual(got, records) {
+		t.Errorf("expect the records kept without fields, but got %v", got)
+	}
+}
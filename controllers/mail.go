@@ -41,6 +41,16 @@ type donationSuccessReqBody struct {
 	IsAutoPay         bool     `json:"is_auto_pay"`
 }
 
+type postStateChangeReqBody struct {
+	Email     string `json:"email" binding:"required"`
+	Slug      string `json:"slug" binding:"required"`
+	Title     string `json:"title"`
+	FromState string `json:"from_state" binding:"required"`
+	ToState   string `json:"to_state" binding:"required"`
+	Note      string `json:"note"`
+	Editor    string `json:"editor"`
+}
+
 // NewMailController is used to new *MailController
 func NewMailController(svc services.MailService, t *template.Template) *MailController {
 	return &MailController{
@@ -152,6 +162,60 @@ func (contrl *MailController) SendDonationSuccessMail(c *gin.Context) (int, gin.
 	return http.StatusNoContent, gin.H{}, nil
 }
 
+// SendPostStateChangeMail notifies the admin that the post is moved to another state
+func (contrl *MailController) SendPostStateChangeMail(c *gin.Context) (int, gin.H, error) {
+	var err error
+	var failData gin.H
+	var out bytes.Buffer
+	var reqBody postStateChangeReqBody
+	var valid bool
+
+	if failData, valid = bindRequestJSONBody(c, &reqBody); valid == false {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	subject := fmt.Sprintf("文章狀態變更：%s", reqBody.Title)
+	if reqBody.Title == "" {
+		subject = fmt.Sprintf("文章狀態變更：%s", reqBody.Slug)
+	}
+
+	if err = contrl.HTMLTemplate.ExecuteTemplate(&out, "post-state-change.tmpl", reqBody); err != nil {
+		return http.StatusInternalServerError, gin.H{"status": "error", "message": "can not create post state change mail body"}, errors.WithStack(err)
+	}
+
+	if err = contrl.MailService.Send(reqBody.Email, subject, out.String()); err != nil {
+		return http.StatusInternalServerError, gin.H{"status": "error", "message": fmt.Sprintf("can not send post state change mail to %s", reqBody.Email)}, err
+	}
+
+	return http.StatusNoContent, gin.H{}, nil
+}
+
+// SendContentReportMail notifies the moderator of the content report
+func (contrl *MailController) SendContentReportMail(c *gin.Context) (int, gin.H, error) {
+	var err error
+	var out bytes.Buffer
+	var reqBody contentReportMailReqBody
+
+	if failData, valid := bindRequestJSONBody(c, &reqBody); !valid {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	subject := fmt.Sprintf("讀者檢舉文章：%s", reqBody.Title)
+	if reqBody.Title == "" {
+		subject = fmt.Sprintf("讀者檢舉文章：%s", reqBody.Slug)
+	}
+
+	if err = contrl.HTMLTemplate.ExecuteTemplate(&out, "content-report.tmpl", reqBody); err != nil {
+		return http.StatusInternalServerError, gin.H{"status": "error", "message": "can not create content report mail body"}, errors.WithStack(err)
+	}
+
+	if err = contrl.MailService.Send(reqBody.Email, subject, out.String()); err != nil {
+		return http.StatusInternalServerError, gin.H{"status": "error", "message": fmt.Sprintf("can not send content report mail to %s", reqBody.Email)}, err
+	}
+
+	return http.StatusNoContent, gin.H{}, nil
+}
+
 func postMailServiceEndpoint(reqBody interface{}, endpoint string) error {
 	var body []byte
 	var err error
@@ -165,7 +229,7 @@ func postMailServiceEndpoint(reqBody interface{}, endpoint string) error {
 	}
 
 	// Setup HTTP client with timeout
-	client := &http.Client{Timeout: timeout}
+	client := utils.NewHTTPClient(timeout)
 
 	accessToken, _ = utils.RetrieveMailServiceAccessToken(expiration)
 	req, _ := http.NewRequest("POST", endpoint, bytes.NewBuffer(body))
@@ -6,12 +6,16 @@ import (
 
 // NewMembershipController ...
 func NewMembershipController(s storage.MembershipStorage) *MembershipController {
-	return &MembershipController{s}
+	return &MembershipController{Storage: s}
 }
 
 // MembershipController ...
 type MembershipController struct {
 	Storage storage.MembershipStorage
+	// TopicStorage expands the bookmarks with their topics
+	TopicStorage topicsBySlugsGetter
+	// PostStorage validates the slugs of the imported bookmarks against the published posts
+	PostStorage postsBySlugsGetter
 }
 
 // Close is the method of Controller interface
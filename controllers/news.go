@@ -1,9 +1,12 @@
 package controllers
 
 import (
-	"strconv"
+	"fmt"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
 	"twreporter.org/go-api/models"
 	"twreporter.org/go-api/storage"
 )
@@ -15,35 +18,92 @@ type NewsController struct {
 
 // NewNewsController ...
 func NewNewsController(s storage.NewsStorage) *NewsController {
-	return &NewsController{s}
+	return &NewsController{Storage: s}
 }
 
-// GetQueryParam pares url param
-func (nc *NewsController) GetQueryParam(c *gin.Context) (err error, mq models.MongoQuery, limit int, offset int, sort string, full bool) {
-	where := c.Query("where")
-	_limit := c.Query("limit")
-	_offset := c.Query("offset")
-	_full := c.Query("full")
-	sort = c.Query("sort")
+// defaultPerPage is the number of records in a page if neither `perPage` nor `limit` is given
+const defaultPerPage = 10
 
-	// provide default param if error occurs
-	limit, _ = strconv.Atoi(_limit)
-	offset, _ = strconv.Atoi(_offset)
-	full, _ = strconv.ParseBool(_full)
+// errConflictingPagination is returned by BindListParams if `page` and `perPage` conflict with `offset` and `limit`
+var errConflictingPagination = errors.New("page and perPage conflict with offset and limit")
 
-	if limit < 0 {
-		limit = 0
-	}
+// errInvalidState is returned by GetQueryParam if `state` is not a state of the editorial workflow
+var errInvalidState = errors.New("invalid state")
 
-	if offset < 0 {
-		offset = 0
+// GetQueryParam pares url param.
+// `author` filters the posts by the comma separated author ids, models.ErrInvalidAuthorID is returned if any is malformed.
+// `expand` embeds the comma separated fields inline, models.ErrInvalidExpandField is returned if any is not expandable.
+// `state` filters the records of any state for the admins, see stateQuery.
+// The list params are bound by the binder of the endpoint, see ListParamsBinder.BindListParams,
+// and *ListParamError is returned if any is invalid, e.g. `page` and `perPage` conflicting with `offset` and `limit`.
+func (nc *NewsController) GetQueryParam(c *gin.Context, b ListParamsBinder) (err error, mq models.MongoQuery, limit int, offset int, sort string, full bool) {
+	params, err := b.BindListParams(c)
+	if err != nil {
+		return
 	}
+	limit, offset, sort, full = params.Limit, params.Offset, params.Sort, params.Full
 
+	where := c.Query("where")
 	if where == "" {
 		where = "{}"
 	}
 
-	err = models.GetQuery(where, &mq)
+	if err = models.GetQuery(where, &mq); err != nil {
+		return
+	}
+
+	if author := c.Query("author"); author != "" {
+		if err = mq.FilterByAuthors(author); err != nil {
+			return
+		}
+	}
+
+	if err = expandQuery(c, &mq); err != nil {
+		return
+	}
 
+	err = nc.stateQuery(c, &mq)
 	return
 }
+
+// stateQuery filters the records by `state` url query param, e.g. `draft`.
+// It is only permitted for the admins, which is checked by middlewares.ValidateAdmin in the router.
+func (nc *NewsController) stateQuery(c *gin.Context, mq *models.MongoQuery) error {
+	state := c.Query("state")
+	if state == "" {
+		return nil
+	}
+
+	// the records vary with the authorization, which should not be cached publicly
+	c.Header("Cache-Control", "no-store")
+
+	if !isValidPostState(state) {
+		return errInvalidState
+	}
+
+	mq.State = state
+	mq.AnyState = true
+	return nil
+}
+
+// invalidStateResponse responds 400 if `state` url query param is not a state of the editorial workflow
+func invalidStateResponse() (int, gin.H, error) {
+	return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+		"state": fmt.Sprintf("should be one of %s, %s, %s, %s or %s", postStateDraft, postStateReview, postStateScheduled, postStatePublished, postStateArchived),
+	}}, nil
+}
+
+// expandQuery sets the fields of `expand` url query param, e.g. `authors`, to be expanded by the storage
+func expandQuery(c *gin.Context, mq *models.MongoQuery) error {
+	if expand := c.Query("expand"); expand != "" {
+		return mq.Expand(expand)
+	}
+	return nil
+}
+
+// invalidExpandResponse responds 400 if `expand` url query param has any field not expandable
+func invalidExpandResponse() (int, gin.H, error) {
+	return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+		"expand": fmt.Sprintf("should be %s", models.ExpandAuthors),
+	}}, nil
+}
@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+)
+
+func TestGetQueryParamPagination(t *testing.T) {
+	cases := []struct {
+		name       string
+		query      string
+		wantParam  string
+		wantLimit  int
+		wantOffset int
+	}{
+		{name: "Given offset and limit", query: "offset=20&limit=10", wantLimit: 10, wantOffset: 20},
+		{name: "Given page and perPage", query: "page=3&perPage=10", wantLimit: 10, wantOffset: 20},
+		{name: "Given page only", query: "page=2", wantLimit: defaultPerPage, wantOffset: defaultPerPage},
+		{name: "Given page and limit", query: "page=2&limit=5", wantLimit: 5, wantOffset: 5},
+		{name: "Given both styles agreed", query: "page=3&perPage=10&offset=20&limit=10", wantLimit: 10, wantOffset: 20},
+		{name: "Given no pagination", query: "", wantLimit: defaultPerPage, wantOffset: 0},
+		{name: "Given conflicting offset", query: "page=3&perPage=10&offset=10", wantParam: "page"},
+		{name: "Given conflicting limit", query: "perPage=10&limit=20", wantParam: "page"},
+		{name: "Given invalid full", query: "full=yes", wantParam: "full"},
+	}
+
+	nc := NewNewsController(nil)
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts?"+tc.query, nil)
+
+			err, _, limit, offset, _, _ := nc.GetQueryParam(c, postListParams)
+			if tc.wantParam != "" {
+				if paramErr, ok := err.(*ListParamError); !ok || paramErr.Param != tc.wantParam {
+					t.Fatalf("expect invalid %s, but got %v", tc.wantParam, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, but got %v", err)
+			}
+			if limit != tc.wantLimit || offset != tc.wantOffset {
+				t.Errorf("expect limit %d and offset %d, but got %d and %d", tc.wantLimit, tc.wantOffset, limit, offset)
+			}
+		})
+	}
+}
+
+func TestGetPostsConflictingPagination(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts?page=2&perPage=10&offset=0", nil)
+
+	code, body, _ := NewNewsController(nil).GetPosts(c)
+	if code != http.StatusBadRequest {
+		t.Fatalf("expect status %d, but got %d", http.StatusBadRequest, code)
+	}
+	if body["status"] != "fail" {
+		t.Errorf("expect status fail, but got %v", body)
+	}
+}
+
+func TestGetQueryParamAuthor(t *testing.T) {
+	cases := []struct {
+		name        string
+		query       string
+		wantErr     error
+		wantAuthors int
+	}{
+		{name: "Given no author", query: "", wantAuthors: 0},
+		{name: "Given an author", query: "author=5edf118c3e631f0600c9e5ca", wantAuthors: 4},
+		{name: "Given comma separated authors", query: "author=5edf118c3e631f0600c9e5ca,5edf118c3e631f0600c9e5cb", wantAuthors: 4},
+		{name: "Given a malformed author", query: "author=mock-author", wantErr: models.ErrInvalidAuthorID},
+		{name: "Given an empty author in the list", query: "author=5edf118c3e631f0600c9e5ca,", wantErr: models.ErrInvalidAuthorID},
+	}
+
+	nc := NewNewsController(nil)
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts?"+tc.query, nil)
+
+			err, mq, _, _, _, _ := nc.GetQueryParam(c, postListParams)
+			if err != tc.wantErr {
+				t.Fatalf("expect error %v, but got %v", tc.wantErr, err)
+			}
+			if len(mq.Authors) != tc.wantAuthors {
+				t.Errorf("expect %d author conditions, but got %v", tc.wantAuthors, mq.Authors)
+			}
+		})
+	}
+}
+
+func TestGetPostsInvalidAuthor(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts?author=mock-author", nil)
+
+	code, body, _ := NewNewsController(nil).GetPosts(c)
+	if code != http.StatusBadRequest {
+		t.Fatalf("expect status %d, but got %d", http.StatusBadRequest, code)
+	}
+	if body["error"] != "invalid author ID format" {
+		t.Errorf("expect error invalid author ID format, but got %v", body)
+	}
+}
+
+func TestGetQueryParamExpand(t *testing.T) {
+	cases := []struct {
+		name       string
+		query      string
+		wantErr    error
+		wantExpand bool
+	}{
+		{name: "Given no expand", query: ""},
+		{name: "Given expand authors", query: "expand=authors", wantExpand: true},
+		{name: "Given an unknown expand", query: "expand=tags", wantErr: models.ErrInvalidExpandField},
+		{name: "Given an unknown expand in the list", query: "expand=authors,tags", wantErr: models.ErrInvalidExpandField},
+	}
+
+	nc := NewNewsController(nil)
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts?"+tc.query, nil)
+
+			err, mq, _, _, _, _ := nc.GetQueryParam(c, postListParams)
+			if err != tc.wantErr {
+				t.Fatalf("expect error %v, but got %v", tc.wantErr, err)
+			}
+			if mq.IsExpanded(models.ExpandAuthors) != tc.wantExpand {
+				t.Errorf("expect authors expanded %t, but got %v", tc.wantExpand, mq.ExpandFields)
+			}
+		})
+	}
+}
+
+func TestGetPostsInvalidExpand(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts?expand=comments", nil)
+
+	code, body, _ := NewNewsController(nil).GetPosts(c)
+	if code != http.StatusBadRequest {
+		t.Fatalf("expect status %d, but got %d", http.StatusBadRequest, code)
+	}
+	if body["status"] != "fail" {
+		t.Errorf("expect status fail, but got %v", body)
+	}
+}
+
+func TestGetQueryParamState(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		wantErr   error
+		wantState string
+	}{
+		{name: "Given no state", query: ""},
+		{name: "Given drafts", query: "state=draft", wantState: "draft"},
+		{name: "Given an unknown state", query: "state=deleted", wantErr: errInvalidState},
+	}
+
+	nc := NewNewsController(nil)
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts?"+tc.query, nil)
+
+			err, mq, _, _, _, _ := nc.GetQueryParam(c, postListParams)
+			if err != tc.wantErr {
+				t.Fatalf("expect error %v, but got %v", tc.wantErr, err)
+			}
+			if mq.State != tc.wantState || mq.AnyState != (tc.wantState != "") {
+				t.Errorf("expect state %q of any state, but got %q(%t)", tc.wantState, mq.State, mq.AnyState)
+			}
+			if tc.query != "" && resp.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("expect the state not cached publicly, but got Cache-Control %q", resp.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestGetPostsByYearMonthInvalidDate(t *testing.T) {
+	cases := []struct {
+		name      string
+		year      string
+		month     string
+		wantParam string
+	}{
+		{name: "Given a year before 2000", year: "1999", month: "12", wantParam: "year"},
+		{name: "Given a malformed year", year: "mock-year", month: "06", wantParam: "year"},
+		{name: "Given month 0", year: "2020", month: "0", wantParam: "month"},
+		{name: "Given month 13", year: "2020", month: "13", wantParam: "month"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts/by-date/"+tc.year+"/"+tc.month, nil)
+			c.Params = gin.Params{{Key: "year", Value: tc.year}, {Key: "month", Value: tc.month}}
+
+			code, body, _ := NewNewsController(nil).GetPostsByYearMonth(c)
+			if code != http.StatusBadRequest {
+				t.Fatalf("expect status %d, but got %d", http.StatusBadRequest, code)
+			}
+			if _, ok := body["data"].(gin.H)[tc.wantParam]; !ok {
+				t.Errorf("expect %s to be invalid, but got %v", tc.wantParam, body)
+			}
+		})
+	}
+}
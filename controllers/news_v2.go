@@ -33,7 +33,7 @@ type newsV2Controller struct {
 func (nc *newsV2Controller) GetPosts(c *gin.Context) {
 	var err error
 
-	ctx, cancel := context.WithTimeout(c, globals.Conf.News.PostPageTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), globals.Conf.News.PostPageTimeout)
 	defer cancel()
 
 	defer func() {
@@ -55,18 +55,15 @@ func (nc *newsV2Controller) GetPosts(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": posts, "meta": gin.H{
-		"total":  total,
-		"offset": q.Offset,
-		"limit":  q.Limit,
-	}}})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": posts, "meta": newMetaOfResponse(total, q.Offset, q.Limit)}})
 }
 
 func (nc *newsV2Controller) GetAPost(c *gin.Context) {
 	var post interface{}
+	var restricted []string
 	var err error
 
-	ctx, cancel := context.WithTimeout(c, globals.Conf.News.PostPageTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), globals.Conf.News.PostPageTimeout)
 	defer cancel()
 
 	defer func() {
@@ -82,12 +79,14 @@ func (nc *newsV2Controller) GetAPost(c *gin.Context) {
 		posts, err = nc.Storage.GetFullPosts(ctx, q)
 		if len(posts) > 0 {
 			post = posts[0]
+			restricted = posts[0].RestrictedCountries
 		}
 	} else {
 		var posts []news.MetaOfPost
 		posts, err = nc.Storage.GetMetaOfPosts(ctx, q)
 		if len(posts) > 0 {
 			post = posts[0]
+			restricted = posts[0].RestrictedCountries
 		}
 	}
 
@@ -100,13 +99,18 @@ func (nc *newsV2Controller) GetAPost(c *gin.Context) {
 		return
 	}
 
+	if failBody, available := checkPostCountry(c, c.Param("slug"), restricted); !available {
+		c.JSON(http.StatusUnavailableForLegalReasons, failBody)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"status": "success", "data": post})
 }
 
 func (nc *newsV2Controller) GetTopics(c *gin.Context) {
 	var err error
 
-	ctx, cancel := context.WithTimeout(c, globals.Conf.News.TopicPageTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), globals.Conf.News.TopicPageTimeout)
 	defer cancel()
 
 	defer func() {
@@ -128,18 +132,14 @@ func (nc *newsV2Controller) GetTopics(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": topics, "meta": gin.H{
-		"total":  total,
-		"offset": q.Offset,
-		"limit":  q.Limit,
-	}}})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": topics, "meta": newMetaOfResponse(total, q.Offset, q.Limit)}})
 }
 
 func (nc *newsV2Controller) GetATopic(c *gin.Context) {
 	var topic interface{}
 	var err error
 
-	ctx, cancel := context.WithTimeout(c, globals.Conf.News.TopicPageTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), globals.Conf.News.TopicPageTimeout)
 	defer cancel()
 
 	defer func() {
@@ -195,7 +195,7 @@ const (
 )
 
 func (nc *newsV2Controller) GetIndexPage(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c, globals.Conf.News.IndexPageTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), globals.Conf.News.IndexPageTimeout)
 	defer cancel()
 
 	jobs := nc.getIndexPageJobs()
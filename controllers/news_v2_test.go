@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/internal/news"
+)
+
+type mockNewsV2Storage struct {
+	post news.Post
+}
+
+func (s *mockNewsV2Storage) GetFullPosts(ctx context.Context, q *news.Query) ([]news.Post, error) {
+	if q.Filter.Slug != s.post.Slug {
+		return nil, nil
+	}
+	return []news.Post{s.post}, nil
+}
+
+func (s *mockNewsV2Storage) GetMetaOfPosts(ctx context.Context, q *news.Query) ([]news.MetaOfPost, error) {
+	if q.Filter.Slug != s.post.Slug {
+		return nil, nil
+	}
+	return []news.MetaOfPost{s.post.MetaOfPost}, nil
+}
+
+func (s *mockNewsV2Storage) GetFullTopics(ctx context.Context, q *news.Query) ([]news.Topic, error) {
+	return nil, nil
+}
+
+func (s *mockNewsV2Storage) GetMetaOfTopics(ctx context.Context, q *news.Query) ([]news.MetaOfTopic, error) {
+	return nil, nil
+}
+
+func (s *mockNewsV2Storage) GetPostCount(ctx context.Context, q *news.Query) (int, error) {
+	return 0, nil
+}
+
+func (s *mockNewsV2Storage) GetTopicCount(ctx context.Context, q *news.Query) (int, error) {
+	return 0, nil
+}
+
+func TestGetAPostV2RestrictedCountries(t *testing.T) {
+	defaultNews := globals.Conf.News
+	globals.Conf.News.PostPageTimeout = time.Second
+	defer func() { globals.Conf.News = defaultNews }()
+
+	s := &mockNewsV2Storage{post: news.Post{MetaOfPost: news.MetaOfPost{Slug: "restricted-post", RestrictedCountries: []string{"CN"}}}}
+
+	cases := []struct {
+		name     string
+		country  string
+		full     bool
+		wantCode int
+	}{
+		{name: "Given the client country is restricted", country: "CN", wantCode: http.StatusUnavailableForLegalReasons},
+		{name: "Given the client country is restricted with full content", country: "CN", full: true, wantCode: http.StatusUnavailableForLegalReasons},
+		{name: "Given the client country is not restricted", country: "TW", full: true, wantCode: http.StatusOK},
+		{name: "Given the client country is unresolved", full: true, wantCode: http.StatusOK},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.GET("/v2/posts/:slug", func(c *gin.Context) {
+				c.Header("Cache-Control", "public,max-age=900")
+				if tc.country != "" {
+					c.Set(globals.ClientCountryProperty, tc.country)
+				}
+			}, NewNewsV2Controller(s).GetAPost)
+
+			url := "/v2/posts/restricted-post"
+			if tc.full {
+				url += "?full=true"
+			}
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, url, nil))
+
+			if resp.Code != tc.wantCode {
+				t.Errorf("expect status %d, but got %d", tc.wantCode, resp.Code)
+			}
+			if got := resp.Header().Get("Cache-Control"); got != "private,max-age=900" {
+				t.Errorf("expect Cache-Control private,max-age=900, but got %q", got)
+			}
+		})
+	}
+}
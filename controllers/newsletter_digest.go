@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+)
+
+const (
+	digestPostsLimit = 10
+	digestCacheTTL   = time.Hour
+	digestMaxWindow  = 31 * 24 * time.Hour
+	// digestOthers is the group of the posts without category
+	digestOthers = "others"
+)
+
+// digestPost is the post rendered in the newsletter template
+type digestPost struct {
+	Title     string        `json:"title"`
+	Slug      string        `json:"slug"`
+	HeroImage *models.Image `json:"hero_image"`
+	Excerpt   string        `json:"excerpt"`
+	ViewCount int           `json:"view_count"`
+}
+
+// digestCategory groups the posts of the digest by their first category
+type digestCategory struct {
+	ID    string       `json:"id"`
+	Name  string       `json:"name"`
+	Posts []digestPost `json:"posts"`
+}
+
+type newsletterDigest struct {
+	Window     string           `json:"window"`
+	Since      time.Time        `json:"since"`
+	Categories []digestCategory `json:"categories"`
+}
+
+type digestCacheEntry struct {
+	digest    newsletterDigest
+	expiresAt time.Time
+}
+
+// digestCache caches the digest of each window duration for `digestCacheTTL`
+var digestCache = struct {
+	sync.Mutex
+	entries map[string]digestCacheEntry
+}{entries: make(map[string]digestCacheEntry)}
+
+// parseDigestWindow parses the window of the digest, which is at most `digestMaxWindow`
+func parseDigestWindow(window string) (time.Duration, error) {
+	return parseWindow(window, digestMaxWindow)
+}
+
+// parseWindow parses the window in days(e.g. `7d`) or in Go duration(e.g. `24h`),
+// which should be positive and not longer than max.
+func parseWindow(window string, max time.Duration) (time.Duration, error) {
+	var d time.Duration
+
+	if strings.HasSuffix(window, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(window, "d"))
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(window); err != nil {
+			return 0, err
+		}
+	}
+
+	if d <= 0 || d > max {
+		return 0, fmt.Errorf("window %s is out of range", window)
+	}
+	return d, nil
+}
+
+// buildNewsletterDigest groups the ranked posts by their first category,
+// the categories are ordered by their most viewed post.
+func buildNewsletterDigest(posts []models.Post) []digestCategory {
+	var categories = make([]digestCategory, 0)
+	var indexes = make(map[string]int)
+
+	for _, post := range posts {
+		category := digestCategory{ID: digestOthers, Name: digestOthers}
+		if len(post.Categories) > 0 {
+			category = digestCategory{ID: post.Categories[0].ID.Hex(), Name: post.Categories[0].Name}
+		}
+
+		i, ok := indexes[category.ID]
+		if !ok {
+			i = len(categories)
+			indexes[category.ID] = i
+			categories = append(categories, category)
+		}
+
+		categories[i].Posts = append(categories[i].Posts, digestPost{
+			Title:     post.Title,
+			Slug:      post.Slug,
+			HeroImage: post.HeroImage,
+			Excerpt:   post.OgDescription,
+			ViewCount: post.ViewCount,
+		})
+	}
+
+	return categories
+}
+
+// GetNewsletterDigest returns the top viewed posts published within `window`, grouped by category,
+// for the newsletter template. The digest is cached for an hour.
+func (nc *NewsController) GetNewsletterDigest(c *gin.Context) (int, gin.H, error) {
+	window := c.DefaultQuery("window", "7d")
+	d, err := parseDigestWindow(window)
+	if err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"window": "should be a duration in days or hours within 31 days, e.g. 7d or 24h",
+		}}, nil
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("public,max-age=%d", int(digestCacheTTL.Seconds())))
+
+	digestCache.Lock()
+	entry, ok := digestCache.entries[d.String()]
+	digestCache.Unlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return http.StatusOK, gin.H{"status": "success", "data": entry.digest}, nil
+	}
+
+	since := time.Now().Add(-d)
+	mq := models.MongoQuery{
+		State:         postStatePublished,
+		PublishedDate: models.MongoQueryTimeComparison{GT: since},
+	}
+
+	posts, _, err := nc.Storage.GetMetaOfPosts(c.Request.Context(), mq, digestPostsLimit, 0, "-viewCount", []string{"hero_image", "categories"})
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	digest := newsletterDigest{
+		Window:     window,
+		Since:      since,
+		Categories: buildNewsletterDigest(posts),
+	}
+
+	digestCache.Lock()
+	digestCache.entries[d.String()] = digestCacheEntry{digest: digest, expiresAt: time.Now().Add(digestCacheTTL)}
+	digestCache.Unlock()
+
+	return http.StatusOK, gin.H{"status": "success", "data": digest}, nil
+}
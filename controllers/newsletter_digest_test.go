@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+func TestParseDigestWindow(t *testing.T) {
+	cases := []struct {
+		window  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{window: "7d", want: 7 * 24 * time.Hour},
+		{window: "24h", want: 24 * time.Hour},
+		{window: "0d", wantErr: true},
+		{window: "32d", wantErr: true},
+		{window: "a week", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.window, func(t *testing.T) {
+			got, err := parseDigestWindow(tc.window)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildNewsletterDigest(t *testing.T) {
+	review := models.Category{ID: bson.NewObjectId(), Name: "review"}
+	photography := models.Category{ID: bson.NewObjectId(), Name: "photography"}
+
+	posts := []models.Post{
+		{Slug: "post-1", ViewCount: 300, Categories: []models.Category{review}},
+		{Slug: "post-2", ViewCount: 200, Categories: []models.Category{photography, review}},
+		{Slug: "post-3", ViewCount: 100},
+		{Slug: "post-4", ViewCount: 50, Categories: []models.Category{review}},
+	}
+
+	var got = make(map[string][]string)
+	var order []string
+	for _, category := range buildNewsletterDigest(posts) {
+		order = append(order, category.Name)
+		for _, post := range category.Posts {
+			got[category.Name] = append(got[category.Name], post.Slug)
+		}
+	}
+
+	if want := []string{"review", "photography", digestOthers}; !reflect.DeepEqual(order, want) {
+		t.Errorf("expected categories %v, got %v", want, order)
+	}
+
+	want := map[string][]string{
+		"review":      {"post-1", "post-4"},
+		"photography": {"post-2"},
+		digestOthers:  {"post-3"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected posts %v, got %v", want, got)
+	}
+}
@@ -1,11 +1,15 @@
 package controllers
 
 import (
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/sessions"
@@ -18,14 +22,25 @@ import (
 	"golang.org/x/oauth2/google"
 	"gopkg.in/guregu/null.v3"
 
+	"twreporter.org/go-api/controllers/oauth/apple"
+	"twreporter.org/go-api/controllers/oauth/linkedin"
 	"twreporter.org/go-api/globals"
 	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/services"
 	"twreporter.org/go-api/storage"
 	"twreporter.org/go-api/utils"
 )
 
 const defaultDestination = "https://www.twreporter.org/"
 
+// facebookDataRefreshInterval is the interval the stored facebook profile is refreshed at most once within
+const facebookDataRefreshInterval = 24 * time.Hour
+
+const (
+	facebookUserInfoEndpoint    = "https://graph.facebook.com/v3.2/me?fields=id,name,email,picture,birthday,first_name,last_name,gender"
+	facebookPermissionsEndpoint = "https://graph.facebook.com/v3.2/me/permissions"
+)
+
 type basicInfo struct {
 	Email  null.String `json:"email"`
 	Name   null.String `json:"name"`
@@ -69,7 +84,7 @@ func (info *googleOauthInfoRaw) Gender() null.String {
 // 1. state
 // 2. destination(go to page)
 // and redirect users to oauth server.
-func beginAuth(c *gin.Context, conf *oauth2.Config) {
+func beginAuth(c *gin.Context, conf *oauth2.Config, opts ...oauth2.AuthCodeOption) {
 	var state string
 	var err error
 
@@ -78,6 +93,11 @@ func beginAuth(c *gin.Context, conf *oauth2.Config) {
 		destination = defaultDestination
 	}
 
+	if _, err = validateDestination(destination); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"destination": err.Error()}})
+		return
+	}
+
 	if state, err = utils.GenerateRandomString(32); err != nil {
 		state = "twreporter-oauth-state"
 	}
@@ -87,31 +107,87 @@ func beginAuth(c *gin.Context, conf *oauth2.Config) {
 	session.Set("destination", destination)
 	session.Save()
 
-	url := conf.AuthCodeURL(state)
+	url := conf.AuthCodeURL(state, opts...)
 
 	c.Redirect(http.StatusTemporaryRedirect, url)
 }
 
-// getOauthUserInfo does the following three things
-// 1. validate state
-// 2. exchange code to token
-// 3. get user info from oauth server by token
-func getOauthUserInfo(c *gin.Context, conf *oauth2.Config, userInfoEndpoint string, oauthUser interface{}) error {
+// validateDestination checks the destination, which users are redirected to after authentication, is an absolute http(s) URL
+// of `app.domain` or its subdomains, so that a relative path, a `javascript:` URL or another site never reaches the redirection.
+func validateDestination(destination string) (*url.URL, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, errors.Wrap(err, "should be a valid URL")
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.New(fmt.Sprintf("should be an absolute http or https URL, but got %s", destination))
+	}
+
+	if u.Host == "" {
+		return nil, errors.New(fmt.Sprintf("should be an absolute URL with host, but got %s", destination))
+	}
+
+	domain := globals.Conf.App.Domain
+	if hostname := u.Hostname(); hostname != domain && !strings.HasSuffix(hostname, "."+domain) {
+		return nil, errors.New(fmt.Sprintf("should be a URL of %s or its subdomains, but got %s", domain, destination))
+	}
+	return u, nil
+}
+
+// validateState checks the state returned from oauth server is the one stored in the session
+func validateState(c *gin.Context, state string) error {
 	session := sessions.Default(c)
 	retrievedState := session.Get("state")
-	state := c.Query("state")
 	if state != retrievedState {
-		return errors.New(fmt.Sprintf("expect state is %s, but actual state is %s", retrievedState, state))
+		return errors.WithStack(&models.AppError{
+			Code:       models.ErrCodeOAuthStateInvalid,
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("expect state is %s, but actual state is %s", retrievedState, state),
+		})
 	}
+	return nil
+}
+
+// newExchangeError returns the error of exchanging the code to token with oauth server
+func newExchangeError(err error) error {
+	return errors.WithStack(&models.AppError{
+		Code:       models.ErrCodeOAuthExchangeFailed,
+		StatusCode: http.StatusBadGateway,
+		Message:    "cannot exchange the code to token",
+		Err:        err,
+	})
+}
+
+// getOauthContext returns the context carrying the http client for the outbound requests to oauth server
+func getOauthContext() context.Context {
+	return context.WithValue(oauth2.NoContext, oauth2.HTTPClient, utils.NewHTTPClient(0))
+}
 
+// getOauthClient does the following two things
+// 1. validate state
+// 2. exchange code to token
+// and returns the http client carrying the token
+func getOauthClient(c *gin.Context, conf *oauth2.Config) (*http.Client, error) {
+	if err := validateState(c, c.Query("state")); err != nil {
+		return nil, err
+	}
+
+	ctx := getOauthContext()
 	code := c.Query("code")
-	token, err := conf.Exchange(oauth2.NoContext, code)
+	token, err := conf.Exchange(ctx, code)
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, newExchangeError(err)
 	}
 
-	client := conf.Client(oauth2.NoContext, token)
-	response, err := client.Get(userInfoEndpoint)
+	return conf.Client(ctx, token), nil
+}
+
+// getRemoteUserData gets data from oauth server by the client carrying the token.
+// The transport only decompresses the responses to the requests it adds `Accept-Encoding` to,
+// so the gzip encoded body is decoded here if the server compresses the response anyway.
+func getRemoteUserData(client *http.Client, endpoint string, data interface{}) error {
+	response, err := client.Get(endpoint)
 
 	if err != nil {
 		return errors.WithStack(err)
@@ -119,24 +195,177 @@ func getOauthUserInfo(c *gin.Context, conf *oauth2.Config, userInfoEndpoint stri
 
 	defer response.Body.Close()
 
-	userInfo, err := ioutil.ReadAll(response.Body)
+	var reader io.Reader = response.Body
+	if strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		gr, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer gr.Close()
+		reader = gr
+	}
+
+	body, err := ioutil.ReadAll(reader)
 
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	if err = json.Unmarshal(userInfo, &oauthUser); err != nil {
+	if err = json.Unmarshal(body, data); err != nil {
 		return errors.WithStack(err)
 	}
 
 	return nil
 }
 
+// getOauthUserInfo does the following three things
+// 1. validate state
+// 2. exchange code to token
+// 3. get user info from oauth server by token
+func getOauthUserInfo(c *gin.Context, conf *oauth2.Config, userInfoEndpoint string, oauthUser interface{}) error {
+	client, err := getOauthClient(c, conf)
+	if err != nil {
+		return err
+	}
+
+	return getRemoteUserData(client, userInfoEndpoint, oauthUser)
+}
+
+// getLinkedInUserInfo gets the profile and the email address from LinkedIn,
+// which are provided by two different endpoints
+func getLinkedInUserInfo(c *gin.Context, conf *oauth2.Config) (oauthUser models.OAuthAccount, err error) {
+	var client *http.Client
+	var email linkedin.EmailRaw
+	var profile linkedin.ProfileRaw
+
+	if client, err = getOauthClient(c, conf); err != nil {
+		return oauthUser, err
+	}
+
+	if err = getRemoteUserData(client, linkedin.ProfileEndpoint, &profile); err != nil {
+		return oauthUser, err
+	}
+
+	if err = getRemoteUserData(client, linkedin.EmailEndpoint, &email); err != nil {
+		return oauthUser, err
+	}
+
+	return linkedin.ToOAuthAccount(profile, email), nil
+}
+
+// getFacebookUserInfo gets the user info from Facebook after confirming the required scopes were granted,
+// since the user can decline some of the permissions on the login dialog.
+func getFacebookUserInfo(c *gin.Context, conf *oauth2.Config, oauthInfo *facebookOauthInfoRaw) error {
+	client, err := getOauthClient(c, conf)
+	if err != nil {
+		return err
+	}
+
+	if err = checkFacebookPermissions(client, facebookPermissionsEndpoint, conf.Scopes); err != nil {
+		return err
+	}
+
+	return getRemoteUserData(client, facebookUserInfoEndpoint, oauthInfo)
+}
+
+// checkFacebookPermissions returns the error listing the required scopes not granted by the user
+func checkFacebookPermissions(client *http.Client, endpoint string, required []string) error {
+	var permissions struct {
+		Data []struct {
+			Permission string `json:"permission"`
+			Status     string `json:"status"`
+		} `json:"data"`
+	}
+
+	if err := getRemoteUserData(client, endpoint, &permissions); err != nil {
+		return err
+	}
+
+	granted := make(map[string]bool)
+	for _, p := range permissions.Data {
+		granted[p.Permission] = p.Status == "granted"
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.WithStack(&models.AppError{
+			Code:       models.ErrCodeOAuthScopesNotGranted,
+			StatusCode: http.StatusForbidden,
+			Message:    fmt.Sprintf("facebook permissions are not granted: %s", strings.Join(missing, ", ")),
+		})
+	}
+	return nil
+}
+
+// getAppleUserInfo does the following things
+// 1. validate state posted by apple
+// 2. exchange code to token with the client secret generated from private key
+// 3. get user info from id token and `user` field, which is only posted on the first authentication
+func getAppleUserInfo(c *gin.Context, conf *oauth2.Config) (oauthUser models.OAuthAccount, err error) {
+	var claims apple.IDTokenClaims
+	var secret string
+	var token *oauth2.Token
+	var user apple.User
+
+	if err = validateState(c, c.PostForm("state")); err != nil {
+		return oauthUser, err
+	}
+
+	appleSettings := globals.Conf.Oauth.Apple
+	if secret, err = apple.GenerateClientSecret(appleSettings.TeamID, appleSettings.ClientID, appleSettings.KeyID, appleSettings.PrivateKeyPath); err != nil {
+		return oauthUser, err
+	}
+
+	exchangeConf := *conf
+	exchangeConf.ClientSecret = secret
+	if token, err = exchangeConf.Exchange(getOauthContext(), c.PostForm("code")); err != nil {
+		return oauthUser, newExchangeError(err)
+	}
+
+	idToken, _ := token.Extra("id_token").(string)
+	if claims, err = apple.ParseIDToken(idToken, conf.ClientID); err != nil {
+		return oauthUser, err
+	}
+
+	if rawUser := c.PostForm("user"); rawUser != "" {
+		if err = json.Unmarshal([]byte(rawUser), &user); err != nil {
+			return oauthUser, errors.WithStack(err)
+		}
+	}
+
+	return apple.ToOAuthAccount(claims, user), nil
+}
+
+// errRegistrationClosed is returned by findOrCreateUser if the oauth user is unknown and the auto registration is disabled
+var errRegistrationClosed = &models.AppError{
+	Code:       models.ErrCodeRegistrationClosed,
+	StatusCode: http.StatusForbidden,
+	Message:    "registration closed",
+}
+
+// errAccountBlocked is returned if the oauth user is blocked by the admins
+var errAccountBlocked = &models.AppError{
+	Code:       models.ErrCodeAccountBlocked,
+	StatusCode: http.StatusForbidden,
+	Message:    "account blocked",
+}
+
 // In order to avoid from storing user info repeatedly,
 // findOrCreateUser handles how to store oauth users in the storage.
+// If globals.Conf.Oauth.AllowAutoRegister is false, the users are never created,
+// and errRegistrationClosed is returned for the oauth user matching no existing user.
+// The stored facebook profile is not updated if it is updated within facebookDataRefreshInterval.
 func findOrCreateUser(oauthUser models.OAuthAccount, ms storage.MembershipStorage) (user models.User, err error) {
+	var storedOAuthUser models.OAuthAccount
+
 	// get the record from o_auth_accounts table
-	_, err = ms.GetOAuthData(oauthUser.AId, oauthUser.Type)
+	storedOAuthUser, err = ms.GetOAuthData(oauthUser.AId, oauthUser.Type)
 
 	// oAuth account is not existed
 	// sign in by oauth for the first time
@@ -156,6 +385,10 @@ func findOrCreateUser(oauthUser models.OAuthAccount, ms storage.MembershipStorag
 					return user, err
 				}
 
+				if !globals.Conf.Oauth.AllowAutoRegister {
+					return user, errors.WithStack(errRegistrationClosed)
+				}
+
 				// no record in users table with this email
 				// create a record in users table
 				// and create a record in o_auth_accounts table
@@ -172,6 +405,10 @@ func findOrCreateUser(oauthUser models.OAuthAccount, ms storage.MembershipStorag
 				}
 			}
 		} else {
+			if !globals.Conf.Oauth.AllowAutoRegister {
+				return user, errors.WithStack(errRegistrationClosed)
+			}
+
 			// email is not provided in oAuth response
 			// create a record in users table
 			// and also create a record in o_auth_accounts table
@@ -185,9 +422,18 @@ func findOrCreateUser(oauthUser models.OAuthAccount, ms storage.MembershipStorag
 			return user, err
 		}
 
-		// update existing OAuth data
+		// skip the writes of the facebook profile unless it is stale
+		if oauthUser.Type == globals.FacebookOAuth && time.Since(storedOAuthUser.UpdatedAt) < facebookDataRefreshInterval {
+			return user, nil
+		}
+
+		// update existing OAuth data,
+		// the conflict means another login refreshes the data concurrently, which does not fail the sign-in
 		if _, err = ms.UpdateOAuthData(oauthUser); err != nil {
-			return user, err
+			if !storage.IsConflict(err) {
+				return user, err
+			}
+			log.Infof("%+v", err)
 		}
 	}
 
@@ -196,8 +442,12 @@ func findOrCreateUser(oauthUser models.OAuthAccount, ms storage.MembershipStorag
 
 // OAuth which stores storage connection and oauth config
 type OAuth struct {
-	Storage   storage.MembershipStorage
-	oauthConf *oauth2.Config
+	Storage         storage.MembershipStorage
+	oauthConf       *oauth2.Config
+	authCodeOptions []oauth2.AuthCodeOption
+	logSampler      *utils.LogSampler
+	// avatarService stores the resized variants of the avatars, nil if it is disabled
+	avatarService *services.AvatarService
 }
 
 // InitGoogleConfig initiates facebook oauth config
@@ -208,7 +458,7 @@ func (o *OAuth) InitFacebookConfig() {
 		ClientID:     globals.Conf.Oauth.Facebook.ID,
 		ClientSecret: globals.Conf.Oauth.Facebook.Secret,
 		RedirectURL:  redirectURL,
-		Scopes:       []string{"public_profile", "email"},
+		Scopes:       globals.Conf.Oauth.Facebook.Scopes,
 		Endpoint:     facebook.Endpoint,
 	}
 }
@@ -230,16 +480,139 @@ func (o *OAuth) InitGoogleConfig() {
 	}
 }
 
-// BeginAuth redirects user to the [facebook|google] authentication(login) page
+// InitLinkedInConfig initiates linkedin oauth config
+func (o *OAuth) InitLinkedInConfig() {
+	appsettings := globals.Conf.App
+	redirectURL := fmt.Sprintf("%s://%s:%s/v2/auth/linkedin/callback", appsettings.Protocol, appsettings.Host, appsettings.Port)
+	o.oauthConf = &oauth2.Config{
+		ClientID:     globals.Conf.Oauth.LinkedIn.ID,
+		ClientSecret: globals.Conf.Oauth.LinkedIn.Secret,
+		RedirectURL:  redirectURL,
+		Scopes:       linkedin.Scopes,
+		Endpoint:     linkedin.Endpoint,
+	}
+}
+
+// InitAppleConfig initiates apple oauth config.
+// The client secret is generated from the private key while exchanging token.
+func (o *OAuth) InitAppleConfig() {
+	appsettings := globals.Conf.App
+	redirectURL := fmt.Sprintf("%s://%s:%s/v2/auth/apple/callback", appsettings.Protocol, appsettings.Host, appsettings.Port)
+	o.oauthConf = &oauth2.Config{
+		ClientID:    globals.Conf.Oauth.Apple.ClientID,
+		RedirectURL: redirectURL,
+		Scopes:      apple.Scopes,
+		Endpoint:    apple.Endpoint,
+	}
+	o.authCodeOptions = apple.AuthCodeOptions
+}
+
+// validateConfig checks the oauth config is initiated by one of the Init*Config with the endpoint of oauth server,
+// so that the misconfigured routes respond 500 rather than panicking.
+func (o *OAuth) validateConfig() error {
+	if o.oauthConf == nil || o.oauthConf.Endpoint.AuthURL == "" || o.oauthConf.Endpoint.TokenURL == "" {
+		return errors.WithStack(&models.AppError{
+			Code:       models.ErrCodeOAuthConfigInvalid,
+			StatusCode: http.StatusInternalServerError,
+			Message:    "oauth config is not initiated",
+		})
+	}
+	return nil
+}
+
+// respondConfigError responds the invalid oauth config in the body of jsend error
+func respondConfigError(c *gin.Context, err error) {
+	appErr := errors.Cause(err).(*models.AppError)
+	c.JSON(appErr.StatusCode, gin.H{"status": "error", "code": appErr.Code, "message": appErr.Message})
+}
+
+// hasAvatarVariants returns true if the variants of the picture are stored for the OAuth account already
+func hasAvatarVariants(stored models.OAuthAccount, picture null.String) bool {
+	pictures := stored.Pictures
+	return stored.Picture == picture && pictures.Small.Valid && pictures.Medium.Valid && pictures.Large.Valid
+}
+
+// storeAvatarVariants stores the resized variants of the avatar of the OAuth account.
+// The pictures are left as they are if the avatar cannot be downloaded or is not an image,
+// which are null if the variants were never stored.
+func (o *OAuth) storeAvatarVariants(account models.OAuthAccount, userID uint) {
+	pictures, err := o.avatarService.StoreVariants(account.Picture.String, fmt.Sprintf("avatars/%d/%s", userID, strings.ToLower(account.Type)))
+	if err != nil {
+		logError(errors.WithMessage(err, fmt.Sprintf("fail to store avatar variants of user(id: %d)", userID)))
+		return
+	}
+
+	if err = o.Storage.UpdateOAuthPictures(account.AId, account.Type, pictures); err != nil {
+		logError(errors.WithMessage(err, fmt.Sprintf("fail to update avatar variants of user(id: %d)", userID)))
+	}
+}
+
+// BeginAuth redirects user to the [facebook|google|linkedin|apple] authentication(login) page
 func (o *OAuth) BeginOAuth(c *gin.Context) {
-	beginAuth(c, o.oauthConf)
+	if err := o.validateConfig(); err != nil {
+		logError(err)
+		respondConfigError(c, err)
+		return
+	}
+	beginAuth(c, o.oauthConf, o.authCodeOptions...)
 	return
 }
 
-// Authenticate handles [google|facebook] oauth of users and redirect them to specific URL they want
+// getRedirectStatus returns the configured status redirecting back to the destination after authentication.
+// 307 is used if the configured status is not a redirection to GET-able destination.
+// Since 307 preserves the method and body, 303 is used instead for the non-GET callback(e.g. Apple form_post),
+// so that the destination is always requested by GET.
+func getRedirectStatus(method string) int {
+	status := globals.Conf.Oauth.RedirectStatus
+
+	switch status {
+	case http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect:
+		// omit intentionally
+	default:
+		status = http.StatusTemporaryRedirect
+	}
+
+	if status == http.StatusTemporaryRedirect && method != http.MethodGet && method != http.MethodHead {
+		return http.StatusSeeOther
+	}
+	return status
+}
+
+// oauthErrorDescriptions are the messages for the error codes redirected back to the destination.
+// The messages are fixed rather than the errors themselves, so that the details never leak into the URL.
+var oauthErrorDescriptions = map[string]string{
+	models.ErrCodeInternal:              "Something went wrong, please try again later",
+	models.ErrCodeOAuthStateInvalid:     "The login session is expired, please try again",
+	models.ErrCodeOAuthExchangeFailed:   "Cannot sign in with the provider, please try again",
+	models.ErrCodeOAuthScopesNotGranted: "The required permissions are not granted",
+	models.ErrCodeRegistrationClosed:    "Registration is closed",
+	models.ErrCodeAccountBlocked:        "The account is blocked",
+}
+
+// redirectWithError redirects back to the destination with `error` code and `error_description` query params,
+// and the code is internal_error if the error is not one of oauthErrorDescriptions.
+func redirectWithError(c *gin.Context, destinationURL *url.URL, err error) {
+	code := models.ErrCodeInternal
+	if appErr, ok := errors.Cause(err).(*models.AppError); ok {
+		if _, ok = oauthErrorDescriptions[appErr.Code]; ok {
+			code = appErr.Code
+		}
+	}
+
+	location := *destinationURL
+	parameters := location.Query()
+	parameters.Set("error", code)
+	parameters.Set("error_description", oauthErrorDescriptions[code])
+	location.RawQuery = parameters.Encode()
+
+	c.Redirect(getRedirectStatus(c.Request.Method), location.String())
+}
+
+// Authenticate handles [google|facebook|linkedin|apple] oauth of users and redirect them to specific URL they want
 // with Set-Cookie response header which contains JWT
 func (o *OAuth) Authenticate(c *gin.Context) {
 	var destination string
+	var destinationURL *url.URL
 	var err error
 	var matchUser models.User
 	var oauthType string
@@ -250,11 +623,18 @@ func (o *OAuth) Authenticate(c *gin.Context) {
 	var userInfoEndpoint string
 
 	defer func() {
-		if err != nil {
-			log.Infof("%v", err)
-		}
+		// the successful logins are sampled to reduce the noise, while the failures are always logged
+		o.logSampler.Infof(log.WithFields(log.Fields{
+			"type":    oauthType,
+			"user_id": matchUser.ID,
+		}), err, "oauth succeeds")
 	}()
 
+	if err = o.validateConfig(); err != nil {
+		respondConfigError(c, err)
+		return
+	}
+
 	session = sessions.Default(c)
 
 	if retrievedDestination = session.Get("destination"); retrievedDestination != nil {
@@ -265,54 +645,88 @@ func (o *OAuth) Authenticate(c *gin.Context) {
 		destination = defaultDestination
 	}
 
-	if o.oauthConf.Endpoint == google.Endpoint {
+	if destinationURL, err = validateDestination(destination); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"destination": err.Error()}})
+		err = errors.Wrap(err, "oauth fails due to invalid destination:")
+		return
+	}
+
+	switch o.oauthConf.Endpoint {
+	case google.Endpoint:
 		var oauthInfo googleOauthInfoRaw
 		userInfoEndpoint = "https://www.googleapis.com/oauth2/v3/userinfo"
 		err = getOauthUserInfo(c, o.oauthConf, userInfoEndpoint, &oauthInfo)
 		copier.Copy(&oauthUser, &oauthInfo)
 		oauthType = globals.GoogleOAuth
-	} else {
+	case linkedin.Endpoint:
+		oauthUser, err = getLinkedInUserInfo(c, o.oauthConf)
+		oauthType = globals.LinkedInOAuth
+	case apple.Endpoint:
+		oauthUser, err = getAppleUserInfo(c, o.oauthConf)
+		oauthType = globals.AppleOAuth
+
+		// apple only provides the user name on the first authentication,
+		// keep the stored one rather than overwriting it with null
+		if err == nil && !oauthUser.Name.Valid {
+			if storedUser, storedErr := o.Storage.GetOAuthData(oauthUser.AId, oauthType); storedErr == nil {
+				oauthUser.Name = storedUser.Name
+				oauthUser.FirstName = storedUser.FirstName
+				oauthUser.LastName = storedUser.LastName
+			}
+		}
+	default:
 		var oauthInfo facebookOauthInfoRaw
-		userInfoEndpoint = "https://graph.facebook.com/v3.2/me?fields=id,name,email,picture,birthday,first_name,last_name,gender"
-		err = getOauthUserInfo(c, o.oauthConf, userInfoEndpoint, &oauthInfo)
+		err = getFacebookUserInfo(c, o.oauthConf, &oauthInfo)
 		copier.Copy(&oauthUser, &oauthInfo)
 		oauthType = globals.FacebookOAuth
 	}
 
 	if err != nil {
 		err = errors.Wrap(err, "oauth fails while getting user info from api, error message:")
-		c.Redirect(http.StatusTemporaryRedirect, destination)
+		redirectWithError(c, destinationURL, err)
 		return
 	}
 
 	oauthUser.Type = oauthType
 
+	// the stored avatar is read before it is updated by the sign-in, so that the unchanged one is not resized again
+	var storeAvatar = oauthType == globals.FacebookOAuth && o.avatarService != nil && oauthUser.Picture.Valid
+	if storeAvatar {
+		if storedUser, storedErr := o.Storage.GetOAuthData(oauthUser.AId, oauthType); storedErr == nil {
+			storeAvatar = !hasAvatarVariants(storedUser, oauthUser.Picture)
+		}
+	}
+
 	if matchUser, err = findOrCreateUser(oauthUser, o.Storage); err != nil {
 		err = errors.Wrap(err, "oauth fails due to database operation error:")
-		c.Redirect(http.StatusTemporaryRedirect, destination)
+		redirectWithError(c, destinationURL, err)
 		return
 	}
 
-	if token, err = utils.RetrieveV2IDToken(matchUser.ID, matchUser.Email.ValueOrZero(), matchUser.FirstName.ValueOrZero(), matchUser.LastName.ValueOrZero(), idTokenExpiration); err != nil {
-		err = errors.Wrap(err, "oauth fails due to generate JWT error:")
-		c.Redirect(http.StatusTemporaryRedirect, destination)
+	if matchUser.Blocked {
+		err = errors.Wrap(errAccountBlocked, fmt.Sprintf("oauth fails due to user(id: %d) blocked:", matchUser.ID))
+		redirectWithError(c, destinationURL, err)
 		return
 	}
 
-	var u *url.URL
-	var secure = false
-	u, err = url.Parse(destination)
+	if storeAvatar {
+		go o.storeAvatarVariants(oauthUser, matchUser.ID)
+	}
 
-	if u.Scheme == "https" {
-		secure = true
+	if token, err = utils.RetrieveV2IDToken(matchUser.ID, matchUser.Email.ValueOrZero(), matchUser.FirstName.ValueOrZero(), matchUser.LastName.ValueOrZero(), idTokenExpiration); err != nil {
+		err = errors.Wrap(err, "oauth fails due to generate JWT error:")
+		redirectWithError(c, destinationURL, err)
+		return
 	}
 
-	parameters := u.Query()
+	var secure = destinationURL.Scheme == "https"
+
+	parameters := destinationURL.Query()
 	parameters.Add("login", oauthType)
 	parameters.Add("login_time", fmt.Sprintf("%d", time.Now().Unix()))
 
-	u.RawQuery = parameters.Encode()
-	destination = u.String()
+	destinationURL.RawQuery = parameters.Encode()
+	destination = destinationURL.String()
 
 	// hours to seconds
 	maxAge := idTokenExpiration
@@ -321,5 +735,5 @@ func (o *OAuth) Authenticate(c *gin.Context) {
 	// so each hostname of [www|support|tsai-tracker].twreporter.org will be applied
 
 	c.SetCookie("id_token", token, maxAge, "/", "."+globals.Conf.App.Domain, secure, true)
-	c.Redirect(http.StatusTemporaryRedirect, destination)
+	c.Redirect(getRedirectStatus(c.Request.Method), destination)
 }
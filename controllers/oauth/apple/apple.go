@@ -0,0 +1,156 @@
+package apple
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/models"
+)
+
+// Issuer is the `iss` claim of the id token and the `aud` claim of the client secret
+const Issuer = "https://appleid.apple.com"
+
+// clientSecretExpiration is the lifetime of the generated client secret.
+// Apple accepts at most 6 months, the secret is generated for every token exchange so that it could be short.
+const clientSecretExpiration = 5 * time.Minute
+
+// Endpoint is Apple's OAuth 2.0 endpoint
+var Endpoint = oauth2.Endpoint{
+	AuthURL:   "https://appleid.apple.com/auth/authorize",
+	TokenURL:  "https://appleid.apple.com/auth/token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+// Scopes are the permissions asked for while signing in with Apple
+var Scopes = []string{"name", "email"}
+
+// AuthCodeOptions are required by Apple while asking for `name` and `email` scopes.
+// Apple posts `code`, `state` and `user` to the redirect URL as a form.
+var AuthCodeOptions = []oauth2.AuthCodeOption{
+	oauth2.SetAuthURLParam("response_mode", "form_post"),
+}
+
+// User is the `user` field posted to the redirect URL.
+// Apple only provides it on the first authentication of the user.
+type User struct {
+	Name struct {
+		FirstName string `json:"firstName"`
+		LastName  string `json:"lastName"`
+	} `json:"name"`
+	Email string `json:"email"`
+}
+
+// IDTokenClaims is the claims of the id token returned by the token endpoint
+type IDTokenClaims struct {
+	Email string `json:"email"`
+	jwt.StandardClaims
+}
+
+// parsePrivateKey parses the PEM encoded PKCS8 private key downloaded from Apple developer account.
+// SEC1 format is also accepted.
+func parsePrivateKey(key []byte) (*ecdsa.PrivateKey, error) {
+	var block *pem.Block
+	if block, _ = pem.Decode(key); block == nil {
+		return nil, errors.WithStack(jwt.ErrKeyMustBePEMEncoded)
+	}
+
+	if parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if pkey, ok := parsedKey.(*ecdsa.PrivateKey); ok {
+			return pkey, nil
+		}
+		return nil, errors.WithStack(jwt.ErrNotECPrivateKey)
+	}
+
+	pkey, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return pkey, nil
+}
+
+// GenerateClientSecret generates the ES256 signed JWT which is used as the client secret of Apple
+func GenerateClientSecret(teamID, clientID, keyID, privateKeyPath string) (string, error) {
+	var err error
+	var key []byte
+	var pkey *ecdsa.PrivateKey
+	var secret string
+
+	if key, err = ioutil.ReadFile(privateKeyPath); err != nil {
+		return "", errors.Wrap(err, "fail to read apple private key")
+	}
+
+	if pkey, err = parsePrivateKey(key); err != nil {
+		return "", errors.Wrap(err, "fail to parse apple private key")
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.StandardClaims{
+		Issuer:    teamID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(clientSecretExpiration).Unix(),
+		Audience:  Issuer,
+		Subject:   clientID,
+	})
+	token.Header["kid"] = keyID
+
+	if secret, err = token.SignedString(pkey); err != nil {
+		return "", errors.Wrap(err, "fail to sign apple client secret")
+	}
+
+	return secret, nil
+}
+
+// ParseIDToken parses the id token and validates its claims.
+// The signature is not verified since the token is received from Apple's token endpoint directly over TLS.
+func ParseIDToken(idToken, clientID string) (IDTokenClaims, error) {
+	const verifyRequired = true
+	var claims IDTokenClaims
+
+	if _, _, err := new(jwt.Parser).ParseUnverified(idToken, &claims); err != nil {
+		return claims, errors.WithStack(err)
+	}
+
+	if err := claims.Valid(); err != nil {
+		return claims, errors.WithStack(err)
+	}
+
+	if !claims.VerifyIssuer(Issuer, verifyRequired) {
+		return claims, errors.New("invalid issuer of apple id token")
+	}
+
+	if !claims.VerifyAudience(clientID, verifyRequired) {
+		return claims, errors.New("invalid audience of apple id token")
+	}
+
+	return claims, nil
+}
+
+// ToOAuthAccount maps Apple id token claims and user to models.OAuthAccount.
+// Name fields are only valid on the first authentication of the user.
+func ToOAuthAccount(claims IDTokenClaims, user User) models.OAuthAccount {
+	email := claims.Email
+	if email == "" {
+		email = user.Email
+	}
+
+	firstName := user.Name.FirstName
+	lastName := user.Name.LastName
+	name := strings.TrimSpace(firstName + " " + lastName)
+
+	return models.OAuthAccount{
+		AId:       null.NewString(claims.Subject, claims.Subject != ""),
+		Email:     null.NewString(email, email != ""),
+		Name:      null.NewString(name, name != ""),
+		FirstName: null.NewString(firstName, firstName != ""),
+		LastName:  null.NewString(lastName, lastName != ""),
+	}
+}
@@ -0,0 +1,83 @@
+package apple
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestGenerateClientSecret(t *testing.T) {
+	pkey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(pkey)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	f, err := ioutil.TempFile("", "AuthKey_*.p8")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	f.Close()
+
+	secret, err := GenerateClientSecret("team-id", "org.twreporter.web", "key-id", f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var claims jwt.StandardClaims
+	token, err := jwt.ParseWithClaims(secret, &claims, func(token *jwt.Token) (interface{}, error) {
+		return &pkey.PublicKey, nil
+	})
+	if err != nil || !token.Valid {
+		t.Fatalf("expected valid client secret, got error %v", err)
+	}
+
+	if token.Header["kid"] != "key-id" || token.Method != jwt.SigningMethodES256 {
+		t.Errorf("unexpected header %v", token.Header)
+	}
+	if claims.Issuer != "team-id" || claims.Subject != "org.twreporter.web" || claims.Audience != Issuer {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	if _, err = GenerateClientSecret("team-id", "org.twreporter.web", "key-id", f.Name()+".notexisted"); err == nil {
+		t.Errorf("expected error when the private key does not exist")
+	}
+}
+
+func TestToOAuthAccount(t *testing.T) {
+	claims := IDTokenClaims{Email: "bob@privaterelay.appleid.com"}
+	claims.Subject = "001234.abcd"
+
+	// first authentication
+	var user User
+	user.Name.FirstName = "Bob"
+	user.Name.LastName = "Smith"
+
+	account := ToOAuthAccount(claims, user)
+	if account.AId.ValueOrZero() != "001234.abcd" || account.Email.ValueOrZero() != "bob@privaterelay.appleid.com" {
+		t.Errorf("unexpected account %+v", account)
+	}
+	if account.Name.ValueOrZero() != "Bob Smith" || account.FirstName.ValueOrZero() != "Bob" || account.LastName.ValueOrZero() != "Smith" {
+		t.Errorf("unexpected name of account %+v", account)
+	}
+
+	// later authentication without user
+	account = ToOAuthAccount(claims, User{})
+	if account.Name.Valid || account.FirstName.Valid || account.LastName.Valid {
+		t.Errorf("expected name fields to be null, got %+v", account)
+	}
+}
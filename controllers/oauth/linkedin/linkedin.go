@@ -0,0 +1,135 @@
+package linkedin
+
+import (
+	"strings"
+
+	"golang.org/x/oauth2"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/models"
+)
+
+const (
+	// ProfileEndpoint returns the lite profile and the profile picture of the member
+	ProfileEndpoint = "https://api.linkedin.com/v2/me?projection=(id,localizedFirstName,localizedLastName,firstName,lastName,profilePicture(displayImage~:playableStreams))"
+
+	// EmailEndpoint returns the primary email address of the member
+	EmailEndpoint = "https://api.linkedin.com/v2/emailAddress?q=members&projection=(elements*(handle~))"
+)
+
+// Endpoint is LinkedIn's OAuth 2.0 endpoint.
+// LinkedIn requires `client_id` and `client_secret` to be sent in the POST body.
+var Endpoint = oauth2.Endpoint{
+	AuthURL:   "https://www.linkedin.com/oauth/v2/authorization",
+	TokenURL:  "https://www.linkedin.com/oauth/v2/accessToken",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+// Scopes are the permissions asked for while signing in with LinkedIn
+var Scopes = []string{"r_liteprofile", "r_emailaddress"}
+
+type locale struct {
+	Country  string `json:"country"`
+	Language string `json:"language"`
+}
+
+// multiLocaleString is the format LinkedIn uses to represent the localized fields, e.g.
+// {"localized": {"en_US": "Bob"}, "preferredLocale": {"country": "US", "language": "en"}}
+type multiLocaleString struct {
+	Localized       map[string]string `json:"localized"`
+	PreferredLocale locale            `json:"preferredLocale"`
+}
+
+// value returns the string of the preferred locale,
+// or any of the localized strings if the preferred one is not provided
+func (s multiLocaleString) value() string {
+	if v, ok := s.Localized[s.PreferredLocale.Language+"_"+s.PreferredLocale.Country]; ok {
+		return v
+	}
+
+	for _, v := range s.Localized {
+		return v
+	}
+
+	return ""
+}
+
+// ProfileRaw is the response of ProfileEndpoint
+type ProfileRaw struct {
+	ID                 string            `json:"id"`
+	LocalizedFirstName string            `json:"localizedFirstName"`
+	LocalizedLastName  string            `json:"localizedLastName"`
+	FirstNameObj       multiLocaleString `json:"firstName"`
+	LastNameObj        multiLocaleString `json:"lastName"`
+	PictureObj         struct {
+		DisplayImage struct {
+			Elements []struct {
+				Identifiers []struct {
+					Identifier string `json:"identifier"`
+				} `json:"identifiers"`
+			} `json:"elements"`
+		} `json:"displayImage~"`
+	} `json:"profilePicture"`
+}
+
+// FirstName returns `localizedFirstName`, and falls back to the localized `firstName` object
+func (p *ProfileRaw) FirstName() string {
+	if p.LocalizedFirstName != "" {
+		return p.LocalizedFirstName
+	}
+	return p.FirstNameObj.value()
+}
+
+// LastName returns `localizedLastName`, and falls back to the localized `lastName` object
+func (p *ProfileRaw) LastName() string {
+	if p.LocalizedLastName != "" {
+		return p.LocalizedLastName
+	}
+	return p.LastNameObj.value()
+}
+
+// Picture returns the URL of the largest profile picture.
+// LinkedIn sorts the display images by size ascendingly.
+func (p *ProfileRaw) Picture() string {
+	elements := p.PictureObj.DisplayImage.Elements
+	for i := len(elements) - 1; i >= 0; i-- {
+		if identifiers := elements[i].Identifiers; len(identifiers) > 0 {
+			return identifiers[0].Identifier
+		}
+	}
+	return ""
+}
+
+// EmailRaw is the response of EmailEndpoint
+type EmailRaw struct {
+	Elements []struct {
+		Handle struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"handle~"`
+	} `json:"elements"`
+}
+
+// EmailAddress returns the primary email address
+func (e *EmailRaw) EmailAddress() string {
+	if len(e.Elements) > 0 {
+		return e.Elements[0].Handle.EmailAddress
+	}
+	return ""
+}
+
+// ToOAuthAccount maps LinkedIn profile and email address to models.OAuthAccount
+func ToOAuthAccount(profile ProfileRaw, email EmailRaw) models.OAuthAccount {
+	firstName := profile.FirstName()
+	lastName := profile.LastName()
+
+	name := strings.TrimSpace(firstName + " " + lastName)
+
+	return models.OAuthAccount{
+		AId:       null.NewString(profile.ID, profile.ID != ""),
+		Email:     null.NewString(email.EmailAddress(), email.EmailAddress() != ""),
+		Name:      null.NewString(name, name != ""),
+		FirstName: null.NewString(firstName, firstName != ""),
+		LastName:  null.NewString(lastName, lastName != ""),
+		Picture:   null.NewString(profile.Picture(), profile.Picture() != ""),
+	}
+}
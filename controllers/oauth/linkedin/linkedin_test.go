@@ -0,0 +1,78 @@
+package linkedin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestToOAuthAccount(t *testing.T) {
+	cases := []struct {
+		name          string
+		profile       string
+		email         string
+		wantFirstName string
+		wantLastName  string
+		wantName      string
+		wantEmail     string
+		wantPicture   string
+	}{
+		{
+			name:          "Given localized names",
+			profile:       `{"id":"abc","localizedFirstName":"Bob","localizedLastName":"Smith"}`,
+			email:         `{"elements":[{"handle~":{"emailAddress":"bob@twreporter.org"}}]}`,
+			wantFirstName: "Bob",
+			wantLastName:  "Smith",
+			wantName:      "Bob Smith",
+			wantEmail:     "bob@twreporter.org",
+		},
+		{
+			name:          "Given multi-locale names only",
+			profile:       `{"id":"abc","firstName":{"localized":{"zh_TW":"小明","en_US":"Ming"},"preferredLocale":{"country":"TW","language":"zh"}},"lastName":{"localized":{"zh_TW":"王"},"preferredLocale":{"country":"TW","language":"zh"}}}`,
+			email:         `{"elements":[]}`,
+			wantFirstName: "小明",
+			wantLastName:  "王",
+			wantName:      "小明 王",
+		},
+		{
+			name:          "Given profile pictures",
+			profile:       `{"id":"abc","localizedFirstName":"Bob","profilePicture":{"displayImage~":{"elements":[{"identifiers":[{"identifier":"https://media.licdn.com/100"}]},{"identifiers":[{"identifier":"https://media.licdn.com/800"}]}]}}}`,
+			email:         `{}`,
+			wantFirstName: "Bob",
+			wantName:      "Bob",
+			wantPicture:   "https://media.licdn.com/800",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var profile ProfileRaw
+			var email EmailRaw
+			if err := json.Unmarshal([]byte(tc.profile), &profile); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if err := json.Unmarshal([]byte(tc.email), &email); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			account := ToOAuthAccount(profile, email)
+			if account.AId.ValueOrZero() != "abc" {
+				t.Errorf("expected a_id %s, got %s", "abc", account.AId.ValueOrZero())
+			}
+			if account.FirstName.ValueOrZero() != tc.wantFirstName {
+				t.Errorf("expected firstname %s, got %s", tc.wantFirstName, account.FirstName.ValueOrZero())
+			}
+			if account.LastName.ValueOrZero() != tc.wantLastName {
+				t.Errorf("expected lastname %s, got %s", tc.wantLastName, account.LastName.ValueOrZero())
+			}
+			if account.Name.ValueOrZero() != tc.wantName {
+				t.Errorf("expected name %s, got %s", tc.wantName, account.Name.ValueOrZero())
+			}
+			if account.Email.Valid != (tc.wantEmail != "") || account.Email.ValueOrZero() != tc.wantEmail {
+				t.Errorf("expected email %s, got %v", tc.wantEmail, account.Email)
+			}
+			if account.Picture.ValueOrZero() != tc.wantPicture {
+				t.Errorf("expected picture %s, got %s", tc.wantPicture, account.Picture.ValueOrZero())
+			}
+		})
+	}
+}
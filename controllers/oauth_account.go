@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+)
+
+// linkedOAuthAccount is the OAuth account shown to the user,
+// the tokens and the user id returned by OAuth services are excluded.
+type linkedOAuthAccount struct {
+	Type        string                `json:"type"`
+	Email       string                `json:"email"`
+	Name        string                `json:"name"`
+	DisplayName string                `json:"display_name"`
+	Pictures    models.AvatarPictures `json:"pictures"`
+	LinkedAt    time.Time             `json:"linked_at"`
+}
+
+// maskString keeps the first character and masks the rest
+func maskString(s string) string {
+	runes := []rune(s)
+	if len(runes) <= 1 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[0]) + strings.Repeat("*", len(runes)-1)
+}
+
+// maskEmail masks the local part of the email, e.g. john@gmail.com becomes j***@gmail.com
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return maskString(email)
+	}
+	return maskString(email[:at]) + email[at:]
+}
+
+// GetOAuthAccountsOfAUser returns the OAuth providers linked to the user
+func (mc *MembershipController) GetOAuthAccountsOfAUser(c *gin.Context) (int, gin.H, error) {
+	var err error
+	var accounts []models.OAuthAccount
+
+	userID := c.Param("userID")
+
+	if _, err = mc.Storage.GetUserByID(userID); err != nil {
+		return toResponse(err)
+	}
+
+	if accounts, err = mc.Storage.GetOAuthAccountsOfAUser(userID); err != nil {
+		return toResponse(err)
+	}
+
+	var records = make([]linkedOAuthAccount, 0, len(accounts))
+	for _, account := range accounts {
+		// the display name is masked as the name unless it falls back to the default
+		displayName := account.DisplayName()
+		if displayName != models.DefaultDisplayName {
+			displayName = maskString(displayName)
+		}
+
+		records = append(records, linkedOAuthAccount{
+			Type:        account.Type,
+			Email:       maskEmail(account.Email.ValueOrZero()),
+			Name:        maskString(account.Name.ValueOrZero()),
+			DisplayName: displayName,
+			Pictures:    account.Pictures,
+			LinkedAt:    account.CreatedAt,
+		})
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": records}}, nil
+}
@@ -0,0 +1,494 @@
+package controllers
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/controllers/oauth/apple"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+// setDomain sets `app.domain`, which the destinations are validated against, and returns the func restoring it
+func setDomain(domain string) func() {
+	defaultDomain := globals.Conf.App.Domain
+	globals.Conf.App.Domain = domain
+	return func() {
+		globals.Conf.App.Domain = defaultDomain
+	}
+}
+
+func TestAuthenticateRedirectStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		method   string
+		endpoint oauth2.Endpoint
+		want     int
+	}{
+		{name: "Given the default status", status: 0, method: http.MethodGet, endpoint: google.Endpoint, want: http.StatusTemporaryRedirect},
+		{name: "Given 302 status", status: http.StatusFound, method: http.MethodGet, endpoint: google.Endpoint, want: http.StatusFound},
+		{name: "Given 307 status", status: http.StatusTemporaryRedirect, method: http.MethodGet, endpoint: google.Endpoint, want: http.StatusTemporaryRedirect},
+		{name: "Given invalid status", status: http.StatusMovedPermanently, method: http.MethodGet, endpoint: google.Endpoint, want: http.StatusTemporaryRedirect},
+		{name: "Given 307 status for POST callback", status: http.StatusTemporaryRedirect, method: http.MethodPost, endpoint: apple.Endpoint, want: http.StatusSeeOther},
+		{name: "Given 302 status for POST callback", status: http.StatusFound, method: http.MethodPost, endpoint: apple.Endpoint, want: http.StatusFound},
+	}
+
+	defer setDomain("twreporter.org")()
+
+	defaultStatus := globals.Conf.Oauth.RedirectStatus
+	defer func() {
+		globals.Conf.Oauth.RedirectStatus = defaultStatus
+	}()
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			globals.Conf.Oauth.RedirectStatus = tc.status
+
+			o := &OAuth{oauthConf: &oauth2.Config{Endpoint: tc.endpoint}}
+			engine := gin.New()
+			engine.Use(sessions.Sessions("go-api-session", cookie.NewStore([]byte("secret"))))
+			engine.Handle(tc.method, "/callback", o.Authenticate)
+
+			// the request without state fails the authentication and redirects back to the destination
+			req, _ := http.NewRequest(tc.method, "/callback", nil)
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != tc.want {
+				t.Errorf("expected status %d, got %d", tc.want, resp.Code)
+			}
+			if location := resp.Header().Get("Location"); !strings.HasPrefix(location, defaultDestination+"?") {
+				t.Errorf("expected redirection to %s, got %s", defaultDestination, location)
+			}
+		})
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	cases := map[string]string{
+		"john@gmail.com": "j***@gmail.com",
+		"j@gmail.com":    "*@gmail.com",
+		"":               "",
+		"not-an-email":   "n***********",
+	}
+
+	for email, want := range cases {
+		if got := maskEmail(email); got != want {
+			t.Errorf("maskEmail(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
+
+func TestGetRemoteUserDataGzip(t *testing.T) {
+	const userInfo = `{"id":"1234","email":"john@gmail.com","name":"John"}`
+
+	cases := []struct {
+		name string
+		gzip bool
+	}{
+		{name: "Given a gzip encoded userinfo response", gzip: true},
+		{name: "Given a plain userinfo response", gzip: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if !tc.gzip {
+					io.WriteString(w, userInfo)
+					return
+				}
+				// compress regardless of what the client accepts, as some upstreams do
+				w.Header().Set("Content-Encoding", "gzip")
+				gw := gzip.NewWriter(w)
+				io.WriteString(gw, userInfo)
+				gw.Close()
+			}))
+			defer server.Close()
+
+			// disable the transparent decompression of transport to ensure the body reaches getRemoteUserData compressed
+			client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+			var user struct {
+				ID    string `json:"id"`
+				Email string `json:"email"`
+				Name  string `json:"name"`
+			}
+			if err := getRemoteUserData(client, server.URL, &user); err != nil {
+				t.Fatalf("getRemoteUserData() error = %v", err)
+			}
+
+			if user.ID != "1234" || user.Email != "john@gmail.com" || user.Name != "John" {
+				t.Errorf("getRemoteUserData() = %+v, want the userinfo decoded", user)
+			}
+		})
+	}
+}
+
+func TestCheckFacebookPermissions(t *testing.T) {
+	cases := []struct {
+		name        string
+		required    []string
+		permissions string
+		missing     []string
+	}{
+		{
+			name:        "Given all the scopes granted",
+			required:    []string{"public_profile", "email"},
+			permissions: `{"data":[{"permission":"public_profile","status":"granted"},{"permission":"email","status":"granted"},{"permission":"user_birthday","status":"declined"}]}`,
+		},
+		{
+			name:        "Given email declined",
+			required:    []string{"public_profile", "email"},
+			permissions: `{"data":[{"permission":"public_profile","status":"granted"},{"permission":"email","status":"declined"}]}`,
+			missing:     []string{"email"},
+		},
+		{
+			name:        "Given email and user_birthday absent",
+			required:    []string{"public_profile", "email", "user_birthday"},
+			permissions: `{"data":[{"permission":"public_profile","status":"granted"}]}`,
+			missing:     []string{"email", "user_birthday"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				io.WriteString(w, tc.permissions)
+			}))
+			defer server.Close()
+
+			err := checkFacebookPermissions(server.Client(), server.URL, tc.required)
+			if len(tc.missing) == 0 {
+				if err != nil {
+					t.Errorf("checkFacebookPermissions() error = %v, want nil", err)
+				}
+				return
+			}
+
+			appErr, ok := errors.Cause(err).(*models.AppError)
+			if !ok {
+				t.Fatalf("checkFacebookPermissions() error = %v, want *models.AppError", err)
+			}
+			if appErr.Code != models.ErrCodeOAuthScopesNotGranted {
+				t.Errorf("expected code %s, got %s", models.ErrCodeOAuthScopesNotGranted, appErr.Code)
+			}
+			if want := "facebook permissions are not granted: " + strings.Join(tc.missing, ", "); appErr.Message != want {
+				t.Errorf("expected message %q, got %q", want, appErr.Message)
+			}
+		})
+	}
+}
+
+func TestAuthenticateDestination(t *testing.T) {
+	cases := []struct {
+		name        string
+		destination string
+		want        int
+	}{
+		{name: "Given a relative URL", destination: "/account", want: http.StatusBadRequest},
+		{name: "Given a javascript URL", destination: "javascript:alert(1)", want: http.StatusBadRequest},
+		{name: "Given a protocol-relative URL", destination: "//evil.example.com/", want: http.StatusBadRequest},
+		{name: "Given an absolute URL", destination: "https://support.twreporter.org/account", want: http.StatusTemporaryRedirect},
+		{name: "Given a URL of the domain", destination: "https://twreporter.org/", want: http.StatusTemporaryRedirect},
+		{name: "Given a URL of another site", destination: "https://evil.example.com/", want: http.StatusBadRequest},
+		{name: "Given a URL of the host suffixed with the domain", destination: "https://eviltwreporter.org/", want: http.StatusBadRequest},
+		{name: "Given a URL of the domain as the subdomain of another site", destination: "https://twreporter.org.evil.example.com/", want: http.StatusBadRequest},
+	}
+
+	defer setDomain("twreporter.org")()
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &OAuth{oauthConf: &oauth2.Config{Endpoint: google.Endpoint}}
+			engine := gin.New()
+			engine.Use(sessions.Sessions("go-api-session", cookie.NewStore([]byte("secret"))))
+			engine.GET("/begin", func(c *gin.Context) {
+				session := sessions.Default(c)
+				session.Set("destination", tc.destination)
+				session.Save()
+			})
+			engine.GET("/callback", o.Authenticate)
+
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/begin", nil))
+			cookie := resp.Header().Get("Set-Cookie")
+
+			// the request without state fails the authentication and redirects back to the destination if it is valid
+			req := httptest.NewRequest(http.MethodGet, "/callback", nil)
+			req.Header.Set("Cookie", cookie)
+			resp = httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != tc.want {
+				t.Fatalf("expected status %d, got %d", tc.want, resp.Code)
+			}
+			if location := resp.Header().Get("Location"); tc.want == http.StatusTemporaryRedirect && !strings.HasPrefix(location, tc.destination+"?") {
+				t.Errorf("expected redirection to %s, got %s", tc.destination, location)
+			}
+			if tc.want == http.StatusBadRequest && !strings.Contains(resp.Body.String(), `"destination"`) {
+				t.Errorf("expected the invalid destination responded, got %s", resp.Body.String())
+			}
+		})
+	}
+}
+
+// mockOAuthMembershipStorage stores no users, and counts the users created by oauth
+type mockOAuthMembershipStorage struct {
+	storage.MembershipStorage
+	inserted int
+}
+
+func (s *mockOAuthMembershipStorage) GetOAuthData(aid null.String, aType string) (models.OAuthAccount, error) {
+	return models.OAuthAccount{}, storage.ErrRecordNotFound
+}
+
+func (s *mockOAuthMembershipStorage) GetUserByEmail(email string) (models.User, error) {
+	return models.User{}, storage.ErrRecordNotFound
+}
+
+func (s *mockOAuthMembershipStorage) InsertUserByOAuth(account models.OAuthAccount) (models.User, error) {
+	s.inserted++
+	return models.User{ID: 1, Email: account.Email}, nil
+}
+
+func TestFindOrCreateUserAutoRegister(t *testing.T) {
+	cases := []struct {
+		name         string
+		allow        bool
+		email        null.String
+		wantErr      error
+		wantInserted int
+	}{
+		{name: "Given auto registration enabled", allow: true, email: null.StringFrom("mock@twreporter.org"), wantInserted: 1},
+		{name: "Given auto registration enabled without email", allow: true, wantInserted: 1},
+		{name: "Given auto registration disabled", allow: false, email: null.StringFrom("mock@twreporter.org"), wantErr: errRegistrationClosed},
+		{name: "Given auto registration disabled without email", allow: false, wantErr: errRegistrationClosed},
+	}
+
+	defaultAllow := globals.Conf.Oauth.AllowAutoRegister
+	defer func() {
+		globals.Conf.Oauth.AllowAutoRegister = defaultAllow
+	}()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			globals.Conf.Oauth.AllowAutoRegister = tc.allow
+			ms := &mockOAuthMembershipStorage{}
+
+			_, err := findOrCreateUser(models.OAuthAccount{Type: globals.GoogleOAuth, AId: null.StringFrom("mock-aid"), Email: tc.email}, ms)
+			if errors.Cause(err) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if ms.inserted != tc.wantInserted {
+				t.Errorf("expected %d users created, got %d", tc.wantInserted, ms.inserted)
+			}
+		})
+	}
+}
+
+func TestAuthenticateErrorRedirect(t *testing.T) {
+	// the oauth server failing to exchange the code to token
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error":"invalid_grant","error_description":"the code mock-code of client mock-id is expired"}`)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name     string
+		query    string
+		wantCode string
+	}{
+		{name: "Given a mismatched state", query: "state=another-state&code=mock-code", wantCode: models.ErrCodeOAuthStateInvalid},
+		{name: "Given the code failed to exchange", query: "state=mock-state&code=mock-code", wantCode: models.ErrCodeOAuthExchangeFailed},
+	}
+
+	defer setDomain("twreporter.org")()
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &OAuth{oauthConf: &oauth2.Config{
+				ClientID: "mock-id",
+				Endpoint: oauth2.Endpoint{AuthURL: server.URL + "/auth", TokenURL: server.URL + "/token"},
+			}}
+			engine := gin.New()
+			engine.Use(sessions.Sessions("go-api-session", cookie.NewStore([]byte("secret"))))
+			engine.GET("/begin", func(c *gin.Context) {
+				session := sessions.Default(c)
+				session.Set("state", "mock-state")
+				session.Set("destination", "https://accounts.twreporter.org/signin?from=header")
+				session.Save()
+			})
+			engine.GET("/callback", o.Authenticate)
+
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/begin", nil))
+			cookie := resp.Header().Get("Set-Cookie")
+
+			req := httptest.NewRequest(http.MethodGet, "/callback?"+tc.query, nil)
+			req.Header.Set("Cookie", cookie)
+			resp = httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != http.StatusTemporaryRedirect {
+				t.Fatalf("expected status %d, got %d", http.StatusTemporaryRedirect, resp.Code)
+			}
+
+			location, err := url.Parse(resp.Header().Get("Location"))
+			if err != nil {
+				t.Fatalf("expected a valid redirection, got %s", resp.Header().Get("Location"))
+			}
+			if location.Host != "accounts.twreporter.org" || location.Query().Get("from") != "header" {
+				t.Errorf("expected redirection to the destination, got %s", location)
+			}
+			if got := location.Query().Get("error"); got != tc.wantCode {
+				t.Errorf("expected error %s, got %s", tc.wantCode, got)
+			}
+			if got := location.Query().Get("error_description"); got != oauthErrorDescriptions[tc.wantCode] {
+				t.Errorf("expected error description %q, got %q", oauthErrorDescriptions[tc.wantCode], got)
+			}
+			if strings.Contains(location.RawQuery, "mock") {
+				t.Errorf("expected no detail of the error leaked, got %s", location.RawQuery)
+			}
+		})
+	}
+}
+
+// mockSignedInMembershipStorage stores the oauth user signed in before, and counts the updates of the oauth data
+type mockSignedInMembershipStorage struct {
+	storage.MembershipStorage
+	updatedAt time.Time
+	updated   int
+	// conflict is true if the oauth data is updated by another login concurrently
+	conflict bool
+}
+
+func (s *mockSignedInMembershipStorage) GetOAuthData(aid null.String, aType string) (models.OAuthAccount, error) {
+	return models.OAuthAccount{ID: 1, UserID: 1, AId: aid, Type: aType, UpdatedAt: s.updatedAt}, nil
+}
+
+func (s *mockSignedInMembershipStorage) GetUserDataByOAuth(account models.OAuthAccount) (models.User, error) {
+	return models.User{ID: 1}, nil
+}
+
+func (s *mockSignedInMembershipStorage) UpdateOAuthData(account models.OAuthAccount) (models.OAuthAccount, error) {
+	s.updated++
+	if s.conflict {
+		return models.OAuthAccount{}, errors.WithStack(storage.ErrUpdateConflict)
+	}
+	return account, nil
+}
+
+func TestFindOrCreateUserRefreshOAuthData(t *testing.T) {
+	cases := []struct {
+		name        string
+		oauthType   string
+		updatedAt   time.Time
+		conflict    bool
+		wantUpdated int
+	}{
+		{name: "Given the facebook profile updated recently", oauthType: globals.FacebookOAuth, updatedAt: time.Now().Add(-time.Hour), wantUpdated: 0},
+		{name: "Given the stale facebook profile", oauthType: globals.FacebookOAuth, updatedAt: time.Now().Add(-25 * time.Hour), wantUpdated: 1},
+		{name: "Given the google profile updated recently", oauthType: globals.GoogleOAuth, updatedAt: time.Now().Add(-time.Hour), wantUpdated: 1},
+		{name: "Given the profile updated by another login concurrently", oauthType: globals.GoogleOAuth, updatedAt: time.Now().Add(-time.Hour), conflict: true, wantUpdated: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockSignedInMembershipStorage{updatedAt: tc.updatedAt, conflict: tc.conflict}
+
+			user, err := findOrCreateUser(models.OAuthAccount{Type: tc.oauthType, AId: null.StringFrom("mock-aid")}, ms)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if user.ID != 1 {
+				t.Errorf("expected the user signed in before, got %v", user)
+			}
+			if ms.updated != tc.wantUpdated {
+				t.Errorf("expected %d updates, got %d", tc.wantUpdated, ms.updated)
+			}
+		})
+	}
+}
+
+func TestAuthenticateWithoutConfig(t *testing.T) {
+	cases := []struct {
+		name string
+		conf *oauth2.Config
+	}{
+		{name: "Given no config initiated", conf: nil},
+		{name: "Given the config without endpoint", conf: &oauth2.Config{ClientID: "mock-id"}},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &OAuth{oauthConf: tc.conf}
+			engine := gin.New()
+			engine.Use(sessions.Sessions("go-api-session", cookie.NewStore([]byte("secret"))))
+			engine.GET("/begin", o.BeginOAuth)
+			engine.GET("/callback", o.Authenticate)
+
+			for _, path := range []string{"/begin", "/callback?state=mock-state&code=mock-code"} {
+				resp := httptest.NewRecorder()
+				engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+
+				if resp.Code != http.StatusInternalServerError {
+					t.Fatalf("expected status %d of %s, got %d", http.StatusInternalServerError, path, resp.Code)
+				}
+				if !strings.Contains(resp.Body.String(), models.ErrCodeOAuthConfigInvalid) {
+					t.Errorf("expected error code %s of %s, got %s", models.ErrCodeOAuthConfigInvalid, path, resp.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestHasAvatarVariants(t *testing.T) {
+	picture := null.StringFrom("https://graph.facebook.com/mock/picture")
+	variants := models.AvatarPictures{
+		Small:  null.StringFrom("https://avatars.example.com/small.jpg"),
+		Medium: null.StringFrom("https://avatars.example.com/medium.jpg"),
+		Large:  null.StringFrom("https://avatars.example.com/large.jpg"),
+	}
+
+	cases := []struct {
+		name   string
+		stored models.OAuthAccount
+		want   bool
+	}{
+		{name: "Given the variants of the picture stored", stored: models.OAuthAccount{Picture: picture, Pictures: variants}, want: true},
+		{name: "Given the picture changed", stored: models.OAuthAccount{Picture: null.StringFrom("https://graph.facebook.com/old/picture"), Pictures: variants}, want: false},
+		{name: "Given the variants never stored", stored: models.OAuthAccount{Picture: picture}, want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := hasAvatarVariants(tc.stored, picture); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
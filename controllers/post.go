@@ -1,60 +1,74 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gopkg.in/mgo.v2/bson"
 	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
 )
 
+// postListParams binds the list params of the posts
+var postListParams = ListParamsBinder{
+	DefaultLimit: defaultPerPage,
+	DefaultSort:  "-publishedDate",
+	SortFields:   []string{"publishedDate", "updatedAt"},
+}
+
 // GetPosts receive HTTP GET method request, and return the posts.
-// `query`, `limit`, `offset`, `sort` and `full` are the url query params,
+// `where` and the list params, see ListParamsBinder.BindListParams, are the url query params,
 // which define the rule we retrieve posts from storage.
+// If `expand=authors` is provided, the authors of the posts are embedded even if they are not full.
+// If `state` is provided by the admins, the posts of the state are returned instead of the published ones.
 func (nc *NewsController) GetPosts(c *gin.Context) (int, gin.H, error) {
 	var total int
 	var posts []models.Post = make([]models.Post, 0)
 
-	err, mq, limit, offset, sort, full := nc.GetQueryParam(c)
-
-	// response empty records if parsing url query param occurs error
-	if err != nil {
-		return http.StatusOK, gin.H{"status": "ok", "records": posts, "meta": models.MetaOfResponse{
-			Total:  total,
-			Offset: offset,
-			Limit:  limit,
-		}}, nil
+	err, mq, limit, offset, sort, full := nc.GetQueryParam(c, postListParams)
+	if _, ok := err.(*ListParamError); ok {
+		return listParamsFailResponse(err)
 	}
-
-	if limit == 0 {
-		limit = 10
+	if err == models.ErrInvalidAuthorID {
+		return http.StatusBadRequest, gin.H{"status": "fail", "error": err.Error()}, nil
+	}
+	if err == models.ErrInvalidExpandField {
+		return invalidExpandResponse()
+	}
+	if err == errInvalidState {
+		return invalidStateResponse()
 	}
 
-	if sort == "" {
-		sort = "-publishedDate"
+	// response empty records if parsing url query param occurs error
+	if err != nil {
+		return http.StatusOK, gin.H{"status": "ok", "records": posts, "meta": newMetaOfResponse(total, offset, limit)}, nil
 	}
 
 	if full {
-		posts, total, err = nc.Storage.GetFullPosts(mq, limit, offset, sort, nil)
+		posts, total, err = nc.Storage.GetFullPosts(c.Request.Context(), mq, limit, offset, sort, nil)
 	} else {
-		posts, total, err = nc.Storage.GetMetaOfPosts(mq, limit, offset, sort, nil)
+		posts, total, err = nc.Storage.GetMetaOfPosts(c.Request.Context(), mq, limit, offset, sort, nil)
 	}
 
 	if err != nil {
 		return toPostResponse(err)
 	}
 
+	if full {
+		stripRestrictedContents(c, posts)
+	}
+
 	// make sure `response.records`
 	// would be `[]` rather than  `null`
 	if posts == nil {
 		posts = make([]models.Post, 0)
 	}
 
-	return http.StatusOK, gin.H{"status": "ok", "records": posts, "meta": models.MetaOfResponse{
-		Total:  total,
-		Offset: offset,
-		Limit:  limit,
-	}}, nil
+	return http.StatusOK, gin.H{"status": "ok", "records": posts, "meta": newMetaOfResponse(total, offset, limit)}, nil
 }
 
 // GetAPost receive HTTP GET method request, and return the certain post.
@@ -68,11 +82,14 @@ func (nc *NewsController) GetAPost(c *gin.Context) (int, gin.H, error) {
 	mq := models.MongoQuery{
 		Slug: slug,
 	}
+	if err = expandQuery(c, &mq); err != nil {
+		return invalidExpandResponse()
+	}
 
 	if full {
-		posts, _, err = nc.Storage.GetFullPosts(mq, 1, 0, "-publishedDate", nil)
+		posts, _, err = nc.Storage.GetFullPosts(c.Request.Context(), mq, 1, 0, "-publishedDate", nil)
 	} else {
-		posts, _, err = nc.Storage.GetMetaOfPosts(mq, 1, 0, "-publishedDate", nil)
+		posts, _, err = nc.Storage.GetMetaOfPosts(c.Request.Context(), mq, 1, 0, "-publishedDate", nil)
 	}
 
 	if err != nil {
@@ -80,8 +97,190 @@ func (nc *NewsController) GetAPost(c *gin.Context) (int, gin.H, error) {
 	}
 
 	if len(posts) == 0 {
-		return http.StatusNotFound, gin.H{"status": "Record Not Found", "error": "Record Not Found"}, nil
+		return mergedPostResponse(c, nc.Storage, slug)
+	}
+
+	if failBody, available := checkPostCountry(c, slug, posts[0].RestrictedCountries); !available {
+		return restrictedPostResponse(failBody)
 	}
 
 	return http.StatusOK, gin.H{"status": "ok", "record": posts[0]}, nil
 }
+
+// mergedPostResponse redirects to the post which the post of the slug is merged into,
+// or responds 404 if the post is not merged.
+func mergedPostResponse(c *gin.Context, s postGetter, slug string) (int, gin.H, error) {
+	post, err := s.GetPostBySlug(slug)
+	if err != nil && !storage.IsNotFound(err) {
+		return toPostResponse(err)
+	}
+
+	if err != nil || post.RedirectTo == "" {
+		return http.StatusNotFound, gin.H{"status": "Record Not Found", "error": "Record Not Found"}, nil
+	}
+
+	location := "/v1/posts/" + post.RedirectTo
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Header("Location", location)
+
+	return http.StatusMovedPermanently, gin.H{"status": "fail", "data": gin.H{
+		"slug": fmt.Sprintf("post(slug: %s) is merged into post(slug: %s)", slug, post.RedirectTo),
+	}}, nil
+}
+
+// GetPostsBySeries returns all the posts of the series of `seriesSlug` path param ordered by the part number,
+// e.g. `/v1/posts/series/a-series-slug`
+func (nc *NewsController) GetPostsBySeries(c *gin.Context) (int, gin.H, error) {
+	seriesSlug := c.Param("seriesSlug")
+
+	posts, err := nc.Storage.GetPostsBySeries(c.Request.Context(), seriesSlug)
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	if len(posts) == 0 {
+		return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{
+			"seriesSlug": fmt.Sprintf("cannot find the series(slug: %s)", seriesSlug),
+		}}, nil
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": posts}}, nil
+}
+
+// GetSeries returns the series having the published posts with their post count
+func (nc *NewsController) GetSeries(c *gin.Context) (int, gin.H, error) {
+	series, err := nc.Storage.GetSeries(c.Request.Context())
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": emptyIfNil(series)}}, nil
+}
+
+// adjacentPost is the meta of the post published immediately before or after the current one
+type adjacentPost struct {
+	Slug          string        `json:"slug"`
+	Title         string        `json:"title"`
+	HeroImage     *models.Image `json:"hero_image"`
+	PublishedDate time.Time     `json:"published_date"`
+}
+
+// getAdjacentPost finds the published post next to the post of `:slug` by `publishedDate`.
+// `record` is null if the current post is the first or the last published one.
+func (nc *NewsController) getAdjacentPost(c *gin.Context, next bool) (int, gin.H, error) {
+	var posts []models.Post
+
+	current, err := nc.Storage.GetPostBySlug(c.Param("slug"))
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	if current.State != postStatePublished {
+		return http.StatusNotFound, gin.H{"status": "Record Not Found", "error": "Record Not Found"}, nil
+	}
+
+	mq := models.MongoQuery{State: postStatePublished}
+	sort := "-publishedDate"
+	if next {
+		mq.PublishedDate.GT = current.PublishedDate
+		sort = "publishedDate"
+	} else {
+		mq.PublishedDate.LT = current.PublishedDate
+	}
+
+	if posts, _, err = nc.Storage.GetMetaOfPosts(c.Request.Context(), mq, 1, 0, sort, []string{"hero_image"}); err != nil {
+		return toPostResponse(err)
+	}
+
+	if len(posts) == 0 {
+		return http.StatusOK, gin.H{"status": "ok", "record": nil}, nil
+	}
+
+	return http.StatusOK, gin.H{"status": "ok", "record": adjacentPost{
+		Slug:          posts[0].Slug,
+		Title:         posts[0].Title,
+		HeroImage:     posts[0].HeroImage,
+		PublishedDate: posts[0].PublishedDate,
+	}}, nil
+}
+
+// GetPreviousPost receive HTTP GET method request, and return the post published immediately before the certain post.
+func (nc *NewsController) GetPreviousPost(c *gin.Context) (int, gin.H, error) {
+	return nc.getAdjacentPost(c, false)
+}
+
+// GetNextPost receive HTTP GET method request, and return the post published immediately after the certain post.
+func (nc *NewsController) GetNextPost(c *gin.Context) (int, gin.H, error) {
+	return nc.getAdjacentPost(c, true)
+}
+
+// GetRandomPost returns a random published post, optionally in the category of `category` url query param.
+// The slugs in `exclude`(comma separated) url query param, e.g. the posts recently seen, are not returned.
+func (nc *NewsController) GetRandomPost(c *gin.Context) (int, gin.H, error) {
+	var category bson.ObjectId
+	var excluded []string
+
+	if _category := c.Query("category"); _category != "" {
+		if !bson.IsObjectIdHex(_category) {
+			return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+				"category": "should be a mongo ObjectId",
+			}}, nil
+		}
+		category = bson.ObjectIdHex(_category)
+	}
+
+	for _, slug := range strings.Split(c.Query("exclude"), ",") {
+		if slug = strings.TrimSpace(slug); slug != "" {
+			excluded = append(excluded, slug)
+		}
+	}
+
+	// each request gets a different post
+	c.Header("Cache-Control", "no-store")
+
+	post, err := nc.Storage.GetRandomPost(category, excluded)
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	statusCode, resp := singleResponse(post)
+	return statusCode, resp, nil
+}
+
+// minArchiveYear is the earliest year of the archive
+const minArchiveYear = 2000
+
+// postArchiveListParams binds the list params of the archive, which are always sorted by publishedDate descendingly
+var postArchiveListParams = ListParamsBinder{
+	DefaultLimit: 10,
+	MaxLimit:     100,
+}
+
+// GetPostsByYearMonth returns the posts published within the month of `year` and `month` path params,
+// e.g. `/v1/posts/by-date/2020/06`, sorted by publishedDate descendingly and paginated by `limit` and `offset`.
+func (nc *NewsController) GetPostsByYearMonth(c *gin.Context) (int, gin.H, error) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil || year < minArchiveYear {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"year": "should be an integer not before 2000"}}, nil
+	}
+
+	month, err := strconv.Atoi(c.Param("month"))
+	if err != nil || month < 1 || month > 12 {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"month": "should be an integer between 1 and 12"}}, nil
+	}
+
+	params, err := postArchiveListParams.BindListParams(c)
+	if err != nil {
+		return listParamsFailResponse(err)
+	}
+
+	posts, total, err := nc.Storage.GetPostsByYearMonth(year, month, params.Limit, params.Offset)
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	statusCode, resp := paginatedResponse(posts, total, params.Offset, params.Limit)
+	return statusCode, resp, nil
+}
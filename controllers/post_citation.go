@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+)
+
+const (
+	citationFormatCSL    = "csl"
+	citationFormatBibTeX = "bibtex"
+
+	citationContainerTitle = "報導者 The Reporter"
+	citationLanguage       = "zh-TW"
+)
+
+// bibtexEscaper escapes the special characters of LaTeX in the field values
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// bibtexKeyPattern matches the characters not allowed in the citation key
+var bibtexKeyPattern = regexp.MustCompile(`[^A-Za-z0-9_:-]+`)
+
+var bibtexMonths = [...]string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+// cslName is the name variable of CSL-JSON, the names of authors are literal
+// since they are not separated into the family and given names
+type cslName struct {
+	Literal string `json:"literal"`
+}
+
+// cslDate is the date variable of CSL-JSON in `[[year, month, day]]`
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// cslItem is the citation item of CSL-JSON(Citation Style Language)
+type cslItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title"`
+	ContainerTitle string    `json:"container-title"`
+	Author         []cslName `json:"author,omitempty"`
+	URL            string    `json:"URL"`
+	Issued         *cslDate  `json:"issued,omitempty"`
+	Language       string    `json:"language"`
+}
+
+type postMetaGetter interface {
+	GetMetaOfPosts(context.Context, models.MongoQuery, int, int, string, []string) ([]models.Post, int, error)
+}
+
+// NewPostCitationController ...
+func NewPostCitationController(s postMetaGetter) *PostCitationController {
+	return &PostCitationController{Storage: s}
+}
+
+// PostCitationController formats the posts into the citation metadata
+type PostCitationController struct {
+	Storage postMetaGetter
+}
+
+// GetCitationOfAPost returns the citation of the post of `:slug` in CSL-JSON,
+// or in BibTeX if `format=bibtex` is provided.
+// The citations are cached publicly, so the accessed date is left out of them
+// and stamped by the client when the citation is retrieved.
+func (pcc *PostCitationController) GetCitationOfAPost(c *gin.Context) {
+	format := c.DefaultQuery("format", citationFormatCSL)
+	if format != citationFormatCSL && format != citationFormatBibTeX {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"format": fmt.Sprintf("should be %s or %s", citationFormatCSL, citationFormatBibTeX),
+		}})
+		return
+	}
+
+	posts, _, err := pcc.Storage.GetMetaOfPosts(c.Request.Context(), models.MongoQuery{Slug: c.Param("slug")}, 1, 0, "-publishedDate", []string{"writters"})
+	if err != nil {
+		code, body, _ := toPostResponse(err)
+		c.JSON(code, body)
+		return
+	}
+
+	if len(posts) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"status": "Record Not Found", "error": "Record Not Found"})
+		return
+	}
+
+	if failBody, available := checkPostCountry(c, posts[0].Slug, posts[0].RestrictedCountries); !available {
+		c.JSON(http.StatusUnavailableForLegalReasons, failBody)
+		return
+	}
+
+	if format == citationFormatBibTeX {
+		c.Data(http.StatusOK, "application/x-bibtex; charset=utf-8", []byte(toBibTeX(posts[0])))
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.citationstyles.csl+json; charset=utf-8")
+	c.JSON(http.StatusOK, []cslItem{toCSLItem(posts[0])})
+}
+
+func postURL(post models.Post) string {
+	return fmt.Sprintf("%s/a/%s", globals.MainSiteOrigin, post.Slug)
+}
+
+func toCSLDate(t time.Time) cslDate {
+	return cslDate{DateParts: [][]int{{t.Year(), int(t.Month()), t.Day()}}}
+}
+
+func toCSLItem(post models.Post) cslItem {
+	item := cslItem{
+		ID:             post.Slug,
+		Type:           "article-newspaper",
+		Title:          post.Title,
+		ContainerTitle: citationContainerTitle,
+		URL:            postURL(post),
+		Language:       citationLanguage,
+	}
+
+	for _, author := range post.Writters {
+		item.Author = append(item.Author, cslName{Literal: author.Name})
+	}
+
+	if !post.PublishedDate.IsZero() {
+		issued := toCSLDate(post.PublishedDate)
+		item.Issued = &issued
+	}
+	return item
+}
+
+// toBibTeX formats the post into an article entry of BibTeX
+func toBibTeX(post models.Post) string {
+	var fields [][2]string
+	var authors []string
+
+	for _, author := range post.Writters {
+		// the braces keep the name as it is rather than parsing it into the family and given names
+		authors = append(authors, "{"+bibtexEscaper.Replace(author.Name)+"}")
+	}
+	if len(authors) > 0 {
+		fields = append(fields, [2]string{"author", strings.Join(authors, " and ")})
+	}
+
+	fields = append(fields,
+		[2]string{"title", bibtexEscaper.Replace(post.Title)},
+		[2]string{"journal", bibtexEscaper.Replace(citationContainerTitle)},
+	)
+
+	if !post.PublishedDate.IsZero() {
+		fields = append(fields,
+			[2]string{"year", fmt.Sprint(post.PublishedDate.Year())},
+			[2]string{"month", bibtexMonths[post.PublishedDate.Month()-1]},
+		)
+	}
+
+	fields = append(fields,
+		[2]string{"url", postURL(post)},
+		[2]string{"language", citationLanguage},
+	)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@article{%s,\n", bibtexKeyPattern.ReplaceAllString(post.Slug, "-"))
+	for _, field := range fields {
+		fmt.Fprintf(&b, "  %s = {%s},\n", field[0], field[1])
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+)
+
+type mockPostMetaGetter struct {
+	posts []models.Post
+}
+
+func (m *mockPostMetaGetter) GetMetaOfPosts(ctx context.Context, mq models.MongoQuery, limit, offset int, sort string, embedded []string) ([]models.Post, int, error) {
+	var posts []models.Post
+	for _, post := range m.posts {
+		if post.Slug == mq.Slug {
+			posts = append(posts, post)
+		}
+	}
+	return posts, len(posts), nil
+}
+
+func TestGetCitationOfAPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := &mockPostMetaGetter{posts: []models.Post{{
+		Slug:          "mock-post",
+		Title:         "50% of {the} cases & more",
+		Writters:      []models.Author{{Name: "writer 1"}, {Name: "writer 2"}},
+		PublishedDate: time.Date(2020, time.June, 8, 16, 0, 0, 0, time.UTC),
+	}}}
+
+	engine := gin.New()
+	engine.GET("/v1/posts/:slug/citations", NewPostCitationController(s).GetCitationOfAPost)
+
+	t.Run("Given CSL-JSON", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/v1/posts/mock-post/citations", nil))
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expect status %d, but got %d", http.StatusOK, resp.Code)
+		}
+		if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/vnd.citationstyles.csl+json") {
+			t.Errorf("expect CSL-JSON content type, but got %s", ct)
+		}
+
+		var items []map[string]interface{}
+		if err := json.Unmarshal(resp.Body.Bytes(), &items); err != nil || len(items) != 1 {
+			t.Fatalf("expect a CSL-JSON item, but got %s", resp.Body.String())
+		}
+
+		item := items[0]
+		for field, want := range map[string]interface{}{
+			"id":              "mock-post",
+			"type":            "article-newspaper",
+			"title":           "50% of {the} cases & more",
+			"container-title": citationContainerTitle,
+			"URL":             "https://www.twreporter.org/a/mock-post",
+		} {
+			if item[field] != want {
+				t.Errorf("expect %s to be %v, but got %v", field, want, item[field])
+			}
+		}
+
+		authors, _ := json.Marshal(item["author"])
+		if string(authors) != `[{"literal":"writer 1"},{"literal":"writer 2"}]` {
+			t.Errorf("expect the literal names of the writers, but got %s", authors)
+		}
+
+		issued, _ := json.Marshal(item["issued"])
+		if string(issued) != `{"date-parts":[[2020,6,8]]}` {
+			t.Errorf("expect the published date, but got %s", issued)
+		}
+		if _, ok := item["accessed"]; ok {
+			t.Errorf("expect the accessed date left out of the cached citation, but got %v", item)
+		}
+	})
+
+	t.Run("Given BibTeX", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/v1/posts/mock-post/citations?format=bibtex", nil))
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expect status %d, but got %d", http.StatusOK, resp.Code)
+		}
+		if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/x-bibtex") {
+			t.Errorf("expect BibTeX content type, but got %s", ct)
+		}
+
+		want := "@article{mock-post,\n" +
+			"  author = {{writer 1} and {writer 2}},\n" +
+			"  title = {50\\% of \\{the\\} cases \\& more},\n" +
+			"  journal = {報導者 The Reporter},\n" +
+			"  year = {2020},\n" +
+			"  month = {jun},\n" +
+			"  url = {https://www.twreporter.org/a/mock-post},\n" +
+			"  language = {zh-TW},\n" +
+			"}\n"
+		if got := resp.Body.String(); got != want {
+			t.Errorf("expect BibTeX\n%s\nbut got\n%s", want, got)
+		}
+	})
+
+	t.Run("Given an unknown format", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/v1/posts/mock-post/citations?format=ris", nil))
+
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expect status %d, but got %d", http.StatusBadRequest, resp.Code)
+		}
+	})
+
+	t.Run("Given a non-existing post", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/v1/posts/not-found/citations", nil))
+
+		if resp.Code != http.StatusNotFound {
+			t.Errorf("expect status %d, but got %d", http.StatusNotFound, resp.Code)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type postDuplicator interface {
+	DuplicatePost(string) (models.Post, error)
+}
+
+// NewPostDuplicateController ...
+func NewPostDuplicateController(s postDuplicator) *PostDuplicateController {
+	return &PostDuplicateController{Storage: s}
+}
+
+// PostDuplicateController clones the posts for the editors to start a similar post
+type PostDuplicateController struct {
+	Storage postDuplicator
+}
+
+// DuplicatePost clones the post of `:slug` as a draft with a new unique slug.
+// The embedded media are kept as they are, the editors can update them afterwards.
+func (pdc *PostDuplicateController) DuplicatePost(c *gin.Context) (int, gin.H, error) {
+	slug := c.Param("slug")
+
+	post, err := pdc.Storage.DuplicatePost(slug)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{
+				"slug": fmt.Sprintf("cannot find the post(slug: %s)", slug),
+			}}, nil
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusCreated, gin.H{"status": "success", "data": post}, nil
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockPostDuplicator struct {
+	posts map[string]models.Post
+}
+
+func (m *mockPostDuplicator) DuplicatePost(slug string) (models.Post, error) {
+	post, ok := m.posts[slug]
+	if !ok {
+		return models.Post{}, errors.WithStack(storage.ErrMgoNotFound)
+	}
+	post.Slug = slug + "-copy"
+	post.Title = post.Title + " (copy)"
+	post.State = "draft"
+	return post, nil
+}
+
+func TestDuplicatePost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pdc := NewPostDuplicateController(&mockPostDuplicator{posts: map[string]models.Post{
+		"mock-post": {Slug: "mock-post", Title: "mock title", State: "published"},
+	}})
+	engine := gin.New()
+	engine.POST("/v1/admin/posts/:slug/duplicate", func(c *gin.Context) {
+		code, body, _ := pdc.DuplicatePost(c)
+		c.JSON(code, body)
+	})
+
+	t.Run("Given an existing post", func(t *testing.T) {
+		var res struct {
+			Status string      `json:"status"`
+			Data   models.Post `json:"data"`
+		}
+
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/v1/admin/posts/mock-post/duplicate", nil))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("expect status %d, but got %d", http.StatusCreated, resp.Code)
+		}
+
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		if res.Status != "success" || res.Data.Slug != "mock-post-copy" || res.Data.State != "draft" {
+			t.Errorf("expect the draft copy of mock-post, but got %v", res)
+		}
+	})
+
+	t.Run("Given a post not existing", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/v1/admin/posts/unknown-post/duplicate", nil))
+		if resp.Code != http.StatusNotFound {
+			t.Errorf("expect status %d, but got %d", http.StatusNotFound, resp.Code)
+		}
+	})
+}
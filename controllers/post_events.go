@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/storage"
+)
+
+// postEventsHeartbeatInterval is the interval the comment is sent on the idle stream,
+// so the proxies do not close the connection and the disconnected clients are noticed
+var postEventsHeartbeatInterval = 15 * time.Second
+
+type postWatcher interface {
+	WatchPosts(context.Context, func(storage.PostEvent) error) error
+}
+
+// NewPostEventsController ...
+// At most maxConnections clients are streamed at the same time, zero or negative means unlimited.
+func NewPostEventsController(s postWatcher, maxConnections int) *PostEventsController {
+	pec := &PostEventsController{Storage: s}
+	if maxConnections > 0 {
+		pec.connections = make(chan struct{}, maxConnections)
+	}
+	return pec
+}
+
+// PostEventsController pushes the changes of posts to the clients by Server-Sent Events
+type PostEventsController struct {
+	Storage postWatcher
+	// connections is the semaphore limiting the concurrent streams
+	connections chan struct{}
+}
+
+// writeServerSentEvent writes the event in the format of Server-Sent Events and flushes it to the client
+func writeServerSentEvent(w gin.ResponseWriter, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return errors.WithStack(err)
+	}
+	w.Flush()
+	return nil
+}
+
+// StreamPostEvents forwards the changes of the published posts to the client.
+// The handler blocks on the change stream, which is closed once the client disconnects,
+// since the request context is canceled then.
+// A heartbeat comment is sent every `postEventsHeartbeatInterval` while the stream is open.
+func (pec *PostEventsController) StreamPostEvents(c *gin.Context) {
+	if pec.connections != nil {
+		select {
+		case pec.connections <- struct{}{}:
+			defer func() { <-pec.connections }()
+		default:
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "error", "message": "too many event stream connections"})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// disable the response buffering of nginx
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	// the events and heartbeats are written by different goroutines
+	var mu sync.Mutex
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(postEventsHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				mu.Lock()
+				fmt.Fprint(c.Writer, ": heartbeat\n\n")
+				c.Writer.Flush()
+				mu.Unlock()
+			}
+		}
+	}()
+
+	err := pec.Storage.WatchPosts(c.Request.Context(), func(event storage.PostEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return writeServerSentEvent(c.Writer, event.Type, event)
+	})
+
+	close(stop)
+	wg.Wait()
+
+	if err != nil {
+		logError(errors.WithMessage(err, "fail to stream post events"))
+		// the stream is started already, notify the client by an error event
+		writeServerSentEvent(c.Writer, "error", gin.H{"message": "event stream is interrupted"})
+	}
+}
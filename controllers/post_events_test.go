@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/storage"
+)
+
+type mockPostWatcher struct {
+	events []storage.PostEvent
+	// block blocks the watch until the request context is done after emitting the events
+	block   bool
+	started chan struct{}
+}
+
+func (m *mockPostWatcher) WatchPosts(ctx context.Context, fn func(storage.PostEvent) error) error {
+	for _, event := range m.events {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	if m.block {
+		m.started <- struct{}{}
+		<-ctx.Done()
+	}
+	return nil
+}
+
+func TestStreamPostEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	updatedAt := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+	s := &mockPostWatcher{events: []storage.PostEvent{
+		{Type: storage.PostEventUpdate, ID: "5edf118c3e631f0600198935", Slug: "a-slug", UpdatedAt: &updatedAt},
+		{Type: storage.PostEventUnpublish, ID: "5edf118c3e631f0600198937", Slug: "b-slug"},
+		{Type: storage.PostEventDelete, ID: "5edf118c3e631f0600198936"},
+	}}
+
+	engine := gin.New()
+	engine.GET("/v1/events/posts", NewPostEventsController(s, 1).StreamPostEvents)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/events/posts", nil)
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %s", contentType)
+	}
+
+	want := "event: update\ndata: {\"type\":\"update\",\"id\":\"5edf118c3e631f0600198935\",\"slug\":\"a-slug\",\"updatedAt\":\"2020-06-01T00:00:00Z\"}\n\n" +
+		"event: unpublish\ndata: {\"type\":\"unpublish\",\"id\":\"5edf118c3e631f0600198937\",\"slug\":\"b-slug\"}\n\n" +
+		"event: delete\ndata: {\"type\":\"delete\",\"id\":\"5edf118c3e631f0600198936\"}\n\n"
+	if w.Body.String() != want {
+		t.Errorf("expected events %q, got %q", want, w.Body.String())
+	}
+}
+
+func TestStreamPostEventsMaxConnections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := &mockPostWatcher{block: true, started: make(chan struct{})}
+	engine := gin.New()
+	engine.GET("/v1/events/posts", NewPostEventsController(s, 1).StreamPostEvents)
+
+	// the first client holds the only connection until it disconnects
+	ctx, disconnect := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req, _ := http.NewRequest(http.MethodGet, "/v1/events/posts", nil)
+		engine.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+	}()
+	<-s.started
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/events/posts", nil)
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	// the stream ends and releases the connection once the client disconnects
+	disconnect()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stream to end after the client disconnects")
+	}
+
+	s.block = false
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "error") {
+		t.Errorf("expected the connection released, got status %d and body %q", w.Code, w.Body.String())
+	}
+}
+
+func TestStreamPostEventsHeartbeat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	defaultInterval := postEventsHeartbeatInterval
+	postEventsHeartbeatInterval = 10 * time.Millisecond
+	defer func() { postEventsHeartbeatInterval = defaultInterval }()
+
+	s := &mockPostWatcher{block: true, started: make(chan struct{}, 1)}
+	engine := gin.New()
+	engine.GET("/v1/events/posts", NewPostEventsController(s, 1).StreamPostEvents)
+
+	// the client disconnects after a few heartbeats
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/events/posts", nil)
+	engine.ServeHTTP(w, req.WithContext(ctx))
+
+	if !strings.Contains(w.Body.String(), ": heartbeat\n\n") {
+		t.Errorf("expected heartbeats in the idle stream, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+const (
+	exportFormatJSON   = "json"
+	exportFormatNDJSON = "ndjson"
+
+	// exportRangeUnit is the range unit resuming the export after the post of the id, e.g. `Range: posts=<id>-`
+	exportRangeUnit = "posts"
+)
+
+// parseExportRange parses the id of the last post exported from `Range: posts=<id>-`.
+// The ranges of the other units, e.g. bytes, are ignored as RFC 7233, and ok is false.
+func parseExportRange(header string) (after string, ok bool, err error) {
+	if !strings.HasPrefix(header, exportRangeUnit+"=") {
+		return "", false, nil
+	}
+
+	spec := strings.TrimPrefix(header, exportRangeUnit+"=")
+	if !strings.HasSuffix(spec, "-") || !bson.IsObjectIdHex(strings.TrimSuffix(spec, "-")) {
+		return "", true, errors.New(fmt.Sprintf("should be %s=<id>-, but got %s", exportRangeUnit, header))
+	}
+	return strings.TrimSuffix(spec, "-"), true, nil
+}
+
+type postIterator interface {
+	IteratePosts(models.MongoQuery, func(models.Post) error) error
+}
+
+// NewPostExportController ...
+func NewPostExportController(s postIterator) *PostExportController {
+	return &PostExportController{Storage: s}
+}
+
+// PostExportController exports the posts for the backup of CMS
+type PostExportController struct {
+	Storage postIterator
+}
+
+// ExportPosts streams the posts matching `state` as a JSON array, or as NDJSON if `format=ndjson`.
+// The posts are encoded one by one while iterating, so the whole export is never held in memory.
+// The response is gzip compressed if the client accepts it.
+// The posts are always in the order of their ids, so an interrupted export is resumed by `after`,
+// or `Range: posts=<id>-`, with the id of the last post received, which responds 206 with the posts after it.
+func (pec *PostExportController) ExportPosts(c *gin.Context) {
+	format := c.DefaultQuery("format", exportFormatJSON)
+	if format != exportFormatJSON && format != exportFormatNDJSON {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"format": fmt.Sprintf("should be %s or %s", exportFormatJSON, exportFormatNDJSON),
+		}})
+		return
+	}
+
+	c.Header("Accept-Ranges", exportRangeUnit)
+
+	after, hasAfter := c.GetQuery("after")
+	if hasAfter && !bson.IsObjectIdHex(after) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"after": "should be the id of the last post received",
+		}})
+		return
+	}
+	if !hasAfter {
+		var err error
+		if after, hasAfter, err = parseExportRange(c.GetHeader("Range")); err != nil {
+			c.Header("Content-Range", fmt.Sprintf("%s */*", exportRangeUnit))
+			c.JSON(http.StatusRequestedRangeNotSatisfiable, gin.H{"status": "fail", "data": gin.H{
+				"req.Headers.Range": err.Error(),
+			}})
+			return
+		}
+	}
+
+	query := models.MongoQuery{State: c.Query("state")}
+	status := http.StatusOK
+	if hasAfter {
+		query.IDs.GT = bson.ObjectIdHex(after)
+		status = http.StatusPartialContent
+		c.Header("Content-Range", fmt.Sprintf("%s %s-", exportRangeUnit, after))
+	}
+
+	contentType := "application/json"
+	if format == exportFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"posts-export-%s.%s\"", time.Now().Format("2006-01-02"), format))
+	c.Header("Vary", "Accept-Encoding")
+
+	var w io.Writer = c.Writer
+	if strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+		c.Header("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(c.Writer)
+		defer gw.Close()
+		w = gw
+	}
+
+	c.Status(status)
+
+	ctx := c.Request.Context()
+	if err := writePosts(w, format, func(fn func(models.Post) error) error {
+		return pec.Storage.IteratePosts(query, func(post models.Post) error {
+			// stop iterating once the request is timed out or the client is gone
+			if err := ctx.Err(); err != nil {
+				return errors.WithStack(err)
+			}
+			return fn(post)
+		})
+	}); err != nil {
+		// the response is sent partially, the error can only be logged
+		logError(errors.WithMessage(err, "fail to export posts"))
+	}
+}
+
+// writePosts encodes the posts provided by `iterate` in the format to w
+func writePosts(w io.Writer, format string, iterate func(func(models.Post) error) error) error {
+	encoder := json.NewEncoder(w)
+
+	if format == exportFormatNDJSON {
+		return iterate(func(post models.Post) error {
+			return errors.WithStack(encoder.Encode(post))
+		})
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return errors.WithStack(err)
+	}
+
+	first := true
+	if err := iterate(func(post models.Post) error {
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		first = false
+		return errors.WithStack(encoder.Encode(post))
+	}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "]")
+	return errors.WithStack(err)
+}
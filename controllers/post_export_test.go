@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+type mockPostIterator struct {
+	posts []models.Post
+	query models.MongoQuery
+}
+
+func (m *mockPostIterator) IteratePosts(mq models.MongoQuery, fn func(models.Post) error) error {
+	m.query = mq
+	for _, post := range m.posts {
+		// the posts are in the order of their ids
+		if mq.IDs.GT != "" && post.ID.Hex() <= mq.IDs.GT.Hex() {
+			continue
+		}
+		if err := fn(post); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func serveExportPosts(s postIterator, target string, header http.Header) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/v1/admin/posts/export", NewPostExportController(s).ExportPosts)
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key := range header {
+		req.Header.Set(key, header.Get(key))
+	}
+	resp := httptest.NewRecorder()
+	engine.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestExportPosts(t *testing.T) {
+	s := &mockPostIterator{posts: []models.Post{
+		{Slug: "post-1", Title: "post 1", State: "published"},
+		{Slug: "post-2", Title: "post 2", State: "published"},
+	}}
+
+	t.Run("JSON", func(t *testing.T) {
+		resp := serveExportPosts(s, "/v1/admin/posts/export?state=published", nil)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expect status %d, but got %d", http.StatusOK, resp.Code)
+		}
+		if s.query.State != "published" {
+			t.Errorf("expect state filter published, but got %q", s.query.State)
+		}
+		if cd := resp.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment; filename=\"posts-export-") || !strings.HasSuffix(cd, ".json\"") {
+			t.Errorf("unexpected Content-Disposition %q", cd)
+		}
+
+		var posts []models.Post
+		if err := json.Unmarshal(resp.Body.Bytes(), &posts); err != nil {
+			t.Fatalf("expect a JSON array, but got %s: %s", resp.Body.String(), err.Error())
+		}
+		if len(posts) != 2 || posts[0].Slug != "post-1" || posts[1].Slug != "post-2" {
+			t.Errorf("unexpected posts %#v", posts)
+		}
+	})
+
+	t.Run("Empty JSON", func(t *testing.T) {
+		resp := serveExportPosts(&mockPostIterator{}, "/v1/admin/posts/export", nil)
+		if body := resp.Body.String(); body != "[]" {
+			t.Errorf("expect empty array, but got %q", body)
+		}
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		resp := serveExportPosts(s, "/v1/admin/posts/export?format=ndjson", nil)
+		if ct := resp.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Errorf("expect Content-Type application/x-ndjson, but got %q", ct)
+		}
+
+		var slugs []string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var post models.Post
+			if err := json.Unmarshal(scanner.Bytes(), &post); err != nil {
+				t.Fatalf("expect a post per line, but got %q", scanner.Text())
+			}
+			slugs = append(slugs, post.Slug)
+		}
+		if len(slugs) != 2 {
+			t.Errorf("expect 2 lines, but got %v", slugs)
+		}
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		resp := serveExportPosts(s, "/v1/admin/posts/export", http.Header{"Accept-Encoding": {"gzip, deflate"}})
+		if ce := resp.Header().Get("Content-Encoding"); ce != "gzip" {
+			t.Fatalf("expect Content-Encoding gzip, but got %q", ce)
+		}
+
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			t.Fatalf("expect gzip body: %s", err.Error())
+		}
+		var posts []models.Post
+		if err = json.NewDecoder(gr).Decode(&posts); err != nil || len(posts) != 2 {
+			t.Errorf("expect 2 posts, but got %#v(%v)", posts, err)
+		}
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		resp := serveExportPosts(s, "/v1/admin/posts/export?format=xml", nil)
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expect status %d, but got %d", http.StatusBadRequest, resp.Code)
+		}
+	})
+}
+
+func TestExportPostsResume(t *testing.T) {
+	s := &mockPostIterator{}
+	for i := 1; i <= 5; i++ {
+		s.posts = append(s.posts, models.Post{ID: bson.ObjectIdHex(fmt.Sprintf("5edf118c3e631f060019893%d", i)), Slug: fmt.Sprintf("post-%d", i)})
+	}
+
+	full := serveExportPosts(s, "/v1/admin/posts/export?format=ndjson", nil)
+	if full.Code != http.StatusOK || full.Header().Get("Accept-Ranges") != exportRangeUnit {
+		t.Fatalf("expect status %d accepting ranges, but got %d, %v", http.StatusOK, full.Code, full.Header())
+	}
+	lines := strings.SplitAfter(full.Body.String(), "\n")
+
+	// the stream stopped after the second post
+	var last models.Post
+	json.Unmarshal([]byte(lines[1]), &last)
+
+	cases := []struct {
+		name   string
+		target string
+		header http.Header
+	}{
+		{name: "Given after", target: "/v1/admin/posts/export?format=ndjson&after=" + last.ID.Hex()},
+		{name: "Given Range", target: "/v1/admin/posts/export?format=ndjson", header: http.Header{"Range": {"posts=" + last.ID.Hex() + "-"}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := serveExportPosts(s, tc.target, tc.header)
+			if resp.Code != http.StatusPartialContent {
+				t.Fatalf("expect status %d, but got %d", http.StatusPartialContent, resp.Code)
+			}
+			if cr := resp.Header().Get("Content-Range"); cr != "posts "+last.ID.Hex()+"-" {
+				t.Errorf("unexpected Content-Range %q", cr)
+			}
+			if got, want := lines[0]+lines[1]+resp.Body.String(), full.Body.String(); got != want {
+				t.Errorf("expect the resumed export %q, but got %q", want, got)
+			}
+		})
+	}
+
+	t.Run("Given an invalid after", func(t *testing.T) {
+		resp := serveExportPosts(s, "/v1/admin/posts/export?after=post-2", nil)
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expect status %d, but got %d", http.StatusBadRequest, resp.Code)
+		}
+	})
+
+	t.Run("Given an invalid Range", func(t *testing.T) {
+		resp := serveExportPosts(s, "/v1/admin/posts/export", http.Header{"Range": {"posts=0-10"}})
+		if resp.Code != http.StatusRequestedRangeNotSatisfiable {
+			t.Errorf("expect status %d, but got %d", http.StatusRequestedRangeNotSatisfiable, resp.Code)
+		}
+	})
+
+	t.Run("Given a bytes Range", func(t *testing.T) {
+		resp := serveExportPosts(s, "/v1/admin/posts/export?format=ndjson", http.Header{"Range": {"bytes=0-10"}})
+		if resp.Code != http.StatusOK || resp.Body.String() != full.Body.String() {
+			t.Errorf("expect the full export ignoring the range, but got %d", resp.Code)
+		}
+	})
+}
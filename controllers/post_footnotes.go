@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type footnotesGetter interface {
+	GetFootnotesOfPost(string) (models.Post, error)
+}
+
+// NewPostFootnotesController returns a PostFootnotesController with the storage of the footnotes
+func NewPostFootnotesController(s footnotesGetter) *PostFootnotesController {
+	return &PostFootnotesController{Storage: s}
+}
+
+// PostFootnotesController serves the footnotes of the posts apart from the content, e.g. for the footnote tooltips
+type PostFootnotesController struct {
+	Storage footnotesGetter
+}
+
+// GetFootnotesOfAPost responds the footnotes of the published post in the order of the index
+func (pfc *PostFootnotesController) GetFootnotesOfAPost(c *gin.Context) (int, gin.H, error) {
+	slug := c.Param("slug")
+
+	post, err := pfc.Storage.GetFootnotesOfPost(slug)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return postNotFoundResponse(slug)
+		}
+		return toResponse(err)
+	}
+
+	if failBody, available := checkPostCountry(c, slug, post.RestrictedCountries); !available {
+		return restrictedPostResponse(failBody)
+	}
+
+	footnotes := post.Footnotes
+	sort.SliceStable(footnotes, func(i, j int) bool {
+		return footnotes[i].Index < footnotes[j].Index
+	})
+	for i := range footnotes {
+		if footnotes[i].References == nil {
+			footnotes[i].References = []string{}
+		}
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": emptyIfNil(footnotes)}}, nil
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockFootnotesStorage struct {
+	footnotes  map[string][]models.Footnote
+	restricted map[string][]string
+}
+
+func (m *mockFootnotesStorage) GetFootnotesOfPost(slug string) (models.Post, error) {
+	if footnotes, ok := m.footnotes[slug]; ok {
+		return models.Post{Footnotes: footnotes, RestrictedCountries: m.restricted[slug]}, nil
+	}
+	return models.Post{}, storage.ErrMgoNotFound
+}
+
+func TestGetFootnotesOfAPost(t *testing.T) {
+	s := &mockFootnotesStorage{footnotes: map[string][]models.Footnote{
+		"footnoted-post": {
+			{Index: 2, Text: "second", References: []string{"https://example.com/2"}},
+			{Index: 1, Text: "first"},
+		},
+		"no-footnotes-post": nil,
+	}}
+
+	cases := []struct {
+		name     string
+		slug     string
+		wantCode int
+		wantData gin.H
+	}{
+		{
+			name:     "Given a post with footnotes",
+			slug:     "footnoted-post",
+			wantCode: http.StatusOK,
+			wantData: gin.H{"records": []models.Footnote{
+				{Index: 1, Text: "first", References: []string{}},
+				{Index: 2, Text: "second", References: []string{"https://example.com/2"}},
+			}},
+		},
+		{
+			name:     "Given a post without footnotes",
+			slug:     "no-footnotes-post",
+			wantCode: http.StatusOK,
+			wantData: gin.H{"records": []models.Footnote{}},
+		},
+		{name: "Given a post not found", slug: "not-found", wantCode: http.StatusNotFound},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts/"+tc.slug+"/footnotes", nil)
+			c.Params = gin.Params{{Key: "slug", Value: tc.slug}}
+
+			code, body, _ := NewPostFootnotesController(s).GetFootnotesOfAPost(c)
+
+			if code != tc.wantCode {
+				t.Errorf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if tc.wantData != nil && !reflect.DeepEqual(body["data"], tc.wantData) {
+				t.Errorf("expect data %v, but got %v", tc.wantData, body["data"])
+			}
+		})
+	}
+}
+
+func TestGetFootnotesOfARestrictedPost(t *testing.T) {
+	s := &mockFootnotesStorage{
+		footnotes:  map[string][]models.Footnote{"restricted-post": {{Index: 1, Text: "first"}}},
+		restricted: map[string][]string{"restricted-post": {"CN"}},
+	}
+
+	cases := []struct {
+		name     string
+		country  string
+		wantCode int
+	}{
+		{name: "Given the client country is restricted", country: "CN", wantCode: http.StatusUnavailableForLegalReasons},
+		{name: "Given the client country is not restricted", country: "TW", wantCode: http.StatusOK},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts/restricted-post/footnotes", nil)
+			c.Params = gin.Params{{Key: "slug", Value: "restricted-post"}}
+			c.Set(globals.ClientCountryProperty, tc.country)
+
+			code, _, _ := NewPostFootnotesController(s).GetFootnotesOfAPost(c)
+
+			if code != tc.wantCode {
+				t.Errorf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if got := resp.Header().Get("Cache-Control"); !strings.HasPrefix(got, "private") {
+				t.Errorf("expect private Cache-Control, but got %q", got)
+			}
+		})
+	}
+}
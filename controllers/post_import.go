@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+const (
+	// maxImportPosts is the maximum number of posts imported in a request
+	maxImportPosts     = 500
+	importPostsTimeout = 30 * time.Second
+)
+
+type postUpserter interface {
+	UpsertPosts(context.Context, []models.Post, uint) (storage.UpsertResult, error)
+}
+
+// NewPostImportController ...
+func NewPostImportController(s postUpserter) *PostImportController {
+	return &PostImportController{Storage: s}
+}
+
+// PostImportController imports the posts migrated from external CMS.
+// The cache is nil if it is not enabled.
+type PostImportController struct {
+	Storage postUpserter
+	Cache   postsCacheInvalidator
+}
+
+type importError struct {
+	Index int    `json:"index"`
+	Slug  string `json:"slug"`
+	Error string `json:"error"`
+}
+
+// ImportPosts validates the posts one by one and upserts the valid ones keyed by slug.
+// The invalid posts are reported in the summary without aborting the whole batch.
+// The previous versions of the updated posts are recorded along with the admin importing them.
+// The cached listed posts are invalidated if any post is created or updated.
+func (pic *PostImportController) ImportPosts(c *gin.Context) (int, gin.H, error) {
+	var posts []models.Post
+
+	if err := c.ShouldBindJSON(&posts); err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": fmt.Sprintf("should be an array of posts. %s", err.Error()),
+		}}, nil
+	}
+
+	if len(posts) == 0 || len(posts) > maxImportPosts {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": fmt.Sprintf("should contain 1 to %d posts", maxImportPosts),
+		}}, nil
+	}
+
+	var valid []models.Post
+	// indexes maps the index of valid posts to the index of request body
+	var indexes []int
+	var importErrors = make([]importError, 0)
+
+	for i, post := range posts {
+		if err := post.Validate(); err != nil {
+			importErrors = append(importErrors, importError{Index: i, Slug: post.Slug, Error: err.Error()})
+			continue
+		}
+		valid = append(valid, post)
+		indexes = append(indexes, i)
+	}
+
+	var result storage.UpsertResult
+	if len(valid) > 0 {
+		var err error
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), importPostsTimeout)
+		defer cancel()
+
+		editorID, _ := strconv.ParseUint(fmt.Sprint(c.Request.Context().Value(globals.AuthUserIDProperty)), 10, 64)
+
+		if result, err = pic.Storage.UpsertPosts(ctx, valid, uint(editorID)); err != nil {
+			return toResponse(err)
+		}
+
+		if pic.Cache != nil && result.Created+result.Updated > 0 {
+			// the posts are written already, the failure of invalidating the cache is only logged
+			if err = pic.Cache.InvalidatePosts(); err != nil {
+				log.Errorf("%+v", err)
+			}
+		}
+	}
+
+	for i, index := range indexes {
+		if err, ok := result.Errors[i]; ok {
+			importErrors = append(importErrors, importError{Index: index, Slug: posts[index].Slug, Error: err.Error()})
+		}
+	}
+
+	sort.Slice(importErrors, func(i, j int) bool {
+		return importErrors[i].Index < importErrors[j].Index
+	})
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"created": result.Created,
+		"updated": result.Updated,
+		"errors":  importErrors,
+	}}, nil
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockPostUpserter struct {
+	posts       []models.Post
+	invalidated bool
+}
+
+func (m *mockPostUpserter) InvalidatePosts() error {
+	m.invalidated = true
+	return nil
+}
+
+func (m *mockPostUpserter) UpsertPosts(ctx context.Context, posts []models.Post, editorID uint) (storage.UpsertResult, error) {
+	result := storage.UpsertResult{Errors: make(map[int]error)}
+	m.posts = posts
+	for i, post := range posts {
+		switch {
+		case post.Slug == "duplicate-key":
+			result.Errors[i] = errors.New("E11000 duplicate key error")
+		case strings.HasPrefix(post.Slug, "existing-"):
+			result.Updated++
+		default:
+			result.Created++
+		}
+	}
+	return result, nil
+}
+
+func TestImportPosts(t *testing.T) {
+	type summary struct {
+		Status string `json:"status"`
+		Data   struct {
+			Created int           `json:"created"`
+			Updated int           `json:"updated"`
+			Errors  []importError `json:"errors"`
+		} `json:"data"`
+	}
+
+	posts := []models.Post{
+		{Slug: "new-post", Title: "new post"},
+		{Slug: "", Title: "post without slug"},
+		{Slug: "existing-post", Title: "existing post"},
+		{Slug: "duplicate-key", Title: "duplicate key"},
+		{Slug: "post-without-title"},
+	}
+	body, _ := json.Marshal(posts)
+
+	gin.SetMode(gin.TestMode)
+	s := &mockPostUpserter{}
+	engine := gin.New()
+	engine.POST("/import", func(c *gin.Context) {
+		pic := NewPostImportController(s)
+		pic.Cache = s
+		status, resp, _ := pic.ImportPosts(c)
+		c.JSON(status, resp)
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, "/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	engine.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	var got summary
+	json.Unmarshal(resp.Body.Bytes(), &got)
+
+	if got.Data.Created != 1 || got.Data.Updated != 1 {
+		t.Errorf("expected 1 created and 1 updated, got %d created and %d updated", got.Data.Created, got.Data.Updated)
+	}
+
+	if len(s.posts) != 3 {
+		t.Errorf("expected only valid posts to be upserted, got %d posts", len(s.posts))
+	}
+
+	if !s.invalidated {
+		t.Errorf("expected the cached posts invalidated after the posts upserted")
+	}
+
+	wantIndexes := []int{1, 3, 4}
+	if len(got.Data.Errors) != len(wantIndexes) {
+		t.Fatalf("expected errors of post %v, got %v", wantIndexes, got.Data.Errors)
+	}
+	for i, index := range wantIndexes {
+		if got.Data.Errors[i].Index != index {
+			t.Errorf("expected error of post %d, got %v", index, got.Data.Errors[i])
+		}
+	}
+}
+
+func TestImportPostsBatchSize(t *testing.T) {
+	cases := []struct {
+		name  string
+		count int
+		want  int
+	}{
+		{name: "Given an empty batch", count: 0, want: http.StatusBadRequest},
+		{name: "Given the maximum batch", count: maxImportPosts, want: http.StatusOK},
+		{name: "Given a batch exceeding the maximum", count: maxImportPosts + 1, want: http.StatusBadRequest},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			posts := make([]models.Post, 0)
+			for i := 0; i < tc.count; i++ {
+				posts = append(posts, models.Post{Slug: fmt.Sprintf("post-%d", i), Title: "title"})
+			}
+			body, _ := json.Marshal(posts)
+
+			engine := gin.New()
+			engine.POST("/import", func(c *gin.Context) {
+				status, resp, _ := NewPostImportController(&mockPostUpserter{}).ImportPosts(c)
+				c.JSON(status, resp)
+			})
+
+			req, _ := http.NewRequest(http.MethodPost, "/import", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != tc.want {
+				t.Errorf("expected status %d, got %d", tc.want, resp.Code)
+			}
+		})
+	}
+}
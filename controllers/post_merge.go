@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type postMerger interface {
+	MergePosts(context.Context, string, string, uint) ([]string, error)
+}
+
+// postsCacheInvalidator deletes the cached listed posts after they are modified bypassing the cached storage
+type postsCacheInvalidator interface {
+	InvalidatePosts() error
+}
+
+type bookmarkMover interface {
+	MoveBookmarksOfPost(string, string) (int, error)
+}
+
+// NewPostMergeController returns a PostMergeController with the storage of posts and bookmarks
+func NewPostMergeController(s postMerger, bs bookmarkMover) *PostMergeController {
+	return &PostMergeController{Storage: s, BookmarkStorage: bs}
+}
+
+// PostMergeController merges the duplicate posts for the editors.
+// The cache is nil if it is not enabled.
+type PostMergeController struct {
+	Storage         postMerger
+	BookmarkStorage bookmarkMover
+	Cache           postsCacheInvalidator
+}
+
+type postMergeReqBody struct {
+	Source string `json:"source" binding:"required"`
+	Target string `json:"target" binding:"required"`
+}
+
+// MergePosts merges the source post into the target post of the body.
+// The source is soft-deleted with the redirect to the target, and its bookmarks are pointed to the target.
+// The bookmarks are in MySQL and moved after the posts are merged, the request could be retried if it fails.
+// The cached listed posts are invalidated after the merge, since both posts may appear in any of them.
+// Every merge is logged with the admin requesting it, and the previous version of the target is recorded.
+func (pmc *PostMergeController) MergePosts(c *gin.Context) (int, gin.H, error) {
+	var reqBody postMergeReqBody
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": fmt.Sprintf("should be {\"source\": \"slug1\", \"target\": \"slug2\"}. %s", err.Error()),
+		}}, nil
+	}
+
+	if reqBody.Source == reqBody.Target {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"target": "should be different from the source",
+		}}, nil
+	}
+
+	editorID, _ := strconv.ParseUint(fmt.Sprint(c.Request.Context().Value(globals.AuthUserIDProperty)), 10, 64)
+
+	legacySlugs, err := pmc.Storage.MergePosts(c.Request.Context(), reqBody.Source, reqBody.Target, uint(editorID))
+	if err != nil {
+		switch {
+		case storage.IsNotFound(err):
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{
+				"req.Body": fmt.Sprintf("cannot find the post(slug: %s) or the post(slug: %s)", reqBody.Source, reqBody.Target),
+			}}, nil
+		case storage.IsConflict(err):
+			return http.StatusConflict, gin.H{"status": "fail", "data": gin.H{
+				"source": fmt.Sprintf("post(slug: %s) is deleted or merged into another post already", reqBody.Source),
+			}}, nil
+		}
+		return toResponse(err)
+	}
+
+	if pmc.Cache != nil {
+		// the posts are merged already, the failure of invalidating the cache is only logged
+		if err = pmc.Cache.InvalidatePosts(); err != nil {
+			log.Errorf("%+v", err)
+		}
+	}
+
+	moved, err := pmc.BookmarkStorage.MoveBookmarksOfPost(reqBody.Source, reqBody.Target)
+	if err != nil {
+		return toResponse(err)
+	}
+
+	log.WithFields(log.Fields{
+		"source_slug": reqBody.Source,
+		"target_slug": reqBody.Target,
+		"user_id":     c.Request.Context().Value(globals.AuthUserIDProperty),
+	}).Info("merge posts")
+
+	if legacySlugs == nil {
+		legacySlugs = []string{}
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": models.PostMergeResult{
+		Slug:           reqBody.Target,
+		MergedSlug:     reqBody.Source,
+		LegacySlugs:    legacySlugs,
+		MovedBookmarks: moved,
+	}}, nil
+}
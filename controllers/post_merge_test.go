@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockPostMergeStorage struct {
+	posts       map[string]bool
+	merged      map[string]string
+	moved       map[string]string
+	invalidated bool
+}
+
+func (m *mockPostMergeStorage) MergePosts(ctx context.Context, source, target string, editorID uint) ([]string, error) {
+	if !m.posts[source] || !m.posts[target] {
+		return nil, storage.ErrMgoNotFound
+	}
+	if into, ok := m.merged[source]; ok && into != target {
+		return nil, errors.Wrap(storage.ErrUpdateConflict, "deleted already")
+	}
+	m.merged[source] = target
+	return []string{source}, nil
+}
+
+func (m *mockPostMergeStorage) InvalidatePosts() error {
+	m.invalidated = true
+	return nil
+}
+
+func (m *mockPostMergeStorage) MoveBookmarksOfPost(source, target string) (int, error) {
+	m.moved[source] = target
+	return 2, nil
+}
+
+func TestMergePosts(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		wantCode  int
+		wantMoved bool
+	}{
+		{name: "Given duplicate posts", body: `{"source": "dup", "target": "origin"}`, wantCode: http.StatusOK, wantMoved: true},
+		{name: "Given the merge is retried", body: `{"source": "merged", "target": "origin"}`, wantCode: http.StatusOK, wantMoved: true},
+		{name: "Given the source merged into another post", body: `{"source": "merged", "target": "dup"}`, wantCode: http.StatusConflict},
+		{name: "Given the same source and target", body: `{"source": "origin", "target": "origin"}`, wantCode: http.StatusBadRequest},
+		{name: "Given no target", body: `{"source": "dup"}`, wantCode: http.StatusBadRequest},
+		{name: "Given a post not found", body: `{"source": "not-found", "target": "origin"}`, wantCode: http.StatusNotFound},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockPostMergeStorage{
+				posts:  map[string]bool{"dup": true, "origin": true, "merged": true},
+				merged: map[string]string{"merged": "origin"},
+				moved:  map[string]string{},
+			}
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/posts/merge", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			pmc := NewPostMergeController(s, s)
+			pmc.Cache = s
+			code, body, _ := pmc.MergePosts(c)
+
+			if code != tc.wantCode {
+				t.Errorf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if moved := len(s.moved) > 0; moved != tc.wantMoved {
+				t.Errorf("expect bookmarks moved %v, but got %v", tc.wantMoved, moved)
+			}
+			if s.invalidated != tc.wantMoved {
+				t.Errorf("expect the cached posts invalidated %v, but got %v", tc.wantMoved, s.invalidated)
+			}
+			if code == http.StatusOK {
+				result := body["data"].(models.PostMergeResult)
+				if result.Slug != "origin" || result.MovedBookmarks != 2 {
+					t.Errorf("expect merged into origin with 2 bookmarks moved, but got %+v", result)
+				}
+			}
+		})
+	}
+}
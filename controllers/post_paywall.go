@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type paywallLevelGetter interface {
+	GetPaywallLevelOfPost(string) (int, error)
+}
+
+type privilegeGetter interface {
+	GetUserByID(string) (models.User, error)
+}
+
+type subscriptionChecker interface {
+	IsUserSubscribed(string) (bool, error)
+}
+
+// NewPostPaywallController returns a PostPaywallController with the storage of posts, users and subscriptions
+func NewPostPaywallController(ns paywallLevelGetter, ms privilegeGetter, ss subscriptionChecker) *PostPaywallController {
+	return &PostPaywallController{NewsStorage: ns, MembershipStorage: ms, SubscriptionStorage: ss}
+}
+
+// PostPaywallController checks whether the readers have access to the posts behind the paywall
+type PostPaywallController struct {
+	NewsStorage         paywallLevelGetter
+	MembershipStorage   privilegeGetter
+	SubscriptionStorage subscriptionChecker
+}
+
+// GetPaywallStatusOfAPost responds the paywall level of the post, and whether the authenticated user has access to it.
+// The anonymous requests only have access to the free posts.
+// The subscribers are the members, or the users with an active subscription.
+// It is called before rendering the content, so the subscription is only checked if the privilege is not enough.
+func (ppc *PostPaywallController) GetPaywallStatusOfAPost(c *gin.Context) (int, gin.H, error) {
+	slug := c.Param("slug")
+
+	level, err := ppc.NewsStorage.GetPaywallLevelOfPost(slug)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{
+				"slug": fmt.Sprintf("cannot find the post(slug: %s)", slug),
+			}}, nil
+		}
+		return toResponse(err)
+	}
+
+	hasAccess := level == models.PaywallFree
+	if authUserID := c.Request.Context().Value(globals.AuthUserIDProperty); !hasAccess && authUserID != nil {
+		user, err := ppc.MembershipStorage.GetUserByID(fmt.Sprint(authUserID))
+		if err != nil && !storage.IsNotFound(err) {
+			return toResponse(err)
+		}
+		hasAccess = err == nil && hasPaywallAccess(user, level)
+
+		if err == nil && !hasAccess && level == models.PaywallSubscriber {
+			if hasAccess, err = ppc.SubscriptionStorage.IsUserSubscribed(fmt.Sprint(user.ID)); err != nil {
+				return toResponse(err)
+			}
+		}
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"level":     level,
+		"hasAccess": hasAccess,
+	}}, nil
+}
+
+// hasPaywallAccess reports whether the privilege of the user reaches the paywall level
+func hasPaywallAccess(user models.User, level int) bool {
+	switch {
+	case level <= models.PaywallFree:
+		return true
+	case level == models.PaywallRegistered:
+		return user.Privilege >= constants.PrivilegeRegistered
+	default:
+		return user.Privilege >= constants.PrivilegeMember
+	}
+}
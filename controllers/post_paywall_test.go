@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockPaywallLevelGetter map[string]int
+
+func (m mockPaywallLevelGetter) GetPaywallLevelOfPost(slug string) (int, error) {
+	if level, ok := m[slug]; ok {
+		return level, nil
+	}
+	return 0, storage.ErrMgoNotFound
+}
+
+type mockPrivilegeGetter map[string]models.User
+
+func (m mockPrivilegeGetter) GetUserByID(userID string) (models.User, error) {
+	if user, ok := m[userID]; ok {
+		return user, nil
+	}
+	return models.User{}, storage.ErrRecordNotFound
+}
+
+type mockSubscriptionChecker map[string]bool
+
+func (m mockSubscriptionChecker) IsUserSubscribed(userID string) (bool, error) {
+	if userID == "500" {
+		return false, errors.New("connection refused")
+	}
+	return m[userID], nil
+}
+
+func TestGetPaywallStatusOfAPost(t *testing.T) {
+	posts := mockPaywallLevelGetter{
+		"free-post":       models.PaywallFree,
+		"registered-post": models.PaywallRegistered,
+		"subscriber-post": models.PaywallSubscriber,
+	}
+	users := mockPrivilegeGetter{
+		"1":   {ID: 1, Privilege: constants.PrivilegeRegistered},
+		"2":   {ID: 2, Privilege: constants.PrivilegeMember},
+		"4":   {ID: 4, Privilege: constants.PrivilegeRegistered},
+		"500": {ID: 500, Privilege: constants.PrivilegeRegistered},
+	}
+	subscriptions := mockSubscriptionChecker{"4": true}
+
+	cases := []struct {
+		name          string
+		slug          string
+		userID        interface{}
+		wantCode      int
+		wantLevel     int
+		wantHasAccess bool
+	}{
+		{name: "Given an anonymous request of a free post", slug: "free-post", wantCode: http.StatusOK, wantLevel: 0, wantHasAccess: true},
+		{name: "Given an anonymous request of a registered post", slug: "registered-post", wantCode: http.StatusOK, wantLevel: 1, wantHasAccess: false},
+		{name: "Given a registered user of a registered post", slug: "registered-post", userID: float64(1), wantCode: http.StatusOK, wantLevel: 1, wantHasAccess: true},
+		{name: "Given a registered user of a subscriber post", slug: "subscriber-post", userID: float64(1), wantCode: http.StatusOK, wantLevel: 2, wantHasAccess: false},
+		{name: "Given a subscriber of a subscriber post", slug: "subscriber-post", userID: float64(2), wantCode: http.StatusOK, wantLevel: 2, wantHasAccess: true},
+		{name: "Given a user subscribed of a subscriber post", slug: "subscriber-post", userID: float64(4), wantCode: http.StatusOK, wantLevel: 2, wantHasAccess: true},
+		{name: "Given a user subscribed of a registered post", slug: "registered-post", userID: float64(4), wantCode: http.StatusOK, wantLevel: 1, wantHasAccess: true},
+		{name: "Given subscription storage error", slug: "subscriber-post", userID: float64(500), wantCode: http.StatusInternalServerError},
+		{name: "Given a deleted user of a registered post", slug: "registered-post", userID: float64(3), wantCode: http.StatusOK, wantLevel: 1, wantHasAccess: false},
+		{name: "Given a post not found", slug: "not-found", wantCode: http.StatusNotFound},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts/"+tc.slug+"/paywall-status", nil)
+			c.Params = gin.Params{{Key: "slug", Value: tc.slug}}
+			if tc.userID != nil {
+				c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), globals.AuthUserIDProperty, tc.userID))
+			}
+
+			code, body, _ := NewPostPaywallController(posts, users, subscriptions).GetPaywallStatusOfAPost(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			data := body["data"].(gin.H)
+			if data["level"] != tc.wantLevel || data["hasAccess"] != tc.wantHasAccess {
+				t.Errorf("expect level %v and hasAccess %v, but got %v", tc.wantLevel, tc.wantHasAccess, data)
+			}
+		})
+	}
+}
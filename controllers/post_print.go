@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+const (
+	printBlockList  = "list"
+	printBlockImage = "image"
+)
+
+// printTextBlocks are the types of text blocks kept in the printed post,
+// the others(videos, audios, embedded codes, infoboxes...) are stripped.
+var printTextBlocks = map[string]bool{
+	"unstyled":   true,
+	"header-one": true,
+	"header-two": true,
+	"blockquote": true,
+}
+
+// printListBlocks maps the types of list blocks
+var printListBlocks = map[string]bool{
+	"ordered-list-item":   true,
+	"unordered-list-item": true,
+}
+
+// printImageBlocks are the types of image blocks kept in the printed post
+var printImageBlocks = map[string]bool{
+	"image":        true,
+	"slideshow":    true,
+	"image-link":   true,
+	"small-image":  true,
+	"image-diffs":  true,
+	"image-double": true,
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// printImage is the image rendered in the printed post
+type printImage struct {
+	URL         string
+	Description string
+}
+
+// printBlock is the block of the content rendered in the printed post
+type printBlock struct {
+	Type   string
+	Text   string
+	Items  []string
+	Images []printImage
+}
+
+// printPost is the post rendered in the print template
+type printPost struct {
+	Title         string
+	Subtitle      string
+	Writers       string
+	Photographers string
+	ExtendByline  string
+	PublishedDate time.Time
+	HeroImage     *printImage
+	Blocks        []printBlock
+}
+
+type fullPostGetter interface {
+	GetFullPosts(context.Context, models.MongoQuery, int, int, string, []string) ([]models.Post, int, error)
+}
+
+// NewPostPrintController ...
+func NewPostPrintController(s fullPostGetter, t *template.Template) *PostPrintController {
+	return &PostPrintController{Storage: s, Template: t}
+}
+
+// PostPrintController renders the posts to printer-friendly HTML pages
+type PostPrintController struct {
+	Storage  fullPostGetter
+	Template *template.Template
+}
+
+// GetAPrintedPost renders the text and images of the post of `:slug` to a clean HTML page
+func (ppc *PostPrintController) GetAPrintedPost(c *gin.Context) {
+	posts, _, err := ppc.Storage.GetFullPosts(c.Request.Context(), models.MongoQuery{Slug: c.Param("slug")}, 1, 0, "-publishedDate", nil)
+	if err != nil {
+		code, body, _ := toPostResponse(err)
+		c.JSON(code, body)
+		return
+	}
+
+	if len(posts) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"status": "Record Not Found", "error": "Record Not Found"})
+		return
+	}
+
+	if failBody, available := checkPostCountry(c, posts[0].Slug, posts[0].RestrictedCountries); !available {
+		c.JSON(http.StatusUnavailableForLegalReasons, failBody)
+		return
+	}
+
+	var out bytes.Buffer
+	if err = ppc.Template.Execute(&out, toPrintPost(posts[0])); err != nil {
+		logError(errors.Wrap(err, "can not render printed post"))
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "can not render printed post"})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", out.Bytes())
+}
+
+func toPrintPost(post models.Post) printPost {
+	p := printPost{
+		Title:         post.Title,
+		Subtitle:      post.Subtitle,
+		Writers:       joinAuthorNames(post.Writters),
+		Photographers: joinAuthorNames(post.Photographers),
+		ExtendByline:  post.ExtendByline,
+		PublishedDate: post.PublishedDate,
+	}
+
+	if post.HeroImage != nil {
+		url := post.HeroImage.ResizedTargets.Desktop.URL
+		if url == "" {
+			url = post.HeroImage.URL
+		}
+		p.HeroImage = &printImage{URL: url, Description: post.HeroImage.Description}
+	}
+
+	if post.Content != nil {
+		p.Blocks = toPrintBlocks(post.Content.APIData)
+	}
+
+	return p
+}
+
+func joinAuthorNames(authors []models.Author) string {
+	var names []string
+	for _, author := range authors {
+		names = append(names, author.Name)
+	}
+	return strings.Join(names, "、")
+}
+
+// toPrintBlocks converts the api data of the content to the blocks rendered in the printed post.
+// The consecutive list items are grouped into a list, and the rich media are stripped.
+func toPrintBlocks(apiData []bson.M) []printBlock {
+	var blocks []printBlock
+
+	for _, data := range apiData {
+		blockType, _ := data["type"].(string)
+		content, _ := data["content"].([]interface{})
+
+		switch {
+		case printTextBlocks[blockType]:
+			if text := toPlainText(content); text != "" {
+				blocks = append(blocks, printBlock{Type: blockType, Text: text})
+			}
+		case printListBlocks[blockType]:
+			text := toPlainText(content)
+			if text == "" {
+				continue
+			}
+			if len(blocks) == 0 || blocks[len(blocks)-1].Type != printBlockList {
+				blocks = append(blocks, printBlock{Type: printBlockList})
+			}
+			blocks[len(blocks)-1].Items = append(blocks[len(blocks)-1].Items, text)
+		case printImageBlocks[blockType]:
+			if images := toPrintImages(content); len(images) > 0 {
+				blocks = append(blocks, printBlock{Type: printBlockImage, Images: images})
+			}
+		}
+	}
+
+	return blocks
+}
+
+// toPlainText joins the strings of the content with the inline html tags stripped
+func toPlainText(content []interface{}) string {
+	var texts []string
+	for _, c := range content {
+		if s, ok := c.(string); ok {
+			if text := strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(s, ""))); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+func toPrintImages(content []interface{}) []printImage {
+	var images []printImage
+	for _, c := range content {
+		image, ok := c.(bson.M)
+		if !ok {
+			continue
+		}
+
+		url, _ := image["url"].(string)
+		if desktop, ok := image["desktop"].(bson.M); ok {
+			if desktopURL, _ := desktop["url"].(string); desktopURL != "" {
+				url = desktopURL
+			}
+		}
+		if url == "" {
+			continue
+		}
+
+		description, _ := image["description"].(string)
+		images = append(images, printImage{URL: url, Description: description})
+	}
+	return images
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/utils"
+)
+
+type mockFullPostGetter struct {
+	posts []models.Post
+}
+
+func (m *mockFullPostGetter) GetFullPosts(ctx context.Context, mq models.MongoQuery, limit, offset int, sort string, embedded []string) ([]models.Post, int, error) {
+	var posts []models.Post
+	for _, post := range m.posts {
+		if post.Slug == mq.Slug {
+			posts = append(posts, post)
+		}
+	}
+	return posts, len(posts), nil
+}
+
+func TestGetAPrintedPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := &mockFullPostGetter{posts: []models.Post{{
+		Slug:     "mock-post",
+		Title:    "<script>alert('title')</script>",
+		Writters: []models.Author{{Name: "writer 1"}, {Name: "writer 2"}},
+		Content: &models.ContentBody{APIData: []bson.M{
+			{"type": "header-one", "content": []interface{}{"the header"}},
+			{"type": "unstyled", "content": []interface{}{"the <strong>bold</strong> &amp; <a href=\"https://example.com\">link</a>"}},
+			{"type": "youtube", "content": []interface{}{bson.M{"youtubeId": "mock-youtube-id"}}},
+			{"type": "embeddedcode", "content": []interface{}{bson.M{"embeddedCode": "<iframe src=\"https://example.com/embedded\"></iframe>"}}},
+			{"type": "unordered-list-item", "content": []interface{}{"item 1"}},
+			{"type": "unordered-list-item", "content": []interface{}{"item 2"}},
+			{"type": "image", "content": []interface{}{bson.M{
+				"description": "the image",
+				"url":         "https://example.com/original.jpg",
+				"desktop":     bson.M{"url": "https://example.com/desktop.jpg"},
+			}}},
+		}},
+	}}}
+
+	engine := gin.New()
+	engine.GET("/v1/posts/:slug/print", NewPostPrintController(s, template.Must(template.ParseFiles(utils.GetProjectRoot()+"/template/post-print.tmpl"))).GetAPrintedPost)
+
+	t.Run("Given an existing post", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/v1/posts/mock-post/print", nil))
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expect status %d, but got %d", http.StatusOK, resp.Code)
+		}
+		if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("expect html content type, but got %s", ct)
+		}
+
+		body := resp.Body.String()
+		for _, want := range []string{
+			"<h2>the header</h2>",
+			"<p>the bold &amp; link</p>",
+			"<li>item 1</li><li>item 2</li>",
+			`<img src="https://example.com/desktop.jpg" alt="the image"/>`,
+			"writer 1、writer 2",
+			"&lt;script&gt;",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expect printed post to contain %s, but got %s", want, body)
+			}
+		}
+		for _, unwanted := range []string{"mock-youtube-id", "<iframe", "<script>", "<strong>"} {
+			if strings.Contains(body, unwanted) {
+				t.Errorf("expect printed post not to contain %s", unwanted)
+			}
+		}
+	})
+
+	t.Run("Given a nonexistent post", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/v1/posts/nonexistent/print", nil))
+
+		if resp.Code != http.StatusNotFound {
+			t.Errorf("expect status %d, but got %d", http.StatusNotFound, resp.Code)
+		}
+	})
+
+	t.Run("Given a post restricted in the client country", func(t *testing.T) {
+		s := &mockFullPostGetter{posts: []models.Post{{Slug: "restricted-post", Title: "restricted", RestrictedCountries: []string{"CN"}}}}
+		engine := gin.New()
+		engine.GET("/v1/posts/:slug/print", func(c *gin.Context) {
+			c.Header("Cache-Control", "public,max-age=3600")
+			c.Set(globals.ClientCountryProperty, c.Query("country"))
+		}, NewPostPrintController(s, template.Must(template.ParseFiles(utils.GetProjectRoot()+"/template/post-print.tmpl"))).GetAPrintedPost)
+
+		for country, wantCode := range map[string]int{"CN": http.StatusUnavailableForLegalReasons, "TW": http.StatusOK} {
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/v1/posts/restricted-post/print?country="+country, nil))
+
+			if resp.Code != wantCode {
+				t.Errorf("expect status %d in %s, but got %d", wantCode, country, resp.Code)
+			}
+			if got := resp.Header().Get("Cache-Control"); got != "private,max-age=3600" {
+				t.Errorf("expect Cache-Control private,max-age=3600 in %s, but got %q", country, got)
+			}
+		}
+	})
+}
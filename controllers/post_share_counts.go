@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type shareCountsStorage interface {
+	GetShareCountsOfPost(string) (models.Post, error)
+	RequestShareCountsRefresh(string, time.Time) error
+}
+
+// NewPostShareCountsController returns a PostShareCountsController with the storage of the share counts
+func NewPostShareCountsController(s shareCountsStorage) *PostShareCountsController {
+	return &PostShareCountsController{Storage: s}
+}
+
+// PostShareCountsController serves the counts of the posts shared on the social networks
+type PostShareCountsController struct {
+	Storage shareCountsStorage
+}
+
+func postNotFoundResponse(slug string) (int, gin.H, error) {
+	return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{
+		"slug": fmt.Sprintf("cannot find the post(slug: %s)", slug),
+	}}, nil
+}
+
+// GetShareCountsOfAPost responds the share counts of the published post on facebook, twitter and line, along with the total
+func (pscc *PostShareCountsController) GetShareCountsOfAPost(c *gin.Context) (int, gin.H, error) {
+	slug := c.Param("slug")
+
+	post, err := pscc.Storage.GetShareCountsOfPost(slug)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return postNotFoundResponse(slug)
+		}
+		return toResponse(err)
+	}
+
+	if failBody, available := checkPostCountry(c, slug, post.RestrictedCountries); !available {
+		return restrictedPostResponse(failBody)
+	}
+
+	var counts models.ShareCounts
+	if post.ShareCounts != nil {
+		counts = *post.ShareCounts
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"facebook":   counts.Facebook,
+		"twitter":    counts.Twitter,
+		"line":       counts.Line,
+		"total":      counts.Total(),
+		"updated_at": counts.UpdatedAt,
+	}}, nil
+}
+
+// RefreshShareCountsOfAPost requests the job to poll the share counts of the post,
+// 202 is responded since the counts are refreshed asynchronously
+func (pscc *PostShareCountsController) RefreshShareCountsOfAPost(c *gin.Context) (int, gin.H, error) {
+	slug := c.Param("slug")
+	requestedAt := time.Now()
+
+	if err := pscc.Storage.RequestShareCountsRefresh(slug, requestedAt); err != nil {
+		if storage.IsNotFound(err) {
+			return postNotFoundResponse(slug)
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusAccepted, gin.H{"status": "success", "data": gin.H{
+		"slug":                 slug,
+		"refresh_requested_at": requestedAt,
+	}}, nil
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockShareCountsStorage struct {
+	counts     map[string]models.ShareCounts
+	requested  map[string]time.Time
+	restricted map[string][]string
+}
+
+func (m *mockShareCountsStorage) GetShareCountsOfPost(slug string) (models.Post, error) {
+	if counts, ok := m.counts[slug]; ok {
+		post := models.Post{Slug: slug, RestrictedCountries: m.restricted[slug]}
+		if counts != (models.ShareCounts{}) {
+			post.ShareCounts = &counts
+		}
+		return post, nil
+	}
+	return models.Post{}, storage.ErrMgoNotFound
+}
+
+func (m *mockShareCountsStorage) RequestShareCountsRefresh(slug string, requestedAt time.Time) error {
+	if _, ok := m.counts[slug]; !ok {
+		return storage.ErrMgoNotFound
+	}
+	m.requested[slug] = requestedAt
+	return nil
+}
+
+func TestGetShareCountsOfAPost(t *testing.T) {
+	updatedAt := time.Date(2020, 6, 8, 16, 0, 0, 0, time.UTC)
+	s := &mockShareCountsStorage{counts: map[string]models.ShareCounts{
+		"shared-post":     {Facebook: 10, Twitter: 2, Line: 5, UpdatedAt: &updatedAt},
+		"not-polled-post": {},
+		"restricted-post": {Facebook: 1},
+	}, restricted: map[string][]string{"restricted-post": {"CN"}}}
+
+	cases := []struct {
+		name     string
+		slug     string
+		country  string
+		wantCode int
+		wantData gin.H
+	}{
+		{
+			name:     "Given a shared post",
+			slug:     "shared-post",
+			wantCode: http.StatusOK,
+			wantData: gin.H{"facebook": 10, "twitter": 2, "line": 5, "total": 17, "updated_at": &updatedAt},
+		},
+		{
+			name:     "Given a post never polled",
+			slug:     "not-polled-post",
+			wantCode: http.StatusOK,
+			wantData: gin.H{"facebook": 0, "twitter": 0, "line": 0, "total": 0, "updated_at": (*time.Time)(nil)},
+		},
+		{name: "Given a post not found", slug: "not-found", wantCode: http.StatusNotFound},
+		{name: "Given a post restricted in the client country", slug: "restricted-post", country: "CN", wantCode: http.StatusUnavailableForLegalReasons},
+		{name: "Given a post restricted in the other countries", slug: "restricted-post", country: "TW", wantCode: http.StatusOK, wantData: gin.H{"facebook": 1}},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts/"+tc.slug+"/share-counts", nil)
+			c.Params = gin.Params{{Key: "slug", Value: tc.slug}}
+			c.Set(globals.ClientCountryProperty, tc.country)
+
+			code, body, _ := NewPostShareCountsController(s).GetShareCountsOfAPost(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			data := body["data"].(gin.H)
+			for key, want := range tc.wantData {
+				if data[key] != want {
+					t.Errorf("expect %s to be %v, but got %v", key, want, data[key])
+				}
+			}
+		})
+	}
+}
+
+func TestRefreshShareCountsOfAPost(t *testing.T) {
+	s := &mockShareCountsStorage{
+		counts:    map[string]models.ShareCounts{"shared-post": {Facebook: 10}},
+		requested: make(map[string]time.Time),
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range []struct {
+		name     string
+		slug     string
+		wantCode int
+	}{
+		{name: "Given a post", slug: "shared-post", wantCode: http.StatusAccepted},
+		{name: "Given a post not found", slug: "not-found", wantCode: http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/posts/"+tc.slug+"/share-counts/refresh", nil)
+			c.Params = gin.Params{{Key: "slug", Value: tc.slug}}
+
+			code, _, _ := NewPostShareCountsController(s).RefreshShareCountsOfAPost(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if _, ok := s.requested[tc.slug]; ok != (tc.wantCode == http.StatusAccepted) {
+				t.Errorf("expect the refresh requested %v, but got %v", tc.wantCode == http.StatusAccepted, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	f "github.com/twreporter/logformatter"
+	"gopkg.in/guregu/null.v3"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/services"
+	"twreporter.org/go-api/storage"
+)
+
+const (
+	postStateDraft     = "draft"
+	postStateReview    = "review"
+	postStateScheduled = "scheduled"
+	postStatePublished = "published"
+	postStateArchived  = "archived"
+)
+
+// postStateTransitions is the editorial workflow of posts,
+// which maps the current state to the states it can move to.
+var postStateTransitions = map[string][]string{
+	postStateDraft:     {postStateReview},
+	postStateReview:    {postStateDraft, postStateScheduled, postStatePublished},
+	postStateScheduled: {postStateReview, postStatePublished},
+	postStatePublished: {postStateArchived},
+	postStateArchived:  {postStateReview},
+}
+
+func isValidPostState(state string) bool {
+	_, ok := postStateTransitions[state]
+	return ok
+}
+
+func isValidPostStateTransition(from, to string) bool {
+	for _, state := range postStateTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+type postStateReqBody struct {
+	State string `json:"state" binding:"required"`
+	Note  string `json:"note"`
+}
+
+// NewPostStateController ...
+func NewPostStateController(ns storage.NewsStorage, ms storage.MembershipStorage) *PostStateController {
+	return &PostStateController{
+		NewsStorage:       ns,
+		MembershipStorage: ms,
+	}
+}
+
+// PostStateController moves the posts through the editorial workflow
+type PostStateController struct {
+	NewsStorage       storage.NewsStorage
+	MembershipStorage storage.MembershipStorage
+	// PushService notifies the mobile apps of the published posts, nil if it is disabled
+	PushService services.PushService
+	PushStorage storage.PushStorage
+}
+
+// UpdatePostState validates the transition of the post state, updates the state,
+// records the change in the audit log and notifies the admins by email.
+// The subscribers of the categories of the post are notified by push notifications once it is published.
+func (psc *PostStateController) UpdatePostState(c *gin.Context) (int, gin.H, error) {
+	var err error
+	var post models.Post
+	var reqBody postStateReqBody
+
+	if failData, valid := bindRequestJSONBody(c, &reqBody); !valid {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	if !isValidPostState(reqBody.State) {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"state": fmt.Sprintf("%s is not a valid state", reqBody.State),
+		}}, nil
+	}
+
+	slug := c.Param("slug")
+	if post, err = psc.NewsStorage.GetPostBySlug(slug); err != nil {
+		return toResponse(err)
+	}
+
+	if !isValidPostStateTransition(post.State, reqBody.State) {
+		return http.StatusConflict, gin.H{"status": "fail", "data": gin.H{
+			"state": fmt.Sprintf("cannot move post from %s to %s", post.State, reqBody.State),
+		}}, nil
+	}
+
+	if err = psc.NewsStorage.UpdatePost(slug, bson.M{"state": reqBody.State, "updatedAt": time.Now()}); err != nil {
+		return toResponse(err)
+	}
+
+	authUserID := fmt.Sprint(c.Request.Context().Value(globals.AuthUserIDProperty))
+	editor, _ := psc.MembershipStorage.GetUserByID(authUserID)
+
+	stateLog := models.PostStateLog{
+		PostSlug:  slug,
+		FromState: post.State,
+		ToState:   reqBody.State,
+		Note:      null.NewString(reqBody.Note, reqBody.Note != ""),
+		UserID:    editor.ID,
+	}
+
+	if err = psc.MembershipStorage.Create(&stateLog); err != nil {
+		// the state is changed already, only log the failure of audit log
+		logError(errors.WithMessage(err, fmt.Sprintf("fail to record post(slug: %s) state change", slug)))
+	}
+
+	go psc.sendPostStateChangeMail(post, stateLog, editor)
+
+	if reqBody.State == postStatePublished && psc.PushService != nil {
+		go psc.sendPostPublishedPush(post)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"slug":           slug,
+		"state":          reqBody.State,
+		"previous_state": post.State,
+		"note":           stateLog.Note,
+	}}, nil
+}
+
+// sendPostStateChangeMail notifies the admins except the editor of the state change
+func (psc *PostStateController) sendPostStateChangeMail(post models.Post, stateLog models.PostStateLog, editor models.User) {
+	var admins []models.User
+
+	if err := psc.MembershipStorage.GetByConditions(map[string]interface{}{"privilege": constants.PrivilegeAdmin}, &admins); err != nil {
+		logError(errors.WithMessage(err, fmt.Sprintf("fail to get admins to notify post(slug: %s) state change", post.Slug)))
+		return
+	}
+
+	for _, admin := range admins {
+		if admin.ID == editor.ID || !admin.Email.Valid {
+			continue
+		}
+
+		reqBody := postStateChangeReqBody{
+			Email:     admin.Email.String,
+			Slug:      post.Slug,
+			Title:     post.Title,
+			FromState: stateLog.FromState,
+			ToState:   stateLog.ToState,
+			Note:      stateLog.Note.ValueOrZero(),
+			Editor:    editor.Email.ValueOrZero(),
+		}
+
+		if err := postMailServiceEndpoint(reqBody, fmt.Sprintf("http://localhost:%s/v1/%s", globals.LocalhostPort, globals.SendPostStateChangeRoutePath)); err != nil {
+			logError(errors.Wrap(err, fmt.Sprintf("fail to send post(slug: %s) state change mail to %s", post.Slug, admin.Email.String)))
+		}
+	}
+}
+
+// postCategoryIDs returns the ids of the categories of the post without duplicates
+func postCategoryIDs(post models.Post) []string {
+	var ids []string
+	var seen = make(map[bson.ObjectId]bool)
+
+	categoryIDs := append([]bson.ObjectId{}, post.CategoriesOrigin...)
+	for _, category := range post.Categories {
+		categoryIDs = append(categoryIDs, category.ID)
+	}
+
+	for _, id := range categoryIDs {
+		if !id.Valid() || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id.Hex())
+	}
+	return ids
+}
+
+// sendPostPublishedPush notifies the devices of the users subscribing the categories of the post,
+// and deletes the device tokens which are no longer valid
+func (psc *PostStateController) sendPostPublishedPush(post models.Post) {
+	categoryIDs := postCategoryIDs(post)
+	if len(categoryIDs) == 0 {
+		return
+	}
+
+	deviceTokens, err := psc.PushStorage.GetDeviceTokensOfCategories(categoryIDs)
+	if err != nil {
+		logError(errors.WithMessage(err, fmt.Sprintf("fail to get device tokens to notify post(slug: %s) published", post.Slug)))
+		return
+	}
+	if len(deviceTokens) == 0 {
+		return
+	}
+
+	tokens := make([]string, 0, len(deviceTokens))
+	for _, deviceToken := range deviceTokens {
+		tokens = append(tokens, deviceToken.Token)
+	}
+
+	invalidTokens, err := psc.PushService.Send(tokens, services.PushNotification{
+		Title: post.Title,
+		Body:  post.OgDescription,
+		Data:  map[string]string{"slug": post.Slug},
+	})
+	if err != nil {
+		logError(errors.WithMessage(err, fmt.Sprintf("fail to notify post(slug: %s) published", post.Slug)))
+	}
+
+	if err = psc.PushStorage.DeleteDeviceTokens(invalidTokens); err != nil {
+		logError(errors.WithMessage(err, "fail to delete invalid device tokens"))
+	}
+}
+
+func logError(err error) {
+	if globals.Conf.Environment == "development" {
+		log.Errorf("%+v", err)
+	} else {
+		log.WithField("detail", err).Errorf("%s", f.FormatStack(err))
+	}
+}
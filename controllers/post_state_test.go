@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/services"
+	"twreporter.org/go-api/storage"
+)
+
+func TestIsValidPostStateTransition(t *testing.T) {
+	cases := []struct {
+		name string
+		from string
+		to   string
+		want bool
+	}{
+		{name: "Given draft to review", from: postStateDraft, to: postStateReview, want: true},
+		{name: "Given review to scheduled", from: postStateReview, to: postStateScheduled, want: true},
+		{name: "Given scheduled to published", from: postStateScheduled, to: postStatePublished, want: true},
+		{name: "Given published to archived", from: postStatePublished, to: postStateArchived, want: true},
+		{name: "Given published to draft", from: postStatePublished, to: postStateDraft, want: false},
+		{name: "Given draft to published", from: postStateDraft, to: postStatePublished, want: false},
+		{name: "Given the same state", from: postStateReview, to: postStateReview, want: false},
+		{name: "Given unknown state", from: "invisible", to: postStateReview, want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isValidPostStateTransition(tc.from, tc.to); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+type mockPushService struct {
+	tokens       []string
+	notification services.PushNotification
+	invalid      []string
+}
+
+func (m *mockPushService) Send(tokens []string, notification services.PushNotification) ([]string, error) {
+	m.tokens, m.notification = tokens, notification
+	return m.invalid, nil
+}
+
+type mockPushStorage struct {
+	storage.PushStorage
+	tokensOfCategories map[string][]models.DeviceToken
+	deleted            []string
+}
+
+func (m *mockPushStorage) GetDeviceTokensOfCategories(categoryIDs []string) ([]models.DeviceToken, error) {
+	var tokens []models.DeviceToken
+	for _, id := range categoryIDs {
+		tokens = append(tokens, m.tokensOfCategories[id]...)
+	}
+	return tokens, nil
+}
+
+func (m *mockPushStorage) DeleteDeviceTokens(tokens []string) error {
+	m.deleted = append(m.deleted, tokens...)
+	return nil
+}
+
+func TestSendPostPublishedPush(t *testing.T) {
+	category := bson.NewObjectId()
+	other := bson.NewObjectId()
+
+	ps := &mockPushService{invalid: []string{"unregistered-token"}}
+	pst := &mockPushStorage{tokensOfCategories: map[string][]models.DeviceToken{
+		category.Hex(): {{Token: "valid-token"}, {Token: "unregistered-token"}},
+	}}
+	psc := &PostStateController{PushService: ps, PushStorage: pst}
+
+	psc.sendPostPublishedPush(models.Post{
+		Slug:             "mock-slug",
+		Title:            "mock title",
+		OgDescription:    "mock description",
+		CategoriesOrigin: []bson.ObjectId{category, other},
+		Categories:       []models.Category{{ID: category}},
+	})
+
+	if want := []string{"valid-token", "unregistered-token"}; fmt.Sprint(ps.tokens) != fmt.Sprint(want) {
+		t.Errorf("expected tokens %v, got %v", want, ps.tokens)
+	}
+	if ps.notification.Title != "mock title" || ps.notification.Body != "mock description" || ps.notification.Data["slug"] != "mock-slug" {
+		t.Errorf("unexpected notification %+v", ps.notification)
+	}
+	if want := []string{"unregistered-token"}; fmt.Sprint(pst.deleted) != fmt.Sprint(want) {
+		t.Errorf("expected invalid tokens %v deleted, got %v", want, pst.deleted)
+	}
+
+	// the post without any category is not notified
+	ps.tokens = nil
+	psc.sendPostPublishedPush(models.Post{Slug: "mock-slug"})
+	if ps.tokens != nil {
+		t.Errorf("expected no notification, got tokens %v", ps.tokens)
+	}
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockRestrictedPostStorage struct {
+	storage.NewsStorage
+	post models.Post
+}
+
+func (m *mockRestrictedPostStorage) GetMetaOfPosts(ctx context.Context, mq models.MongoQuery, limit, offset int, sort string, embedded []string) ([]models.Post, int, error) {
+	return []models.Post{m.post}, 1, nil
+}
+
+func (m *mockRestrictedPostStorage) GetFullPosts(ctx context.Context, mq models.MongoQuery, limit, offset int, sort string, embedded []string) ([]models.Post, int, error) {
+	post := m.post
+	post.Full = true
+	return []models.Post{post}, 1, nil
+}
+
+type mockMergedPostStorage struct {
+	storage.NewsStorage
+	posts map[string]models.Post
+}
+
+func (m *mockMergedPostStorage) GetMetaOfPosts(ctx context.Context, mq models.MongoQuery, limit, offset int, sort string, embedded []string) ([]models.Post, int, error) {
+	if post, ok := m.posts[mq.Slug]; ok && post.State == postStatePublished {
+		return []models.Post{post}, 1, nil
+	}
+	return []models.Post{}, 0, nil
+}
+
+func (m *mockMergedPostStorage) GetPostBySlug(slug string) (models.Post, error) {
+	if post, ok := m.posts[slug]; ok {
+		return post, nil
+	}
+	return models.Post{}, storage.ErrMgoNotFound
+}
+
+func TestGetAPostMerged(t *testing.T) {
+	s := &mockMergedPostStorage{posts: map[string]models.Post{
+		"origin": {Slug: "origin", State: postStatePublished},
+		"dup":    {Slug: "dup", State: "deleted", RedirectTo: "origin"},
+		"draft":  {Slug: "draft", State: "draft"},
+	}}
+
+	cases := []struct {
+		name         string
+		slug         string
+		wantCode     int
+		wantLocation string
+	}{
+		{name: "Given a published post", slug: "origin", wantCode: http.StatusOK},
+		{name: "Given a merged post", slug: "dup", wantCode: http.StatusMovedPermanently, wantLocation: "/v1/posts/origin?utm_source=newsletter"},
+		{name: "Given an unpublished post", slug: "draft", wantCode: http.StatusNotFound},
+		{name: "Given a post not found", slug: "not-found", wantCode: http.StatusNotFound},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts/"+tc.slug+"?utm_source=newsletter", nil)
+			c.Params = gin.Params{{Key: "slug", Value: tc.slug}}
+
+			code, _, _ := NewNewsController(s).GetAPost(c)
+
+			if code != tc.wantCode {
+				t.Errorf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if got := resp.Header().Get("Location"); got != tc.wantLocation {
+				t.Errorf("expect Location %q, but got %q", tc.wantLocation, got)
+			}
+		})
+	}
+}
+
+func TestGetAPostRestrictedCountries(t *testing.T) {
+	cases := []struct {
+		name         string
+		restricted   []string
+		country      string
+		wantCode     int
+		wantCacheCtl string
+	}{
+		{name: "Given the post is not restricted", country: "TW", wantCode: http.StatusOK},
+		{name: "Given the client country is restricted", restricted: []string{"cn", "HK"}, country: "CN", wantCode: http.StatusUnavailableForLegalReasons, wantCacheCtl: "private,max-age=900"},
+		{name: "Given the client country is not restricted", restricted: []string{"CN"}, country: "TW", wantCode: http.StatusOK, wantCacheCtl: "private,max-age=900"},
+		{name: "Given the client country is unresolved", restricted: []string{"CN"}, wantCode: http.StatusOK, wantCacheCtl: "private,max-age=900"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockRestrictedPostStorage{post: models.Post{Slug: "mock-post", RestrictedCountries: tc.restricted}}
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts/mock-post", nil)
+			c.Params = gin.Params{{Key: "slug", Value: "mock-post"}}
+			if tc.country != "" {
+				c.Set(globals.ClientCountryProperty, tc.country)
+			}
+
+			code, body, _ := NewNewsController(s).GetAPost(c)
+
+			if code != tc.wantCode {
+				t.Errorf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if code == http.StatusUnavailableForLegalReasons && body["status"] != "fail" {
+				t.Errorf("expect jsend fail response, but got %v", body)
+			}
+			if got := resp.Header().Get("Cache-Control"); got != tc.wantCacheCtl {
+				t.Errorf("expect Cache-Control %q, but got %q", tc.wantCacheCtl, got)
+			}
+		})
+	}
+}
+
+func TestGetPostsRestrictedCountries(t *testing.T) {
+	content := &models.ContentBody{APIData: []bson.M{{"type": "unstyled", "content": []interface{}{"restricted"}}}}
+
+	cases := []struct {
+		name        string
+		country     string
+		wantContent bool
+	}{
+		{name: "Given the client country is restricted", country: "CN"},
+		{name: "Given the client country is not restricted", country: "TW", wantContent: true},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockRestrictedPostStorage{post: models.Post{Slug: "mock-post", Content: content, RestrictedCountries: []string{"CN"}}}
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/posts?full=true", nil)
+			c.Set(globals.ClientCountryProperty, tc.country)
+
+			code, body, _ := NewNewsController(s).GetPosts(c)
+
+			if code != http.StatusOK {
+				t.Fatalf("expect status %d, but got %d", http.StatusOK, code)
+			}
+			posts := body["records"].([]models.Post)
+			if got := posts[0].Content != nil; got != tc.wantContent {
+				t.Errorf("expect content listed %v, but got %v", tc.wantContent, got)
+			}
+			if got := resp.Header().Get("Cache-Control"); !strings.HasPrefix(got, "private") {
+				t.Errorf("expect private Cache-Control, but got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+	"twreporter.org/go-api/utils"
+)
+
+type postVersionStorage interface {
+	GetPostVersions(context.Context, string) ([]models.PostVersion, error)
+	GetPostVersion(context.Context, string, string) (models.PostVersion, error)
+	GetCurrentPostVersion(context.Context, string) (models.PostVersion, error)
+}
+
+// NewPostVersionController ...
+func NewPostVersionController(s postVersionStorage) *PostVersionController {
+	return &PostVersionController{Storage: s}
+}
+
+// PostVersionController serves the version history of the posts
+type PostVersionController struct {
+	Storage postVersionStorage
+}
+
+// GetPostVersions returns the meta of the previous versions of the post, the latest one comes first
+func (pvc *PostVersionController) GetPostVersions(c *gin.Context) (int, gin.H, error) {
+	slug := c.Param("slug")
+	ctx := c.Request.Context()
+
+	// respond 404 rather than an empty list if the post does not exist
+	if _, err := pvc.Storage.GetCurrentPostVersion(ctx, slug); err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{"slug": "Cannot find the post from the slug"}}, nil
+		}
+		return toResponse(err)
+	}
+
+	versions, err := pvc.Storage.GetPostVersions(ctx, slug)
+	if err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": versions}}, nil
+}
+
+// GetPostVersionDiff returns the word-level diff of the title and the text of the content
+// from the version of `:versionID` to the current post
+func (pvc *PostVersionController) GetPostVersionDiff(c *gin.Context) (int, gin.H, error) {
+	slug := c.Param("slug")
+	ctx := c.Request.Context()
+
+	version, err := pvc.Storage.GetPostVersion(ctx, slug, c.Param("versionID"))
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{"versionID": "Cannot find the version of the post"}}, nil
+		}
+		return toResponse(err)
+	}
+
+	current, err := pvc.Storage.GetCurrentPostVersion(ctx, slug)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{"slug": "Cannot find the post from the slug"}}, nil
+		}
+		return toResponse(err)
+	}
+
+	titleDiff := utils.WordDiff(version.Title, current.Title)
+	contentDiff := utils.WordDiff(contentToText(version.Content), contentToText(current.Content))
+
+	// the content is diffed as text, exclude it from the meta of the version
+	version.Content = nil
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"version":    version,
+		"updated_at": current.UpdatedAt,
+		"title":      titleDiff,
+		"content":    contentDiff,
+	}}, nil
+}
+
+// contentToText joins the plain text of the content blocks by newlines
+func contentToText(content *models.ContentBody) string {
+	if content == nil {
+		return ""
+	}
+
+	var texts []string
+	for _, data := range content.APIData {
+		blockContent, _ := data["content"].([]interface{})
+		if text := toPlainText(blockContent); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
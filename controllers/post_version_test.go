@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+	"twreporter.org/go-api/utils"
+)
+
+type mockPostVersionStorage struct {
+	current  models.PostVersion
+	versions []models.PostVersion
+}
+
+func (m *mockPostVersionStorage) GetPostVersions(ctx context.Context, slug string) ([]models.PostVersion, error) {
+	var versions = make([]models.PostVersion, 0)
+	for _, version := range m.versions {
+		if version.PostSlug == slug {
+			version.Content = nil
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+func (m *mockPostVersionStorage) GetPostVersion(ctx context.Context, slug, id string) (models.PostVersion, error) {
+	for _, version := range m.versions {
+		if version.PostSlug == slug && version.ID.Hex() == id {
+			return version, nil
+		}
+	}
+	return models.PostVersion{}, errors.WithStack(storage.ErrMgoNotFound)
+}
+
+func (m *mockPostVersionStorage) GetCurrentPostVersion(ctx context.Context, slug string) (models.PostVersion, error) {
+	if slug != m.current.PostSlug {
+		return models.PostVersion{}, errors.WithStack(storage.ErrMgoNotFound)
+	}
+	return m.current, nil
+}
+
+func newContent(texts ...string) *models.ContentBody {
+	var content = &models.ContentBody{}
+	for _, text := range texts {
+		content.APIData = append(content.APIData, bson.M{"type": "unstyled", "content": []interface{}{text}})
+	}
+	return content
+}
+
+func TestPostVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	versionID := bson.NewObjectId()
+	s := &mockPostVersionStorage{
+		current: models.PostVersion{PostSlug: "mock-post", Title: "the new title", Content: newContent("the <strong>first</strong> paragraph", "the added paragraph"), UpdatedAt: time.Now()},
+		versions: []models.PostVersion{
+			{ID: versionID, PostSlug: "mock-post", Title: "the old title", Content: newContent("the first paragraph"), EditorID: 1},
+		},
+	}
+
+	pvc := NewPostVersionController(s)
+	engine := gin.New()
+	engine.GET("/v1/admin/posts/:slug/versions", func(c *gin.Context) {
+		code, body, _ := pvc.GetPostVersions(c)
+		c.JSON(code, body)
+	})
+	engine.GET("/v1/admin/posts/:slug/versions/:versionID/diff", func(c *gin.Context) {
+		code, body, _ := pvc.GetPostVersionDiff(c)
+		c.JSON(code, body)
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		return resp
+	}
+
+	t.Run("Given the versions of a post", func(t *testing.T) {
+		var res struct {
+			Data struct {
+				Records []models.PostVersion `json:"records"`
+			} `json:"data"`
+		}
+
+		resp := serve("/v1/admin/posts/mock-post/versions")
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expect status %d, but got %d", http.StatusOK, resp.Code)
+		}
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		if len(res.Data.Records) != 1 || res.Data.Records[0].ID != versionID || res.Data.Records[0].EditorID != 1 {
+			t.Errorf("expect the version %s, but got %v", versionID.Hex(), res.Data.Records)
+		}
+	})
+
+	t.Run("Given the diff of a version", func(t *testing.T) {
+		var res struct {
+			Data struct {
+				Version models.PostVersion  `json:"version"`
+				Title   []utils.DiffSegment `json:"title"`
+				Content []utils.DiffSegment `json:"content"`
+			} `json:"data"`
+		}
+
+		resp := serve("/v1/admin/posts/mock-post/versions/" + versionID.Hex() + "/diff")
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expect status %d, but got %d", http.StatusOK, resp.Code)
+		}
+		json.Unmarshal(resp.Body.Bytes(), &res)
+
+		wantTitle := []utils.DiffSegment{{Type: utils.DiffEqual, Text: "the "}, {Type: utils.DiffDelete, Text: "old"}, {Type: utils.DiffInsert, Text: "new"}, {Type: utils.DiffEqual, Text: " title"}}
+		if len(res.Data.Title) != len(wantTitle) {
+			t.Fatalf("expect title diff %v, but got %v", wantTitle, res.Data.Title)
+		}
+		for i := range wantTitle {
+			if res.Data.Title[i] != wantTitle[i] {
+				t.Errorf("expect title diff %v, but got %v", wantTitle, res.Data.Title)
+			}
+		}
+
+		wantContent := []utils.DiffSegment{{Type: utils.DiffEqual, Text: "the first paragraph"}, {Type: utils.DiffInsert, Text: "\nthe added paragraph"}}
+		if len(res.Data.Content) != len(wantContent) || res.Data.Content[0] != wantContent[0] || res.Data.Content[1] != wantContent[1] {
+			t.Errorf("expect content diff %v, but got %v", wantContent, res.Data.Content)
+		}
+
+		if res.Data.Version.Content != nil {
+			t.Errorf("expect the content excluded from the version, but got %v", res.Data.Version.Content)
+		}
+	})
+
+	t.Run("Given an unknown version", func(t *testing.T) {
+		if resp := serve("/v1/admin/posts/mock-post/versions/" + bson.NewObjectId().Hex() + "/diff"); resp.Code != http.StatusNotFound {
+			t.Errorf("expect status %d, but got %d", http.StatusNotFound, resp.Code)
+		}
+	})
+
+	t.Run("Given an unknown post", func(t *testing.T) {
+		if resp := serve("/v1/admin/posts/unknown-post/versions"); resp.Code != http.StatusNotFound {
+			t.Errorf("expect status %d, but got %d", http.StatusNotFound, resp.Code)
+		}
+	})
+}
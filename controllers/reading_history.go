@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/storage"
+)
+
+type readingHistoryReqBody struct {
+	Slug string `json:"slug" binding:"required"`
+}
+
+// NewReadingHistoryController returns a ReadingHistoryController with the storage of posts and reading history
+func NewReadingHistoryController(ns postGetter, s storage.ReadingHistoryStorage) *ReadingHistoryController {
+	return &ReadingHistoryController{NewsStorage: ns, Storage: s}
+}
+
+// ReadingHistoryController manages the posts read by the users
+type ReadingHistoryController struct {
+	NewsStorage postGetter
+	Storage     storage.ReadingHistoryStorage
+}
+
+// RecordReadingHistory records the published post of `slug` in POST body as read by the user now.
+// The post views are recorded by the frontend on this endpoint,
+// since the responses of the posts are cached publicly and never reach the api for each read.
+func (rhc *ReadingHistoryController) RecordReadingHistory(c *gin.Context) (int, gin.H, error) {
+	var reqBody readingHistoryReqBody
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"slug": "should be the slug of the post read",
+		}}, nil
+	}
+
+	if _, err := rhc.NewsStorage.GetPublishedPostBySlug(reqBody.Slug); err != nil {
+		if storage.IsNotFound(err) {
+			return postNotFoundResponse(reqBody.Slug)
+		}
+		return toResponse(err)
+	}
+
+	userID, _ := strconv.ParseUint(c.Param("userID"), 10, 0)
+	readAt := time.Now()
+
+	if err := rhc.Storage.RecordReadingHistory(uint(userID), reqBody.Slug, readAt); err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusCreated, gin.H{"status": "success", "data": gin.H{
+		"slug":    reqBody.Slug,
+		"read_at": readAt,
+	}}, nil
+}
+
+// ClearReadingHistory removes all the posts from the reading history of the user
+func (rhc *ReadingHistoryController) ClearReadingHistory(c *gin.Context) (int, gin.H, error) {
+	if err := rhc.Storage.ClearReadingHistory(c.Param("userID")); err != nil {
+		return toResponse(err)
+	}
+
+	return http.StatusNoContent, gin.H{}, nil
+}
+
+// DeleteReadingHistory removes the post of `:slug` from the reading history of the user
+func (rhc *ReadingHistoryController) DeleteReadingHistory(c *gin.Context) (int, gin.H, error) {
+	slug := c.Param("slug")
+
+	if err := rhc.Storage.DeleteReadingHistory(c.Param("userID"), slug); err != nil {
+		if storage.IsNotFound(err) {
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{
+				"slug": fmt.Sprintf("post(slug: %s) is not in the reading history", slug),
+			}}, nil
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusNoContent, gin.H{}, nil
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockReadingHistoryStorage struct {
+	readAt map[string]time.Time
+}
+
+func (s *mockReadingHistoryStorage) RecordReadingHistory(userID uint, slug string, readAt time.Time) error {
+	s.readAt[slug] = readAt
+	return nil
+}
+
+func (s *mockReadingHistoryStorage) DeleteReadingHistory(userID string, slug string) error {
+	delete(s.readAt, slug)
+	return nil
+}
+
+func (s *mockReadingHistoryStorage) ClearReadingHistory(userID string) error {
+	s.readAt = map[string]time.Time{}
+	return nil
+}
+
+func TestRecordReadingHistory(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "Given a published post", body: `{"slug":"published-post"}`, wantCode: http.StatusCreated},
+		{name: "Given a draft", body: `{"slug":"mock-post"}`, wantCode: http.StatusNotFound},
+		{name: "Given the post not found", body: `{"slug":"unknown-post"}`, wantCode: http.StatusNotFound},
+		{name: "Given no slug", body: `{}`, wantCode: http.StatusBadRequest},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockReadingHistoryStorage{readAt: map[string]time.Time{}}
+			rhc := NewReadingHistoryController(annotatedPosts, s)
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/users/1/reading-history", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "userID", Value: "1"}}
+
+			code, _, _ := rhc.RecordReadingHistory(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			wantRecorded := tc.wantCode == http.StatusCreated
+			if recorded := len(s.readAt) == 1; recorded != wantRecorded {
+				t.Errorf("expect the post recorded %v, but got %v", wantRecorded, recorded)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"twreporter.org/go-api/models"
+)
+
+// recommendationListParams binds the number of the recommended posts
+var recommendationListParams = ListParamsBinder{
+	DefaultLimit: 10,
+	MaxLimit:     50,
+}
+
+const (
+	recommendationSourcePersonalized = "personalized"
+	recommendationSourcePopular      = "popular"
+)
+
+type recommendationReader interface {
+	GetRecommendedPostIDs(string) ([]string, error)
+}
+
+type recommendedPostsGetter interface {
+	GetPostsByIDs([]primitive.ObjectID) ([]models.Post, error)
+	GetMetaOfPosts(context.Context, models.MongoQuery, int, int, string, []string) ([]models.Post, int, error)
+}
+
+// NewRecommendationController ...
+// The storage is nil if the cache is not enabled, and the popular posts are always recommended.
+func NewRecommendationController(s recommendationReader, ns recommendedPostsGetter) *RecommendationController {
+	return &RecommendationController{Storage: s, NewsStorage: ns}
+}
+
+// RecommendationController recommends the posts to the members by their reading history
+type RecommendationController struct {
+	Storage     recommendationReader
+	NewsStorage recommendedPostsGetter
+}
+
+// GetRecommendedPosts returns the posts recommended for the user of `:userID`, which are computed by the recommendation job.
+// The most viewed posts are returned instead for the new users or if the recommended posts are unavailable.
+func (rc *RecommendationController) GetRecommendedPosts(c *gin.Context) (int, gin.H, error) {
+	params, err := recommendationListParams.BindListParams(c)
+	if err != nil {
+		return listParamsFailResponse(err)
+	}
+
+	posts, err := rc.getPersonalizedPosts(c.Param("userID"), params.Limit)
+	if err != nil {
+		// degrade to the popular posts rather than failing the request
+		log.Errorf("%+v", err)
+	}
+	if len(posts) > 0 {
+		return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+			"records": posts,
+			"meta":    gin.H{"source": recommendationSourcePersonalized},
+		}}, nil
+	}
+
+	posts, _, err = rc.NewsStorage.GetMetaOfPosts(c.Request.Context(), models.MongoQuery{}, params.Limit, 0, "-viewCount", nil)
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"records": emptyIfNil(posts),
+		"meta":    gin.H{"source": recommendationSourcePopular},
+	}}, nil
+}
+
+// getPersonalizedPosts returns at most limit posts cached for the user,
+// the posts unpublished after computed are skipped
+func (rc *RecommendationController) getPersonalizedPosts(userID string, limit int) ([]models.Post, error) {
+	if rc.Storage == nil {
+		return nil, nil
+	}
+
+	hexIDs, err := rc.Storage.GetRecommendedPostIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(hexIDs))
+	for _, hexID := range hexIDs {
+		id, err := primitive.ObjectIDFromHex(hexID)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	posts, err := rc.NewsStorage.GetPostsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts, nil
+}
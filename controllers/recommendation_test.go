@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+type mockRecommendationCache struct {
+	ids map[string][]string
+	err error
+}
+
+func (s *mockRecommendationCache) GetRecommendedPostIDs(userID string) ([]string, error) {
+	return s.ids[userID], s.err
+}
+
+type mockRecommendedPostsStorage struct {
+	posts map[string]models.Post
+	sort  string
+}
+
+func (s *mockRecommendedPostsStorage) GetPostsByIDs(ids []primitive.ObjectID) ([]models.Post, error) {
+	posts := []models.Post{}
+	for _, id := range ids {
+		if post, ok := s.posts[id.Hex()]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (s *mockRecommendedPostsStorage) GetMetaOfPosts(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Post, int, error) {
+	s.sort = sort
+	return []models.Post{{Slug: "popular-post"}}, 1, nil
+}
+
+func TestGetRecommendedPosts(t *testing.T) {
+	const (
+		idA = "5edf118c3e631f0600190001"
+		idB = "5edf118c3e631f0600190002"
+		// idGone is unpublished after recommended
+		idGone = "5edf118c3e631f0600190003"
+	)
+	ns := &mockRecommendedPostsStorage{posts: map[string]models.Post{
+		idA: {ID: bson.ObjectIdHex(idA), Slug: "post-a"},
+		idB: {ID: bson.ObjectIdHex(idB), Slug: "post-b"},
+	}}
+	cache := &mockRecommendationCache{ids: map[string][]string{
+		"1": {idB, idGone, idA},
+		"2": {idGone},
+	}}
+
+	cases := []struct {
+		name      string
+		cache     recommendationReader
+		userID    string
+		query     string
+		wantCode  int
+		wantSlugs []string
+		wantFrom  string
+	}{
+		{name: "Given the recommended posts", cache: cache, userID: "1", wantCode: http.StatusOK, wantSlugs: []string{"post-b", "post-a"}, wantFrom: recommendationSourcePersonalized},
+		{name: "Given the limit", cache: cache, userID: "1", query: "?limit=1", wantCode: http.StatusOK, wantSlugs: []string{"post-b"}, wantFrom: recommendationSourcePersonalized},
+		{name: "Given a new user", cache: cache, userID: "3", wantCode: http.StatusOK, wantSlugs: []string{"popular-post"}, wantFrom: recommendationSourcePopular},
+		{name: "Given the recommended posts are all unpublished", cache: cache, userID: "2", wantCode: http.StatusOK, wantSlugs: []string{"popular-post"}, wantFrom: recommendationSourcePopular},
+		{name: "Given the cache fails", cache: &mockRecommendationCache{err: errors.New("connection refused")}, userID: "1", wantCode: http.StatusOK, wantSlugs: []string{"popular-post"}, wantFrom: recommendationSourcePopular},
+		{name: "Given the cache is not enabled", userID: "1", wantCode: http.StatusOK, wantSlugs: []string{"popular-post"}, wantFrom: recommendationSourcePopular},
+		{name: "Given an invalid limit", cache: cache, userID: "1", query: "?limit=-1", wantCode: http.StatusBadRequest},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/users/"+tc.userID+"/recommended"+tc.query, nil)
+			c.Params = gin.Params{{Key: "userID", Value: tc.userID}}
+
+			rc := NewRecommendationController(nil, ns)
+			if tc.cache != nil {
+				rc.Storage = tc.cache
+			}
+
+			code, body, err := rc.GetRecommendedPosts(c)
+			if err != nil {
+				t.Fatalf("expect no error, but got %v", err)
+			}
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			data := body["data"].(gin.H)
+			posts := data["records"].([]models.Post)
+			if len(posts) != len(tc.wantSlugs) {
+				t.Fatalf("expect %d posts, but got %d", len(tc.wantSlugs), len(posts))
+			}
+			for i, slug := range tc.wantSlugs {
+				if posts[i].Slug != slug {
+					t.Errorf("expect post %d to be %s, but got %s", i, slug, posts[i].Slug)
+				}
+			}
+			if from := data["meta"].(gin.H)["source"]; from != tc.wantFrom {
+				t.Errorf("expect source %s, but got %v", tc.wantFrom, from)
+			}
+			if tc.wantFrom == recommendationSourcePopular && ns.sort != "-viewCount" {
+				t.Errorf("expect the popular posts sorted by -viewCount, but got %s", ns.sort)
+			}
+		})
+	}
+}
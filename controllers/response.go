@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+)
+
+// paginatedResponse builds the `{status, records, meta}` payload of the list endpoints.
+// `records` is guaranteed to be `[]` rather than `null` in the response.
+func paginatedResponse(records interface{}, total, offset, limit int) (int, gin.H) {
+	return http.StatusOK, gin.H{"status": "ok", "records": emptyIfNil(records), "meta": newMetaOfResponse(total, offset, limit)}
+}
+
+// newMetaOfResponse returns the pagination meta, whose numbers are serialized as configured by `app.meta_numbers_as_strings`
+func newMetaOfResponse(total, offset, limit int) models.MetaOfResponse {
+	meta := models.NewMetaOfResponse(total, offset, limit)
+	meta.NumbersAsStrings = globals.Conf.App.MetaNumbersAsStrings
+	return meta
+}
+
+// singleResponse builds the `{status, record}` payload of the single resource endpoints.
+func singleResponse(record interface{}) (int, gin.H) {
+	return http.StatusOK, gin.H{"status": "ok", "record": record}
+}
+
+// notFoundResponse builds the payload of the single resource endpoints when the record is not found.
+func notFoundResponse() (int, gin.H) {
+	return http.StatusNotFound, gin.H{"status": "Record Not Found", "error": "Record Not Found"}
+}
+
+// emptyIfNil converts nil or nil slice into an empty slice of the same type
+func emptyIfNil(records interface{}) interface{} {
+	if records == nil {
+		return make([]interface{}, 0)
+	}
+
+	v := reflect.ValueOf(records)
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	return records
+}
+
+// checkNotModified sets Last-Modified header and reports whether the copy cached by the client is still fresh.
+// As RFC 7232, If-Modified-Since is ignored if If-None-Match is sent, since the entity tag is more accurate.
+func checkNotModified(c *gin.Context, lastModified time.Time) bool {
+	if lastModified.IsZero() {
+		return false
+	}
+
+	// HTTP date is in seconds
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	if c.GetHeader("If-None-Match") != "" {
+		return false
+	}
+
+	ims, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(ims)
+}
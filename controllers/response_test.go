@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+)
+
+func TestPaginatedResponse(t *testing.T) {
+	cases := []struct {
+		name    string
+		records interface{}
+		want    string
+	}{
+		{
+			name:    "Given nil records",
+			records: nil,
+			want:    `{"meta":{"total":0,"offset":0,"limit":10,"page":1,"per_page":10},"records":[],"status":"ok"}`,
+		},
+		{
+			name:    "Given nil slice of records",
+			records: []models.Topic(nil),
+			want:    `{"meta":{"total":0,"offset":0,"limit":10,"page":1,"per_page":10},"records":[],"status":"ok"}`,
+		},
+		{
+			name:    "Given slice of records",
+			records: []string{"record1", "record2"},
+			want:    `{"meta":{"total":2,"offset":0,"limit":10,"page":1,"per_page":10},"records":["record1","record2"],"status":"ok"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var total int
+			if records, ok := tc.records.([]string); ok {
+				total = len(records)
+			}
+
+			statusCode, resp := paginatedResponse(tc.records, total, 0, 10)
+			if statusCode != http.StatusOK {
+				t.Errorf("expected status code %d, got %d", http.StatusOK, statusCode)
+			}
+
+			got, _ := json.Marshal(resp)
+			if string(got) != tc.want {
+				t.Errorf("expected response %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSingleResponse(t *testing.T) {
+	statusCode, resp := singleResponse("record")
+	if statusCode != http.StatusOK {
+		t.Errorf("expected status code %d, got %d", http.StatusOK, statusCode)
+	}
+	if resp["record"] != "record" {
+		t.Errorf("expected record %s, got %v", "record", resp["record"])
+	}
+
+	statusCode, _ = notFoundResponse()
+	if statusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, got %d", http.StatusNotFound, statusCode)
+	}
+}
+
+func TestCheckNotModified(t *testing.T) {
+	lastModified := time.Date(2020, 6, 8, 16, 0, 0, 500, time.UTC)
+
+	cases := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{
+			name: "Given no conditional headers",
+			want: false,
+		},
+		{
+			name:    "Given If-Modified-Since equal to Last-Modified",
+			headers: map[string]string{"If-Modified-Since": "Mon, 08 Jun 2020 16:00:00 GMT"},
+			want:    true,
+		},
+		{
+			name:    "Given newer If-Modified-Since",
+			headers: map[string]string{"If-Modified-Since": "Tue, 09 Jun 2020 16:00:00 GMT"},
+			want:    true,
+		},
+		{
+			name:    "Given older If-Modified-Since",
+			headers: map[string]string{"If-Modified-Since": "Sun, 07 Jun 2020 16:00:00 GMT"},
+			want:    false,
+		},
+		{
+			name:    "Given If-None-Match along with If-Modified-Since",
+			headers: map[string]string{"If-Modified-Since": "Tue, 09 Jun 2020 16:00:00 GMT", "If-None-Match": `"etag"`},
+			want:    false,
+		},
+		{
+			name:    "Given invalid If-Modified-Since",
+			headers: map[string]string{"If-Modified-Since": "yesterday"},
+			want:    false,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/topics", nil)
+			for key, value := range tc.headers {
+				c.Request.Header.Set(key, value)
+			}
+
+			if got := checkNotModified(c, lastModified); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+			if got := resp.Header().Get("Last-Modified"); got != "Mon, 08 Jun 2020 16:00:00 GMT" {
+				t.Errorf("unexpected Last-Modified %q", got)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/algolia/algoliasearch-client-go/algoliasearch"
 	"github.com/gin-gonic/gin"
@@ -13,37 +14,67 @@ import (
 	"twreporter.org/go-api/globals"
 )
 
-// search - search records from algolia webservice
+type searchResult struct {
+	res algoliasearch.QueryRes
+	err error
+}
+
+// searchIndex searches the index of algolia webservice, the read timeout is zero if the request has no deadline
+var searchIndex = func(indexName string, keywords string, params algoliasearch.Map, readTimeout time.Duration) (algoliasearch.QueryRes, error) {
+	client := algoliasearch.NewClient(globals.Conf.Algolia.ApplicationID, globals.Conf.Algolia.APIKey)
+	if readTimeout > 0 {
+		client.SetReadTimeout(readTimeout)
+	}
+	return client.InitIndex(indexName).Search(keywords, params)
+}
+
+// search - search records from algolia webservice.
+// The algolia client is not context-aware, so the search runs apart from the handler,
+// which returns without writing once the request context is done, and leaves the response to the timeout middleware.
+// The search left running is bounded by the read timeout of the deadline, and its result is dropped.
 func search(c *gin.Context, indexName string) {
-	var err error
 	var hitsPerPage int
 	var page int
-	var res algoliasearch.QueryRes
+	var readTimeout time.Duration
 
 	filters := c.Query("filters")
 	hitsPerPage, _ = strconv.Atoi(c.Query("hitsPerPage"))
 	page, _ = strconv.Atoi(c.Query("page"))
 	keywords := c.Query("keywords")
 
-	client := algoliasearch.NewClient(globals.Conf.Algolia.ApplicationID, globals.Conf.Algolia.APIKey)
-	index := client.InitIndex(indexName)
+	ctx := c.Request.Context()
+	if deadline, ok := ctx.Deadline(); ok {
+		readTimeout = time.Until(deadline)
+	}
 
-	res, err = index.Search(keywords, algoliasearch.Map{
-		"filters":     filters,
-		"hitsPerPage": hitsPerPage,
-		"page":        page,
-	})
+	done := make(chan searchResult, 1)
+	go func() {
+		res, err := searchIndex(indexName, keywords, algoliasearch.Map{
+			"filters":     filters,
+			"hitsPerPage": hitsPerPage,
+			"page":        page,
+		}, readTimeout)
+		done <- searchResult{res, err}
+	}()
+
+	var result searchResult
+	select {
+	case <-ctx.Done():
+		return
+	case result = <-done:
+	}
 
-	if err != nil {
+	if err := result.err; err != nil {
 		if globals.Conf.Environment == "development" {
 			log.Errorf("%+v", errors.WithStack(err))
 		} else {
 			log.WithField("detail", err).Errorf("%s", f.FormatStack(errors.WithStack(err)))
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "Internal server error", "error": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	c.JSON(http.StatusOK, result.res)
 }
 
 // SearchAuthors - search authors from algolia webservice
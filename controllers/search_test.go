@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/middlewares"
+)
+
+func TestSearchTimeout(t *testing.T) {
+	defaultSearchIndex := searchIndex
+	defer func() { searchIndex = defaultSearchIndex }()
+
+	readTimeouts := make(chan time.Duration, 1)
+	released := make(chan struct{})
+	searchIndex = func(indexName string, keywords string, params algoliasearch.Map, timeout time.Duration) (algoliasearch.QueryRes, error) {
+		readTimeouts <- timeout
+		// the search ignores the deadline
+		<-released
+		return algoliasearch.QueryRes{NbHits: 1}, nil
+	}
+	defer close(released)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/search", middlewares.Timeout(20*time.Millisecond), func(c *gin.Context) {
+		search(c, "posts-index-v2")
+	})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/search?keywords=mock", nil)
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("expect status %d, but got %d", http.StatusGatewayTimeout, w.Code)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expect responded at the deadline, but took %s", elapsed)
+	}
+	if readTimeout := <-readTimeouts; readTimeout <= 0 || readTimeout > 20*time.Millisecond {
+		t.Errorf("expect the read timeout bounded by the deadline, but got %s", readTimeout)
+	}
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+const (
+	// tagMatchAny matches the topics having any of the tags
+	tagMatchAny = "any"
+	// tagMatchAll matches the topics having all of the tags
+	tagMatchAll = "all"
+)
+
+// GetTags lists the distinct tags of the topics along with the number of the topics referencing each
+func (nc *NewsController) GetTags(c *gin.Context) (int, gin.H, error) {
+	tags, err := nc.Storage.GetTagCountsOfTopics()
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	statusCode, resp := paginatedResponse(tags, len(tags), 0, len(tags))
+	return statusCode, resp, nil
+}
+
+// GetTopicsOfTags returns the topics of the comma separated tag slugs, e.g. `/v1/tags/taiwan,election/topics`.
+// `match` url query param is `any`(by default) or `all`, matching the topics having any or all of the tags.
+// The unknown tags match no topics, so the empty records are returned if none of the tags is known,
+// or any of them is unknown while matching all.
+// The topics are paginated by the list params, see ListParamsBinder.BindListParams.
+// `expand=authors` embeds the authors of the related posts of the full topics, see topicExpandQuery.
+func (nc *NewsController) GetTopicsOfTags(c *gin.Context) (int, gin.H, error) {
+	var topics []models.Topic
+	var total int
+
+	params, err := topicListParams.BindListParams(c)
+	if err != nil {
+		return listParamsFailResponse(err)
+	}
+
+	match := c.DefaultQuery("match", tagMatchAny)
+	if match != tagMatchAny && match != tagMatchAll {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"match": "should be any or all"}}, nil
+	}
+
+	var mq models.MongoQuery
+	if err = topicExpandQuery(c, &mq, params.Full); err != nil {
+		return topicExpandFailResponse(err)
+	}
+
+	slugs := strings.Split(c.Param("tag"), ",")
+	tags, err := nc.Storage.GetTagsBySlugs(slugs)
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	if len(tags) == 0 || (match == tagMatchAll && len(tags) < len(slugs)) {
+		statusCode, resp := paginatedResponse(topics, total, params.Offset, params.Limit)
+		return statusCode, resp, nil
+	}
+
+	var ids = make([]bson.ObjectId, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+
+	if match == tagMatchAll {
+		mq.Tags.All = ids
+	} else {
+		mq.Tags.In = ids
+	}
+
+	if params.Full {
+		topics, total, err = nc.Storage.GetFullTopics(c.Request.Context(), mq, params.Limit, params.Offset, params.Sort, nil)
+	} else {
+		topics, total, err = nc.Storage.GetMetaOfTopics(c.Request.Context(), mq, params.Limit, params.Offset, params.Sort, nil)
+	}
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	statusCode, resp := paginatedResponse(topics, total, params.Offset, params.Limit)
+	return statusCode, resp, nil
+}
@@ -1,78 +1,196 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"twreporter.org/go-api/globals"
 	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
 )
 
+const (
+	defaultTopicPostLimit = 10
+	defaultTrendingTopics = 10
+	maxTrendingTopics     = 50
+	defaultRelatedTopics  = 5
+	maxRelatedTopics      = 20
+)
+
+// topicListParams binds the list params of the topics
+var topicListParams = ListParamsBinder{
+	DefaultLimit: 10,
+	MaxLimit:     100,
+	DefaultSort:  "-publishedDate",
+	SortFields:   []string{"publishedDate", "updatedAt"},
+	Fields: []string{
+		"id", "slug", "name", "topic_name", "title", "title_position", "subtitle", "headline", "state",
+		"description", "team_description", "relateds", "relateds_format", "relateds_background",
+		"leading_image", "leading_image_portrait", "leading_video", "og_title", "og_description", "og_image",
+		"tags", "published_date", "updated_at", "full", "post_count",
+	},
+}
+
 // GetTopics receive HTTP GET method request, and return the topics.
-// `query`, `limit`, `offset` and `sort` are the url query params,
+// `where` and the list params, see ListParamsBinder.BindListParams, are the url query params,
 // which define the rule we retrieve topics from storage.
+// `fields` selects the fields of the topics, e.g. `fields=slug,title`.
+// Last-Modified header is the latest updatedAt of the returned topics, and 304 is responded for If-Modified-Since.
+// If `since`(or `updatedSince`) or `until` url query param is provided, only the topics updated after since and not after until
+// are returned, sorted by updatedAt ascendingly.
+// `expand=authors` embeds the authors of the related posts, which is only supported with `full=true`, see topicExpandQuery.
 func (nc *NewsController) GetTopics(c *gin.Context) (int, gin.H, error) {
+	var mq models.MongoQuery
 	var total int
-	var topics []models.Topic = make([]models.Topic, 0)
-
-	err, mq, limit, offset, sort, full := nc.GetQueryParam(c)
+	var topics []models.Topic
 
-	// response empty records if parsing url query param occurs error
+	params, err := topicListParams.BindListParams(c)
 	if err != nil {
-		return http.StatusOK, gin.H{"status": "ok", "records": topics, "meta": models.MetaOfResponse{
-			Total:  total,
-			Offset: offset,
-			Limit:  limit,
-		}}, nil
+		return listParamsFailResponse(err)
 	}
 
-	if limit == 0 {
-		limit = 10
+	if !params.Since.IsZero() || !params.Until.IsZero() {
+		// the updated topics are not full, which have no related posts to expand
+		if _, ok := c.GetQuery("expand"); ok {
+			return expandNotFullResponse()
+		}
+		topics, total, err = nc.Storage.GetTopicsUpdatedSince(c.Request.Context(), params.Since, params.Until, params.Limit, params.Offset)
+	} else {
+		where := c.Query("where")
+		if where == "" {
+			where = "{}"
+		}
+
+		// response empty records if parsing url query param occurs error
+		if err = models.GetQuery(where, &mq); err != nil {
+			statusCode, resp := paginatedResponse(topics, total, params.Offset, params.Limit)
+			return statusCode, resp, nil
+		}
+
+		if err = topicExpandQuery(c, &mq, params.Full); err != nil {
+			return topicExpandFailResponse(err)
+		}
+
+		if params.Full {
+			topics, total, err = nc.Storage.GetFullTopics(c.Request.Context(), mq, params.Limit, params.Offset, params.Sort, nil)
+		} else {
+			topics, total, err = nc.Storage.GetMetaOfTopics(c.Request.Context(), mq, params.Limit, params.Offset, params.Sort, nil)
+		}
 	}
 
-	if sort == "" {
-		sort = "-publishedDate"
+	if err != nil {
+		return toPostResponse(err)
 	}
 
-	if full {
-		topics, total, err = nc.Storage.GetFullTopics(mq, limit, offset, sort, nil)
-	} else {
-		topics, total, err = nc.Storage.GetMetaOfTopics(mq, limit, offset, sort, nil)
+	if checkNotModified(c, lastModifiedOfTopics(topics)) {
+		return http.StatusNotModified, nil, nil
 	}
 
+	records, err := selectFields(topics, params.Fields)
 	if err != nil {
-		return toPostResponse(err)
+		return toResponse(err)
+	}
+
+	statusCode, resp := paginatedResponse(records, total, params.Offset, params.Limit)
+	return statusCode, resp, nil
+}
+
+// errExpandNotFull is returned by topicExpandQuery if `expand` is provided without `full=true`
+var errExpandNotFull = errors.New("expand is only supported with full=true")
+
+// topicExpandQuery sets the fields of `expand` url query param of the topics.
+// The topics refer to no authors, the authors of the related posts are expanded instead,
+// which are only embedded in the full topics, so errExpandNotFull is returned if the topics are not full.
+func topicExpandQuery(c *gin.Context, mq *models.MongoQuery, full bool) error {
+	if err := expandQuery(c, mq); err != nil {
+		return err
 	}
+	if len(mq.ExpandFields) > 0 && !full {
+		return errExpandNotFull
+	}
+	return nil
+}
 
-	// make sure `response.records`
-	// would be `[]` rather than  `null`
-	if topics == nil {
-		topics = make([]models.Topic, 0)
+// topicExpandFailResponse responds 400 if `expand` url query param of the topics is invalid, see topicExpandQuery
+func topicExpandFailResponse(err error) (int, gin.H, error) {
+	if err == errExpandNotFull {
+		return expandNotFullResponse()
 	}
+	return invalidExpandResponse()
+}
 
-	return http.StatusOK, gin.H{"status": "ok", "records": topics, "meta": models.MetaOfResponse{
-		Total:  total,
-		Offset: offset,
-		Limit:  limit,
+// expandNotFullResponse responds 400 if `expand` url query param is provided for the topics which are not full
+func expandNotFullResponse() (int, gin.H, error) {
+	return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+		"expand": errExpandNotFull.Error(),
 	}}, nil
 }
 
+// lastModifiedOfTopics returns the latest updatedAt of the topics
+func lastModifiedOfTopics(topics []models.Topic) time.Time {
+	var lastModified time.Time
+	for _, topic := range topics {
+		if topic.UpdatedAt.After(lastModified) {
+			lastModified = topic.UpdatedAt
+		}
+	}
+	return lastModified
+}
+
+// paginateTopicSections slices the sections(related posts) of the topic by offset and limit,
+// and returns the total number of sections.
+// Zero limit means all the sections after offset, and out-of-range offset results in empty sections.
+func paginateTopicSections(topic *models.Topic, offset, limit int) int {
+	total := len(topic.Relateds)
+
+	if offset >= total {
+		topic.Relateds = []models.Post{}
+		return total
+	}
+
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+
+	topic.Relateds = topic.Relateds[offset:end]
+	return total
+}
+
 // GetATopic receive HTTP GET method request, and return the certain post.
+// `sectionOffset` and `sectionLimit` are the url query params,
+// which paginate the sections(related posts) of the topic.
+// If `withPosts=true` is provided, the posts of the topic are returned together.
+// If `expand=authors` is provided with `full=true`, the authors of the related posts of the topic are embedded,
+// otherwise 400 is responded.
 func (nc *NewsController) GetATopic(c *gin.Context) (int, gin.H, error) {
 	var topics []models.Topic
 	var err error
 
 	slug := c.Param("slug")
+	if withPosts, _ := strconv.ParseBool(c.Query("withPosts")); withPosts {
+		return nc.getTopicWithPosts(c, slug)
+	}
+
 	full, _ := strconv.ParseBool(c.Query("full"))
+	_sectionOffset, hasSectionOffset := c.GetQuery("sectionOffset")
+	_sectionLimit, hasSectionLimit := c.GetQuery("sectionLimit")
 
 	mq := models.MongoQuery{
 		Slug: slug,
 	}
+	if err = topicExpandQuery(c, &mq, full); err != nil {
+		return topicExpandFailResponse(err)
+	}
 
 	if full {
-		topics, _, err = nc.Storage.GetFullTopics(mq, 1, 0, "-publishedDate", nil)
+		topics, _, err = nc.Storage.GetFullTopics(c.Request.Context(), mq, 1, 0, "-publishedDate", nil)
 	} else {
-		topics, _, err = nc.Storage.GetMetaOfTopics(mq, 1, 0, "-publishedDate", nil)
+		topics, _, err = nc.Storage.GetMetaOfTopics(c.Request.Context(), mq, 1, 0, "-publishedDate", nil)
 	}
 
 	if err != nil {
@@ -80,8 +198,155 @@ func (nc *NewsController) GetATopic(c *gin.Context) (int, gin.H, error) {
 	}
 
 	if len(topics) == 0 {
-		return http.StatusNotFound, gin.H{"status": "Record Not Found", "error": "Record Not Found"}, nil
+		statusCode, resp := notFoundResponse()
+		return statusCode, resp, nil
+	}
+
+	nc.Storage.IncrementTopicViews(slug)
+
+	if !hasSectionOffset && !hasSectionLimit {
+		statusCode, resp := singleResponse(topics[0])
+		return statusCode, resp, nil
+	}
+
+	// provide default param if error occurs
+	sectionOffset, _ := strconv.Atoi(_sectionOffset)
+	sectionLimit, _ := strconv.Atoi(_sectionLimit)
+
+	if sectionOffset < 0 {
+		sectionOffset = 0
+	}
+
+	if sectionLimit < 0 {
+		sectionLimit = 0
+	}
+
+	sectionTotal := paginateTopicSections(&topics[0], sectionOffset, sectionLimit)
+
+	statusCode, resp := singleResponse(topics[0])
+	resp["meta"] = gin.H{
+		"sectionTotal":  sectionTotal,
+		"sectionOffset": sectionOffset,
+		"sectionLimit":  sectionLimit,
+	}
+	return statusCode, resp, nil
+}
+
+// getTopicWithPosts returns the topic with its posts,
+// which are paginated by `postOffset` and `postLimit` url query params.
+func (nc *NewsController) getTopicWithPosts(c *gin.Context, slug string) (int, gin.H, error) {
+	// provide default param if error occurs
+	postOffset, _ := strconv.Atoi(c.Query("postOffset"))
+	postLimit, err := strconv.Atoi(c.Query("postLimit"))
+	if err != nil || postLimit <= 0 {
+		postLimit = defaultTopicPostLimit
+	}
+
+	if postOffset < 0 {
+		postOffset = 0
+	}
+
+	topic, posts, postTotal, err := nc.Storage.GetTopicWithPosts(c.Request.Context(), slug, postLimit, postOffset)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			statusCode, resp := notFoundResponse()
+			return statusCode, resp, nil
+		}
+		return toPostResponse(err)
+	}
+
+	nc.Storage.IncrementTopicViews(slug)
+
+	statusCode, resp := singleResponse(topic)
+	resp["posts"] = emptyIfNil(posts)
+	resp["meta"] = gin.H{
+		"postTotal":  postTotal,
+		"postOffset": postOffset,
+		"postLimit":  postLimit,
+	}
+	return statusCode, resp, nil
+}
+
+// GetTrendingTopics returns the topics ranked by their views in the `window` url query param,
+// e.g. `24h` or `7d`. The window defaults to `news.trending_topics_window` of config.
+func (nc *NewsController) GetTrendingTopics(c *gin.Context) (int, gin.H, error) {
+	window := c.DefaultQuery("window", globals.Conf.News.TrendingTopicsWindow.String())
+	d, err := parseWindow(window, globals.Conf.News.TrendingTopicsMaxWindow)
+	if err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"window": fmt.Sprintf("should be a duration(e.g. 24h or 7d) not longer than %s", globals.Conf.News.TrendingTopicsMaxWindow),
+		}}, nil
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	if limit <= 0 || limit > maxTrendingTopics {
+		limit = defaultTrendingTopics
+	}
+
+	views, err := nc.Storage.GetTopicViewsSince(time.Now().Add(-d), limit)
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	var topics = make([]models.Topic, 0, len(views))
+	if len(views) > 0 {
+		slugs := make([]string, 0, len(views))
+		for _, view := range views {
+			slugs = append(slugs, view.Slug)
+		}
+
+		matched, err := nc.Storage.GetMetaOfTopicsBySlugs(slugs)
+		if err != nil {
+			return toPostResponse(err)
+		}
+
+		topicsBySlug := make(map[string]models.Topic, len(matched))
+		for _, topic := range matched {
+			topicsBySlug[topic.Slug] = topic
+		}
+		// keep the order of the views, the topic might be unpublished or deleted after it was viewed
+		for _, view := range views {
+			if topic, ok := topicsBySlug[view.Slug]; ok {
+				topics = append(topics, topic)
+			}
+		}
+	}
+
+	statusCode, resp := paginatedResponse(topics, len(topics), 0, limit)
+	return statusCode, resp, nil
+}
+
+// GetRelatedTopics returns the topics sharing at least one tag with the topic of the slug,
+// which are ranked by the number of shared tags, and then by publishedDate.
+// The number of the topics is limited by `limit` url query param.
+func (nc *NewsController) GetRelatedTopics(c *gin.Context) (int, gin.H, error) {
+	slug := c.Param("slug")
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	if limit <= 0 || limit > maxRelatedTopics {
+		limit = defaultRelatedTopics
+	}
+
+	matched, _, err := nc.Storage.GetMetaOfTopics(c.Request.Context(), models.MongoQuery{Slug: slug}, 1, 0, "-publishedDate", []string{})
+	if err != nil {
+		return toPostResponse(err)
+	}
+
+	if len(matched) == 0 {
+		statusCode, resp := notFoundResponse()
+		return statusCode, resp, nil
+	}
+
+	var tags = make([]string, 0, len(matched[0].TagsOrigin))
+	for _, tag := range matched[0].TagsOrigin {
+		tags = append(tags, tag.Hex())
+	}
+
+	topics, err := nc.Storage.GetRelatedTopics(tags, slug, limit)
+	if err != nil {
+		return toPostResponse(err)
 	}
 
-	return http.StatusOK, gin.H{"status": "ok", "record": topics[0]}, nil
+	statusCode, resp := paginatedResponse(topics, len(topics), 0, limit)
+	return statusCode, resp, nil
 }
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/storage"
+)
+
+type topicPostsReorderer interface {
+	ReorderPostsOfTopic(string, []string) error
+}
+
+// NewTopicPostsController ...
+func NewTopicPostsController(s topicPostsReorderer) *TopicPostsController {
+	return &TopicPostsController{Storage: s}
+}
+
+// TopicPostsController arranges the posts within the topics for the editors
+type TopicPostsController struct {
+	Storage topicPostsReorderer
+}
+
+type topicPostsOrderReqBody struct {
+	Order []string `json:"order" binding:"required"`
+}
+
+// ReorderPostsOfTopic rearranges the posts of the topic of `:slug` in the order of the post slugs in the request body.
+// The slugs should be exactly the current posts of the topic, otherwise 409 is responded.
+func (tpc *TopicPostsController) ReorderPostsOfTopic(c *gin.Context) (int, gin.H, error) {
+	var reqBody topicPostsOrderReqBody
+
+	slug := c.Param("slug")
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": fmt.Sprintf("should be {\"order\": [\"slug1\", \"slug2\"]}. %s", err.Error()),
+		}}, nil
+	}
+
+	if err := tpc.Storage.ReorderPostsOfTopic(slug, reqBody.Order); err != nil {
+		switch {
+		case storage.IsNotFound(err):
+			return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{
+				"slug": fmt.Sprintf("cannot find the topic(slug: %s)", slug),
+			}}, nil
+		case errors.Cause(err) == storage.ErrTopicPostsMismatch:
+			return http.StatusConflict, gin.H{"status": "fail", "data": gin.H{
+				"order": fmt.Sprintf("should be exactly the posts of the topic(slug: %s)", slug),
+			}}, nil
+		case storage.IsConflict(err):
+			return http.StatusConflict, gin.H{"status": "fail", "data": gin.H{
+				"order": fmt.Sprintf("posts of the topic(slug: %s) are updated concurrently, please retry", slug),
+			}}, nil
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{"slug": slug, "order": reqBody.Order}}, nil
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/storage"
+)
+
+type mockTopicPostsReorderer struct {
+	err   error
+	order []string
+}
+
+func (m *mockTopicPostsReorderer) ReorderPostsOfTopic(slug string, order []string) error {
+	m.order = order
+	return m.err
+}
+
+func TestReorderPostsOfTopic(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+	}{
+		{name: "Given the posts of the topic", body: `{"order": ["post-b", "post-a"]}`, wantCode: http.StatusOK},
+		{name: "Given the order missing", body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "Given a malformed body", body: `{"order": "post-a"}`, wantCode: http.StatusBadRequest},
+		{name: "Given a topic not existing", body: `{"order": []}`, err: errors.WithStack(storage.ErrMgoNotFound), wantCode: http.StatusNotFound},
+		{name: "Given the posts not matched", body: `{"order": ["post-a"]}`, err: errors.WithStack(storage.ErrTopicPostsMismatch), wantCode: http.StatusConflict},
+		{name: "Given the posts updated concurrently", body: `{"order": ["post-b", "post-a"]}`, err: errors.WithStack(storage.ErrUpdateConflict), wantCode: http.StatusConflict},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockTopicPostsReorderer{err: tc.err}
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPatch, "/v1/admin/topics/mock-topic/posts", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "slug", Value: "mock-topic"}}
+
+			code, body, _ := NewTopicPostsController(s).ReorderPostsOfTopic(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, code)
+			}
+			if code == http.StatusOK && strings.Join(body["data"].(gin.H)["order"].([]string), ",") != "post-b,post-a" {
+				t.Errorf("expect the order responded, but got %v", body)
+			}
+		})
+	}
+}
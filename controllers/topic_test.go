@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+func helperCreateTopic(sections int) models.Topic {
+	var topic models.Topic
+	for i := 0; i < sections; i++ {
+		topic.Relateds = append(topic.Relateds, models.Post{Slug: fmt.Sprintf("post-%d", i)})
+	}
+	return topic
+}
+
+func helperGetSectionSlugs(topic models.Topic) []string {
+	slugs := make([]string, 0)
+	for _, post := range topic.Relateds {
+		slugs = append(slugs, post.Slug)
+	}
+	return slugs
+}
+
+func TestPaginateTopicSections(t *testing.T) {
+	cases := []struct {
+		name   string
+		offset int
+		limit  int
+		want   []string
+	}{
+		{
+			name:   "Given the first page",
+			offset: 0,
+			limit:  2,
+			want:   []string{"post-0", "post-1"},
+		},
+		{
+			name:   "Given the last page",
+			offset: 4,
+			limit:  2,
+			want:   []string{"post-4"},
+		},
+		{
+			name:   "Given zero limit",
+			offset: 3,
+			limit:  0,
+			want:   []string{"post-3", "post-4"},
+		},
+		{
+			name:   "Given out-of-range offset",
+			offset: 5,
+			limit:  2,
+			want:   []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			topic := helperCreateTopic(5)
+
+			total := paginateTopicSections(&topic, tc.offset, tc.limit)
+			if total != 5 {
+				t.Errorf("expected section total %d, got %d", 5, total)
+			}
+
+			if got := helperGetSectionSlugs(topic); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected sections %v, got %v", tc.want, got)
+			}
+
+			if topic.Relateds == nil {
+				t.Errorf("expected sections to be an empty slice rather than nil")
+			}
+		})
+	}
+}
+
+type mockBlockingTopicsStorage struct {
+	storage.NewsStorage
+	started  chan struct{}
+	canceled chan struct{}
+}
+
+func (m *mockBlockingTopicsStorage) GetMetaOfTopics(ctx context.Context, mq models.MongoQuery, limit, offset int, sort string, embedded []string) ([]models.Topic, int, error) {
+	close(m.started)
+	// slow backend
+	select {
+	case <-ctx.Done():
+		close(m.canceled)
+		return nil, 0, errors.WithStack(ctx.Err())
+	case <-time.After(time.Second):
+		return []models.Topic{}, 0, nil
+	}
+}
+
+func (m *mockBlockingTopicsStorage) GetTopicsUpdatedSince(ctx context.Context, since, until time.Time, limit, offset int) ([]models.Topic, int, error) {
+	return m.GetMetaOfTopics(ctx, models.MongoQuery{}, limit, offset, "updatedAt,_id", nil)
+}
+
+func TestGetTopicsCanceledRequest(t *testing.T) {
+	s := &mockBlockingTopicsStorage{started: make(chan struct{}), canceled: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.started
+		// the client disconnects
+		cancel()
+	}()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/topics", nil).WithContext(ctx)
+
+	start := time.Now()
+	NewNewsController(s).GetTopics(c)
+
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("expect the storage call aborted before it finishes, but got %v", elapsed)
+	}
+	select {
+	case <-s.canceled:
+	default:
+		t.Errorf("expect the storage call receiving the canceled request context")
+	}
+}
+
+func TestGetTopicsUpdatedSinceCanceledRequest(t *testing.T) {
+	s := &mockBlockingTopicsStorage{started: make(chan struct{}), canceled: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.started
+		cancel()
+	}()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/topics?since=2020-01-01T00:00:00Z", nil).WithContext(ctx)
+
+	NewNewsController(s).GetTopics(c)
+
+	select {
+	case <-s.canceled:
+	default:
+		t.Errorf("expect the storage call receiving the canceled request context")
+	}
+}
+
+type mockTrendingTopicsStorage struct {
+	storage.NewsStorage
+	views []models.TopicViews
+	calls [][]string
+}
+
+func (m *mockTrendingTopicsStorage) GetTopicViewsSince(since time.Time, limit int) ([]models.TopicViews, error) {
+	return m.views, nil
+}
+
+func (m *mockTrendingTopicsStorage) GetMetaOfTopicsBySlugs(slugs []string) ([]models.Topic, error) {
+	m.calls = append(m.calls, slugs)
+	// the topics are not in the order of the slugs
+	return []models.Topic{{Slug: "topic-a"}, {Slug: "topic-b"}}, nil
+}
+
+func TestGetTrendingTopics(t *testing.T) {
+	defaultNews := globals.Conf.News
+	globals.Conf.News.TrendingTopicsWindow = 24 * time.Hour
+	globals.Conf.News.TrendingTopicsMaxWindow = 168 * time.Hour
+	defer func() { globals.Conf.News = defaultNews }()
+
+	s := &mockTrendingTopicsStorage{views: []models.TopicViews{
+		{Slug: "topic-b", Views: 10},
+		// the topic is unpublished after it was viewed
+		{Slug: "removed-topic", Views: 5},
+		{Slug: "topic-a", Views: 1},
+	}}
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/topics/trending", nil)
+
+	code, body, _ := NewNewsController(s).GetTrendingTopics(c)
+	if code != http.StatusOK {
+		t.Fatalf("expect status %d, but got %d", http.StatusOK, code)
+	}
+
+	if len(s.calls) != 1 {
+		t.Fatalf("expect topics fetched at once, but got %d calls", len(s.calls))
+	}
+
+	topics := body["records"].([]models.Topic)
+	if got := helperGetTopicSlugs(topics); !reflect.DeepEqual(got, []string{"topic-b", "topic-a"}) {
+		t.Errorf("expect topics ranked by views, but got %v", got)
+	}
+}
+
+func helperGetTopicSlugs(topics []models.Topic) []string {
+	slugs := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		slugs = append(slugs, topic.Slug)
+	}
+	return slugs
+}
+
+type mockTopicsUpdatedStorage struct {
+	storage.NewsStorage
+	since, until time.Time
+}
+
+func (m *mockTopicsUpdatedStorage) GetTopicsUpdatedSince(ctx context.Context, since, until time.Time, limit, offset int) ([]models.Topic, int, error) {
+	m.since, m.until = since, until
+	return []models.Topic{{Slug: "topic-a", Title: "topic a"}}, 1, nil
+}
+
+func TestGetTopicsUpdatedUntil(t *testing.T) {
+	until := time.Date(2020, time.June, 8, 16, 0, 0, 0, time.UTC)
+	s := &mockTopicsUpdatedStorage{}
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/topics?until=2020-06-08T16:00:00Z&fields=slug", nil)
+
+	code, body, _ := NewNewsController(s).GetTopics(c)
+	if code != http.StatusOK {
+		t.Fatalf("expect status %d, but got %d", http.StatusOK, code)
+	}
+	if !s.since.IsZero() || !s.until.Equal(until) {
+		t.Errorf("expect the topics updated until %v, but got since %v and until %v", until, s.since, s.until)
+	}
+
+	b, _ := json.Marshal(body["records"])
+	if want := `[{"slug":"topic-a"}]`; string(b) != want {
+		t.Errorf("expect records %s, but got %s", want, b)
+	}
+}
+
+type mockFullTopicsStorage struct {
+	storage.NewsStorage
+	expanded []string
+}
+
+func (m *mockFullTopicsStorage) GetFullTopics(ctx context.Context, mq models.MongoQuery, limit, offset int, sort string, embedded []string) ([]models.Topic, int, error) {
+	m.expanded = mq.ExpandFields
+	return []models.Topic{{Slug: "topic-a"}}, 1, nil
+}
+
+func TestGetTopicsExpand(t *testing.T) {
+	cases := []struct {
+		name         string
+		query        string
+		wantCode     int
+		wantExpanded []string
+	}{
+		{name: "Given the full topics", query: "full=true&expand=authors", wantCode: http.StatusOK, wantExpanded: []string{models.ExpandAuthors}},
+		{name: "Given the topics not full", query: "expand=authors", wantCode: http.StatusBadRequest},
+		{name: "Given the topics updated since", query: "since=2020-01-01T00:00:00Z&full=true&expand=authors", wantCode: http.StatusBadRequest},
+		{name: "Given an unknown field", query: "full=true&expand=tags", wantCode: http.StatusBadRequest},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockFullTopicsStorage{}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/topics?"+tc.query, nil)
+
+			code, body, _ := NewNewsController(s).GetTopics(c)
+			if code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d, %v", tc.wantCode, code, body)
+			}
+			if !reflect.DeepEqual(s.expanded, tc.wantExpanded) {
+				t.Errorf("expect %v expanded, but got %v", tc.wantExpanded, s.expanded)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+)
+
+// maxBatchUsers is the maximum number of users looked up in a request
+const maxBatchUsers = 100
+
+type batchUsersReqBody struct {
+	IDs []uint `json:"ids" binding:"required"`
+}
+
+// publicUser is the user without the sensitive fields, which is rendered in author bylines
+type publicUser struct {
+	ID        uint   `json:"id"`
+	FirstName string `json:"firstname"`
+	LastName  string `json:"lastname"`
+}
+
+// GetUsersByIDs resolves the users of the IDs for internal services.
+// The IDs not found are absent from the `users` map.
+func (mc *MembershipController) GetUsersByIDs(c *gin.Context) (int, gin.H, error) {
+	var err error
+	var reqBody batchUsersReqBody
+	var users map[uint]models.User
+
+	if failData, valid := bindRequestJSONBody(c, &reqBody); !valid {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	if len(reqBody.IDs) > maxBatchUsers {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"ids": fmt.Sprintf("should contain at most %d ids", maxBatchUsers),
+		}}, nil
+	}
+
+	if users, err = mc.Storage.GetUsersByIDs(reqBody.IDs); err != nil {
+		return toResponse(err)
+	}
+
+	var records = make(map[uint]publicUser, len(users))
+	for id, user := range users {
+		records[id] = publicUser{
+			ID:        user.ID,
+			FirstName: user.FirstName.ValueOrZero(),
+			LastName:  user.LastName.ValueOrZero(),
+		}
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{"users": records}}, nil
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+	"twreporter.org/go-api/utils"
+)
+
+// maxBlockReasonLength is the maximum length of the reason blocking the user
+const maxBlockReasonLength = 512
+
+// userListParams binds the list params of the users, which are ordered by `orderBy` rather than `sort`
+var userListParams = ListParamsBinder{
+	DefaultLimit: 20,
+	MaxLimit:     100,
+}
+
+// NewUserAdminController returns a UserAdminController with the user storage
+func NewUserAdminController(s storage.UserStorage) *UserAdminController {
+	return &UserAdminController{Storage: s}
+}
+
+// UserAdminController manages the users by the admins
+type UserAdminController struct {
+	Storage storage.UserStorage
+}
+
+// adminUserRecord is the user listed to the admins,
+// which leaves out the accounts of the user, e.g. the activate token of the reporter account
+type adminUserRecord struct {
+	ID               uint        `json:"id"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	Email            null.String `json:"email"`
+	FirstName        null.String `json:"firstname"`
+	LastName         null.String `json:"lastname"`
+	Privilege        int         `json:"privilege"`
+	RegistrationDate null.Time   `json:"registration_date"`
+	EnableEmail      int         `json:"enable_email"`
+	Blocked          bool        `json:"blocked"`
+	BlockReason      null.String `json:"block_reason"`
+}
+
+func newAdminUserRecord(user models.User) adminUserRecord {
+	return adminUserRecord{
+		ID:               user.ID,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Privilege:        user.Privilege,
+		RegistrationDate: user.RegistrationDate,
+		EnableEmail:      user.EnableEmail,
+		Blocked:          user.Blocked,
+		BlockReason:      user.BlockReason,
+	}
+}
+
+// ListUsers lists the users whose email starts with `email`, ordered by `orderBy`(`-createdAt` by default)
+func (uac *UserAdminController) ListUsers(c *gin.Context) (int, gin.H, error) {
+	params, err := userListParams.BindListParams(c)
+	if err != nil {
+		return listParamsFailResponse(err)
+	}
+
+	filter := storage.UserFilter{EmailPrefix: c.Query("email"), OrderBy: c.Query("orderBy")}
+	if _, ok := storage.UserOrderColumns[strings.TrimPrefix(filter.OrderBy, "-")]; filter.OrderBy != "" && !ok {
+		var columns []string
+		for column := range storage.UserOrderColumns {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"orderBy": "should be one of " + strings.Join(columns, ", ") + ", optionally prefixed with -",
+		}}, nil
+	}
+
+	users, total, err := uac.Storage.ListUsers(filter, params.Limit, params.Offset)
+	if err != nil {
+		return toResponse(err)
+	}
+
+	records := make([]adminUserRecord, 0, len(users))
+	for _, user := range users {
+		records = append(records, newAdminUserRecord(user))
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"records": records,
+		"meta":    newMetaOfResponse(total, params.Offset, params.Limit),
+	}}, nil
+}
+
+type userBlockReqBody struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// BlockUser blocks the user violating the community guidelines, whose tokens are rejected afterwards,
+// and records the reason along with the admin in the audit log.
+func (uac *UserAdminController) BlockUser(c *gin.Context) (int, gin.H, error) {
+	var reqBody userBlockReqBody
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil || strings.TrimSpace(reqBody.Reason) == "" {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"reason": "should be a non-empty string explaining why the user is blocked",
+		}}, nil
+	}
+	if len([]rune(reqBody.Reason)) > maxBlockReasonLength {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"reason": fmt.Sprintf("should be at most %d characters", maxBlockReasonLength),
+		}}, nil
+	}
+
+	userID := c.Param("userID")
+	adminID := fmt.Sprint(c.Request.Context().Value(globals.AuthUserIDProperty))
+	if userID == adminID {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"userID": "should not be the admin self",
+		}}, nil
+	}
+
+	if err := uac.Storage.BlockUser(userID, reqBody.Reason); err != nil {
+		return userBlockErrorResponse(userID, err)
+	}
+	// the path param is normalized since the user is marked by the id in the token
+	if id, err := strconv.ParseUint(userID, 10, 64); err == nil {
+		utils.MarkUserBlocked(strconv.FormatUint(id, 10))
+	}
+
+	uac.logUserBlock(c, userID, models.UserBlockActionBlock, null.StringFrom(reqBody.Reason))
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"user_id": userID,
+		"blocked": true,
+		"reason":  reqBody.Reason,
+	}}, nil
+}
+
+// UnblockUser unblocks the user and records the admin in the audit log
+func (uac *UserAdminController) UnblockUser(c *gin.Context) (int, gin.H, error) {
+	userID := c.Param("userID")
+
+	if err := uac.Storage.UnblockUser(userID); err != nil {
+		return userBlockErrorResponse(userID, err)
+	}
+
+	uac.logUserBlock(c, userID, models.UserBlockActionUnblock, null.String{})
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"user_id": userID,
+		"blocked": false,
+	}}, nil
+}
+
+func userBlockErrorResponse(userID string, err error) (int, gin.H, error) {
+	if storage.IsNotFound(err) {
+		return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{
+			"userID": fmt.Sprintf("cannot find the user(id: %s)", userID),
+		}}, nil
+	}
+	return toResponse(err)
+}
+
+// logUserBlock records the action of the admin in the audit log,
+// and the failure is only logged since the user is blocked or unblocked already.
+func (uac *UserAdminController) logUserBlock(c *gin.Context, userID string, action string, reason null.String) {
+	id, _ := strconv.ParseUint(userID, 10, 64)
+	adminID, _ := strconv.ParseUint(fmt.Sprint(c.Request.Context().Value(globals.AuthUserIDProperty)), 10, 64)
+
+	blockLog := models.UserBlockLog{
+		UserID:  uint(id),
+		Action:  action,
+		Reason:  reason,
+		AdminID: uint(adminID),
+	}
+	if err := uac.Storage.CreateUserBlockLog(blockLog); err != nil {
+		logError(errors.WithMessage(err, fmt.Sprintf("fail to record %s of user(id: %s)", action, userID)))
+	}
+
+	log.WithFields(log.Fields{
+		"action":   action,
+		"user_id":  userID,
+		"admin_id": adminID,
+	}).Info("moderate user")
+}
@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/models"
+)
+
+// exportBookmarksPageSize is the number of bookmarks read from storage at a time while exporting
+const exportBookmarksPageSize = 100
+
+// exportedProfile is the profile of the user in the export,
+// the associations are exported in their own sections.
+type exportedProfile struct {
+	ID               uint        `json:"id"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	Email            null.String `json:"email"`
+	FirstName        null.String `json:"firstname"`
+	LastName         null.String `json:"lastname"`
+	SecurityID       null.String `json:"security_id"`
+	PassportID       null.String `json:"passport_id"`
+	City             null.String `json:"city"`
+	State            null.String `json:"state"`
+	Country          null.String `json:"country"`
+	Zip              null.String `json:"zip"`
+	Address          null.String `json:"address"`
+	Phone            null.String `json:"phone"`
+	RegistrationDate null.Time   `json:"registration_date"`
+	Birthday         null.Time   `json:"birthday"`
+	Gender           null.String `json:"gender"`
+	Education        null.String `json:"education"`
+	EnableEmail      int         `json:"enable_email"`
+}
+
+// exportedOAuthAccount is the OAuth account in the export,
+// the user id returned by OAuth services is excluded.
+// LastProfileRefreshAt is the last time the profile is refreshed from the OAuth service,
+// which is usually, but not necessarily, a sign-in, since the sign-ins are not logged.
+type exportedOAuthAccount struct {
+	Type        string                `json:"type"`
+	Email       null.String           `json:"email"`
+	Name        null.String           `json:"name"`
+	FirstName   null.String           `json:"firstname"`
+	LastName    null.String           `json:"lastname"`
+	DisplayName string                `json:"display_name"`
+	Gender      null.String           `json:"gender"`
+	Picture     null.String           `json:"picture"`
+	Pictures    models.AvatarPictures `json:"pictures"`
+	Birthday    null.String           `json:"birthday"`
+	LinkedAt    time.Time             `json:"linked_at"`
+
+	LastProfileRefreshAt time.Time `json:"last_profile_refresh_at"`
+}
+
+// exportedEmailAccount is the account signing in by email in the export, the activation token is excluded.
+type exportedEmailAccount struct {
+	Email     string    `json:"email"`
+	LinkedAt  time.Time `json:"linked_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// exportedSubscription is the web push subscription in the export, the keys are excluded.
+type exportedSubscription struct {
+	Endpoint       string     `json:"endpoint"`
+	ExpirationTime *time.Time `json:"expiration_time"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// exportedDeviceToken is the mobile app receiving the push notifications in the export,
+// the token is excluded since it addresses the device.
+type exportedDeviceToken struct {
+	Platform  string    `json:"platform"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// exportedCategorySubscription is the category whose published posts are pushed to the user in the export
+type exportedCategorySubscription struct {
+	CategoryID string    `json:"category_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// exportedReadingHistory is the post read by the user in the export
+type exportedReadingHistory struct {
+	Slug   string    `json:"slug"`
+	ReadAt time.Time `json:"read_at"`
+}
+
+// exportedPlanSubscription is the subscription of the plan, e.g. the paid membership, in the export
+type exportedPlanSubscription struct {
+	Plan      string    `json:"plan"`
+	Status    string    `json:"status"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   null.Time `json:"end_date"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportUserData streams the data of the user as a single JSON document,
+// which contains the profile, linked OAuth and email accounts, bookmarks, subscriptions, device tokens, category subscriptions,
+// plan subscriptions and reading history.
+// The bookmarks are encoded page by page, so the whole export is never held in memory.
+func (mc *MembershipController) ExportUserData(c *gin.Context) {
+	var err error
+	var user models.User
+	var accounts []models.OAuthAccount
+	var reporterAccounts []models.ReporterAccount
+	var wpSubs []models.WebPushSubscription
+	var deviceTokens []models.DeviceToken
+	var categorySubs []models.CategorySubscription
+	var histories []models.ReadingHistory
+	var planSubs []models.Subscription
+
+	userID := c.Param("userID")
+
+	if user, err = mc.Storage.GetUserByID(userID); err != nil {
+		code, body, _ := toResponse(err)
+		c.JSON(code, body)
+		return
+	}
+
+	if accounts, err = mc.Storage.GetOAuthAccountsOfAUser(userID); err != nil {
+		code, body, _ := toResponse(err)
+		c.JSON(code, body)
+		return
+	}
+
+	if err = mc.Storage.GetByConditions(map[string]interface{}{"user_id": user.ID}, &reporterAccounts); err != nil {
+		code, body, _ := toResponse(err)
+		c.JSON(code, body)
+		return
+	}
+
+	if wpSubs, err = mc.Storage.GetWebPushSubscriptionsOfAUser(userID); err != nil {
+		code, body, _ := toResponse(err)
+		c.JSON(code, body)
+		return
+	}
+
+	if err = mc.Storage.GetByConditions(map[string]interface{}{"user_id": user.ID}, &deviceTokens); err != nil {
+		code, body, _ := toResponse(err)
+		c.JSON(code, body)
+		return
+	}
+
+	if err = mc.Storage.GetByConditions(map[string]interface{}{"user_id": user.ID}, &categorySubs); err != nil {
+		code, body, _ := toResponse(err)
+		c.JSON(code, body)
+		return
+	}
+
+	if err = mc.Storage.GetByConditions(map[string]interface{}{"user_id": user.ID}, &histories); err != nil {
+		code, body, _ := toResponse(err)
+		c.JSON(code, body)
+		return
+	}
+
+	if err = mc.Storage.GetByConditions(map[string]interface{}{"user_id": user.ID}, &planSubs); err != nil {
+		code, body, _ := toResponse(err)
+		c.JSON(code, body)
+		return
+	}
+
+	var oauthAccounts = make([]exportedOAuthAccount, 0, len(accounts))
+	for _, account := range accounts {
+		oauthAccounts = append(oauthAccounts, exportedOAuthAccount{
+			Type:        account.Type,
+			Email:       account.Email,
+			Name:        account.Name,
+			FirstName:   account.FirstName,
+			LastName:    account.LastName,
+			DisplayName: account.DisplayName(),
+			Gender:      account.Gender,
+			Picture:     account.Picture,
+			Pictures:    account.Pictures,
+			Birthday:    account.Birthday,
+			LinkedAt:    account.CreatedAt,
+			// the oauth data is updated when the profile is fetched on sign-in
+			LastProfileRefreshAt: account.UpdatedAt,
+		})
+	}
+
+	var emailAccounts = make([]exportedEmailAccount, 0, len(reporterAccounts))
+	for _, account := range reporterAccounts {
+		emailAccounts = append(emailAccounts, exportedEmailAccount{
+			Email:     account.Email,
+			LinkedAt:  account.CreatedAt,
+			UpdatedAt: account.UpdatedAt,
+		})
+	}
+
+	var subscriptions = make([]exportedSubscription, 0, len(wpSubs))
+	for _, wpSub := range wpSubs {
+		subscriptions = append(subscriptions, exportedSubscription{
+			Endpoint:       wpSub.Endpoint,
+			ExpirationTime: wpSub.ExpirationTime,
+			CreatedAt:      wpSub.CreatedAt,
+		})
+	}
+
+	var devices = make([]exportedDeviceToken, 0, len(deviceTokens))
+	for _, token := range deviceTokens {
+		devices = append(devices, exportedDeviceToken{
+			Platform:  token.Platform,
+			CreatedAt: token.CreatedAt,
+			UpdatedAt: token.UpdatedAt,
+		})
+	}
+
+	var categorySubscriptions = make([]exportedCategorySubscription, 0, len(categorySubs))
+	for _, sub := range categorySubs {
+		categorySubscriptions = append(categorySubscriptions, exportedCategorySubscription{
+			CategoryID: sub.CategoryID,
+			CreatedAt:  sub.CreatedAt,
+		})
+	}
+
+	var planSubscriptions = make([]exportedPlanSubscription, 0, len(planSubs))
+	for _, sub := range planSubs {
+		planSubscriptions = append(planSubscriptions, exportedPlanSubscription{
+			Plan:      sub.Plan,
+			Status:    sub.Status,
+			StartDate: sub.StartDate,
+			EndDate:   sub.EndDate,
+			CreatedAt: sub.CreatedAt,
+		})
+	}
+
+	var readingHistory = make([]exportedReadingHistory, 0, len(histories))
+	for _, history := range histories {
+		readingHistory = append(readingHistory, exportedReadingHistory{
+			Slug:   history.Slug,
+			ReadAt: history.ReadAt,
+		})
+	}
+
+	c.Header("Content-Type", "application/json")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"user-%s-export-%s.json\"", userID, time.Now().Format("2006-01-02")))
+	c.Status(http.StatusOK)
+
+	if err = writeUserData(c.Writer, []exportSection{
+		{"profile", toExportedProfile(user)},
+		{"oauth_accounts", oauthAccounts},
+		{"email_accounts", emailAccounts},
+		{"bookmarks", bookmarkIterator(func(fn func(models.Bookmark) error) error {
+			return mc.iterateBookmarksOfAUser(userID, fn)
+		})},
+		{"subscriptions", subscriptions},
+		{"device_tokens", devices},
+		{"category_subscriptions", categorySubscriptions},
+		{"plan_subscriptions", planSubscriptions},
+		{"reading_history", readingHistory},
+	}); err != nil {
+		// the response is sent partially, the error can only be logged
+		logError(errors.WithMessage(err, fmt.Sprintf("fail to export data of user(id: %s)", userID)))
+	}
+}
+
+// bookmarkIterator calls the function with the bookmarks one by one
+type bookmarkIterator func(func(models.Bookmark) error) error
+
+// exportSection is a field of the exported document.
+// If the value is a bookmarkIterator, the bookmarks are encoded as an array while iterating.
+type exportSection struct {
+	name  string
+	value interface{}
+}
+
+// writeUserData encodes the sections as a JSON object to w
+func writeUserData(w io.Writer, sections []exportSection) error {
+	encoder := json.NewEncoder(w)
+
+	for i, section := range sections {
+		prefix := ","
+		if i == 0 {
+			prefix = "{"
+		}
+		if _, err := io.WriteString(w, fmt.Sprintf("%s%q:", prefix, section.name)); err != nil {
+			return errors.WithStack(err)
+		}
+
+		iterate, ok := section.value.(bookmarkIterator)
+		if !ok {
+			if err := encoder.Encode(section.value); err != nil {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+
+		if _, err := io.WriteString(w, "["); err != nil {
+			return errors.WithStack(err)
+		}
+		first := true
+		if err := iterate(func(bookmark models.Bookmark) error {
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			first = false
+			return errors.WithStack(encoder.Encode(bookmark))
+		}); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "]"); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	_, err := io.WriteString(w, "}")
+	return errors.WithStack(err)
+}
+
+// iterateBookmarksOfAUser calls fn with the bookmarks of the user page by page
+func (mc *MembershipController) iterateBookmarksOfAUser(userID string, fn func(models.Bookmark) error) error {
+	for offset := 0; ; offset += exportBookmarksPageSize {
+		bookmarks, _, err := mc.Storage.GetBookmarksOfAUser(userID, exportBookmarksPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, bookmark := range bookmarks {
+			if err = fn(bookmark); err != nil {
+				return err
+			}
+		}
+
+		if len(bookmarks) < exportBookmarksPageSize {
+			return nil
+		}
+	}
+}
+
+func toExportedProfile(user models.User) exportedProfile {
+	return exportedProfile{
+		ID:               user.ID,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		SecurityID:       user.SecurityID,
+		PassportID:       user.PassportID,
+		City:             user.City,
+		State:            user.State,
+		Country:          user.Country,
+		Zip:              user.Zip,
+		Address:          user.Address,
+		Phone:            user.Phone,
+		RegistrationDate: user.RegistrationDate,
+		Birthday:         user.Birthday,
+		Gender:           user.Gender,
+		Education:        user.Education,
+		EnableEmail:      user.EnableEmail,
+	}
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"twreporter.org/go-api/models"
+)
+
+func TestWriteUserData(t *testing.T) {
+	var buf bytes.Buffer
+	var exported struct {
+		Profile   exportedProfile   `json:"profile"`
+		Bookmarks []models.Bookmark `json:"bookmarks"`
+		Empty     []models.Bookmark `json:"empty"`
+	}
+
+	bookmarks := []models.Bookmark{{Slug: "bookmark-1"}, {Slug: "bookmark-2"}}
+
+	if err := writeUserData(&buf, []exportSection{
+		{"profile", exportedProfile{ID: 1}},
+		{"bookmarks", bookmarkIterator(func(fn func(models.Bookmark) error) error {
+			for _, bookmark := range bookmarks {
+				if err := fn(bookmark); err != nil {
+					return err
+				}
+			}
+			return nil
+		})},
+		{"empty", bookmarkIterator(func(fn func(models.Bookmark) error) error {
+			return nil
+		})},
+	}); err != nil {
+		t.Fatalf("expect no error, but got %v", err)
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatalf("expect a valid JSON document, but got %s: %v", buf.String(), err)
+	}
+	if exported.Profile.ID != 1 {
+		t.Errorf("expect profile id 1, but got %d", exported.Profile.ID)
+	}
+	if len(exported.Bookmarks) != 2 || exported.Bookmarks[1].Slug != "bookmark-2" {
+		t.Errorf("expect 2 bookmarks, but got %v", exported.Bookmarks)
+	}
+	if exported.Empty == nil || len(exported.Empty) != 0 {
+		t.Errorf("expect an empty array, but got %v", exported.Empty)
+	}
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/storage"
+)
+
+type userMergeReqBody struct {
+	TargetUserID uint `json:"target_user_id" binding:"required"`
+}
+
+// MergeUsers merges the user of the path into the target user of the body, and then deletes the former.
+// Every merge is logged with the admin requesting it.
+func (mc *MembershipController) MergeUsers(c *gin.Context) (int, gin.H, error) {
+	var reqBody userMergeReqBody
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"target_user_id": fmt.Sprintf("should be the id of the user merged into. %s", err.Error()),
+		}}, nil
+	}
+
+	sourceID := c.Param("userID")
+	targetID := fmt.Sprint(reqBody.TargetUserID)
+
+	result, err := mc.Storage.MergeUsers(sourceID, targetID)
+	if err != nil {
+		if errors.Cause(err) == storage.ErrMergeSameUser {
+			return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+				"target_user_id": "should be different from the merged user",
+			}}, nil
+		}
+		return toResponse(err)
+	}
+
+	log.WithFields(log.Fields{
+		"source_user_id": sourceID,
+		"target_user_id": targetID,
+		"user_id":        c.Request.Context().Value(globals.AuthUserIDProperty),
+	}).Info("merge users")
+
+	return http.StatusOK, gin.H{"status": "success", "data": result}, nil
+}
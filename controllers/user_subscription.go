@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+// maxSubscriptionPlanLength is the maximum length of the plan name
+const maxSubscriptionPlanLength = 20
+
+// NewSubscriptionController returns a SubscriptionController with the subscription storage
+func NewSubscriptionController(s storage.SubscriptionStorage) *SubscriptionController {
+	return &SubscriptionController{Storage: s}
+}
+
+// SubscriptionController manages the paid subscriptions of the users
+type SubscriptionController struct {
+	Storage storage.SubscriptionStorage
+}
+
+type subscriptionReqBody struct {
+	UserID    uint       `json:"user_id" binding:"required"`
+	Plan      string     `json:"plan" binding:"required"`
+	StartDate *time.Time `json:"start_date"`
+	EndDate   null.Time  `json:"end_date"`
+}
+
+func validateSubscriptionPlan(plan string) gin.H {
+	if plan == "" || len(plan) > maxSubscriptionPlanLength {
+		return gin.H{"plan": fmt.Sprintf("should be a non-empty string, at most %d characters", maxSubscriptionPlanLength)}
+	}
+	return nil
+}
+
+func subscriptionNotFoundResponse(param, message string) (int, gin.H, error) {
+	return http.StatusNotFound, gin.H{"status": "fail", "data": gin.H{param: message}}, nil
+}
+
+// CreateSubscription creates the active subscription of the user, which starts now if `start_date` is not given,
+// and never expires if `end_date` is not given.
+func (sc *SubscriptionController) CreateSubscription(c *gin.Context) (int, gin.H, error) {
+	var reqBody subscriptionReqBody
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": fmt.Sprintf("should be {\"user_id\": 1, \"plan\": \"...\"}. %s", err.Error()),
+		}}, nil
+	}
+
+	if failData := validateSubscriptionPlan(reqBody.Plan); failData != nil {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+	}
+
+	sub := models.Subscription{
+		UserID:    reqBody.UserID,
+		Plan:      reqBody.Plan,
+		StartDate: time.Now(),
+		EndDate:   reqBody.EndDate,
+		Status:    models.SubscriptionStatusActive,
+	}
+	if reqBody.StartDate != nil {
+		sub.StartDate = *reqBody.StartDate
+	}
+
+	if sub.EndDate.Valid && !sub.EndDate.Time.After(sub.StartDate) {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"end_date": "should be after start_date"}}, nil
+	}
+
+	sub, err := sc.Storage.CreateSubscription(sub)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return subscriptionNotFoundResponse("user_id", fmt.Sprintf("cannot find the user(id: %d)", reqBody.UserID))
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusCreated, gin.H{"status": "success", "data": sub}, nil
+}
+
+// GetSubscriptionOfAUser returns the latest subscription of the user, along with whether it is active now
+func (sc *SubscriptionController) GetSubscriptionOfAUser(c *gin.Context) (int, gin.H, error) {
+	userID := c.Param("userID")
+
+	sub, err := sc.Storage.GetSubscriptionOfAUser(userID)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return subscriptionNotFoundResponse("userID", fmt.Sprintf("cannot find the subscription of the user(id: %s)", userID))
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"subscription": sub,
+		"active":       sub.IsActiveAt(time.Now()),
+	}}, nil
+}
+
+// UpdateSubscription updates `plan` or `end_date` of the subscription,
+// and the subscription never expires if `end_date` is null.
+func (sc *SubscriptionController) UpdateSubscription(c *gin.Context) (int, gin.H, error) {
+	var reqBody map[string]json.RawMessage
+	var columns = make(map[string]interface{})
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil || len(reqBody) == 0 {
+		return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+			"req.Body": "should be {\"plan\": \"...\"} or {\"end_date\": \"2020-06-08T16:00:00Z\"}",
+		}}, nil
+	}
+
+	for key, value := range reqBody {
+		switch key {
+		case "plan":
+			var plan string
+			json.Unmarshal(value, &plan)
+			if failData := validateSubscriptionPlan(plan); failData != nil {
+				return http.StatusBadRequest, gin.H{"status": "fail", "data": failData}, nil
+			}
+			columns["plan"] = plan
+		case "end_date":
+			var endDate null.Time
+			if err := json.Unmarshal(value, &endDate); err != nil {
+				return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+					"end_date": "should be a RFC 3339 timestamp or null",
+				}}, nil
+			}
+			columns["end_date"] = endDate
+		default:
+			return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{
+				key: "should be plan or end_date",
+			}}, nil
+		}
+	}
+
+	sub, err := sc.Storage.UpdateSubscription(c.Param("id"), columns)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return subscriptionNotFoundResponse("id", fmt.Sprintf("cannot find the subscription(id: %s)", c.Param("id")))
+		}
+		if errors.Cause(err) == storage.ErrInvalidDateRange {
+			return http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{"end_date": "should be after start_date"}}, nil
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": sub}, nil
+}
+
+// CancelSubscription cancels the subscription, which ends right away
+func (sc *SubscriptionController) CancelSubscription(c *gin.Context) (int, gin.H, error) {
+	sub, err := sc.Storage.CancelSubscription(c.Param("id"))
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return subscriptionNotFoundResponse("id", fmt.Sprintf("cannot find the subscription(id: %s)", c.Param("id")))
+		}
+		return toResponse(err)
+	}
+
+	return http.StatusOK, gin.H{"status": "success", "data": sub}, nil
+}
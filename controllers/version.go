@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+)
+
+// VersionController serves the build metadata of the deployed go-api
+type VersionController struct{}
+
+// Retrieve responds the version, git commit and build time injected on build, along with the Go runtime version
+func (vc VersionController) Retrieve(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"version":    globals.Version,
+		"git_commit": globals.GitCommit,
+		"build_time": globals.BuildTime,
+		"go_version": runtime.Version(),
+	})
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+)
+
+func TestVersionRetrieve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	defaultVersion, defaultGitCommit, defaultBuildTime := globals.Version, globals.GitCommit, globals.BuildTime
+	globals.Version, globals.GitCommit, globals.BuildTime = "v1.2.3", "0123456789abcdef", "2020-01-01T00:00:00Z"
+	defer func() {
+		globals.Version, globals.GitCommit, globals.BuildTime = defaultVersion, defaultGitCommit, defaultBuildTime
+	}()
+
+	engine := gin.New()
+	engine.GET("/version", VersionController{}.Retrieve)
+
+	resp := httptest.NewRecorder()
+	engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expect status %d, but got %d", http.StatusOK, resp.Code)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expect JSON body, but got %s", resp.Body.String())
+	}
+
+	for key, want := range map[string]string{
+		"version":    "v1.2.3",
+		"git_commit": "0123456789abcdef",
+		"build_time": "2020-01-01T00:00:00Z",
+		"go_version": runtime.Version(),
+	} {
+		if body[key] != want {
+			t.Errorf("expect %s to be %s, but got %s", key, want, body[key])
+		}
+	}
+}
@@ -22,6 +22,8 @@ const (
 	// route path
 	SendActivationRoutePath      = "mail/send_activation"
 	SendSuccessDonationRoutePath = "mail/send_success_donation"
+	SendPostStateChangeRoutePath = "mail/send_post_state_change"
+	SendContentReportRoutePath   = "mail/send_content_report"
 
 	// controller name
 	MembershipController = "membership_controller"
@@ -68,6 +70,8 @@ const (
 	// oauth type
 	GoogleOAuth   = "Google"
 	FacebookOAuth = "Facebook"
+	LinkedInOAuth = "LinkedIn"
+	AppleOAuth    = "Apple"
 
 	// donation
 	PeriodicDonationType = "periodic_donation"
@@ -78,6 +82,11 @@ const (
 	// jwt prefix
 	MailServiceJWTPrefix = "mail-service-jwt-"
 
+	// signed url secret prefix
+	SignedURLSecretPrefix = "signed-url-"
+
 	// custom context key
 	AuthUserIDProperty = "auth-user-id"
+	// ClientCountryProperty is the ISO country code of the client IP, e.g. TW, set in gin context
+	ClientCountryProperty = "client-country"
 )
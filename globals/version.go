@@ -0,0 +1,9 @@
+package globals
+
+// The build metadata injected by -ldflags, e.g.
+// go build -ldflags "-X twreporter.org/go-api/globals.Version=v6.0.0 -X twreporter.org/go-api/globals.GitCommit=$(git rev-parse HEAD) -X twreporter.org/go-api/globals.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	GitCommit = ""
+	BuildTime = ""
+)
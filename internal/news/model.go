@@ -55,6 +55,8 @@ type MetaOfPost struct {
 	IsExternal           bool               `bson:"is_external" json:"is_external"`
 	Tags                 []Tag              `bson:"tags" json:"tags,omitempty"`
 	Full                 bool               `bson:"-" json:"full"`
+	// RestrictedCountries are the ISO country codes, e.g. TW, where the post is unavailable for legal reasons
+	RestrictedCountries []string `bson:"restrictedCountries,omitempty" json:"restricted_countries,omitempty"`
 }
 
 type Post struct {
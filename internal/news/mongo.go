@@ -199,6 +199,7 @@ const (
 	ColTags           = "tags"
 	ColPosts          = "posts"
 	ColTopics         = "topics"
+	ColPostVersions   = "postVersions"
 
 	// TODO: rename fields to writer
 	fieldWriters              = "writters"
@@ -0,0 +1,188 @@
+// Package recommendation computes the posts recommended for the members by their reading history
+package recommendation
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+const (
+	// RefreshInterval is how often the recommended posts of the members are recomputed
+	RefreshInterval = 6 * time.Hour
+	// electionInterval is how often each instance tries to be elected to refresh,
+	// the instance elected holds the lock for the RefreshInterval, so the others skip until it expires
+	electionInterval = 10 * time.Minute
+	// readersWindow only the members reading within the window are recomputed,
+	// the recommended posts of the others expire and fall back to the popular posts
+	readersWindow = 30 * 24 * time.Hour
+	// maxReadSlugs is the number of the latest read posts excluded from the recommendation
+	maxReadSlugs = 200
+	// maxTaggedSlugs is the number of the latest read posts whose tags profile the interests of the member
+	maxTaggedSlugs = 50
+	// maxCandidates is the number of the latest posts sharing the tags to be scored
+	maxCandidates = 500
+	// maxRecommended is the number of the recommended posts cached for each member
+	maxRecommended = 50
+)
+
+type readingHistoryReader interface {
+	GetReadersSince(time.Time) ([]uint, error)
+	GetRecentlyReadSlugs(uint, int) ([]string, error)
+}
+
+type candidateReader interface {
+	GetTagsOfPostsBySlugs([]string) ([]models.Post, error)
+	GetRecommendationCandidates([]bson.ObjectId, []string, int) ([]models.Post, error)
+}
+
+type recommendationWriter interface {
+	SetRecommendedPostIDs(uint, []string, time.Duration) error
+	AcquireRefreshLock(time.Duration) (bool, error)
+}
+
+// Job recomputes the recommended posts of the recent readers periodically
+type Job struct {
+	History  readingHistoryReader
+	Posts    candidateReader
+	Cache    recommendationWriter
+	interval time.Duration
+	election time.Duration
+	now      func() time.Time
+}
+
+// NewJob returns a Job refreshing the recommended posts every RefreshInterval
+func NewJob(h readingHistoryReader, p candidateReader, c recommendationWriter) *Job {
+	return &Job{History: h, Posts: p, Cache: c, interval: RefreshInterval, election: electionInterval, now: time.Now}
+}
+
+// Run tries to refresh the recommended posts at once and then every election interval until ctx is done.
+// Only the instance acquiring the lock refreshes, and the lock is held for the whole interval,
+// so the posts are recomputed once every interval across the instances, and never on each boot.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.election)
+	defer ticker.Stop()
+
+	for {
+		j.refreshIfElected()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshIfElected refreshes the recommended posts if the lock of the interval is acquired,
+// the lock is left to expire rather than released, so that it marks the interval as refreshed
+func (j *Job) refreshIfElected() {
+	elected, err := j.Cache.AcquireRefreshLock(j.interval)
+	if err != nil {
+		log.Errorf("recommendation: %+v", err)
+		return
+	}
+	if elected {
+		j.refresh()
+	}
+}
+
+// refresh recomputes the recommended posts of the members reading within the readersWindow,
+// the failures of a member are logged and left to the next run
+func (j *Job) refresh() {
+	userIDs, err := j.History.GetReadersSince(j.now().Add(-readersWindow))
+	if err != nil {
+		log.Errorf("recommendation: %+v", err)
+		return
+	}
+
+	for _, userID := range userIDs {
+		ids, err := j.recommend(userID)
+		if err == nil {
+			// keep the cache available if the next run is late
+			err = j.Cache.SetRecommendedPostIDs(userID, ids, 2*j.interval)
+		}
+		if err != nil {
+			log.Errorf("recommendation: user(id: %d): %+v", userID, err)
+		}
+	}
+}
+
+// recommend scores the unread posts by the tags of the latest read posts of the member
+func (j *Job) recommend(userID uint) ([]string, error) {
+	slugs, err := j.History.GetRecentlyReadSlugs(userID, maxReadSlugs)
+	if err != nil {
+		return nil, err
+	}
+
+	tagged := slugs
+	if len(tagged) > maxTaggedSlugs {
+		tagged = tagged[:maxTaggedSlugs]
+	}
+
+	read, err := j.Posts.GetTagsOfPostsBySlugs(tagged)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[bson.ObjectId]int)
+	for _, post := range read {
+		for _, tag := range post.TagsOrigin {
+			weights[tag]++
+		}
+	}
+	if len(weights) == 0 {
+		return []string{}, nil
+	}
+
+	tags := make([]bson.ObjectId, 0, len(weights))
+	for tag := range weights {
+		tags = append(tags, tag)
+	}
+
+	candidates, err := j.Posts.GetRecommendationCandidates(tags, slugs, maxCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankByTagOverlap(weights, candidates, maxRecommended), nil
+}
+
+// rankByTagOverlap returns the hex ids of the top candidates scored by the sum of the weights of their tags.
+// The candidates sharing no tags are skipped, and the ties keep the order of the candidates, i.e. the latest first.
+func rankByTagOverlap(weights map[bson.ObjectId]int, candidates []models.Post, limit int) []string {
+	type scored struct {
+		id    bson.ObjectId
+		score int
+	}
+
+	ranked := make([]scored, 0, len(candidates))
+	for _, post := range candidates {
+		var score int
+		for _, tag := range post.TagsOrigin {
+			score += weights[tag]
+		}
+		if score > 0 {
+			ranked = append(ranked, scored{post.ID, score})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, k int) bool {
+		return ranked[i].score > ranked[k].score
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	ids := make([]string, 0, len(ranked))
+	for _, r := range ranked {
+		ids = append(ids, r.id.Hex())
+	}
+	return ids
+}
@@ -0,0 +1,172 @@
+package recommendation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+var (
+	tagA = bson.ObjectIdHex("5951db87507c6a0d00ab0001")
+	tagB = bson.ObjectIdHex("5951db87507c6a0d00ab0002")
+	tagC = bson.ObjectIdHex("5951db87507c6a0d00ab0003")
+
+	postX = bson.ObjectIdHex("5951db87507c6a0d00ab1001")
+	postY = bson.ObjectIdHex("5951db87507c6a0d00ab1002")
+	postZ = bson.ObjectIdHex("5951db87507c6a0d00ab1003")
+)
+
+func TestRankByTagOverlap(t *testing.T) {
+	weights := map[bson.ObjectId]int{tagA: 3, tagB: 1}
+	// the candidates are the latest first
+	candidates := []models.Post{
+		{ID: postX, TagsOrigin: []bson.ObjectId{tagB}},
+		{ID: postY, TagsOrigin: []bson.ObjectId{tagC}},
+		{ID: postZ, TagsOrigin: []bson.ObjectId{tagA, tagB}},
+	}
+
+	cases := []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{name: "Given the candidates sharing no tags, skip them", limit: 10, want: []string{postZ.Hex(), postX.Hex()}},
+		{name: "Given the limit, keep the top scored", limit: 1, want: []string{postZ.Hex()}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := rankByTagOverlap(weights, candidates, tc.limit); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expect %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRankByTagOverlapTies(t *testing.T) {
+	weights := map[bson.ObjectId]int{tagA: 1}
+	candidates := []models.Post{
+		{ID: postY, TagsOrigin: []bson.ObjectId{tagA}},
+		{ID: postX, TagsOrigin: []bson.ObjectId{tagA}},
+	}
+
+	want := []string{postY.Hex(), postX.Hex()}
+	if got := rankByTagOverlap(weights, candidates, 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("expect the ties in the order of the candidates %v, but got %v", want, got)
+	}
+}
+
+type mockHistory struct {
+	readers []uint
+	slugs   map[uint][]string
+	since   time.Time
+}
+
+func (m *mockHistory) GetReadersSince(since time.Time) ([]uint, error) {
+	m.since = since
+	return m.readers, nil
+}
+
+func (m *mockHistory) GetRecentlyReadSlugs(userID uint, limit int) ([]string, error) {
+	return m.slugs[userID], nil
+}
+
+type mockPosts struct {
+	tags     map[string][]bson.ObjectId
+	excluded []string
+}
+
+func (m *mockPosts) GetTagsOfPostsBySlugs(slugs []string) ([]models.Post, error) {
+	var posts []models.Post
+	for _, slug := range slugs {
+		posts = append(posts, models.Post{Slug: slug, TagsOrigin: m.tags[slug]})
+	}
+	return posts, nil
+}
+
+func (m *mockPosts) GetRecommendationCandidates(tags []bson.ObjectId, excluded []string, limit int) ([]models.Post, error) {
+	m.excluded = excluded
+	return []models.Post{
+		{ID: postX, TagsOrigin: []bson.ObjectId{tagB}},
+		{ID: postZ, TagsOrigin: []bson.ObjectId{tagA}},
+	}, nil
+}
+
+type mockCache struct {
+	ids    map[uint][]string
+	ttl    time.Duration
+	locked bool
+}
+
+func (m *mockCache) AcquireRefreshLock(ttl time.Duration) (bool, error) {
+	if m.locked {
+		return false, nil
+	}
+	m.locked = true
+	return true, nil
+}
+
+func (m *mockCache) SetRecommendedPostIDs(userID uint, ids []string, ttl time.Duration) error {
+	m.ids[userID] = ids
+	m.ttl = ttl
+	return nil
+}
+
+func TestJobRefresh(t *testing.T) {
+	now := time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)
+	history := &mockHistory{
+		readers: []uint{1, 2},
+		slugs: map[uint][]string{
+			1: {"read-a1", "read-a2"},
+			// reads posts without tags
+			2: {"untagged"},
+		},
+	}
+	posts := &mockPosts{tags: map[string][]bson.ObjectId{
+		"read-a1": {tagA},
+		"read-a2": {tagA, tagB},
+	}}
+	cache := &mockCache{ids: map[uint][]string{}}
+
+	j := NewJob(history, posts, cache)
+	j.now = func() time.Time { return now }
+	j.refresh()
+
+	if want := now.Add(-readersWindow); !history.since.Equal(want) {
+		t.Errorf("expect the readers since %v, but got %v", want, history.since)
+	}
+	if want := []string{"read-a1", "read-a2"}; !reflect.DeepEqual(posts.excluded, want) {
+		t.Errorf("expect the read posts %v excluded, but got %v", want, posts.excluded)
+	}
+	if want := []string{postZ.Hex(), postX.Hex()}; !reflect.DeepEqual(cache.ids[1], want) {
+		t.Errorf("expect user 1 recommended %v, but got %v", want, cache.ids[1])
+	}
+	if got, ok := cache.ids[2]; !ok || len(got) != 0 {
+		t.Errorf("expect user 2 recommended nothing, but got %v", got)
+	}
+	if cache.ttl != 2*RefreshInterval {
+		t.Errorf("expect the cache expires after %s, but got %s", 2*RefreshInterval, cache.ttl)
+	}
+}
+
+func TestJobRefreshIfElected(t *testing.T) {
+	history := &mockHistory{readers: []uint{1}, slugs: map[uint][]string{1: {"untagged"}}}
+	cache := &mockCache{ids: map[uint][]string{}}
+	j := NewJob(history, &mockPosts{}, cache)
+
+	j.refreshIfElected()
+	if _, ok := cache.ids[1]; !ok {
+		t.Fatalf("expect the elected instance refreshes")
+	}
+
+	// the lock is held by the first refresh, e.g. of another instance or before a reboot
+	cache.ids = map[uint][]string{}
+	j.refreshIfElected()
+	if got, ok := cache.ids[1]; ok {
+		t.Errorf("expect the refresh skipped while the lock is held, but got %v", got)
+	}
+}
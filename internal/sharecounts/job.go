@@ -0,0 +1,133 @@
+// Package sharecounts polls the social networks for the counts of the posts shared on them
+package sharecounts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+const (
+	// PollInterval is how often the requested refreshes of the share counts are picked up
+	PollInterval = time.Minute
+	// maxRefreshes is the number of the posts polled each interval at most,
+	// the requests left are picked up in the next intervals to stay within the rate limits of the social APIs
+	maxRefreshes = 20
+	// pollTimeout is the deadline polling the social networks for a post
+	pollTimeout = 30 * time.Second
+)
+
+type shareCountsStore interface {
+	ClaimShareCountsRefresh() (models.Post, error)
+	UpdateShareCounts(bson.ObjectId, models.ShareCounts) error
+}
+
+type shareCounter interface {
+	CountShares(ctx context.Context, url string) (int, error)
+}
+
+// Job polls the share counts of the posts whose refreshes are requested
+type Job struct {
+	Posts shareCountsStore
+	// the counters are nil if the social networks are not polled, whose counts are kept as they are
+	Facebook shareCounter
+	Twitter  shareCounter
+	Line     shareCounter
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewJob returns a Job polling the requested refreshes every PollInterval.
+// Twitter provides no API counting the shares, so its count is not polled.
+func NewJob(p shareCountsStore, facebook shareCounter, line shareCounter) *Job {
+	return &Job{Posts: p, Facebook: facebook, Line: line, interval: PollInterval, now: time.Now}
+}
+
+// Run polls the requested refreshes at once and then every interval until ctx is done.
+// The requests are claimed atomically, so each of them is polled by one of the instances only.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll refreshes the share counts of the requested posts until no request is left or maxRefreshes is reached
+func (j *Job) poll(ctx context.Context) {
+	for i := 0; i < maxRefreshes; i++ {
+		post, err := j.Posts.ClaimShareCountsRefresh()
+		if storage.IsNotFound(err) {
+			return
+		}
+		if err != nil {
+			log.Errorf("sharecounts: %+v", err)
+			return
+		}
+
+		if err = j.refresh(ctx, post); err != nil {
+			log.Errorf("sharecounts: post(slug: %s): %+v", post.Slug, err)
+		}
+	}
+}
+
+// refresh polls the social networks for the post, and keeps the previous counts of the networks failing.
+// The request is consumed even if all of them fail, the refresh should be requested again.
+func (j *Job) refresh(ctx context.Context, post models.Post) error {
+	var counts models.ShareCounts
+	if post.ShareCounts != nil {
+		counts = *post.ShareCounts
+	}
+	counts.RefreshRequestedAt = nil
+
+	ctx, cancel := context.WithTimeout(ctx, pollTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/a/%s", globals.MainSiteOrigin, post.Slug)
+	networks := []struct {
+		name    string
+		counter shareCounter
+		count   *int
+	}{
+		{"facebook", j.Facebook, &counts.Facebook},
+		{"twitter", j.Twitter, &counts.Twitter},
+		{"line", j.Line, &counts.Line},
+	}
+
+	var polled int
+	for _, network := range networks {
+		if network.counter == nil {
+			continue
+		}
+		count, err := network.counter.CountShares(ctx, url)
+		if err != nil {
+			log.Errorf("sharecounts: post(slug: %s): %s: %+v", post.Slug, network.name, err)
+			continue
+		}
+		*network.count = count
+		polled++
+	}
+
+	if polled == 0 {
+		return errors.New("none of the social networks is polled")
+	}
+
+	now := j.now()
+	counts.UpdatedAt = &now
+	return j.Posts.UpdateShareCounts(post.ID, counts)
+}
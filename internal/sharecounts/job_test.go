@@ -0,0 +1,111 @@
+package sharecounts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockShareCountsStore struct {
+	pending []models.Post
+	updated map[bson.ObjectId]models.ShareCounts
+}
+
+func (m *mockShareCountsStore) ClaimShareCountsRefresh() (models.Post, error) {
+	if len(m.pending) == 0 {
+		return models.Post{}, storage.ErrMgoNotFound
+	}
+	post := m.pending[0]
+	m.pending = m.pending[1:]
+	return post, nil
+}
+
+func (m *mockShareCountsStore) UpdateShareCounts(id bson.ObjectId, counts models.ShareCounts) error {
+	m.updated[id] = counts
+	return nil
+}
+
+type mockShareCounter struct {
+	counts map[string]int
+}
+
+func (m mockShareCounter) CountShares(ctx context.Context, url string) (int, error) {
+	count, ok := m.counts[url]
+	if !ok {
+		return 0, errors.New("mock error")
+	}
+	return count, nil
+}
+
+func TestJobPoll(t *testing.T) {
+	var (
+		postA = bson.ObjectIdHex("5951db87507c6a0d00ab1001")
+		postB = bson.ObjectIdHex("5951db87507c6a0d00ab1002")
+		postC = bson.ObjectIdHex("5951db87507c6a0d00ab1003")
+	)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	requestedAt := now.Add(-time.Minute)
+
+	s := &mockShareCountsStore{
+		pending: []models.Post{
+			{ID: postA, Slug: "post-a"},
+			// facebook fails, so the previous count is kept
+			{ID: postB, Slug: "post-b", ShareCounts: &models.ShareCounts{Facebook: 3, Twitter: 2, Line: 1, RefreshRequestedAt: &requestedAt}},
+			// both fail, so the counts are not updated
+			{ID: postC, Slug: "post-c"},
+		},
+		updated: make(map[bson.ObjectId]models.ShareCounts),
+	}
+
+	j := NewJob(s,
+		mockShareCounter{counts: map[string]int{"https://www.twreporter.org/a/post-a": 10}},
+		mockShareCounter{counts: map[string]int{"https://www.twreporter.org/a/post-a": 5, "https://www.twreporter.org/a/post-b": 4}},
+	)
+	j.now = func() time.Time { return now }
+
+	j.poll(context.Background())
+
+	if len(s.pending) != 0 {
+		t.Fatalf("expect all the requests claimed, but %d are left", len(s.pending))
+	}
+
+	cases := []struct {
+		name string
+		id   bson.ObjectId
+		want *models.ShareCounts
+	}{
+		{name: "Given all the networks polled", id: postA, want: &models.ShareCounts{Facebook: 10, Line: 5}},
+		{name: "Given a network failing", id: postB, want: &models.ShareCounts{Facebook: 3, Twitter: 2, Line: 4}},
+		{name: "Given all the networks failing", id: postC},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := s.updated[tc.id]
+			if tc.want == nil {
+				if ok {
+					t.Errorf("expect the counts not updated, but got %+v", got)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("expect the counts updated")
+			}
+			if got.Facebook != tc.want.Facebook || got.Twitter != tc.want.Twitter || got.Line != tc.want.Line {
+				t.Errorf("expect counts %+v, but got %+v", *tc.want, got)
+			}
+			if got.UpdatedAt == nil || !got.UpdatedAt.Equal(now) {
+				t.Errorf("expect the counts updated at %v, but got %v", now, got.UpdatedAt)
+			}
+			if got.RefreshRequestedAt != nil {
+				t.Error("expect the refresh request cleared")
+			}
+		})
+	}
+}
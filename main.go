@@ -5,23 +5,33 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	f "github.com/twreporter/logformatter"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 
 	"twreporter.org/go-api/configs"
 	"twreporter.org/go-api/controllers"
 	"twreporter.org/go-api/globals"
-	"twreporter.org/go-api/internal/mongo"
+	"twreporter.org/go-api/internal/recommendation"
+	"twreporter.org/go-api/internal/sharecounts"
 	"twreporter.org/go-api/routers"
 	"twreporter.org/go-api/services"
+	"twreporter.org/go-api/storage"
 	"twreporter.org/go-api/utils"
 )
 
+const (
+	// mongoConnectTimeout is the deadline connecting to MongoDB at startup
+	mongoConnectTimeout = 30 * time.Second
+	// shutdownTimeout is the deadline of the in-flight requests on shutdown, which is longer than the write timeout
+	shutdownTimeout = 45 * time.Second
+)
+
 func main() {
 	var err error
 	var cf *controllers.ControllerFactory
@@ -33,6 +43,7 @@ func main() {
 			} else {
 				log.WithField("detail", err).Errorf("%s", f.FormatStack(err))
 			}
+			os.Exit(1)
 		}
 	}()
 
@@ -42,8 +53,18 @@ func main() {
 		return
 	}
 
+	if err = globals.Conf.Validate(); err != nil {
+		err = errors.WithMessage(err, "Fatal error config file")
+		return
+	}
+
 	configLogger()
 
+	if err = utils.InitSigningKeys(); err != nil {
+		err = errors.Wrap(err, "Fail to initiate jwt signing keys")
+		return
+	}
+
 	// set up database connection
 	log.Info("Connecting to MySQL cloud")
 	db, err := utils.InitDB(10, 5)
@@ -60,20 +81,38 @@ func main() {
 	}
 
 	log.Info("Connection to MongoDB with mongo-go-driver")
-	ctx := context.Background()
-	opts := options.Client()
-	client, err := mongo.NewClient(ctx, opts.ApplyURI(globals.Conf.DB.Mongo.URL).SetReadPreference(readpref.Nearest()))
+	ctx, cancel := context.WithTimeout(context.Background(), mongoConnectTimeout)
+	ms, err := storage.NewMongoStorageWithOptions(ctx, globals.Conf.DB.Mongo.URL, storage.MongoStorageOptions{
+		ReadPreference: readpref.Nearest(),
+	})
+	cancel()
 
 	if err != nil {
 		return
 	}
+	client := ms.MongoClient()
 	defer func() {
-		client.Disconnect(ctx)
+		client.Disconnect(context.Background())
 	}()
+	redisClient, err := utils.InitRedis()
+	if err != nil {
+		return
+	}
+	if redisClient != nil {
+		log.Info("Caching news in Redis")
+		defer redisClient.Close()
+
+		// the recommended posts are cached in Redis only
+		go recommendation.NewJob(storage.NewGormStorage(db), storage.NewMongoStorage(session), storage.NewRecommendationCache(redisClient)).Run(context.Background())
+	}
+
+	// the refreshes of the share counts requested by the admins are claimed in MongoDB
+	go sharecounts.NewJob(storage.NewMongoStorage(session), services.NewFacebookShareCounter(globals.Conf.Oauth.Facebook), services.NewLineShareCounter()).Run(context.Background())
+
 	// mailSender := services.NewSMTPMailService() // use office365 to send mails
 	mailSvc := services.NewAmazonMailService() // use Amazon SES to send mails
 
-	cf = controllers.NewControllerFactory(db, session, mailSvc, client)
+	cf = controllers.NewControllerFactory(db, session, mailSvc, client, redisClient)
 
 	// set up the router
 	router := routers.SetupRouter(cf)
@@ -82,16 +121,41 @@ func main() {
 
 	// Set writeTimeout bigger than 30 secs.
 	// 30 secs is to ensure donation request is handled correctly.
+	// The event streams are exempted from it.
 	writeTimeout := 40 * time.Second
 	s := &http.Server{
 		Addr:         fmt.Sprintf(":%s", globals.LocalhostPort),
-		Handler:      router,
+		Handler:      routers.ExemptStreamsFromWriteTimeout(routers.RewritePrefixes(router)),
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 	}
 
-	if err = s.ListenAndServe(); err != nil {
+	// stop accepting the requests on SIGINT/SIGTERM, and wait for the in-flight ones
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := s.Shutdown(ctx); err != nil {
+			log.Errorf("%+v", errors.Wrap(err, "Fail to shut down HTTP server gracefully"))
+		}
+	}()
+
+	if err = s.ListenAndServe(); err != http.ErrServerClosed {
 		err = errors.Wrap(err, "Fail to start HTTP server")
+		return
+	}
+	err = nil
+	<-shutdown
+
+	// the views of the topics are buffered in memory
+	if err := storage.NewMongoStorage(session).FlushTopicViews(); err != nil {
+		log.Errorf("%+v", err)
 	}
 	return
 }
@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type userGetter interface {
+	GetUserByID(string) (models.User, error)
+}
+
+// ValidateAdmin checks the authenticated user has the admin privilege.
+// It should be used after ValidateAuthorization, which sets the user id in the context.
+func ValidateAdmin(s userGetter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authUserID := c.Request.Context().Value(globals.AuthUserIDProperty)
+		if authUserID == nil {
+			authorizationErrorHandler(c, "Required authorization token not found")
+			return
+		}
+
+		user, err := s.GetUserByID(fmt.Sprint(authUserID))
+		if err != nil && !storage.IsNotFound(err) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": fmt.Sprintf("internal server error. %s", err.Error()),
+			})
+			return
+		}
+
+		if err != nil || user.Privilege < constants.PrivilegeAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"status": "fail",
+				"data": gin.H{
+					"req.Headers.Authorization": "the request is not permitted to reach the resource",
+				},
+			})
+			return
+		}
+	}
+}
+
+// ValidateUserIDOrAdmin checks claim userID in the jwt with :userID param in the request url,
+// the request of other users is only permitted if the authenticated user has the admin privilege.
+// It should be used after ValidateAuthorization, which sets the user id in the context.
+func ValidateUserIDOrAdmin(s userGetter) gin.HandlerFunc {
+	validateAdmin := ValidateAdmin(s)
+	return func(c *gin.Context) {
+		if c.Param("userID") == fmt.Sprint(c.Request.Context().Value(globals.AuthUserIDProperty)) {
+			return
+		}
+		validateAdmin(c)
+	}
+}
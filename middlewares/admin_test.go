@@ -0,0 +1,112 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type mockUserGetter map[string]models.User
+
+func (m mockUserGetter) GetUserByID(userID string) (models.User, error) {
+	if userID == "500" {
+		return models.User{}, errors.New("connection refused")
+	}
+	if user, ok := m[userID]; ok {
+		return user, nil
+	}
+	return models.User{}, storage.ErrRecordNotFound
+}
+
+func TestValidateAdmin(t *testing.T) {
+	users := mockUserGetter{
+		"1": {ID: 1, Privilege: constants.PrivilegeAdmin},
+		"2": {ID: 2, Privilege: constants.PrivilegeRegistered},
+	}
+
+	cases := []struct {
+		name   string
+		userID interface{}
+		want   int
+	}{
+		{name: "Given an admin", userID: float64(1), want: http.StatusOK},
+		{name: "Given a registered user", userID: float64(2), want: http.StatusForbidden},
+		{name: "Given an unknown user", userID: float64(3), want: http.StatusForbidden},
+		{name: "Given storage error", userID: float64(500), want: http.StatusInternalServerError},
+		{name: "Given no authenticated user", userID: nil, want: http.StatusUnauthorized},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(func(c *gin.Context) {
+				if tc.userID != nil {
+					*c.Request = *c.Request.WithContext(context.WithValue(c.Request.Context(), globals.AuthUserIDProperty, tc.userID))
+				}
+			})
+			engine.GET("/admin", ValidateAdmin(users), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != tc.want {
+				t.Errorf("expected status %d, got %d", tc.want, resp.Code)
+			}
+		})
+	}
+}
+
+func TestValidateUserIDOrAdmin(t *testing.T) {
+	users := mockUserGetter{
+		"1": {ID: 1, Privilege: constants.PrivilegeAdmin},
+		"2": {ID: 2, Privilege: constants.PrivilegeRegistered},
+	}
+
+	cases := []struct {
+		name   string
+		userID interface{}
+		path   string
+		want   int
+	}{
+		{name: "Given the user itself", userID: float64(2), path: "/users/2", want: http.StatusOK},
+		{name: "Given an admin", userID: float64(1), path: "/users/2", want: http.StatusOK},
+		{name: "Given another user", userID: float64(2), path: "/users/1", want: http.StatusForbidden},
+		{name: "Given no authenticated user", userID: nil, path: "/users/2", want: http.StatusUnauthorized},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(func(c *gin.Context) {
+				if tc.userID != nil {
+					*c.Request = *c.Request.WithContext(context.WithValue(c.Request.Context(), globals.AuthUserIDProperty, tc.userID))
+				}
+			})
+			engine.GET("/users/:userID", ValidateUserIDOrAdmin(users), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != tc.want {
+				t.Errorf("expected status %d, got %d", tc.want, resp.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidateContentType responds 415 if the body of POST, PATCH, PUT or DELETE request is not in one of the media types,
+// e.g. `application/json`. The requests of other methods and the requests without body are passed.
+// The routes accepting other media types, e.g. `multipart/form-data` for uploads,
+// should be registered on a group not applying this middleware.
+func ValidateContentType(mediaTypes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPatch, http.MethodPut, http.MethodDelete:
+		default:
+			return
+		}
+
+		if c.Request.ContentLength == 0 {
+			return
+		}
+
+		if mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type")); err == nil {
+			for _, t := range mediaTypes {
+				if mediaType == t {
+					return
+				}
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{
+			"status": "fail",
+			"data": gin.H{
+				"req.Headers.Content-Type": fmt.Sprintf("should be %s", strings.Join(mediaTypes, " or ")),
+			},
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestValidateContentType(t *testing.T) {
+	cases := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		want        int
+	}{
+		{name: "Given a JSON body", method: http.MethodPost, contentType: "application/json; charset=utf-8", body: `{}`, want: http.StatusOK},
+		{name: "Given a form body", method: http.MethodPut, contentType: binding.MIMEPOSTForm, body: "a=b", want: http.StatusOK},
+		{name: "Given a XML body", method: http.MethodPost, contentType: binding.MIMEXML, body: "<a></a>", want: http.StatusUnsupportedMediaType},
+		{name: "Given a body without Content-Type", method: http.MethodPatch, body: `{}`, want: http.StatusUnsupportedMediaType},
+		{name: "Given a multipart body", method: http.MethodPost, contentType: "multipart/form-data; boundary=x", body: "--x--", want: http.StatusUnsupportedMediaType},
+		{name: "Given a DELETE with a text body", method: http.MethodDelete, contentType: "text/plain", body: "a", want: http.StatusUnsupportedMediaType},
+		{name: "Given a DELETE without body", method: http.MethodDelete, want: http.StatusOK},
+		{name: "Given no body", method: http.MethodPost, want: http.StatusOK},
+		{name: "Given a GET", method: http.MethodGet, contentType: binding.MIMEXML, body: "<a></a>", want: http.StatusOK},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(ValidateContentType(binding.MIMEJSON, binding.MIMEPOSTForm))
+			engine.Handle(tc.method, "/resource", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, "/resource", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != tc.want {
+				t.Errorf("expect status %d, but got %d", tc.want, resp.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"twreporter.org/go-api/utils"
+)
+
+// RouteVersionInfo describes the route superseded by the route of the newer version
+type RouteVersionInfo struct {
+	// Successor is the path of the route superseding it, e.g. `/v2/posts`.
+	// The params of the path are filled in by the ones of the deprecated route, e.g. `/v2/posts/:slug`.
+	Successor string
+	// Sunset is when the route is going to be removed
+	Sunset time.Time
+	// LogSampler samples the warnings of the calls, all of them are logged if it is nil
+	LogSampler *utils.LogSampler
+}
+
+// Deprecate marks the route superseded by the newer version with `Deprecation: true` and `Sunset` response headers,
+// along with `Link` header to the successor, and logs a warning for the sampled calls of the deprecated route.
+// It should be registered alongside the route, e.g. `v1Group.GET("/posts", middlewares.Deprecate(info), ...)`.
+func Deprecate(info RouteVersionInfo) gin.HandlerFunc {
+	sunset := info.Sunset.UTC().Format(http.TimeFormat)
+
+	return func(c *gin.Context) {
+		successor := successorPath(info.Successor, c.Params)
+
+		c.Header("Deprecation", "true")
+		c.Header("Sunset", sunset)
+		if successor != "" {
+			c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		}
+
+		if info.LogSampler.Sample() {
+			log.WithFields(log.Fields{
+				"route":      c.FullPath(),
+				"successor":  successor,
+				"sunset":     sunset,
+				"user_agent": c.Request.UserAgent(),
+			}).Warn("deprecated route is called")
+		}
+	}
+}
+
+// successorPath fills the params of the path, e.g. `/v2/posts/:slug`, by the ones of the request
+func successorPath(path string, params gin.Params) string {
+	if !strings.Contains(path, ":") {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = params.ByName(segment[1:])
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/utils"
+)
+
+func TestDeprecate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/v1/posts", Deprecate(RouteVersionInfo{
+		Successor: "/v2/posts",
+		Sunset:    time.Date(2021, time.June, 30, 0, 0, 0, 0, time.UTC),
+	}), func(c *gin.Context) {
+		c.String(http.StatusOK, "posts")
+	})
+	engine.GET("/v1/posts/:slug", Deprecate(RouteVersionInfo{
+		Successor:  "/v2/posts/:slug",
+		Sunset:     time.Date(2021, time.June, 30, 0, 0, 0, 0, time.UTC),
+		LogSampler: utils.NewLogSampler(100),
+	}), func(c *gin.Context) {
+		c.String(http.StatusOK, "post")
+	})
+	engine.GET("/v2/posts", func(c *gin.Context) {
+		c.String(http.StatusOK, "posts")
+	})
+
+	cases := []struct {
+		name        string
+		path        string
+		wantHeaders map[string]string
+	}{
+		{
+			name: "Given the deprecated route",
+			path: "/v1/posts",
+			wantHeaders: map[string]string{
+				"Deprecation": "true",
+				"Sunset":      "Wed, 30 Jun 2021 00:00:00 GMT",
+				"Link":        `</v2/posts>; rel="successor-version"`,
+			},
+		},
+		{
+			name: "Given the deprecated route with params",
+			path: "/v1/posts/mock-slug",
+			wantHeaders: map[string]string{
+				"Deprecation": "true",
+				"Sunset":      "Wed, 30 Jun 2021 00:00:00 GMT",
+				"Link":        `</v2/posts/mock-slug>; rel="successor-version"`,
+			},
+		},
+		{
+			name: "Given the successor route",
+			path: "/v2/posts",
+			wantHeaders: map[string]string{
+				"Deprecation": "",
+				"Sunset":      "",
+				"Link":        "",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if resp.Code != http.StatusOK {
+				t.Fatalf("expect status %d, but got %d", http.StatusOK, resp.Code)
+			}
+			for key, want := range tc.wantHeaders {
+				if got := resp.Header().Get(key); got != want {
+					t.Errorf("expect %s header %q, but got %q", key, want, got)
+				}
+			}
+		})
+	}
+}
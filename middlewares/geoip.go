@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oschwald/geoip2-golang"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"twreporter.org/go-api/globals"
+)
+
+// countryResolver resolves the IP to the ISO country code, e.g. TW
+type countryResolver interface {
+	Country(net.IP) (string, error)
+}
+
+// NewGeoIPResolver opens the MaxMind GeoLite2 Country database of path,
+// the database is loaded into memory and kept open for the lifetime of the server.
+func NewGeoIPResolver(path string) (*GeoIPResolver, error) {
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("fail to open geoip database(path: %s)", path))
+	}
+	return &GeoIPResolver{reader: reader}, nil
+}
+
+// GeoIPResolver implements countryResolver by the MaxMind GeoLite2 Country database
+type GeoIPResolver struct {
+	reader *geoip2.Reader
+}
+
+// Country returns the ISO country code of the IP, or empty string if the IP is not in the database, e.g. the private IPs
+func (r *GeoIPResolver) Country(ip net.IP) (string, error) {
+	record, err := r.reader.Country(ip)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("fail to resolve the country of ip(%s)", ip))
+	}
+	return record.Country.IsoCode, nil
+}
+
+// SetClientCountry resolves `c.ClientIP()` to the country code, which is set in gin context by globals.ClientCountryProperty.
+// It should be used after SetTrustedProxies so that the client IP is not spoofed.
+// The country is not set if it cannot be resolved, and the request is passed as usual.
+func SetClientCountry(r countryResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := net.ParseIP(c.ClientIP())
+		if ip == nil {
+			return
+		}
+
+		country, err := r.Country(ip)
+		if err != nil {
+			log.Warnf("%+v", err)
+			return
+		}
+		if country != "" {
+			c.Set(globals.ClientCountryProperty, country)
+		}
+	}
+}
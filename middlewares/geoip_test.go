@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+)
+
+type mockCountryResolver struct {
+	countries map[string]string
+}
+
+func (m mockCountryResolver) Country(ip net.IP) (string, error) {
+	country, ok := m.countries[ip.String()]
+	if !ok {
+		return "", errors.New("address not found")
+	}
+	return country, nil
+}
+
+func TestSetClientCountry(t *testing.T) {
+	resolver := mockCountryResolver{countries: map[string]string{
+		"203.0.113.10": "TW",
+		"10.0.0.1":     "",
+	}}
+
+	cases := []struct {
+		name       string
+		remoteAddr string
+		want       string
+		wantSet    bool
+	}{
+		{name: "Given a resolvable client IP", remoteAddr: "203.0.113.10:5678", want: "TW", wantSet: true},
+		{name: "Given a private client IP", remoteAddr: "10.0.0.1:5678"},
+		{name: "Given the resolver fails", remoteAddr: "198.51.100.1:5678"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			var exists bool
+
+			engine := gin.New()
+			engine.Use(SetClientCountry(resolver))
+			engine.GET("/", func(c *gin.Context) {
+				var v interface{}
+				v, exists = c.Get(globals.ClientCountryProperty)
+				got, _ = v.(string)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != http.StatusOK {
+				t.Errorf("expect the request passed, but got status %d", resp.Code)
+			}
+			if exists != tc.wantSet || got != tc.want {
+				t.Errorf("expect country %q(set: %v), but got %q(set: %v)", tc.want, tc.wantSet, got, exists)
+			}
+		})
+	}
+}
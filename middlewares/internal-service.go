@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/utils"
+)
+
+// ValidateInternalService checks the client credentials of the internal services
+// carried by HTTP Basic authentication
+func ValidateInternalService() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientID, clientSecret, _ := c.Request.BasicAuth()
+		if !utils.ValidateInternalServiceCredentials(clientID, clientSecret) {
+			c.Header("WWW-Authenticate", `Basic realm="internal"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"status": "fail",
+				"data": gin.H{
+					"req.Headers.Authorization": "invalid client credentials",
+				},
+			})
+		}
+	}
+}
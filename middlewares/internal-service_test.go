@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/globals"
+)
+
+func TestValidateInternalService(t *testing.T) {
+	defaultApp := globals.Conf.App
+	globals.Conf.App.InternalClientID = "test-client"
+	globals.Conf.App.InternalClientSecret = "test-client-secret"
+	globals.Conf.App.IntrospectionClientID = "test-introspection-client"
+	globals.Conf.App.IntrospectionClientSecret = "test-introspection-client-secret"
+	defer func() {
+		globals.Conf.App = defaultApp
+	}()
+
+	cases := []struct {
+		name   string
+		id     string
+		secret string
+		want   int
+	}{
+		{name: "Given valid credentials", id: "test-client", secret: "test-client-secret", want: http.StatusOK},
+		{name: "Given invalid secret", id: "test-client", secret: "invalid", want: http.StatusUnauthorized},
+		{name: "Given no credentials", want: http.StatusUnauthorized},
+		{name: "Given the introspection credentials", id: "test-introspection-client", secret: "test-introspection-client-secret", want: http.StatusUnauthorized},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.POST("/internal", ValidateInternalService(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(http.MethodPost, "/internal", nil)
+			if tc.id != "" {
+				req.SetBasicAuth(tc.id, tc.secret)
+			}
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != tc.want {
+				t.Errorf("expected status %d, got %d", tc.want, resp.Code)
+			}
+		})
+	}
+}
@@ -2,12 +2,14 @@ package middlewares
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
 	"twreporter.org/go-api/utils"
 
 	"github.com/auth0/go-jwt-middleware"
@@ -16,41 +18,104 @@ import (
 	"github.com/gin-gonic/gin/binding"
 )
 
-const authUserProperty = "app-auth-jwt"
-
-var jwtMiddleware = jwtmiddleware.New(jwtmiddleware.Options{
-	ValidationKeyGetter: func(token *jwt.Token) (interface{}, error) {
-		return []byte(globals.Conf.App.JwtSecret), nil
-	},
-	UserProperty:  authUserProperty,
-	SigningMethod: jwt.SigningMethodHS256,
-	ErrorHandler: func(w http.ResponseWriter, r *http.Request, err string) {
-		var res = map[string]interface{}{
-			"status": "fail",
-			"data": map[string]interface{}{
-				"req.Headers.Authorization": err,
-			},
-		}
-		var resByte, _ = json.Marshal(res)
-		http.Error(w, string(resByte), http.StatusUnauthorized)
-	},
-})
+// authorizationErrorHandler responds 401 with the reason why the Authorization header is invalid
+func authorizationErrorHandler(c *gin.Context, err string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"status": "fail",
+		"data": gin.H{
+			"req.Headers.Authorization": err,
+		},
+	})
+}
+
+// userCheckAge is the age of the token since which the user is checked to still exist.
+// The younger tokens are trusted without the database roundtrip,
+// unless the user is just blocked by the admins in this process.
+const userCheckAge = 5 * time.Minute
+
+// accountBlockedHandler responds 403 to the user blocked by the admins
+func accountBlockedHandler(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"status": "fail",
+		"error":  models.ErrCodeAccountBlocked,
+		"data": gin.H{
+			"req.Headers.Authorization": "the user of the token is blocked",
+		},
+	})
+}
+
+// validateUserExists checks the user of the token is not deleted or blocked since the token was issued.
+// The user is looked up by `user_id` claim, since the tokens of the users signing in by the providers without email
+// have no `email` claim. The email of the user should still be the one of the token if it is claimed.
+// It returns false after responding the error.
+func validateUserExists(c *gin.Context, s userGetter, claims jwt.MapClaims) bool {
+	iat, _ := claims["iat"].(float64)
+	email, _ := claims["email"].(string)
+	userID := userIDOfClaims(claims)
+	if jwt.TimeFunc().Sub(time.Unix(int64(iat), 0)) < userCheckAge && !utils.IsUserRecentlyBlocked(userID) {
+		return true
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil && !storage.IsNotFound(err) {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": fmt.Sprintf("internal server error. %s", err.Error()),
+		})
+		return false
+	}
+
+	if err != nil || (email != "" && user.Email.String != email) {
+		authorizationErrorHandler(c, "the user of the token does not exist")
+		return false
+	}
 
-// ValidateAuthorization checks the jwt token in the Authorization header is valid or not
-func ValidateAuthorization() gin.HandlerFunc {
+	if user.Blocked {
+		accountBlockedHandler(c)
+		return false
+	}
+	return true
+}
+
+// userIDOfClaims formats `user_id` claim, which is decoded as float64, without the exponent
+func userIDOfClaims(claims jwt.MapClaims) string {
+	if id, ok := claims["user_id"].(float64); ok {
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	}
+	return fmt.Sprint(claims["user_id"])
+}
+
+// ValidateAuthorization checks the jwt token in the Authorization header is valid or not,
+// and the user of the token still exists and is not blocked if the token is older than `userCheckAge`
+func ValidateAuthorization(s userGetter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		const verifyRequired = true
 		var err error
-		var userProperty interface{}
+		var parsedClaims jwt.Claims
 		var claims jwt.MapClaims
+		var tokenString string
 
-		if err = jwtMiddleware.CheckJWT(c.Writer, c.Request); err != nil {
-			c.Abort()
+		if tokenString, err = jwtmiddleware.FromAuthHeader(c.Request); err != nil {
+			authorizationErrorHandler(c, err.Error())
 			return
 		}
 
-		userProperty = c.Request.Context().Value(authUserProperty)
-		claims = userProperty.(*jwt.Token).Claims.(jwt.MapClaims)
+		if tokenString == "" {
+			authorizationErrorHandler(c, "Required authorization token not found")
+			return
+		}
+
+		if tokenString, err = utils.DecryptToken(tokenString); err != nil {
+			authorizationErrorHandler(c, fmt.Sprintf("Error decrypting token: %v", err))
+			return
+		}
+
+		if parsedClaims, err = utils.ParseToken(tokenString, jwt.MapClaims{}, globals.Conf.App.JwtSecret); err != nil {
+			authorizationErrorHandler(c, fmt.Sprintf("Error parsing token: %v", err))
+			return
+		}
+
+		claims = parsedClaims.(jwt.MapClaims)
 		if !claims.VerifyAudience(globals.Conf.App.JwtAudience, verifyRequired) ||
 			!claims.VerifyIssuer(globals.Conf.App.JwtIssuer, verifyRequired) {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
@@ -62,6 +127,10 @@ func ValidateAuthorization() gin.HandlerFunc {
 			return
 		}
 
+		if !validateUserExists(c, s, claims) {
+			return
+		}
+
 		var newRequest *http.Request
 
 		// Set user_id with key "auth-user-id" in context to avoid hierarchy access
@@ -70,6 +139,18 @@ func ValidateAuthorization() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthorization validates the jwt token in the Authorization header as ValidateAuthorization if it is present,
+// while the requests without the header are passed as anonymous ones.
+func OptionalAuthorization(s userGetter) gin.HandlerFunc {
+	validateAuthorization := ValidateAuthorization(s)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			return
+		}
+		validateAuthorization(c)
+	}
+}
+
 // ValidateUserID checks claim userID in the jwt with :userID param in the request url.
 // if the two values are not the same, return the 401 response
 func ValidateUserID() gin.HandlerFunc {
@@ -131,7 +212,6 @@ func ValidateAuthentication() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var tokenString string
 		var err error
-		var token *jwt.Token
 
 		defer func() {
 			if r := recover(); r != nil {
@@ -149,14 +229,11 @@ func ValidateAuthentication() gin.HandlerFunc {
 			panic(err)
 		}
 
-		if token, err = jwt.ParseWithClaims(tokenString, &utils.IDTokenJWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(globals.Conf.App.JwtSecret), nil
-		}); err != nil {
+		if tokenString, err = utils.DecryptToken(tokenString); err != nil {
 			panic(err)
 		}
 
-		if !token.Valid {
-			err = errors.New("id_token is invalid")
+		if _, err = utils.ParseToken(tokenString, &utils.IDTokenJWTClaims{}, globals.Conf.App.JwtSecret); err != nil {
 			panic(err)
 		}
 	}
@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+	"twreporter.org/go-api/utils"
+)
+
+// countingUserGetter counts the lookups, so the tokens trusted without checking the user are told
+type countingUserGetter struct {
+	users map[string]models.User
+	calls int
+}
+
+func (m *countingUserGetter) GetUserByID(userID string) (models.User, error) {
+	m.calls++
+	if userID == "4" {
+		return models.User{}, errors.New("connection refused")
+	}
+	if user, ok := m.users[userID]; ok {
+		return user, nil
+	}
+	return models.User{}, storage.ErrRecordNotFound
+}
+
+func TestValidateAuthorizationUserExists(t *testing.T) {
+	defaultApp := globals.Conf.App
+	globals.Conf.App.JwtSecret = "test-secret"
+	globals.Conf.App.JwtIssuer = "test-issuer"
+	globals.Conf.App.JwtAudience = "test-audience"
+	defer func() {
+		globals.Conf.App = defaultApp
+		jwt.TimeFunc = time.Now
+	}()
+
+	s := &countingUserGetter{users: map[string]models.User{
+		"1": {ID: 1, Email: null.StringFrom("user@twreporter.org")},
+		"3": {ID: 3, Email: null.StringFrom("changed@twreporter.org")},
+		"5": {ID: 5, Email: null.StringFrom("blocked@twreporter.org"), Blocked: true},
+		"6": {ID: 6, Email: null.StringFrom("just-blocked@twreporter.org"), Blocked: true},
+		"7": {ID: 7, Blocked: true},
+		"8": {ID: 8},
+	}}
+	utils.MarkUserBlocked("6")
+
+	cases := []struct {
+		name      string
+		userID    uint
+		email     string
+		age       time.Duration
+		want      int
+		wantCalls int
+	}{
+		{name: "Given a fresh token of deleted user", userID: 2, email: "deleted@twreporter.org", want: http.StatusOK, wantCalls: 0},
+		{name: "Given an old token of existing user", userID: 1, email: "user@twreporter.org", age: 10 * time.Minute, want: http.StatusOK, wantCalls: 1},
+		{name: "Given an old token of deleted user", userID: 2, email: "deleted@twreporter.org", age: 10 * time.Minute, want: http.StatusUnauthorized, wantCalls: 1},
+		{name: "Given an old token of reassigned email", userID: 3, email: "user@twreporter.org", age: 10 * time.Minute, want: http.StatusUnauthorized, wantCalls: 1},
+		{name: "Given an old token of blocked user", userID: 5, email: "blocked@twreporter.org", age: 10 * time.Minute, want: http.StatusForbidden, wantCalls: 1},
+		{name: "Given a fresh token of just blocked user", userID: 6, email: "just-blocked@twreporter.org", want: http.StatusForbidden, wantCalls: 1},
+		{name: "Given an old token without email of existing user", userID: 8, age: 10 * time.Minute, want: http.StatusOK, wantCalls: 1},
+		{name: "Given an old token without email of blocked user", userID: 7, age: 10 * time.Minute, want: http.StatusForbidden, wantCalls: 1},
+		{name: "Given storage error", userID: 4, email: "error@twreporter.org", age: 10 * time.Minute, want: http.StatusInternalServerError, wantCalls: 1},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			jwt.TimeFunc = time.Now
+			token, _ := utils.RetrieveV2AccessToken(tc.userID, tc.email, 3600)
+
+			now := time.Now().Add(tc.age)
+			jwt.TimeFunc = func() time.Time { return now }
+			s.calls = 0
+
+			engine := gin.New()
+			engine.GET("/auth", ValidateAuthorization(s), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/auth", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != tc.want {
+				t.Errorf("expected status %d, got %d", tc.want, resp.Code)
+			}
+			if s.calls != tc.wantCalls {
+				t.Errorf("expected %d lookups, got %d", tc.wantCalls, s.calls)
+			}
+		})
+	}
+}
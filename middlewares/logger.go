@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"twreporter.org/go-api/utils"
+)
+
+const requestIDHeader = "X-Request-Id"
+
+// LogRequest logs the completed requests as structured fields, whose query params are redacted by the redactor.
+// The requests completed faster than minLatency are not logged, e.g. health checks.
+// The request id is taken from X-Request-Id header, or generated if the header is not provided.
+func LogRequest(logger *log.Logger, minLatency time.Duration, redactor *Redactor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" {
+			requestID, _ = utils.GenerateRandomString(12)
+		}
+		c.Header(requestIDHeader, requestID)
+
+		c.Next()
+
+		latency := time.Since(start)
+		if latency < minLatency {
+			return
+		}
+
+		entry := logger.WithFields(log.Fields{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"query":      redactor.RedactQuery(c.Request.URL.RawQuery),
+			"route":      c.FullPath(),
+			"status":     c.Writer.Status(),
+			"latency_ms": float64(latency) / float64(time.Millisecond),
+			"client_ip":  c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+			"bytes_out":  c.Writer.Size(),
+			"request_id": requestID,
+		})
+
+		switch {
+		case c.Writer.Status() >= 500:
+			entry.Error("request completed")
+		case c.Writer.Status() >= 400:
+			entry.Warn("request completed")
+		default:
+			entry.Info("request completed")
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestLogRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger, hook := test.NewNullLogger()
+	engine := gin.New()
+	engine.Use(LogRequest(logger, 20*time.Millisecond, NewRedactor(nil, nil)))
+	engine.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	engine.GET("/posts/:slug", func(c *gin.Context) {
+		time.Sleep(30 * time.Millisecond)
+		c.String(http.StatusNotFound, "not found")
+	})
+
+	t.Run("Given a request faster than the threshold", func(t *testing.T) {
+		hook.Reset()
+		req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+
+		if len(hook.Entries) != 0 {
+			t.Errorf("expected no log, got %d entries", len(hook.Entries))
+		}
+	})
+
+	t.Run("Given a request slower than the threshold", func(t *testing.T) {
+		hook.Reset()
+		req, _ := http.NewRequest(http.MethodGet, "/posts/a-slug", nil)
+		req.Header.Set("X-Request-Id", "request-id")
+		req.Header.Set("User-Agent", "test-agent")
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, req)
+
+		entry := hook.LastEntry()
+		if entry == nil {
+			t.Fatal("expected the request is logged")
+		}
+		if entry.Level != log.WarnLevel {
+			t.Errorf("expected warn level, got %s", entry.Level)
+		}
+
+		want := log.Fields{
+			"method":     http.MethodGet,
+			"path":       "/posts/a-slug",
+			"route":      "/posts/:slug",
+			"status":     http.StatusNotFound,
+			"user_agent": "test-agent",
+			"bytes_out":  len("not found"),
+			"request_id": "request-id",
+		}
+		for key, value := range want {
+			if entry.Data[key] != value {
+				t.Errorf("expected %s to be %v, got %v", key, value, entry.Data[key])
+			}
+		}
+		if latency, _ := entry.Data["latency_ms"].(float64); latency < 30 {
+			t.Errorf("expected latency_ms at least 30, got %v", entry.Data["latency_ms"])
+		}
+		if resp.Header().Get("X-Request-Id") != "request-id" {
+			t.Errorf("expected the request id is responded")
+		}
+	})
+}
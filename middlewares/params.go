@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSlugLength is the maximum length of the slugs of posts and topics
+const maxSlugLength = 200
+
+var slugPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+func isValidSlug(slug string) bool {
+	return len(slug) <= maxSlugLength && slugPattern.MatchString(slug)
+}
+
+func abortWithInvalidParam(c *gin.Context, param, message string) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": "fail", "data": gin.H{param: message}})
+}
+
+// ValidateSlug rejects the request whose `:slug` path param is not alphanumerics and hyphens,
+// or longer than `maxSlugLength`, before it reaches the storage.
+func ValidateSlug() gin.HandlerFunc {
+	return ValidateSlugParam("slug")
+}
+
+// ValidateSlugParam validates the path param of the name as ValidateSlug does, e.g. `:seriesSlug`
+func ValidateSlugParam(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isValidSlug(c.Param(param)) {
+			abortWithInvalidParam(c, param, fmt.Sprintf("should be alphanumerics and hyphens, at most %d characters", maxSlugLength))
+			return
+		}
+		c.Next()
+	}
+}
+
+// ValidateListParams rejects the request whose `limit` or `offset` url query param is not a non-negative integer,
+// or whose `sort` url query param sorts by the fields out of sortFields.
+// `sort` is the comma separated fields, which are prefixed with `-` for descending order, e.g. `-publishedDate,updatedAt`.
+func ValidateListParams(sortFields ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool)
+	for _, field := range sortFields {
+		allowed[field] = true
+	}
+
+	return func(c *gin.Context) {
+		for _, param := range []string{"limit", "offset"} {
+			value, ok := c.GetQuery(param)
+			if !ok {
+				continue
+			}
+			if n, err := strconv.Atoi(value); err != nil || n < 0 {
+				abortWithInvalidParam(c, param, "should be a non-negative integer")
+				return
+			}
+		}
+
+		if sort := c.Query("sort"); sort != "" {
+			for _, field := range strings.Split(sort, ",") {
+				if !allowed[strings.TrimPrefix(field, "-")] {
+					abortWithInvalidParam(c, "sort", fmt.Sprintf("should sort by %s", strings.Join(sortFields, ", ")))
+					return
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,94 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidateSlug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.GET("/posts/:slug", ValidateSlug(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	cases := []struct {
+		slug string
+		code int
+	}{
+		{"a-mock-post-slug-1", http.StatusOK},
+		{"Post2020", http.StatusOK},
+		{strings.Repeat("a", maxSlugLength), http.StatusOK},
+		{strings.Repeat("a", maxSlugLength+1), http.StatusBadRequest},
+		{"%24where", http.StatusBadRequest},
+		{"slug.with.dots", http.StatusBadRequest},
+		{"slug_with_underscores", http.StatusBadRequest},
+		{"%E4%B8%AD%E6%96%87", http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/posts/"+tc.slug, nil))
+		if resp.Code != tc.code {
+			t.Errorf("slug %s: expect status %d, got %d", tc.slug, tc.code, resp.Code)
+		}
+	}
+}
+
+func TestValidateSlugParam(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.GET("/series/:seriesSlug", ValidateSlugParam("seriesSlug"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for slug, code := range map[string]int{
+		"a-mock-series": http.StatusOK,
+		"%24where":      http.StatusBadRequest,
+	} {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/series/"+slug, nil))
+		if resp.Code != code {
+			t.Errorf("series slug %s: expect status %d, got %d", slug, code, resp.Code)
+		}
+	}
+}
+
+func TestValidateListParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.GET("/posts", ValidateListParams("publishedDate", "updatedAt"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	cases := []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusOK},
+		{"limit=10&offset=0", http.StatusOK},
+		{"sort=-publishedDate", http.StatusOK},
+		{"sort=updatedAt,-publishedDate", http.StatusOK},
+		{"limit=-1", http.StatusBadRequest},
+		{"offset=-10", http.StatusBadRequest},
+		{"limit=ten", http.StatusBadRequest},
+		{"offset=", http.StatusBadRequest},
+		{"sort=password", http.StatusBadRequest},
+		{"sort=-publishedDate,$where", http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/posts?"+tc.query, nil))
+		if resp.Code != tc.code {
+			t.Errorf("query %s: expect status %d, got %d", tc.query, tc.code, resp.Code)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RedactedPlaceholder replaces the values of the secrets in the logs
+const RedactedPlaceholder = "[REDACTED]"
+
+// Redactor redacts the secrets of the requests, e.g. the tokens in Authorization header or `code` query param,
+// so that the requests are logged safely.
+type Redactor struct {
+	headers map[string]bool
+	params  map[string]bool
+}
+
+// NewRedactor returns a Redactor of the headers and the query params, which are matched case-insensitively
+func NewRedactor(headers, params []string) *Redactor {
+	r := &Redactor{headers: make(map[string]bool), params: make(map[string]bool)}
+	for _, header := range headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, param := range params {
+		r.params[strings.ToLower(param)] = true
+	}
+	return r
+}
+
+// RedactQuery returns the raw query whose values of the redacted params are replaced with RedactedPlaceholder
+func (r *Redactor) RedactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	pairs := strings.Split(rawQuery, "&")
+	for i, pair := range pairs {
+		key := strings.SplitN(pair, "=", 2)[0]
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if r.params[strings.ToLower(key)] {
+			// the placeholder is kept unescaped for the readability of the logs
+			pairs[i] = strings.SplitN(pair, "=", 2)[0] + "=" + RedactedPlaceholder
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// RedactRequest returns a shallow copy of the request whose redacted headers and query params are replaced,
+// while the original request is left as it is.
+func (r *Redactor) RedactRequest(req *http.Request) *http.Request {
+	redacted := new(http.Request)
+	*redacted = *req
+
+	u := *req.URL
+	u.RawQuery = r.RedactQuery(req.URL.RawQuery)
+	redacted.URL = &u
+	redacted.RequestURI = u.RequestURI()
+
+	redacted.Header = make(http.Header, len(req.Header))
+	for key, values := range req.Header {
+		if r.headers[http.CanonicalHeaderKey(key)] {
+			values = []string{RedactedPlaceholder}
+		}
+		redacted.Header[key] = values
+	}
+	return redacted
+}
+
+// LogFormatter wraps the formatter of gin logger, which formats the request redacted
+func (r *Redactor) LogFormatter(formatter gin.LogFormatter) gin.LogFormatter {
+	return func(params gin.LogFormatterParams) string {
+		if params.Request != nil {
+			params.Request = r.RedactRequest(params.Request)
+			params.Path = params.Request.URL.Path
+			if params.Request.URL.RawQuery != "" {
+				params.Path += "?" + params.Request.URL.RawQuery
+			}
+		}
+		return formatter(params)
+	}
+}
+
+// DevLogFormatter is the same format as the default logger of gin
+func DevLogFormatter(params gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if params.IsOutputColor() {
+		statusColor = params.StatusCodeColor()
+		methodColor = params.MethodColor()
+		resetColor = params.ResetColor()
+	}
+
+	if params.Latency > time.Minute {
+		params.Latency = params.Latency - params.Latency%time.Second
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %s\n%s",
+		params.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, params.StatusCode, resetColor,
+		params.Latency,
+		params.ClientIP,
+		methodColor, params.Method, resetColor,
+		params.Path,
+		params.ErrorMessage,
+	)
+}
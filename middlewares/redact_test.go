@@ -0,0 +1,122 @@
+package middlewares
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+const (
+	mockToken = "mock-secret-token"
+	mockCode  = "mock-secret-code"
+)
+
+func newMockRedactor() *Redactor {
+	return NewRedactor([]string{"Authorization", "cookie"}, []string{"token", "code", "access_token"})
+}
+
+func TestRedactQuery(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Given no query", in: "", want: ""},
+		{name: "Given no redacted params", in: "limit=10&offset=0", want: "limit=10&offset=0"},
+		{name: "Given redacted params", in: "code=abc&state=xyz&Access_Token=def", want: "code=[REDACTED]&state=xyz&Access_Token=[REDACTED]"},
+		{name: "Given an escaped redacted param", in: "%74oken=abc", want: "%74oken=[REDACTED]"},
+		{name: "Given a redacted param without value", in: "token", want: "token=[REDACTED]"},
+	}
+
+	r := newMockRedactor()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := r.RedactQuery(tc.in); got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLogRequestRedacted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger, hook := test.NewNullLogger()
+	engine := gin.New()
+	engine.Use(LogRequest(logger, 0, newMockRedactor()))
+	engine.GET("/oauth/google/callback", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Query("code"))
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/oauth/google/callback?code="+mockCode+"&state=mock-state", nil)
+	resp := httptest.NewRecorder()
+	engine.ServeHTTP(resp, req)
+
+	entry := hook.LastEntry()
+	if entry == nil {
+		t.Fatal("expected the request is logged")
+	}
+	line, _ := entry.String()
+	if strings.Contains(line, mockCode) {
+		t.Errorf("expected the code is redacted, got %s", line)
+	}
+	if want := "code=" + RedactedPlaceholder + "&state=mock-state"; entry.Data["query"] != want {
+		t.Errorf("expected query to be %s, got %v", want, entry.Data["query"])
+	}
+	if resp.Body.String() != mockCode {
+		t.Errorf("expected the handler gets the original code, got %s", resp.Body.String())
+	}
+}
+
+func TestLogFormatterRedacted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var params gin.LogFormatterParams
+	var buf bytes.Buffer
+	formatter := newMockRedactor().LogFormatter(func(p gin.LogFormatterParams) string {
+		params = p
+		return DevLogFormatter(p)
+	})
+
+	engine := gin.New()
+	engine.Use(gin.LoggerWithConfig(gin.LoggerConfig{Formatter: formatter, Output: &buf}))
+	engine.GET("/v1/activate", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetHeader("Authorization"))
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/activate?email=abc%40twreporter.org&token="+mockToken, nil)
+	req.Header.Set("Authorization", "Bearer "+mockToken)
+	req.Header.Set("Cookie", "id_token="+mockToken)
+	req.Header.Set("User-Agent", "test-agent")
+	resp := httptest.NewRecorder()
+	engine.ServeHTTP(resp, req)
+
+	if strings.Contains(buf.String(), mockToken) {
+		t.Errorf("expected the token is redacted, got %s", buf.String())
+	}
+	if want := "/v1/activate?email=abc%40twreporter.org&token=" + RedactedPlaceholder; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected the log contains %s, got %s", want, buf.String())
+	}
+
+	for header, want := range map[string]string{
+		"Authorization": RedactedPlaceholder,
+		"Cookie":        RedactedPlaceholder,
+		"User-Agent":    "test-agent",
+	} {
+		if got := params.Request.Header.Get(header); got != want {
+			t.Errorf("expected %s header to be %s, got %s", header, want, got)
+		}
+	}
+
+	if req.Header.Get("Authorization") != "Bearer "+mockToken || !strings.Contains(req.URL.RawQuery, mockToken) {
+		t.Errorf("expected the original request is left as it is")
+	}
+	if resp.Body.String() != "Bearer "+mockToken {
+		t.Errorf("expected the handler gets the original header, got %s", resp.Body.String())
+	}
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PrefixRewrite serves the paths with Prefix by the routes under Target instead,
+// e.g. `/v1/posts/by-date/2020/06` by `/_rewritten/v1/posts-by-date/:year/:month`.
+// gin does not allow the wildcard along with static paths at the same position,
+// so the routes conflicting with the others are registered under the target and rewritten.
+type PrefixRewrite struct {
+	Prefix string
+	Target string
+}
+
+// RewritePrefixes wraps the handler of the server and rewrites the paths before they are routed,
+// so the global middlewares of gin run once along with the target routes.
+// The trailing slash of the rewritten paths is trimmed, since the targets are not reachable by the redirects.
+// The paths under internalPrefix, which the targets should be under, are responded 404 if they are requested directly.
+func RewritePrefixes(h http.Handler, internalPrefix string, rewrites ...PrefixRewrite) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if strings.HasPrefix(path, internalPrefix) {
+			http.NotFound(w, r)
+			return
+		}
+
+		for _, rewrite := range rewrites {
+			if !strings.HasPrefix(path, rewrite.Prefix) {
+				continue
+			}
+
+			// the request is copied as http.StripPrefix does, since the handlers should not modify the request
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = rewrite.Target + strings.TrimRight(strings.TrimPrefix(path, rewrite.Prefix), "/")
+			r2.URL.RawPath = ""
+			h.ServeHTTP(w, r2)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,74 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRewritePrefixes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+
+	var calls int
+	engine.Use(func(c *gin.Context) {
+		calls++
+	})
+	engine.GET("/v1/posts/:slug", func(c *gin.Context) {
+		c.String(http.StatusOK, "post "+c.Param("slug"))
+	})
+	engine.GET("/v1/posts/:slug/next", func(c *gin.Context) {
+		c.String(http.StatusOK, "next of "+c.Param("slug"))
+	})
+	engine.GET("/_rewritten/v1/posts-by-date/:year/:month", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Param("year")+"/"+c.Param("month")+" limit "+c.Query("limit"))
+	})
+	h := RewritePrefixes(engine, "/_rewritten", PrefixRewrite{Prefix: "/v1/posts/by-date/", Target: "/_rewritten/v1/posts-by-date/"})
+
+	cases := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Given the path with the prefix", path: "/v1/posts/by-date/2020/06?limit=5", wantCode: http.StatusOK, wantBody: "2020/06 limit 5"},
+		{name: "Given the path with the prefix and trailing slash", path: "/v1/posts/by-date/2020/06/", wantCode: http.StatusOK, wantBody: "2020/06 limit "},
+		{name: "Given the route matched without rewriting", path: "/v1/posts/by-date", wantCode: http.StatusOK, wantBody: "post by-date"},
+		{name: "Given the route of the slug", path: "/v1/posts/mock-slug/next", wantCode: http.StatusOK, wantBody: "next of mock-slug"},
+		{name: "Given the path with the prefix not routed", path: "/v1/posts/by-date/2020", wantCode: http.StatusNotFound},
+		{name: "Given the target requested directly", path: "/_rewritten/v1/posts-by-date/2020/06", wantCode: http.StatusNotFound},
+		{name: "Given the path without the prefix not routed", path: "/v1/unknown", wantCode: http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = 0
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			h.ServeHTTP(resp, req)
+
+			if resp.Code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, resp.Code)
+			}
+			if tc.wantBody != "" && resp.Body.String() != tc.wantBody {
+				t.Errorf("expect body %s, but got %s", tc.wantBody, resp.Body.String())
+			}
+			if tc.wantCode == http.StatusOK && calls != 1 {
+				t.Errorf("expect the global middlewares run once, but got %d", calls)
+			}
+			if req.URL.Path != stripQuery(tc.path) {
+				t.Errorf("expect the request of the client kept, but got %s", req.URL.Path)
+			}
+		})
+	}
+}
+
+func stripQuery(path string) string {
+	if i := strings.Index(path, "?"); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/utils"
+)
+
+// ValidateSignedURL checks the request url is signed by utils.SignedURL and not expired,
+// it is used to gate the protected assets, e.g. member-only topic attachments.
+func ValidateSignedURL() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := utils.VerifySignedURL(c.Request.URL); err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"status": "fail",
+				"data": gin.H{
+					"req.URL.signature": errors.Cause(err).Error(),
+				},
+			})
+			return
+		}
+	}
+}
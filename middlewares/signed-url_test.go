@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/utils"
+)
+
+func TestValidateSignedURL(t *testing.T) {
+	const path = "/attachments/report.pdf"
+
+	cases := []struct {
+		name string
+		url  string
+		want int
+	}{
+		{name: "Given a valid signed url", url: utils.SignedURL(path, time.Now().Add(time.Hour)), want: http.StatusOK},
+		{name: "Given an expired signed url", url: utils.SignedURL(path, time.Now().Add(-time.Hour)), want: http.StatusForbidden},
+		{name: "Given a tampered signed url", url: strings.Replace(utils.SignedURL(path, time.Now().Add(time.Hour)), "signature=", "signature=0", 1), want: http.StatusForbidden},
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET(path, ValidateSignedURL(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tc.url, nil)
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != tc.want {
+				t.Errorf("expected status %d, got %d", tc.want, resp.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+)
+
+// timeoutWriter discards the response written after the deadline of the request,
+// so that the timeout response is not mixed up with the one of the handler.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+}
+
+func (w *timeoutWriter) timedOut() bool {
+	return w.ctx.Err() == context.DeadlineExceeded
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	if !w.timedOut() {
+		return w.ResponseWriter.Write(b)
+	}
+	// the response has been sent partially, fail the streaming handlers to stop them
+	if w.ResponseWriter.Written() {
+		return 0, http.ErrHandlerTimeout
+	}
+	// the response is going to be replaced by the timeout response.
+	// no error is returned, since gin panics if rendering fails.
+	return len(b), nil
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Timeout sets the deadline of the request context to d, and responds 504 if the route exceeds it.
+// Go cannot stop a running handler, it is the context-aware handlers and storage calls,
+// which use `c.Request.Context()`, giving up once the deadline is exceeded.
+// The handlers calling the clients without context support have to run the calls apart and return at the deadline,
+// otherwise the timeout response waits for the calls.
+// The response written by the handler after the deadline is discarded.
+// Zero or negative d means no timeout.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		// keep the headers set before the handler, the ones set by the handler
+		// (e.g. Content-Encoding) do not apply to the timeout response
+		header := c.Writer.Header()
+		original := make(http.Header, len(header))
+		for k, v := range header {
+			original[k] = v
+		}
+
+		tw := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = tw
+
+		c.Next()
+
+		c.Writer = tw.ResponseWriter
+		if !tw.timedOut() || c.Writer.Written() {
+			return
+		}
+
+		for k := range header {
+			delete(header, k)
+		}
+		for k, v := range original {
+			header[k] = v
+		}
+
+		c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+			"status":  "error",
+			"code":    models.ErrCodeRequestTimeout,
+			"message": "Request timeout.",
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twreporter.org/go-api/models"
+)
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// queryStorage simulates a context-aware storage call taking `latency`
+	queryStorage := func(c *gin.Context, latency time.Duration) error {
+		select {
+		case <-time.After(latency):
+			return nil
+		case <-c.Request.Context().Done():
+			return c.Request.Context().Err()
+		}
+	}
+
+	engine := gin.New()
+	engine.GET("/slow", Timeout(20*time.Millisecond), func(c *gin.Context) {
+		if err := queryStorage(c, time.Second); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	engine.GET("/fast", Timeout(time.Second), func(c *gin.Context) {
+		if err := queryStorage(c, time.Millisecond); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	engine.GET("/stream", Timeout(20*time.Millisecond), func(c *gin.Context) {
+		c.Header("Content-Encoding", "gzip")
+		c.Status(http.StatusOK)
+		queryStorage(c, time.Second)
+		// the writes after the deadline are discarded
+		io.WriteString(c.Writer, "partial")
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Given a handler exceeding the route timeout", func(t *testing.T) {
+		start := time.Now()
+		w := serve("/slow")
+
+		if latency := time.Since(start); latency > 500*time.Millisecond {
+			t.Errorf("expected the storage call to give up at the deadline, took %s", latency)
+		}
+
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("expected status %d, got %d", http.StatusGatewayTimeout, w.Code)
+		}
+
+		var body map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("expected a clean JSON body, got %q", w.Body.String())
+		}
+		if body["code"] != models.ErrCodeRequestTimeout {
+			t.Errorf("expected code %s, got %s", models.ErrCodeRequestTimeout, body["code"])
+		}
+	})
+
+	t.Run("Given a handler finishing in time", func(t *testing.T) {
+		w := serve("/fast")
+
+		if w.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if strings.TrimSpace(w.Body.String()) != `{"status":"ok"}` {
+			t.Errorf("expected the response of the handler, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("Given a handler setting headers before exceeding the route timeout", func(t *testing.T) {
+		w := serve("/stream")
+
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("expected status %d, got %d", http.StatusGatewayTimeout, w.Code)
+		}
+		if encoding := w.Header().Get("Content-Encoding"); encoding != "" {
+			t.Errorf("expected the headers of the handler dropped, got Content-Encoding %s", encoding)
+		}
+		if strings.Contains(w.Body.String(), "partial") {
+			t.Errorf("expected the response of the handler discarded, got %q", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// TrailingSlashRedirect redirects the paths with trailing slash to the canonical routes
+	TrailingSlashRedirect = "redirect"
+	// TrailingSlashRewrite serves the paths with trailing slash by the canonical routes directly
+	TrailingSlashRewrite = "rewrite"
+)
+
+// matchRoute reports whether the path matches the route pattern of gin, e.g. `/v1/posts/:slug`
+func matchRoute(pattern, path string) bool {
+	patterns := strings.Split(pattern, "/")
+	segments := strings.Split(path, "/")
+
+	for i, p := range patterns {
+		if strings.HasPrefix(p, "*") {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if strings.HasPrefix(p, ":") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if p != segments[i] {
+			return false
+		}
+	}
+	return len(patterns) == len(segments)
+}
+
+// HandleTrailingSlash handles the paths with trailing slash, e.g. `/v1/topics/`,
+// by the canonical routes without trailing slash, while the other paths not found are responded 404 as usual.
+// GET and HEAD are redirected with 301, and the others are redirected with 308 to preserve the method and body.
+// If mode is TrailingSlashRewrite, the canonical routes handle the requests without redirecting,
+// and the global middlewares run again along with the canonical routes.
+// It should be the NoRoute handler of the engine, whose RedirectTrailingSlash is disabled.
+func HandleTrailingSlash(engine *gin.Engine, mode string) gin.HandlerFunc {
+	var once sync.Once
+	var routes gin.RoutesInfo
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		canonical := strings.TrimRight(path, "/")
+		if canonical == path || canonical == "" {
+			return
+		}
+
+		// the routes are all registered once the requests are served
+		once.Do(func() {
+			routes = engine.Routes()
+		})
+
+		found := false
+		for _, route := range routes {
+			if route.Method == c.Request.Method && matchRoute(route.Path, canonical) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+
+		if mode == TrailingSlashRewrite {
+			c.Request.URL.Path = canonical
+			engine.HandleContext(c)
+			return
+		}
+
+		code := http.StatusPermanentRedirect
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+
+		location := *c.Request.URL
+		location.Path = canonical
+		location.RawPath = ""
+		c.Redirect(code, location.RequestURI())
+	}
+}
@@ -0,0 +1,126 @@
+package middlewares
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTrailingSlashEngine(mode string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.RedirectTrailingSlash = false
+	engine.NoRoute(HandleTrailingSlash(engine, mode))
+
+	engine.GET("/v1/topics", func(c *gin.Context) {
+		c.String(http.StatusOK, "topics")
+	})
+	engine.GET("/v1/posts/:slug", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Param("slug"))
+	})
+	engine.POST("/v1/users/:userID/bookmarks", func(c *gin.Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, string(body))
+	})
+	return engine
+}
+
+func TestHandleTrailingSlash(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     string
+		method   string
+		path     string
+		wantCode int
+		// wantLocation is checked for the redirections, otherwise the body is checked
+		wantLocation string
+		wantBody     string
+	}{
+		{
+			name:         "Given a GET with trailing slash",
+			mode:         TrailingSlashRedirect,
+			method:       http.MethodGet,
+			path:         "/v1/topics/?limit=10",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/v1/topics?limit=10",
+		},
+		{
+			name:         "Given a GET of a wildcard route with trailing slash",
+			mode:         TrailingSlashRedirect,
+			method:       http.MethodGet,
+			path:         "/v1/posts/mock-slug/",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/v1/posts/mock-slug",
+		},
+		{
+			name:         "Given a POST with trailing slash",
+			mode:         TrailingSlashRedirect,
+			method:       http.MethodPost,
+			path:         "/v1/users/1/bookmarks/",
+			wantCode:     http.StatusPermanentRedirect,
+			wantLocation: "/v1/users/1/bookmarks",
+		},
+		{
+			name:     "Given a GET with trailing slash rewritten",
+			mode:     TrailingSlashRewrite,
+			method:   http.MethodGet,
+			path:     "/v1/topics/",
+			wantCode: http.StatusOK,
+			wantBody: "topics",
+		},
+		{
+			name:     "Given a POST with trailing slash rewritten",
+			mode:     TrailingSlashRewrite,
+			method:   http.MethodPost,
+			path:     "/v1/users/1/bookmarks/",
+			wantCode: http.StatusCreated,
+			wantBody: `{"slug":"mock-slug"}`,
+		},
+		{
+			name:     "Given a path with trailing slash not routed",
+			mode:     TrailingSlashRedirect,
+			method:   http.MethodGet,
+			path:     "/v1/unknown/",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Given a method not routed",
+			mode:     TrailingSlashRedirect,
+			method:   http.MethodPost,
+			path:     "/v1/topics/",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Given the canonical path",
+			mode:     TrailingSlashRedirect,
+			method:   http.MethodGet,
+			path:     "/v1/topics",
+			wantCode: http.StatusOK,
+			wantBody: "topics",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := newTrailingSlashEngine(tc.mode)
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{"slug":"mock-slug"}`))
+			engine.ServeHTTP(resp, req)
+
+			if resp.Code != tc.wantCode {
+				t.Fatalf("expect status %d, but got %d", tc.wantCode, resp.Code)
+			}
+			if tc.wantLocation != "" && resp.Header().Get("Location") != tc.wantLocation {
+				t.Errorf("expect location %s, but got %s", tc.wantLocation, resp.Header().Get("Location"))
+			}
+			if tc.wantBody != "" && resp.Body.String() != tc.wantBody {
+				t.Errorf("expect body %s, but got %s", tc.wantBody, resp.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	headerXForwardedFor = "X-Forwarded-For"
+	headerXRealIP       = "X-Real-Ip"
+)
+
+// parseTrustedProxies parses IPs or CIDRs of the trusted proxies
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var cidrs []*net.IPNet
+
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, errors.New(fmt.Sprintf("invalid trusted proxy: %s", proxy))
+			}
+
+			bits := net.IPv6len * 8
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = net.IPv4len * 8
+			}
+			cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, cidr, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("invalid trusted proxy: %s", proxy))
+		}
+		cidrs = append(cidrs, cidr)
+	}
+
+	return cidrs, nil
+}
+
+func isTrustedProxy(cidrs []*net.IPNet, ip net.IP) bool {
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTrustedProxies makes `c.ClientIP()` aware of the trusted proxies.
+// gin v1.5 trusts `X-Forwarded-For` and `X-Real-Ip` headers of any request, which could be spoofed by clients.
+// The returned middleware removes these headers if the request is not from the trusted proxies,
+// otherwise, it reduces `X-Forwarded-For` to the right-most IP which is not a trusted proxy.
+// Hence, `c.ClientIP()` returns either the client IP reported by trusted proxies or the remote address.
+func SetTrustedProxies(proxies []string) (gin.HandlerFunc, error) {
+	cidrs, err := parseTrustedProxies(proxies)
+
+	return func(c *gin.Context) {
+		header := c.Request.Header
+
+		host, _, splitErr := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+		remoteIP := net.ParseIP(host)
+		if splitErr != nil || remoteIP == nil || !isTrustedProxy(cidrs, remoteIP) {
+			header.Del(headerXForwardedFor)
+			header.Del(headerXRealIP)
+			return
+		}
+
+		forwarded := header.Get(headerXForwardedFor)
+		if forwarded == "" {
+			return
+		}
+
+		// walk through the chain from the nearest proxy,
+		// the first IP which is not a trusted proxy is the client IP.
+		// If the chain is malformed, the last valid IP is used.
+		clientIP := remoteIP.String()
+		ips := strings.Split(forwarded, ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(ips[i]))
+			if ip == nil {
+				break
+			}
+
+			clientIP = ip.String()
+			if !isTrustedProxy(cidrs, ip) {
+				break
+			}
+		}
+
+		header.Set(headerXForwardedFor, clientIP)
+	}, err
+}
@@ -0,0 +1,94 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetTrustedProxies(t *testing.T) {
+	cases := []struct {
+		name          string
+		proxies       []string
+		remoteAddr    string
+		xForwardedFor string
+		xRealIP       string
+		want          string
+	}{
+		{
+			name:          "Given a spoofed X-Forwarded-For from an untrusted client",
+			proxies:       []string{"10.0.0.0/8"},
+			remoteAddr:    "203.0.113.10:5678",
+			xForwardedFor: "1.2.3.4",
+			want:          "203.0.113.10",
+		},
+		{
+			name:       "Given a spoofed X-Real-Ip from an untrusted client",
+			proxies:    []string{"10.0.0.0/8"},
+			remoteAddr: "203.0.113.10:5678",
+			xRealIP:    "1.2.3.4",
+			want:       "203.0.113.10",
+		},
+		{
+			name:          "Given X-Forwarded-For from a trusted proxy",
+			proxies:       []string{"10.0.0.0/8"},
+			remoteAddr:    "10.0.0.1:5678",
+			xForwardedFor: "203.0.113.10",
+			want:          "203.0.113.10",
+		},
+		{
+			name:          "Given a spoofed X-Forwarded-For forwarded by a trusted proxy",
+			proxies:       []string{"10.0.0.0/8"},
+			remoteAddr:    "10.0.0.1:5678",
+			xForwardedFor: "1.2.3.4, 203.0.113.10",
+			want:          "203.0.113.10",
+		},
+		{
+			name:          "Given X-Forwarded-For through multiple trusted proxies",
+			proxies:       []string{"10.0.0.0/8", "192.168.0.1"},
+			remoteAddr:    "10.0.0.1:5678",
+			xForwardedFor: "203.0.113.10, 192.168.0.1",
+			want:          "203.0.113.10",
+		},
+		{
+			name:          "Given no trusted proxies",
+			proxies:       nil,
+			remoteAddr:    "10.0.0.1:5678",
+			xForwardedFor: "203.0.113.10",
+			want:          "10.0.0.1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, err := SetTrustedProxies(tc.proxies)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+			c.Request.RemoteAddr = tc.remoteAddr
+			if tc.xForwardedFor != "" {
+				c.Request.Header.Set("X-Forwarded-For", tc.xForwardedFor)
+			}
+			if tc.xRealIP != "" {
+				c.Request.Header.Set("X-Real-Ip", tc.xRealIP)
+			}
+
+			handler(c)
+
+			if got := c.ClientIP(); got != tc.want {
+				t.Errorf("expected client IP %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSetTrustedProxiesInvalidProxy(t *testing.T) {
+	if _, err := SetTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Errorf("expected error for invalid trusted proxy")
+	}
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `post_state_logs` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `deleted_at` timestamp NULL DEFAULT NULL,
+  `post_slug` varchar(100) NOT NULL,
+  `from_state` varchar(20) NOT NULL,
+  `to_state` varchar(20) NOT NULL,
+  `note` varchar(512) DEFAULT NULL,
+  `user_id` int(10) unsigned NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_post_state_logs_post_slug` (`post_slug`),
+  KEY `idx_post_state_logs_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1 @@
+ALTER TABLE `o_auth_accounts` DROP COLUMN `version`;
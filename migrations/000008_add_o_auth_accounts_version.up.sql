@@ -0,0 +1 @@
+ALTER TABLE `o_auth_accounts` ADD COLUMN `version` int(10) unsigned NOT NULL DEFAULT 0;
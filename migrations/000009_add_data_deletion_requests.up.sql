@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `data_deletion_requests` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `confirmation_code` varchar(64) NOT NULL,
+  `type` varchar(10) NOT NULL,
+  `a_id` varchar(255) NOT NULL,
+  `status` varchar(20) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uix_data_deletion_requests_confirmation_code` (`confirmation_code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
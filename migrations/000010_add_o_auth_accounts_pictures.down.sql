@@ -0,0 +1 @@
+ALTER TABLE `o_auth_accounts` DROP COLUMN `picture_small`, DROP COLUMN `picture_medium`, DROP COLUMN `picture_large`;
@@ -0,0 +1 @@
+ALTER TABLE `o_auth_accounts` ADD COLUMN `picture_small` varchar(255) DEFAULT NULL, ADD COLUMN `picture_medium` varchar(255) DEFAULT NULL, ADD COLUMN `picture_large` varchar(255) DEFAULT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `user_block_logs`;
+ALTER TABLE `users` DROP COLUMN `blocked`, DROP COLUMN `block_reason`;
@@ -0,0 +1,14 @@
+ALTER TABLE `users` ADD COLUMN `blocked` tinyint(1) NOT NULL DEFAULT 0, ADD COLUMN `block_reason` varchar(512) DEFAULT NULL;
+CREATE TABLE IF NOT EXISTS `user_block_logs` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `deleted_at` timestamp NULL DEFAULT NULL,
+  `user_id` int(10) unsigned NOT NULL,
+  `action` varchar(20) NOT NULL,
+  `reason` varchar(512) DEFAULT NULL,
+  `admin_id` int(10) unsigned NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_block_logs_user_id` (`user_id`),
+  KEY `idx_user_block_logs_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
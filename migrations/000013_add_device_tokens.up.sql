@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS `device_tokens` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `user_id` int(10) unsigned NOT NULL,
+  `token` varchar(255) NOT NULL,
+  `platform` varchar(10) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_device_tokens_token` (`token`),
+  KEY `idx_device_tokens_user_id` (`user_id`),
+  CONSTRAINT `fk_device_tokens_users1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE NO ACTION
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+CREATE TABLE IF NOT EXISTS `category_subscriptions` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `user_id` int(10) unsigned NOT NULL,
+  `category_id` varchar(24) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_category_subscriptions_user_id_category_id` (`user_id`, `category_id`),
+  KEY `idx_category_subscriptions_category_id` (`category_id`),
+  CONSTRAINT `fk_category_subscriptions_users1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE NO ACTION
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
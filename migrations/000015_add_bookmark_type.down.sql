@@ -0,0 +1 @@
+ALTER TABLE `bookmarks` DROP `type`;
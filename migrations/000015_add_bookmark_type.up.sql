@@ -0,0 +1 @@
+ALTER TABLE `bookmarks` ADD `type` varchar(20) NOT NULL DEFAULT 'article';
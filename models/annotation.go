@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Annotation is the editorial note, e.g. the fact-check, of a paragraph of the post,
+// which is overlaid on the content by the frontend
+type Annotation struct {
+	ID             bson.ObjectId `bson:"_id" json:"id"`
+	PostSlug       string        `bson:"postSlug" json:"post_slug"`
+	ParagraphIndex int           `bson:"paragraphIndex" json:"paragraph_index"`
+	Note           string        `bson:"note" json:"note"`
+	AuthorID       uint          `bson:"authorId" json:"author_id"`
+	CreatedAt      time.Time     `bson:"createdAt" json:"created_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// The machine-readable codes of AppError, which clients can branch on.
+// The codes are stable, new codes should be appended here rather than defined elsewhere.
+const (
+	ErrCodeInternal          = "internal_error"
+	ErrCodeRecordNotFound    = "record_not_found"
+	ErrCodeRecordConflict    = "record_conflict"
+	ErrCodeUpstreamTimeout   = "upstream_timeout"
+	ErrCodeServiceReadOnly   = "service_read_only"
+	ErrCodeOAuthStateInvalid = "oauth_state_invalid"
+	ErrCodeRequestTimeout    = "request_timeout"
+
+	ErrCodeOAuthScopesNotGranted = "oauth_scopes_not_granted"
+	ErrCodeRegistrationClosed    = "registration_closed"
+	ErrCodeOAuthExchangeFailed   = "oauth_exchange_failed"
+	ErrCodeOAuthConfigInvalid    = "oauth_config_invalid"
+	ErrCodeAccountBlocked        = "account_blocked"
+)
+
+// AppError is the error which decides how it is responded to the client
+type AppError struct {
+	// Code is one of the ErrCode constants
+	Code       string `json:"code"`
+	StatusCode int    `json:"-"`
+	Message    string `json:"message"`
+	// RetryAfter is responded in Retry-After header if it is not zero
+	RetryAfter time.Duration `json:"-"`
+	// Err is the original error
+	Err error `json:"-"`
+}
+
+func (e *AppError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s(%s)", e.Message, e.Code)
+	}
+	return fmt.Sprintf("%s(%s): %s", e.Message, e.Code, e.Err.Error())
+}
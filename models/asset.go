@@ -132,15 +132,32 @@ type FullAuthor struct {
 
 // Category ...
 type Category struct {
-	ID        bson.ObjectId `bson:"_id" json:"id"`
-	SortOrder uint          `bson:"sort_order" json:"sort_order"`
-	Name      string        `bson:"name" json:"name"`
+	ID              bson.ObjectId `bson:"_id" json:"id"`
+	Slug            string        `bson:"slug" json:"slug"`
+	SortOrder       uint          `bson:"sort_order" json:"sort_order"`
+	Name            string        `bson:"name" json:"name"`
+	Description     string        `bson:"description" json:"description"`
+	PostCount       int           `bson:"postCount" json:"post_count"`
+	HeroImage       *Image        `bson:"-" json:"hero_image,omitempty"`
+	HeroImageOrigin bson.ObjectId `bson:"heroImage,omitempty" json:"-"`
 }
 
 // Tag ...
 type Tag struct {
-	ID   bson.ObjectId `bson:"_id" json:"id"`
-	Name string        `bson:"name" json:"name"`
+	ID          bson.ObjectId `bson:"_id" json:"id"`
+	Slug        string        `bson:"slug" json:"slug"`
+	Name        string        `bson:"name" json:"name"`
+	Description string        `bson:"description" json:"description"`
+	// PostCount is the number of posts referencing the tag, which is maintained while upserting posts
+	PostCount       int           `bson:"postCount" json:"post_count"`
+	HeroImage       *Image        `bson:"-" json:"hero_image,omitempty"`
+	HeroImageOrigin bson.ObjectId `bson:"heroImage,omitempty" json:"-"`
+}
+
+// TagCount is the tag along with the number of the topics referencing it
+type TagCount struct {
+	Tag        `bson:",inline"`
+	TopicCount int `bson:"topicCount" json:"topic_count"`
 }
 
 // NewsEntity defines the method of structs such `Topic`, `Post` ...etc
@@ -261,7 +278,7 @@ func (t *Topic) GetEmbeddedAsset(key string) []bson.ObjectId {
 	case "CategoriesOrigin":
 		return nil
 	case "TagsOrigin":
-		return nil
+		return t.TagsOrigin
 	case "ThemeOrigin":
 		return nil
 	case "OgImageOrigin":
@@ -21,4 +21,27 @@ type Bookmark struct {
 	Thumbnail  string     `gorm:"size:512" json:"thumbnail" form:"thumbnail" binding:"required"`
 	Authors    string     `gorm:"size:250" json:"authors" form:"authors"`
 	PubDate    uint       `gorm:"not null;default:0" json:"published_date" form:"published_date"`
+	Type       string     `gorm:"size:20;not null;default:'article'" json:"type" form:"type" binding:"omitempty,oneof=article topic"`
+}
+
+const (
+	// BookmarkTypeArticle is the type of the bookmarks of articles, which is the default type
+	BookmarkTypeArticle = "article"
+	// BookmarkTypeTopic is the type of the bookmarks of topics
+	BookmarkTypeTopic = "topic"
+)
+
+const (
+	// BookmarkImportAdded means the bookmark is added to the user
+	BookmarkImportAdded = "added"
+	// BookmarkImportSkipped means the user has bookmarked it already
+	BookmarkImportSkipped = "skipped"
+	// BookmarkImportInvalid means there is no published post of the slug
+	BookmarkImportInvalid = "invalid"
+)
+
+// BookmarkImportResult is the result of importing the bookmark of the slug
+type BookmarkImportResult struct {
+	Slug   string `json:"slug"`
+	Status string `json:"status"`
 }
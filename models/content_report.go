@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// The reasons of the content reports
+const (
+	ReportReasonMisinformation = "misinformation"
+	ReportReasonHarassment     = "harassment"
+	ReportReasonSpam           = "spam"
+	ReportReasonOther          = "other"
+)
+
+// The statuses of the content reports
+const (
+	ReportStatusPending  = "pending"
+	ReportStatusResolved = "resolved"
+)
+
+// ContentReport is the problematic content of the post flagged by the reader
+type ContentReport struct {
+	ID        bson.ObjectId `bson:"_id" json:"id"`
+	CreatedAt time.Time     `bson:"createdAt" json:"created_at"`
+	PostSlug  string        `bson:"postSlug" json:"post_slug"`
+	UserID    uint          `bson:"userId" json:"user_id"`
+	Reason    string        `bson:"reason" json:"reason"`
+	Details   string        `bson:"details,omitempty" json:"details"`
+	Status    string        `bson:"status" json:"status"`
+}
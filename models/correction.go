@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// CorrectionSeverityMinor is the correction of the details, e.g. typos of the names
+	CorrectionSeverityMinor = "minor"
+	// CorrectionSeverityMajor is the correction of the facts affecting the report
+	CorrectionSeverityMajor = "major"
+	// CorrectionSeverityRetraction withdraws the report
+	CorrectionSeverityRetraction = "retraction"
+)
+
+// Correction is the published correction, i.e. erratum, of the post
+type Correction struct {
+	ID       bson.ObjectId `bson:"_id" json:"id"`
+	PostSlug string        `bson:"postSlug" json:"post_slug"`
+	// IssuedAt is the time the correction is announced
+	IssuedAt time.Time `bson:"issuedAt" json:"issued_at"`
+	// CorrectedAt is the time the content of the post is corrected, nil if it is not corrected yet
+	CorrectedAt *time.Time `bson:"correctedAt,omitempty" json:"corrected_at"`
+	Description string     `bson:"description" json:"description"`
+	// Severity is one of CorrectionSeverityMinor, CorrectionSeverityMajor and CorrectionSeverityRetraction
+	Severity  string    `bson:"severity" json:"severity"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	DataDeletionStatusPending   = "pending"
+	DataDeletionStatusCompleted = "completed"
+	DataDeletionStatusFailed    = "failed"
+)
+
+// DataDeletionRequest is the request to delete the user data associated with an OAuth account,
+// which is sent by the OAuth service, e.g. the data deletion callback of Facebook.
+type DataDeletionRequest struct {
+	ID               uint      `gorm:"primary_key" json:"-"`
+	CreatedAt        time.Time `json:"requested_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	ConfirmationCode string    `gorm:"size:64;not null;unique_index" json:"confirmation_code"`
+	Type             string    `gorm:"size:10;not null" json:"-"` // Facebook / Google ...
+	AId              string    `gorm:"not null" json:"-"`         // user ID returned by OAuth services
+	Status           string    `gorm:"size:20;not null" json:"status"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	DeviceTokenPlatformAndroid = "android"
+	DeviceTokenPlatformIOS     = "ios"
+)
+
+// DeviceToken is the FCM registration token of the mobile app installed by the user,
+// which receives the push notifications of the posts in the subscribed categories
+type DeviceToken struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	Token     string    `gorm:"size:255;unique;not null" json:"token"`
+	Platform  string    `gorm:"size:10;not null" json:"platform"`
+}
+
+// CategorySubscription is the category of which the user is notified of the published posts
+type CategorySubscription struct {
+	ID         uint      `gorm:"primary_key" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UserID     uint      `gorm:"not null" json:"user_id"`
+	CategoryID string    `gorm:"size:24;not null" json:"category_id"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Footnote is the numbered note of the post, which is referred by the index in the content
+type Footnote struct {
+	Index int    `bson:"index" json:"index"`
+	Text  string `bson:"text" json:"text"`
+	// References are the URLs of the sources of the footnote
+	References []string `bson:"references,omitempty" json:"references"`
+}
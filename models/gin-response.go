@@ -1,7 +1,56 @@
 package models
 
+import (
+	"encoding/json"
+	"strconv"
+)
+
 type MetaOfResponse struct {
 	Total  int `json:"total"`
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
+	// Page and PerPage are the same pagination as Offset and Limit for the clients paging by `page` and `perPage`
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+	// NumbersAsStrings serializes the numbers as strings, e.g. `"total": "100"`,
+	// for the clients which cannot represent the large integers precisely, e.g. JavaScript
+	NumbersAsStrings bool `json:"-"`
+}
+
+// NewMetaOfResponse returns the meta of the records paginated by offset and limit,
+// and the page of the records starts from 1 if they are paged by limit.
+func NewMetaOfResponse(total, offset, limit int) MetaOfResponse {
+	meta := MetaOfResponse{
+		Total:   total,
+		Offset:  offset,
+		Limit:   limit,
+		PerPage: limit,
+	}
+	if limit > 0 {
+		meta.Page = offset/limit + 1
+	}
+	return meta
+}
+
+// MarshalJSON serializes the numbers of the meta as strings if NumbersAsStrings is set
+func (m MetaOfResponse) MarshalJSON() ([]byte, error) {
+	// metaOfResponse has no MarshalJSON to avoid the recursion
+	type metaOfResponse MetaOfResponse
+	if !m.NumbersAsStrings {
+		return json.Marshal(metaOfResponse(m))
+	}
+
+	return json.Marshal(struct {
+		Total   string `json:"total"`
+		Offset  string `json:"offset"`
+		Limit   string `json:"limit"`
+		Page    string `json:"page"`
+		PerPage string `json:"per_page"`
+	}{
+		Total:   strconv.Itoa(m.Total),
+		Offset:  strconv.Itoa(m.Offset),
+		Limit:   strconv.Itoa(m.Limit),
+		Page:    strconv.Itoa(m.Page),
+		PerPage: strconv.Itoa(m.PerPage),
+	})
 }
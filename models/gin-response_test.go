@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMetaOfResponseMarshalJSON(t *testing.T) {
+	cases := []struct {
+		name             string
+		numbersAsStrings bool
+		want             string
+	}{
+		{
+			name: "Given the numbers",
+			want: `{"total":9007199254740993,"offset":20,"limit":10,"page":3,"per_page":10}`,
+		},
+		{
+			name:             "Given the numbers as strings",
+			numbersAsStrings: true,
+			want:             `{"total":"9007199254740993","offset":"20","limit":"10","page":"3","per_page":"10"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			meta := NewMetaOfResponse(9007199254740993, 20, 10)
+			meta.NumbersAsStrings = tc.numbersAsStrings
+
+			// the meta is embedded in the response payload by value
+			got, err := json.Marshal(map[string]interface{}{"meta": meta})
+			if err != nil {
+				t.Fatalf("expect no error, but got %v", err)
+			}
+			if want := `{"meta":` + tc.want + `}`; string(got) != want {
+				t.Errorf("expect %s, but got %s", want, got)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"gopkg.in/guregu/null.v3"
@@ -32,6 +34,9 @@ type User struct {
 	Gender           null.String     `gorm:"size:2" json:"gender"`     // e.g., "M", "F" ...
 	Education        null.String     `gorm:"size:20" json:"education"` // e.g., "High School"
 	EnableEmail      int             `gorm:"type:int(5);size:2" json:"enable_email"`
+	// Blocked users are rejected by the authorization, see BlockReason for why they are blocked by the admins
+	Blocked     bool        `gorm:"not null;default:false" json:"blocked"`
+	BlockReason null.String `gorm:"size:512" json:"block_reason"`
 }
 
 // OAuthAccount ...
@@ -49,7 +54,61 @@ type OAuthAccount struct {
 	LastName  null.String `gorm:"size:50" json:"lastname"`
 	Gender    null.String `gorm:"size:20" json:"gender"`
 	Picture   null.String `json:"picture"` // user profile photo url
-	Birthday  null.String `json:"birthday"`
+	// Pictures are the resized variants of the picture stored by us
+	Pictures AvatarPictures `gorm:"embedded;embedded_prefix:picture_" json:"pictures"`
+	Birthday null.String    `json:"birthday"`
+	// Version is increased on each update, which detects the concurrent updates
+	Version uint `gorm:"not null;default:0" json:"-"`
+}
+
+// DefaultDisplayName is the display name of the OAuth account without any name or email
+var DefaultDisplayName = "Reader"
+
+// DisplayName returns the name shown on UIs, which falls back in the order of
+// Name, FirstName and LastName, the local part of Email, and DefaultDisplayName.
+// The null or blank fields are skipped.
+func (oa OAuthAccount) DisplayName() string {
+	if name := strings.TrimSpace(oa.Name.ValueOrZero()); name != "" {
+		return name
+	}
+
+	var names []string
+	for _, name := range []null.String{oa.FirstName, oa.LastName} {
+		if name := strings.TrimSpace(name.ValueOrZero()); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		return strings.Join(names, " ")
+	}
+
+	email := strings.TrimSpace(oa.Email.ValueOrZero())
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		email = email[:at]
+	}
+	if email != "" {
+		return email
+	}
+
+	return DefaultDisplayName
+}
+
+// AvatarPictures are the URLs of the avatar variants in different sizes
+type AvatarPictures struct {
+	Small  null.String `gorm:"size:255" json:"small"`
+	Medium null.String `gorm:"size:255" json:"medium"`
+	Large  null.String `gorm:"size:255" json:"large"`
+}
+
+// MarshalJSON encodes the pictures as null if none of the variants is stored
+func (p AvatarPictures) MarshalJSON() ([]byte, error) {
+	if !p.Small.Valid && !p.Medium.Valid && !p.Large.Valid {
+		return []byte("null"), nil
+	}
+
+	// avoid calling MarshalJSON recursively
+	type pictures AvatarPictures
+	return json.Marshal(pictures(p))
 }
 
 // ReporterAccount ...
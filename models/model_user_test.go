@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestOAuthAccountDisplayName(t *testing.T) {
+	cases := []struct {
+		name    string
+		account OAuthAccount
+		want    string
+	}{
+		{
+			name: "Given a name",
+			account: OAuthAccount{
+				Name:      null.StringFrom("Nick Lin"),
+				FirstName: null.StringFrom("Nick"),
+				LastName:  null.StringFrom("Chen"),
+				Email:     null.StringFrom("nick@twreporter.org"),
+			},
+			want: "Nick Lin",
+		},
+		{
+			name: "Given a blank name with first and last names",
+			account: OAuthAccount{
+				Name:      null.StringFrom(" "),
+				FirstName: null.StringFrom("Nick"),
+				LastName:  null.StringFrom("Chen"),
+			},
+			want: "Nick Chen",
+		},
+		{
+			name: "Given a first name only",
+			account: OAuthAccount{
+				FirstName: null.StringFrom("Nick"),
+				LastName:  null.NewString("", false),
+			},
+			want: "Nick",
+		},
+		{
+			name: "Given a last name only",
+			account: OAuthAccount{
+				LastName: null.StringFrom("Chen"),
+			},
+			want: "Chen",
+		},
+		{
+			name: "Given an email only",
+			account: OAuthAccount{
+				Name:  null.NewString("", false),
+				Email: null.StringFrom("nick@twreporter.org"),
+			},
+			want: "nick",
+		},
+		{
+			name: "Given an email with empty local part",
+			account: OAuthAccount{
+				Email: null.StringFrom("@twreporter.org"),
+			},
+			want: DefaultDisplayName,
+		},
+		{
+			name:    "Given all fields null",
+			account: OAuthAccount{},
+			want:    DefaultDisplayName,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.account.DisplayName(); got != tc.want {
+				t.Errorf("expect display name %s, but got %s", tc.want, got)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package models
 
 import (
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"gopkg.in/mgo.v2/bson"
 )
 
@@ -11,6 +13,15 @@ type ContentBody struct {
 	APIData []bson.M `bson:"apiData" json:"api_data"`
 }
 
+const (
+	// PaywallFree is the paywall level of the posts free to everyone
+	PaywallFree = 0
+	// PaywallRegistered is the paywall level of the posts for the registered users
+	PaywallRegistered = 1
+	// PaywallSubscriber is the paywall level of the posts for the subscribers
+	PaywallSubscriber = 2
+)
+
 // Post ...
 type Post struct {
 	ID                         bson.ObjectId   `bson:"_id" json:"id"`
@@ -59,4 +70,40 @@ type Post struct {
 	UpdatedAt                  time.Time       `bson:"updatedAt" json:"updated_at"`
 	Full                       bool            `bson:"-" json:"full"`
 	IsExternal                 bool            `bson:"is_external" json:"is_external"`
+	// ViewCount is synchronized from the analytics
+	ViewCount int `bson:"viewCount,omitempty" json:"view_count"`
+	// PaywallLevel is the least privilege of the readers to read the post, see PaywallFree etc.
+	PaywallLevel int `bson:"paywallLevel,omitempty" json:"paywall_level"`
+	// ShareCounts are served by the share counts endpoint only
+	ShareCounts *ShareCounts `bson:"shareCounts,omitempty" json:"-"`
+	// RestrictedCountries are the ISO country codes, e.g. TW, where the post is unavailable for legal reasons
+	RestrictedCountries []string `bson:"restrictedCountries,omitempty" json:"restricted_countries,omitempty"`
+	// Footnotes are also served by the footnotes endpoint for the tooltips
+	Footnotes []Footnote `bson:"footnotes,omitempty" json:"footnotes,omitempty"`
+	// LegacySlugs are the slugs of the duplicate posts merged into the post
+	LegacySlugs []string `bson:"legacySlugs,omitempty" json:"legacy_slugs,omitempty"`
+	// RedirectTo is the slug of the post which the deleted post is merged into
+	RedirectTo string `bson:"redirectTo,omitempty" json:"redirect_to,omitempty"`
+	// SeriesSlug groups the parts of the multi-part report, which are ordered by PartNumber starting from 1
+	SeriesSlug string `bson:"seriesSlug,omitempty" json:"series_slug,omitempty"`
+	PartNumber int    `bson:"partNumber,omitempty" json:"part_number,omitempty"`
+	TotalParts int    `bson:"totalParts,omitempty" json:"total_parts,omitempty"`
+}
+
+// Validate checks the required fields of the post,
+// it is used before writing the post from external sources into database.
+func (p Post) Validate() error {
+	if p.Slug == "" {
+		return errors.New("slug is required")
+	}
+
+	if strings.ContainsAny(p.Slug, " \t\n/?#") {
+		return errors.New("slug should not contain whitespaces, '/', '?' or '#'")
+	}
+
+	if p.Title == "" {
+		return errors.New("title is required")
+	}
+
+	return nil
 }
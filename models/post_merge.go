@@ -0,0 +1,10 @@
+package models
+
+// PostMergeResult is the outcome of merging the source post into the target post
+type PostMergeResult struct {
+	Slug       string `json:"slug"`
+	MergedSlug string `json:"merged_slug"`
+	// LegacySlugs are the slugs redirected to the target post after the merge
+	LegacySlugs    []string `json:"legacy_slugs"`
+	MovedBookmarks int      `json:"moved_bookmarks"`
+}
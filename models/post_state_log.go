@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// PostStateLog is the audit log of the post state changes
+type PostStateLog struct {
+	ID        uint        `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	DeletedAt *time.Time  `json:"deleted_at"`
+	PostSlug  string      `gorm:"size:100;not null" json:"post_slug"`
+	FromState string      `gorm:"size:20;not null" json:"from_state"`
+	ToState   string      `gorm:"size:20;not null" json:"to_state"`
+	Note      null.String `gorm:"size:512" json:"note"`
+	UserID    uint        `gorm:"not null" json:"user_id"`
+}
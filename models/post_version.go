@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// PostVersion is the snapshot of the post before it is updated by the editor
+type PostVersion struct {
+	ID       bson.ObjectId `bson:"_id,omitempty" json:"id"`
+	PostSlug string        `bson:"postSlug" json:"post_slug"`
+	Title    string        `bson:"title" json:"title"`
+	Content  *ContentBody  `bson:"content,omitempty" json:"content,omitempty"`
+	// UpdatedAt is the time the snapshot of the post was updated
+	UpdatedAt time.Time `bson:"updatedAt" json:"updated_at"`
+	EditorID  uint      `bson:"editorID" json:"editor_id"`
+	CreatedAt time.Time `bson:"createdAt" json:"created_at"`
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"strings"
+	"time"
+
 	"github.com/pkg/errors"
 	"gopkg.in/mgo.v2/bson"
 )
@@ -17,9 +20,19 @@ type Query interface {
 	UnmarshalQueryString(string) error
 }
 
-// MongoQueryComparison ...
+// MongoQueryComparison matches the array field containing any of `In`, and all of `All`
 type MongoQueryComparison struct {
-	In []bson.ObjectId `json:"in" bson:"$in,omitempty"`
+	In  []bson.ObjectId `json:"in" bson:"$in,omitempty"`
+	All []bson.ObjectId `json:"all" bson:"$all,omitempty"`
+	GT  bson.ObjectId   `json:"gt" bson:"$gt,omitempty"`
+}
+
+// MongoQueryTimeComparison is the time range condition
+type MongoQueryTimeComparison struct {
+	GT  time.Time `json:"gt" bson:"$gt,omitempty"`
+	GTE time.Time `json:"gte" bson:"$gte,omitempty"`
+	LT  time.Time `json:"lt" bson:"$lt,omitempty"`
+	LTE time.Time `json:"lte" bson:"$lte,omitempty"`
 }
 
 // MongoQuery implements Query interface, which stores the JSON in Query field.
@@ -32,6 +45,78 @@ type MongoQuery struct {
 	Tags       MongoQueryComparison `bson:"tags,omitempty" json:"tags"`
 	Topics     MongoQueryComparison `bson:"topics,omitempty" json:"topics"`
 	IDs        MongoQueryComparison `bson:"_id,omitempty" json:"ids"`
+	SeriesSlug string               `bson:"seriesSlug,omitempty" json:"series_slug"`
+
+	UpdatedAt     MongoQueryTimeComparison `bson:"updatedAt,omitempty" json:"updated_at"`
+	PublishedDate MongoQueryTimeComparison `bson:"publishedDate,omitempty" json:"published_date"`
+
+	// AuthorID is the comma separated ids of the authors, see FilterByAuthors
+	AuthorID string `bson:"-" json:"-"`
+	// Authors is the condition of AuthorID built by FilterByAuthors
+	Authors []bson.M `bson:"$or,omitempty" json:"-"`
+
+	// ExpandFields are the fields embedded inline by the storage in the same aggregation, see Expand
+	ExpandFields []string `bson:"-" json:"-"`
+
+	// AnyState lets the storage return the records of State other than published, which is only set for the admins
+	AnyState bool `bson:"-" json:"-"`
+}
+
+// ExpandAuthors expands the writers, photographers, designers and engineers of the posts
+const ExpandAuthors = "authors"
+
+// ErrInvalidExpandField is returned by Expand if any field is not expandable
+var ErrInvalidExpandField = errors.New("invalid expand field")
+
+// Expand sets ExpandFields by the comma separated fields, only `authors` is supported now
+func (query *MongoQuery) Expand(expand string) error {
+	var fields []string
+
+	for _, field := range strings.Split(expand, ",") {
+		if field != ExpandAuthors {
+			return ErrInvalidExpandField
+		}
+		fields = append(fields, field)
+	}
+
+	query.ExpandFields = fields
+	return nil
+}
+
+// IsExpanded reports whether the field is in ExpandFields
+func (query MongoQuery) IsExpanded(field string) bool {
+	for _, expanded := range query.ExpandFields {
+		if expanded == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrInvalidAuthorID is returned by FilterByAuthors if any author id is not a mongo ObjectId
+var ErrInvalidAuthorID = errors.New("invalid author ID format")
+
+// authorFields are the fields of the posts referring to the authors
+var authorFields = []string{"writters", "photographers", "designers", "engineers"}
+
+// FilterByAuthors filters the posts by any of the authors of the comma separated ids,
+// who is one of the writers, photographers, designers or engineers.
+func (query *MongoQuery) FilterByAuthors(authorID string) error {
+	var ids []bson.ObjectId
+
+	for _, id := range strings.Split(authorID, ",") {
+		if !bson.IsObjectIdHex(id) {
+			return ErrInvalidAuthorID
+		}
+		ids = append(ids, bson.ObjectIdHex(id))
+	}
+
+	query.AuthorID = authorID
+	query.Authors = make([]bson.M, 0, len(authorFields))
+	for _, field := range authorFields {
+		query.Authors = append(query.Authors, bson.M{field: bson.M{"$in": ids}})
+	}
+	return nil
 }
 
 func (query MongoQuery) ValidObjectIds(ids []bson.ObjectId) bool {
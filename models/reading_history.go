@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// ReadingHistory is the post read by the user, only the latest read time is kept for each post
+type ReadingHistory struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	Slug      string    `gorm:"size:100;not null" json:"slug"`
+	ReadAt    time.Time `json:"read_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// Series is the multi-part report grouped by the series slug of the posts
+type Series struct {
+	Slug      string `bson:"_id" json:"slug"`
+	PostCount int    `bson:"postCount" json:"post_count"`
+	// TotalParts is the planned number of the parts, which could be more than PostCount before all the parts are published
+	TotalParts          int       `bson:"totalParts" json:"total_parts"`
+	LatestPublishedDate time.Time `bson:"latestPublishedDate" json:"latest_published_date"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// ShareCounts are the counts of the post shared on the social networks,
+// which are updated asynchronously by the job polling the social APIs
+type ShareCounts struct {
+	Facebook int `bson:"facebook" json:"facebook"`
+	Twitter  int `bson:"twitter" json:"twitter"`
+	Line     int `bson:"line" json:"line"`
+	// UpdatedAt is nil if the counts are never polled
+	UpdatedAt *time.Time `bson:"updatedAt,omitempty" json:"updated_at"`
+	// RefreshRequestedAt is set if the refresh is requested manually, the job polls the requested posts first
+	RefreshRequestedAt *time.Time `bson:"refreshRequestedAt,omitempty" json:"-"`
+}
+
+// Total is the sum of the counts of all the social networks
+func (s ShareCounts) Total() int {
+	return s.Facebook + s.Twitter + s.Line
+}
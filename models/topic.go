@@ -33,7 +33,17 @@ type Topic struct {
 	OgDescription              string          `bson:"og_description" json:"og_description"`
 	OgImage                    *Image          `bson:"-" json:"og_image,omitempty"`
 	OgImageOrigin              bson.ObjectId   `bson:"og_image,omitempty" json:"-"`
+	Tags                       []Tag           `bson:"-" json:"tags,omitempty"`
+	TagsOrigin                 []bson.ObjectId `bson:"tags,omitempty" json:"-"`
 	PublishedDate              time.Time       `bson:"publishedDate" json:"published_date"`
 	UpdatedAt                  time.Time       `bson:"updatedAt" json:"updated_at"`
 	Full                       bool            `bson:"-" json:"full"`
+	// PostCount is the number of the posts belonging to the topic, which is computed on query
+	PostCount int `bson:"postCount,omitempty" json:"post_count"`
+}
+
+// TopicViews is the number of views of the topic
+type TopicViews struct {
+	Slug  string `bson:"_id" json:"slug"`
+	Views int    `bson:"views" json:"views"`
 }
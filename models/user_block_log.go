@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// The actions of UserBlockLog
+const (
+	UserBlockActionBlock   = "block"
+	UserBlockActionUnblock = "unblock"
+)
+
+// UserBlockLog is the audit log of blocking and unblocking the users by the admins
+type UserBlockLog struct {
+	ID        uint        `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	DeletedAt *time.Time  `json:"deleted_at"`
+	UserID    uint        `gorm:"not null" json:"user_id"`
+	Action    string      `gorm:"size:20;not null" json:"action"`
+	Reason    null.String `gorm:"size:512" json:"reason"`
+	AdminID   uint        `gorm:"not null" json:"admin_id"`
+}
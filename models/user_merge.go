@@ -0,0 +1,13 @@
+package models
+
+// UserMergeResult is the outcome of merging the source user into the target user
+type UserMergeResult struct {
+	UserID       uint `json:"user_id"`
+	MergedUserID uint `json:"merged_user_id"`
+	// MovedOAuthAccounts are the types of the OAuth accounts reassigned to the target user
+	MovedOAuthAccounts []string `json:"moved_oauth_accounts"`
+	// DroppedOAuthAccounts are the types linked on both users, whose accounts of the source user are deleted
+	DroppedOAuthAccounts []string `json:"dropped_oauth_accounts"`
+	MovedBookmarks       int      `json:"moved_bookmarks"`
+	MovedSubscriptions   int      `json:"moved_subscriptions"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	SubscriptionStatusActive   = "active"
+	SubscriptionStatusCanceled = "canceled"
+)
+
+// Subscription is the paid plan of the user, which grants access to the posts behind the paywall
+type Subscription struct {
+	ID        uint       `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	UserID    uint       `gorm:"not null" json:"user_id"`
+	Plan      string     `gorm:"size:20;not null" json:"plan"`
+	StartDate time.Time  `json:"start_date"`
+	// EndDate is null if the subscription never expires
+	EndDate null.Time `json:"end_date"`
+	Status  string    `gorm:"size:20;not null" json:"status"`
+}
+
+// IsActiveAt reports whether the subscription is active and not expired at the time
+func (s Subscription) IsActiveAt(t time.Time) bool {
+	return s.Status == SubscriptionStatusActive &&
+		!s.StartDate.After(t) &&
+		(!s.EndDate.Valid || s.EndDate.Time.After(t))
+}
@@ -2,23 +2,66 @@ package routers
 
 import (
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/mongo"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	f "github.com/twreporter/logformatter"
 
 	"twreporter.org/go-api/controllers"
 	"twreporter.org/go-api/globals"
 	"twreporter.org/go-api/middlewares"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+	"twreporter.org/go-api/utils"
 )
 
 const (
 	maxAge = 3600
 )
 
+// streamPaths are the long-lived responses, e.g. Server-Sent Events, exempted from the write timeout of the server
+var streamPaths = map[string]bool{
+	"/v1/events/posts": true,
+}
+
+// ExemptStreamsFromWriteTimeout clears the write deadline, which is set by `WriteTimeout` of the server, for `streamPaths`,
+// so that the streams are not cut after the timeout.
+// It wraps the handler of the server, since the write deadline is not reachable through the writer of gin.
+func ExemptStreamsFromWriteTimeout(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if streamPaths[strings.TrimSuffix(r.URL.Path, "/")] {
+			if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+				log.WithField("path", r.URL.Path).Warnf("fail to clear the write deadline of the stream: %v", err)
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// rewrittenPrefix is the prefix of the routes only reachable through the rewrites of RewritePrefixes
+const rewrittenPrefix = "/_rewritten"
+
+// postRewrites are the paths conflicting with `/v1/posts/:slug/*`, which are served by the routes under rewrittenPrefix
+var postRewrites = []middlewares.PrefixRewrite{
+	{Prefix: "/v1/posts/by-date/", Target: rewrittenPrefix + "/v1/posts-by-date/"},
+	{Prefix: "/v1/posts/series/", Target: rewrittenPrefix + "/v1/posts-by-series/"},
+}
+
+// RewritePrefixes rewrites the paths conflicting with the other routes before they are routed by the router.
+// It wraps the router the same as ExemptStreamsFromWriteTimeout does.
+func RewritePrefixes(h http.Handler) http.Handler {
+	return middlewares.RewritePrefixes(h, rewrittenPrefix, postRewrites...)
+}
+
 type wrappedFn func(c *gin.Context) (int, gin.H, error)
 
 func ginResponseWrapper(fn wrappedFn) func(c *gin.Context) {
@@ -30,22 +73,104 @@ func ginResponseWrapper(fn wrappedFn) func(c *gin.Context) {
 			} else {
 				log.WithField("detail", err).Errorf("%s", f.FormatStack(err))
 			}
+
+			if appErr, ok := errors.Cause(err).(*models.AppError); ok && appErr.RetryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(appErr.RetryAfter.Seconds())))
+			}
 		}
 		c.JSON(statusCode, obj)
 	}
 }
 
+// dispatchReservedSlugs dispatches the requests of the reserved slugs to their handlers, and the others to fn.
+// gin does not allow static paths along with the wildcard at the same position, e.g. `/posts/newsletter-digest` and `/posts/:slug`.
+func dispatchReservedSlugs(handlers map[string]wrappedFn, fn wrappedFn) wrappedFn {
+	return func(c *gin.Context) (int, gin.H, error) {
+		if handler, ok := handlers[c.Param("slug")]; ok {
+			return handler(c)
+		}
+		return fn(c)
+	}
+}
+
+// exceptReservedSlugs runs the middleware except for the reserved slugs,
+// e.g. the deprecation of `/v1/posts/:slug` is not applied to `/v1/posts/random`, which has no successor.
+func exceptReservedSlugs(middleware gin.HandlerFunc, slugs ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, slug := range slugs {
+			if c.Param("slug") == slug {
+				return
+			}
+		}
+		middleware(c)
+	}
+}
+
+// onlyReservedSlug responds 404 to the requests except the reserved slugs.
+// It lets a static path share the position with the wildcard of the other routes, e.g. `/admin/posts/export` and `/admin/posts/:slug/versions`,
+// while keeping the middlewares of the static path.
+func onlyReservedSlug(slugs ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, slug := range slugs {
+			if c.Param("slug") == slug {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatus(http.StatusNotFound)
+	}
+}
+
+// authorizeWithQuery runs the authorization handlers in order only if the url query param is provided,
+// so that the public requests are not rejected by the invalid tokens, e.g. the expired ones sent by the browsers.
+func authorizeWithQuery(param string, handlers ...gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.GetQuery(param); !ok {
+			return
+		}
+		for _, handler := range handlers {
+			if handler(c); c.IsAborted() {
+				return
+			}
+		}
+	}
+}
+
 // SetupRouter ...
 func SetupRouter(cf *controllers.ControllerFactory) (engine *gin.Engine) {
+	// the secrets, e.g. the tokens in Authorization header, are redacted before the requests are logged
+	redactor := middlewares.NewRedactor(globals.Conf.App.LogSettings.RedactedHeaders, globals.Conf.App.LogSettings.RedactedParams)
+
 	switch globals.Conf.Environment {
 	case "production", "staging":
 		// Disable default logger(stdout/stderr)
 		gin.SetMode(gin.ReleaseMode)
 		engine = gin.New()
 		engine.Use(middlewares.Recovery())
-		engine.Use(gin.LoggerWithFormatter(f.NewGinLogFormatter()))
+		engine.Use(gin.LoggerWithFormatter(redactor.LogFormatter(f.NewGinLogFormatter())))
 	default:
-		engine = gin.Default()
+		engine = gin.New()
+		engine.Use(gin.LoggerWithFormatter(redactor.LogFormatter(middlewares.DevLogFormatter)), gin.Recovery())
+	}
+
+	// the paths with trailing slash are handled by the canonical routes consistently regardless of the method
+	engine.RedirectTrailingSlash = false
+	engine.NoRoute(middlewares.HandleTrailingSlash(engine, globals.Conf.App.TrailingSlash))
+
+	engine.Use(middlewares.LogRequest(log.StandardLogger(), time.Duration(globals.Conf.App.RequestLogMinLatency)*time.Millisecond, redactor))
+
+	trustedProxies, err := middlewares.SetTrustedProxies(globals.Conf.App.TrustedProxies)
+	if err != nil {
+		log.Errorf("%+v", errors.Wrap(err, "none of the proxies is trusted"))
+	}
+	engine.Use(trustedProxies)
+
+	if path := globals.Conf.App.GeoIPDatabasePath; path != "" {
+		if resolver, err := middlewares.NewGeoIPResolver(path); err != nil {
+			log.Errorf("%+v", errors.Wrap(err, "the geo-restriction of the posts is disabled"))
+		} else {
+			engine.Use(middlewares.SetClientCountry(resolver))
+		}
 	}
 
 	config := cors.DefaultConfig()
@@ -69,6 +194,8 @@ func SetupRouter(cf *controllers.ControllerFactory) (engine *gin.Engine) {
 	config.AddAllowHeaders("Authorization")
 	config.AddAllowMethods("DELETE")
 	config.AddAllowMethods("PATCH")
+	// let the browsers read the headers of the deprecated routes
+	config.AddExposeHeaders("Deprecation", "Sunset", "Link")
 
 	// Enable Access-Control-Allow-Credentials header for axios pre-flight(OPTION) request
 	// so that the subsequent request could carry cookie
@@ -76,7 +203,24 @@ func SetupRouter(cf *controllers.ControllerFactory) (engine *gin.Engine) {
 
 	engine.Use(cors.New(config))
 
-	v1Group := engine.Group("/v1")
+	// public keys for external services to verify the JWT
+	jwks := new(controllers.JWKSController)
+	engine.GET("/.well-known/jwks.json", middlewares.SetCacheControl("public,max-age=3600"), jwks.Retrieve)
+
+	// build metadata of the deployed go-api
+	version := new(controllers.VersionController)
+	engine.GET("/version", middlewares.SetCacheControl("no-store"), version.Retrieve)
+
+	// health check of the connection to MongoDB
+	health := cf.GetHealthController()
+	engine.GET("/health", middlewares.SetCacheControl("no-store"), health.Check)
+
+	// the bodies of mutating requests are JSON, except the endpoints called by the forms of browsers or OAuth services
+	validateJSON := middlewares.ValidateContentType(binding.MIMEJSON)
+	validateJSONOrForm := middlewares.ValidateContentType(binding.MIMEJSON, binding.MIMEPOSTForm)
+
+	v1Group := engine.Group("/v1", validateJSON)
+	v1FormGroup := engine.Group("/v1", validateJSONOrForm)
 	{
 		menuitems := new(controllers.MenuItemsController)
 		v1Group.GET("/ping", menuitems.Retrieve)
@@ -86,65 +230,182 @@ func SetupRouter(cf *controllers.ControllerFactory) (engine *gin.Engine) {
 	// membership service endpoints
 	// =============================
 	mc := cf.GetMembershipController()
+	validateAuthorization := middlewares.ValidateAuthorization(storage.NewGormStorage(cf.GetGormDB()))
+	// endpoint for oauth providers linked to users
+	v1Group.GET("/users/:userID/oauth", validateAuthorization, middlewares.ValidateUserIDOrAdmin(storage.NewGormStorage(cf.GetGormDB())), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.GetOAuthAccountsOfAUser))
+	v1Group.GET("/users/:userID/export", validateAuthorization, middlewares.ValidateUserIDOrAdmin(storage.NewGormStorage(cf.GetGormDB())), middlewares.SetCacheControl("no-store"), mc.ExportUserData)
+
 	// endpoints for bookmarks of users
-	v1Group.GET("/users/:userID/bookmarks", middlewares.ValidateAuthorization(), middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.GetBookmarksOfAUser))
-	v1Group.GET("/users/:userID/bookmarks/:bookmarkSlug", middlewares.ValidateAuthorization(), middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.GetBookmarksOfAUser))
-	v1Group.POST("/users/:userID/bookmarks", middlewares.ValidateAuthorization(), middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.CreateABookmarkOfAUser))
-	v1Group.DELETE("/users/:userID/bookmarks/:bookmarkID", middlewares.ValidateAuthorization(), middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.DeleteABookmarkOfAUser))
+	v1Group.GET("/users/:userID/bookmarks", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.GetBookmarksOfAUser))
+	v1Group.GET("/users/:userID/bookmarks/:bookmarkSlug", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.GetBookmarksOfAUser))
+	v1Group.POST("/users/:userID/bookmarks", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.CreateABookmarkOfAUser))
+	v1Group.POST("/users/:userID/bookmarks/import", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.ImportBookmarksOfAUser))
+	v1Group.DELETE("/users/:userID/bookmarks", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.DeleteBookmarksOfAUser))
+	v1Group.DELETE("/users/:userID/bookmarks/:bookmarkID", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.DeleteABookmarkOfAUser))
+
+	// endpoints for reading history of users
+	rhc := cf.GetReadingHistoryController()
+	v1Group.POST("/users/:userID/reading-history", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(rhc.RecordReadingHistory))
+	v1Group.DELETE("/users/:userID/reading-history", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(rhc.ClearReadingHistory))
+	v1Group.DELETE("/users/:userID/reading-history/:slug", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(rhc.DeleteReadingHistory))
+
+	// endpoint for recommended posts of users
+	rc := cf.GetRecommendationController()
+	v1Group.GET("/users/:userID/recommended", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(rc.GetRecommendedPosts))
+
+	// endpoint for external services to validate JWT
+	v1FormGroup.POST("/auth/introspect", middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.IntrospectToken))
+
+	// endpoints for webhooks
+	v1FormGroup.POST("/webhooks/facebook/data-deletion", middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.HandleFacebookDataDeletion))
+	v1Group.GET("/webhooks/facebook/data-deletion/:code", middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.GetDataDeletionStatus))
 
 	// endpoints for donation
-	v1Group.POST("/periodic-donations", middlewares.ValidateAuthentication(), middlewares.ValidateAuthorization(), middlewares.ValidateUserIDInReqBody(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.CreateAPeriodicDonationOfAUser))
-	v1Group.PATCH("/periodic-donations/orders/:order", middlewares.ValidateAuthentication(), middlewares.ValidateAuthorization(), middlewares.ValidateUserIDInReqBody(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
+	v1Group.POST("/periodic-donations", middlewares.ValidateAuthentication(), validateAuthorization, middlewares.ValidateUserIDInReqBody(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.CreateAPeriodicDonationOfAUser))
+	v1Group.PATCH("/periodic-donations/orders/:order", middlewares.ValidateAuthentication(), validateAuthorization, middlewares.ValidateUserIDInReqBody(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
 		return mc.PatchADonationOfAUser(c, globals.PeriodicDonationType)
 	}))
-	v1Group.GET("/periodic-donations/orders/:order", middlewares.ValidateAuthentication(), middlewares.ValidateAuthorization(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
+	v1Group.GET("/periodic-donations/orders/:order", middlewares.ValidateAuthentication(), validateAuthorization, middlewares.SetCacheControl("no-store"), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
 		return mc.GetADonationOfAUser(c, globals.PeriodicDonationType)
 	}))
-	v1Group.POST("/donations/prime", middlewares.ValidateAuthentication(), middlewares.ValidateAuthorization(), middlewares.ValidateUserIDInReqBody(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.CreateADonationOfAUser))
-	v1Group.PATCH("/donations/prime/orders/:order", middlewares.ValidateAuthentication(), middlewares.ValidateAuthorization(), middlewares.ValidateUserIDInReqBody(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
+	v1Group.POST("/donations/prime", middlewares.ValidateAuthentication(), validateAuthorization, middlewares.ValidateUserIDInReqBody(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.CreateADonationOfAUser))
+	v1Group.PATCH("/donations/prime/orders/:order", middlewares.ValidateAuthentication(), validateAuthorization, middlewares.ValidateUserIDInReqBody(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
 		return mc.PatchADonationOfAUser(c, globals.PrimeDonationType)
 	}))
-	// v1Group.GET("/users/:userID/donations", middlewares.ValidateAuthorization(), middlewares.ValidateUserID(), ginResponseWrapper(mc.GetDonationsOfAUser))
+	// v1Group.GET("/users/:userID/donations", validateAuthorization, middlewares.ValidateUserID(), ginResponseWrapper(mc.GetDonationsOfAUser))
 	// one-time donation including credit_card, line pay, apple pay, google pay and samsung pay
-	v1Group.GET("/donations/prime/orders/:order", middlewares.ValidateAuthentication(), middlewares.ValidateAuthorization(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
+	v1Group.GET("/donations/prime/orders/:order", middlewares.ValidateAuthentication(), validateAuthorization, middlewares.SetCacheControl("no-store"), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
 		return mc.GetADonationOfAUser(c, globals.PrimeDonationType)
 	}))
-	v1Group.GET("/donations/prime/orders/:order/transaction_verification", middlewares.ValidateAuthentication(), middlewares.ValidateAuthorization(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.GetVerificationInfoOfADonation))
+	v1Group.GET("/donations/prime/orders/:order/transaction_verification", middlewares.ValidateAuthentication(), validateAuthorization, middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.GetVerificationInfoOfADonation))
 
 	v1Group.POST("/donations/prime/line-notify", ginResponseWrapper(mc.PatchLinePayOfAUser))
-	v1Group.POST("/tappay_query", middlewares.ValidateAuthentication(), middlewares.ValidateAuthorization(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.QueryTappayServer))
+	v1Group.POST("/tappay_query", middlewares.ValidateAuthentication(), validateAuthorization, middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.QueryTappayServer))
 	// TODO
 	// donations derived from the periodic donation
-	// v1Group.GET("/users/:userID/donations/token/:id", middlewares.ValidateAuthorization(), middlewares.ValidateUserID(), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
+	// v1Group.GET("/users/:userID/donations/token/:id", validateAuthorization, middlewares.ValidateUserID(), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
 	//  return mc.GetADonationOfAUser(c, globals.TokenDonationType)
 	//}))
 
 	// other donations not included in the above endpoints
-	v1Group.GET("/donations/others/orders/:order", middlewares.ValidateAuthentication(), middlewares.ValidateAuthorization(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
+	v1Group.GET("/donations/others/orders/:order", middlewares.ValidateAuthentication(), validateAuthorization, middlewares.SetCacheControl("no-store"), ginResponseWrapper(func(c *gin.Context) (int, gin.H, error) {
 		return mc.GetADonationOfAUser(c, globals.OthersDonationType)
 	}))
 
 	// endpoints for web push subscriptions
-	v1Group.POST("/web-push/subscriptions" /*middlewares.ValidateAuthorization()*/, middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.SubscribeWebPush))
+	v1Group.POST("/web-push/subscriptions" /*validateAuthorization*/, middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.SubscribeWebPush))
 	v1Group.GET("/web-push/subscriptions", middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.IsWebPushSubscribed))
 
+	// endpoints for push notifications of the mobile apps
+	pc := cf.GetPushController()
+	v1Group.POST("/users/:userID/device-tokens", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(pc.RegisterDeviceToken))
+	v1Group.GET("/users/:userID/category-subscriptions", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(pc.GetCategorySubscriptionsOfAUser))
+	v1Group.POST("/users/:userID/category-subscriptions", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(pc.CreateCategorySubscription))
+	v1Group.DELETE("/users/:userID/category-subscriptions/:categoryID", validateAuthorization, middlewares.ValidateUserID(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(pc.DeleteCategorySubscription))
+
 	// =============================
 	// news service endpoints
 	// =============================
 	nc := cf.GetNewsController()
+	validateSlug := middlewares.ValidateSlug()
+	deprecationSampler := utils.NewLogSampler(globals.Conf.App.LogSettings.DeprecationSampleRate)
+	deprecateV1 := func(successor string) gin.HandlerFunc {
+		return middlewares.Deprecate(middlewares.RouteVersionInfo{Successor: successor, Sunset: globals.Conf.App.V1Sunset, LogSampler: deprecationSampler})
+	}
 	// endpoints for authors
-	v1Group.GET("/authors", middlewares.SetCacheControl("public,max-age=600"), ginResponseWrapper(nc.GetAuthors))
+	v1Group.GET("/authors", middlewares.ValidateListParams("updatedAt", "name"), middlewares.SetCacheControl("public,max-age=600"), ginResponseWrapper(nc.GetAuthors))
 	// endpoints for posts
-	v1Group.GET("/posts", middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetPosts))
-	v1Group.GET("/posts/:slug", middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetAPost))
+	v1Group.GET("/posts", deprecateV1("/v2/posts"), middlewares.ValidateListParams("publishedDate", "updatedAt"), authorizeWithQuery("state", validateAuthorization, middlewares.ValidateAdmin(storage.NewGormStorage(cf.GetGormDB()))), middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetPosts))
+	v1Group.GET("/posts/:slug", validateSlug, exceptReservedSlugs(deprecateV1("/v2/posts/:slug"), "newsletter-digest", "random"), middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(dispatchReservedSlugs(map[string]wrappedFn{
+		"newsletter-digest": nc.GetNewsletterDigest,
+		"random":            nc.GetRandomPost,
+	}, nc.GetAPost)))
+	// the routes rewritten from `/v1/posts/by-date/:year/:month` and `/v1/posts/series/:seriesSlug`, see RewritePrefixes
+	rewrittenGroup := engine.Group(rewrittenPrefix+"/v1", validateJSON)
+	rewrittenGroup.GET("/posts-by-date/:year/:month", middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetPostsByYearMonth))
+	rewrittenGroup.GET("/posts-by-series/:seriesSlug", middlewares.ValidateSlugParam("seriesSlug"), middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetPostsBySeries))
+	v1Group.GET("/series", middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetSeries))
+	v1Group.GET("/posts/:slug/previous", validateSlug, middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetPreviousPost))
+	v1Group.GET("/posts/:slug/next", validateSlug, middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetNextPost))
+	// endpoint for printer-friendly posts
+	ppc := cf.GetPostPrintController()
+	v1Group.GET("/posts/:slug/print", validateSlug, middlewares.SetCacheControl("public,max-age=3600"), ppc.GetAPrintedPost)
+	// endpoint for citations of posts
+	pcc := cf.GetPostCitationController()
+	v1Group.GET("/posts/:slug/citations", validateSlug, middlewares.SetCacheControl("public,max-age=3600"), pcc.GetCitationOfAPost)
+	ppwc := cf.GetPostPaywallController()
+	v1Group.GET("/posts/:slug/paywall-status", validateSlug, middlewares.OptionalAuthorization(storage.NewGormStorage(cf.GetGormDB())), middlewares.SetCacheControl("no-store"), ginResponseWrapper(ppwc.GetPaywallStatusOfAPost))
+	pfc := cf.GetPostFootnotesController()
+	v1Group.GET("/posts/:slug/footnotes", validateSlug, middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(pfc.GetFootnotesOfAPost))
+	pscc := cf.GetPostShareCountsController()
+	v1Group.GET("/posts/:slug/share-counts", validateSlug, middlewares.SetCacheControl("public,max-age=300"), ginResponseWrapper(pscc.GetShareCountsOfAPost))
+	// endpoints for real-time events
+	pevc := cf.GetPostEventsController()
+	v1Group.GET("/events/posts", pevc.StreamPostEvents)
 	// endpoints for topics
-	v1Group.GET("/topics", middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetTopics))
-	v1Group.GET("/topics/:slug", middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetATopic))
-	v1Group.GET("/index_page", middlewares.SetCacheControl("public,max-age=1800"), nc.GetIndexPageContents)
+	v1Group.GET("/topics", deprecateV1("/v2/topics"), middlewares.ValidateListParams("publishedDate", "updatedAt"), middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetTopics))
+	v1Group.GET("/topics/:slug", validateSlug, exceptReservedSlugs(deprecateV1("/v2/topics/:slug"), "trending"), middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(dispatchReservedSlugs(map[string]wrappedFn{
+		"trending": nc.GetTrendingTopics,
+	}, nc.GetATopic)))
+	v1Group.GET("/topics/:slug/related", validateSlug, middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetRelatedTopics))
+	// endpoints for tags
+	v1Group.GET("/tags", middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetTags))
+	v1Group.GET("/tags/:tag/topics", middlewares.SetCacheControl("public,max-age=900"), ginResponseWrapper(nc.GetTopicsOfTags))
+	v1Group.GET("/index_page", deprecateV1("/v2/index_page"), middlewares.SetCacheControl("public,max-age=1800"), nc.GetIndexPageContents)
 	v1Group.GET("/index_page_categories", middlewares.SetCacheControl("public,max-age=1800"), nc.GetCategoriesPosts)
 	// endpoints for search
-	v1Group.GET("/search/authors", middlewares.SetCacheControl("public,max-age=3600"), nc.SearchAuthors)
-	v1Group.GET("/search/posts", middlewares.SetCacheControl("public,max-age=3600"), nc.SearchPosts)
+	searchTimeout := middlewares.Timeout(globals.Conf.App.RouteTimeouts.Search)
+	v1Group.GET("/search/authors", searchTimeout, middlewares.SetCacheControl("public,max-age=3600"), nc.SearchAuthors)
+	v1Group.GET("/search/posts", searchTimeout, middlewares.SetCacheControl("public,max-age=3600"), nc.SearchPosts)
+
+	// =============================
+	// admin endpoints
+	// =============================
+	psc := cf.GetPostStateController()
+	validateAdmin := middlewares.ValidateAdmin(storage.NewGormStorage(cf.GetGormDB()))
+	v1Group.PATCH("/admin/posts/:slug/state", validateSlug, validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(psc.UpdatePostState))
+	pic := cf.GetPostImportController()
+	pmc := cf.GetPostMergeController()
+	v1Group.POST("/admin/posts/:slug", onlyReservedSlug("import", "merge"), validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(dispatchReservedSlugs(map[string]wrappedFn{
+		"merge": pmc.MergePosts,
+	}, pic.ImportPosts)))
+	pdc := cf.GetPostDuplicateController()
+	v1Group.POST("/admin/posts/:slug/duplicate", validateSlug, validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(pdc.DuplicatePost))
+	v1Group.POST("/admin/posts/:slug/share-counts/refresh", validateSlug, validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(pscc.RefreshShareCountsOfAPost))
+	tpc := cf.GetTopicPostsController()
+	v1Group.PATCH("/admin/topics/:slug/posts", validateSlug, validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(tpc.ReorderPostsOfTopic))
+	// endpoints for content reports
+	crc := cf.GetContentReportController()
+	v1Group.POST("/posts/:slug/report", validateSlug, validateAuthorization, middlewares.SetCacheControl("no-store"), ginResponseWrapper(crc.CreateContentReport))
+	v1Group.GET("/admin/reports", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(crc.GetContentReports))
+	// endpoints for annotations
+	ac := cf.GetAnnotationController()
+	v1Group.GET("/posts/:slug/annotations", validateSlug, middlewares.SetCacheControl("public,max-age=300"), ginResponseWrapper(ac.GetAnnotationsOfAPost))
+	v1Group.POST("/admin/posts/:slug/annotations", validateSlug, validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(ac.CreateAnnotation))
+	v1Group.PATCH("/admin/annotations/:id", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(ac.UpdateAnnotation))
+	v1Group.DELETE("/admin/annotations/:id", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(ac.DeleteAnnotation))
+	// endpoints for corrections
+	corc := cf.GetCorrectionController()
+	v1Group.GET("/posts/:slug/corrections", validateSlug, middlewares.SetCacheControl("public,max-age=300"), ginResponseWrapper(corc.GetCorrectionsOfAPost))
+	v1Group.POST("/admin/posts/:slug/corrections", validateSlug, validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(corc.CreateCorrection))
+	v1Group.PATCH("/admin/corrections/:id", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(corc.UpdateCorrection))
+	pec := cf.GetPostExportController()
+	v1Group.GET("/admin/posts/:slug", onlyReservedSlug("export"), validateAuthorization, validateAdmin, middlewares.Timeout(globals.Conf.App.RouteTimeouts.Export), middlewares.SetCacheControl("no-store"), pec.ExportPosts)
+	pvc := cf.GetPostVersionController()
+	v1Group.GET("/admin/posts/:slug/versions", validateSlug, validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(pvc.GetPostVersions))
+	v1Group.GET("/admin/posts/:slug/versions/:versionID/diff", validateSlug, validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(pvc.GetPostVersionDiff))
+	cc := cf.GetCacheController()
+	v1Group.POST("/admin/cache/purge", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(cc.PurgeCache))
+	uac := cf.GetUserAdminController()
+	v1Group.GET("/admin/users", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(uac.ListUsers))
+	v1Group.POST("/admin/users/:userID/block", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(uac.BlockUser))
+	v1Group.DELETE("/admin/users/:userID/block", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(uac.UnblockUser))
+	v1Group.POST("/admin/users/:userID/merge", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(mc.MergeUsers))
+	sc := cf.GetSubscriptionController()
+	v1Group.POST("/admin/subscriptions", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(sc.CreateSubscription))
+	v1Group.PATCH("/admin/subscriptions/:id", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(sc.UpdateSubscription))
+	v1Group.DELETE("/admin/subscriptions/:id", validateAuthorization, validateAdmin, middlewares.SetCacheControl("no-store"), ginResponseWrapper(sc.CancelSubscription))
+	v1Group.GET("/users/:userID/subscription", validateAuthorization, middlewares.ValidateUserIDOrAdmin(storage.NewGormStorage(cf.GetGormDB())), middlewares.SetCacheControl("no-store"), ginResponseWrapper(sc.GetSubscriptionOfAUser))
 
 	// =============================
 	// mail service endpoints
@@ -154,19 +415,28 @@ func SetupRouter(cf *controllers.ControllerFactory) (engine *gin.Engine) {
 	mailMiddleware := middlewares.GetMailServiceMiddleware()
 	v1Group.POST(fmt.Sprintf("/%s", globals.SendActivationRoutePath), mailMiddleware.ValidateAuthorization(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mailContrl.SendActivation))
 	v1Group.POST(fmt.Sprintf("/%s", globals.SendSuccessDonationRoutePath), mailMiddleware.ValidateAuthorization(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mailContrl.SendDonationSuccessMail))
+	v1Group.POST(fmt.Sprintf("/%s", globals.SendPostStateChangeRoutePath), mailMiddleware.ValidateAuthorization(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mailContrl.SendPostStateChangeMail))
+	v1Group.POST(fmt.Sprintf("/%s", globals.SendContentReportRoutePath), mailMiddleware.ValidateAuthorization(), middlewares.SetCacheControl("no-store"), ginResponseWrapper(mailContrl.SendContentReportMail))
+
+	// =============================
+	// internal service endpoints
+	// =============================
+
+	internalGroup := engine.Group("/internal", validateJSON, middlewares.ValidateInternalService(), middlewares.SetCacheControl("no-store"))
+	internalGroup.POST("/users/batch", ginResponseWrapper(mc.GetUsersByIDs))
 
-	v2Group := engine.Group("/v2")
+	v2Group := engine.Group("/v2", validateJSON)
 	ncV2 := cf.GetNewsV2Controller()
-	v2Group.GET("/posts", middlewares.SetCacheControl("public,max-age=900"), ncV2.GetPosts)
-	v2Group.GET("/posts/:slug", middlewares.SetCacheControl("public,max-age=900"), ncV2.GetAPost)
+	v2Group.GET("/posts", middlewares.ValidateListParams("published_date", "updated_at"), middlewares.SetCacheControl("public,max-age=900"), ncV2.GetPosts)
+	v2Group.GET("/posts/:slug", validateSlug, middlewares.SetCacheControl("public,max-age=900"), ncV2.GetAPost)
 	// endpoints for topics
-	v2Group.GET("/topics", middlewares.SetCacheControl("public,max-age=900"), ncV2.GetTopics)
-	v2Group.GET("/topics/:slug", middlewares.SetCacheControl("public,max-age=900"), ncV2.GetATopic)
+	v2Group.GET("/topics", middlewares.ValidateListParams("published_date", "updated_at"), middlewares.SetCacheControl("public,max-age=900"), ncV2.GetTopics)
+	v2Group.GET("/topics/:slug", validateSlug, middlewares.SetCacheControl("public,max-age=900"), ncV2.GetATopic)
 	v2Group.GET("/index_page", middlewares.SetCacheControl("public,max-age=1800"), ncV2.GetIndexPage)
 	// =============================
 	// v2 oauth endpoints
 	// =============================
-	v2AuthGroup := v2Group.Group("/auth")
+	v2AuthGroup := engine.Group("/v2/auth", validateJSONOrForm)
 
 	session := cf.GetMgoSession()
 	c := session.DB("go-api").C("sessions")
@@ -185,6 +455,13 @@ func SetupRouter(cf *controllers.ControllerFactory) (engine *gin.Engine) {
 	ofc := cf.GetOAuthController(globals.FacebookOAuth)
 	v2AuthGroup.GET("/facebook", middlewares.SetCacheControl("no-store"), ofc.BeginOAuth)
 	v2AuthGroup.GET("/facebook/callback", middlewares.SetCacheControl("no-store"), ofc.Authenticate)
+	olc := cf.GetOAuthController(globals.LinkedInOAuth)
+	v2AuthGroup.GET("/linkedin", middlewares.SetCacheControl("no-store"), olc.BeginOAuth)
+	v2AuthGroup.GET("/linkedin/callback", middlewares.SetCacheControl("no-store"), olc.Authenticate)
+	oac := cf.GetOAuthController(globals.AppleOAuth)
+	v2AuthGroup.GET("/apple", middlewares.SetCacheControl("no-store"), oac.BeginOAuth)
+	// apple posts the authorization response to the callback since `response_mode=form_post`
+	v2AuthGroup.POST("/apple/callback", middlewares.SetCacheControl("no-store"), oac.Authenticate)
 
 	// =============================
 	// v2 membership service endpoints
@@ -0,0 +1,185 @@
+package routers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+
+	"twreporter.org/go-api/controllers"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+// readOnlyPostStorage simulates the database during failovers, which rejects the writes and serves the reads
+type readOnlyPostStorage struct{}
+
+func (s readOnlyPostStorage) UpsertPosts(context.Context, []models.Post, uint) (storage.UpsertResult, error) {
+	return storage.UpsertResult{}, errors.Wrap(&mgo.LastError{Code: 10107, Err: "not master"}, "upsert posts in bulk occurs error")
+}
+
+func (s readOnlyPostStorage) IteratePosts(mq models.MongoQuery, fn func(models.Post) error) error {
+	return fn(models.Post{Slug: "a-slug", Title: "a title"})
+}
+
+func TestReadOnlyStorage(t *testing.T) {
+	defaultRetryAfter := globals.Conf.DB.ReadOnlyRetryAfter
+	globals.Conf.DB.ReadOnlyRetryAfter = 30
+	defer func() {
+		globals.Conf.DB.ReadOnlyRetryAfter = defaultRetryAfter
+	}()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/posts/import", ginResponseWrapper(controllers.NewPostImportController(readOnlyPostStorage{}).ImportPosts))
+	engine.GET("/posts/export", controllers.NewPostExportController(readOnlyPostStorage{}).ExportPosts)
+
+	t.Run("Given a write", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/posts/import", strings.NewReader(`[{"slug":"a-slug","title":"a title"}]`))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, resp.Code)
+		}
+		if retryAfter := resp.Header().Get("Retry-After"); retryAfter != "30" {
+			t.Errorf("expected Retry-After 30, got %q", retryAfter)
+		}
+	})
+
+	t.Run("Given a read", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/posts/export", nil)
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, resp.Code)
+		}
+		if retryAfter := resp.Header().Get("Retry-After"); retryAfter != "" {
+			t.Errorf("expected no Retry-After, got %q", retryAfter)
+		}
+	})
+}
+
+func TestOnlyReservedSlug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/admin/posts/:slug", onlyReservedSlug("export"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	engine.GET("/admin/posts/:slug/versions", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	engine.GET("/admin/topics/:slug", onlyReservedSlug("export", "import"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for path, want := range map[string]int{
+		"/admin/topics/export":         http.StatusOK,
+		"/admin/topics/import":         http.StatusOK,
+		"/admin/topics/a-slug":         http.StatusNotFound,
+		"/admin/posts/export":          http.StatusOK,
+		"/admin/posts/a-slug":          http.StatusNotFound,
+		"/admin/posts/a-slug/versions": http.StatusOK,
+		"/admin/posts/export/versions": http.StatusOK,
+		"/admin/posts/a-slug/unknown":  http.StatusNotFound,
+	} {
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		if resp.Code != want {
+			t.Errorf("%s: expected status %d, got %d", path, want, resp.Code)
+		}
+	}
+}
+
+func TestAuthorizeWithQuery(t *testing.T) {
+	var adminChecked bool
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	authorize := func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	}
+	validateAdmin := func(c *gin.Context) {
+		adminChecked = true
+		c.AbortWithStatus(http.StatusForbidden)
+	}
+	engine.GET("/posts", authorizeWithQuery("state", authorize, validateAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	cases := []struct {
+		name             string
+		query            string
+		authorization    string
+		want             int
+		wantAdminChecked bool
+	}{
+		{name: "Given no state", query: "", want: http.StatusOK},
+		{name: "Given state without authorization", query: "?state=draft", want: http.StatusUnauthorized},
+		{name: "Given state with authorization", query: "?state=draft", authorization: "Bearer token", want: http.StatusForbidden, wantAdminChecked: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			adminChecked = false
+			req := httptest.NewRequest(http.MethodGet, "/posts"+tc.query, nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+			if resp.Code != tc.want {
+				t.Errorf("expected status %d, got %d", tc.want, resp.Code)
+			}
+			if adminChecked != tc.wantAdminChecked {
+				t.Errorf("expected the admin checked %t, got %t", tc.wantAdminChecked, adminChecked)
+			}
+		})
+	}
+}
+
+func TestExemptStreamsFromWriteTimeout(t *testing.T) {
+	// the handler outlives the write timeout before writing the response
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("data"))
+	})
+
+	server := httptest.NewUnstartedServer(ExemptStreamsFromWriteTimeout(slow))
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	t.Run("Given the stream", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/v1/events/posts")
+		if err != nil {
+			t.Fatalf("expected the stream responded, got %v", err)
+		}
+		defer resp.Body.Close()
+
+		if body, err := ioutil.ReadAll(resp.Body); err != nil || string(body) != "data" {
+			t.Errorf("expected the body data, got %q and %v", body, err)
+		}
+	})
+
+	t.Run("Given the other path", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/v1/posts")
+		if err == nil {
+			resp.Body.Close()
+			t.Error("expected the response cut by the write timeout")
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	// register the decoders of the avatar formats
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"golang.org/x/image/draw"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/configs"
+	"twreporter.org/go-api/models"
+)
+
+const (
+	// maxAvatarBytes is the maximum size of the downloaded avatar
+	maxAvatarBytes = 5 << 20
+	// maxAvatarPixels is the maximum number of pixels of the downloaded avatar,
+	// which is checked before decoding, since a small compressed image could be decoded into a huge bitmap
+	maxAvatarPixels = 4096 * 4096
+	avatarQuality   = 85
+)
+
+// AvatarSizes are the widths and heights in pixels of the avatar variants
+var AvatarSizes = struct {
+	Small  int
+	Medium int
+	Large  int
+}{
+	Small:  50,
+	Medium: 100,
+	Large:  200,
+}
+
+// ErrInvalidAvatar is returned if the downloaded avatar is not a decodable image
+var ErrInvalidAvatar = errors.New("avatar is not a valid image")
+
+// AvatarStore defines an interface storing the avatar variants
+type AvatarStore interface {
+	// Put stores the data by key and returns its public URL
+	Put(key, contentType string, data []byte) (string, error)
+}
+
+// NewS3AvatarStore returns a S3AvatarStore struct with required config,
+// the session to AWS is created once and shared by the uploads
+func NewS3AvatarStore(conf configs.AvatarConfig) (AvatarStore, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(conf.AwsRegion)},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create a session to AWS")
+	}
+
+	return &S3AvatarStore{conf: conf, client: s3.New(sess)}, nil
+}
+
+// S3AvatarStore implements AvatarStore interface, which uploads the variants to S3
+type S3AvatarStore struct {
+	conf   configs.AvatarConfig
+	client *s3.S3
+}
+
+// Put uploads the data to the bucket, the URL is the key under the base URL
+func (s *S3AvatarStore) Put(key, contentType string, data []byte) (string, error) {
+	if _, err := s.client.PutObject(&s3.PutObjectInput{
+		Bucket:       aws.String(s.conf.Bucket),
+		Key:          aws.String(key),
+		Body:         bytes.NewReader(data),
+		ContentType:  aws.String(contentType),
+		CacheControl: aws.String("public,max-age=86400"),
+	}); err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("cannot upload avatar(key: %s) to S3", key))
+	}
+
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(s.conf.BaseURL, "/"), key), nil
+}
+
+// NewAvatarService returns an AvatarService downloading the avatars by client and storing the variants in store
+func NewAvatarService(client *http.Client, store AvatarStore) *AvatarService {
+	return &AvatarService{client: client, store: store}
+}
+
+// AvatarService generates the variants of the avatars in different sizes
+type AvatarService struct {
+	client *http.Client
+	store  AvatarStore
+}
+
+// StoreVariants downloads the avatar from the source, and stores its small, medium and large variants
+// as `<keyPrefix>-small.jpg`, etc.
+// ErrInvalidAvatar is returned if the download is not an image, is broken or is too large.
+func (s *AvatarService) StoreVariants(source, keyPrefix string) (models.AvatarPictures, error) {
+	var pictures models.AvatarPictures
+
+	img, err := s.download(source)
+	if err != nil {
+		return pictures, err
+	}
+
+	square := cropSquare(img)
+	for _, variant := range []struct {
+		name string
+		size int
+		url  *null.String
+	}{
+		{"small", AvatarSizes.Small, &pictures.Small},
+		{"medium", AvatarSizes.Medium, &pictures.Medium},
+		{"large", AvatarSizes.Large, &pictures.Large},
+	} {
+		var buf bytes.Buffer
+
+		dst := image.NewRGBA(image.Rect(0, 0, variant.size, variant.size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, square, draw.Src, nil)
+
+		if err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: avatarQuality}); err != nil {
+			return models.AvatarPictures{}, errors.Wrap(err, fmt.Sprintf("cannot encode %s avatar", variant.name))
+		}
+
+		url, err := s.store.Put(fmt.Sprintf("%s-%s.jpg", keyPrefix, variant.name), "image/jpeg", buf.Bytes())
+		if err != nil {
+			return models.AvatarPictures{}, err
+		}
+		*variant.url = null.StringFrom(url)
+	}
+
+	return pictures, nil
+}
+
+// download gets and decodes the avatar
+func (s *AvatarService) download(source string) (image.Image, error) {
+	resp, err := s.client.Get(source)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("cannot download avatar(url: %s)", source))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Wrap(ErrInvalidAvatar, fmt.Sprintf("download avatar(url: %s) responds status %d", source, resp.StatusCode))
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "image/") {
+		return nil, errors.Wrap(ErrInvalidAvatar, fmt.Sprintf("download avatar(url: %s) responds content type %s", source, contentType))
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxAvatarBytes+1))
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("cannot download avatar(url: %s)", source))
+	}
+	if len(data) > maxAvatarBytes {
+		return nil, errors.Wrap(ErrInvalidAvatar, fmt.Sprintf("avatar(url: %s) exceeds %d bytes", source, maxAvatarBytes))
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidAvatar, fmt.Sprintf("cannot decode avatar(url: %s). %s", source, err.Error()))
+	}
+	if config.Width*config.Height > maxAvatarPixels {
+		return nil, errors.Wrap(ErrInvalidAvatar, fmt.Sprintf("avatar(url: %s) of %dx%d exceeds %d pixels", source, config.Width, config.Height, maxAvatarPixels))
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidAvatar, fmt.Sprintf("cannot decode avatar(url: %s). %s", source, err.Error()))
+	}
+	return img, nil
+}
+
+// cropSquare returns the largest square at the center of the image
+func cropSquare(img image.Image) image.Rectangle {
+	b := img.Bounds()
+	size := b.Dx()
+	if b.Dy() < size {
+		size = b.Dy()
+	}
+
+	x := b.Min.X + (b.Dx()-size)/2
+	y := b.Min.Y + (b.Dy()-size)/2
+	return image.Rect(x, y, x+size, y+size)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/models"
+)
+
+type fakeAvatarStore struct {
+	objects map[string][]byte
+}
+
+func (s *fakeAvatarStore) Put(key, contentType string, data []byte) (string, error) {
+	s.objects[key] = data
+	return "https://avatars.example.com/" + key, nil
+}
+
+// newFakeAvatarSource serves a 300x200 png at /avatar.png, a text at /avatar.txt, a broken png at /broken.png
+// and a 5000x4000 png, which is small in bytes, at /huge.png
+func newFakeAvatarSource(t *testing.T) *httptest.Server {
+	var buf, huge bytes.Buffer
+
+	img := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	for x := 0; x < 300; x++ {
+		for y := 0; y < 200; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(&huge, image.NewPaletted(image.Rect(0, 0, 5000, 4000), color.Palette{color.Black, color.White})); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/avatar.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/avatar.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("not an image"))
+	})
+	mux.HandleFunc("/broken.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes()[:100])
+	})
+	mux.HandleFunc("/huge.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(huge.Bytes())
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestStoreVariants(t *testing.T) {
+	source := newFakeAvatarSource(t)
+	defer source.Close()
+
+	t.Run("Given an image", func(t *testing.T) {
+		store := &fakeAvatarStore{objects: make(map[string][]byte)}
+		s := NewAvatarService(source.Client(), store)
+
+		pictures, err := s.StoreVariants(source.URL+"/avatar.png", "avatars/1/facebook")
+		if err != nil {
+			t.Fatalf("expect no error, but got %v", err)
+		}
+
+		for key, size := range map[string]int{
+			"avatars/1/facebook-small.jpg":  AvatarSizes.Small,
+			"avatars/1/facebook-medium.jpg": AvatarSizes.Medium,
+			"avatars/1/facebook-large.jpg":  AvatarSizes.Large,
+		} {
+			data, ok := store.objects[key]
+			if !ok {
+				t.Errorf("expect the variant %s stored, but got %v", key, store.objects)
+				continue
+			}
+
+			img, err := jpeg.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Errorf("expect the variant %s in jpeg, but got %v", key, err)
+				continue
+			}
+			if b := img.Bounds(); b.Dx() != size || b.Dy() != size {
+				t.Errorf("expect the variant %s in %dx%d, but got %dx%d", key, size, size, b.Dx(), b.Dy())
+			}
+		}
+
+		if pictures.Small.String != "https://avatars.example.com/avatars/1/facebook-small.jpg" ||
+			pictures.Medium.String != "https://avatars.example.com/avatars/1/facebook-medium.jpg" ||
+			pictures.Large.String != "https://avatars.example.com/avatars/1/facebook-large.jpg" {
+			t.Errorf("expect the URLs of the variants, but got %v", pictures)
+		}
+	})
+
+	for name, path := range map[string]string{
+		"Given a non-image":     "/avatar.txt",
+		"Given a broken image":  "/broken.png",
+		"Given a missing image": "/not-found.png",
+		"Given a huge image":    "/huge.png",
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := &fakeAvatarStore{objects: make(map[string][]byte)}
+			s := NewAvatarService(source.Client(), store)
+
+			pictures, err := s.StoreVariants(source.URL+path, "avatars/1/facebook")
+			if errors.Cause(err) != ErrInvalidAvatar {
+				t.Errorf("expect ErrInvalidAvatar, but got %v", err)
+			}
+			if len(store.objects) != 0 {
+				t.Errorf("expect no variant stored, but got %d", len(store.objects))
+			}
+
+			data, _ := json.Marshal(pictures)
+			if string(data) != "null" {
+				t.Errorf("expect the pictures encoded as null, but got %s", data)
+			}
+		})
+	}
+}
+
+func TestAvatarPicturesJSON(t *testing.T) {
+	data, _ := json.Marshal(models.AvatarPictures{})
+	if string(data) != "null" {
+		t.Errorf("expect null, but got %s", data)
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"twreporter.org/go-api/configs"
+	"twreporter.org/go-api/utils"
+)
+
+const (
+	// fcmBatchSize is the maximum number of the messages sent concurrently,
+	// the next batch is not sent until the previous one completes
+	fcmBatchSize = 500
+	fcmScope     = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmEndpoint  = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+
+	fcmErrorType            = "type.googleapis.com/google.firebase.fcm.v1.FcmError"
+	fcmBadRequestType       = "type.googleapis.com/google.rpc.BadRequest"
+	fcmErrorUnregistered    = "UNREGISTERED"
+	fcmErrorInvalidArgument = "INVALID_ARGUMENT"
+)
+
+// PushNotification is the notification shown on the devices, along with the data handled by the app
+type PushNotification struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// PushService defines an interface sending the push notifications to the devices
+type PushService interface {
+	// Send sends the notification to each of the device tokens,
+	// and returns the tokens which are no longer valid and should be deleted.
+	Send(tokens []string, notification PushNotification) (invalidTokens []string, err error)
+}
+
+// NewFCMService returns a FCMService authorized by the service account key of `credentials_path`.
+// The messages are sent to `project_id`, or the project of the service account if it is empty.
+func NewFCMService(conf configs.PushConfig) (*FCMService, error) {
+	data, err := ioutil.ReadFile(conf.CredentialsPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to read fcm credentials")
+	}
+
+	jwtConf, err := google.JWTConfigFromJSON(data, fcmScope)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to parse fcm credentials")
+	}
+
+	projectID := conf.ProjectID
+	if projectID == "" {
+		var key struct {
+			ProjectID string `json:"project_id"`
+		}
+		json.Unmarshal(data, &key)
+		projectID = key.ProjectID
+	}
+	if projectID == "" {
+		return nil, errors.New("fcm project id is not provided")
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, utils.NewHTTPClient(conf.Timeout))
+	client := jwtConf.Client(ctx)
+	client.Timeout = conf.Timeout
+
+	return newFCMService(client, fmt.Sprintf(fcmEndpoint, projectID)), nil
+}
+
+func newFCMService(client *http.Client, endpoint string) *FCMService {
+	return &FCMService{client: client, endpoint: endpoint}
+}
+
+// FCMService implements PushService interface, which sends the messages by FCM HTTP v1 API
+type FCMService struct {
+	client   *http.Client
+	endpoint string
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			Type            string `json:"@type"`
+			ErrorCode       string `json:"errorCode"`
+			FieldViolations []struct {
+				Field string `json:"field"`
+			} `json:"fieldViolations"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// isInvalidToken reports whether the token is unregistered, e.g. the app is uninstalled,
+// or is not a valid registration token
+func (r fcmErrorResponse) isInvalidToken() bool {
+	var errorCode string
+	var tokenViolated bool
+
+	for _, detail := range r.Error.Details {
+		switch detail.Type {
+		case fcmErrorType:
+			errorCode = detail.ErrorCode
+		case fcmBadRequestType:
+			for _, violation := range detail.FieldViolations {
+				tokenViolated = tokenViolated || violation.Field == "message.token"
+			}
+		}
+	}
+
+	// INVALID_ARGUMENT is also responded for the invalid payload, which is not the fault of the token
+	return errorCode == fcmErrorUnregistered || (errorCode == fcmErrorInvalidArgument && tokenViolated)
+}
+
+// Send sends the messages in batches of `fcmBatchSize` tokens since FCM HTTP v1 API sends a message per request.
+// All the tokens are tried even if some of them fail, the first error is returned with the number of failures.
+func (s *FCMService) Send(tokens []string, notification PushNotification) ([]string, error) {
+	var mu sync.Mutex
+	var firstErr error
+	var failures int
+	var invalidTokens []string
+
+	for start := 0; start < len(tokens); start += fcmBatchSize {
+		end := start + fcmBatchSize
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+
+		var wg sync.WaitGroup
+		for _, token := range tokens[start:end] {
+			wg.Add(1)
+			go func(token string) {
+				defer wg.Done()
+
+				invalid, err := s.send(token, notification)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if invalid {
+					invalidTokens = append(invalidTokens, token)
+				} else if err != nil {
+					failures++
+					if firstErr == nil {
+						firstErr = err
+					}
+				}
+			}(token)
+		}
+		wg.Wait()
+	}
+
+	if firstErr != nil {
+		return invalidTokens, errors.WithMessage(firstErr, fmt.Sprintf("fail to send %d of %d fcm messages", failures, len(tokens)))
+	}
+	return invalidTokens, nil
+}
+
+// send sends the message to the token, and reports whether the token is invalid
+func (s *FCMService) send(token string, notification PushNotification) (bool, error) {
+	body, _ := json.Marshal(struct {
+		Message fcmMessage `json:"message"`
+	}{
+		Message: fcmMessage{
+			Token:        token,
+			Notification: fcmNotification{Title: notification.Title, Body: notification.Body},
+			Data:         notification.Data,
+		},
+	})
+
+	resp, err := s.client.Post(s.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "cannot send fcm message")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		return false, nil
+	}
+
+	var errResp fcmErrorResponse
+	if err = json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		return false, errors.New(fmt.Sprintf("send fcm message responds status %d", resp.StatusCode))
+	}
+	if errResp.isInvalidToken() {
+		return true, nil
+	}
+	return false, errors.New(fmt.Sprintf("send fcm message responds status %d: %s", resp.StatusCode, errResp.Error.Message))
+}
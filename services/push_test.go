@@ -0,0 +1,92 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	mockUnregisteredResponse = `{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND",
+		"details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`
+	mockInvalidTokenResponse = `{"error":{"code":400,"message":"The registration token is not a valid FCM registration token","status":"INVALID_ARGUMENT",
+		"details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"INVALID_ARGUMENT"},
+		{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"message.token","description":"Invalid registration token"}]}]}}`
+	mockInvalidPayloadResponse = `{"error":{"code":400,"message":"Invalid value at 'message.data'","status":"INVALID_ARGUMENT",
+		"details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"INVALID_ARGUMENT"},
+		{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"message.data","description":"Invalid data"}]}]}}`
+	mockUnavailableResponse = `{"error":{"code":503,"message":"The service is currently unavailable.","status":"UNAVAILABLE",
+		"details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNAVAILABLE"}]}}`
+)
+
+func TestFCMServiceSend(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+
+	responses := map[string]struct {
+		status int
+		body   string
+	}{
+		"unregistered":    {http.StatusNotFound, mockUnregisteredResponse},
+		"invalid-token":   {http.StatusBadRequest, mockInvalidTokenResponse},
+		"invalid-payload": {http.StatusBadRequest, mockInvalidPayloadResponse},
+		"unavailable":     {http.StatusServiceUnavailable, mockUnavailableResponse},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Message fcmMessage `json:"message"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		received = append(received, body.Message.Token)
+		mu.Unlock()
+
+		if body.Message.Notification.Title != "mock title" || body.Message.Data["slug"] != "mock-slug" {
+			t.Errorf("unexpected message %+v", body.Message)
+		}
+
+		if resp, ok := responses[body.Message.Token]; ok {
+			w.WriteHeader(resp.status)
+			fmt.Fprint(w, resp.body)
+			return
+		}
+		fmt.Fprint(w, `{"name":"projects/mock/messages/1"}`)
+	}))
+	defer server.Close()
+
+	tokens := []string{"unregistered", "invalid-token", "invalid-payload", "unavailable"}
+	// more than a batch
+	for i := 0; i < fcmBatchSize; i++ {
+		tokens = append(tokens, fmt.Sprintf("valid-%d", i))
+	}
+
+	s := newFCMService(server.Client(), server.URL)
+	invalidTokens, err := s.Send(tokens, PushNotification{
+		Title: "mock title",
+		Body:  "mock body",
+		Data:  map[string]string{"slug": "mock-slug"},
+	})
+
+	if len(received) != len(tokens) {
+		t.Errorf("expected %d messages sent, got %d", len(tokens), len(received))
+	}
+
+	sort.Strings(invalidTokens)
+	if want := []string{"invalid-token", "unregistered"}; fmt.Sprint(invalidTokens) != fmt.Sprint(want) {
+		t.Errorf("expected invalid tokens %v, got %v", want, invalidTokens)
+	}
+
+	if err == nil {
+		t.Fatal("expected the failures are reported")
+	}
+	if want := fmt.Sprintf("fail to send 2 of %d fcm messages", len(tokens)); !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("expected error %s, got %s", want, err.Error())
+	}
+}
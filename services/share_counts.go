@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/configs"
+	"twreporter.org/go-api/utils"
+)
+
+const (
+	shareCountsTimeout       = 10 * time.Second
+	facebookShareCountsURL   = "https://graph.facebook.com/v12.0/"
+	lineShareCountsURL       = "https://api.line.me/social-plugin/metrics"
+	maxShareCountsBodyLength = 1 << 16
+)
+
+// ShareCounter defines an interface counting how many times the url is shared on a social network
+type ShareCounter interface {
+	CountShares(ctx context.Context, url string) (int, error)
+}
+
+// NewFacebookShareCounter returns a ShareCounter querying the Graph API by the app access token of the facebook oauth app.
+// nil is returned if the app is not configured.
+func NewFacebookShareCounter(conf configs.FacebookConfig) ShareCounter {
+	if conf.ID == "" || conf.Secret == "" {
+		return nil
+	}
+	return &facebookShareCounter{
+		client:      utils.NewHTTPClient(shareCountsTimeout),
+		endpoint:    facebookShareCountsURL,
+		accessToken: conf.ID + "|" + conf.Secret,
+	}
+}
+
+type facebookShareCounter struct {
+	client      *http.Client
+	endpoint    string
+	accessToken string
+}
+
+// CountShares returns the share count of the engagement of the url
+func (f *facebookShareCounter) CountShares(ctx context.Context, target string) (int, error) {
+	var body struct {
+		Engagement struct {
+			ShareCount int `json:"share_count"`
+		} `json:"engagement"`
+	}
+
+	query := url.Values{"id": {target}, "fields": {"engagement"}, "access_token": {f.accessToken}}
+	if err := getShareCounts(ctx, f.client, f.endpoint+"?"+query.Encode(), &body); err != nil {
+		return 0, errors.WithMessage(err, "fail to count facebook shares")
+	}
+	return body.Engagement.ShareCount, nil
+}
+
+// NewLineShareCounter returns a ShareCounter querying the metrics of the LINE social plugins, which requires no credentials
+func NewLineShareCounter() ShareCounter {
+	return &lineShareCounter{client: utils.NewHTTPClient(shareCountsTimeout), endpoint: lineShareCountsURL}
+}
+
+type lineShareCounter struct {
+	client   *http.Client
+	endpoint string
+}
+
+// CountShares returns the share count of the url, which LINE responds as a string
+func (l *lineShareCounter) CountShares(ctx context.Context, target string) (int, error) {
+	var body struct {
+		Share int `json:"share,string"`
+	}
+
+	query := url.Values{"url": {target}}
+	if err := getShareCounts(ctx, l.client, l.endpoint+"?"+query.Encode(), &body); err != nil {
+		return 0, errors.WithMessage(err, "fail to count line shares")
+	}
+	return body.Share, nil
+}
+
+func getShareCounts(ctx context.Context, client *http.Client, endpoint string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		// the url is left out since it contains the access token
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return errors.Wrap(err, "cannot request the share counts")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxShareCountsBodyLength))
+		return errors.New(fmt.Sprintf("unexpected status code %d", resp.StatusCode))
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxShareCountsBodyLength)).Decode(v); err != nil {
+		return errors.Wrap(err, "cannot decode the share counts")
+	}
+	return nil
+}
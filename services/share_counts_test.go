@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"twreporter.org/go-api/configs"
+)
+
+func TestShareCounters(t *testing.T) {
+	const target = "https://www.twreporter.org/a/mock-slug"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/facebook":
+			if r.URL.Query().Get("id") != target || r.URL.Query().Get("access_token") != "id|secret" {
+				t.Errorf("unexpected facebook query %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"engagement":{"reaction_count":10,"comment_count":2,"share_count":5},"id":"`+target+`"}`)
+		case "/line":
+			if r.URL.Query().Get("url") != target {
+				t.Errorf("unexpected line query %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"share":"7"}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"invalid token"}}`)
+		}
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name    string
+		counter ShareCounter
+		want    int
+		wantErr bool
+	}{
+		{name: "Given facebook", counter: &facebookShareCounter{client: server.Client(), endpoint: server.URL + "/facebook", accessToken: "id|secret"}, want: 5},
+		{name: "Given line", counter: &lineShareCounter{client: server.Client(), endpoint: server.URL + "/line"}, want: 7},
+		{name: "Given a failed request", counter: &facebookShareCounter{client: server.Client(), endpoint: server.URL + "/unknown", accessToken: "id|secret"}, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			count, err := tc.counter.CountShares(context.Background(), target)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expect an error, but got nil")
+				}
+				if strings.Contains(err.Error(), "secret") {
+					t.Errorf("expect the access token left out of the error, but got %s", err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, but got %s", err.Error())
+			}
+			if count != tc.want {
+				t.Errorf("expect %d shares, but got %d", tc.want, count)
+			}
+		})
+	}
+
+	if NewFacebookShareCounter(configs.FacebookConfig{}) != nil {
+		t.Error("expect the facebook counter disabled without the oauth app")
+	}
+}
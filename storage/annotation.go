@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+)
+
+const colAnnotations = "annotations"
+
+// AnnotationStorage defines the methods to store the editorial notes of the paragraphs of the posts
+type AnnotationStorage interface {
+	GetAnnotationsOfAPost(string) ([]models.Annotation, error)
+	GetAnnotation(string) (models.Annotation, error)
+	CreateAnnotation(models.Annotation) (models.Annotation, error)
+	UpdateAnnotation(string, bson.M) (models.Annotation, error)
+	DeleteAnnotation(string) error
+}
+
+// hexObjectID converts the id of the kind, e.g. annotation, in hex, the malformed one is not found
+func hexObjectID(kind, id string) (bson.ObjectId, error) {
+	if !bson.IsObjectIdHex(id) {
+		return "", errors.Wrap(ErrMgoNotFound, fmt.Sprintf("%s(id: %s) is not a valid object id", kind, id))
+	}
+	return bson.ObjectIdHex(id), nil
+}
+
+// GetAnnotationsOfAPost gets the annotations of the post in the order of the paragraphs,
+// and the annotations of the same paragraph are sorted by createdAt
+func (m *MongoStorage) GetAnnotationsOfAPost(slug string) ([]models.Annotation, error) {
+	var annotations []models.Annotation
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		var found []models.Annotation
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		if err := session.DB(globals.Conf.DB.Mongo.DBname).C(colAnnotations).Find(bson.M{"postSlug": slug}).Sort("paragraphIndex", "createdAt", "_id").All(&found); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get annotations of post(slug: %s) occurs error", slug))
+		}
+		annotations = found
+		return nil
+	})
+	return annotations, err
+}
+
+// GetAnnotation gets the annotation by id
+func (m *MongoStorage) GetAnnotation(id string) (models.Annotation, error) {
+	var annotation models.Annotation
+
+	objectID, err := hexObjectID("annotation", id)
+	if err != nil {
+		return annotation, err
+	}
+
+	session := m.db.Copy()
+	defer session.Close()
+
+	if err = session.DB(globals.Conf.DB.Mongo.DBname).C(colAnnotations).FindId(objectID).One(&annotation); err != nil {
+		return annotation, errors.Wrap(err, fmt.Sprintf("get annotation(id: %s) occurs error", id))
+	}
+	return annotation, nil
+}
+
+// CreateAnnotation stores the annotation of the paragraph
+func (m *MongoStorage) CreateAnnotation(annotation models.Annotation) (models.Annotation, error) {
+	session := m.db.Copy()
+	defer session.Close()
+
+	annotation.ID = bson.NewObjectId()
+	annotation.CreatedAt = time.Now()
+
+	if err := session.DB(globals.Conf.DB.Mongo.DBname).C(colAnnotations).Insert(annotation); err != nil {
+		return models.Annotation{}, errors.Wrap(err, fmt.Sprintf("create annotation of post(slug: %s) occurs error", annotation.PostSlug))
+	}
+	return annotation, nil
+}
+
+// UpdateAnnotation updates the fields of the annotation by id, and returns the updated one
+func (m *MongoStorage) UpdateAnnotation(id string, fields bson.M) (models.Annotation, error) {
+	var annotation models.Annotation
+
+	objectID, err := hexObjectID("annotation", id)
+	if err != nil {
+		return annotation, err
+	}
+
+	session := m.db.Copy()
+	defer session.Close()
+
+	change := mgo.Change{Update: bson.M{"$set": fields}, ReturnNew: true}
+	if _, err = session.DB(globals.Conf.DB.Mongo.DBname).C(colAnnotations).FindId(objectID).Apply(change, &annotation); err != nil {
+		return annotation, errors.Wrap(err, fmt.Sprintf("update annotation(id: %s, fields: %v) occurs error", id, fields))
+	}
+	return annotation, nil
+}
+
+// DeleteAnnotation removes the annotation by id
+func (m *MongoStorage) DeleteAnnotation(id string) error {
+	objectID, err := hexObjectID("annotation", id)
+	if err != nil {
+		return err
+	}
+
+	session := m.db.Copy()
+	defer session.Close()
+
+	if err = session.DB(globals.Conf.DB.Mongo.DBname).C(colAnnotations).RemoveId(objectID); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("delete annotation(id: %s) occurs error", id))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -105,7 +106,6 @@ func (m *MongoStorage) GetEmbeddedAsset(entity models.NewsEntity, embedded []str
 				}
 				break
 			case "relateds", "topic_relateds":
-				var _relateds []models.Post
 				if ids := entity.GetEmbeddedAsset("RelatedsOrigin"); ids != nil {
 					if len(ids) > 0 {
 						query := models.MongoQuery{
@@ -117,17 +117,9 @@ func (m *MongoStorage) GetEmbeddedAsset(entity models.NewsEntity, embedded []str
 						if ele == "topic_relateds" {
 							embedded = []string{"hero_image", "categories", "tags", "og_image"}
 						}
-						relateds, _, err := m.GetMetaOfPosts(query, 0, 0, "-publishedDate", embedded)
-						for _, id := range ids {
-							for _, related := range relateds {
-								if id.Hex() == related.ID.Hex() {
-									_relateds = append(_relateds, related)
-								}
-							}
-						}
-
+						relateds, _, err := m.GetMetaOfPosts(context.Background(), query, 0, 0, "-publishedDate", embedded)
 						if err == nil {
-							entity.SetEmbeddedAsset("Relateds", _relateds)
+							entity.SetEmbeddedAsset("Relateds", orderPostsByIDs(ids, relateds))
 						}
 					}
 				}
@@ -141,7 +133,7 @@ func (m *MongoStorage) GetEmbeddedAsset(entity models.NewsEntity, embedded []str
 							},
 						}
 
-						topics, _, err := m.GetMetaOfTopics(query, 0, 0, "-publishedDate", nil)
+						topics, _, err := m.GetMetaOfTopics(context.Background(), query, 0, 0, "-publishedDate", nil)
 
 						if err == nil && len(topics) > 0 {
 							entity.SetEmbeddedAsset("Topic", &topics[0])
@@ -158,7 +150,7 @@ func (m *MongoStorage) GetEmbeddedAsset(entity models.NewsEntity, embedded []str
 							},
 						}
 
-						topics, _, err := m.GetFullTopics(query, 0, 0, "-publishedDate", nil)
+						topics, _, err := m.GetFullTopics(context.Background(), query, 0, 0, "-publishedDate", nil)
 
 						if err == nil && len(topics) > 0 {
 							entity.SetEmbeddedAsset("Topic", &topics[0])
@@ -186,7 +178,7 @@ func (m *MongoStorage) _GetAssetsByIDs(ids []bson.ObjectId, collectionName strin
 		},
 	}
 
-	_, err := m.GetDocuments(query, 0, 0, "_id", collectionName, v)
+	_, err := m.GetDocuments(context.Background(), query, 0, 0, "_id", collectionName, v)
 
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("get assets %s by ids %v occurs error", collectionName, ids))
@@ -198,7 +190,6 @@ func (m *MongoStorage) _GetAssetsByIDs(ids []bson.ObjectId, collectionName strin
 // GetAuthors - get whole author documents by their objectIDs
 func (m *MongoStorage) GetAuthors(ids []bson.ObjectId) ([]models.Author, error) {
 	var authors []models.Author
-	var orderedAuthors []models.Author
 
 	if ids == nil {
 		return authors, nil
@@ -210,12 +201,19 @@ func (m *MongoStorage) GetAuthors(ids []bson.ObjectId) ([]models.Author, error)
 		},
 	}
 
-	_, err := m.GetDocuments(query, 0, 0, "_id", "contacts", &authors)
+	_, err := m.GetDocuments(context.Background(), query, 0, 0, "_id", "contacts", &authors)
 
 	if err != nil {
 		return authors, err
 	}
 
+	return orderAuthorsByIDs(ids, authors), nil
+}
+
+// orderAuthorsByIDs sorts the authors in the order of the ids
+func orderAuthorsByIDs(ids []bson.ObjectId, authors []models.Author) []models.Author {
+	var orderedAuthors []models.Author
+
 	for _, id := range ids {
 		for _, author := range authors {
 			if author.ID == id {
@@ -224,5 +222,5 @@ func (m *MongoStorage) GetAuthors(ids []bson.ObjectId) ([]models.Author, error)
 		}
 	}
 
-	return orderedAuthors, nil
+	return orderedAuthors
 }
@@ -3,7 +3,9 @@ package storage
 import (
 	"fmt"
 	"strconv"
+	"time"
 
+	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 
 	"twreporter.org/go-api/models"
@@ -110,3 +112,167 @@ func (g *GormStorage) DeleteABookmarkOfAUser(userID, bookmarkID string) error {
 
 	return nil
 }
+
+// DeleteBookmarksOfAUser this func will delete the relationships between the user and all of the bookmarks
+func (g *GormStorage) DeleteBookmarksOfAUser(userID string) error {
+	user, err := g.GetUserByID(userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = g.db.Model(&user).Association(bookmarksStr).Clear().Error
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("delete bookmarks from user(id: %s) occurs error", userID))
+	}
+
+	return nil
+}
+
+// ImportBookmarksOfAUser builds the relationships between the user and the bookmarks in a single transaction,
+// and the bookmarks not existing yet are created by the slugs and hosts. The results are in the order of the bookmarks.
+// The bookmarks bookmarked by the user already, or repeated, are skipped.
+func (g *GormStorage) ImportBookmarksOfAUser(userID string, bookmarks []models.Bookmark) ([]models.BookmarkImportResult, error) {
+	var existing []models.Bookmark
+	var bookmarkedIDs []int
+	var results = make([]models.BookmarkImportResult, 0, len(bookmarks))
+
+	user, err := g.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(bookmarks) == 0 {
+		return results, nil
+	}
+
+	var slugs = make([]string, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		slugs = append(slugs, bookmark.Slug)
+	}
+
+	tx := g.db.Begin()
+
+	if err = tx.Error; nil != err {
+		return nil, errors.Wrap(err, "cannot begin the bookmark import transaction")
+	}
+
+	if err = tx.Where("slug IN (?)", slugs).Find(&existing).Error; nil != err {
+		tx.Rollback()
+		return nil, errors.Wrap(err, fmt.Sprintf("get bookmarks(slugs: %v) occurs error", slugs))
+	}
+
+	if err = tx.Table("users_bookmarks").Where("user_id = ?", user.ID).Pluck("bookmark_id", &bookmarkedIDs).Error; nil != err {
+		tx.Rollback()
+		return nil, errors.Wrap(err, fmt.Sprintf("get bookmarks of user(id: %s) occurs error", userID))
+	}
+
+	// the bookmarks are keyed by their hosts and slugs
+	var bookmarkIDs = make(map[[2]string]uint, len(existing))
+	for _, bookmark := range existing {
+		bookmarkIDs[[2]string{bookmark.Host, bookmark.Slug}] = bookmark.ID
+	}
+
+	var bookmarked = make(map[uint]bool, len(bookmarkedIDs))
+	for _, id := range bookmarkedIDs {
+		bookmarked[uint(id)] = true
+	}
+
+	now := time.Now()
+	for _, bookmark := range bookmarks {
+		key := [2]string{bookmark.Host, bookmark.Slug}
+		id, ok := bookmarkIDs[key]
+		if !ok {
+			if err = tx.Create(&bookmark).Error; nil != err {
+				tx.Rollback()
+				return nil, errors.Wrap(err, fmt.Sprintf("create a bookmark(slug: %s, host: %s) occurs error", bookmark.Slug, bookmark.Host))
+			}
+			id = bookmark.ID
+			bookmarkIDs[key] = id
+		}
+
+		if bookmarked[id] {
+			results = append(results, models.BookmarkImportResult{Slug: bookmark.Slug, Status: models.BookmarkImportSkipped})
+			continue
+		}
+
+		if err = tx.Create(&models.UsersBookmarks{
+			UserID:     int(user.ID),
+			BookmarkID: int(id),
+			CreatedAt:  now,
+		}).Error; nil != err {
+			tx.Rollback()
+			return nil, errors.Wrap(err, fmt.Sprintf("append the bookmark(slug: %s) to the user(id: %s) occurs error", bookmark.Slug, userID))
+		}
+		bookmarked[id] = true
+		results = append(results, models.BookmarkImportResult{Slug: bookmark.Slug, Status: models.BookmarkImportAdded})
+	}
+
+	if err = tx.Commit().Error; nil != err {
+		return nil, errors.Wrap(err, "cannot commit the bookmark import transaction")
+	}
+
+	return results, nil
+}
+
+// MoveBookmarksOfPost points the bookmarks of the source slug to the target slug, e.g. after the posts are merged.
+// If the target is bookmarked on the same host already, the users of the source bookmark are moved to the target bookmark,
+// except those bookmarking both, and then the source bookmark is deleted.
+// It returns the number of the bookmarks of the users pointing to the target now.
+func (g *GormStorage) MoveBookmarksOfPost(source, target string) (int, error) {
+	tx := g.db.Begin()
+
+	if err := tx.Error; nil != err {
+		return 0, errors.Wrap(err, "cannot begin the bookmark move transaction")
+	}
+
+	moved, err := moveBookmarksOfPost(tx, source, target)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; nil != err {
+		return 0, errors.Wrap(err, "cannot commit the bookmark move transaction")
+	}
+	return moved, nil
+}
+
+func moveBookmarksOfPost(tx *gorm.DB, source, target string) (int, error) {
+	var bookmarks []models.Bookmark
+	var moved int
+
+	if err := tx.Unscoped().Set("gorm:query_option", "FOR UPDATE").Where("slug = ?", source).Find(&bookmarks).Error; err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("get bookmarks(slug: %s) error", source))
+	}
+
+	for _, bookmark := range bookmarks {
+		var targetBookmark models.Bookmark
+		var users int
+
+		err := tx.Unscoped().Set("gorm:query_option", "FOR UPDATE").Where("slug = ? AND host = ?", target, bookmark.Host).First(&targetBookmark).Error
+		switch {
+		case gorm.IsRecordNotFoundError(err):
+			if err := tx.Table("users_bookmarks").Where("bookmark_id = ?", bookmark.ID).Count(&users).Error; err != nil {
+				return 0, errors.Wrap(err, fmt.Sprintf("count users of bookmark(id: %d) error", bookmark.ID))
+			}
+			if err := tx.Unscoped().Model(&bookmark).UpdateColumn("slug", target).Error; err != nil {
+				return 0, errors.Wrap(err, fmt.Sprintf("cannot point bookmark(id: %d) to slug(%s)", bookmark.ID, target))
+			}
+			moved += users
+		case err != nil:
+			return 0, errors.Wrap(err, fmt.Sprintf("get bookmark(slug: %s, host: %s) error", target, bookmark.Host))
+		default:
+			// the users bookmarking both are left behind and deleted along with the source bookmark
+			res := tx.Exec("UPDATE `users_bookmarks` SET `bookmark_id` = ? WHERE `bookmark_id` = ? AND `user_id` NOT IN (SELECT `user_id` FROM (SELECT `user_id` FROM `users_bookmarks` WHERE `bookmark_id` = ?) AS `u`)",
+				targetBookmark.ID, bookmark.ID, targetBookmark.ID)
+			if err := res.Error; err != nil {
+				return 0, errors.Wrap(err, fmt.Sprintf("cannot move users of bookmark(id: %d) to bookmark(id: %d)", bookmark.ID, targetBookmark.ID))
+			}
+			if err := tx.Unscoped().Delete(&bookmark).Error; err != nil {
+				return 0, errors.Wrap(err, fmt.Sprintf("cannot delete bookmark(id: %d)", bookmark.ID))
+			}
+			moved += int(res.RowsAffected)
+		}
+	}
+	return moved, nil
+}
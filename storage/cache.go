@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+// cacheScanCount is the number of keys hinted to Redis in each SCAN iteration
+const cacheScanCount = 100
+
+// CacheStorage manages the cached entries in Redis
+type CacheStorage struct {
+	client *redis.Client
+}
+
+// NewCacheStorage initializes the storage of the cache
+func NewCacheStorage(client *redis.Client) *CacheStorage {
+	return &CacheStorage{client}
+}
+
+// DeleteKeys deletes the cached keys and returns the number of the keys deleted
+func (c *CacheStorage) DeleteKeys(keys []string) (int64, error) {
+	deleted, err := c.client.Del(keys...).Result()
+	if err != nil {
+		return deleted, errors.Wrap(err, fmt.Sprintf("delete cache(keys: %v) occurs error", keys))
+	}
+	return deleted, nil
+}
+
+// DeleteKeysByPattern deletes the cached keys matching the glob-style pattern.
+// The keys are iterated by SCAN rather than KEYS, so that Redis is not blocked.
+func (c *CacheStorage) DeleteKeysByPattern(pattern string) (int64, error) {
+	var cursor uint64
+	var deleted int64
+
+	for {
+		keys, next, err := c.client.Scan(cursor, pattern, cacheScanCount).Result()
+		if err != nil {
+			return deleted, errors.Wrap(err, fmt.Sprintf("scan cache(pattern: %s) occurs error", pattern))
+		}
+
+		if len(keys) > 0 {
+			n, err := c.DeleteKeys(keys)
+			deleted += n
+			if err != nil {
+				return deleted, err
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
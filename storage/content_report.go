@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+)
+
+const (
+	colContentReports      = "content_reports"
+	colContentReportQuotas = "content_report_quotas"
+)
+
+// ContentReportStorage defines the methods to store the content reports of the readers
+type ContentReportStorage interface {
+	CreateContentReport(models.ContentReport, int) (models.ContentReport, error)
+	GetContentReports(string, int, int) ([]models.ContentReport, int, error)
+}
+
+// CreateContentReport stores the pending report if the user reports the post less than `max` times,
+// otherwise ErrTooManyReports is returned.
+// The reports are counted by an atomic upsert of the quota keyed by the user and the post, whose _id is unique,
+// so that the concurrent reports cannot exceed the limit.
+func (m *MongoStorage) CreateContentReport(report models.ContentReport, max int) (models.ContentReport, error) {
+	session := m.db.Copy()
+	defer session.Close()
+
+	db := session.DB(globals.Conf.DB.Mongo.DBname)
+
+	// the quota exceeded is not matched, so the upsert inserts the same _id again and fails
+	quota := bson.M{"_id": fmt.Sprintf("%d:%s", report.UserID, report.PostSlug), "count": bson.M{"$lt": max}}
+	if _, err := db.C(colContentReportQuotas).Upsert(quota, bson.M{"$inc": bson.M{"count": 1}}); err != nil {
+		if mgo.IsDup(err) {
+			return models.ContentReport{}, errors.WithStack(ErrTooManyReports)
+		}
+		return models.ContentReport{}, errors.Wrap(err, fmt.Sprintf("count content reports of post(slug: %s) by user(id: %d) occurs error", report.PostSlug, report.UserID))
+	}
+
+	report.ID = bson.NewObjectId()
+	report.CreatedAt = time.Now()
+	report.Status = models.ReportStatusPending
+
+	if err := db.C(colContentReports).Insert(report); err != nil {
+		// give the quota back since the report is not stored
+		db.C(colContentReportQuotas).UpdateId(quota["_id"], bson.M{"$inc": bson.M{"count": -1}})
+		return models.ContentReport{}, errors.Wrap(err, fmt.Sprintf("create content report of post(slug: %s) occurs error", report.PostSlug))
+	}
+	return report, nil
+}
+
+// GetContentReports gets the reports of the status, sorted by createdAt descendingly
+func (m *MongoStorage) GetContentReports(status string, limit int, offset int) ([]models.ContentReport, int, error) {
+	var reports []models.ContentReport
+	var total int
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		var found []models.ContentReport
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		col := session.DB(globals.Conf.DB.Mongo.DBname).C(colContentReports)
+		if err := col.Find(bson.M{"status": status}).Sort("-createdAt").Skip(offset).Limit(limit).All(&found); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get content reports(status: %s) occurs error", status))
+		}
+
+		n, err := col.Find(bson.M{"status": status}).Count()
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("count content reports(status: %s) occurs error", status))
+		}
+		reports, total = found, n
+		return nil
+	})
+	return reports, total, err
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+)
+
+const colCorrections = "corrections"
+
+// CorrectionStorage defines the methods to store the corrections of the posts.
+// The corrections are the public records of the errata, they are updated but never deleted.
+type CorrectionStorage interface {
+	GetCorrectionsOfAPost(string) ([]models.Correction, error)
+	CreateCorrection(models.Correction) (models.Correction, error)
+	UpdateCorrection(string, bson.M) (models.Correction, error)
+}
+
+// GetCorrectionsOfAPost gets the corrections of the post, the latest issued come first
+func (m *MongoStorage) GetCorrectionsOfAPost(slug string) ([]models.Correction, error) {
+	var corrections []models.Correction
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		var found []models.Correction
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		if err := session.DB(globals.Conf.DB.Mongo.DBname).C(colCorrections).Find(bson.M{"postSlug": slug}).Sort("-issuedAt", "-_id").All(&found); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get corrections of post(slug: %s) occurs error", slug))
+		}
+		corrections = found
+		return nil
+	})
+	return corrections, err
+}
+
+// CreateCorrection stores the correction of the post, it is issued now if IssuedAt is zero
+func (m *MongoStorage) CreateCorrection(correction models.Correction) (models.Correction, error) {
+	session := m.db.Copy()
+	defer session.Close()
+
+	now := time.Now()
+	correction.ID = bson.NewObjectId()
+	correction.UpdatedAt = now
+	if correction.IssuedAt.IsZero() {
+		correction.IssuedAt = now
+	}
+
+	if err := session.DB(globals.Conf.DB.Mongo.DBname).C(colCorrections).Insert(correction); err != nil {
+		return models.Correction{}, errors.Wrap(err, fmt.Sprintf("create correction of post(slug: %s) occurs error", correction.PostSlug))
+	}
+	return correction, nil
+}
+
+// UpdateCorrection updates the fields of the correction by id, and returns the updated one
+func (m *MongoStorage) UpdateCorrection(id string, fields bson.M) (models.Correction, error) {
+	var correction models.Correction
+
+	objectID, err := hexObjectID("correction", id)
+	if err != nil {
+		return correction, err
+	}
+
+	session := m.db.Copy()
+	defer session.Close()
+
+	fields["updatedAt"] = time.Now()
+	change := mgo.Change{Update: bson.M{"$set": fields}, ReturnNew: true}
+	if _, err = session.DB(globals.Conf.DB.Mongo.DBname).C(colCorrections).FindId(objectID).Apply(change, &correction); err != nil {
+		return correction, errors.Wrap(err, fmt.Sprintf("update correction(id: %s, fields: %v) occurs error", id, fields))
+	}
+	return correction, nil
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/models"
+)
+
+// erasedUserColumns are the personal data of the user erased on data deletion
+var erasedUserColumns = []string{
+	"email",
+	"first_name",
+	"last_name",
+	"security_id",
+	"passport_id",
+	"city",
+	"state",
+	"country",
+	"zip",
+	"address",
+	"phone",
+	"birthday",
+	"gender",
+	"education",
+}
+
+// DeleteUserDataByOAuth deletes the data of the users linked to the OAuth account in a transaction.
+// The accounts, bookmarks, subscriptions, registrations, device tokens, category subscriptions and reading history of the users are deleted,
+// while the users are soft deleted with the personal data erased,
+// since the donations referring to them are kept for the receipts.
+// The rows are deleted explicitly, since `ON DELETE CASCADE` never fires on the soft deletion.
+// Nothing is deleted if no user is linked to the OAuth account.
+func (gs *GormStorage) DeleteUserDataByOAuth(aType, aID string) error {
+	var userIDs []uint
+
+	// SELECT user_id FROM o_auth_accounts WHERE type = $aType AND a_id = $aID
+	if err := gs.db.Unscoped().Model(&models.OAuthAccount{}).Where("type = ? AND a_id = ?", aType, aID).Pluck("user_id", &userIDs).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("get users of oauth account(type: %s, a_id: %s) error", aType, aID))
+	}
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	tx := gs.db.Begin()
+
+	if err := tx.Error; nil != err {
+		return errors.Wrap(err, "cannot begin the user data deletion transaction")
+	}
+
+	var erased = map[string]interface{}{"deleted_at": time.Now()}
+	for _, column := range erasedUserColumns {
+		erased[column] = nil
+	}
+
+	for _, stmt := range []struct {
+		table  string
+		delete func(*gorm.DB) *gorm.DB
+	}{
+		{"users_bookmarks", func(db *gorm.DB) *gorm.DB {
+			return db.Exec("DELETE FROM `users_bookmarks` WHERE `user_id` IN (?)", userIDs)
+		}},
+		{"web_push_subs", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Where("user_id IN (?)", userIDs).Delete(models.WebPushSubscription{})
+		}},
+		{"registrations", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Where("user_id IN (?)", userIDs).Delete(models.Registration{})
+		}},
+		{"device_tokens", func(db *gorm.DB) *gorm.DB {
+			return db.Where("user_id IN (?)", userIDs).Delete(models.DeviceToken{})
+		}},
+		{"category_subscriptions", func(db *gorm.DB) *gorm.DB {
+			return db.Where("user_id IN (?)", userIDs).Delete(models.CategorySubscription{})
+		}},
+		{"reading_histories", func(db *gorm.DB) *gorm.DB {
+			return db.Where("user_id IN (?)", userIDs).Delete(models.ReadingHistory{})
+		}},
+		{"o_auth_accounts", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Where("user_id IN (?)", userIDs).Delete(models.OAuthAccount{})
+		}},
+		{"reporter_accounts", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Where("user_id IN (?)", userIDs).Delete(models.ReporterAccount{})
+		}},
+		{"users", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Model(&models.User{}).Where("id IN (?)", userIDs).Updates(erased)
+		}},
+	} {
+		if err := stmt.delete(tx).Error; nil != err {
+			tx.Rollback()
+			return errors.Wrap(err, fmt.Sprintf("cannot delete %s of users(ids: %v)", stmt.table, userIDs))
+		}
+	}
+
+	if err := tx.Commit().Error; nil != err {
+		return errors.Wrap(err, "cannot commit the user data deletion transaction")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/models"
+)
+
+// PushStorage defines the methods to manage the device tokens and the category subscriptions of the push notifications
+type PushStorage interface {
+	SaveDeviceToken(models.DeviceToken) (models.DeviceToken, error)
+	DeleteDeviceTokens([]string) error
+	GetDeviceTokensOfCategories([]string) ([]models.DeviceToken, error)
+	CreateCategorySubscription(models.CategorySubscription) (models.CategorySubscription, error)
+	GetCategorySubscriptionsOfAUser(string) ([]models.CategorySubscription, error)
+	DeleteCategorySubscription(string, string) error
+}
+
+// SaveDeviceToken creates the device token, or binds the existing one with the user
+// since the app could be signed in by another user on the same device
+func (g *GormStorage) SaveDeviceToken(deviceToken models.DeviceToken) (models.DeviceToken, error) {
+	var saved models.DeviceToken
+
+	if err := g.db.Where(models.DeviceToken{Token: deviceToken.Token}).
+		Assign(models.DeviceToken{UserID: deviceToken.UserID, Platform: deviceToken.Platform}).
+		FirstOrCreate(&saved).Error; err != nil {
+		return saved, errors.Wrap(err, fmt.Sprintf("save device token of user(id: %d) error", deviceToken.UserID))
+	}
+	return saved, nil
+}
+
+// DeleteDeviceTokens deletes the device tokens, e.g. the tokens unregistered from FCM
+func (g *GormStorage) DeleteDeviceTokens(tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// DELETE FROM device_tokens WHERE token IN $tokens
+	if err := g.db.Where("token IN (?)", tokens).Delete(&models.DeviceToken{}).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("delete %d device tokens error", len(tokens)))
+	}
+	return nil
+}
+
+// GetDeviceTokensOfCategories returns the device tokens of the users subscribing any of the categories,
+// the blocked and deleted users are excluded.
+func (g *GormStorage) GetDeviceTokensOfCategories(categoryIDs []string) ([]models.DeviceToken, error) {
+	var tokens []models.DeviceToken
+
+	if len(categoryIDs) == 0 {
+		return tokens, nil
+	}
+
+	// SELECT DISTINCT device_tokens.* FROM device_tokens
+	// JOIN category_subscriptions ON category_subscriptions.user_id = device_tokens.user_id
+	// JOIN users ON users.id = device_tokens.user_id
+	// WHERE category_subscriptions.category_id IN $categoryIDs AND users.deleted_at IS NULL AND users.blocked = false
+	if err := g.db.Table("device_tokens").Select("DISTINCT device_tokens.*").
+		Joins("JOIN category_subscriptions ON category_subscriptions.user_id = device_tokens.user_id").
+		Joins("JOIN users ON users.id = device_tokens.user_id").
+		Where("category_subscriptions.category_id IN (?)", categoryIDs).
+		Where("users.deleted_at IS NULL AND users.blocked = ?", false).
+		Order("device_tokens.id").
+		Find(&tokens).Error; err != nil {
+		return tokens, errors.Wrap(err, fmt.Sprintf("get device tokens of categories(%s) error", strings.Join(categoryIDs, ", ")))
+	}
+	return tokens, nil
+}
+
+// CreateCategorySubscription subscribes the user to the category
+func (g *GormStorage) CreateCategorySubscription(sub models.CategorySubscription) (models.CategorySubscription, error) {
+	if err := g.db.Create(&sub).Error; err != nil {
+		return sub, errors.Wrap(err, fmt.Sprintf("create subscription of category(id: %s) of user(id: %d) error", sub.CategoryID, sub.UserID))
+	}
+	return sub, nil
+}
+
+// GetCategorySubscriptionsOfAUser returns the category subscriptions of the user in the order they are created
+func (g *GormStorage) GetCategorySubscriptionsOfAUser(userID string) ([]models.CategorySubscription, error) {
+	var subs = make([]models.CategorySubscription, 0)
+
+	if err := g.db.Where("user_id = ?", userID).Order("created_at, id").Find(&subs).Error; err != nil {
+		return subs, errors.Wrap(err, fmt.Sprintf("get category subscriptions of user(id: %s) error", userID))
+	}
+	return subs, nil
+}
+
+// DeleteCategorySubscription unsubscribes the user from the category
+func (g *GormStorage) DeleteCategorySubscription(userID, categoryID string) error {
+	var sub models.CategorySubscription
+
+	if err := g.db.First(&sub, "user_id = ? AND category_id = ?", userID, categoryID).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("get subscription of category(id: %s) of user(id: %s) error", categoryID, userID))
+	}
+
+	if err := g.db.Delete(&sub).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("delete subscription of category(id: %s) of user(id: %s) error", categoryID, userID))
+	}
+	return nil
+}
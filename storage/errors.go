@@ -1,10 +1,18 @@
 package storage
 
 import (
+	"net/http"
+	"strings"
+	"time"
+
 	"github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
 	"gopkg.in/mgo.v2"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
 )
 
 // ErrRecordNotFound record not found error, happens when haven't find any matched data when looking up with a struct
@@ -13,6 +21,24 @@ var ErrRecordNotFound = gorm.ErrRecordNotFound
 // ErrMgoNotFound record not found error when accessing MongoDB
 var ErrMgoNotFound = mgo.ErrNotFound
 
+// ErrQueryTimeout query does not finish within the configured timeout
+var ErrQueryTimeout = errors.New("query timeout")
+
+// ErrUpdateConflict the record is updated by others after it is read
+var ErrUpdateConflict = errors.New("record is updated concurrently")
+
+// ErrTopicPostsMismatch the posts to reorder are not exactly the posts of the topic
+var ErrTopicPostsMismatch = errors.New("posts do not match the posts of the topic")
+
+// ErrInvalidDateRange the end date of the subscription is not after its start date
+var ErrInvalidDateRange = errors.New("end_date should be after start_date")
+
+// ErrTooManyReports the user reports the post more times than allowed
+var ErrTooManyReports = errors.New("too many reports of the post")
+
+// ErrMergeSameUser the users to merge are the same user, e.g. `01` and `1`
+var ErrMergeSameUser = errors.New("cannot merge the user into itself")
+
 func IsNotFound(err error) bool {
 	cause := errors.Cause(err)
 
@@ -21,6 +47,8 @@ func IsNotFound(err error) bool {
 		return true
 	case ErrMgoNotFound:
 		return true
+	case mongo.ErrNoDocuments:
+		return true
 	default:
 		// omit intentionally
 	}
@@ -43,5 +71,91 @@ func IsConflict(err error) bool {
 	default:
 		// omit intentionally
 	}
+	return cause == ErrUpdateConflict
+}
+
+func IsTimeout(err error) bool {
+	// ErrMgoExceededTimeLimit operation is terminated by MongoDB due to maxTimeMS
+	var ErrMgoExceededTimeLimit = 50
+
+	cause := errors.Cause(err)
+
+	switch e := cause.(type) {
+	case *mgo.QueryError:
+		return e.Code == ErrMgoExceededTimeLimit
+	default:
+		// omit intentionally
+	}
+	return cause == ErrQueryTimeout
+}
+
+// isReadOnlyCode checks the MongoDB error code is caused by writing to the secondary,
+// which happens when the primary steps down during failovers
+func isReadOnlyCode(code int) bool {
+	const (
+		ErrMgoPrimarySteppedDown              = 189
+		ErrMgoNotMaster                       = 10107
+		ErrMgoInterruptedDueToReplStateChange = 11602
+		ErrMgoNotMasterNoSlaveOk              = 13435
+		ErrMgoNotMasterOrSecondary            = 13436
+	)
+
+	switch code {
+	case ErrMgoPrimarySteppedDown, ErrMgoNotMaster, ErrMgoInterruptedDueToReplStateChange, ErrMgoNotMasterNoSlaveOk, ErrMgoNotMasterOrSecondary:
+		return true
+	default:
+		// omit intentionally
+	}
 	return false
 }
+
+// IsReadOnly checks the write is rejected since the database is read-only, e.g. during failovers
+func IsReadOnly(err error) bool {
+	const (
+		// ErrOptionPreventsStatement the server is running with --read-only option
+		ErrOptionPreventsStatement uint16 = 1290
+		// ErrCantExecuteInReadOnlyTransaction the statement is executed in a read-only transaction
+		ErrCantExecuteInReadOnlyTransaction uint16 = 1792
+	)
+
+	cause := errors.Cause(err)
+
+	switch e := cause.(type) {
+	case *models.AppError:
+		return IsReadOnly(e.Err)
+	case *mysql.MySQLError:
+		return e.Number == ErrOptionPreventsStatement || e.Number == ErrCantExecuteInReadOnlyTransaction
+	case *mgo.LastError:
+		return isReadOnlyCode(e.Code)
+	case *mgo.QueryError:
+		return isReadOnlyCode(e.Code)
+	case mongo.CommandError:
+		return isReadOnlyCode(int(e.Code))
+	case mongo.WriteException:
+		if e.WriteConcernError != nil && isReadOnlyCode(e.WriteConcernError.Code) {
+			return true
+		}
+		for _, we := range e.WriteErrors {
+			if isReadOnlyCode(we.Code) {
+				return true
+			}
+		}
+	default:
+		// omit intentionally
+	}
+
+	// mgo reports the write to the secondary without error code
+	return cause != nil && strings.HasPrefix(cause.Error(), "not master")
+}
+
+// NewReadOnlyError wraps the write rejected by the read-only database into AppError,
+// which is responded with 503 and Retry-After header so the client can retry after the failover
+func NewReadOnlyError(err error) *models.AppError {
+	return &models.AppError{
+		Code:       models.ErrCodeServiceReadOnly,
+		StatusCode: http.StatusServiceUnavailable,
+		Message:    "Service is temporarily read-only, please retry later.",
+		RetryAfter: time.Duration(globals.Conf.DB.ReadOnlyRetryAfter) * time.Second,
+		Err:        err,
+	}
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2"
+)
+
+func TestIsReadOnly(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "Given mysql running with read-only option",
+			err:  errors.Wrap(&mysql.MySQLError{Number: 1290, Message: "The MySQL server is running with the --read-only option"}, "create bookmark"),
+			want: true,
+		},
+		{
+			name: "Given mongo not master error",
+			err:  errors.WithStack(&mgo.LastError{Code: 10107, Err: "not master"}),
+			want: true,
+		},
+		{
+			name: "Given mongo not master error without code",
+			err:  errors.WithStack(errors.New("not master")),
+			want: true,
+		},
+		{
+			name: "Given mongo driver write exception",
+			err:  mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 10107, Message: "not master"}}},
+			want: true,
+		},
+		{
+			name: "Given duplicate entry error",
+			err:  errors.WithStack(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}),
+			want: false,
+		},
+		{
+			name: "Given not found error",
+			err:  errors.WithStack(ErrMgoNotFound),
+			want: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsReadOnly(tc.err); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "Given gorm record not found error",
+			err:  errors.Wrap(ErrRecordNotFound, "get user"),
+			want: true,
+		},
+		{
+			name: "Given mgo not found error",
+			err:  errors.WithStack(mgo.ErrNotFound),
+			want: true,
+		},
+		{
+			name: "Given mongo driver no documents error",
+			err:  errors.Wrap(mongo.ErrNoDocuments, "get post version"),
+			want: true,
+		},
+		{
+			name: "Given other error",
+			err:  errors.New("connection refused"),
+			want: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsNotFound(tc.err); got != tc.want {
+				t.Errorf("expect %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
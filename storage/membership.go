@@ -27,8 +27,10 @@ type MembershipStorage interface {
 	/** User methods **/
 	GetUserByID(string) (models.User, error)
 	GetUserByEmail(string) (models.User, error)
+	GetUsersByIDs([]uint) (map[uint]models.User, error)
 	GetOAuthData(null.String, string) (models.OAuthAccount, error)
 	GetUserDataByOAuth(models.OAuthAccount) (models.User, error)
+	GetOAuthAccountsOfAUser(string) ([]models.OAuthAccount, error)
 	GetReporterAccountData(string) (models.ReporterAccount, error)
 	GetUserDataByReporterAccount(models.ReporterAccount) (models.User, error)
 	InsertOAuthAccount(models.OAuthAccount) error
@@ -36,7 +38,10 @@ type MembershipStorage interface {
 	InsertUserByOAuth(models.OAuthAccount) (models.User, error)
 	InsertUserByReporterAccount(models.ReporterAccount) (models.User, error)
 	UpdateOAuthData(models.OAuthAccount) (models.OAuthAccount, error)
+	UpdateOAuthPictures(null.String, string, models.AvatarPictures) error
 	UpdateReporterAccount(models.ReporterAccount) error
+	DeleteUserDataByOAuth(string, string) error
+	MergeUsers(string, string) (models.UserMergeResult, error)
 
 	/** Bookmark methods **/
 	GetABookmarkBySlug(string) (models.Bookmark, error)
@@ -45,10 +50,13 @@ type MembershipStorage interface {
 	GetBookmarksOfAUser(string, int, int) ([]models.Bookmark, int, error)
 	CreateABookmarkOfAUser(string, models.Bookmark) (models.Bookmark, error)
 	DeleteABookmarkOfAUser(string, string) error
+	DeleteBookmarksOfAUser(string) error
+	ImportBookmarksOfAUser(string, []models.Bookmark) ([]models.BookmarkImportResult, error)
 
 	/** Web Push Subscription methods **/
 	CreateAWebPushSubscription(models.WebPushSubscription) error
 	GetAWebPushSubscription(uint32, string) (models.WebPushSubscription, error)
+	GetWebPushSubscriptionsOfAUser(string) ([]models.WebPushSubscription, error)
 
 	/** Donation methods **/
 	CreateAPeriodicDonation(*models.PeriodicDonation, *models.PayByCardTokenDonation) error
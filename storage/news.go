@@ -1,9 +1,14 @@
 package storage
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 
@@ -18,13 +23,39 @@ type NewsStorage interface {
 	Close() error
 
 	/** Posts methods **/
-	GetMetaOfPosts(models.MongoQuery, int, int, string, []string) ([]models.Post, int, error)
-	GetFullPosts(models.MongoQuery, int, int, string, []string) ([]models.Post, int, error)
-	GetMetaOfTopics(models.MongoQuery, int, int, string, []string) ([]models.Topic, int, error)
-	GetFullTopics(models.MongoQuery, int, int, string, []string) ([]models.Topic, int, error)
+	// the listing methods are aborted once the context is done, e.g. the client disconnects
+	GetMetaOfPosts(context.Context, models.MongoQuery, int, int, string, []string) ([]models.Post, int, error)
+	GetFullPosts(context.Context, models.MongoQuery, int, int, string, []string) ([]models.Post, int, error)
+	GetPostBySlug(string) (models.Post, error)
+	GetPublishedPostBySlug(string) (models.Post, error)
+	GetPostsByIDs([]primitive.ObjectID) ([]models.Post, error)
+	GetPostsByYearMonth(int, int, int, int) ([]models.Post, int, error)
+	GetPostsBySeries(context.Context, string) ([]models.Post, error)
+	GetSeries(context.Context) ([]models.Series, error)
+	GetPaywallLevelOfPost(string) (int, error)
+	UpdatePost(string, bson.M) error
+	SoftDeletePost(string) error
+	DuplicatePost(string) (models.Post, error)
+	GetRandomPost(bson.ObjectId, []string) (models.Post, error)
+	GetMetaOfTopics(context.Context, models.MongoQuery, int, int, string, []string) ([]models.Topic, int, error)
+	GetFullTopics(context.Context, models.MongoQuery, int, int, string, []string) ([]models.Topic, int, error)
+	GetMetaOfTopicsBySlugs([]string) ([]models.Topic, error)
+	GetTopicsUpdatedSince(context.Context, time.Time, time.Time, int, int) ([]models.Topic, int, error)
+	GetTopicWithPosts(context.Context, string, int, int) (models.Topic, []models.Post, int, error)
+	ReorderPostsOfTopic(string, []string) error
+	GetRelatedTopics([]string, string, int) ([]models.Topic, error)
+	IncrementTopicViews(string)
+	GetTopicViewsSince(time.Time, int) ([]models.TopicViews, error)
 
 	/** Authors methods **/
 	GetFullAuthors(int, int, string) ([]models.FullAuthor, int, error)
+
+	/** Tags and categories methods **/
+	GetTagBySlug(string) (models.Tag, error)
+	GetTagsBySlugs([]string) ([]models.Tag, error)
+	GetTagCountsOfTopics() ([]models.TagCount, error)
+	GetCategoryBySlug(string) (models.Category, error)
+	UpsertTag(models.Tag) (models.Tag, error)
 }
 
 // NewMongoStorage initializes the storage connected to Mongo database
@@ -43,40 +74,224 @@ func (m *MongoStorage) Close() error {
 	return nil
 }
 
+// getQueryTimeout returns the configured per-query timeout, zero means no timeout
+func getQueryTimeout() time.Duration {
+	return time.Duration(globals.Conf.DB.Mongo.QueryTimeout) * time.Second
+}
+
+// newSharedQueryContext returns the context of the query shared by the concurrent requests,
+// which is bounded by the query timeout only, rather than by the context of any request.
+func newSharedQueryContext() (context.Context, context.CancelFunc) {
+	if timeout := getQueryTimeout(); timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// withQueryTimeout runs the query in another goroutine and returns ErrQueryTimeout
+// if the query does not finish within the timeout.
+// The context passed to the query is canceled once the timeout is exceeded.
+// Since the query may still be running after timeout, it should not write to the variables of the caller.
+func withQueryTimeout(timeout time.Duration, query func(ctx context.Context) error) error {
+	return withQueryTimeoutContext(context.Background(), timeout, query)
+}
+
+// withQueryTimeoutContext is withQueryTimeout bounded by the parent context as well,
+// the error of the parent context, e.g. context.Canceled, is returned if it is done before the query finishes.
+func withQueryTimeoutContext(parent context.Context, timeout time.Duration, query func(ctx context.Context) error) error {
+	if timeout <= 0 && parent.Done() == nil {
+		return query(parent)
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, timeout)
+	}
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- query(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if err := parent.Err(); err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(ErrQueryTimeout)
+	}
+}
+
 // GetDocuments ...
-func (m *MongoStorage) GetDocuments(qs models.MongoQuery, limit int, offset int, sort string, collection string, documents interface{}) (count int, err error) {
+func (m *MongoStorage) GetDocuments(ctx context.Context, qs models.MongoQuery, limit int, offset int, sort string, collection string, documents interface{}) (count int, err error) {
 	var dbname = globals.Conf.DB.Mongo.DBname
+	var timeout = getQueryTimeout()
+	var total int
+
+	// decode to a new value, and copy it to `documents` only if the query finishes in time
+	result := reflect.New(reflect.TypeOf(documents).Elem())
+
+	err = withQueryTimeoutContext(ctx, timeout, func(ctx context.Context) error {
+		// session is copied and closed in the query goroutine,
+		// so that it is still available for the query running after timeout
+		session := m.db.Copy()
+		defer session.Close()
 
-	session := m.db.Copy()
-	defer session.Close()
+		// MongoDB terminates the query if it exceeds the timeout
+		// multiple sort fields are separated by comma, e.g. "updatedAt,_id"
+		query := session.DB(dbname).C(collection).Find(qs).Limit(limit).Skip(offset).Sort(strings.Split(sort, ",")...)
+		countQuery := session.DB(dbname).C(collection).Find(qs)
+		if timeout > 0 {
+			query = query.SetMaxTime(timeout)
+			countQuery = countQuery.SetMaxTime(timeout)
+		}
 
-	err = session.DB(dbname).C(collection).Find(qs).Limit(limit).Skip(offset).Sort(sort).All(documents)
+		if err := query.All(result.Interface()); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get documents by conditions(where: %#v, limit: %d, offset: %d, sort: %s, collection:%s) occurs error", qs, limit, offset, sort, collection))
+		}
+
+		c, err := countQuery.Count()
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("count documents by condition(where: %#v, collection: %s) occurs error", qs, collection))
+		}
+
+		total = c
+		return nil
+	})
 
 	if err != nil {
-		return 0, errors.Wrap(err, fmt.Sprintf("get documents by conditions(where: %#v, limit: %d, offset: %d, sort: %s, collection:%s) occurs error", qs, limit, offset, sort, collection))
+		return 0, errors.WithMessage(err, fmt.Sprintf("query documents(collection: %s) within %v", collection, timeout))
 	}
 
-	count, err = session.DB(dbname).C(collection).Find(qs).Count()
+	reflect.ValueOf(documents).Elem().Set(result.Elem())
+	return total, nil
+}
+
+// AggregateDocuments is the same as GetDocuments, except that the stages are appended to the aggregation
+// after the documents are matched, sorted and paginated, e.g. $lookup the referred documents inline.
+func (m *MongoStorage) AggregateDocuments(ctx context.Context, qs models.MongoQuery, limit int, offset int, sort string, collection string, stages []bson.M, documents interface{}) (int, error) {
+	var dbname = globals.Conf.DB.Mongo.DBname
+	var timeout = getQueryTimeout()
+	var total int
+
+	pipeline := []bson.M{{"$match": qs}}
+	if fields := sortFields(sort); len(fields) > 0 {
+		pipeline = append(pipeline, bson.M{"$sort": fields})
+	}
+	if offset > 0 {
+		pipeline = append(pipeline, bson.M{"$skip": offset})
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": limit})
+	}
+	pipeline = append(pipeline, stages...)
+
+	// decode to a new value, and copy it to `documents` only if the query finishes in time
+	result := reflect.New(reflect.TypeOf(documents).Elem())
+
+	err := withQueryTimeoutContext(ctx, timeout, func(ctx context.Context) error {
+		session := m.db.Copy()
+		defer session.Close()
+
+		// the aggregation is terminated by MongoDB after timeout as the find query
+		if err := pipeWithMaxTime(session.DB(dbname).C(collection), pipeline, timeout).All(result.Interface()); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("aggregate documents by conditions(where: %#v, limit: %d, offset: %d, sort: %s, collection:%s) occurs error", qs, limit, offset, sort, collection))
+		}
+
+		countQuery := session.DB(dbname).C(collection).Find(qs)
+		if timeout > 0 {
+			countQuery = countQuery.SetMaxTime(timeout)
+		}
+		c, err := countQuery.Count()
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("count documents by condition(where: %#v, collection: %s) occurs error", qs, collection))
+		}
+
+		total = c
+		return nil
+	})
 
 	if err != nil {
-		return 0, errors.Wrap(err, fmt.Sprintf("count documents by condition(where: %#v, collection: %s) occurs error", qs, collection))
+		return 0, errors.WithMessage(err, fmt.Sprintf("query documents(collection: %s) within %v", collection, timeout))
 	}
 
-	return count, nil
+	reflect.ValueOf(documents).Elem().Set(result.Elem())
+	return total, nil
 }
 
-// GetDocument ...
-func (m *MongoStorage) GetDocument(id bson.ObjectId, collection string, doc interface{}) error {
+// pipeWithMaxTime runs the aggregation as Pipe.Iter does, and
+// limits the time MongoDB spends on the aggregation by `maxTimeMS` if the timeout is positive,
+// since the version of mgo does not provide Pipe.SetMaxTime.
+func pipeWithMaxTime(c *mgo.Collection, pipeline interface{}, timeout time.Duration) *mgo.Iter {
+	var result struct {
+		Cursor struct {
+			FirstBatch []bson.Raw `bson:"firstBatch"`
+			ID         int64      `bson:"id"`
+		}
+	}
+
+	cmd := bson.D{
+		{Name: "aggregate", Value: c.Name},
+		{Name: "pipeline", Value: pipeline},
+		{Name: "cursor", Value: bson.M{}},
+	}
+	if timeout > 0 {
+		cmd = append(cmd, bson.DocElem{Name: "maxTimeMS", Value: int64(timeout / time.Millisecond)})
+	}
+
+	err := c.Database.Run(cmd, &result)
+	return c.NewIter(nil, result.Cursor.FirstBatch, result.Cursor.ID, err)
+}
+
+// sortFields converts the sort of mgo, e.g. "-publishedDate,_id", to the document of `$sort` stage
+func sortFields(sort string) bson.D {
+	var fields bson.D
+	for _, field := range strings.Split(sort, ",") {
+		switch {
+		case field == "":
+			// omit intentionally
+		case strings.HasPrefix(field, "-"):
+			fields = append(fields, bson.DocElem{Name: field[1:], Value: -1})
+		default:
+			fields = append(fields, bson.DocElem{Name: strings.TrimPrefix(field, "+"), Value: 1})
+		}
+	}
+	return fields
+}
+
+// GetDocument finds the document of the id in the collection, the query stops once ctx is done
+func (m *MongoStorage) GetDocument(ctx context.Context, id bson.ObjectId, collection string, doc interface{}) error {
 	if id == "" {
 		return errors.Wrap(ErrMgoNotFound, "can not get document by zeroed string")
 	}
-	session := m.db.Copy()
-	defer session.Close()
 
-	err := session.DB(globals.Conf.DB.Mongo.DBname).C(collection).FindId(id).One(doc)
+	var timeout = getQueryTimeout()
+
+	// decode to a new value, and copy it to `doc` only if the query finishes in time
+	result := reflect.New(reflect.TypeOf(doc).Elem())
+
+	err := withQueryTimeoutContext(ctx, timeout, func(ctx context.Context) error {
+		session := m.db.Copy()
+		defer session.Close()
+
+		query := session.DB(globals.Conf.DB.Mongo.DBname).C(collection).FindId(id)
+		if timeout > 0 {
+			query = query.SetMaxTime(timeout)
+		}
+
+		if err := query.One(result.Interface()); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get document(id: %v, collection: %s) occurs error", id, collection))
+		}
+		return nil
+	})
 
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("get document(id: %v, collection: %s) occurs error", id, collection))
+		return err
 	}
+
+	reflect.ValueOf(doc).Elem().Set(result.Elem())
 	return nil
 }
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+const (
+	// postsCacheTTL is the period the listed posts are served from the cache
+	postsCacheTTL = 5 * time.Minute
+	// postsCachePrefix is the prefix of the keys caching the listed posts
+	postsCachePrefix = "news:posts:"
+	// postsCacheIndex is the set of the keys caching the listed posts,
+	// which are deleted all together when any post is modified
+	postsCacheIndex = postsCachePrefix + "keys"
+)
+
+// CachedNewsStorage wraps `MongoStorage` and caches the listed posts in Redis.
+// The cached posts are invalidated when the posts are modified through it.
+type CachedNewsStorage struct {
+	*MongoStorage
+	client *redis.Client
+}
+
+// NewCachedNewsStorage initializes the storage caching the results of `MongoStorage` in Redis
+func NewCachedNewsStorage(m *MongoStorage, client *redis.Client) *CachedNewsStorage {
+	return &CachedNewsStorage{
+		MongoStorage: m,
+		client:       client,
+	}
+}
+
+type postsCacheEntry struct {
+	Posts []models.Post `json:"posts"`
+	Total int           `json:"total"`
+}
+
+// getPostsCacheKey prefixes the hash of the listing arguments with the namespace and the method
+func getPostsCacheKey(method string, mq models.MongoQuery, limit int, offset int, sort string, embedded []string) (string, error) {
+	sum, err := hashListingArgs(mq, limit, offset, sort, embedded)
+	if err != nil {
+		return "", err
+	}
+	return postsCachePrefix + method + ":" + sum, nil
+}
+
+// hashListingArgs hashes the stable JSON serialization of the listing arguments.
+// JSON encoder outputs struct fields in declaration order, so the same query always has the same hash.
+func hashListingArgs(mq models.MongoQuery, limit int, offset int, sort string, embedded []string) (string, error) {
+	args, err := json.Marshal(struct {
+		Query    models.MongoQuery `json:"query"`
+		Limit    int               `json:"limit"`
+		Offset   int               `json:"offset"`
+		Sort     string            `json:"sort"`
+		Embedded []string          `json:"embedded"`
+		Expand   []string          `json:"expand"`
+		AnyState bool              `json:"any_state"`
+		AuthorID string            `json:"author_id"`
+	}{
+		Query:    mq,
+		Limit:    limit,
+		Offset:   offset,
+		Sort:     sort,
+		Embedded: embedded,
+		Expand:   mq.ExpandFields,
+		AnyState: mq.AnyState,
+		AuthorID: mq.AuthorID,
+	})
+
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("serialize query(where: %#v) occurs error", mq))
+	}
+
+	sum := sha1.Sum(args)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// getCachedPosts serves the posts from the cache if present, otherwise it gets the posts by `get` and caches them.
+// Cache errors are logged, and the posts are got by `get` instead.
+func (c *CachedNewsStorage) getCachedPosts(key string, get func() ([]models.Post, int, error)) ([]models.Post, int, error) {
+	var entry postsCacheEntry
+
+	data, err := c.client.Get(key).Bytes()
+	switch {
+	case err == nil:
+		if err = json.Unmarshal(data, &entry); err == nil {
+			return entry.Posts, entry.Total, nil
+		}
+		log.Warnf("%+v", errors.Wrap(err, fmt.Sprintf("decode cached posts(key: %s) occurs error", key)))
+	case err != redis.Nil:
+		log.Warnf("%+v", errors.Wrap(err, fmt.Sprintf("get cached posts(key: %s) occurs error", key)))
+	}
+
+	posts, total, err := get()
+	if err != nil {
+		return posts, total, err
+	}
+
+	if data, err = json.Marshal(postsCacheEntry{Posts: posts, Total: total}); err != nil {
+		log.Warnf("%+v", errors.Wrap(err, fmt.Sprintf("encode posts(key: %s) occurs error", key)))
+		return posts, total, nil
+	}
+
+	_, err = c.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Set(key, data, postsCacheTTL)
+		pipe.SAdd(postsCacheIndex, key)
+		pipe.Expire(postsCacheIndex, postsCacheTTL)
+		return nil
+	})
+	if err != nil {
+		log.Warnf("%+v", errors.Wrap(err, fmt.Sprintf("cache posts(key: %s) occurs error", key)))
+	}
+
+	return posts, total, nil
+}
+
+// invalidatePosts deletes all the cached listed posts,
+// since the modified post may appear in any of them.
+func (c *CachedNewsStorage) invalidatePosts() error {
+	keys, err := c.client.SMembers(postsCacheIndex).Result()
+	if err != nil {
+		return errors.Wrap(err, "get cached posts keys occurs error")
+	}
+
+	keys = append(keys, postsCacheIndex)
+	if err = c.client.Del(keys...).Err(); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("delete cached posts(keys: %v) occurs error", keys))
+	}
+	return nil
+}
+
+// InvalidatePosts deletes all the cached listed posts,
+// it is called after the posts are modified by the other storage, e.g. merged by the client supporting transactions.
+func (c *CachedNewsStorage) InvalidatePosts() error {
+	return c.invalidatePosts()
+}
+
+// GetMetaOfPosts is a cached version of `MongoStorage.GetMetaOfPosts`
+func (c *CachedNewsStorage) GetMetaOfPosts(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Post, int, error) {
+	key, err := getPostsCacheKey("meta", mq, limit, offset, sort, embedded)
+	if err != nil {
+		return c.MongoStorage.GetMetaOfPosts(ctx, mq, limit, offset, sort, embedded)
+	}
+
+	return c.getCachedPosts(key, func() ([]models.Post, int, error) {
+		return c.MongoStorage.GetMetaOfPosts(ctx, mq, limit, offset, sort, embedded)
+	})
+}
+
+// GetFullPosts is a cached version of `MongoStorage.GetFullPosts`
+func (c *CachedNewsStorage) GetFullPosts(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Post, int, error) {
+	key, err := getPostsCacheKey("full", mq, limit, offset, sort, embedded)
+	if err != nil {
+		return c.MongoStorage.GetFullPosts(ctx, mq, limit, offset, sort, embedded)
+	}
+
+	return c.getCachedPosts(key, func() ([]models.Post, int, error) {
+		return c.MongoStorage.GetFullPosts(ctx, mq, limit, offset, sort, embedded)
+	})
+}
+
+// UpdatePost updates the post and invalidates the cached posts
+func (c *CachedNewsStorage) UpdatePost(slug string, fields bson.M) error {
+	if err := c.MongoStorage.UpdatePost(slug, fields); err != nil {
+		return err
+	}
+	return c.invalidatePosts()
+}
+
+// SoftDeletePost soft deletes the post and invalidates the cached posts
+func (c *CachedNewsStorage) SoftDeletePost(slug string) error {
+	if err := c.MongoStorage.SoftDeletePost(slug); err != nil {
+		return err
+	}
+	return c.invalidatePosts()
+}
+
+// DuplicatePost duplicates the post and invalidates the cached posts
+func (c *CachedNewsStorage) DuplicatePost(slug string) (models.Post, error) {
+	post, err := c.MongoStorage.DuplicatePost(slug)
+	if err != nil {
+		return post, err
+	}
+	return post, c.invalidatePosts()
+}
+
+// ReorderPostsOfTopic reorders the posts of the topic and invalidates the cached posts,
+// since the posts embed their topics along with the related posts
+func (c *CachedNewsStorage) ReorderPostsOfTopic(slug string, order []string) error {
+	if err := c.MongoStorage.ReorderPostsOfTopic(slug, order); err != nil {
+		return err
+	}
+	return c.invalidatePosts()
+}
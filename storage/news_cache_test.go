@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+func TestGetPostsCacheKey(t *testing.T) {
+	id := bson.NewObjectId()
+	mq := models.MongoQuery{State: "published", Categories: models.MongoQueryComparison{In: []bson.ObjectId{id}}}
+
+	key, err := getPostsCacheKey("meta", mq, 10, 0, "-publishedDate", nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if !strings.HasPrefix(key, postsCachePrefix+"meta:") {
+		t.Errorf("expected key prefixed with %s, got %s", postsCachePrefix+"meta:", key)
+	}
+
+	// the same query always has the same key
+	sameQuery := models.MongoQuery{State: "published", Categories: models.MongoQueryComparison{In: []bson.ObjectId{id}}}
+	if sameKey, _ := getPostsCacheKey("meta", sameQuery, 10, 0, "-publishedDate", nil); sameKey != key {
+		t.Errorf("expected key %s, got %s", key, sameKey)
+	}
+
+	cases := []struct {
+		name     string
+		method   string
+		mq       models.MongoQuery
+		offset   int
+		embedded []string
+	}{
+		{name: "Given different method", method: "full", mq: mq},
+		{name: "Given different query", method: "meta", mq: models.MongoQuery{State: "published"}},
+		{name: "Given different offset", method: "meta", mq: mq, offset: 10},
+		{name: "Given different embedded assets", method: "meta", mq: mq, embedded: []string{"og_image"}},
+		{name: "Given the author filter", method: "meta", mq: models.MongoQuery{State: "published", Categories: mq.Categories, AuthorID: id.Hex()}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, _ := getPostsCacheKey(tc.method, tc.mq, 10, tc.offset, "-publishedDate", tc.embedded); got == key {
+				t.Errorf("expected key different from %s", key)
+			}
+		})
+	}
+}
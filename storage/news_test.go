@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestWithQueryTimeout(t *testing.T) {
+	t.Run("Given a query exceeding the timeout", func(t *testing.T) {
+		canceled := make(chan struct{})
+
+		start := time.Now()
+		err := withQueryTimeout(10*time.Millisecond, func(ctx context.Context) error {
+			// slow backend
+			select {
+			case <-ctx.Done():
+				close(canceled)
+			case <-time.After(time.Second):
+			}
+			return nil
+		})
+
+		if !IsTimeout(err) {
+			t.Errorf("expected timeout error, got %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed >= time.Second {
+			t.Errorf("expected query to be reported before it finishes, got %v", elapsed)
+		}
+
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Errorf("expected the context of query to be canceled")
+		}
+	})
+
+	t.Run("Given a query finishing in time", func(t *testing.T) {
+		expected := errors.New("query error")
+		err := withQueryTimeout(time.Second, func(ctx context.Context) error {
+			return expected
+		})
+
+		if err != expected {
+			t.Errorf("expected error %v, got %v", expected, err)
+		}
+	})
+
+	t.Run("Given no timeout", func(t *testing.T) {
+		err := withQueryTimeout(0, func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); ok {
+				t.Errorf("expected context without deadline")
+			}
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+}
+
+func TestWithQueryTimeoutContext(t *testing.T) {
+	for _, timeout := range []time.Duration{0, time.Minute} {
+		t.Run("Given the parent context canceled in flight with timeout "+timeout.String(), func(t *testing.T) {
+			parent, cancel := context.WithCancel(context.Background())
+			started := make(chan struct{})
+			canceled := make(chan struct{})
+
+			go func() {
+				<-started
+				cancel()
+			}()
+
+			start := time.Now()
+			err := withQueryTimeoutContext(parent, timeout, func(ctx context.Context) error {
+				close(started)
+				// slow backend
+				select {
+				case <-ctx.Done():
+					close(canceled)
+				case <-time.After(time.Second):
+				}
+				return nil
+			})
+
+			if errors.Cause(err) != context.Canceled {
+				t.Errorf("expected context canceled error, got %v", err)
+			}
+			if IsTimeout(err) {
+				t.Errorf("expected the cancellation not reported as timeout")
+			}
+			if elapsed := time.Since(start); elapsed >= time.Second {
+				t.Errorf("expected query to be aborted before it finishes, got %v", elapsed)
+			}
+
+			select {
+			case <-canceled:
+			case <-time.After(time.Second):
+				t.Errorf("expected the context of query to be canceled")
+			}
+		})
+	}
+}
+
+func TestIsTimeout(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "Given query timeout error",
+			err:  errors.Wrap(ErrQueryTimeout, "get documents"),
+			want: true,
+		},
+		{
+			name: "Given mongo exceeded time limit error",
+			err:  errors.WithStack(&mgo.QueryError{Code: 50, Message: "operation exceeded time limit"}),
+			want: true,
+		},
+		{
+			name: "Given not found error",
+			err:  errors.WithStack(ErrMgoNotFound),
+			want: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsTimeout(tc.err); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSortFields(t *testing.T) {
+	cases := []struct {
+		name string
+		sort string
+		want bson.D
+	}{
+		{name: "Given a descending field", sort: "-publishedDate", want: bson.D{{Name: "publishedDate", Value: -1}}},
+		{name: "Given multiple fields", sort: "updatedAt,_id", want: bson.D{{Name: "updatedAt", Value: 1}, {Name: "_id", Value: 1}}},
+		{name: "Given an explicitly ascending field", sort: "+name,-_id", want: bson.D{{Name: "name", Value: 1}, {Name: "_id", Value: -1}}},
+		{name: "Given no sort", sort: "", want: nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sortFields(tc.sort); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expect %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"golang.org/x/sync/singleflight"
 	"twreporter.org/go-api/globals"
 	"twreporter.org/go-api/internal/news"
 )
@@ -17,10 +20,98 @@ type fetchResult struct {
 
 type mongoStorage struct {
 	*mongo.Client
+	// group deduplicates the concurrent identical queries
+	group singleflight.Group
 }
 
 func NewMongoV2Storage(client *mongo.Client) *mongoStorage {
-	return &mongoStorage{client}
+	return &mongoStorage{Client: client}
+}
+
+// MongoStorageOptions are the options of the client connecting to MongoDB
+type MongoStorageOptions struct {
+	// ReadPreference is primary if it is nil
+	ReadPreference *readpref.ReadPref
+}
+
+// MongoClientStorage is the storage connected to MongoDB by NewMongoStorageWithOptions
+type MongoClientStorage interface {
+	Ping(context.Context) error
+	// Disconnect closes the connections of the client, which should be called on shutdown
+	Disconnect(context.Context) error
+	// MongoClient returns the client to be shared by the other storages, see NewMongoV2Storage
+	MongoClient() *mongo.Client
+}
+
+// NewMongoStorageWithOptions connects to MongoDB of the uri and returns the storage with the client.
+// The context bounds both the connection and the ping,
+// so that the startup does not hang forever if MongoDB is unreachable.
+func NewMongoStorageWithOptions(ctx context.Context, uri string, opts MongoStorageOptions) (MongoClientStorage, error) {
+	clientOpts := options.Client().ApplyURI(uri)
+	if opts.ReadPreference != nil {
+		clientOpts = clientOpts.SetReadPreference(opts.ReadPreference)
+	}
+
+	client, err := mongo.Connect(ctx, clientOpts)
+	if err != nil {
+		return nil, errors.Wrap(err, "Establishing a new connection to cluster occurs error:")
+	}
+
+	if err = client.Ping(ctx, nil); err != nil {
+		// release the connections established already
+		client.Disconnect(context.Background())
+		return nil, errors.Wrap(err, "Connection to cluster does not response:")
+	}
+	return NewMongoV2Storage(client), nil
+}
+
+// MongoClient returns the client of the storage
+func (m *mongoStorage) MongoClient() *mongo.Client {
+	return m.Client
+}
+
+// Ping verifies the connection to the primary of MongoDB is alive without fetching any data
+func (m *mongoStorage) Ping(ctx context.Context) error {
+	if err := m.Client.Ping(ctx, readpref.Primary()); err != nil {
+		return errors.Wrap(err, "ping mongodb primary occurs error")
+	}
+	return nil
+}
+
+// fetchOnce shares one fetch among the concurrent calls with the same kind and stages.
+// The result, including the error, is only shared by the calls in flight,
+// the calls arriving after the fetch finishes trigger a new one.
+// The shared fetch is bounded by the query timeout instead of the context of any call,
+// so that a call canceled by its client neither fails nor stops the others; it only stops waiting for the result.
+func (m *mongoStorage) fetchOnce(ctx context.Context, kind string, stages []bson.D, fetch func(context.Context, []bson.D) <-chan fetchResult) <-chan fetchResult {
+	pipeline, err := bson.MarshalExtJSON(bson.D{{Key: "pipeline", Value: stages}}, true, false)
+	if err != nil {
+		return fetch(ctx, stages)
+	}
+
+	key := kind + "." + string(pipeline)
+	shared := m.group.DoChan(key, func() (interface{}, error) {
+		flightCtx, cancel := newSharedQueryContext()
+		defer cancel()
+
+		result, ok := <-fetch(flightCtx, stages)
+		if !ok {
+			return nil, errors.WithStack(flightCtx.Err())
+		}
+		return result.Content, result.Error
+	})
+
+	result := make(chan fetchResult, 1)
+	go func() {
+		defer close(result)
+		select {
+		case <-ctx.Done():
+			// closed without result, the caller reports ctx.Err()
+		case r := <-shared:
+			result <- fetchResult{Content: r.Val, Error: r.Err}
+		}
+	}()
+	return result
 }
 
 func (m *mongoStorage) GetFullPosts(ctx context.Context, q *news.Query) ([]news.Post, error) {
@@ -37,7 +128,7 @@ func (m *mongoStorage) GetFullPosts(ctx context.Context, q *news.Query) ([]news.
 	select {
 	case <-ctx.Done():
 		return nil, errors.WithStack(ctx.Err())
-	case result, ok := <-m.getFullPosts(ctx, stages):
+	case result, ok := <-m.fetchOnce(ctx, "fullPosts", stages, m.getFullPosts):
 		switch {
 		case !ok:
 			return nil, errors.WithStack(ctx.Err())
@@ -45,9 +136,6 @@ func (m *mongoStorage) GetFullPosts(ctx context.Context, q *news.Query) ([]news.
 			return nil, result.Error
 		}
 		posts = result.Content.([]news.Post)
-		for i := 0; i < len(posts); i++ {
-			posts[i].Full = true
-		}
 
 	}
 
@@ -73,6 +161,7 @@ func (m *mongoStorage) getFullPosts(ctx context.Context, stages []bson.D) <-chan
 				result <- fetchResult{Error: errors.WithStack(err)}
 				return
 			}
+			post.Full = true
 			posts = append(posts, post)
 		}
 		if err := cursor.Err(); err != nil {
@@ -97,7 +186,7 @@ func (m *mongoStorage) GetMetaOfPosts(ctx context.Context, q *news.Query) ([]new
 	select {
 	case <-ctx.Done():
 		return nil, errors.WithStack(ctx.Err())
-	case result, ok := <-m.getMetaOfPosts(ctx, stages):
+	case result, ok := <-m.fetchOnce(ctx, "metaOfPosts", stages, m.getMetaOfPosts):
 		switch {
 		case !ok:
 			return nil, errors.WithStack(ctx.Err())
@@ -155,7 +244,7 @@ func (m *mongoStorage) GetFullTopics(ctx context.Context, q *news.Query) ([]news
 	select {
 	case <-ctx.Done():
 		return nil, errors.WithStack(ctx.Err())
-	case result, ok := <-m.getFullTopics(ctx, stages):
+	case result, ok := <-m.fetchOnce(ctx, "fullTopics", stages, m.getFullTopics):
 		switch {
 		case !ok:
 			return nil, errors.WithStack(ctx.Err())
@@ -163,9 +252,6 @@ func (m *mongoStorage) GetFullTopics(ctx context.Context, q *news.Query) ([]news
 			return nil, result.Error
 		}
 		topics = result.Content.([]news.Topic)
-		for i := 0; i < len(topics); i++ {
-			topics[i].Full = true
-		}
 	}
 
 	return topics, nil
@@ -190,6 +276,7 @@ func (m *mongoStorage) getFullTopics(ctx context.Context, stages []bson.D) <-cha
 				result <- fetchResult{Error: errors.WithStack(err)}
 				return
 			}
+			topic.Full = true
 			topics = append(topics, topic)
 		}
 		if err := cursor.Err(); err != nil {
@@ -216,7 +303,7 @@ func (m *mongoStorage) GetMetaOfTopics(ctx context.Context, q *news.Query) ([]ne
 	select {
 	case <-ctx.Done():
 		return nil, errors.WithStack(ctx.Err())
-	case result, ok := <-m.getMetaOfTopics(ctx, stages):
+	case result, ok := <-m.fetchOnce(ctx, "metaOfTopics", stages, m.getMetaOfTopics):
 		switch {
 		case !ok:
 			return nil, errors.WithStack(ctx.Err())
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFetchOnce(t *testing.T) {
+	const concurrency = 10
+
+	var calls int32
+	release := make(chan struct{})
+	stages := []bson.D{{{Key: "$match", Value: bson.D{{Key: "slug", Value: "mock-slug"}}}}}
+
+	m := &mongoStorage{}
+	fetch := func(ctx context.Context, stages []bson.D) <-chan fetchResult {
+		result := make(chan fetchResult)
+		go func() {
+			defer close(result)
+			atomic.AddInt32(&calls, 1)
+			// slow backend
+			<-release
+			result <- fetchResult{Content: []string{"mock-slug"}}
+		}()
+		return result
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan fetchResult, concurrency)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- <-m.fetchOnce(context.Background(), "mock", stages, fetch)
+		}()
+	}
+
+	// wait for all the calls joining the fetch in flight
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected backend to be called once, got %d", got)
+	}
+
+	for result := range results {
+		if result.Error != nil {
+			t.Errorf("unexpected error %v", result.Error)
+		}
+		if slugs, ok := result.Content.([]string); !ok || len(slugs) != 1 {
+			t.Errorf("expected shared content, got %v", result.Content)
+		}
+	}
+}
+
+func TestFetchOnceError(t *testing.T) {
+	var calls int32
+	stages := []bson.D{{{Key: "$limit", Value: 10}}}
+
+	m := &mongoStorage{}
+	fetch := func(ctx context.Context, stages []bson.D) <-chan fetchResult {
+		result := make(chan fetchResult, 1)
+		atomic.AddInt32(&calls, 1)
+		result <- fetchResult{Error: errors.New("mock error")}
+		close(result)
+		return result
+	}
+
+	for i := 0; i < 2; i++ {
+		if result := <-m.fetchOnce(context.Background(), "mock", stages, fetch); result.Error == nil {
+			t.Errorf("expected error")
+		}
+	}
+
+	// error is not cached after the fetch finishes
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("expected backend to be called twice, got %d", got)
+	}
+}
+
+func TestFetchOnceCanceled(t *testing.T) {
+	stages := []bson.D{{{Key: "$match", Value: bson.D{{Key: "slug", Value: "canceled-slug"}}}}}
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	m := &mongoStorage{}
+	fetch := func(ctx context.Context, stages []bson.D) <-chan fetchResult {
+		result := make(chan fetchResult)
+		go func() {
+			defer close(result)
+			close(started)
+			select {
+			case <-ctx.Done():
+				result <- fetchResult{Error: errors.WithStack(ctx.Err())}
+			case <-release:
+				result <- fetchResult{Content: []string{"canceled-slug"}}
+			}
+		}()
+		return result
+	}
+
+	// the first call starts the shared fetch and then its client disconnects
+	ctx, cancel := context.WithCancel(context.Background())
+	first := m.fetchOnce(ctx, "mock", stages, fetch)
+	<-started
+	second := m.fetchOnce(context.Background(), "mock", stages, fetch)
+	cancel()
+
+	if _, ok := <-first; ok {
+		t.Errorf("expect the canceled call to stop waiting without result")
+	}
+
+	close(release)
+	if result := <-second; result.Error != nil {
+		t.Errorf("expect the other call unaffected, but got error %v", result.Error)
+	}
+}
+
+func TestNewMongoStorageWithOptions(t *testing.T) {
+	// nothing listens on the port, the ping waits for the server until the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewMongoStorageWithOptions(ctx, "mongodb://127.0.0.1:1/?connectTimeoutMS=100", MongoStorageOptions{})
+	if err == nil {
+		t.Fatal("expect an error connecting to unreachable MongoDB")
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expect giving up around the deadline, but it takes %v", elapsed)
+	}
+}
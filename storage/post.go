@@ -1,25 +1,48 @@
 package storage
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
 	"twreporter.org/go-api/globals"
 	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/utils"
 )
 
 // _GetPosts finds the posts according to query string and also get the embedded assets
-func (m *MongoStorage) _GetPosts(mq models.MongoQuery, limit int, offset int, sort string, embedded []string, isFull bool) ([]models.Post, int, error) {
+func (m *MongoStorage) _GetPosts(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string, embedded []string, isFull bool) ([]models.Post, int, error) {
 	var posts []models.Post
 
-	if globals.Conf.Environment != "development" {
+	if globals.Conf.Environment != "development" && !mq.AnyState {
 		mq.State = "published"
 	}
 
-	total, err := m.GetDocuments(mq, limit, offset, sort, "posts", &posts)
+	var total int
+	var err error
+	if mq.IsExpanded(models.ExpandAuthors) {
+		posts, total, err = m.getPostsWithAuthors(ctx, mq, limit, offset, sort)
+		// the authors are embedded already
+		embedded = withoutEmbedded(embedded, postAuthorFields...)
+	} else {
+		total, err = m.GetDocuments(ctx, mq, limit, offset, sort, "posts", &posts)
+	}
 
 	if err != nil {
 		return posts, 0, err
 	}
 
 	for index := range posts {
+		// stop populating the assets if the request is canceled
+		if err = ctx.Err(); err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
 		m.GetEmbeddedAsset(&posts[index], embedded)
 		if isFull == false {
 			posts[index].Content = nil
@@ -31,22 +54,462 @@ func (m *MongoStorage) _GetPosts(mq models.MongoQuery, limit int, offset int, so
 	return posts, total, nil
 }
 
+// postAuthorFields are the fields of the posts referring to the authors in `contacts` collection
+var postAuthorFields = []string{"writters", "photographers", "designers", "engineers"}
+
+// postWithAuthors is the post decoded along with the authors looked up by getPostsWithAuthors
+type postWithAuthors struct {
+	models.Post           `bson:",inline"`
+	ExpandedWritters      []models.Author `bson:"expandedWritters"`
+	ExpandedPhotographers []models.Author `bson:"expandedPhotographers"`
+	ExpandedDesigners     []models.Author `bson:"expandedDesigners"`
+	ExpandedEngineers     []models.Author `bson:"expandedEngineers"`
+}
+
+// getPostsWithAuthors is the same as GetDocuments of the posts,
+// except that the authors are embedded by $lookup in the same aggregation rather than a query per post.
+func (m *MongoStorage) getPostsWithAuthors(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string) ([]models.Post, int, error) {
+	var found []postWithAuthors
+
+	stages := make([]bson.M, 0, len(postAuthorFields))
+	for _, field := range postAuthorFields {
+		stages = append(stages, bson.M{"$lookup": bson.M{
+			"from":         "contacts",
+			"localField":   field,
+			"foreignField": "_id",
+			"as":           "expanded" + m._StringToPscalCase(field),
+		}})
+	}
+
+	total, err := m.AggregateDocuments(ctx, mq, limit, offset, sort, "posts", stages, &found)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	posts := make([]models.Post, 0, len(found))
+	for _, p := range found {
+		post := p.Post
+		// $lookup does not keep the order of the ids
+		post.Writters = orderAuthorsByIDs(post.WrittersOrigin, p.ExpandedWritters)
+		post.Photographers = orderAuthorsByIDs(post.PhotographersOrigin, p.ExpandedPhotographers)
+		post.Designers = orderAuthorsByIDs(post.DesignersOrigin, p.ExpandedDesigners)
+		post.Engineers = orderAuthorsByIDs(post.EngineersOrigin, p.ExpandedEngineers)
+		posts = append(posts, post)
+	}
+	return posts, total, nil
+}
+
+// withoutEmbedded removes the elements from the embedded assets
+func withoutEmbedded(embedded []string, removed ...string) []string {
+	var rest = make([]string, 0, len(embedded))
+	for _, ele := range embedded {
+		keep := true
+		for _, r := range removed {
+			keep = keep && ele != r
+		}
+		if keep {
+			rest = append(rest, ele)
+		}
+	}
+	return rest
+}
+
 // GetMetaOfPosts is a type-specific functions implementing the method defined in the NewsStorage.
 // It finds the posts according to query string and only return the metadata of posts.
-func (m *MongoStorage) GetMetaOfPosts(mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Post, int, error) {
+func (m *MongoStorage) GetMetaOfPosts(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Post, int, error) {
 	if embedded == nil {
 		embedded = []string{"hero_image", "leading_image_portrait", "categories", "tags", "topic", "og_image", "theme"}
 	}
 
-	return m._GetPosts(mq, limit, offset, sort, embedded, false)
+	return m._GetPosts(ctx, mq, limit, offset, sort, embedded, false)
+}
+
+// taipeiLocation is the timezone of the publication, which has no daylight saving time
+var taipeiLocation = time.FixedZone("Asia/Taipei", 8*60*60)
+
+// GetPostsByYearMonth gets the posts published within the month(in Taipei time) with PARTIAL corresponding assets,
+// sorted by publishedDate descendingly.
+func (m *MongoStorage) GetPostsByYearMonth(year, month int, limit int, offset int) ([]models.Post, int, error) {
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, taipeiLocation)
+
+	mq := models.MongoQuery{
+		PublishedDate: models.MongoQueryTimeComparison{GTE: start, LT: start.AddDate(0, 1, 0)},
+	}
+
+	return m.GetMetaOfPosts(context.Background(), mq, limit, offset, "-publishedDate", nil)
 }
 
 // GetFullPosts is a type-specific functions implementing the method defined in the NewsStorage.
 // It finds the posts according to query string.
-func (m *MongoStorage) GetFullPosts(mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Post, int, error) {
+func (m *MongoStorage) GetFullPosts(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Post, int, error) {
 	if embedded == nil {
 		embedded = []string{"hero_image", "leading_image_portrait", "leading_video", "categories", "tags", "topic_full", "og_image", "writters", "photographers", "designers", "engineers", "relateds", "theme"}
 	}
 
-	return m._GetPosts(mq, limit, offset, sort, embedded, true)
+	return m._GetPosts(ctx, mq, limit, offset, sort, embedded, true)
+}
+
+// GetPostsByIDs finds the meta of the posts by their ids in a single query.
+// The posts are returned in the order of the ids, and the ids not found are skipped.
+func (m *MongoStorage) GetPostsByIDs(ids []primitive.ObjectID) ([]models.Post, error) {
+	var in = make([]bson.ObjectId, 0, len(ids))
+	for _, id := range ids {
+		in = append(in, bson.ObjectIdHex(id.Hex()))
+	}
+
+	if len(in) == 0 {
+		return []models.Post{}, nil
+	}
+
+	posts, _, err := m.GetMetaOfPosts(context.Background(), models.MongoQuery{IDs: models.MongoQueryComparison{In: in}}, 0, 0, "-publishedDate", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderPostsByIDs(in, posts), nil
+}
+
+// orderPostsByIDs sorts the posts in the order of the ids, the posts of the repeated ids are repeated as well
+func orderPostsByIDs(ids []bson.ObjectId, posts []models.Post) []models.Post {
+	var byID = make(map[bson.ObjectId]models.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+
+	var ordered = make([]models.Post, 0, len(ids))
+	for _, id := range ids {
+		if post, ok := byID[id]; ok {
+			ordered = append(ordered, post)
+		}
+	}
+	return ordered
+}
+
+// GetPostBySlug finds the post by slug no matter which state it is in.
+// The embedded assets are not populated.
+func (m *MongoStorage) GetPostBySlug(slug string) (models.Post, error) {
+	var posts []models.Post
+
+	if _, err := m.GetDocuments(context.Background(), models.MongoQuery{Slug: slug}, 1, 0, "-publishedDate", "posts", &posts); err != nil {
+		return models.Post{}, err
+	}
+
+	if len(posts) == 0 {
+		return models.Post{}, errors.Wrap(ErrMgoNotFound, fmt.Sprintf("get post(slug: %s) occurs error", slug))
+	}
+
+	return posts[0], nil
+}
+
+// GetPublishedPostBySlug is GetPostBySlug excluding the posts not published,
+// for the public endpoints serving the data attached to the post
+func (m *MongoStorage) GetPublishedPostBySlug(slug string) (models.Post, error) {
+	var posts []models.Post
+
+	if _, err := m.GetDocuments(context.Background(), models.MongoQuery{Slug: slug, State: "published"}, 1, 0, "-publishedDate", "posts", &posts); err != nil {
+		return models.Post{}, err
+	}
+
+	if len(posts) == 0 {
+		return models.Post{}, errors.Wrap(ErrMgoNotFound, fmt.Sprintf("get published post(slug: %s) occurs error", slug))
+	}
+
+	return posts[0], nil
+}
+
+// GetPublishedPostsBySlugs gets the published posts of the slugs at once with their hero images,
+// the slugs without any published post are omitted.
+func (m *MongoStorage) GetPublishedPostsBySlugs(slugs []string) ([]models.Post, error) {
+	var posts []models.Post
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		var found []models.Post
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		if err := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").
+			Find(bson.M{"state": "published", "slug": bson.M{"$in": slugs}}).
+			Select(bson.M{"slug": 1, "title": 1, "og_description": 1, "heroImage": 1, "publishedDate": 1}).
+			All(&found); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get published posts(slugs: %v) occurs error", slugs))
+		}
+		posts = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range posts {
+		m.GetEmbeddedAsset(&posts[index], []string{"hero_image"})
+	}
+	return posts, nil
+}
+
+// UpdatePost updates the fields of the post by slug
+func (m *MongoStorage) UpdatePost(slug string, fields bson.M) error {
+	session := m.db.Copy()
+	defer session.Close()
+
+	if err := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").Update(bson.M{"slug": slug}, bson.M{"$set": fields}); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("update post(slug: %s, fields: %v) occurs error", slug, fields))
+	}
+	return nil
+}
+
+// SoftDeletePost marks the post by slug as deleted instead of removing the document,
+// so that the post is excluded from the published posts.
+// The post count of its tags is decremented once, by the state read along with the update atomically.
+func (m *MongoStorage) SoftDeletePost(slug string) error {
+	var previous struct {
+		State string          `bson:"state"`
+		Tags  []bson.ObjectId `bson:"tags"`
+	}
+
+	session := m.db.Copy()
+	defer session.Close()
+
+	db := session.DB(globals.Conf.DB.Mongo.DBname)
+	change := mgo.Change{Update: bson.M{"$set": bson.M{"state": postStateDeleted, "updatedAt": time.Now()}}}
+	if _, err := db.C("posts").Find(bson.M{"slug": slug}).Select(bson.M{"state": 1, "tags": 1}).Apply(change, &previous); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("soft delete post(slug: %s) occurs error", slug))
+	}
+
+	// the post is deleted already, the failure of counting is only logged
+	if previous.State != postStateDeleted && len(previous.Tags) > 0 {
+		if _, err := db.C("tags").UpdateAll(bson.M{"_id": bson.M{"$in": previous.Tags}}, bson.M{"$inc": bson.M{"postCount": -1}}); err != nil {
+			log.Errorf("%+v", errors.Wrap(err, fmt.Sprintf("decrement post count of tags(ids: %v) occurs error", previous.Tags)))
+		}
+	}
+	return nil
+}
+
+// DuplicatePost clones the post by slug as a draft, whose slug is `<slug>-copy` or `<slug>-copy-2`, etc.
+// The clone keeps the fields of the document as they are, including the embedded media,
+// except that the published date, view count and share counts are removed and the title is appended with ` (copy)`.
+func (m *MongoStorage) DuplicatePost(slug string) (models.Post, error) {
+	var doc bson.M
+	var countErr error
+
+	session := m.db.Copy()
+	defer session.Close()
+
+	db := session.DB(globals.Conf.DB.Mongo.DBname)
+	col := db.C("posts")
+
+	if err := col.Find(bson.M{"slug": slug}).Sort("-publishedDate").One(&doc); err != nil {
+		return models.Post{}, errors.Wrap(err, fmt.Sprintf("get post(slug: %s) occurs error", slug))
+	}
+
+	newSlug := utils.UniqueSlug(slug+"-copy", func(candidate string) bool {
+		n, err := col.Find(bson.M{"slug": candidate}).Count()
+		if err != nil {
+			// stop generating the slug, the error is returned below
+			countErr = err
+			return false
+		}
+		return n > 0
+	})
+	if countErr != nil {
+		return models.Post{}, errors.Wrap(countErr, fmt.Sprintf("check slug of the copy of post(slug: %s) occurs error", slug))
+	}
+
+	title, _ := doc["title"].(string)
+	delete(doc, "publishedDate")
+	delete(doc, "viewCount")
+	delete(doc, "shareCounts")
+	doc["_id"] = bson.NewObjectId()
+	doc["slug"] = newSlug
+	doc["title"] = title + " (copy)"
+	doc["state"] = "draft"
+	doc["updatedAt"] = time.Now()
+
+	if err := col.Insert(doc); err != nil {
+		return models.Post{}, errors.Wrap(err, fmt.Sprintf("insert the copy(slug: %s) of post(slug: %s) occurs error", newSlug, slug))
+	}
+
+	// the tags are counted along with the posts written through import, count the copy as well
+	if tags, ok := doc["tags"].([]interface{}); ok && len(tags) > 0 {
+		if _, err := db.C("tags").UpdateAll(bson.M{"_id": bson.M{"$in": tags}}, bson.M{"$inc": bson.M{"postCount": 1}}); err != nil {
+			log.Errorf("%+v", errors.Wrap(err, fmt.Sprintf("increment post count of tags(ids: %v) occurs error", tags)))
+		}
+	}
+
+	return m.GetPostBySlug(newSlug)
+}
+
+// IteratePosts calls fn with each post matching the query one by one, sorted by _id,
+// so that all the posts are not loaded into memory at once.
+// Iteration stops at the first error returned by fn.
+func (m *MongoStorage) IteratePosts(mq models.MongoQuery, fn func(models.Post) error) error {
+	session := m.db.Copy()
+	defer session.Close()
+
+	iter := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").Find(mq).Sort("_id").Iter()
+
+	var post models.Post
+	for iter.Next(&post) {
+		if err := fn(post); err != nil {
+			iter.Close()
+			return err
+		}
+		post = models.Post{}
+	}
+
+	if err := iter.Close(); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("iterate posts(where: %#v) occurs error", mq))
+	}
+	return nil
+}
+
+// GetRandomPost samples a published post with its meta, optionally in the category,
+// and excluding the posts of the slugs.
+func (m *MongoStorage) GetRandomPost(category bson.ObjectId, excludedSlugs []string) (models.Post, error) {
+	var posts []models.Post
+	var timeout = getQueryTimeout()
+
+	match := bson.M{"state": "published"}
+	if category.Valid() {
+		match["categories"] = category
+	}
+	if len(excludedSlugs) > 0 {
+		match["slug"] = bson.M{"$nin": excludedSlugs}
+	}
+
+	err := withQueryTimeout(timeout, func(ctx context.Context) error {
+		var sampled []models.Post
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		pipe := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").Pipe([]bson.M{
+			{"$match": match},
+			{"$sample": bson.M{"size": 1}},
+		})
+
+		if err := pipe.All(&sampled); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("sample post(where: %v) occurs error", match))
+		}
+		posts = sampled
+		return nil
+	})
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	if len(posts) == 0 {
+		return models.Post{}, errors.Wrap(ErrMgoNotFound, fmt.Sprintf("sample post(where: %v) occurs error", match))
+	}
+
+	post := posts[0]
+	m.GetEmbeddedAsset(&post, []string{"hero_image", "leading_image_portrait", "categories", "tags", "topic", "og_image", "theme"})
+	post.Content = nil
+	return post, nil
+}
+
+// GetPaywallLevelOfPost returns the paywall level of the published post, only the level is read for speed
+func (m *MongoStorage) GetPaywallLevelOfPost(slug string) (int, error) {
+	var post models.Post
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		session := m.db.Copy()
+		defer session.Close()
+
+		if err := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").
+			Find(bson.M{"slug": slug, "state": "published"}).
+			Select(bson.M{"paywallLevel": 1}).
+			One(&post); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get paywall level of post(slug: %s) occurs error", slug))
+		}
+		return nil
+	})
+	return post.PaywallLevel, err
+}
+
+// GetShareCountsOfPost returns the published post with only the share counts and the restricted countries,
+// the counts are nil if they are never polled.
+func (m *MongoStorage) GetShareCountsOfPost(slug string) (models.Post, error) {
+	var post models.Post
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		session := m.db.Copy()
+		defer session.Close()
+
+		if err := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").
+			Find(bson.M{"slug": slug, "state": "published"}).
+			Select(bson.M{"shareCounts": 1, "restrictedCountries": 1}).
+			One(&post); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get share counts of post(slug: %s) occurs error", slug))
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Post{}, err
+	}
+	return post, nil
+}
+
+// GetFootnotesOfPost returns the published post with only the footnotes and the restricted countries,
+// the content is not read
+func (m *MongoStorage) GetFootnotesOfPost(slug string) (models.Post, error) {
+	var post models.Post
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		session := m.db.Copy()
+		defer session.Close()
+
+		if err := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").
+			Find(bson.M{"slug": slug, "state": "published"}).
+			Select(bson.M{"footnotes": 1, "restrictedCountries": 1}).
+			One(&post); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get footnotes of post(slug: %s) occurs error", slug))
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Post{}, err
+	}
+	return post, nil
+}
+
+// RequestShareCountsRefresh marks the share counts of the post to be polled by the job as soon as possible
+func (m *MongoStorage) RequestShareCountsRefresh(slug string, requestedAt time.Time) error {
+	return m.UpdatePost(slug, bson.M{"shareCounts.refreshRequestedAt": requestedAt})
+}
+
+// ClaimShareCountsRefresh picks the post requested to refresh the share counts earliest and clears the request at once,
+// so that each request is polled by one of the instances only. ErrMgoNotFound is returned if there is no request.
+func (m *MongoStorage) ClaimShareCountsRefresh() (models.Post, error) {
+	var post models.Post
+
+	session := m.db.Copy()
+	defer session.Close()
+
+	change := mgo.Change{Update: bson.M{"$unset": bson.M{"shareCounts.refreshRequestedAt": ""}}}
+	if _, err := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").
+		Find(bson.M{"shareCounts.refreshRequestedAt": bson.M{"$exists": true}}).
+		Sort("shareCounts.refreshRequestedAt").
+		Select(bson.M{"slug": 1, "shareCounts": 1}).
+		Apply(change, &post); err != nil {
+		return models.Post{}, errors.Wrap(err, "claim share counts refresh occurs error")
+	}
+	return post, nil
+}
+
+// UpdateShareCounts sets the polled share counts of the post by id,
+// the refresh requested after the post is claimed is kept
+func (m *MongoStorage) UpdateShareCounts(id bson.ObjectId, counts models.ShareCounts) error {
+	session := m.db.Copy()
+	defer session.Close()
+
+	if err := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").UpdateId(id, bson.M{"$set": bson.M{
+		"shareCounts.facebook":  counts.Facebook,
+		"shareCounts.twitter":   counts.Twitter,
+		"shareCounts.line":      counts.Line,
+		"shareCounts.updatedAt": counts.UpdatedAt,
+	}}); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("update share counts of post(id: %s) occurs error", id.Hex()))
+	}
+	return nil
 }
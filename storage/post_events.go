@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/internal/news"
+)
+
+// The types of PostEvent
+const (
+	PostEventInsert = "insert"
+	PostEventUpdate = "update"
+	PostEventDelete = "delete"
+	// PostEventUnpublish is emitted when the published post is moved to the other states
+	PostEventUnpublish = "unpublish"
+)
+
+// postEventStatePublished is the state of the posts whose changes are streamed
+const postEventStatePublished = "published"
+
+// PostEvent is the change of a post
+type PostEvent struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	// Slug is empty for the delete events, since the post is gone
+	Slug      string     `json:"slug,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+type postChange struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument struct {
+		Slug      string    `bson:"slug"`
+		State     string    `bson:"state"`
+		UpdatedAt time.Time `bson:"updatedAt"`
+	} `bson:"fullDocument"`
+}
+
+// WatchPosts opens a change stream on the posts and calls fn with each change of the published posts,
+// until ctx is done or fn returns an error. The change stream requires MongoDB running as a replica set.
+// The changes of the drafts are not streamed, the posts unpublished or deleted are emitted as tombstones,
+// which only carry the id and slug.
+func (m *mongoStorage) WatchPosts(ctx context.Context, fn func(PostEvent) error) error {
+	pipeline := []bson.D{
+		{{Key: "$match", Value: bson.D{{Key: "$or", Value: bson.A{
+			bson.D{
+				{Key: "operationType", Value: bson.D{{Key: "$in", Value: bson.A{"insert", "update", "replace"}}}},
+				{Key: "fullDocument.state", Value: postEventStatePublished},
+			},
+			// the state of the post is changed, which might be unpublished
+			bson.D{
+				{Key: "operationType", Value: "update"},
+				{Key: "updateDescription.updatedFields.state", Value: bson.D{{Key: "$exists", Value: true}}},
+			},
+			bson.D{{Key: "operationType", Value: "delete"}},
+		}}}}},
+	}
+
+	cs, err := m.Database(globals.Conf.DB.Mongo.DBname).Collection(news.ColPosts).Watch(ctx, pipeline, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		return errors.Wrap(err, "watch posts occurs error")
+	}
+	// ctx might be done already, close the change stream with a fresh one
+	defer cs.Close(context.Background())
+
+	for cs.Next(ctx) {
+		var change postChange
+		if err = cs.Decode(&change); err != nil {
+			return errors.Wrap(err, "decode change of posts occurs error")
+		}
+
+		event := PostEvent{
+			Type: change.OperationType,
+			ID:   change.DocumentKey.ID.Hex(),
+			Slug: change.FullDocument.Slug,
+		}
+		switch {
+		case event.Type == PostEventDelete:
+			// omit intentionally
+		case change.FullDocument.State != postEventStatePublished:
+			event.Type = PostEventUnpublish
+		default:
+			if event.Type == "replace" {
+				event.Type = PostEventUpdate
+			}
+			if updatedAt := change.FullDocument.UpdatedAt; !updatedAt.IsZero() {
+				event.UpdatedAt = &updatedAt
+			}
+		}
+
+		if err = fn(event); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return errors.Wrap(cs.Err(), "watch posts occurs error")
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	mgobson "gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/internal/news"
+	"twreporter.org/go-api/models"
+)
+
+// UpsertResult is the summary of upserting documents in bulk
+type UpsertResult struct {
+	Created int
+	Updated int
+	// Errors maps the index of the document failing to write to its error
+	Errors map[int]error
+}
+
+// buildPostUpsert builds the update document of the post.
+// models.Post is encoded by mgo bson, so that the object ids are stored as they are read by MongoStorage.
+func buildPostUpsert(post models.Post) (bson.Raw, error) {
+	var fields mgobson.M
+
+	id := post.ID
+	if !id.Valid() {
+		id = mgobson.NewObjectId()
+	}
+	post.ID = id
+
+	data, err := mgobson.Marshal(post)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("encode post(slug: %s) occurs error", post.Slug))
+	}
+
+	if err = mgobson.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("decode post(slug: %s) occurs error", post.Slug))
+	}
+	// _id is immutable, only set it when the post is created
+	delete(fields, "_id")
+
+	if data, err = mgobson.Marshal(mgobson.M{
+		"$set":         fields,
+		"$setOnInsert": mgobson.M{"_id": id},
+	}); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("encode update of post(slug: %s) occurs error", post.Slug))
+	}
+
+	return bson.Raw(data), nil
+}
+
+// UpsertPosts creates or updates the posts keyed by slug one by one.
+// Failures of some posts do not stop writing the others.
+// Each post is written along with reading its previous tags atomically, so that the post count of the tags
+// added to or removed from the post is updated by `$inc` without losing the concurrent upserts of the same post.
+// The previous versions of the updated posts are recorded along with the editor.
+func (m *mongoStorage) UpsertPosts(ctx context.Context, posts []models.Post, editorID uint) (UpsertResult, error) {
+	var result = UpsertResult{Errors: make(map[int]error)}
+	var deltas = make(map[string]int)
+	var versions []models.PostVersion
+
+	col := m.Database(globals.Conf.DB.Mongo.DBname).Collection(news.ColPosts)
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.Before).
+		SetProjection(bson.D{
+			{Key: "slug", Value: 1},
+			{Key: "title", Value: 1},
+			{Key: "content", Value: 1},
+			{Key: "state", Value: 1},
+			{Key: "updatedAt", Value: 1},
+			{Key: "tags", Value: 1},
+		})
+
+	for i, post := range posts {
+		update, err := buildPostUpsert(post)
+		if err != nil {
+			result.Errors[i] = err
+			continue
+		}
+
+		var currentTags []string
+		if post.State != postStateDeleted {
+			for _, id := range post.TagsOrigin {
+				currentTags = append(currentTags, id.Hex())
+			}
+		}
+
+		raw, err := col.FindOneAndUpdate(ctx, bson.D{{Key: "slug", Value: post.Slug}}, update, opts).DecodeBytes()
+		if err == mongo.ErrNoDocuments {
+			result.Created++
+			addTagDeltas(deltas, nil, currentTags)
+			continue
+		}
+		if err != nil {
+			// the failure of the post is reported, unless the database is unavailable for the others as well
+			if ce, ok := err.(mongo.CommandError); ok && !ce.HasErrorLabel("NetworkError") {
+				result.Errors[i] = errors.New(ce.Message)
+				continue
+			}
+			// the posts written already are still counted
+			m.countUpsertedPosts(ctx, deltas, versions)
+			return result, errors.Wrap(err, fmt.Sprintf("upsert post(slug: %s) occurs error", post.Slug))
+		}
+
+		result.Updated++
+		previous, err := decodePreviousPost(raw)
+		if err != nil {
+			// the post is written already, the tags and the version are skipped
+			log.Errorf("%+v", errors.WithMessage(err, fmt.Sprintf("post(slug: %s)", post.Slug)))
+			continue
+		}
+		addTagDeltas(deltas, previous.tags, currentTags)
+
+		previous.version.EditorID = editorID
+		versions = append(versions, previous.version)
+	}
+
+	m.countUpsertedPosts(ctx, deltas, versions)
+	return result, nil
+}
+
+// countUpsertedPosts updates the post count of the tags and records the previous versions of the upserted posts,
+// the posts are written already, the failures are only logged
+func (m *mongoStorage) countUpsertedPosts(ctx context.Context, deltas map[string]int, versions []models.PostVersion) {
+	if err := m.incrementPostCountOfTags(ctx, deltas); err != nil {
+		log.Errorf("%+v", err)
+	}
+
+	if err := m.insertPostVersions(ctx, versions); err != nil {
+		log.Errorf("%+v", err)
+	}
+}
+
+// previousPost is the existing post before it is upserted
+type previousPost struct {
+	// tags are the tags in hex counted by the post, which are none if the post is deleted
+	tags    []string
+	version models.PostVersion
+}
+
+// decodePreviousPost decodes the post by mgo bson as it is encoded by buildPostUpsert
+func decodePreviousPost(raw bson.Raw) (previousPost, error) {
+	var post struct {
+		Slug      string              `bson:"slug"`
+		Title     string              `bson:"title"`
+		Content   *models.ContentBody `bson:"content,omitempty"`
+		State     string              `bson:"state"`
+		UpdatedAt time.Time           `bson:"updatedAt"`
+		Tags      []mgobson.ObjectId  `bson:"tags"`
+	}
+	if err := mgobson.Unmarshal(raw, &post); err != nil {
+		return previousPost{}, errors.Wrap(err, "decode previous post occurs error")
+	}
+
+	p := previousPost{version: models.PostVersion{
+		PostSlug:  post.Slug,
+		Title:     post.Title,
+		Content:   post.Content,
+		UpdatedAt: post.UpdatedAt,
+	}}
+	if post.State != postStateDeleted {
+		for _, id := range post.Tags {
+			p.tags = append(p.tags, id.Hex())
+		}
+	}
+	return p, nil
+}
+
+// addTagDeltas adds the changes of post count of the tags into deltas,
+// when the tags of a post change from previous to current.
+func addTagDeltas(deltas map[string]int, previous, current []string) {
+	var changes = make(map[string]int)
+
+	for _, id := range previous {
+		changes[id] = -1
+	}
+	for _, id := range current {
+		switch delta, ok := changes[id]; {
+		case !ok:
+			changes[id] = 1
+		case delta == -1:
+			// the tag is kept
+			changes[id] = 0
+		}
+	}
+
+	for id, delta := range changes {
+		if delta != 0 {
+			deltas[id] += delta
+		}
+	}
+}
+
+// incrementPostCountOfTags increments the post count of the tags by deltas in bulk.
+// Each `$inc` is atomic, so that the concurrent upserts do not lose the counts.
+func (m *mongoStorage) incrementPostCountOfTags(ctx context.Context, deltas map[string]int) error {
+	var writes []mongo.WriteModel
+
+	for hex, delta := range deltas {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil || delta == 0 {
+			continue
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{Key: "_id", Value: id}}).
+			SetUpdate(bson.D{{Key: "$inc", Value: bson.D{{Key: "postCount", Value: delta}}}}))
+	}
+
+	if len(writes) == 0 {
+		return nil
+	}
+
+	if _, err := m.Database(globals.Conf.DB.Mongo.DBname).Collection(news.ColTags).BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("increment post count of tags(deltas: %v) occurs error", deltas))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mgobson "gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+func TestBuildPostUpsert(t *testing.T) {
+	topicID := mgobson.NewObjectId()
+	post := models.Post{Slug: "mock-slug", Title: "mock title", TopicOrigin: topicID}
+
+	update, err := buildPostUpsert(post)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	set, ok := update.Lookup("$set").DocumentOK()
+	if !ok {
+		t.Fatalf("expected $set document, got %v", update)
+	}
+
+	if _, err = set.LookupErr("_id"); err == nil {
+		t.Errorf("expected _id not to be set on update")
+	}
+
+	if slug := set.Lookup("slug").StringValue(); slug != post.Slug {
+		t.Errorf("expected slug %s, got %s", post.Slug, slug)
+	}
+
+	if topic, ok := set.Lookup("topics").ObjectIDOK(); !ok || topic.Hex() != topicID.Hex() {
+		t.Errorf("expected topics to be object id %s, got %v", topicID.Hex(), set.Lookup("topics"))
+	}
+
+	id, ok := update.Lookup("$setOnInsert", "_id").ObjectIDOK()
+	if !ok || id == primitive.NilObjectID {
+		t.Errorf("expected _id to be generated on insert, got %v", update.Lookup("$setOnInsert"))
+	}
+}
+
+func TestAddTagDeltas(t *testing.T) {
+	deltas := make(map[string]int)
+
+	// a new post with tags a and b
+	addTagDeltas(deltas, nil, []string{"a", "b"})
+	// an existing post changing its tags from a and c to a and b
+	addTagDeltas(deltas, []string{"a", "c"}, []string{"a", "b"})
+	// an existing post referencing the same tag twice
+	addTagDeltas(deltas, []string{"d"}, []string{"d", "d"})
+
+	want := map[string]int{"a": 1, "b": 2, "c": -1}
+	if len(deltas) != len(want) {
+		t.Fatalf("expected deltas %v, got %v", want, deltas)
+	}
+	for id, delta := range want {
+		if deltas[id] != delta {
+			t.Errorf("expected delta of tag %s to be %d, got %d", id, delta, deltas[id])
+		}
+	}
+}
+
+func TestDecodePreviousPost(t *testing.T) {
+	tagID := mgobson.NewObjectId()
+
+	cases := []struct {
+		name     string
+		state    string
+		wantTags []string
+	}{
+		{name: "Given a published post", state: "published", wantTags: []string{tagID.Hex()}},
+		{name: "Given a deleted post, it counts no tags", state: postStateDeleted},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, _ := mgobson.Marshal(mgobson.M{"slug": "mock-slug", "title": "mock title", "state": tc.state, "tags": []mgobson.ObjectId{tagID}})
+
+			previous, err := decodePreviousPost(raw)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if previous.version.PostSlug != "mock-slug" || previous.version.Title != "mock title" {
+				t.Errorf("unexpected version %+v", previous.version)
+			}
+			if len(previous.tags) != len(tc.wantTags) || (len(tc.wantTags) > 0 && previous.tags[0] != tc.wantTags[0]) {
+				t.Errorf("expected tags %v, got %v", tc.wantTags, previous.tags)
+			}
+		})
+	}
+}
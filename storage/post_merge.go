@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	mgobson "gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/internal/news"
+	"twreporter.org/go-api/models"
+)
+
+const postStateDeleted = "deleted"
+
+// mergedPost is the part of the post read while merging,
+// which is decoded by mgo bson as the content of the post versions
+type mergedPost struct {
+	Slug        string              `bson:"slug"`
+	Title       string              `bson:"title"`
+	Content     *models.ContentBody `bson:"content,omitempty"`
+	State       string              `bson:"state"`
+	LegacySlugs []string            `bson:"legacySlugs"`
+	RedirectTo  string              `bson:"redirectTo"`
+	UpdatedAt   time.Time           `bson:"updatedAt"`
+	Tags        []mgobson.ObjectId  `bson:"tags"`
+}
+
+// mergeOutcome is the result of the merge transaction
+type mergeOutcome struct {
+	legacySlugs []string
+	// previous is the target before the merge, which is nil if the merge is a no-op
+	previous *models.PostVersion
+}
+
+// MergePosts merges the source post into the target post in a transaction,
+// the source slug along with its legacy slugs are added to the legacy slugs of the target,
+// and the source is soft-deleted with the redirect to the target, whose tags no longer count it.
+// The posts redirected to the source are redirected to the target as well, so that the redirects are never chained.
+// Merging the source into the same target again is a no-op, so that the merge could be retried.
+// The previous version of the target is recorded along with the editor merging the posts.
+// It returns the legacy slugs of the target after the merge.
+func (m *mongoStorage) MergePosts(ctx context.Context, source, target string, editorID uint) ([]string, error) {
+	session, err := m.StartSession()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot start the post merge session")
+	}
+	defer session.EndSession(ctx)
+
+	outcome, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return mergePosts(sc, m.Database(globals.Conf.DB.Mongo.DBname).Collection(news.ColPosts), source, target)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merged := outcome.(mergeOutcome)
+	if merged.previous != nil {
+		merged.previous.EditorID = editorID
+		// the posts are merged already, the failure of recording the version is only logged
+		if err = m.insertPostVersions(ctx, []models.PostVersion{*merged.previous}); err != nil {
+			log.Errorf("%+v", err)
+		}
+	}
+	return merged.legacySlugs, nil
+}
+
+// findMergedPost decodes the post matching the filter by mgo bson, as the content is encoded
+func findMergedPost(ctx mongo.SessionContext, col *mongo.Collection, filter bson.D, slug string) (mergedPost, error) {
+	var post mergedPost
+
+	raw, err := col.FindOne(ctx, filter).DecodeBytes()
+	if err != nil {
+		return post, errors.Wrap(err, fmt.Sprintf("get post(slug: %s) occurs error", slug))
+	}
+	if err = mgobson.Unmarshal(raw, &post); err != nil {
+		return post, errors.Wrap(err, fmt.Sprintf("decode post(slug: %s) occurs error", slug))
+	}
+	return post, nil
+}
+
+func mergePosts(ctx mongo.SessionContext, col *mongo.Collection, source, target string) (mergeOutcome, error) {
+	sourcePost, err := findMergedPost(ctx, col, bson.D{{Key: "slug", Value: source}}, source)
+	if err != nil {
+		return mergeOutcome{}, err
+	}
+	targetPost, err := findMergedPost(ctx, col, bson.D{
+		{Key: "slug", Value: target},
+		{Key: "state", Value: bson.D{{Key: "$ne", Value: postStateDeleted}}},
+	}, target)
+	if err != nil {
+		return mergeOutcome{}, err
+	}
+
+	if sourcePost.State == postStateDeleted {
+		if sourcePost.RedirectTo == target {
+			return mergeOutcome{legacySlugs: targetPost.LegacySlugs}, nil
+		}
+		return mergeOutcome{}, errors.Wrap(ErrUpdateConflict, fmt.Sprintf("post(slug: %s) is deleted already", source))
+	}
+
+	now := time.Now()
+	legacySlugs := append([]string{source}, sourcePost.LegacySlugs...)
+
+	if _, err := col.UpdateOne(ctx, bson.D{{Key: "slug", Value: target}}, bson.D{
+		{Key: "$addToSet", Value: bson.D{{Key: "legacySlugs", Value: bson.D{{Key: "$each", Value: legacySlugs}}}}},
+		{Key: "$set", Value: bson.D{{Key: "updatedAt", Value: now}}},
+	}); err != nil {
+		return mergeOutcome{}, errors.Wrap(err, fmt.Sprintf("add legacy slugs to post(slug: %s) occurs error", target))
+	}
+
+	if _, err := col.UpdateOne(ctx, bson.D{{Key: "slug", Value: source}}, bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "state", Value: postStateDeleted},
+			{Key: "redirectTo", Value: target},
+			{Key: "updatedAt", Value: now},
+		}},
+		{Key: "$unset", Value: bson.D{{Key: "legacySlugs", Value: ""}}},
+	}); err != nil {
+		return mergeOutcome{}, errors.Wrap(err, fmt.Sprintf("soft delete post(slug: %s) occurs error", source))
+	}
+
+	if len(sourcePost.Tags) > 0 {
+		// the ids decoded by mgo bson are encoded by the driver as strings, convert them back
+		tags := make(bson.A, 0, len(sourcePost.Tags))
+		for _, id := range sourcePost.Tags {
+			if oid, err := primitive.ObjectIDFromHex(id.Hex()); err == nil {
+				tags = append(tags, oid)
+			}
+		}
+		if _, err := col.Database().Collection(news.ColTags).UpdateMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: tags}}}}, bson.D{
+			{Key: "$inc", Value: bson.D{{Key: "postCount", Value: -1}}},
+		}); err != nil {
+			return mergeOutcome{}, errors.Wrap(err, fmt.Sprintf("decrement post count of tags of post(slug: %s) occurs error", source))
+		}
+	}
+
+	if _, err := col.UpdateMany(ctx, bson.D{{Key: "redirectTo", Value: source}}, bson.D{
+		{Key: "$set", Value: bson.D{{Key: "redirectTo", Value: target}}},
+	}); err != nil {
+		return mergeOutcome{}, errors.Wrap(err, fmt.Sprintf("redirect posts merged into post(slug: %s) occurs error", source))
+	}
+
+	return mergeOutcome{
+		legacySlugs: mergeLegacySlugs(targetPost.LegacySlugs, legacySlugs),
+		previous: &models.PostVersion{
+			PostSlug:  targetPost.Slug,
+			Title:     targetPost.Title,
+			Content:   targetPost.Content,
+			UpdatedAt: targetPost.UpdatedAt,
+		},
+	}, nil
+}
+
+// mergeLegacySlugs appends the slugs not in the legacy slugs yet, as $addToSet does
+func mergeLegacySlugs(legacySlugs []string, slugs []string) []string {
+	merged := append([]string{}, legacySlugs...)
+	existing := make(map[string]bool, len(legacySlugs))
+	for _, s := range legacySlugs {
+		existing[s] = true
+	}
+	for _, s := range slugs {
+		if !existing[s] {
+			existing[s] = true
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeLegacySlugs(t *testing.T) {
+	cases := []struct {
+		name        string
+		legacySlugs []string
+		slugs       []string
+		want        []string
+	}{
+		{name: "Given no legacy slugs", slugs: []string{"source"}, want: []string{"source"}},
+		{name: "Given the legacy slugs of the source", legacySlugs: []string{"older"}, slugs: []string{"source", "oldest"}, want: []string{"older", "source", "oldest"}},
+		{name: "Given a slug merged already", legacySlugs: []string{"source"}, slugs: []string{"source"}, want: []string{"source"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mergeLegacySlugs(tc.legacySlugs, tc.slugs); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expect legacy slugs %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+func TestOrderPostsByIDs(t *testing.T) {
+	id1, id2, id3 := bson.NewObjectId(), bson.NewObjectId(), bson.NewObjectId()
+	posts := []models.Post{{ID: id1, Slug: "post-1"}, {ID: id2, Slug: "post-2"}, {ID: id3, Slug: "post-3"}}
+
+	cases := []struct {
+		name string
+		ids  []bson.ObjectId
+		want []string
+	}{
+		{
+			name: "Given the ids in another order",
+			ids:  []bson.ObjectId{id3, id1, id2},
+			want: []string{"post-3", "post-1", "post-2"},
+		},
+		{
+			name: "Given an id not found",
+			ids:  []bson.ObjectId{id2, bson.NewObjectId(), id1},
+			want: []string{"post-2", "post-1"},
+		},
+		{
+			name: "Given a repeated id",
+			ids:  []bson.ObjectId{id1, id1},
+			want: []string{"post-1", "post-1"},
+		},
+		{
+			name: "Given no ids",
+			ids:  nil,
+			want: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var slugs = make([]string, 0)
+			for _, post := range orderPostsByIDs(tc.ids, posts) {
+				slugs = append(slugs, post.Slug)
+			}
+
+			if !reflect.DeepEqual(slugs, tc.want) {
+				t.Errorf("expect posts %v, but got %v", tc.want, slugs)
+			}
+		})
+	}
+}
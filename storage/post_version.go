@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	mgobson "gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/internal/news"
+	"twreporter.org/go-api/models"
+)
+
+// insertPostVersions records the snapshots of the posts into postVersions collection
+func (m *mongoStorage) insertPostVersions(ctx context.Context, versions []models.PostVersion) error {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	var docs = make([]interface{}, 0, len(versions))
+	now := time.Now()
+	for _, version := range versions {
+		version.ID = mgobson.NewObjectId()
+		version.CreatedAt = now
+
+		// models.PostVersion is encoded by mgo bson, so that the content is stored as it is in posts collection
+		data, err := mgobson.Marshal(version)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("encode version of post(slug: %s) occurs error", version.PostSlug))
+		}
+		docs = append(docs, bson.Raw(data))
+	}
+
+	if _, err := m.Database(globals.Conf.DB.Mongo.DBname).Collection(news.ColPostVersions).InsertMany(ctx, docs, options.InsertMany().SetOrdered(false)); err != nil {
+		return errors.Wrap(err, "insert post versions occurs error")
+	}
+	return nil
+}
+
+// GetPostVersions returns the meta of the versions of the post, the latest one comes first.
+// The content is excluded.
+func (m *mongoStorage) GetPostVersions(ctx context.Context, slug string) ([]models.PostVersion, error) {
+	var versions = make([]models.PostVersion, 0)
+
+	cursor, err := m.Database(globals.Conf.DB.Mongo.DBname).Collection(news.ColPostVersions).Find(ctx,
+		bson.D{{Key: "postSlug", Value: slug}},
+		options.Find().
+			SetProjection(bson.D{{Key: "content", Value: 0}}).
+			SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}))
+	if err != nil {
+		return versions, errors.Wrap(err, fmt.Sprintf("get versions of post(slug: %s) occurs error", slug))
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var version models.PostVersion
+		if err = mgobson.Unmarshal(cursor.Current, &version); err != nil {
+			return versions, errors.Wrap(err, fmt.Sprintf("decode versions of post(slug: %s) occurs error", slug))
+		}
+		versions = append(versions, version)
+	}
+
+	if err = cursor.Err(); err != nil {
+		return versions, errors.Wrap(err, fmt.Sprintf("get versions of post(slug: %s) occurs error", slug))
+	}
+	return versions, nil
+}
+
+// GetPostVersion returns the version of the post by its id
+func (m *mongoStorage) GetPostVersion(ctx context.Context, slug string, id string) (models.PostVersion, error) {
+	var version models.PostVersion
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return version, errors.Wrap(ErrMgoNotFound, fmt.Sprintf("version(id: %s) is not a valid object id", id))
+	}
+
+	raw, err := m.Database(globals.Conf.DB.Mongo.DBname).Collection(news.ColPostVersions).FindOne(ctx,
+		bson.D{{Key: "_id", Value: objectID}, {Key: "postSlug", Value: slug}}).DecodeBytes()
+	if err != nil {
+		return version, errors.Wrap(err, fmt.Sprintf("get version(id: %s) of post(slug: %s) occurs error", id, slug))
+	}
+
+	if err = mgobson.Unmarshal(raw, &version); err != nil {
+		return version, errors.Wrap(err, fmt.Sprintf("decode version(id: %s) of post(slug: %s) occurs error", id, slug))
+	}
+	return version, nil
+}
+
+// GetCurrentPostVersion returns the snapshot of the post as it is now
+func (m *mongoStorage) GetCurrentPostVersion(ctx context.Context, slug string) (models.PostVersion, error) {
+	var post struct {
+		Slug      string              `bson:"slug"`
+		Title     string              `bson:"title"`
+		Content   *models.ContentBody `bson:"content,omitempty"`
+		UpdatedAt time.Time           `bson:"updatedAt"`
+	}
+
+	raw, err := m.Database(globals.Conf.DB.Mongo.DBname).Collection(news.ColPosts).FindOne(ctx,
+		bson.D{{Key: "slug", Value: slug}},
+		options.FindOne().SetProjection(bson.D{
+			{Key: "slug", Value: 1},
+			{Key: "title", Value: 1},
+			{Key: "content", Value: 1},
+			{Key: "updatedAt", Value: 1},
+		})).DecodeBytes()
+	if err != nil {
+		return models.PostVersion{}, errors.Wrap(err, fmt.Sprintf("get post(slug: %s) occurs error", slug))
+	}
+
+	if err = mgobson.Unmarshal(raw, &post); err != nil {
+		return models.PostVersion{}, errors.Wrap(err, fmt.Sprintf("decode post(slug: %s) occurs error", slug))
+	}
+
+	return models.PostVersion{
+		PostSlug:  post.Slug,
+		Title:     post.Title,
+		Content:   post.Content,
+		UpdatedAt: post.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/models"
+)
+
+// ReadingHistoryStorage defines the methods to manage the posts read by the users
+type ReadingHistoryStorage interface {
+	RecordReadingHistory(uint, string, time.Time) error
+	DeleteReadingHistory(string, string) error
+	ClearReadingHistory(string) error
+}
+
+// RecordReadingHistory records the post of slug read by the user at readAt.
+// Only the latest read time is kept, the row of the post read before is updated in place.
+func (g *GormStorage) RecordReadingHistory(userID uint, slug string, readAt time.Time) error {
+	now := time.Now()
+
+	// the unique key of (user_id, slug) makes the upsert atomic, so that the concurrent reads never duplicate the row
+	if err := g.db.Exec("INSERT INTO `reading_histories` (`created_at`, `updated_at`, `user_id`, `slug`, `read_at`) VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `updated_at` = VALUES(`updated_at`), `read_at` = VALUES(`read_at`)",
+		now, now, userID, slug, readAt).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("record reading history(slug: %s) of user(id: %d) error", slug, userID))
+	}
+	return nil
+}
+
+// DeleteReadingHistory removes the post of slug from the reading history of the user
+func (g *GormStorage) DeleteReadingHistory(userID string, slug string) error {
+	var history models.ReadingHistory
+
+	if err := g.db.First(&history, "user_id = ? AND slug = ?", userID, slug).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("get reading history(slug: %s) of user(id: %s) error", slug, userID))
+	}
+
+	if err := g.db.Delete(&history).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("delete reading history(slug: %s) of user(id: %s) error", slug, userID))
+	}
+	return nil
+}
+
+// ClearReadingHistory removes all the posts from the reading history of the user
+func (g *GormStorage) ClearReadingHistory(userID string) error {
+	// DELETE FROM reading_histories WHERE user_id = $userID
+	if err := g.db.Where("user_id = ?", userID).Delete(&models.ReadingHistory{}).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("clear reading history of user(id: %s) error", userID))
+	}
+	return nil
+}
+
+// GetReadersSince returns the ids of the users reading any post after `since`
+func (g *GormStorage) GetReadersSince(since time.Time) ([]uint, error) {
+	var userIDs []uint
+
+	// SELECT DISTINCT user_id FROM reading_histories WHERE read_at >= $since
+	if err := g.db.Model(&models.ReadingHistory{}).Where("read_at >= ?", since).Pluck("DISTINCT user_id", &userIDs).Error; err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("get readers since %v error", since))
+	}
+	return userIDs, nil
+}
+
+// GetRecentlyReadSlugs returns the slugs of the posts read by the user, the latest read come first
+func (g *GormStorage) GetRecentlyReadSlugs(userID uint, limit int) ([]string, error) {
+	var slugs []string
+
+	if err := g.db.Model(&models.ReadingHistory{}).Where("user_id = ?", userID).Order("read_at desc").Limit(limit).Pluck("slug", &slugs).Error; err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("get reading history of user(id: %d) error", userID))
+	}
+	return slugs, nil
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+)
+
+const (
+	// recommendedForPrefix is the prefix of the keys caching the recommended posts of the users, i.e. `recommendedFor:<userID>`
+	recommendedForPrefix = "recommendedFor:"
+	// recommendationRefreshLockKey is held by the instance refreshing the recommended posts
+	recommendationRefreshLockKey = "recommendation:refresh:lock"
+)
+
+// GetTagsOfPostsBySlugs returns the posts of the slugs with only their ids, slugs and tags
+func (m *MongoStorage) GetTagsOfPostsBySlugs(slugs []string) ([]models.Post, error) {
+	var posts []models.Post
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		var found []models.Post
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		if err := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").
+			Find(bson.M{"slug": bson.M{"$in": slugs}}).
+			Select(bson.M{"slug": 1, "tags": 1}).
+			All(&found); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get tags of posts(slugs: %v) occurs error", slugs))
+		}
+		posts = found
+		return nil
+	})
+	return posts, err
+}
+
+// GetRecommendationCandidates returns the published posts tagged with any of the tags except the excluded slugs,
+// the latest published come first. Only the ids, slugs, tags and published dates are read.
+func (m *MongoStorage) GetRecommendationCandidates(tags []bson.ObjectId, excluded []string, limit int) ([]models.Post, error) {
+	var posts []models.Post
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		var found []models.Post
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		if err := session.DB(globals.Conf.DB.Mongo.DBname).C("posts").
+			Find(bson.M{"state": "published", "tags": bson.M{"$in": tags}, "slug": bson.M{"$nin": excluded}}).
+			Select(bson.M{"slug": 1, "tags": 1, "publishedDate": 1}).
+			Sort("-publishedDate").
+			Limit(limit).
+			All(&found); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get posts tagged with any of tags(%v) occurs error", tags))
+		}
+		posts = found
+		return nil
+	})
+	return posts, err
+}
+
+// RecommendationCache stores the recommended posts of the users in Redis,
+// which are computed periodically by the recommendation job
+type RecommendationCache struct {
+	client *redis.Client
+}
+
+// NewRecommendationCache initializes the cache of the recommended posts
+func NewRecommendationCache(client *redis.Client) *RecommendationCache {
+	return &RecommendationCache{client}
+}
+
+// GetRecommendedPostIDs returns the hex ids of the posts recommended for the user in order,
+// it is empty if they are never computed or expired, e.g. for the new users.
+func (rc *RecommendationCache) GetRecommendedPostIDs(userID string) ([]string, error) {
+	var ids []string
+
+	data, err := rc.client.Get(recommendedForPrefix + userID).Bytes()
+	if err == redis.Nil {
+		return ids, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("get recommended posts of user(id: %s) occurs error", userID))
+	}
+
+	if err = json.Unmarshal(data, &ids); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("decode recommended posts of user(id: %s) occurs error", userID))
+	}
+	return ids, nil
+}
+
+// SetRecommendedPostIDs replaces the posts recommended for the user, which expire after ttl
+func (rc *RecommendationCache) SetRecommendedPostIDs(userID uint, ids []string, ttl time.Duration) error {
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("encode recommended posts of user(id: %d) occurs error", userID))
+	}
+
+	if err = rc.client.Set(fmt.Sprintf("%s%d", recommendedForPrefix, userID), data, ttl).Err(); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("set recommended posts of user(id: %d) occurs error", userID))
+	}
+	return nil
+}
+
+// AcquireRefreshLock returns true if the caller acquires the lock of refreshing the recommended posts,
+// which is held until ttl expires. It is false if another instance holds the lock.
+func (rc *RecommendationCache) AcquireRefreshLock(ttl time.Duration) (bool, error) {
+	acquired, err := rc.client.SetNX(recommendationRefreshLockKey, time.Now().Unix(), ttl).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "acquire the lock of refreshing recommended posts occurs error")
+	}
+	return acquired, nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+)
+
+// GetPostsBySeries gets all the posts of the series with PARTIAL corresponding assets, ordered by partNumber
+func (m *MongoStorage) GetPostsBySeries(ctx context.Context, seriesSlug string) ([]models.Post, error) {
+	posts, _, err := m.GetMetaOfPosts(ctx, models.MongoQuery{SeriesSlug: seriesSlug}, 0, 0, "partNumber,publishedDate", nil)
+	return posts, err
+}
+
+// GetSeries lists the series having the published posts along with their post count,
+// the series updated recently come first. The aggregation is bounded by the query timeout and ctx.
+func (m *MongoStorage) GetSeries(ctx context.Context) ([]models.Series, error) {
+	var series []models.Series
+
+	match := bson.M{"seriesSlug": bson.M{"$exists": true, "$ne": ""}}
+	if globals.Conf.Environment != "development" {
+		match["state"] = "published"
+	}
+
+	timeout := getQueryTimeout()
+	err := withQueryTimeoutContext(ctx, timeout, func(ctx context.Context) error {
+		var found []models.Series
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		// the aggregation is terminated by MongoDB after timeout as well
+		iter := pipeWithMaxTime(session.DB(globals.Conf.DB.Mongo.DBname).C("posts"), []bson.M{
+			{"$match": match},
+			{"$group": bson.M{
+				"_id":                 "$seriesSlug",
+				"postCount":           bson.M{"$sum": 1},
+				"totalParts":          bson.M{"$max": "$totalParts"},
+				"latestPublishedDate": bson.M{"$max": "$publishedDate"},
+			}},
+			{"$sort": bson.D{{Name: "latestPublishedDate", Value: -1}, {Name: "_id", Value: 1}}},
+		}, timeout)
+
+		if err := iter.All(&found); err != nil {
+			return errors.Wrap(err, "get series of posts occurs error")
+		}
+		series = found
+		return nil
+	})
+	return series, err
+}
@@ -30,3 +30,14 @@ func (g *GormStorage) GetAWebPushSubscription(crc32Endpoint uint32, endpoint str
 
 	return wpSub, nil
 }
+
+// GetWebPushSubscriptionsOfAUser - read the web push subscriptions bound with the user in the order they are created
+func (g *GormStorage) GetWebPushSubscriptionsOfAUser(userID string) ([]models.WebPushSubscription, error) {
+	var wpSubs []models.WebPushSubscription
+
+	if err := g.db.Where("user_id = ?", userID).Order("created_at, id").Find(&wpSubs).Error; err != nil {
+		return wpSubs, errors.Wrap(err, fmt.Sprintf("getting web push subscriptions of the user(id: %s) occurs error", userID))
+	}
+
+	return wpSubs, nil
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+)
+
+// getHeroImage gets the hero image by id, nil is returned if the image is not found
+func (m *MongoStorage) getHeroImage(id bson.ObjectId) *models.Image {
+	var imgs []models.MongoImage
+
+	if !id.Valid() {
+		return nil
+	}
+
+	if err := m._GetAssetsByIDs([]bson.ObjectId{id}, "images", &imgs); err != nil || len(imgs) == 0 {
+		return nil
+	}
+
+	img := imgs[0].ToImage()
+	return &img
+}
+
+// GetTagBySlug finds the tag by slug with its hero image
+func (m *MongoStorage) GetTagBySlug(slug string) (models.Tag, error) {
+	var tags []models.Tag
+
+	if _, err := m.GetDocuments(context.Background(), models.MongoQuery{Slug: slug}, 1, 0, "_id", "tags", &tags); err != nil {
+		return models.Tag{}, err
+	}
+
+	if len(tags) == 0 {
+		return models.Tag{}, errors.Wrap(ErrMgoNotFound, fmt.Sprintf("get tag(slug: %s) occurs error", slug))
+	}
+
+	tags[0].HeroImage = m.getHeroImage(tags[0].HeroImageOrigin)
+	return tags[0], nil
+}
+
+// GetTagsBySlugs finds the tags by slugs, and the slugs not found are omitted
+func (m *MongoStorage) GetTagsBySlugs(slugs []string) ([]models.Tag, error) {
+	var tags []models.Tag
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		var found []models.Tag
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		if err := session.DB(globals.Conf.DB.Mongo.DBname).C("tags").Find(bson.M{"slug": bson.M{"$in": slugs}}).All(&found); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get tags(slugs: %v) occurs error", slugs))
+		}
+		tags = found
+		return nil
+	})
+	return tags, err
+}
+
+// GetTagCountsOfTopics lists the distinct tags of the topics along with the number of the topics referencing each,
+// sorted by the number descendingly, and then by slug.
+// The tags referenced by the topics but not existing are omitted.
+func (m *MongoStorage) GetTagCountsOfTopics() ([]models.TagCount, error) {
+	var counts []models.TagCount
+
+	match := bson.M{"tags.0": bson.M{"$exists": true}}
+	if globals.Conf.Environment != "development" {
+		match["state"] = "published"
+	}
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		var found []models.TagCount
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		pipe := session.DB(globals.Conf.DB.Mongo.DBname).C("topics").Pipe([]bson.M{
+			{"$match": match},
+			{"$unwind": "$tags"},
+			{"$group": bson.M{"_id": "$tags", "topicCount": bson.M{"$sum": 1}}},
+			{"$lookup": bson.M{"from": "tags", "localField": "_id", "foreignField": "_id", "as": "tag"}},
+			{"$unwind": "$tag"},
+			{"$project": bson.M{
+				"slug":        "$tag.slug",
+				"name":        "$tag.name",
+				"description": "$tag.description",
+				"postCount":   "$tag.postCount",
+				"topicCount":  1,
+			}},
+			{"$sort": bson.D{{Name: "topicCount", Value: -1}, {Name: "slug", Value: 1}}},
+		})
+
+		if err := pipe.All(&found); err != nil {
+			return errors.Wrap(err, "get tag counts of topics occurs error")
+		}
+		counts = found
+		return nil
+	})
+	return counts, err
+}
+
+// GetCategoryBySlug finds the category by slug with its hero image
+func (m *MongoStorage) GetCategoryBySlug(slug string) (models.Category, error) {
+	var categories []models.Category
+
+	if _, err := m.GetDocuments(context.Background(), models.MongoQuery{Slug: slug}, 1, 0, "_id", "postcategories", &categories); err != nil {
+		return models.Category{}, err
+	}
+
+	if len(categories) == 0 {
+		return models.Category{}, errors.Wrap(ErrMgoNotFound, fmt.Sprintf("get category(slug: %s) occurs error", slug))
+	}
+
+	categories[0].HeroImage = m.getHeroImage(categories[0].HeroImageOrigin)
+	return categories[0], nil
+}
+
+// UpsertTag creates or updates the tag keyed by slug.
+// PostCount is not overwritten, since it is maintained while upserting posts.
+func (m *MongoStorage) UpsertTag(tag models.Tag) (models.Tag, error) {
+	session := m.db.Copy()
+	defer session.Close()
+
+	fields := bson.M{
+		"name":        tag.Name,
+		"description": tag.Description,
+	}
+	if tag.HeroImageOrigin.Valid() {
+		fields["heroImage"] = tag.HeroImageOrigin
+	}
+
+	id := tag.ID
+	if !id.Valid() {
+		id = bson.NewObjectId()
+	}
+
+	col := session.DB(globals.Conf.DB.Mongo.DBname).C("tags")
+	if _, err := col.Upsert(bson.M{"slug": tag.Slug}, bson.M{
+		"$set":         fields,
+		"$setOnInsert": bson.M{"_id": id, "postCount": 0},
+	}); err != nil {
+		return models.Tag{}, errors.Wrap(err, fmt.Sprintf("upsert tag(slug: %s) occurs error", tag.Slug))
+	}
+
+	var upserted models.Tag
+	if err := col.Find(bson.M{"slug": tag.Slug}).One(&upserted); err != nil {
+		return models.Tag{}, errors.Wrap(err, fmt.Sprintf("get tag(slug: %s) occurs error", tag.Slug))
+	}
+	return upserted, nil
+}
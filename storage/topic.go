@@ -1,25 +1,47 @@
 package storage
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+	"gopkg.in/mgo.v2/bson"
+
 	"twreporter.org/go-api/globals"
 	"twreporter.org/go-api/models"
 )
 
 // _GetTopics finds the topics according to query string and also get the embedded assets
-func (m *MongoStorage) _GetTopics(mq models.MongoQuery, limit int, offset int, sort string, embedded []string, isFull bool) ([]models.Topic, int, error) {
+func (m *MongoStorage) _GetTopics(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string, embedded []string, isFull bool) ([]models.Topic, int, error) {
 	var topics []models.Topic
 
 	if globals.Conf.Environment != "development" {
 		mq.State = "published"
 	}
 
-	total, err := m.GetDocuments(mq, limit, offset, sort, "topics", &topics)
+	total, err := m.getTopicsWithPostCount(ctx, mq, limit, offset, sort, &topics)
 
 	if err != nil {
 		return topics, 0, err
 	}
 
+	// the topics refer to no authors, the authors of the related posts are expanded instead
+	rest := withoutEmbedded(embedded, "topic_relateds")
+	expandRelateds := mq.IsExpanded(models.ExpandAuthors) && len(rest) < len(embedded)
+	if expandRelateds {
+		embedded = rest
+	}
+
 	for index := range topics {
+		// stop populating the assets if the request is canceled
+		if err = ctx.Err(); err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
+		if expandRelateds {
+			m.getRelatedsWithAuthors(ctx, &topics[index])
+		}
 		m.GetEmbeddedAsset(&topics[index], embedded)
 		if isFull {
 			topics[index].Full = isFull
@@ -29,22 +51,314 @@ func (m *MongoStorage) _GetTopics(mq models.MongoQuery, limit int, offset int, s
 	return topics, total, nil
 }
 
+// getRelatedsWithAuthors embeds the related posts of the topic as "topic_relateds" of GetEmbeddedAsset does,
+// along with the authors of the posts expanded.
+func (m *MongoStorage) getRelatedsWithAuthors(ctx context.Context, topic *models.Topic) {
+	if len(topic.RelatedsOrigin) == 0 {
+		return
+	}
+
+	query := models.MongoQuery{
+		IDs:          models.MongoQueryComparison{In: topic.RelatedsOrigin},
+		ExpandFields: []string{models.ExpandAuthors},
+	}
+	relateds, _, err := m.GetMetaOfPosts(ctx, query, 0, 0, "-publishedDate", []string{"hero_image", "categories", "tags", "og_image"})
+	if err == nil {
+		topic.Relateds = orderPostsByIDs(topic.RelatedsOrigin, relateds)
+	}
+}
+
+// getTopicsWithPostCount is the same as GetDocuments of the topics,
+// except that `postCount` of each topic is computed by $lookup in the same aggregation,
+// so that the clients do not query the posts of each topic.
+// The posts are joined by the equality of `topics`, which is served by its index, and filtered by the state afterwards.
+func (m *MongoStorage) getTopicsWithPostCount(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string, topics *[]models.Topic) (int, error) {
+	var postCount interface{} = bson.M{"$size": "$postCount"}
+	if globals.Conf.Environment != "development" {
+		postCount = bson.M{"$size": bson.M{"$filter": bson.M{
+			"input": "$postCount",
+			"cond":  bson.M{"$eq": []string{"$$this.state", "published"}},
+		}}}
+	}
+
+	return m.AggregateDocuments(ctx, mq, limit, offset, sort, "topics", []bson.M{
+		{"$lookup": bson.M{
+			"from":         "posts",
+			"localField":   "_id",
+			"foreignField": "topics",
+			"as":           "postCount",
+		}},
+		// the lookup is empty if the topic has no posts
+		{"$addFields": bson.M{"postCount": postCount}},
+	}, topics)
+}
+
+// topicsGroup deduplicates the concurrent identical listings of the topics,
+// it is shared by all the MongoStorage since they are initialized per use
+var topicsGroup singleflight.Group
+
+type topicsResult struct {
+	topics []models.Topic
+	total  int
+}
+
+// getTopicsOnce shares one _GetTopics among the concurrent calls with the same kind and arguments, as fetchOnce does.
+// The shared query is bounded by the query timeout, and each call stops waiting once its own context is done.
+func (m *MongoStorage) getTopicsOnce(ctx context.Context, kind string, mq models.MongoQuery, limit int, offset int, sort string, embedded []string, isFull bool) ([]models.Topic, int, error) {
+	sum, err := hashListingArgs(mq, limit, offset, sort, embedded)
+	if err != nil {
+		return m._GetTopics(ctx, mq, limit, offset, sort, embedded, isFull)
+	}
+
+	shared := topicsGroup.DoChan(kind+":"+sum, func() (interface{}, error) {
+		flightCtx, cancel := newSharedQueryContext()
+		defer cancel()
+
+		topics, total, err := m._GetTopics(flightCtx, mq, limit, offset, sort, embedded, isFull)
+		return topicsResult{topics, total}, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, 0, errors.WithStack(ctx.Err())
+	case r := <-shared:
+		if r.Err != nil {
+			return nil, 0, r.Err
+		}
+		result := r.Val.(topicsResult)
+		if result.topics == nil {
+			return nil, result.total, nil
+		}
+		// copy the shared topics, so that the callers do not modify the others'
+		topics := make([]models.Topic, len(result.topics))
+		copy(topics, result.topics)
+		return topics, result.total, nil
+	}
+}
+
 // GetFullTopics is a type-specific functions implementing the method defined in the NewsStorage.
 // It will get full topics having ALL the corresponding assets
-func (m *MongoStorage) GetFullTopics(mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Topic, int, error) {
+func (m *MongoStorage) GetFullTopics(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Topic, int, error) {
 	if embedded == nil {
 		embedded = []string{"topic_relateds", "leading_image", "leading_image_portrait", "leading_video", "og_image"}
 	}
 
-	return m._GetTopics(mq, limit, offset, sort, embedded, true)
+	return m.getTopicsOnce(ctx, "fullTopics", mq, limit, offset, sort, embedded, true)
 }
 
 // GetMetaOfTopics is a type-specific functions implementing the method defined in the NewsStorage.
 // It will get full topics having PARTIAL corresponding assets
-func (m *MongoStorage) GetMetaOfTopics(mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Topic, int, error) {
+func (m *MongoStorage) GetMetaOfTopics(ctx context.Context, mq models.MongoQuery, limit int, offset int, sort string, embedded []string) ([]models.Topic, int, error) {
 	if embedded == nil {
 		embedded = []string{"leading_image", "leading_image_portrait", "og_image"}
 	}
 
-	return m._GetTopics(mq, limit, offset, sort, embedded, false)
+	return m.getTopicsOnce(ctx, "metaOfTopics", mq, limit, offset, sort, embedded, false)
+}
+
+// GetMetaOfTopicsBySlugs gets the topics of the slugs at once with PARTIAL corresponding assets,
+// the slugs without any topic are omitted.
+func (m *MongoStorage) GetMetaOfTopicsBySlugs(slugs []string) ([]models.Topic, error) {
+	var topics []models.Topic
+
+	query := bson.M{"slug": bson.M{"$in": slugs}}
+	if globals.Conf.Environment != "development" {
+		query["state"] = "published"
+	}
+
+	err := withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		var found []models.Topic
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		if err := session.DB(globals.Conf.DB.Mongo.DBname).C("topics").Find(query).All(&found); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get topics(slugs: %v) occurs error", slugs))
+		}
+		topics = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range topics {
+		m.GetEmbeddedAsset(&topics[index], []string{"leading_image", "leading_image_portrait", "og_image"})
+	}
+	return topics, nil
+}
+
+// GetTopicsUpdatedSince gets the topics updated after `since` and not after `until` with PARTIAL corresponding assets,
+// either of them is unbounded if it is zero.
+// The topics are sorted by updatedAt ascendingly, and topics updated at the same time are sorted by _id,
+// so that paging by limit and offset is stable for incremental fetching.
+func (m *MongoStorage) GetTopicsUpdatedSince(ctx context.Context, since time.Time, until time.Time, limit int, offset int) ([]models.Topic, int, error) {
+	mq := models.MongoQuery{
+		UpdatedAt: models.MongoQueryTimeComparison{GT: since, LTE: until},
+	}
+
+	return m.GetMetaOfTopics(ctx, mq, limit, offset, "updatedAt,_id", nil)
+}
+
+// GetTopicWithPosts gets the topic by slug with PARTIAL corresponding assets,
+// and the posts belonging to it paginated by postLimit and postOffset.
+// The total number of the posts is returned as well.
+func (m *MongoStorage) GetTopicWithPosts(ctx context.Context, slug string, postLimit int, postOffset int) (models.Topic, []models.Post, int, error) {
+	topics, _, err := m.GetMetaOfTopics(ctx, models.MongoQuery{Slug: slug}, 1, 0, "-publishedDate", nil)
+	if err != nil {
+		return models.Topic{}, nil, 0, err
+	}
+
+	if len(topics) == 0 {
+		return models.Topic{}, nil, 0, errors.Wrap(ErrMgoNotFound, fmt.Sprintf("get topic(slug: %s) occurs error", slug))
+	}
+
+	mq := models.MongoQuery{
+		Topics: models.MongoQueryComparison{In: []bson.ObjectId{topics[0].ID}},
+	}
+	// the topic of the posts is omitted, since it is the topic returned
+	embedded := []string{"hero_image", "leading_image_portrait", "categories", "tags", "og_image"}
+
+	posts, total, err := m.GetMetaOfPosts(ctx, mq, postLimit, postOffset, "-publishedDate", embedded)
+	if err != nil {
+		return models.Topic{}, nil, 0, err
+	}
+
+	return topics[0], posts, total, nil
+}
+
+// ReorderPostsOfTopic rearranges the related posts of the topic by slug in the order of the post slugs.
+// The slugs should be exactly the posts of the topic, otherwise ErrTopicPostsMismatch is returned.
+// The related posts which no longer exist are kept at the end since they have no slugs to be ordered by.
+func (m *MongoStorage) ReorderPostsOfTopic(slug string, order []string) error {
+	return withQueryTimeout(getQueryTimeout(), func(ctx context.Context) error {
+		var topic models.Topic
+		var posts []models.Post
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		db := session.DB(globals.Conf.DB.Mongo.DBname)
+
+		if err := db.C("topics").Find(bson.M{"slug": slug}).Select(bson.M{"relateds": 1}).One(&topic); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get topic(slug: %s) occurs error", slug))
+		}
+
+		// $in of no posts is rejected, and there is nothing to reorder anyway
+		if len(topic.RelatedsOrigin) == 0 {
+			if len(order) != 0 {
+				return errors.WithMessage(errors.WithStack(ErrTopicPostsMismatch), fmt.Sprintf("reorder posts of topic(slug: %s) occurs error", slug))
+			}
+			return nil
+		}
+
+		if err := db.C("posts").Find(bson.M{"_id": bson.M{"$in": topic.RelatedsOrigin}}).Select(bson.M{"slug": 1}).All(&posts); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get posts of topic(slug: %s) occurs error", slug))
+		}
+
+		relateds, err := orderRelatedsBySlugs(topic.RelatedsOrigin, posts, order)
+		if err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("reorder posts of topic(slug: %s) occurs error", slug))
+		}
+
+		// the topic is updated only if the posts are not changed by others since they are read
+		selector := bson.M{"_id": topic.ID, "relateds": topic.RelatedsOrigin}
+		if err := db.C("topics").Update(selector, bson.M{"$set": bson.M{"relateds": relateds, "updatedAt": time.Now()}}); err != nil {
+			if err == ErrMgoNotFound {
+				return errors.Wrap(ErrUpdateConflict, fmt.Sprintf("update posts of topic(slug: %s) occurs error", slug))
+			}
+			return errors.Wrap(err, fmt.Sprintf("update posts of topic(slug: %s) occurs error", slug))
+		}
+		return nil
+	})
+}
+
+// orderRelatedsBySlugs returns the ids of the related posts in the order of the slugs,
+// followed by the ids of which the posts are not found.
+func orderRelatedsBySlugs(ids []bson.ObjectId, posts []models.Post, order []string) ([]bson.ObjectId, error) {
+	var dangling []bson.ObjectId
+
+	idsBySlug := make(map[string]bson.ObjectId, len(posts))
+	for _, post := range posts {
+		idsBySlug[post.Slug] = post.ID
+	}
+
+	if len(order) != len(idsBySlug) {
+		return nil, errors.WithStack(ErrTopicPostsMismatch)
+	}
+
+	ordered := make([]bson.ObjectId, 0, len(ids))
+	for _, slug := range order {
+		id, ok := idsBySlug[slug]
+		if !ok {
+			return nil, errors.WithStack(ErrTopicPostsMismatch)
+		}
+		// the duplicated slug is removed so that it is not matched twice
+		delete(idsBySlug, slug)
+		ordered = append(ordered, id)
+	}
+
+	found := make(map[bson.ObjectId]bool, len(posts))
+	for _, post := range posts {
+		found[post.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			dangling = append(dangling, id)
+		}
+	}
+
+	return append(ordered, dangling...), nil
+}
+
+// GetRelatedTopics gets the topics sharing at least one of the tags(in hex) with PARTIAL corresponding assets,
+// excluding the topic of excludeSlug.
+// The topics are ranked by the number of shared tags, and then by publishedDate, both descendingly.
+func (m *MongoStorage) GetRelatedTopics(tags []string, excludeSlug string, limit int) ([]models.Topic, error) {
+	var topics []models.Topic
+	var ids []bson.ObjectId
+	var timeout = getQueryTimeout()
+
+	for _, tag := range tags {
+		if bson.IsObjectIdHex(tag) {
+			ids = append(ids, bson.ObjectIdHex(tag))
+		}
+	}
+
+	if len(ids) == 0 {
+		return topics, nil
+	}
+
+	match := bson.M{"tags": bson.M{"$in": ids}, "slug": bson.M{"$ne": excludeSlug}}
+	if globals.Conf.Environment != "development" {
+		match["state"] = "published"
+	}
+
+	err := withQueryTimeout(timeout, func(ctx context.Context) error {
+		var related []models.Topic
+
+		session := m.db.Copy()
+		defer session.Close()
+
+		pipe := session.DB(globals.Conf.DB.Mongo.DBname).C("topics").Pipe([]bson.M{
+			{"$match": match},
+			{"$addFields": bson.M{"sharedTags": bson.M{"$size": bson.M{"$setIntersection": []interface{}{"$tags", ids}}}}},
+			{"$sort": bson.D{{Name: "sharedTags", Value: -1}, {Name: "publishedDate", Value: -1}}},
+			{"$limit": limit},
+		})
+
+		if err := pipe.All(&related); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("get related topics(where: %v) occurs error", match))
+		}
+		topics = related
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range topics {
+		m.GetEmbeddedAsset(&topics[index], []string{"leading_image", "leading_image_portrait", "og_image"})
+	}
+	return topics, nil
 }
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+func TestOrderRelatedsBySlugs(t *testing.T) {
+	idA, idB, idC, idGone := bson.NewObjectId(), bson.NewObjectId(), bson.NewObjectId(), bson.NewObjectId()
+
+	ids := []bson.ObjectId{idA, idGone, idB, idC}
+	posts := []models.Post{{ID: idA, Slug: "a"}, {ID: idB, Slug: "b"}, {ID: idC, Slug: "c"}}
+
+	cases := []struct {
+		name    string
+		order   []string
+		want    []bson.ObjectId
+		wantErr bool
+	}{
+		{name: "Given the posts reordered", order: []string{"c", "a", "b"}, want: []bson.ObjectId{idC, idA, idB, idGone}},
+		{name: "Given a post missing", order: []string{"c", "a"}, wantErr: true},
+		{name: "Given a post not of the topic", order: []string{"c", "a", "d"}, wantErr: true},
+		{name: "Given a post duplicated", order: []string{"c", "a", "a"}, wantErr: true},
+		{name: "Given an extra post", order: []string{"c", "a", "b", "d"}, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := orderRelatedsBySlugs(ids, posts, tc.order)
+			if tc.wantErr {
+				if errors.Cause(err) != ErrTopicPostsMismatch {
+					t.Errorf("expect ErrTopicPostsMismatch, but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, but got %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expect %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+)
+
+const (
+	// colTopicViews stores the views of topics in hourly buckets, i.e. `{slug, hour, count}`
+	colTopicViews = "topic_views"
+	// topicViewsFlushInterval is the period the buffered views are written to database
+	topicViewsFlushInterval = 10 * time.Second
+)
+
+// viewBuffer accumulates the views in memory, so that a write is issued per slug per flush
+// rather than per view
+type viewBuffer struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newViewBuffer() *viewBuffer {
+	return &viewBuffer{counts: make(map[string]int)}
+}
+
+func (vb *viewBuffer) add(slug string) {
+	vb.mu.Lock()
+	defer vb.mu.Unlock()
+
+	vb.counts[slug]++
+}
+
+// merge adds the views back, e.g. the views drained but failed to be written
+func (vb *viewBuffer) merge(counts map[string]int) {
+	vb.mu.Lock()
+	defer vb.mu.Unlock()
+
+	for slug, count := range counts {
+		vb.counts[slug] += count
+	}
+}
+
+// drain returns the accumulated views and resets the buffer
+func (vb *viewBuffer) drain() map[string]int {
+	vb.mu.Lock()
+	defer vb.mu.Unlock()
+
+	counts := vb.counts
+	vb.counts = make(map[string]int)
+	return counts
+}
+
+var (
+	topicViews          = newViewBuffer()
+	topicViewsFlushOnce sync.Once
+)
+
+// IncrementTopicViews counts a view of the topic.
+// The views are buffered and written to database every `topicViewsFlushInterval`.
+func (m *MongoStorage) IncrementTopicViews(slug string) {
+	topicViewsFlushOnce.Do(func() {
+		go func() {
+			for range time.Tick(topicViewsFlushInterval) {
+				if err := m.FlushTopicViews(); err != nil {
+					log.Errorf("%+v", err)
+				}
+			}
+		}()
+	})
+
+	topicViews.add(slug)
+}
+
+// FlushTopicViews writes the buffered views into the hourly buckets.
+// The views are kept in the buffer for the next flush if the write fails,
+// it should be called on shutdown as well so that the views buffered are not lost.
+func (m *MongoStorage) FlushTopicViews() error {
+	counts := topicViews.drain()
+	if len(counts) == 0 {
+		return nil
+	}
+
+	session := m.db.Copy()
+	defer session.Close()
+
+	hour := time.Now().UTC().Truncate(time.Hour)
+	bulk := session.DB(globals.Conf.DB.Mongo.DBname).C(colTopicViews).Bulk()
+	bulk.Unordered()
+	slugs := make([]string, 0, len(counts))
+	for slug, count := range counts {
+		slugs = append(slugs, slug)
+		bulk.Upsert(bson.M{"slug": slug, "hour": hour}, bson.M{"$inc": bson.M{"count": count}})
+	}
+
+	if _, err := bulk.Run(); err != nil {
+		topicViews.merge(failedTopicViews(err, slugs, counts))
+		return errors.Wrap(err, fmt.Sprintf("flush topic views(counts: %v) occurs error", counts))
+	}
+	return nil
+}
+
+// GetTopicViewsSince ranks the topics by their views after `since`
+func (m *MongoStorage) GetTopicViewsSince(since time.Time, limit int) ([]models.TopicViews, error) {
+	var views []models.TopicViews
+
+	session := m.db.Copy()
+	defer session.Close()
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"hour": bson.M{"$gte": since.UTC().Truncate(time.Hour)}}},
+		{"$group": bson.M{"_id": "$slug", "views": bson.M{"$sum": "$count"}}},
+		{"$sort": bson.D{{Name: "views", Value: -1}, {Name: "_id", Value: 1}}},
+		{"$limit": limit},
+	}
+
+	if err := session.DB(globals.Conf.DB.Mongo.DBname).C(colTopicViews).Pipe(pipeline).All(&views); err != nil {
+		return views, errors.Wrap(err, fmt.Sprintf("get topic views(since: %v) occurs error", since))
+	}
+	return views, nil
+}
+
+// failedTopicViews returns the views of the upserts failed in the bulk, which are all the views
+// unless the failed upserts are all reported, e.g. the connection is lost
+func failedTopicViews(err error, slugs []string, counts map[string]int) map[string]int {
+	bulkErr, ok := err.(*mgo.BulkError)
+	if !ok {
+		return counts
+	}
+
+	failed := make(map[string]int)
+	for _, c := range bulkErr.Cases() {
+		if c.Index < 0 || c.Index >= len(slugs) {
+			return counts
+		}
+		failed[slugs[c.Index]] = counts[slugs[c.Index]]
+	}
+	return failed
+}
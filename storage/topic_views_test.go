@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewBuffer(t *testing.T) {
+	vb := newViewBuffer()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%4 == 0 {
+				vb.add("topic-b")
+			} else {
+				vb.add("topic-a")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{"topic-a": 75, "topic-b": 25}, vb.drain())
+	// the buffer is reset after draining
+	assert.Empty(t, vb.drain())
+
+	vb.add("topic-a")
+	assert.Equal(t, map[string]int{"topic-a": 1}, vb.drain())
+}
+
+func TestViewBufferMerge(t *testing.T) {
+	vb := newViewBuffer()
+	vb.add("topic-a")
+
+	// the views failed to be written are added to the views buffered since the drain
+	failed := failedTopicViews(errors.New("connection reset"), []string{"topic-a", "topic-b"}, map[string]int{"topic-a": 2, "topic-b": 3})
+	vb.merge(failed)
+
+	assert.Equal(t, map[string]int{"topic-a": 3, "topic-b": 3}, vb.drain())
+}
@@ -37,6 +37,27 @@ func (gs *GormStorage) GetUserByEmail(email string) (models.User, error) {
 	return user, nil
 }
 
+// GetUsersByIDs gets the users by their IDs, the IDs not found are absent from the map
+func (gs *GormStorage) GetUsersByIDs(ids []uint) (map[uint]models.User, error) {
+	var users []models.User
+	var usersByID = make(map[uint]models.User, len(ids))
+
+	if len(ids) == 0 {
+		return usersByID, nil
+	}
+
+	// SELECT * FROM users WHERE id IN $ids
+	if err := gs.db.Where("id IN (?)", ids).Find(&users).Error; err != nil {
+		return usersByID, errors.Wrap(err, fmt.Sprintf("get users(ids: %v) error", ids))
+	}
+
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	return usersByID, nil
+}
+
 // GetOAuthData gets the corresponding OAuth by using the OAuth information
 func (gs *GormStorage) GetOAuthData(aid null.String, aType string) (models.OAuthAccount, error) {
 	log.Debug("Getting the matching OAuth data", aid)
@@ -49,6 +70,18 @@ func (gs *GormStorage) GetOAuthData(aid null.String, aType string) (models.OAuth
 	return oac, nil
 }
 
+// GetOAuthAccountsOfAUser gets the OAuth accounts linked to the user in the order they are linked
+func (gs *GormStorage) GetOAuthAccountsOfAUser(userID string) ([]models.OAuthAccount, error) {
+	var accounts []models.OAuthAccount
+
+	// SELECT * FROM o_auth_accounts WHERE user_id = $userID ORDER BY created_at, id
+	if err := gs.db.Where("user_id = ?", userID).Order("created_at, id").Find(&accounts).Error; err != nil {
+		return accounts, errors.Wrap(err, fmt.Sprintf("get oauth accounts of user(id: %s) error", userID))
+	}
+
+	return accounts, nil
+}
+
 // GetUserDataByOAuth gets the corresponding user data by using the OAuth information
 func (gs *GormStorage) GetUserDataByOAuth(oac models.OAuthAccount) (models.User, error) {
 	log.Debug("Getting the matching User data")
@@ -114,7 +147,9 @@ func (gs *GormStorage) InsertReporterAccount(account models.ReporterAccount) err
 	return nil
 }
 
-// InsertUserByOAuth insert a new user into db after the oath loginin
+// InsertUserByOAuth insert a new user into db after the oath loginin.
+// The user and its oauth account are created in the same transaction by gorm association,
+// they cannot be written concurrently since `o_auth_accounts.user_id` refers to the auto-incremented `users.id`.
 func (gs *GormStorage) InsertUserByOAuth(omodel models.OAuthAccount) (user models.User, err error) {
 	log.Debug("Inserting user data")
 	user = models.User{
@@ -150,28 +185,63 @@ func (gs *GormStorage) InsertUserByReporterAccount(raModel models.ReporterAccoun
 	return user, nil
 }
 
-// UpdateOAuthData updates the corresponding OAuth by using the OAuth information
+// UpdateOAuthData updates the corresponding OAuth by using the OAuth information.
+// The update is applied only if the version is unchanged since it is read.
+// ErrUpdateConflict is returned if the OAuth data is updated by another login after it is read,
+// rather than overwriting the data of that login, which could be newer.
 func (gs *GormStorage) UpdateOAuthData(newData models.OAuthAccount) (models.OAuthAccount, error) {
 	log.Debug("Getting the matching OAuth data", newData.AId)
 	matO, err := gs.GetOAuthData(newData.AId, newData.Type)
 	if err != nil {
 		return matO, err
 	}
+
+	version := matO.Version
 	matO.Email = newData.Email
 	matO.Name = newData.Name
 	matO.FirstName = newData.FirstName
 	matO.LastName = newData.LastName
 	matO.Gender = newData.Gender
 	matO.Picture = newData.Picture
-	err = gs.db.Save(&matO).Error
+	matO.Version = version + 1
 
-	if err != nil {
-		return matO, errors.WithStack(err)
+	result := gs.db.Model(&models.OAuthAccount{}).Where("id = ? AND version = ?", matO.ID, version).Updates(map[string]interface{}{
+		"email":      matO.Email,
+		"name":       matO.Name,
+		"first_name": matO.FirstName,
+		"last_name":  matO.LastName,
+		"gender":     matO.Gender,
+		"picture":    matO.Picture,
+		"version":    matO.Version,
+	})
+
+	if result.Error != nil {
+		return matO, errors.WithStack(result.Error)
 	}
 
+	if result.RowsAffected != 1 {
+		return models.OAuthAccount{}, errors.Wrap(ErrUpdateConflict, fmt.Sprintf("update oauth data(a_id: %s, type: %s) occurs error", newData.AId.String, newData.Type))
+	}
 	return matO, nil
 }
 
+// UpdateOAuthPictures updates the avatar variants of the corresponding OAuth.
+// The version and updated_at are kept, since the pictures are not updated by UpdateOAuthData
+// and updated_at records the last refresh of the OAuth data, which is skipped for a recent Facebook sign-in.
+func (gs *GormStorage) UpdateOAuthPictures(aid null.String, aType string, pictures models.AvatarPictures) error {
+	err := gs.db.Model(&models.OAuthAccount{}).Where("type = ? AND a_id = ?", aType, aid).UpdateColumns(map[string]interface{}{
+		"picture_small":  pictures.Small,
+		"picture_medium": pictures.Medium,
+		"picture_large":  pictures.Large,
+	}).Error
+
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("update pictures of oauth account(a_id: %s, type: %s) occurs error", aid.String, aType))
+	}
+
+	return nil
+}
+
 // UpdateReporterAccount update a reporter account
 func (gs *GormStorage) UpdateReporterAccount(ra models.ReporterAccount) error {
 	err := gs.db.Model(&ra).Updates(&ra).Error
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/models"
+)
+
+// setUserBlocked updates whether the user is blocked along with the reason
+func (gs *GormStorage) setUserBlocked(userID string, blocked bool, reason null.String) error {
+	var user models.User
+
+	// SELECT id FROM users WHERE id = $userID
+	if err := gs.db.Select("id").First(&user, "id = ?", userID).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("get user(id: %s) error", userID))
+	}
+
+	// UPDATE users SET blocked = $blocked, block_reason = $reason WHERE id = $userID
+	if err := gs.db.Model(&user).Updates(map[string]interface{}{"blocked": blocked, "block_reason": reason}).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("update blocked of user(id: %s) error", userID))
+	}
+	return nil
+}
+
+// BlockUser blocks the user for the reason
+func (gs *GormStorage) BlockUser(userID string, reason string) error {
+	return gs.setUserBlocked(userID, true, null.NewString(reason, reason != ""))
+}
+
+// UnblockUser unblocks the user and clears the reason
+func (gs *GormStorage) UnblockUser(userID string) error {
+	return gs.setUserBlocked(userID, false, null.String{})
+}
+
+// CreateUserBlockLog records the block or unblock of the user in the audit log
+func (gs *GormStorage) CreateUserBlockLog(blockLog models.UserBlockLog) error {
+	if err := gs.db.Create(&blockLog).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("create block log of user(id: %d) error", blockLog.UserID))
+	}
+	return nil
+}
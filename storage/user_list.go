@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/models"
+)
+
+// UserOrderColumns maps the orders of UserFilter to the columns of users,
+// the order prefixed with `-` is descending, e.g. `-createdAt`
+var UserOrderColumns = map[string]string{
+	"id":        "id",
+	"createdAt": "created_at",
+	"email":     "email",
+}
+
+// UserFilter filters and orders the users listed
+type UserFilter struct {
+	// EmailPrefix matches the users whose email starts with it, all the users are matched if it is empty
+	EmailPrefix string
+	// OrderBy is one of UserOrderColumns, optionally prefixed with `-`, and it is `-createdAt` if empty
+	OrderBy string
+}
+
+// UserStorage defines the methods to manage the users
+type UserStorage interface {
+	ListUsers(filter UserFilter, limit, offset int) ([]models.User, int, error)
+	BlockUser(userID string, reason string) error
+	UnblockUser(userID string) error
+	CreateUserBlockLog(models.UserBlockLog) error
+}
+
+// escapeLike escapes the wildcards of LIKE pattern, so that they are matched literally
+var escapeLike = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace
+
+// ListUsers lists the users matching the filter, along with the total of the matched
+func (gs *GormStorage) ListUsers(filter UserFilter, limit, offset int) ([]models.User, int, error) {
+	var users []models.User
+	var total int
+
+	order := filter.OrderBy
+	if order == "" {
+		order = "-createdAt"
+	}
+	column, ok := UserOrderColumns[strings.TrimPrefix(order, "-")]
+	if !ok {
+		return users, 0, errors.New(fmt.Sprintf("cannot order users by %s", order))
+	}
+	if strings.HasPrefix(order, "-") {
+		column += " DESC"
+	}
+
+	// SELECT * FROM users WHERE email LIKE '$prefix%' ORDER BY $column, id LIMIT $limit OFFSET $offset
+	query := gs.db.Model(&models.User{})
+	if filter.EmailPrefix != "" {
+		query = query.Where("email LIKE ?", escapeLike(filter.EmailPrefix)+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return users, 0, errors.Wrap(err, fmt.Sprintf("count users(filter: %+v) error", filter))
+	}
+
+	if err := query.Order(column).Order("id").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return users, 0, errors.Wrap(err, fmt.Sprintf("list users(filter: %+v, limit: %d, offset: %d) error", filter, limit, offset))
+	}
+
+	return users, total, nil
+}
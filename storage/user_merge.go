@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/models"
+)
+
+// MergeUsers moves the accounts, bookmarks, subscriptions and donations of the source user to the target user
+// in a transaction, and then soft deletes the source user.
+// The registrations, paid subscriptions and donations are moved as well, so the receipts follow the merged user,
+// and so are the device tokens and category subscriptions of the push notifications and the reading history.
+// The conflicts are resolved in favor of the target user: the OAuth account of a type linked on both users,
+// the reporter account, the bookmarks, the category subscriptions and the posts in the reading history
+// the target user already has are kept, and those of the source user are deleted.
+// The post read by both users keeps the later read time.
+// The rows of the source user are moved or deleted explicitly, since `ON DELETE CASCADE` never fires on the soft deletion.
+// Since the login history is the sign-in time of the accounts, it goes along with the accounts.
+func (gs *GormStorage) MergeUsers(sourceID, targetID string) (models.UserMergeResult, error) {
+	var result models.UserMergeResult
+
+	tx := gs.db.Begin()
+
+	if err := tx.Error; nil != err {
+		return result, errors.Wrap(err, "cannot begin the user merge transaction")
+	}
+
+	if err := mergeUsers(tx, sourceID, targetID, &result); err != nil {
+		tx.Rollback()
+		return models.UserMergeResult{}, err
+	}
+
+	if err := tx.Commit().Error; nil != err {
+		return models.UserMergeResult{}, errors.Wrap(err, "cannot commit the user merge transaction")
+	}
+	return result, nil
+}
+
+func mergeUsers(tx *gorm.DB, sourceID, targetID string, result *models.UserMergeResult) error {
+	var source, target models.User
+	var sourceAccounts []models.OAuthAccount
+	var targetTypes []string
+
+	// lock both users, so that they are not merged concurrently
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&source, "id = ?", sourceID).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("get user(id: %s) error", sourceID))
+	}
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&target, "id = ?", targetID).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("get user(id: %s) error", targetID))
+	}
+
+	// the ids are compared after loaded, since different strings can refer to the same user
+	if source.ID == target.ID {
+		return errors.WithStack(ErrMergeSameUser)
+	}
+
+	result.UserID = target.ID
+	result.MergedUserID = source.ID
+	result.MovedOAuthAccounts = make([]string, 0)
+	result.DroppedOAuthAccounts = make([]string, 0)
+
+	// SELECT * FROM o_auth_accounts WHERE user_id = $sourceID ORDER BY type
+	if err := tx.Where("user_id = ?", source.ID).Order("type").Find(&sourceAccounts).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("get oauth accounts of user(id: %d) error", source.ID))
+	}
+	if err := tx.Model(&models.OAuthAccount{}).Where("user_id = ?", target.ID).Pluck("type", &targetTypes).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("get oauth accounts of user(id: %d) error", target.ID))
+	}
+
+	linked := make(map[string]bool, len(targetTypes))
+	for _, t := range targetTypes {
+		linked[t] = true
+	}
+
+	for _, account := range sourceAccounts {
+		if linked[account.Type] {
+			if err := tx.Unscoped().Delete(&account).Error; err != nil {
+				return errors.Wrap(err, fmt.Sprintf("cannot delete %s oauth account of user(id: %d)", account.Type, source.ID))
+			}
+			result.DroppedOAuthAccounts = append(result.DroppedOAuthAccounts, account.Type)
+			continue
+		}
+		if err := tx.Model(&account).UpdateColumn("user_id", target.ID).Error; err != nil {
+			return errors.Wrap(err, fmt.Sprintf("cannot move %s oauth account of user(id: %d)", account.Type, source.ID))
+		}
+		linked[account.Type] = true
+		result.MovedOAuthAccounts = append(result.MovedOAuthAccounts, account.Type)
+	}
+
+	var targetReporterAccounts int
+	if err := tx.Model(&models.ReporterAccount{}).Where("user_id = ?", target.ID).Count(&targetReporterAccounts).Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("get reporter account of user(id: %d) error", target.ID))
+	}
+
+	reporterAccounts := tx.Unscoped().Model(&models.ReporterAccount{}).Where("user_id = ?", source.ID)
+	if targetReporterAccounts > 0 {
+		reporterAccounts = reporterAccounts.Delete(models.ReporterAccount{})
+	} else {
+		reporterAccounts = reporterAccounts.UpdateColumn("user_id", target.ID)
+	}
+	if err := reporterAccounts.Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("cannot merge reporter account of user(id: %d)", source.ID))
+	}
+
+	// the bookmarks the target user already has are left behind and deleted below
+	moved := tx.Exec("UPDATE `users_bookmarks` SET `user_id` = ? WHERE `user_id` = ? AND `bookmark_id` NOT IN (SELECT `bookmark_id` FROM (SELECT `bookmark_id` FROM `users_bookmarks` WHERE `user_id` = ?) AS `b`)",
+		target.ID, source.ID, target.ID)
+	if err := moved.Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("cannot move bookmarks of user(id: %d)", source.ID))
+	}
+	result.MovedBookmarks = int(moved.RowsAffected)
+
+	subs := tx.Unscoped().Model(&models.WebPushSubscription{}).Where("user_id = ?", source.ID).UpdateColumn("user_id", target.ID)
+	if err := subs.Error; err != nil {
+		return errors.Wrap(err, fmt.Sprintf("cannot move web_push_subs of user(id: %d)", source.ID))
+	}
+	result.MovedSubscriptions = int(subs.RowsAffected)
+
+	for _, stmt := range []struct {
+		table string
+		exec  func(*gorm.DB) *gorm.DB
+	}{
+		{"registrations", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Model(&models.Registration{}).Where("user_id = ?", source.ID).UpdateColumn("user_id", target.ID)
+		}},
+		{"subscriptions", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Model(&models.Subscription{}).Where("user_id = ?", source.ID).UpdateColumn("user_id", target.ID)
+		}},
+		{"pay_by_prime_donations", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Model(&models.PayByPrimeDonation{}).Where("user_id = ?", source.ID).UpdateColumn("user_id", target.ID)
+		}},
+		{"pay_by_other_method_donations", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Model(&models.PayByOtherMethodDonation{}).Where("user_id = ?", source.ID).UpdateColumn("user_id", target.ID)
+		}},
+		{"periodic_donations", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Model(&models.PeriodicDonation{}).Where("user_id = ?", source.ID).UpdateColumn("user_id", target.ID)
+		}},
+		{"device_tokens", func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.DeviceToken{}).Where("user_id = ?", source.ID).UpdateColumn("user_id", target.ID)
+		}},
+		// the categories the target user already subscribes are left behind and deleted below
+		{"category_subscriptions", func(db *gorm.DB) *gorm.DB {
+			return db.Exec("UPDATE `category_subscriptions` SET `user_id` = ? WHERE `user_id` = ? AND `category_id` NOT IN (SELECT `category_id` FROM (SELECT `category_id` FROM `category_subscriptions` WHERE `user_id` = ?) AS `c`)",
+				target.ID, source.ID, target.ID)
+		}},
+		{"category_subscriptions", func(db *gorm.DB) *gorm.DB {
+			return db.Where("user_id = ?", source.ID).Delete(models.CategorySubscription{})
+		}},
+		// the posts the target user already read keep the later read time, and those of the source user are deleted below
+		{"reading_histories", func(db *gorm.DB) *gorm.DB {
+			return db.Exec("UPDATE `reading_histories` AS `t` JOIN `reading_histories` AS `s` ON `s`.`slug` = `t`.`slug` AND `s`.`user_id` = ? SET `t`.`read_at` = `s`.`read_at` WHERE `t`.`user_id` = ? AND `s`.`read_at` > `t`.`read_at`",
+				source.ID, target.ID)
+		}},
+		{"reading_histories", func(db *gorm.DB) *gorm.DB {
+			return db.Exec("UPDATE `reading_histories` SET `user_id` = ? WHERE `user_id` = ? AND `slug` NOT IN (SELECT `slug` FROM (SELECT `slug` FROM `reading_histories` WHERE `user_id` = ?) AS `r`)",
+				target.ID, source.ID, target.ID)
+		}},
+		{"reading_histories", func(db *gorm.DB) *gorm.DB {
+			return db.Where("user_id = ?", source.ID).Delete(models.ReadingHistory{})
+		}},
+		{"users_bookmarks", func(db *gorm.DB) *gorm.DB {
+			return db.Exec("DELETE FROM `users_bookmarks` WHERE `user_id` = ?", source.ID)
+		}},
+		{"users", func(db *gorm.DB) *gorm.DB {
+			return db.Model(&source).UpdateColumn("deleted_at", time.Now())
+		}},
+	} {
+		if err := stmt.exec(tx).Error; nil != err {
+			return errors.Wrap(err, fmt.Sprintf("cannot merge %s of user(id: %d)", stmt.table, source.ID))
+		}
+	}
+	return nil
+}
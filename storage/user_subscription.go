@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/models"
+)
+
+// SubscriptionStorage defines the methods to manage the paid subscriptions of the users
+type SubscriptionStorage interface {
+	CreateSubscription(models.Subscription) (models.Subscription, error)
+	GetSubscriptionOfAUser(string) (models.Subscription, error)
+	UpdateSubscription(string, map[string]interface{}) (models.Subscription, error)
+	CancelSubscription(string) (models.Subscription, error)
+	IsUserSubscribed(string) (bool, error)
+}
+
+// CreateSubscription creates the subscription of the existing user
+func (g *GormStorage) CreateSubscription(sub models.Subscription) (models.Subscription, error) {
+	var user models.User
+
+	// SELECT id FROM users WHERE id = $userID
+	if err := g.db.Select("id").First(&user, "id = ?", sub.UserID).Error; err != nil {
+		return models.Subscription{}, errors.Wrap(err, fmt.Sprintf("get user(id: %d) error", sub.UserID))
+	}
+
+	if err := g.db.Create(&sub).Error; err != nil {
+		return models.Subscription{}, errors.Wrap(err, fmt.Sprintf("create subscription of user(id: %d) error", sub.UserID))
+	}
+	return sub, nil
+}
+
+// GetSubscriptionOfAUser returns the latest subscription of the user, no matter whether it is still active
+func (g *GormStorage) GetSubscriptionOfAUser(userID string) (models.Subscription, error) {
+	var sub models.Subscription
+
+	// SELECT * FROM subscriptions WHERE user_id = $userID ORDER BY start_date DESC, id DESC LIMIT 1
+	if err := g.db.Where("user_id = ?", userID).Order("start_date DESC, id DESC").First(&sub).Error; err != nil {
+		return sub, errors.Wrap(err, fmt.Sprintf("get subscription of user(id: %s) error", userID))
+	}
+	return sub, nil
+}
+
+// UpdateSubscription updates the columns of the subscription and returns the updated one.
+// ErrInvalidDateRange is returned if `end_date` is not after the start date of the subscription.
+func (g *GormStorage) UpdateSubscription(id string, columns map[string]interface{}) (models.Subscription, error) {
+	var sub models.Subscription
+
+	if err := g.db.First(&sub, "id = ?", id).Error; err != nil {
+		return sub, errors.Wrap(err, fmt.Sprintf("get subscription(id: %s) error", id))
+	}
+
+	if endDate, ok := columns["end_date"].(null.Time); ok && endDate.Valid && !endDate.Time.After(sub.StartDate) {
+		return sub, errors.Wrap(ErrInvalidDateRange, fmt.Sprintf("update subscription(id: %s) error", id))
+	}
+
+	if err := g.db.Model(&sub).Updates(columns).Error; err != nil {
+		return sub, errors.Wrap(err, fmt.Sprintf("update subscription(id: %s) error", id))
+	}
+	return sub, nil
+}
+
+// CancelSubscription cancels the subscription, which ends right away if it would end later
+func (g *GormStorage) CancelSubscription(id string) (models.Subscription, error) {
+	var sub models.Subscription
+
+	if err := g.db.First(&sub, "id = ?", id).Error; err != nil {
+		return sub, errors.Wrap(err, fmt.Sprintf("get subscription(id: %s) error", id))
+	}
+
+	columns := map[string]interface{}{"status": models.SubscriptionStatusCanceled}
+	if now := time.Now(); !sub.EndDate.Valid || sub.EndDate.Time.After(now) {
+		columns["end_date"] = now
+	}
+
+	if err := g.db.Model(&sub).Updates(columns).Error; err != nil {
+		return sub, errors.Wrap(err, fmt.Sprintf("cancel subscription(id: %s) error", id))
+	}
+	return sub, nil
+}
+
+// IsUserSubscribed reports whether the user has an active subscription not expired
+func (g *GormStorage) IsUserSubscribed(userID string) (bool, error) {
+	var count int
+	now := time.Now()
+
+	// SELECT count(*) FROM subscriptions WHERE user_id = $userID AND status = 'active'
+	// AND start_date <= $now AND (end_date IS NULL OR end_date > $now)
+	if err := g.db.Model(&models.Subscription{}).
+		Where("user_id = ? AND status = ?", userID, models.SubscriptionStatusActive).
+		Where("start_date <= ? AND (end_date IS NULL OR end_date > ?)", now, now).
+		Count(&count).Error; err != nil {
+		return false, errors.Wrap(err, fmt.Sprintf("check subscription of user(id: %s) error", userID))
+	}
+	return count > 0, nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/guregu/null.v3"
+	"gopkg.in/mgo.v2/bson"
 	"twreporter.org/go-api/models"
 )
 
@@ -437,3 +438,226 @@ func TestDeleteBookmark(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteBookmarksOfAUser(t *testing.T) {
+	const cleanupStmt = "SET FOREIGN_KEY_CHECKS=0; TRUNCATE TABLE bookmarks; TRUNCATE TABLE users_bookmarks; SET FOREIGN_KEY_CHECKS=1"
+
+	userA := createUser("testUserA@twreporter.org")
+	defer func() { deleteUser(userA) }()
+	userB := createUser("testUserB@twreporter.org")
+	defer func() { deleteUser(userB) }()
+
+	bookmarks := []models.Bookmark{
+		models.Bookmark{Slug: "mock-slug-1", Host: "mockhost", Title: "mocktitle", Thumbnail: "mockthumb"},
+		models.Bookmark{Slug: "mock-slug-2", Host: "mockhost", Title: "mocktitle", Thumbnail: "mockthumb"},
+	}
+
+	for _, tc := range []struct {
+		name           string
+		targetUser     models.User
+		credential     string
+		resultCode     int
+		resultTotalOfA int
+		resultTotalOfB int
+		resultExistOfA int
+		resultExistOfB int
+	}{
+		{
+			name:           "StatusCode=StatusUnauthorized,Invalid jwt",
+			targetUser:     userA,
+			credential:     "INVALIDJWT",
+			resultCode:     http.StatusUnauthorized,
+			resultTotalOfA: len(bookmarks),
+			resultTotalOfB: len(bookmarks),
+			resultExistOfA: http.StatusOK,
+			resultExistOfB: http.StatusOK,
+		},
+		{
+			name:           "StatusCode=StatusForbidden,Clear bookmarks of another user",
+			targetUser:     userA,
+			credential:     "Bearer " + generateIDToken(userB),
+			resultCode:     http.StatusForbidden,
+			resultTotalOfA: len(bookmarks),
+			resultTotalOfB: len(bookmarks),
+			resultExistOfA: http.StatusOK,
+			resultExistOfB: http.StatusOK,
+		},
+		{
+			name:           "StatusCode=StatusNoContent,Clear all bookmarks of a user",
+			targetUser:     userA,
+			credential:     "Bearer " + generateIDToken(userA),
+			resultCode:     http.StatusNoContent,
+			resultTotalOfA: 0,
+			resultTotalOfB: len(bookmarks),
+			resultExistOfA: http.StatusNotFound,
+			resultExistOfB: http.StatusOK,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() { Globs.GormDB.Exec(cleanupStmt) }()
+
+			for _, u := range []models.User{userA, userB} {
+				for _, b := range bookmarks {
+					s, _ := json.Marshal(b)
+					serveHTTP("POST", fmt.Sprintf("/v1/users/%v/bookmarks", u.ID), string(s), "application/json", "Bearer "+generateIDToken(u))
+				}
+			}
+
+			resp := serveHTTP("DELETE", fmt.Sprintf("/v1/users/%v/bookmarks", tc.targetUser.ID), "", "", tc.credential)
+			assert.Equal(t, tc.resultCode, resp.Code)
+
+			for _, expected := range []struct {
+				user  models.User
+				total int
+				exist int
+			}{
+				{user: userA, total: tc.resultTotalOfA, exist: tc.resultExistOfA},
+				{user: userB, total: tc.resultTotalOfB, exist: tc.resultExistOfB},
+			} {
+				credential := "Bearer " + generateIDToken(expected.user)
+
+				resp = serveHTTP("GET", fmt.Sprintf("/v1/users/%v/bookmarks", expected.user.ID), "", "", credential)
+				body, _ := ioutil.ReadAll(resp.Result().Body)
+				res := response{}
+				json.Unmarshal(body, &res)
+				assert.Equal(t, expected.total, res.Meta.Total)
+
+				// bookmarks are still existed but not related to the user
+				resp = serveHTTP("GET", fmt.Sprintf("/v1/users/%v/bookmarks/%s?host=%s", expected.user.ID, bookmarks[0].Slug, bookmarks[0].Host), "", "", credential)
+				assert.Equal(t, expected.exist, resp.Code)
+			}
+		})
+	}
+}
+
+func TestGetABookmarkOfAUserAuthorization(t *testing.T) {
+	const cleanupStmt = "SET FOREIGN_KEY_CHECKS=0; TRUNCATE TABLE bookmarks; TRUNCATE TABLE users_bookmarks; SET FOREIGN_KEY_CHECKS=1"
+
+	userA := createUser("testUserA@twreporter.org")
+	defer func() { deleteUser(userA) }()
+	userB := createUser("testUserB@twreporter.org")
+	defer func() { deleteUser(userB) }()
+	defer func() { Globs.GormDB.Exec(cleanupStmt) }()
+
+	bookmark, _ := json.Marshal(models.Bookmark{Slug: "mock-slug-1", Host: "mockhost", Title: "mocktitle", Thumbnail: "mockthumb"})
+	serveHTTP("POST", fmt.Sprintf("/v1/users/%v/bookmarks", userA.ID), string(bookmark), "application/json", "Bearer "+generateIDToken(userA))
+
+	path := fmt.Sprintf("/v1/users/%v/bookmarks/mock-slug-1?host=mockhost", userA.ID)
+	for _, tc := range []struct {
+		name       string
+		credential string
+		resultCode int
+	}{
+		{
+			name:       "StatusCode=StatusUnauthorized,Invalid jwt",
+			credential: "INVALIDJWT",
+			resultCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "StatusCode=StatusForbidden,Check bookmark of another user",
+			credential: "Bearer " + generateIDToken(userB),
+			resultCode: http.StatusForbidden,
+		},
+		{
+			name:       "StatusCode=StatusOK,Check bookmark of the token owner",
+			credential: "Bearer " + generateIDToken(userA),
+			resultCode: http.StatusOK,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := serveHTTP("GET", path, "", "", tc.credential)
+			assert.Equal(t, tc.resultCode, resp.Code)
+		})
+	}
+}
+
+func TestImportBookmarksOfAUser(t *testing.T) {
+	type importResponse struct {
+		Status string                        `json:"status"`
+		Data   []models.BookmarkImportResult `json:"data"`
+	}
+
+	user := getUser(Globs.Defaults.Account)
+	path := fmt.Sprintf("/v1/users/%v/bookmarks/import", user.ID)
+	credential := "Bearer " + generateIDToken(user)
+	defer Globs.GormDB.Exec("SET FOREIGN_KEY_CHECKS=0; TRUNCATE TABLE bookmarks; TRUNCATE TABLE users_bookmarks; SET FOREIGN_KEY_CHECKS=1")
+
+	for _, bookmark := range []models.Bookmark{
+		{Slug: "mock-import-bookmarked", Host: "mockhost", Title: "mocktitle", Thumbnail: "mockthumb"},
+		{Slug: "mock-import-new", Host: "mockhost", Title: "mocktitle", Thumbnail: "mockthumb"},
+		{Slug: "mock-import-other-host", Host: "otherhost", Title: "mocktitle", Thumbnail: "mockthumb"},
+	} {
+		Globs.GormDB.Create(&bookmark)
+	}
+
+	postCol := Globs.MgoDB.DB(mgoDBName).C(mgoPostCol)
+	postSlugs := []string{"mock-import-bookmarked", "mock-import-new", "mock-import-other-host", "mock-import-unbookmarked", "mock-import-draft"}
+	for _, slug := range postSlugs {
+		state := "published"
+		if slug == "mock-import-draft" {
+			state = "draft"
+		}
+		postCol.Insert(models.Post{ID: bson.NewObjectId(), Slug: slug, Title: "mocktitle", State: state})
+	}
+	defer postCol.RemoveAll(bson.M{"slug": bson.M{"$in": postSlugs}})
+
+	s, _ := json.Marshal(models.Bookmark{Slug: "mock-import-bookmarked", Host: "mockhost", Title: "mocktitle", Thumbnail: "mockthumb"})
+	resp := serveHTTP("POST", fmt.Sprintf("/v1/users/%v/bookmarks", user.ID), string(s), "application/json", credential)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	// Start -- Import a mixed batch //
+	resp = serveHTTP("POST", path, `{"host":"mockhost","slugs":["mock-import-new","mock-import-bookmarked","mock-import-new","mock-import-other-host","mock-import-unbookmarked","mock-import-draft","mock-import-nonexistent"]}`, "application/json", credential)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	body, _ := ioutil.ReadAll(resp.Result().Body)
+	res := importResponse{}
+	json.Unmarshal(body, &res)
+	assert.Equal(t, "success", res.Status)
+	assert.Equal(t, []models.BookmarkImportResult{
+		{Slug: "mock-import-new", Status: models.BookmarkImportAdded},
+		{Slug: "mock-import-bookmarked", Status: models.BookmarkImportSkipped},
+		{Slug: "mock-import-new", Status: models.BookmarkImportSkipped},
+		// the bookmarks of the published posts are created under the host
+		{Slug: "mock-import-other-host", Status: models.BookmarkImportAdded},
+		{Slug: "mock-import-unbookmarked", Status: models.BookmarkImportAdded},
+		{Slug: "mock-import-draft", Status: models.BookmarkImportInvalid},
+		{Slug: "mock-import-nonexistent", Status: models.BookmarkImportInvalid},
+	}, res.Data)
+
+	resp = serveHTTP("GET", fmt.Sprintf("/v1/users/%v/bookmarks", user.ID), "", "", credential)
+	body, _ = ioutil.ReadAll(resp.Result().Body)
+	listRes := response{}
+	json.Unmarshal(body, &listRes)
+	assert.Equal(t, 4, listRes.Meta.Total)
+	// End -- Import a mixed batch //
+
+	// Start -- Import the same batch again //
+	resp = serveHTTP("POST", path, `{"host":"mockhost","slugs":["mock-import-new"]}`, "application/json", credential)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	body, _ = ioutil.ReadAll(resp.Result().Body)
+	res = importResponse{}
+	json.Unmarshal(body, &res)
+	assert.Equal(t, []models.BookmarkImportResult{{Slug: "mock-import-new", Status: models.BookmarkImportSkipped}}, res.Data)
+	// End -- Import the same batch again //
+
+	// Start -- Import too many slugs //
+	slugs := make([]string, 101)
+	for i := range slugs {
+		slugs[i] = fmt.Sprintf("mock-import-%d", i)
+	}
+	payload, _ := json.Marshal(map[string]interface{}{"host": "mockhost", "slugs": slugs})
+	resp = serveHTTP("POST", path, string(payload), "application/json", credential)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	// End -- Import too many slugs //
+
+	// Start -- Import without slugs //
+	resp = serveHTTP("POST", path, `{"host":"mockhost","slugs":[]}`, "application/json", credential)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	// End -- Import without slugs //
+
+	// Start -- Import by another user //
+	resp = serveHTTP("POST", fmt.Sprintf("/v1/users/%v/bookmarks/import", user.ID+1), `{"host":"mockhost","slugs":["mock-import-new"]}`, "application/json", credential)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	// End -- Import by another user //
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+func signFacebookRequest(payload, secret string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) + "." + encoded
+}
+
+func TestHandleFacebookDataDeletion(t *testing.T) {
+	const secret = "facebook-app-secret"
+	const path = "/v1/webhooks/facebook/data-deletion"
+
+	defaultSecret := globals.Conf.Oauth.Facebook.Secret
+	globals.Conf.Oauth.Facebook.Secret = secret
+	defer func() {
+		globals.Conf.Oauth.Facebook.Secret = defaultSecret
+	}()
+
+	user := createUser("data-deletion@twreporter.org")
+	defer deleteUser(user)
+	linkOAuthAccount(user, globals.FacebookOAuth, "facebook-aid-deletion", "data-deletion@facebook.com", "Deletion")
+	defer Globs.GormDB.Unscoped().Where("user_id = ?", user.ID).Delete(models.OAuthAccount{})
+	defer Globs.GormDB.Unscoped().Where("a_id = ?", "facebook-aid-deletion").Delete(models.DataDeletionRequest{})
+	Globs.GormDB.Create(&models.DeviceToken{UserID: user.ID, Token: "device-token-deletion", Platform: models.DeviceTokenPlatformAndroid})
+	Globs.GormDB.Create(&models.CategorySubscription{UserID: user.ID, CategoryID: "5edf118c3e631f0600198935"})
+	defer Globs.GormDB.Where("user_id = ?", user.ID).Delete(models.DeviceToken{})
+	defer Globs.GormDB.Where("user_id = ?", user.ID).Delete(models.CategorySubscription{})
+	Globs.GormDB.Create(&models.ReadingHistory{UserID: user.ID, Slug: "read-post-deletion", ReadAt: time.Now()})
+	defer Globs.GormDB.Where("user_id = ?", user.ID).Delete(models.ReadingHistory{})
+
+	t.Run("Given a tampered signed request", func(t *testing.T) {
+		form := url.Values{"signed_request": {signFacebookRequest(`{"algorithm":"HMAC-SHA256","user_id":"facebook-aid-deletion"}`, "another-secret")}}
+		resp := serveHTTP(http.MethodPost, path, form.Encode(), "application/x-www-form-urlencoded", "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		_, err := storage.NewGormStorage(Globs.GormDB).GetUserByID(fmt.Sprint(user.ID))
+		assert.Nil(t, err)
+	})
+
+	t.Run("Given a valid signed request", func(t *testing.T) {
+		var res struct {
+			URL              string `json:"url"`
+			ConfirmationCode string `json:"confirmation_code"`
+		}
+
+		form := url.Values{"signed_request": {signFacebookRequest(`{"algorithm":"HMAC-SHA256","issued_at":1291836800,"user_id":"facebook-aid-deletion"}`, secret)}}
+		resp := serveHTTP(http.MethodPost, path, form.Encode(), "application/x-www-form-urlencoded", "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		assert.NotEmpty(t, res.ConfirmationCode)
+		assert.True(t, strings.HasSuffix(res.URL, path+"/"+res.ConfirmationCode))
+
+		// the deletion is scheduled, poll the status until it is done
+		var status struct {
+			Status string                     `json:"status"`
+			Data   models.DataDeletionRequest `json:"data"`
+		}
+		for i := 0; i < 50; i++ {
+			resp = serveHTTP(http.MethodGet, path+"/"+res.ConfirmationCode, "", "", "")
+			assert.Equal(t, http.StatusOK, resp.Code)
+			json.Unmarshal(resp.Body.Bytes(), &status)
+			if status.Data.Status != models.DataDeletionStatusPending {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.Equal(t, models.DataDeletionStatusCompleted, status.Data.Status)
+
+		// the user is soft deleted with the personal data erased
+		var deleted models.User
+		Globs.GormDB.Unscoped().First(&deleted, user.ID)
+		assert.NotNil(t, deleted.DeletedAt)
+		assert.False(t, deleted.Email.Valid)
+
+		accounts, _ := storage.NewGormStorage(Globs.GormDB).GetOAuthAccountsOfAUser(fmt.Sprint(user.ID))
+		assert.Empty(t, accounts)
+
+		// the push tokens and reading history are deleted, since the cascade never fires on the soft deletion
+		var tokens, categorySubs, histories int
+		Globs.GormDB.Model(&models.DeviceToken{}).Where("user_id = ?", user.ID).Count(&tokens)
+		Globs.GormDB.Model(&models.CategorySubscription{}).Where("user_id = ?", user.ID).Count(&categorySubs)
+		Globs.GormDB.Model(&models.ReadingHistory{}).Where("user_id = ?", user.ID).Count(&histories)
+		assert.Equal(t, 0, tokens)
+		assert.Equal(t, 0, categorySubs)
+		assert.Equal(t, 0, histories)
+	})
+
+	t.Run("Given an unknown confirmation code", func(t *testing.T) {
+		resp := serveHTTP(http.MethodGet, path+"/unknown", "", "", "")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
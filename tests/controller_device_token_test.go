@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+func TestPushNotificationSubscriptions(t *testing.T) {
+	const categoryID = "5edf118c3e631f0600198935"
+
+	user := createUser("push-subscriber@twreporter.org")
+	defer deleteUser(user)
+	defer Globs.GormDB.Where("user_id = ?", user.ID).Delete(models.CategorySubscription{})
+	defer Globs.GormDB.Where("user_id = ?", user.ID).Delete(models.DeviceToken{})
+	other := createUser("push-other@twreporter.org")
+	defer deleteUser(other)
+
+	auth := "Bearer " + generateIDToken(user)
+	tokensPath := fmt.Sprintf("/v1/users/%d/device-tokens", user.ID)
+	subsPath := fmt.Sprintf("/v1/users/%d/category-subscriptions", user.ID)
+
+	t.Run("Given another user", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, tokensPath, `{"token":"mock-token","platform":"ios"}`, "application/json", "Bearer "+generateIDToken(other))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Given an invalid platform", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, tokensPath, `{"token":"mock-token","platform":"windows"}`, "application/json", auth)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Given the device tokens and the category subscription", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, tokensPath, `{"token":"mock-token","platform":"ios"}`, "application/json", auth)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		// the same token is registered again
+		resp = serveHTTP(http.MethodPost, tokensPath, `{"token":"mock-token","platform":"ios"}`, "application/json", auth)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		resp = serveHTTP(http.MethodPost, subsPath, fmt.Sprintf(`{"category_id":"%s"}`, categoryID), "application/json", auth)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		resp = serveHTTP(http.MethodPost, subsPath, fmt.Sprintf(`{"category_id":"%s"}`, categoryID), "application/json", auth)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		tokens, err := storage.NewGormStorage(Globs.GormDB).GetDeviceTokensOfCategories([]string{categoryID})
+		assert.Nil(t, err)
+		if assert.Len(t, tokens, 1) {
+			assert.Equal(t, "mock-token", tokens[0].Token)
+			assert.Equal(t, user.ID, tokens[0].UserID)
+		}
+	})
+
+	t.Run("Given the category unsubscribed", func(t *testing.T) {
+		resp := serveHTTP(http.MethodDelete, fmt.Sprintf("%s/%s", subsPath, categoryID), "", "", auth)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		resp = serveHTTP(http.MethodDelete, fmt.Sprintf("%s/%s", subsPath, categoryID), "", "", auth)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		tokens, err := storage.NewGormStorage(Globs.GormDB).GetDeviceTokensOfCategories([]string{categoryID})
+		assert.Nil(t, err)
+		assert.Len(t, tokens, 0)
+	})
+}
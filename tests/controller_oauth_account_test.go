@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type oauthAccountsResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Records []struct {
+			Type        string `json:"type"`
+			Email       string `json:"email"`
+			Name        string `json:"name"`
+			DisplayName string `json:"display_name"`
+			AId         string `json:"a_id"`
+			Pictures    *struct {
+				Small  string `json:"small"`
+				Medium string `json:"medium"`
+				Large  string `json:"large"`
+			} `json:"pictures"`
+		} `json:"records"`
+	} `json:"data"`
+}
+
+func linkOAuthAccount(user models.User, aType, aid, email, name string) {
+	as := storage.NewGormStorage(Globs.GormDB)
+	as.InsertOAuthAccount(models.OAuthAccount{
+		UserID: user.ID,
+		Type:   aType,
+		AId:    null.StringFrom(aid),
+		Email:  null.StringFrom(email),
+		Name:   null.StringFrom(name),
+	})
+}
+
+func TestGetOAuthAccountsOfAUser(t *testing.T) {
+	single := createUser("oauth-single@twreporter.org")
+	multiple := createUser("oauth-multiple@twreporter.org")
+	defer deleteUser(single)
+	defer deleteUser(multiple)
+
+	linkOAuthAccount(single, globals.GoogleOAuth, "google-aid-1", "single@gmail.com", "Single")
+	linkOAuthAccount(multiple, globals.GoogleOAuth, "google-aid-2", "multiple@gmail.com", "Multiple")
+	linkOAuthAccount(multiple, globals.FacebookOAuth, "facebook-aid-2", "multiple@facebook.com", "Multiple")
+	defer Globs.GormDB.Unscoped().Where("user_id IN (?)", []uint{single.ID, multiple.ID}).Delete(models.OAuthAccount{})
+
+	t.Run("Given a user with one provider", func(t *testing.T) {
+		var res oauthAccountsResponse
+
+		resp := serveHTTP(http.MethodGet, fmt.Sprintf("/v1/users/%d/oauth", single.ID), "", "", "Bearer "+generateIDToken(single))
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		assert.Equal(t, "success", res.Status)
+		if assert.Len(t, res.Data.Records, 1) {
+			assert.Equal(t, globals.GoogleOAuth, res.Data.Records[0].Type)
+			assert.Equal(t, "s*****@gmail.com", res.Data.Records[0].Email)
+			assert.Equal(t, "S*****", res.Data.Records[0].Name)
+			assert.Equal(t, "S*****", res.Data.Records[0].DisplayName)
+			assert.Empty(t, res.Data.Records[0].AId)
+		}
+	})
+
+	t.Run("Given a user with multiple providers", func(t *testing.T) {
+		var res oauthAccountsResponse
+
+		resp := serveHTTP(http.MethodGet, fmt.Sprintf("/v1/users/%d/oauth", multiple.ID), "", "", "Bearer "+generateIDToken(multiple))
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		if assert.Len(t, res.Data.Records, 2) {
+			assert.Equal(t, globals.GoogleOAuth, res.Data.Records[0].Type)
+			assert.Equal(t, globals.FacebookOAuth, res.Data.Records[1].Type)
+			assert.Nil(t, res.Data.Records[1].Pictures)
+		}
+	})
+
+	t.Run("Given the avatar variants stored", func(t *testing.T) {
+		var res oauthAccountsResponse
+
+		as := storage.NewGormStorage(Globs.GormDB)
+		err := as.UpdateOAuthPictures(null.StringFrom("facebook-aid-2"), globals.FacebookOAuth, models.AvatarPictures{
+			Small:  null.StringFrom("https://avatars.twreporter.org/small.jpg"),
+			Medium: null.StringFrom("https://avatars.twreporter.org/medium.jpg"),
+			Large:  null.StringFrom("https://avatars.twreporter.org/large.jpg"),
+		})
+		assert.Nil(t, err)
+
+		resp := serveHTTP(http.MethodGet, fmt.Sprintf("/v1/users/%d/oauth", multiple.ID), "", "", "Bearer "+generateIDToken(multiple))
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		if assert.Len(t, res.Data.Records, 2) && assert.NotNil(t, res.Data.Records[1].Pictures) {
+			assert.Equal(t, "https://avatars.twreporter.org/small.jpg", res.Data.Records[1].Pictures.Small)
+			assert.Equal(t, "https://avatars.twreporter.org/medium.jpg", res.Data.Records[1].Pictures.Medium)
+			assert.Equal(t, "https://avatars.twreporter.org/large.jpg", res.Data.Records[1].Pictures.Large)
+		}
+		// the google account is left null
+		assert.Nil(t, res.Data.Records[0].Pictures)
+	})
+
+	t.Run("Given another user", func(t *testing.T) {
+		resp := serveHTTP(http.MethodGet, fmt.Sprintf("/v1/users/%d/oauth", multiple.ID), "", "", "Bearer "+generateIDToken(single))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Given no authorization", func(t *testing.T) {
+		resp := serveHTTP(http.MethodGet, fmt.Sprintf("/v1/users/%d/oauth", single.ID), "", "", "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Given an admin", func(t *testing.T) {
+		admin := createUser("oauth-admin@twreporter.org")
+		defer deleteUser(admin)
+		Globs.GormDB.Model(&admin).Update("privilege", constants.PrivilegeAdmin)
+
+		resp := serveHTTP(http.MethodGet, fmt.Sprintf("/v1/users/%d/oauth", multiple.ID), "", "", "Bearer "+generateIDToken(admin))
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+}
+
+func TestInsertUserByOAuth(t *testing.T) {
+	as := storage.NewGormStorage(Globs.GormDB)
+
+	user, err := as.InsertUserByOAuth(models.OAuthAccount{
+		Type:  globals.GoogleOAuth,
+		AId:   null.StringFrom("google-aid-insert"),
+		Email: null.StringFrom("oauth-insert@twreporter.org"),
+	})
+	assert.Nil(t, err)
+	defer Globs.GormDB.Unscoped().Where("user_id = ?", user.ID).Delete(models.OAuthAccount{})
+	defer Globs.GormDB.Unscoped().Delete(user)
+
+	// both the user and the oauth account referring to it are written
+	_, err = as.GetUserByID(fmt.Sprint(user.ID))
+	assert.Nil(t, err)
+
+	accounts, err := as.GetOAuthAccountsOfAUser(fmt.Sprint(user.ID))
+	assert.Nil(t, err)
+	if assert.Len(t, accounts, 1) {
+		assert.Equal(t, "google-aid-insert", accounts[0].AId.String)
+	}
+}
+
+func TestUpdateOAuthDataConcurrently(t *testing.T) {
+	const logins = 10
+
+	as := storage.NewGormStorage(Globs.GormDB)
+
+	user, err := as.InsertUserByOAuth(models.OAuthAccount{
+		Type:  globals.GoogleOAuth,
+		AId:   null.StringFrom("google-aid-concurrent"),
+		Email: null.StringFrom("oauth-concurrent@twreporter.org"),
+	})
+	assert.Nil(t, err)
+	defer Globs.GormDB.Unscoped().Where("user_id = ?", user.ID).Delete(models.OAuthAccount{})
+	defer Globs.GormDB.Unscoped().Delete(user)
+
+	var wg sync.WaitGroup
+	errs := make([]error, logins)
+	for i := 0; i < logins; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = as.UpdateOAuthData(models.OAuthAccount{
+				Type:      globals.GoogleOAuth,
+				AId:       null.StringFrom("google-aid-concurrent"),
+				Email:     null.StringFrom("oauth-concurrent@twreporter.org"),
+				Name:      null.StringFrom(fmt.Sprintf("name-%d", i)),
+				FirstName: null.StringFrom(fmt.Sprintf("first-%d", i)),
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	var updated int
+	for _, err := range errs {
+		if err == nil {
+			updated++
+			continue
+		}
+		// the logins reading the data updated by another fail with conflict rather than overwriting
+		assert.True(t, storage.IsConflict(err), "unexpected error %v", err)
+	}
+	assert.True(t, updated > 0)
+
+	account, err := as.GetOAuthData(null.StringFrom("google-aid-concurrent"), globals.GoogleOAuth)
+	assert.Nil(t, err)
+	// every successful update is applied once
+	assert.Equal(t, uint(updated), account.Version)
+	// the fields are all written by the same login
+	assert.Equal(t, strings.Replace(account.Name.String, "name-", "first-", 1), account.FirstName.String)
+}
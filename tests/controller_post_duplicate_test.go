@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/models"
+)
+
+func TestDuplicatePost(t *testing.T) {
+	var res struct {
+		Status string      `json:"status"`
+		Data   models.Post `json:"data"`
+	}
+
+	admin := createUser("duplicate-admin@twreporter.org")
+	defer deleteUser(admin)
+	Globs.GormDB.Model(&admin).Update("privilege", constants.PrivilegeAdmin)
+
+	copySlugs := []string{Globs.Defaults.MockPostSlug1 + "-copy", Globs.Defaults.MockPostSlug1 + "-copy-2"}
+	defer Globs.MgoDB.DB(mgoDBName).C(mgoPostCol).RemoveAll(bson.M{"slug": bson.M{"$in": copySlugs}})
+
+	path := "/v1/admin/posts/" + Globs.Defaults.MockPostSlug1 + "/duplicate"
+
+	t.Run("Given a post duplicated twice", func(t *testing.T) {
+		for _, slug := range copySlugs {
+			resp := serveHTTP(http.MethodPost, path, "", "", "Bearer "+generateIDToken(admin))
+			assert.Equal(t, http.StatusCreated, resp.Code)
+
+			res.Data = models.Post{}
+			json.Unmarshal(resp.Body.Bytes(), &res)
+			assert.Equal(t, "success", res.Status)
+			assert.Equal(t, slug, res.Data.Slug)
+			assert.Equal(t, Globs.Defaults.PostCol1.Title+" (copy)", res.Data.Title)
+			assert.Equal(t, "draft", res.Data.State)
+			assert.True(t, res.Data.PublishedDate.IsZero())
+			assert.NotEqual(t, Globs.Defaults.PostID1, res.Data.ID)
+			assert.Equal(t, Globs.Defaults.PostCol1.HeroImageOrigin, res.Data.HeroImageOrigin)
+		}
+	})
+
+	t.Run("Given a post not existing", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, "/v1/admin/posts/post-not-found/duplicate", "", "", "Bearer "+generateIDToken(admin))
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Given a user not admin", func(t *testing.T) {
+		user := createUser("duplicate-user@twreporter.org")
+		defer deleteUser(user)
+
+		resp := serveHTTP(http.MethodPost, path, "", "", "Bearer "+generateIDToken(user))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+}
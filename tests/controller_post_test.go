@@ -7,10 +7,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gopkg.in/mgo.v2/bson"
 	"twreporter.org/go-api/configs"
+	"twreporter.org/go-api/configs/constants"
 	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
 )
 
 /*
@@ -159,3 +164,349 @@ func TestGetPosts(t *testing.T) {
 	assert.Equal(t, post.ID, Globs.Defaults.PostCol2.ID)
 	// End -- Get posts containing TagID //
 }
+
+func TestGetAdjacentPosts(t *testing.T) {
+	type adjacentPostResponse struct {
+		Status string       `json:"status"`
+		Record *models.Post `json:"record"`
+	}
+
+	for _, tc := range []struct {
+		name     string
+		path     string
+		code     int
+		wantSlug string
+	}{
+		{
+			name:     "StatusCode=StatusOK,Next post of the first post",
+			path:     "/v1/posts/" + Globs.Defaults.MockPostSlug1 + "/next",
+			code:     http.StatusOK,
+			wantSlug: Globs.Defaults.PostCol2.Slug,
+		},
+		{
+			name: "StatusCode=StatusOK,Previous post of the first post",
+			path: "/v1/posts/" + Globs.Defaults.MockPostSlug1 + "/previous",
+			code: http.StatusOK,
+		},
+		{
+			name:     "StatusCode=StatusOK,Previous post of the last post",
+			path:     "/v1/posts/" + Globs.Defaults.PostCol2.Slug + "/previous",
+			code:     http.StatusOK,
+			wantSlug: Globs.Defaults.MockPostSlug1,
+		},
+		{
+			name: "StatusCode=StatusOK,Next post of the last post",
+			path: "/v1/posts/" + Globs.Defaults.PostCol2.Slug + "/next",
+			code: http.StatusOK,
+		},
+		{
+			name: "StatusCode=StatusNotFound,Post not found",
+			path: "/v1/posts/post-not-found/next",
+			code: http.StatusNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := serveHTTP("GET", tc.path, "", "", "")
+			assert.Equal(t, tc.code, resp.Code)
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			res := adjacentPostResponse{}
+			json.Unmarshal(resp.Body.Bytes(), &res)
+			if tc.wantSlug == "" {
+				assert.Nil(t, res.Record)
+				return
+			}
+			if assert.NotNil(t, res.Record) {
+				assert.Equal(t, tc.wantSlug, res.Record.Slug)
+			}
+		})
+	}
+}
+
+func TestGetRandomPost(t *testing.T) {
+	getRandomPost := func(path string) (int, models.Post) {
+		resp := serveHTTP("GET", path, "", "", "")
+		body, _ := ioutil.ReadAll(resp.Result().Body)
+		res := postResponse{}
+		json.Unmarshal(body, &res)
+		return resp.Code, res.Record
+	}
+
+	// Start -- Get a random post //
+	code, post := getRandomPost("/v1/posts/random")
+	assert.Equal(t, 200, code)
+	assert.Contains(t, []bson.ObjectId{Globs.Defaults.PostID1, Globs.Defaults.PostID2}, post.ID)
+	// End -- Get a random post //
+
+	// Start -- Get a random post in the category //
+	code, post = getRandomPost("/v1/posts/random?category=" + Globs.Defaults.CatReviewID.Hex())
+	assert.Equal(t, 200, code)
+	assert.Equal(t, Globs.Defaults.PostID2, post.ID)
+	// End -- Get a random post in the category //
+
+	// Start -- Get a random post excluding the seen posts //
+	code, post = getRandomPost("/v1/posts/random?exclude=" + Globs.Defaults.MockPostSlug1)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, Globs.Defaults.PostID2, post.ID)
+
+	code, _ = getRandomPost(fmt.Sprintf("/v1/posts/random?exclude=%s,%s", Globs.Defaults.MockPostSlug1, Globs.Defaults.PostCol2.Slug))
+	assert.Equal(t, 404, code)
+	// End -- Get a random post excluding the seen posts //
+
+	// Start -- Get a random post with invalid category //
+	code, _ = getRandomPost("/v1/posts/random?category=photography")
+	assert.Equal(t, 400, code)
+	// End -- Get a random post with invalid category //
+}
+
+func TestGetPostsByIDs(t *testing.T) {
+	ms := storage.NewMongoStorage(Globs.MgoDB)
+	toObjectID := func(id bson.ObjectId) primitive.ObjectID {
+		objectID, _ := primitive.ObjectIDFromHex(id.Hex())
+		return objectID
+	}
+
+	posts, err := ms.GetPostsByIDs([]primitive.ObjectID{
+		toObjectID(Globs.Defaults.PostCol2.ID),
+		toObjectID(bson.NewObjectId()),
+		toObjectID(Globs.Defaults.PostCol1.ID),
+	})
+	assert.Nil(t, err)
+	if assert.Len(t, posts, 2) {
+		assert.Equal(t, Globs.Defaults.PostCol2.ID, posts[0].ID)
+		assert.Equal(t, Globs.Defaults.PostCol1.ID, posts[1].ID)
+	}
+
+	posts, err = ms.GetPostsByIDs(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, posts)
+}
+
+func TestGetPostsByYearMonth(t *testing.T) {
+	taipei := time.FixedZone("Asia/Taipei", 8*60*60)
+
+	firstDay := models.Post{ID: bson.NewObjectId(), Slug: "post-by-date-first-day", State: "published", PublishedDate: time.Date(2019, time.June, 1, 0, 30, 0, 0, taipei)}
+	midMonth := models.Post{ID: bson.NewObjectId(), Slug: "post-by-date-mid-month", State: "published", PublishedDate: time.Date(2019, time.June, 15, 12, 0, 0, 0, taipei)}
+	nextMonth := models.Post{ID: bson.NewObjectId(), Slug: "post-by-date-next-month", State: "published", PublishedDate: time.Date(2019, time.July, 1, 0, 0, 0, 0, taipei)}
+
+	col := Globs.MgoDB.DB(mgoDBName).C(mgoPostCol)
+	col.Insert(firstDay, midMonth, nextMonth)
+	defer col.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{firstDay.ID, midMonth.ID, nextMonth.ID}}})
+
+	getPosts := func(path string) []string {
+		resp := serveHTTP("GET", path, "", "", "")
+		assert.Equal(t, 200, resp.Code)
+
+		body, _ := ioutil.ReadAll(resp.Result().Body)
+		res := postsResponse{}
+		json.Unmarshal(body, &res)
+
+		slugs := make([]string, 0)
+		for _, post := range res.Records {
+			slugs = append(slugs, post.Slug)
+		}
+		return slugs
+	}
+
+	// Start -- Posts of the month sorted by published date descendingly //
+	assert.Equal(t, []string{midMonth.Slug, firstDay.Slug}, getPosts("/v1/posts/by-date/2019/06"))
+	assert.Equal(t, []string{nextMonth.Slug}, getPosts("/v1/posts/by-date/2019/7"))
+	// End -- Posts of the month sorted by published date descendingly //
+
+	// Start -- Posts of the month paginated //
+	assert.Equal(t, []string{firstDay.Slug}, getPosts("/v1/posts/by-date/2019/06?limit=1&offset=1"))
+	// End -- Posts of the month paginated //
+
+	// Start -- Posts of the month without posts //
+	assert.Equal(t, []string{}, getPosts("/v1/posts/by-date/2019/05"))
+	// End -- Posts of the month without posts //
+
+	// Start -- Invalid year or month //
+	assert.Equal(t, 400, serveHTTP("GET", "/v1/posts/by-date/1999/06", "", "", "").Code)
+	assert.Equal(t, 400, serveHTTP("GET", "/v1/posts/by-date/2019/13", "", "", "").Code)
+	// End -- Invalid year or month //
+}
+
+func TestGetPostsBySeries(t *testing.T) {
+	part1 := models.Post{ID: bson.NewObjectId(), Slug: "post-series-part-1", State: "published", SeriesSlug: "mock-series", PartNumber: 1, TotalParts: 3, PublishedDate: time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC)}
+	part2 := models.Post{ID: bson.NewObjectId(), Slug: "post-series-part-2", State: "published", SeriesSlug: "mock-series", PartNumber: 2, TotalParts: 3, PublishedDate: time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC)}
+
+	col := Globs.MgoDB.DB(mgoDBName).C(mgoPostCol)
+	col.Insert(part2, part1)
+	defer col.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{part1.ID, part2.ID}}})
+
+	// Start -- Posts of the series ordered by part number //
+	resp := serveHTTP("GET", "/v1/posts/series/mock-series", "", "", "")
+	assert.Equal(t, 200, resp.Code)
+
+	var postsRes struct {
+		Data struct {
+			Records []models.Post `json:"records"`
+		} `json:"data"`
+	}
+	body, _ := ioutil.ReadAll(resp.Result().Body)
+	json.Unmarshal(body, &postsRes)
+
+	slugs := make([]string, 0)
+	for _, post := range postsRes.Data.Records {
+		slugs = append(slugs, post.Slug)
+	}
+	assert.Equal(t, []string{part1.Slug, part2.Slug}, slugs)
+	// End -- Posts of the series ordered by part number //
+
+	// Start -- Series not found //
+	assert.Equal(t, 404, serveHTTP("GET", "/v1/posts/series/series-not-found", "", "", "").Code)
+	assert.Equal(t, 400, serveHTTP("GET", "/v1/posts/series/invalid.series", "", "", "").Code)
+	// End -- Series not found //
+
+	// Start -- Series with post count //
+	resp = serveHTTP("GET", "/v1/series", "", "", "")
+	assert.Equal(t, 200, resp.Code)
+
+	var seriesRes struct {
+		Data struct {
+			Records []models.Series `json:"records"`
+		} `json:"data"`
+	}
+	body, _ = ioutil.ReadAll(resp.Result().Body)
+	json.Unmarshal(body, &seriesRes)
+
+	var found *models.Series
+	for i, series := range seriesRes.Data.Records {
+		if series.Slug == "mock-series" {
+			found = &seriesRes.Data.Records[i]
+		}
+	}
+	if assert.NotNil(t, found) {
+		assert.Equal(t, 2, found.PostCount)
+		assert.Equal(t, 3, found.TotalParts)
+		assert.True(t, part1.PublishedDate.Equal(found.LatestPublishedDate))
+	}
+	// End -- Series with post count //
+}
+
+func TestGetPostsExpandAuthors(t *testing.T) {
+	writer := models.Author{ID: bson.NewObjectId(), Name: "writer", JobTitle: "reporter"}
+	photographer := models.Author{ID: bson.NewObjectId(), Name: "photographer"}
+	editor := models.Author{ID: bson.NewObjectId(), Name: "editor"}
+	post := models.Post{
+		ID:                  bson.NewObjectId(),
+		Slug:                "post-expand-authors",
+		State:               "published",
+		PublishedDate:       time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC),
+		WrittersOrigin:      []bson.ObjectId{writer.ID, editor.ID},
+		PhotographersOrigin: []bson.ObjectId{photographer.ID},
+	}
+	topic := models.Topic{ID: bson.NewObjectId(), Slug: "topic-expand-authors", State: "published", RelatedsOrigin: []bson.ObjectId{post.ID}}
+
+	contactCol := Globs.MgoDB.DB(mgoDBName).C("contacts")
+	// insert in the reverse order to ensure the authors are ordered as the post refers to
+	contactCol.Insert(photographer, editor, writer)
+	defer contactCol.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{writer.ID, photographer.ID, editor.ID}}})
+
+	postCol := Globs.MgoDB.DB(mgoDBName).C(mgoPostCol)
+	postCol.Insert(post)
+	defer postCol.RemoveId(post.ID)
+
+	topicCol := Globs.MgoDB.DB(mgoDBName).C(mgoTopicCol)
+	topicCol.Insert(topic)
+	defer topicCol.RemoveId(topic.ID)
+
+	getAuthorNames := func(authors []models.Author) []string {
+		names := make([]string, 0)
+		for _, author := range authors {
+			names = append(names, author.Name)
+		}
+		return names
+	}
+
+	// Start -- Meta of the post without the authors //
+	resp := serveHTTP("GET", "/v1/posts/"+post.Slug, "", "", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	body, _ := ioutil.ReadAll(resp.Result().Body)
+	res := postResponse{}
+	json.Unmarshal(body, &res)
+	assert.Empty(t, res.Record.Writters)
+	// End -- Meta of the post without the authors //
+
+	// Start -- Meta of the post with the authors expanded //
+	resp = serveHTTP("GET", "/v1/posts/"+post.Slug+"?expand=authors", "", "", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	body, _ = ioutil.ReadAll(resp.Result().Body)
+	res = postResponse{}
+	json.Unmarshal(body, &res)
+	assert.Equal(t, []string{writer.Name, editor.Name}, getAuthorNames(res.Record.Writters))
+	assert.Equal(t, []string{photographer.Name}, getAuthorNames(res.Record.Photographers))
+	assert.Equal(t, writer.JobTitle, res.Record.Writters[0].JobTitle)
+	assert.Empty(t, res.Record.Designers)
+	// End -- Meta of the post with the authors expanded //
+
+	// Start -- Posts with the authors expanded //
+	resp = serveHTTP("GET", `/v1/posts?where={"slug":"`+post.Slug+`"}&expand=authors`, "", "", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	body, _ = ioutil.ReadAll(resp.Result().Body)
+	postsRes := postsResponse{}
+	json.Unmarshal(body, &postsRes)
+	if assert.Len(t, postsRes.Records, 1) {
+		assert.Equal(t, []string{writer.Name, editor.Name}, getAuthorNames(postsRes.Records[0].Writters))
+	}
+	// End -- Posts with the authors expanded //
+
+	// Start -- Full topic with the authors of the related posts expanded //
+	resp = serveHTTP("GET", "/v1/topics/"+topic.Slug+"?full=true&expand=authors", "", "", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	body, _ = ioutil.ReadAll(resp.Result().Body)
+	topicRes := topicResponse{}
+	json.Unmarshal(body, &topicRes)
+	if assert.Len(t, topicRes.Record.Relateds, 1) {
+		assert.Equal(t, []string{writer.Name, editor.Name}, getAuthorNames(topicRes.Record.Relateds[0].Writters))
+	}
+	// End -- Full topic with the authors of the related posts expanded //
+
+	// Start -- Unknown expand //
+	assert.Equal(t, http.StatusBadRequest, serveHTTP("GET", "/v1/posts/"+post.Slug+"?expand=tags", "", "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serveHTTP("GET", "/v1/topics?expand=tags", "", "", "").Code)
+	// End -- Unknown expand //
+
+	// Start -- Expand the topics not full //
+	assert.Equal(t, http.StatusBadRequest, serveHTTP("GET", "/v1/topics?expand=authors", "", "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serveHTTP("GET", "/v1/topics/"+topic.Slug+"?expand=authors", "", "", "").Code)
+	// End -- Expand the topics not full //
+}
+
+func TestGetPostsState(t *testing.T) {
+	draft := models.Post{ID: bson.NewObjectId(), Slug: "post-state-draft", State: "draft", PublishedDate: time.Now()}
+	postCol := Globs.MgoDB.DB(mgoDBName).C(mgoPostCol)
+	postCol.Insert(draft)
+	defer postCol.RemoveId(draft.ID)
+
+	admin := createUser("state-admin@twreporter.org")
+	defer deleteUser(admin)
+	Globs.GormDB.Model(&admin).Update("privilege", constants.PrivilegeAdmin)
+	user := createUser("state-user@twreporter.org")
+	defer deleteUser(user)
+
+	// Start -- Get drafts by an admin //
+	resp := serveHTTP(http.MethodGet, "/v1/posts?state=draft", "", "", "Bearer "+generateIDToken(admin))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "no-store", resp.Header().Get("Cache-Control"))
+
+	res := postsResponse{}
+	json.Unmarshal(resp.Body.Bytes(), &res)
+	if assert.Len(t, res.Records, 1) {
+		assert.Equal(t, draft.ID, res.Records[0].ID)
+	}
+	// End -- Get drafts by an admin //
+
+	// Start -- Get drafts by a user not admin //
+	resp = serveHTTP(http.MethodGet, "/v1/posts?state=draft", "", "", "Bearer "+generateIDToken(user))
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	// End -- Get drafts by a user not admin //
+
+	// Start -- Get drafts anonymously //
+	resp = serveHTTP(http.MethodGet, "/v1/posts?state=draft", "", "", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	// End -- Get drafts anonymously //
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+func TestRecordReadingHistory(t *testing.T) {
+	user := createUser("reading-history-record@twreporter.org")
+	defer deleteUser(user)
+	defer Globs.GormDB.Where("user_id = ?", user.ID).Delete(models.ReadingHistory{})
+	other := createUser("reading-history-record-other@twreporter.org")
+	defer deleteUser(other)
+
+	published := models.Post{ID: bson.NewObjectId(), Slug: "reading-history-published", State: "published"}
+	draft := models.Post{ID: bson.NewObjectId(), Slug: "reading-history-draft", State: "draft"}
+	col := Globs.MgoDB.DB(mgoDBName).C(mgoPostCol)
+	col.Insert(published, draft)
+	defer col.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{published.ID, draft.ID}}})
+
+	auth := "Bearer " + generateIDToken(user)
+	path := fmt.Sprintf("/v1/users/%d/reading-history", user.ID)
+	countHistory := func() int {
+		var count int
+		Globs.GormDB.Model(&models.ReadingHistory{}).Where("user_id = ?", user.ID).Count(&count)
+		return count
+	}
+
+	t.Run("Given another user", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, path, `{"slug":"reading-history-published"}`, "application/json", "Bearer "+generateIDToken(other))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Equal(t, 0, countHistory())
+	})
+
+	t.Run("Given a draft", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, path, `{"slug":"reading-history-draft"}`, "application/json", auth)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Equal(t, 0, countHistory())
+	})
+
+	t.Run("Given a published post read twice", func(t *testing.T) {
+		var first, last models.ReadingHistory
+
+		resp := serveHTTP(http.MethodPost, path, `{"slug":"reading-history-published"}`, "application/json", auth)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		Globs.GormDB.First(&first, "user_id = ? AND slug = ?", user.ID, published.Slug)
+
+		time.Sleep(time.Second)
+		resp = serveHTTP(http.MethodPost, path, `{"slug":"reading-history-published"}`, "application/json", auth)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		Globs.GormDB.First(&last, "user_id = ? AND slug = ?", user.ID, published.Slug)
+
+		// only the latest read time is kept
+		assert.Equal(t, 1, countHistory())
+		assert.Equal(t, first.ID, last.ID)
+		assert.True(t, last.ReadAt.After(first.ReadAt))
+	})
+}
+
+func TestDeleteReadingHistory(t *testing.T) {
+	user := createUser("reading-history@twreporter.org")
+	defer deleteUser(user)
+	defer Globs.GormDB.Where("user_id = ?", user.ID).Delete(models.ReadingHistory{})
+	other := createUser("reading-history-other@twreporter.org")
+	defer deleteUser(other)
+
+	for _, slug := range []string{"read-post-1", "read-post-2", "read-post-3"} {
+		Globs.GormDB.Create(&models.ReadingHistory{UserID: user.ID, Slug: slug, ReadAt: time.Now()})
+	}
+
+	auth := "Bearer " + generateIDToken(user)
+	path := fmt.Sprintf("/v1/users/%d/reading-history", user.ID)
+	countHistory := func() int {
+		var count int
+		Globs.GormDB.Model(&models.ReadingHistory{}).Where("user_id = ?", user.ID).Count(&count)
+		return count
+	}
+
+	t.Run("Given another user", func(t *testing.T) {
+		resp := serveHTTP(http.MethodDelete, path, "", "", "Bearer "+generateIDToken(other))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Equal(t, 3, countHistory())
+	})
+
+	t.Run("Given a post in the reading history", func(t *testing.T) {
+		resp := serveHTTP(http.MethodDelete, path+"/read-post-1", "", "", auth)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Equal(t, 2, countHistory())
+
+		resp = serveHTTP(http.MethodDelete, path+"/read-post-1", "", "", auth)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Given the reading history cleared", func(t *testing.T) {
+		resp := serveHTTP(http.MethodDelete, path, "", "", auth)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Equal(t, 0, countHistory())
+
+		// clearing the empty history succeeds as well
+		resp = serveHTTP(http.MethodDelete, path, "", "", auth)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+	})
+}
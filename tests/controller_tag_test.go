@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+
+	"twreporter.org/go-api/models"
+)
+
+type tagCountsResponse struct {
+	Status  string            `json:"status"`
+	Records []models.TagCount `json:"records"`
+}
+
+func TestGetTopicsOfTags(t *testing.T) {
+	now := time.Now()
+	election := models.Tag{ID: bson.NewObjectId(), Slug: "tag-topics-election", Name: "election"}
+	taiwan := models.Tag{ID: bson.NewObjectId(), Slug: "tag-topics-taiwan", Name: "taiwan"}
+
+	both := models.Topic{ID: bson.NewObjectId(), Slug: "tag-topics-both", State: "published", PublishedDate: now, TagsOrigin: []bson.ObjectId{election.ID, taiwan.ID}}
+	electionOnly := models.Topic{ID: bson.NewObjectId(), Slug: "tag-topics-election-only", State: "published", PublishedDate: now.Add(-time.Hour), TagsOrigin: []bson.ObjectId{election.ID}}
+	noTags := models.Topic{ID: bson.NewObjectId(), Slug: "tag-topics-no-tags", State: "published", PublishedDate: now.Add(-2 * time.Hour)}
+
+	tagCol := Globs.MgoDB.DB(mgoDBName).C(mgoTagCol)
+	tagCol.Insert(election, taiwan)
+	defer tagCol.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{election.ID, taiwan.ID}}})
+
+	topicCol := Globs.MgoDB.DB(mgoDBName).C(mgoTopicCol)
+	topicCol.Insert(both, electionOnly, noTags)
+	defer topicCol.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{both.ID, electionOnly.ID, noTags.ID}}})
+
+	getTopics := func(path string) []string {
+		resp := serveHTTP("GET", path, "", "", "")
+		assert.Equal(t, resp.Code, 200)
+
+		body, _ := ioutil.ReadAll(resp.Result().Body)
+		res := topicsResponse{}
+		json.Unmarshal(body, &res)
+
+		slugs := make([]string, 0)
+		for _, topic := range res.Records {
+			slugs = append(slugs, topic.Slug)
+		}
+		return slugs
+	}
+
+	// Start -- Topics of a tag //
+	assert.Equal(t, []string{both.Slug, electionOnly.Slug}, getTopics("/v1/tags/"+election.Slug+"/topics"))
+	assert.Equal(t, []string{both.Slug}, getTopics("/v1/tags/"+taiwan.Slug+"/topics"))
+	// End -- Topics of a tag //
+
+	// Start -- Topics of any of the tags //
+	assert.Equal(t, []string{both.Slug, electionOnly.Slug}, getTopics("/v1/tags/"+election.Slug+","+taiwan.Slug+"/topics"))
+	assert.Equal(t, []string{both.Slug, electionOnly.Slug}, getTopics("/v1/tags/"+election.Slug+",unknown-tag/topics?match=any"))
+	// End -- Topics of any of the tags //
+
+	// Start -- Topics of all of the tags //
+	assert.Equal(t, []string{both.Slug}, getTopics("/v1/tags/"+election.Slug+","+taiwan.Slug+"/topics?match=all"))
+	assert.Equal(t, []string{}, getTopics("/v1/tags/"+election.Slug+",unknown-tag/topics?match=all"))
+	// End -- Topics of all of the tags //
+
+	// Start -- Topics of the unknown tag //
+	assert.Equal(t, []string{}, getTopics("/v1/tags/unknown-tag/topics"))
+	// End -- Topics of the unknown tag //
+
+	// Start -- Invalid match //
+	resp := serveHTTP("GET", "/v1/tags/"+election.Slug+"/topics?match=some", "", "", "")
+	assert.Equal(t, resp.Code, 400)
+	// End -- Invalid match //
+
+	// Start -- Tags with the topic counts //
+	resp = serveHTTP("GET", "/v1/tags", "", "", "")
+	assert.Equal(t, resp.Code, 200)
+
+	body, _ := ioutil.ReadAll(resp.Result().Body)
+	res := tagCountsResponse{}
+	json.Unmarshal(body, &res)
+
+	counts := make(map[string]int)
+	for _, tag := range res.Records {
+		counts[tag.Slug] = tag.TopicCount
+	}
+	assert.Equal(t, 2, counts[election.Slug])
+	assert.Equal(t, 1, counts[taiwan.Slug])
+	// End -- Tags with the topic counts //
+}
@@ -2,12 +2,18 @@ package tests
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"twreporter.org/go-api/configs/constants"
 	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
 )
 
 /*
@@ -120,3 +126,347 @@ func TestGetTopics(t *testing.T) {
 	assert.Equal(t, len(res.Records), 0)
 	// End -- Get the topics with slug=mock-topic-slug//
 }
+
+func TestGetTopicsUpdatedSince(t *testing.T) {
+	cutoff := time.Date(2020, time.June, 8, 16, 0, 0, 0, time.UTC)
+
+	before := models.Topic{ID: bson.NewObjectId(), Slug: "topic-updated-before", State: "published", UpdatedAt: cutoff.Add(-time.Hour)}
+	atCutoff := models.Topic{ID: bson.NewObjectId(), Slug: "topic-updated-at-cutoff", State: "published", UpdatedAt: cutoff}
+	after1 := models.Topic{ID: bson.NewObjectId(), Slug: "topic-updated-after-1", State: "published", UpdatedAt: cutoff.Add(time.Minute)}
+	// topics updated at the same time are ordered by id
+	after2 := models.Topic{ID: bson.NewObjectId(), Slug: "topic-updated-after-2", State: "published", UpdatedAt: cutoff.Add(2 * time.Minute)}
+	after3 := models.Topic{ID: bson.NewObjectId(), Slug: "topic-updated-after-3", State: "published", UpdatedAt: cutoff.Add(2 * time.Minute)}
+
+	col := Globs.MgoDB.DB(mgoDBName).C(mgoTopicCol)
+	// insert in the reverse order to ensure the ordering does not depend on insertion
+	for _, topic := range []models.Topic{after3, after2, after1, atCutoff, before} {
+		col.Insert(topic)
+	}
+	defer col.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{before.ID, atCutoff.ID, after1.ID, after2.ID, after3.ID}}})
+
+	getSlugs := func(path string) []string {
+		resp := serveHTTP("GET", path, "", "", "")
+		assert.Equal(t, resp.Code, 200)
+
+		body, _ := ioutil.ReadAll(resp.Result().Body)
+		res := topicsResponse{}
+		json.Unmarshal(body, &res)
+
+		slugs := make([]string, 0)
+		for _, topic := range res.Records {
+			slugs = append(slugs, topic.Slug)
+		}
+		return slugs
+	}
+
+	since := cutoff.Format(time.RFC3339)
+
+	// Start -- Get the topics updated after the cutoff //
+	assert.Equal(t, []string{after1.Slug, after2.Slug, after3.Slug}, getSlugs("/v1/topics?updatedSince="+since))
+	// End -- Get the topics updated after the cutoff //
+
+	// Start -- Page through the topics with stable ordering //
+	assert.Equal(t, []string{after1.Slug, after2.Slug}, getSlugs(fmt.Sprintf("/v1/topics?updatedSince=%s&limit=2&offset=0", since)))
+	assert.Equal(t, []string{after3.Slug}, getSlugs(fmt.Sprintf("/v1/topics?updatedSince=%s&limit=2&offset=2", since)))
+	// End -- Page through the topics with stable ordering //
+
+	// Start -- Get the topics updated after the last one //
+	assert.Equal(t, []string{}, getSlugs("/v1/topics?updatedSince="+after3.UpdatedAt.Format(time.RFC3339)))
+	// End -- Get the topics updated after the last one //
+
+	// Start -- Get topics with invalid timestamp //
+	resp := serveHTTP("GET", "/v1/topics?updatedSince=yesterday", "", "", "")
+	assert.Equal(t, resp.Code, 400)
+	// End -- Get topics with invalid timestamp //
+}
+
+func TestGetTopicsLastModified(t *testing.T) {
+	updatedAt := time.Date(2020, time.June, 9, 16, 0, 0, 0, time.UTC)
+	topic := models.Topic{ID: bson.NewObjectId(), Slug: "topic-last-modified", State: "published", UpdatedAt: updatedAt}
+
+	col := Globs.MgoDB.DB(mgoDBName).C(mgoTopicCol)
+	col.Insert(topic)
+	defer col.RemoveId(topic.ID)
+
+	path := "/v1/topics?updatedSince=" + updatedAt.Add(-time.Second).Format(time.RFC3339)
+	serveConditionalHTTP := func(ifModifiedSince time.Time) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("If-Modified-Since", ifModifiedSince.Format(http.TimeFormat))
+		resp := httptest.NewRecorder()
+		Globs.GinEngine.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Start -- Not modified since the client cached //
+	resp := serveConditionalHTTP(updatedAt)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.String())
+	// End -- Not modified since the client cached //
+
+	// Start -- Modified after the client cached //
+	resp = serveConditionalHTTP(updatedAt.Add(-time.Hour))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, updatedAt.Format(http.TimeFormat), resp.Header().Get("Last-Modified"))
+	// End -- Modified after the client cached //
+}
+
+func TestGetTrendingTopics(t *testing.T) {
+	popular := models.Topic{ID: bson.NewObjectId(), Slug: "topic-trending-popular", State: "published"}
+	unpopular := models.Topic{ID: bson.NewObjectId(), Slug: "topic-trending-unpopular", State: "published"}
+
+	col := Globs.MgoDB.DB(mgoDBName).C(mgoTopicCol)
+	col.Insert(unpopular, popular)
+	defer col.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{popular.ID, unpopular.ID}}})
+
+	viewCol := Globs.MgoDB.DB(mgoDBName).C("topic_views")
+	defer viewCol.RemoveAll(bson.M{"slug": bson.M{"$in": []string{popular.Slug, unpopular.Slug}}})
+
+	ms := storage.NewMongoStorage(Globs.MgoDB)
+	view := func(slug string, times int) {
+		for i := 0; i < times; i++ {
+			resp := serveHTTP("GET", "/v1/topics/"+slug, "", "", "")
+			assert.Equal(t, resp.Code, 200)
+		}
+		assert.Nil(t, ms.FlushTopicViews())
+	}
+
+	getTrending := func() []string {
+		resp := serveHTTP("GET", "/v1/topics/trending?window=24h", "", "", "")
+		assert.Equal(t, resp.Code, 200)
+
+		body, _ := ioutil.ReadAll(resp.Result().Body)
+		res := topicsResponse{}
+		json.Unmarshal(body, &res)
+
+		slugs := make([]string, 0)
+		for _, topic := range res.Records {
+			slugs = append(slugs, topic.Slug)
+		}
+		return slugs
+	}
+
+	// Start -- Views accumulate across flushes //
+	view(popular.Slug, 2)
+	view(popular.Slug, 1)
+	view(unpopular.Slug, 2)
+
+	var count struct {
+		Count int `bson:"count"`
+	}
+	viewCol.Find(bson.M{"slug": popular.Slug}).One(&count)
+	assert.Equal(t, 3, count.Count)
+	// End -- Views accumulate across flushes //
+
+	// Start -- Trending ordering reflects view counts //
+	assert.Equal(t, []string{popular.Slug, unpopular.Slug}, getTrending())
+
+	view(unpopular.Slug, 2)
+	assert.Equal(t, []string{unpopular.Slug, popular.Slug}, getTrending())
+	// End -- Trending ordering reflects view counts //
+
+	// Start -- Get trending topics with invalid window //
+	resp := serveHTTP("GET", "/v1/topics/trending?window=365d", "", "", "")
+	assert.Equal(t, resp.Code, 400)
+	// End -- Get trending topics with invalid window //
+}
+
+func TestGetATopicWithPosts(t *testing.T) {
+	type topicWithPostsResponse struct {
+		Status string        `json:"status"`
+		Record models.Topic  `json:"record"`
+		Posts  []models.Post `json:"posts"`
+		Meta   struct {
+			PostTotal  int `json:"postTotal"`
+			PostOffset int `json:"postOffset"`
+			PostLimit  int `json:"postLimit"`
+		} `json:"meta"`
+	}
+
+	getTopicWithPosts := func(path string) topicWithPostsResponse {
+		resp := serveHTTP("GET", path, "", "", "")
+		assert.Equal(t, resp.Code, 200)
+
+		body, _ := ioutil.ReadAll(resp.Result().Body)
+		res := topicWithPostsResponse{}
+		json.Unmarshal(body, &res)
+		return res
+	}
+
+	// Start -- Topic not found //
+	resp := serveHTTP("GET", "/v1/topics/topic-not-found?withPosts=true", "", "", "")
+	assert.Equal(t, resp.Code, 404)
+	// End -- Topic not found //
+
+	// Start -- Topic with posts //
+	res := getTopicWithPosts("/v1/topics/" + Globs.Defaults.MockTopicSlug + "?withPosts=true")
+	assert.Equal(t, Globs.Defaults.TopicID, res.Record.ID)
+	assert.Equal(t, 2, res.Meta.PostTotal)
+	if assert.Equal(t, 2, len(res.Posts)) {
+		// the posts are sorted by publishedDate descendingly
+		assert.Equal(t, Globs.Defaults.PostID2, res.Posts[0].ID)
+		assert.Equal(t, Globs.Defaults.PostID1, res.Posts[1].ID)
+	}
+	// End -- Topic with posts //
+
+	// Start -- Paginate the posts of the topic //
+	res = getTopicWithPosts("/v1/topics/" + Globs.Defaults.MockTopicSlug + "?withPosts=true&postLimit=1&postOffset=1")
+	assert.Equal(t, 2, res.Meta.PostTotal)
+	assert.Equal(t, 1, res.Meta.PostLimit)
+	assert.Equal(t, 1, res.Meta.PostOffset)
+	if assert.Equal(t, 1, len(res.Posts)) {
+		assert.Equal(t, Globs.Defaults.PostID1, res.Posts[0].ID)
+	}
+	// End -- Paginate the posts of the topic //
+
+	// Start -- Topic without posts //
+	topic := models.Topic{ID: bson.NewObjectId(), Slug: "topic-without-posts", State: "published"}
+	col := Globs.MgoDB.DB(mgoDBName).C(mgoTopicCol)
+	col.Insert(topic)
+	defer col.RemoveId(topic.ID)
+
+	resp = serveHTTP("GET", "/v1/topics/"+topic.Slug+"?withPosts=true", "", "", "")
+	assert.Equal(t, resp.Code, 200)
+	body, _ := ioutil.ReadAll(resp.Result().Body)
+	assert.Contains(t, string(body), `"posts":[]`)
+	// End -- Topic without posts //
+}
+
+func TestGetTopicsPostCount(t *testing.T) {
+	topic := models.Topic{ID: bson.NewObjectId(), Slug: "topic-post-count", State: "published"}
+	empty := models.Topic{ID: bson.NewObjectId(), Slug: "topic-post-count-empty", State: "published"}
+	post1 := models.Post{ID: bson.NewObjectId(), Slug: "topic-post-count-post-1", State: "published", TopicOrigin: topic.ID}
+	post2 := models.Post{ID: bson.NewObjectId(), Slug: "topic-post-count-post-2", State: "published", TopicOrigin: topic.ID}
+
+	topicCol := Globs.MgoDB.DB(mgoDBName).C(mgoTopicCol)
+	topicCol.Insert(topic, empty)
+	defer topicCol.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{topic.ID, empty.ID}}})
+
+	postCol := Globs.MgoDB.DB(mgoDBName).C(mgoPostCol)
+	postCol.Insert(post1)
+	defer postCol.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{post1.ID, post2.ID}}})
+
+	getPostCounts := func() map[string]int {
+		counts := make(map[string]int)
+		for _, slug := range []string{topic.Slug, empty.Slug} {
+			resp := serveHTTP("GET", `/v1/topics?where={"slug":"`+slug+`"}`, "", "", "")
+			assert.Equal(t, resp.Code, 200)
+
+			body, _ := ioutil.ReadAll(resp.Result().Body)
+			res := topicsResponse{}
+			json.Unmarshal(body, &res)
+			if assert.Equal(t, 1, len(res.Records)) {
+				counts[slug] = res.Records[0].PostCount
+			}
+		}
+		return counts
+	}
+
+	// Start -- Post count of the topics //
+	assert.Equal(t, map[string]int{topic.Slug: 1, empty.Slug: 0}, getPostCounts())
+	// End -- Post count of the topics //
+
+	// Start -- Post count after a post is added to the topic //
+	postCol.Insert(post2)
+	assert.Equal(t, map[string]int{topic.Slug: 2, empty.Slug: 0}, getPostCounts())
+	// End -- Post count after a post is added to the topic //
+
+	// Start -- Post count after a post is removed from the topic //
+	postCol.UpdateId(post1.ID, bson.M{"$unset": bson.M{"topics": ""}})
+	assert.Equal(t, map[string]int{topic.Slug: 1, empty.Slug: 0}, getPostCounts())
+	// End -- Post count after a post is removed from the topic //
+}
+
+func TestGetRelatedTopics(t *testing.T) {
+	tag1, tag2, tag3 := bson.NewObjectId(), bson.NewObjectId(), bson.NewObjectId()
+	now := time.Now()
+
+	source := models.Topic{ID: bson.NewObjectId(), Slug: "topic-related-source", State: "published", TagsOrigin: []bson.ObjectId{tag1, tag2}}
+	shareBoth := models.Topic{ID: bson.NewObjectId(), Slug: "topic-related-share-both", State: "published", PublishedDate: now.Add(-time.Hour), TagsOrigin: []bson.ObjectId{tag1, tag2, tag3}}
+	shareOneNewer := models.Topic{ID: bson.NewObjectId(), Slug: "topic-related-share-one-newer", State: "published", PublishedDate: now, TagsOrigin: []bson.ObjectId{tag2}}
+	shareOneOlder := models.Topic{ID: bson.NewObjectId(), Slug: "topic-related-share-one-older", State: "published", PublishedDate: now.Add(-2 * time.Hour), TagsOrigin: []bson.ObjectId{tag1}}
+	unrelated := models.Topic{ID: bson.NewObjectId(), Slug: "topic-related-unrelated", State: "published", TagsOrigin: []bson.ObjectId{tag3}}
+
+	col := Globs.MgoDB.DB(mgoDBName).C(mgoTopicCol)
+	col.Insert(source, shareBoth, shareOneNewer, shareOneOlder, unrelated)
+	defer col.RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{source.ID, shareBoth.ID, shareOneNewer.ID, shareOneOlder.ID, unrelated.ID}}})
+
+	getRelated := func(path string) []string {
+		resp := serveHTTP("GET", path, "", "", "")
+		assert.Equal(t, resp.Code, 200)
+
+		body, _ := ioutil.ReadAll(resp.Result().Body)
+		res := topicsResponse{}
+		json.Unmarshal(body, &res)
+
+		slugs := make([]string, 0)
+		for _, topic := range res.Records {
+			slugs = append(slugs, topic.Slug)
+		}
+		return slugs
+	}
+
+	// Start -- Related topics ranked by shared tags and published date //
+	assert.Equal(t, []string{shareBoth.Slug, shareOneNewer.Slug, shareOneOlder.Slug}, getRelated("/v1/topics/"+source.Slug+"/related"))
+	// End -- Related topics ranked by shared tags and published date //
+
+	// Start -- Related topics limited //
+	assert.Equal(t, []string{shareBoth.Slug}, getRelated("/v1/topics/"+source.Slug+"/related?limit=1"))
+	// End -- Related topics limited //
+
+	// Start -- Related topics of the topic without tags //
+	assert.Equal(t, []string{}, getRelated("/v1/topics/"+Globs.Defaults.MockTopicSlug+"/related"))
+	// End -- Related topics of the topic without tags //
+
+	// Start -- Related topics of the topic not found //
+	resp := serveHTTP("GET", "/v1/topics/non-existing-topic/related", "", "", "")
+	assert.Equal(t, resp.Code, 404)
+	// End -- Related topics of the topic not found //
+}
+
+func TestReorderPostsOfTopic(t *testing.T) {
+	admin := createUser("reorder-admin@twreporter.org")
+	defer deleteUser(admin)
+	Globs.GormDB.Model(&admin).Update("privilege", constants.PrivilegeAdmin)
+	authorization := "Bearer " + generateIDToken(admin)
+
+	topic := models.Topic{ID: bson.NewObjectId(), Slug: "topic-to-reorder", State: "published", RelatedsOrigin: []bson.ObjectId{Globs.Defaults.PostID1, Globs.Defaults.PostID2}}
+	col := Globs.MgoDB.DB(mgoDBName).C(mgoTopicCol)
+	col.Insert(topic)
+	defer col.RemoveId(topic.ID)
+
+	path := "/v1/admin/topics/" + topic.Slug + "/posts"
+	getRelateds := func() []bson.ObjectId {
+		var found models.Topic
+		col.FindId(topic.ID).One(&found)
+		return found.RelatedsOrigin
+	}
+
+	// Start -- Reorder the posts of the topic //
+	resp := serveHTTP(http.MethodPatch, path, fmt.Sprintf(`{"order": ["%s", "%s"]}`, Globs.Defaults.PostCol2.Slug, Globs.Defaults.MockPostSlug1), "application/json", authorization)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []bson.ObjectId{Globs.Defaults.PostID2, Globs.Defaults.PostID1}, getRelateds())
+	// End -- Reorder the posts of the topic //
+
+	// Start -- Reorder with the posts not matched //
+	resp = serveHTTP(http.MethodPatch, path, fmt.Sprintf(`{"order": ["%s"]}`, Globs.Defaults.MockPostSlug1), "application/json", authorization)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, []bson.ObjectId{Globs.Defaults.PostID2, Globs.Defaults.PostID1}, getRelateds())
+	// End -- Reorder with the posts not matched //
+
+	// Start -- Reorder the posts of a topic not existing //
+	resp = serveHTTP(http.MethodPatch, "/v1/admin/topics/topic-not-found/posts", `{"order": []}`, "application/json", authorization)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	// End -- Reorder the posts of a topic not existing //
+
+	// Start -- Reorder the posts of a topic without posts //
+	emptyTopic := models.Topic{ID: bson.NewObjectId(), Slug: "topic-without-posts", State: "published"}
+	col.Insert(emptyTopic)
+	defer col.RemoveId(emptyTopic.ID)
+
+	resp = serveHTTP(http.MethodPatch, "/v1/admin/topics/"+emptyTopic.Slug+"/posts", `{"order": []}`, "application/json", authorization)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = serveHTTP(http.MethodPatch, "/v1/admin/topics/"+emptyTopic.Slug+"/posts", fmt.Sprintf(`{"order": ["%s"]}`, Globs.Defaults.MockPostSlug1), "application/json", authorization)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	// End -- Reorder the posts of a topic without posts //
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/models"
+)
+
+type adminUserListResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Records []map[string]interface{} `json:"records"`
+		Meta    struct {
+			Total  int `json:"total"`
+			Offset int `json:"offset"`
+			Limit  int `json:"limit"`
+		} `json:"meta"`
+	} `json:"data"`
+}
+
+func TestListUsers(t *testing.T) {
+	admin := createUser("list-users-admin@twreporter.org")
+	defer deleteUser(admin)
+	Globs.GormDB.Model(&admin).Update("privilege", constants.PrivilegeAdmin)
+	adminAuth := "Bearer " + generateIDToken(admin)
+
+	first := createUser("list_users-1@twreporter.org")
+	defer deleteUser(first)
+	second := createUser("list_users-2@twreporter.org")
+	defer deleteUser(second)
+	// `_` is matched literally rather than as the wildcard of LIKE
+	other := createUser("listXusers-3@twreporter.org")
+	defer deleteUser(other)
+
+	t.Run("Given a non-admin", func(t *testing.T) {
+		resp := serveHTTP(http.MethodGet, "/v1/admin/users", "", "", "Bearer "+generateIDToken(first))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Given an invalid orderBy", func(t *testing.T) {
+		resp := serveHTTP(http.MethodGet, "/v1/admin/users?orderBy=password", "", "", adminAuth)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Given an email prefix", func(t *testing.T) {
+		var res adminUserListResponse
+
+		resp := serveHTTP(http.MethodGet, "/v1/admin/users?email=list_users-&orderBy=email&limit=1&offset=1", "", "", adminAuth)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		assert.Equal(t, "success", res.Status)
+		assert.Equal(t, 2, res.Data.Meta.Total)
+		assert.Equal(t, 1, res.Data.Meta.Limit)
+		if assert.Len(t, res.Data.Records, 1) {
+			assert.Equal(t, second.Email.String, res.Data.Records[0]["email"])
+			assert.NotContains(t, res.Data.Records[0], "ReporterAccount")
+			assert.NotContains(t, res.Data.Records[0], "OAuthAccounts")
+		}
+	})
+}
+
+func TestBlockUser(t *testing.T) {
+	admin := createUser("block-user-admin@twreporter.org")
+	defer deleteUser(admin)
+	Globs.GormDB.Model(&admin).Update("privilege", constants.PrivilegeAdmin)
+	adminAuth := "Bearer " + generateIDToken(admin)
+
+	user := createUser("block-user@twreporter.org")
+	defer deleteUser(user)
+	defer Globs.GormDB.Unscoped().Where("user_id = ?", user.ID).Delete(models.UserBlockLog{})
+	idToken := http.Cookie{Name: "id_token", Value: generateIDToken(user)}
+	path := fmt.Sprintf("/v1/admin/users/%d/block", user.ID)
+
+	t.Run("Given a non-admin", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, path, `{"reason":"spam"}`, "application/json", "Bearer "+generateIDToken(user))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Given no reason", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, path, `{}`, "application/json", adminAuth)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Given the admin self", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, fmt.Sprintf("/v1/admin/users/%d/block", admin.ID), `{"reason":"spam"}`, "application/json", adminAuth)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Given an unknown user", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, "/v1/admin/users/999999/block", `{"reason":"spam"}`, "application/json", adminAuth)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Given a user blocked and unblocked", func(t *testing.T) {
+		var logs []models.UserBlockLog
+
+		resp := serveHTTP(http.MethodPost, path, `{"reason":"spam comments"}`, "application/json", adminAuth)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		blocked := getUser(user.Email.String)
+		assert.True(t, blocked.Blocked)
+		assert.Equal(t, "spam comments", blocked.BlockReason.String)
+
+		// the blocked user cannot get the access token
+		resp = serveHTTPWithCookies(http.MethodPost, "/v2/auth/token", "", "", "", idToken)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), models.ErrCodeAccountBlocked)
+
+		resp = serveHTTP(http.MethodDelete, path, "", "", adminAuth)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.False(t, getUser(user.Email.String).Blocked)
+
+		resp = serveHTTPWithCookies(http.MethodPost, "/v2/auth/token", "", "", "", idToken)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		Globs.GormDB.Where("user_id = ?", user.ID).Order("id").Find(&logs)
+		if assert.Len(t, logs, 2) {
+			assert.Equal(t, models.UserBlockActionBlock, logs[0].Action)
+			assert.Equal(t, "spam comments", logs[0].Reason.String)
+			assert.Equal(t, admin.ID, logs[0].AdminID)
+			assert.Equal(t, models.UserBlockActionUnblock, logs[1].Action)
+			assert.False(t, logs[1].Reason.Valid)
+		}
+	})
+}
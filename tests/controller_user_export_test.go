@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+func TestExportUserData(t *testing.T) {
+	as := storage.NewGormStorage(Globs.GormDB)
+
+	user := createUser("export-user@twreporter.org")
+	other := createUser("export-other@twreporter.org")
+	defer deleteUser(user)
+	defer deleteUser(other)
+
+	linkOAuthAccount(user, globals.GoogleOAuth, "google-aid-export", "export@gmail.com", "Export")
+	defer Globs.GormDB.Unscoped().Where("user_id = ?", user.ID).Delete(models.OAuthAccount{})
+
+	bookmark, _ := as.CreateABookmarkOfAUser(fmt.Sprint(user.ID), models.Bookmark{Slug: "export-slug", Host: "mockhost", Title: "mocktitle", Thumbnail: "mockthumb"})
+	defer Globs.GormDB.Unscoped().Delete(bookmark)
+	defer as.DeleteBookmarksOfAUser(fmt.Sprint(user.ID))
+
+	wpSub := models.WebPushSubscription{Endpoint: "https://push.example.com/export", Keys: "secret-push-keys"}
+	wpSub.SetUserID(user.ID)
+	as.CreateAWebPushSubscription(wpSub)
+	defer Globs.GormDB.Unscoped().Where("user_id = ?", user.ID).Delete(models.WebPushSubscription{})
+
+	Globs.GormDB.Create(&models.DeviceToken{UserID: user.ID, Token: "secret-device-token", Platform: models.DeviceTokenPlatformIOS})
+	defer Globs.GormDB.Where("user_id = ?", user.ID).Delete(models.DeviceToken{})
+	Globs.GormDB.Create(&models.CategorySubscription{UserID: user.ID, CategoryID: "5edf118c3e631f0600198935"})
+	defer Globs.GormDB.Where("user_id = ?", user.ID).Delete(models.CategorySubscription{})
+	Globs.GormDB.Create(&models.ReadingHistory{UserID: user.ID, Slug: "export-read-slug", ReadAt: time.Now()})
+	defer Globs.GormDB.Where("user_id = ?", user.ID).Delete(models.ReadingHistory{})
+	Globs.GormDB.Create(&models.Subscription{UserID: user.ID, Plan: "monthly", StartDate: time.Now(), Status: models.SubscriptionStatusActive})
+	defer Globs.GormDB.Unscoped().Where("user_id = ?", user.ID).Delete(models.Subscription{})
+
+	path := fmt.Sprintf("/v1/users/%d/export", user.ID)
+
+	t.Run("Given the user", func(t *testing.T) {
+		var res struct {
+			Profile       map[string]interface{}   `json:"profile"`
+			OAuthAccounts []map[string]interface{} `json:"oauth_accounts"`
+			Bookmarks     []models.Bookmark        `json:"bookmarks"`
+			Subscriptions []map[string]interface{} `json:"subscriptions"`
+			DeviceTokens  []map[string]interface{} `json:"device_tokens"`
+			CategorySubs  []map[string]interface{} `json:"category_subscriptions"`
+			Histories     []map[string]interface{} `json:"reading_history"`
+			EmailAccounts []map[string]interface{} `json:"email_accounts"`
+			PlanSubs      []map[string]interface{} `json:"plan_subscriptions"`
+		}
+
+		resp := serveHTTP(http.MethodGet, path, "", "", "Bearer "+generateIDToken(user))
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		body := resp.Body.String()
+		if assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &res), body) {
+			assert.Equal(t, "export-user@twreporter.org", res.Profile["email"])
+			if assert.Len(t, res.OAuthAccounts, 1) {
+				assert.Equal(t, "export@gmail.com", res.OAuthAccounts[0]["email"])
+				assert.Contains(t, res.OAuthAccounts[0], "last_profile_refresh_at")
+			}
+			if assert.Len(t, res.EmailAccounts, 1) {
+				assert.Equal(t, "export-user@twreporter.org", res.EmailAccounts[0]["email"])
+			}
+			if assert.Len(t, res.Bookmarks, 1) {
+				assert.Equal(t, "export-slug", res.Bookmarks[0].Slug)
+			}
+			if assert.Len(t, res.Subscriptions, 1) {
+				assert.Equal(t, "https://push.example.com/export", res.Subscriptions[0]["endpoint"])
+			}
+			if assert.Len(t, res.DeviceTokens, 1) {
+				assert.Equal(t, models.DeviceTokenPlatformIOS, res.DeviceTokens[0]["platform"])
+			}
+			if assert.Len(t, res.CategorySubs, 1) {
+				assert.Equal(t, "5edf118c3e631f0600198935", res.CategorySubs[0]["category_id"])
+			}
+			if assert.Len(t, res.Histories, 1) {
+				assert.Equal(t, "export-read-slug", res.Histories[0]["slug"])
+			}
+			if assert.Len(t, res.PlanSubs, 1) {
+				assert.Equal(t, "monthly", res.PlanSubs[0]["plan"])
+			}
+			assert.NotContains(t, body, "login_history")
+		}
+
+		// secrets are excluded
+		assert.NotContains(t, body, "google-aid-export")
+		assert.NotContains(t, body, "secret-push-keys")
+		assert.NotContains(t, body, "secret-device-token")
+		assert.NotContains(t, body, Globs.Defaults.Token)
+	})
+
+	t.Run("Given another user", func(t *testing.T) {
+		resp := serveHTTP(http.MethodGet, path, "", "", "Bearer "+generateIDToken(other))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Given no authorization", func(t *testing.T) {
+		resp := serveHTTP(http.MethodGet, path, "", "", "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Given an admin", func(t *testing.T) {
+		admin := createUser("export-admin@twreporter.org")
+		defer deleteUser(admin)
+		Globs.GormDB.Model(&admin).Update("privilege", constants.PrivilegeAdmin)
+
+		resp := serveHTTP(http.MethodGet, path, "", "", "Bearer "+generateIDToken(admin))
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+}
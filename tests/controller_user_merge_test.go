@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/globals"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type userMergeResponse struct {
+	Status string                 `json:"status"`
+	Data   models.UserMergeResult `json:"data"`
+}
+
+func TestMergeUsers(t *testing.T) {
+	as := storage.NewGormStorage(Globs.GormDB)
+
+	admin := createUser("merge-admin@twreporter.org")
+	defer deleteUser(admin)
+	Globs.GormDB.Model(&admin).Update("privilege", constants.PrivilegeAdmin)
+	adminAuth := "Bearer " + generateIDToken(admin)
+
+	t.Run("Given a non-admin", func(t *testing.T) {
+		user := createUser("merge-non-admin@twreporter.org")
+		defer deleteUser(user)
+
+		resp := serveHTTP(http.MethodPost, fmt.Sprintf("/v1/admin/users/%d/merge", user.ID), fmt.Sprintf(`{"target_user_id":%d}`, admin.ID), "application/json", "Bearer "+generateIDToken(user))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Given an invalid target", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, fmt.Sprintf("/v1/admin/users/%d/merge", admin.ID), `{}`, "application/json", adminAuth)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = serveHTTP(http.MethodPost, fmt.Sprintf("/v1/admin/users/%d/merge", admin.ID), fmt.Sprintf(`{"target_user_id":%d}`, admin.ID), "application/json", adminAuth)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		// the zero-padded id refers to the same user
+		resp = serveHTTP(http.MethodPost, fmt.Sprintf("/v1/admin/users/0%d/merge", admin.ID), fmt.Sprintf(`{"target_user_id":%d}`, admin.ID), "application/json", adminAuth)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		_, err := as.GetUserByID(fmt.Sprint(admin.ID))
+		assert.Nil(t, err)
+
+		resp = serveHTTP(http.MethodPost, "/v1/admin/users/999999/merge", fmt.Sprintf(`{"target_user_id":%d}`, admin.ID), "application/json", adminAuth)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Given a clean merge", func(t *testing.T) {
+		var res userMergeResponse
+
+		source := createUser("merge-clean-source@twreporter.org")
+		target := createUser("merge-clean-target@twreporter.org")
+		defer deleteUser(target)
+		defer deleteUser(source)
+		defer Globs.GormDB.Unscoped().Where("user_id IN (?)", []uint{source.ID, target.ID}).Delete(models.OAuthAccount{})
+		linkOAuthAccount(source, globals.FacebookOAuth, "facebook-aid-merge-clean", "merge-clean@facebook.com", "Source")
+		linkOAuthAccount(target, globals.GoogleOAuth, "google-aid-merge-clean", "merge-clean@gmail.com", "Target")
+		as.CreateABookmarkOfAUser(fmt.Sprint(source.ID), models.Bookmark{Slug: "merge-clean-slug", Host: "mockhost", Title: "mocktitle", Thumbnail: "mockthumb"})
+		defer Globs.GormDB.Unscoped().Where("slug = ?", "merge-clean-slug").Delete(models.Bookmark{})
+
+		resp := serveHTTP(http.MethodPost, fmt.Sprintf("/v1/admin/users/%d/merge", source.ID), fmt.Sprintf(`{"target_user_id":%d}`, target.ID), "application/json", adminAuth)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		assert.Equal(t, "success", res.Status)
+		assert.Equal(t, target.ID, res.Data.UserID)
+		assert.Equal(t, source.ID, res.Data.MergedUserID)
+		assert.Equal(t, []string{globals.FacebookOAuth}, res.Data.MovedOAuthAccounts)
+		assert.Empty(t, res.Data.DroppedOAuthAccounts)
+		assert.Equal(t, 1, res.Data.MovedBookmarks)
+
+		var types []string
+		Globs.GormDB.Model(&models.OAuthAccount{}).Where("user_id = ?", target.ID).Order("type").Pluck("type", &types)
+		assert.Equal(t, []string{globals.FacebookOAuth, globals.GoogleOAuth}, types)
+
+		_, total, _ := as.GetBookmarksOfAUser(fmt.Sprint(target.ID), 10, 0)
+		assert.Equal(t, 1, total)
+
+		_, err := as.GetUserByID(fmt.Sprint(source.ID))
+		assert.True(t, storage.IsNotFound(err))
+	})
+
+	t.Run("Given a provider linked on both users", func(t *testing.T) {
+		var res userMergeResponse
+
+		source := createUser("merge-conflict-source@twreporter.org")
+		target := createUser("merge-conflict-target@twreporter.org")
+		defer deleteUser(target)
+		defer deleteUser(source)
+		defer Globs.GormDB.Unscoped().Where("user_id IN (?)", []uint{source.ID, target.ID}).Delete(models.OAuthAccount{})
+		linkOAuthAccount(source, globals.GoogleOAuth, "google-aid-merge-source", "merge-source@gmail.com", "Source")
+		linkOAuthAccount(target, globals.GoogleOAuth, "google-aid-merge-target", "merge-target@gmail.com", "Target")
+		for _, user := range []models.User{source, target} {
+			as.CreateABookmarkOfAUser(fmt.Sprint(user.ID), models.Bookmark{Slug: "merge-conflict-slug", Host: "mockhost", Title: "mocktitle", Thumbnail: "mockthumb"})
+			Globs.GormDB.Create(&models.CategorySubscription{UserID: user.ID, CategoryID: "5edf118c3e631f0600198935"})
+		}
+		defer Globs.GormDB.Unscoped().Where("slug = ?", "merge-conflict-slug").Delete(models.Bookmark{})
+		Globs.GormDB.Create(&models.CategorySubscription{UserID: source.ID, CategoryID: "5edf118c3e631f0600198936"})
+		Globs.GormDB.Create(&models.DeviceToken{UserID: source.ID, Token: "device-token-merge-source", Platform: models.DeviceTokenPlatformIOS})
+		defer Globs.GormDB.Where("user_id IN (?)", []uint{source.ID, target.ID}).Delete(models.CategorySubscription{})
+		defer Globs.GormDB.Where("user_id IN (?)", []uint{source.ID, target.ID}).Delete(models.DeviceToken{})
+		readAt := time.Now().Truncate(time.Second)
+		Globs.GormDB.Create(&models.ReadingHistory{UserID: target.ID, Slug: "merge-read-both", ReadAt: readAt.Add(-time.Hour)})
+		Globs.GormDB.Create(&models.ReadingHistory{UserID: source.ID, Slug: "merge-read-both", ReadAt: readAt})
+		Globs.GormDB.Create(&models.ReadingHistory{UserID: source.ID, Slug: "merge-read-source", ReadAt: readAt})
+		defer Globs.GormDB.Where("user_id IN (?)", []uint{source.ID, target.ID}).Delete(models.ReadingHistory{})
+
+		resp := serveHTTP(http.MethodPost, fmt.Sprintf("/v1/admin/users/%d/merge", source.ID), fmt.Sprintf(`{"target_user_id":%d}`, target.ID), "application/json", adminAuth)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		assert.Empty(t, res.Data.MovedOAuthAccounts)
+		assert.Equal(t, []string{globals.GoogleOAuth}, res.Data.DroppedOAuthAccounts)
+		assert.Equal(t, 0, res.Data.MovedBookmarks)
+
+		// the account of the target user is kept
+		var accounts []models.OAuthAccount
+		Globs.GormDB.Unscoped().Where("user_id IN (?)", []uint{source.ID, target.ID}).Find(&accounts)
+		if assert.Len(t, accounts, 1) {
+			assert.Equal(t, target.ID, accounts[0].UserID)
+			assert.Equal(t, "google-aid-merge-target", accounts[0].AId.String)
+		}
+
+		_, total, _ := as.GetBookmarksOfAUser(fmt.Sprint(target.ID), 10, 0)
+		assert.Equal(t, 1, total)
+
+		// the device tokens and the categories not subscribed by the target user are moved
+		var categoryIDs []string
+		Globs.GormDB.Model(&models.CategorySubscription{}).Where("user_id IN (?)", []uint{source.ID, target.ID}).Order("category_id").Pluck("category_id", &categoryIDs)
+		assert.Equal(t, []string{"5edf118c3e631f0600198935", "5edf118c3e631f0600198936"}, categoryIDs)
+		var tokens int
+		Globs.GormDB.Model(&models.DeviceToken{}).Where("user_id = ?", target.ID).Count(&tokens)
+		assert.Equal(t, 1, tokens)
+
+		// the posts read by both users keep the later read time
+		var histories []models.ReadingHistory
+		Globs.GormDB.Where("user_id IN (?)", []uint{source.ID, target.ID}).Order("slug").Find(&histories)
+		if assert.Len(t, histories, 2) {
+			assert.Equal(t, "merge-read-both", histories[0].Slug)
+			assert.Equal(t, target.ID, histories[0].UserID)
+			assert.True(t, readAt.Equal(histories[0].ReadAt))
+			assert.Equal(t, "merge-read-source", histories[1].Slug)
+			assert.Equal(t, target.ID, histories[1].UserID)
+		}
+	})
+}
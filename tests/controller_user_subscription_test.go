@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"twreporter.org/go-api/configs/constants"
+	"twreporter.org/go-api/models"
+	"twreporter.org/go-api/storage"
+)
+
+type subscriptionResponse struct {
+	Status string              `json:"status"`
+	Data   models.Subscription `json:"data"`
+}
+
+func TestSubscriptions(t *testing.T) {
+	var res subscriptionResponse
+
+	as := storage.NewGormStorage(Globs.GormDB)
+
+	admin := createUser("subscription-admin@twreporter.org")
+	defer deleteUser(admin)
+	Globs.GormDB.Model(&admin).Update("privilege", constants.PrivilegeAdmin)
+	adminAuth := "Bearer " + generateIDToken(admin)
+
+	user := createUser("subscription-user@twreporter.org")
+	defer deleteUser(user)
+	defer Globs.GormDB.Unscoped().Where("user_id = ?", user.ID).Delete(models.Subscription{})
+	userAuth := "Bearer " + generateIDToken(user)
+
+	t.Run("Given a non-admin creating", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, "/v1/admin/subscriptions", fmt.Sprintf(`{"user_id":%d,"plan":"monthly"}`, user.ID), "application/json", userAuth)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Given an invalid subscription", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, "/v1/admin/subscriptions", fmt.Sprintf(`{"user_id":%d}`, user.ID), "application/json", adminAuth)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = serveHTTP(http.MethodPost, "/v1/admin/subscriptions", `{"user_id":999999,"plan":"monthly"}`, "application/json", adminAuth)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Given no subscription", func(t *testing.T) {
+		resp := serveHTTP(http.MethodGet, fmt.Sprintf("/v1/users/%d/subscription", user.ID), "", "", userAuth)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		subscribed, _ := as.IsUserSubscribed(fmt.Sprint(user.ID))
+		assert.False(t, subscribed)
+	})
+
+	t.Run("Given the lifecycle of a subscription", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPost, "/v1/admin/subscriptions", fmt.Sprintf(`{"user_id":%d,"plan":"monthly"}`, user.ID), "application/json", adminAuth)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		assert.Equal(t, "monthly", res.Data.Plan)
+		assert.Equal(t, models.SubscriptionStatusActive, res.Data.Status)
+		assert.False(t, res.Data.EndDate.Valid)
+		id := res.Data.ID
+
+		subscribed, _ := as.IsUserSubscribed(fmt.Sprint(user.ID))
+		assert.True(t, subscribed)
+
+		var read struct {
+			Data struct {
+				Subscription models.Subscription `json:"subscription"`
+				Active       bool                `json:"active"`
+			} `json:"data"`
+		}
+		resp = serveHTTP(http.MethodGet, fmt.Sprintf("/v1/users/%d/subscription", user.ID), "", "", userAuth)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &read)
+		assert.Equal(t, id, read.Data.Subscription.ID)
+		assert.True(t, read.Data.Active)
+
+		endDate := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+		resp = serveHTTP(http.MethodPatch, fmt.Sprintf("/v1/admin/subscriptions/%d", id), fmt.Sprintf(`{"plan":"yearly","end_date":"%s"}`, endDate.Format(time.RFC3339)), "application/json", adminAuth)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		assert.Equal(t, "yearly", res.Data.Plan)
+		assert.True(t, endDate.Equal(res.Data.EndDate.Time))
+
+		resp = serveHTTP(http.MethodPatch, fmt.Sprintf("/v1/admin/subscriptions/%d", id), `{"status":"active"}`, "application/json", adminAuth)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		// the subscription cannot end before it starts
+		resp = serveHTTP(http.MethodPatch, fmt.Sprintf("/v1/admin/subscriptions/%d", id), `{"end_date":"2000-01-01T00:00:00Z"}`, "application/json", adminAuth)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = serveHTTP(http.MethodDelete, fmt.Sprintf("/v1/admin/subscriptions/%d", id), "", "", adminAuth)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		assert.Equal(t, models.SubscriptionStatusCanceled, res.Data.Status)
+
+		subscribed, _ = as.IsUserSubscribed(fmt.Sprint(user.ID))
+		assert.False(t, subscribed)
+	})
+
+	t.Run("Given a subscription not found", func(t *testing.T) {
+		resp := serveHTTP(http.MethodPatch, "/v1/admin/subscriptions/999999", `{"plan":"yearly"}`, "application/json", adminAuth)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		resp = serveHTTP(http.MethodDelete, "/v1/admin/subscriptions/999999", "", "", adminAuth)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"twreporter.org/go-api/globals"
+)
+
+func serveInternalHTTP(method, path, body string) *httptest.ResponseRecorder {
+	req := requestWithBody(method, path, body)
+	req.Header.Add("Content-Type", "application/json")
+	req.SetBasicAuth(globals.Conf.App.InternalClientID, globals.Conf.App.InternalClientSecret)
+
+	resp := httptest.NewRecorder()
+	Globs.GinEngine.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestGetUsersByIDs(t *testing.T) {
+	defaultApp := globals.Conf.App
+	globals.Conf.App.InternalClientID = "test-client"
+	globals.Conf.App.InternalClientSecret = "test-client-secret"
+	defer func() {
+		globals.Conf.App = defaultApp
+	}()
+
+	user := getUser(Globs.Defaults.Account)
+	const missingID = 999999
+
+	t.Run("StatusCode=StatusOK,Mix of existing and missing ids", func(t *testing.T) {
+		var res struct {
+			Status string `json:"status"`
+			Data   struct {
+				Users map[string]map[string]interface{} `json:"users"`
+			} `json:"data"`
+		}
+
+		resp := serveInternalHTTP("POST", "/internal/users/batch", fmt.Sprintf(`{"ids":[%d,%d]}`, user.ID, missingID))
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		assert.Len(t, res.Data.Users, 1)
+		if u, ok := res.Data.Users[fmt.Sprint(user.ID)]; assert.True(t, ok) {
+			assert.NotContains(t, u, "email")
+		}
+		assert.NotContains(t, res.Data.Users, fmt.Sprint(missingID))
+	})
+
+	t.Run("StatusCode=StatusBadRequest,Too many ids", func(t *testing.T) {
+		ids := make([]string, 101)
+		for i := range ids {
+			ids[i] = fmt.Sprint(i + 1)
+		}
+
+		resp := serveInternalHTTP("POST", "/internal/users/batch", fmt.Sprintf(`{"ids":[%s]}`, strings.Join(ids, ",")))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("StatusCode=StatusUnauthorized,Invalid client credentials", func(t *testing.T) {
+		resp := serveHTTP("POST", "/internal/users/batch", fmt.Sprintf(`{"ids":[%d]}`, user.ID), "application/json", "Basic invalid")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+}
@@ -239,7 +239,7 @@ func serveHTTP(method, path, body, contentType, authorization string) (resp *htt
 	}
 
 	resp = httptest.NewRecorder()
-	Globs.GinEngine.ServeHTTP(resp, req)
+	routers.RewritePrefixes(Globs.GinEngine).ServeHTTP(resp, req)
 
 	return
 }
@@ -262,7 +262,7 @@ func serveHTTPWithCookies(method, path, body, contentType, authorization string,
 	}
 
 	resp = httptest.NewRecorder()
-	Globs.GinEngine.ServeHTTP(resp, req)
+	routers.RewritePrefixes(Globs.GinEngine).ServeHTTP(resp, req)
 
 	return
 }
@@ -278,7 +278,7 @@ func (s mockMailStrategy) Send(to, subject, body string) error {
 
 func setupGinServer(gormDB *gorm.DB, mgoDB *mgo.Session, client *mongodriver.Client) *gin.Engine {
 	mailSvc := mockMailStrategy{}
-	cf := controllers.NewControllerFactory(gormDB, mgoDB, mailSvc, client)
+	cf := controllers.NewControllerFactory(gormDB, mgoDB, mailSvc, client, nil)
 	engine := routers.SetupRouter(cf)
 	return engine
 }
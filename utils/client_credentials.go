@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"crypto/subtle"
+
+	"twreporter.org/go-api/globals"
+)
+
+// ValidateIntrospectionCredentials checks the credentials of the token introspection clients against the configured ones.
+// The credentials are always invalid if the client ID or secret is not configured.
+func ValidateIntrospectionCredentials(clientID, clientSecret string) bool {
+	appsettings := globals.Conf.App
+	return validateClientCredentials(clientID, clientSecret, appsettings.IntrospectionClientID, appsettings.IntrospectionClientSecret)
+}
+
+// ValidateInternalServiceCredentials checks the credentials of the internal services, e.g. the batch users lookup,
+// against the configured ones, which are not the credentials of the token introspection.
+// The credentials are always invalid if the client ID or secret is not configured.
+func ValidateInternalServiceCredentials(clientID, clientSecret string) bool {
+	appsettings := globals.Conf.App
+	return validateClientCredentials(clientID, clientSecret, appsettings.InternalClientID, appsettings.InternalClientSecret)
+}
+
+func validateClientCredentials(clientID, clientSecret, wantID, wantSecret string) bool {
+	if wantID == "" || wantSecret == "" {
+		return false
+	}
+
+	validID := subtle.ConstantTimeCompare([]byte(clientID), []byte(wantID)) == 1
+	validSecret := subtle.ConstantTimeCompare([]byte(clientSecret), []byte(wantSecret)) == 1
+	return validID && validSecret
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/go-redis/redis"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/mysql"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -48,6 +49,10 @@ func InitDB(attempts, retryMaxDelay int) (*gorm.DB, error) {
 
 	db.SetJoinTableHandler(&models.User{}, globals.TableBookmarks, &models.UsersBookmarks{})
 
+	// Set connection pool size
+	db.DB().SetMaxOpenConns(globals.Conf.DB.MySQL.MaxOpenConns)
+	db.DB().SetMaxIdleConns(globals.Conf.DB.MySQL.MaxIdleConns)
+
 	//db.LogMode(true)
 
 	return db, nil
@@ -70,6 +75,11 @@ func InitMongoDB() (*mgo.Session, error) {
 	// Set socket timeout to 3 mins
 	session.SetSocketTimeout(3 * time.Minute)
 
+	// Set maximum number of sockets per server
+	if poolLimit := globals.Conf.DB.Mongo.PoolLimit; poolLimit > 0 {
+		session.SetPoolLimit(poolLimit)
+	}
+
 	// As our mongo cluster comprises cost-effective solution(Replica set arbiter),
 	// use Nearest read concern(https://docs.mongodb.com/manual/core/read-preference/#nearest)
 	// to distribute the read load acorss primary and secondary node evenly.
@@ -96,6 +106,29 @@ func InitMongoDBV2() (*mongo.Client, error) {
 	return client, nil
 }
 
+// InitRedis initiates the Redis connection caching the news.
+// nil client is returned if Redis address is not configured.
+func InitRedis() (*redis.Client, error) {
+	var config = globals.Conf.DB.Redis
+
+	if config.Address == "" {
+		return nil, nil
+	}
+
+	log.Debug("connect to redis ", config.Address)
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Address,
+		Password: config.Password,
+		DB:       config.DB,
+	})
+
+	if err := client.Ping().Err(); err != nil {
+		client.Close()
+		return nil, errors.Wrap(err, "Connection to redis does not response:")
+	}
+	return client, nil
+}
+
 // Get the migrate instance for operating migration
 func GetMigrateInstance(dbInstance *sql.DB) (*migrate.Migrate, error) {
 	const migrateMysqlDriver = "mysql"
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"unicode"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+var diffOperations = map[diffmatchpatch.Operation]string{
+	diffmatchpatch.DiffEqual:  DiffEqual,
+	diffmatchpatch.DiffInsert: DiffInsert,
+	diffmatchpatch.DiffDelete: DiffDelete,
+}
+
+// DiffSegment is the text equal, inserted or deleted in the diff
+type DiffSegment struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// tokenizeWords splits the text into words, whitespaces and punctuations.
+// Each Han character is a word, since there is no space between the words of CJK text.
+func tokenizeWords(text string) []string {
+	var tokens []string
+	var word []rune
+
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r), unicode.IsPunct(r), unicode.IsSymbol(r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			if len(word) > 0 && !unicode.IsSpace(word[0]) {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			if len(word) > 0 && unicode.IsSpace(word[0]) {
+				flush()
+			}
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// tokenRune maps the index of the token to a valid rune, the surrogates are skipped
+func tokenRune(index int) rune {
+	r := rune(index + 1)
+	if r >= 0xD800 {
+		r += 0x800
+	}
+	return r
+}
+
+// WordDiff computes the word-level diff from one text to another.
+// The words are mapped to runes and diffed as characters, then mapped back to words.
+func WordDiff(from, to string) []DiffSegment {
+	var tokens []string
+	var indexes = make(map[string]rune)
+
+	encode := func(text string) []rune {
+		var runes []rune
+		for _, token := range tokenizeWords(text) {
+			r, ok := indexes[token]
+			if !ok {
+				r = tokenRune(len(tokens))
+				indexes[token] = r
+				tokens = append(tokens, token)
+			}
+			runes = append(runes, r)
+		}
+		return runes
+	}
+
+	fromRunes, toRunes := encode(from), encode(to)
+
+	var words = make(map[rune]string, len(tokens))
+	for token, r := range indexes {
+		words[r] = token
+	}
+
+	var segments = make([]DiffSegment, 0)
+	for _, diff := range diffmatchpatch.New().DiffMainRunes(fromRunes, toRunes, false) {
+		var text []byte
+		for _, r := range diff.Text {
+			text = append(text, words[r]...)
+		}
+		segments = append(segments, DiffSegment{Type: diffOperations[diff.Type], Text: string(text)})
+	}
+
+	return segments
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWordDiff(t *testing.T) {
+	cases := []struct {
+		name string
+		from string
+		to   string
+		want []DiffSegment
+	}{
+		{
+			name: "Given identical texts",
+			from: "the quick fox",
+			to:   "the quick fox",
+			want: []DiffSegment{{DiffEqual, "the quick fox"}},
+		},
+		{
+			name: "Given a replaced word",
+			from: "the quick fox jumps",
+			to:   "the slow fox jumps",
+			want: []DiffSegment{{DiffEqual, "the "}, {DiffDelete, "quick"}, {DiffInsert, "slow"}, {DiffEqual, " fox jumps"}},
+		},
+		{
+			name: "Given a word sharing the prefix",
+			from: "report",
+			to:   "reporter",
+			want: []DiffSegment{{DiffDelete, "report"}, {DiffInsert, "reporter"}},
+		},
+		{
+			name: "Given an inserted Han character",
+			from: "報導者",
+			to:   "報導記者",
+			want: []DiffSegment{{DiffEqual, "報導"}, {DiffInsert, "記"}, {DiffEqual, "者"}},
+		},
+		{
+			name: "Given an empty text",
+			from: "",
+			to:   "new text.",
+			want: []DiffSegment{{DiffInsert, "new text."}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := WordDiff(tc.from, tc.to); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expect %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTokenRune(t *testing.T) {
+	for _, index := range []int{0, 0xD7FE, 0xD7FF, 0xE000} {
+		if r := tokenRune(index); r >= 0xD800 && r <= 0xDFFF {
+			t.Errorf("expect index %d not to be mapped to a surrogate, but got %U", index, r)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const facebookSignedRequestAlgorithm = "HMAC-SHA256"
+
+// ErrSignedRequestInvalid is returned by ParseFacebookSignedRequest when the signed request is malformed or tampered
+var ErrSignedRequestInvalid = errors.New("signed request is invalid")
+
+// FacebookSignedRequest is the payload of the signed request sent by Facebook
+type FacebookSignedRequest struct {
+	Algorithm string `json:"algorithm"`
+	UserID    string `json:"user_id"`
+	IssuedAt  int64  `json:"issued_at"`
+	Expires   int64  `json:"expires"`
+}
+
+// ParseFacebookSignedRequest verifies the signed request by the app secret and decodes its payload.
+// The signed request is `<signature>.<payload>` encoded in base64url,
+// and the signature is the HMAC-SHA256 of the encoded payload.
+// See https://developers.facebook.com/docs/games/gamesonfacebook/login#parsingsr
+func ParseFacebookSignedRequest(signedRequest, secret string) (FacebookSignedRequest, error) {
+	var payload FacebookSignedRequest
+
+	parts := strings.SplitN(signedRequest, ".", 2)
+	if len(parts) != 2 || secret == "" {
+		return payload, errors.WithStack(ErrSignedRequestInvalid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[0], "="))
+	if err != nil {
+		return payload, errors.WithStack(ErrSignedRequestInvalid)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return payload, errors.WithStack(ErrSignedRequestInvalid)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return payload, errors.WithStack(ErrSignedRequestInvalid)
+	}
+
+	if err = json.Unmarshal(data, &payload); err != nil {
+		return payload, errors.WithStack(ErrSignedRequestInvalid)
+	}
+
+	if !strings.EqualFold(payload.Algorithm, facebookSignedRequestAlgorithm) || payload.UserID == "" {
+		return payload, errors.WithStack(ErrSignedRequestInvalid)
+	}
+
+	return payload, nil
+}
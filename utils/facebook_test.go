@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func signFacebookRequest(payload, secret string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) + "." + encoded
+}
+
+func TestParseFacebookSignedRequest(t *testing.T) {
+	const secret = "facebook-app-secret"
+	const payload = `{"algorithm":"HMAC-SHA256","expires":1291840400,"issued_at":1291836800,"user_id":"218471"}`
+
+	valid := signFacebookRequest(payload, secret)
+
+	t.Run("Given a valid signed request", func(t *testing.T) {
+		req, err := ParseFacebookSignedRequest(valid, secret)
+		if err != nil {
+			t.Fatalf("expect no error, but got %v", err)
+		}
+		if req.UserID != "218471" || req.IssuedAt != 1291836800 {
+			t.Errorf("expect user 218471 issued at 1291836800, but got %+v", req)
+		}
+	})
+
+	cases := []struct {
+		name          string
+		signedRequest string
+		secret        string
+	}{
+		{"Given a request signed by another secret", signFacebookRequest(payload, "another-secret"), secret},
+		{"Given a tampered payload", valid[:len(valid)-2] + "fQ", secret},
+		{"Given no signature", base64.RawURLEncoding.EncodeToString([]byte(payload)), secret},
+		{"Given an empty secret", signFacebookRequest(payload, ""), ""},
+		{"Given an unknown algorithm", signFacebookRequest(`{"algorithm":"none","user_id":"218471"}`, secret), secret},
+		{"Given no user id", signFacebookRequest(`{"algorithm":"HMAC-SHA256"}`, secret), secret},
+		{"Given a malformed payload", signFacebookRequest(`not json`, secret), secret},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseFacebookSignedRequest(tc.signedRequest, tc.secret); errors.Cause(err) != ErrSignedRequestInvalid {
+				t.Errorf("expect %v, but got %v", ErrSignedRequestInvalid, err)
+			}
+		})
+	}
+}
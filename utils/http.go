@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"twreporter.org/go-api/globals"
+)
+
+// outboundTransport sets the configured `User-Agent` on the outbound requests
+// and logs method/url/status/latency of them at debug level
+type outboundTransport struct {
+	base http.RoundTripper
+}
+
+func (t outboundTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper should not modify the request
+	if userAgent := globals.Conf.App.UserAgent; userAgent != "" {
+		header := make(http.Header, len(req.Header))
+		for k, v := range req.Header {
+			header[k] = v
+		}
+		header.Set("User-Agent", userAgent)
+
+		req = req.WithContext(req.Context())
+		req.Header = header
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+
+	if err != nil {
+		log.Debugf("outbound request %s %s fails after %v: %v", req.Method, req.URL.String(), latency, err)
+		return resp, err
+	}
+
+	log.Debugf("outbound request %s %s %d %v", req.Method, req.URL.String(), resp.StatusCode, latency)
+	return resp, nil
+}
+
+// NewOutboundTransport wraps the base RoundTripper with the configured `User-Agent` and debug logging.
+// http.DefaultTransport is used if base is nil.
+func NewOutboundTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return outboundTransport{base: base}
+}
+
+// NewHTTPClient returns the http client for the outbound requests
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: NewOutboundTransport(nil),
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"twreporter.org/go-api/globals"
+)
+
+func TestNewHTTPClientUserAgent(t *testing.T) {
+	const userAgent = "twreporter-go-api/test"
+
+	var receivedUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedUserAgent = r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	defaultUserAgent := globals.Conf.App.UserAgent
+	globals.Conf.App.UserAgent = userAgent
+	defer func() {
+		globals.Conf.App.UserAgent = defaultUserAgent
+	}()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := NewHTTPClient(time.Second).Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	resp.Body.Close()
+
+	if receivedUserAgent != userAgent {
+		t.Errorf("expected User-Agent %s, got %s", userAgent, receivedUserAgent)
+	}
+
+	if req.Header.Get("User-Agent") != "" {
+		t.Errorf("expected the original request not to be modified")
+	}
+}
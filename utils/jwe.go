@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/globals"
+)
+
+const (
+	jweAlgorithm   = "dir"
+	jweEncryption  = "A256GCM"
+	jweContentType = "JWT"
+	jweKeySize     = 32
+)
+
+// ErrTokenDecryption is returned by DecryptToken when the token is not encrypted by any of the encryption keys
+var ErrTokenDecryption = errors.New("token cannot be decrypted")
+
+type jweHeader struct {
+	Algorithm   string `json:"alg"`
+	Encryption  string `json:"enc"`
+	ContentType string `json:"cty"`
+	KeyID       string `json:"kid"`
+}
+
+// encryptionKey is a 256 bits AES key identified by its SHA-256 thumbprint
+type encryptionKey struct {
+	id  string
+	key []byte
+}
+
+func parseEncryptionKey(encoded string) (encryptionKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return encryptionKey{}, errors.Wrap(err, "jwt encryption key is not base64 encoded")
+	}
+	if len(key) != jweKeySize {
+		return encryptionKey{}, errors.New(fmt.Sprintf("jwt encryption key should be %d bytes, but got %d", jweKeySize, len(key)))
+	}
+
+	sum := sha256.Sum256(key)
+	return encryptionKey{id: base64.RawURLEncoding.EncodeToString(sum[:8]), key: key}, nil
+}
+
+// lookupEncryptionKey finds the key of kid among `app.jwt_encryption_key` and `app.jwt_previous_encryption_keys`
+func lookupEncryptionKey(kid string) (encryptionKey, bool) {
+	for _, encoded := range append([]string{globals.Conf.App.JwtEncryptionKey}, globals.Conf.App.JwtPreviousEncryptionKeys...) {
+		if encoded == "" {
+			continue
+		}
+		if key, err := parseEncryptionKey(encoded); err == nil && key.id == kid {
+			return key, true
+		}
+	}
+	return encryptionKey{}, false
+}
+
+// EncryptToken encrypts the signed token into the compact serialization of JWE(RFC 7516)
+// by AES-256-GCM with `app.jwt_encryption_key` directly, so the claims are opaque to the clients.
+// The token is returned as it is if the encryption key is not configured.
+func EncryptToken(tokenString string) (string, error) {
+	if globals.Conf.App.JwtEncryptionKey == "" {
+		return tokenString, nil
+	}
+
+	key, err := parseEncryptionKey(globals.Conf.App.JwtEncryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	header, _ := json.Marshal(jweHeader{
+		Algorithm:   jweAlgorithm,
+		Encryption:  jweEncryption,
+		ContentType: jweContentType,
+		KeyID:       key.id,
+	})
+	encodedHeader := base64.RawURLEncoding.EncodeToString(header)
+
+	gcm, err := newGCM(key.key)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(iv); err != nil {
+		return "", errors.Wrap(err, "fail to generate initialization vector of token")
+	}
+
+	// the encoded header is authenticated as the additional data
+	sealed := gcm.Seal(nil, iv, []byte(tokenString), []byte(encodedHeader))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	// the encrypted key is empty since the content is encrypted by the key directly
+	return strings.Join([]string{
+		encodedHeader,
+		"",
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecryptToken returns the signed token in the JWE encrypted by EncryptToken.
+// The current and the previous encryption keys are looked up by `kid` header, so that the tokens issued
+// before the key rotation are still decryptable.
+// The token not in JWE compact serialization, e.g. issued before the encryption is enabled, is returned as it is,
+// and its signature should be verified by ParseToken anyway.
+func DecryptToken(tokenString string) (string, error) {
+	var header jweHeader
+
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 5 {
+		return tokenString, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", errors.Wrap(ErrTokenDecryption, "header of token is not base64url encoded")
+	}
+	if err = json.Unmarshal(data, &header); err != nil {
+		return "", errors.Wrap(ErrTokenDecryption, "header of token is malformed")
+	}
+	if header.Algorithm != jweAlgorithm || header.Encryption != jweEncryption || parts[1] != "" {
+		return "", errors.Wrap(ErrTokenDecryption, fmt.Sprintf("token is encrypted by unexpected algorithm %s(%s)", header.Algorithm, header.Encryption))
+	}
+
+	key, ok := lookupEncryptionKey(header.KeyID)
+	if !ok {
+		return "", errors.Wrap(ErrTokenDecryption, fmt.Sprintf("encryption key %s is not found", header.KeyID))
+	}
+
+	var segments [3][]byte
+	for i, part := range parts[2:] {
+		if segments[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return "", errors.Wrap(ErrTokenDecryption, "token is not base64url encoded")
+		}
+	}
+	iv, ciphertext, tag := segments[0], segments[1], segments[2]
+
+	gcm, err := newGCM(key.key)
+	if err != nil {
+		return "", err
+	}
+	if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return "", errors.Wrap(ErrTokenDecryption, "initialization vector or authentication tag of token is malformed")
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return "", errors.Wrap(ErrTokenDecryption, "token is not authenticated")
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to create cipher of token")
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to create cipher of token")
+	}
+	return gcm, nil
+}
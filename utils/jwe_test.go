@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/globals"
+)
+
+func helperGenEncryptionKey(t *testing.T) string {
+	key := make([]byte, jweKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestEncryptToken(t *testing.T) {
+	defer helperResetTokenCache()
+
+	defaultApp := globals.Conf.App
+	globals.Conf.App.JwtSecret = testSecret
+	globals.Conf.App.JwtEncryptionKey = helperGenEncryptionKey(t)
+	defer func() {
+		globals.Conf.App = defaultApp
+	}()
+
+	token, err := RetrieveV2AccessToken(1, "user@twreporter.org", 60)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	t.Run("Given an encrypted token", func(t *testing.T) {
+		if parts := strings.Split(token, "."); len(parts) != 5 {
+			t.Fatalf("expected token in JWE compact serialization, got %s", token)
+		}
+		if strings.Contains(token, base64.RawURLEncoding.EncodeToString([]byte(`"email"`))) {
+			t.Errorf("expected claims to be opaque, got %s", token)
+		}
+		if _, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{}); err == nil {
+			t.Errorf("expected encrypted token not to be parsed as JWT")
+		}
+
+		decrypted, err := DecryptToken(token)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		claims, err := ParseToken(decrypted, jwt.MapClaims{}, testSecret)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if claims.(jwt.MapClaims)["email"] != "user@twreporter.org" {
+			t.Errorf("unexpected claims %v", claims)
+		}
+	})
+
+	t.Run("Given a tampered token", func(t *testing.T) {
+		parts := strings.Split(token, ".")
+		ciphertext, _ := base64.RawURLEncoding.DecodeString(parts[3])
+		ciphertext[0] ^= 0xff
+		parts[3] = base64.RawURLEncoding.EncodeToString(ciphertext)
+
+		if _, err := DecryptToken(strings.Join(parts, ".")); errors.Cause(err) != ErrTokenDecryption {
+			t.Errorf("expected ErrTokenDecryption, got %v", err)
+		}
+	})
+
+	t.Run("Given a token encrypted by the rotated key", func(t *testing.T) {
+		defer func(key string, previous []string) {
+			globals.Conf.App.JwtEncryptionKey = key
+			globals.Conf.App.JwtPreviousEncryptionKeys = previous
+		}(globals.Conf.App.JwtEncryptionKey, globals.Conf.App.JwtPreviousEncryptionKeys)
+
+		globals.Conf.App.JwtPreviousEncryptionKeys = []string{globals.Conf.App.JwtEncryptionKey}
+		globals.Conf.App.JwtEncryptionKey = helperGenEncryptionKey(t)
+		if _, err := DecryptToken(token); err != nil {
+			t.Errorf("expected token encrypted by the previous key to be decrypted, got error %v", err)
+		}
+
+		globals.Conf.App.JwtPreviousEncryptionKeys = nil
+		if _, err := DecryptToken(token); errors.Cause(err) != ErrTokenDecryption {
+			t.Errorf("expected ErrTokenDecryption after the previous key is removed, got %v", err)
+		}
+	})
+
+	t.Run("Given a signed token without encryption", func(t *testing.T) {
+		signed, _ := genToken(jwt.StandardClaims{Subject: AccessTokenSubject}, testSecret)
+		if got, err := DecryptToken(signed); err != nil || got != signed {
+			t.Errorf("expected signed token to be returned as it is, got %s, %v", got, err)
+		}
+	})
+
+	t.Run("Given no encryption key", func(t *testing.T) {
+		defer func(key string) {
+			globals.Conf.App.JwtEncryptionKey = key
+		}(globals.Conf.App.JwtEncryptionKey)
+		globals.Conf.App.JwtEncryptionKey = ""
+
+		signed, err := RetrieveV2AccessToken(1, "user@twreporter.org", 60)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if parts := strings.Split(signed, "."); len(parts) != 3 {
+			t.Errorf("expected signed token without encryption, got %s", signed)
+		}
+	})
+}
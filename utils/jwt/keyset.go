@@ -0,0 +1,202 @@
+// Package jwt manages the asymmetric keys signing JWT,
+// including key generation, rotation and JSON Web Key Set(RFC 7517) serialization.
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Algorithm is the JWS algorithm of the keys managed by the key set
+	Algorithm = "RS256"
+
+	keySize = 2048
+)
+
+// Key is a RSA private key identified by `kid`
+type Key struct {
+	ID         string
+	PrivateKey *rsa.PrivateKey
+	// ExpiresAt is set when the key is rotated,
+	// zero value means the key is still used to sign tokens
+	ExpiresAt time.Time
+}
+
+// newKey computes the key ID by the JWK thumbprint(RFC 7638) of the public key
+func newKey(pkey *rsa.PrivateKey) *Key {
+	thumbprint, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{
+		E:   encodeBigInt(big.NewInt(int64(pkey.PublicKey.E))),
+		Kty: "RSA",
+		N:   encodeBigInt(pkey.PublicKey.N),
+	})
+
+	sum := sha256.Sum256(thumbprint)
+
+	return &Key{
+		ID:         base64.RawURLEncoding.EncodeToString(sum[:]),
+		PrivateKey: pkey,
+	}
+}
+
+// GenerateKey generates a new RSA key
+func GenerateKey() (*Key, error) {
+	pkey, err := rsa.GenerateKey(rand.Reader, keySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to generate rsa key")
+	}
+	return newKey(pkey), nil
+}
+
+// ParseKey parses the PEM encoded PKCS1 or PKCS8 RSA private key
+func ParseKey(data []byte) (*Key, error) {
+	var block *pem.Block
+	if block, _ = pem.Decode(data); block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+
+	if pkey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return newKey(pkey), nil
+	}
+
+	parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to parse rsa private key")
+	}
+
+	pkey, ok := parsedKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not a rsa private key")
+	}
+	return newKey(pkey), nil
+}
+
+// KeySet stores the current signing key and the rotated keys
+// which are still used to verify the tokens issued before rotation
+type KeySet struct {
+	mu       sync.RWMutex
+	current  *Key
+	previous []*Key
+}
+
+// NewKeySet returns the key set signing tokens by the given key
+func NewKeySet(key *Key) *KeySet {
+	return &KeySet{current: key}
+}
+
+// Current returns the key signing tokens
+func (ks *KeySet) Current() *Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	return ks.current
+}
+
+// Rotate replaces the current key with the given one.
+// The replaced key is kept in the set for `overlap`, which should be the lifetime of tokens,
+// so that the tokens signed by it are still verifiable.
+func (ks *KeySet) Rotate(key *Key, overlap time.Duration) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	now := time.Now()
+
+	var previous []*Key
+	for _, k := range ks.previous {
+		if now.Before(k.ExpiresAt) {
+			previous = append(previous, k)
+		}
+	}
+
+	if ks.current != nil {
+		rotated := *ks.current
+		rotated.ExpiresAt = now.Add(overlap)
+		previous = append(previous, &rotated)
+	}
+
+	ks.current = key
+	ks.previous = previous
+}
+
+// keys returns the current key and the unexpired rotated keys
+func (ks *KeySet) keys() []*Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := time.Now()
+
+	var keys []*Key
+	if ks.current != nil {
+		keys = append(keys, ks.current)
+	}
+	for _, k := range ks.previous {
+		if now.Before(k.ExpiresAt) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Lookup returns the public key verifying tokens signed by the key of `kid`
+func (ks *KeySet) Lookup(kid string) (*rsa.PublicKey, bool) {
+	for _, k := range ks.keys() {
+		if k.ID == kid {
+			return &k.PrivateKey.PublicKey, true
+		}
+	}
+	return nil, false
+}
+
+// JSONWebKey is the RSA public key in JWK format
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JSONWebKeySet is the JWKS document
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS serializes the public keys of the set
+func (ks *KeySet) JWKS() JSONWebKeySet {
+	var set = JSONWebKeySet{Keys: make([]JSONWebKey, 0)}
+
+	if ks == nil {
+		return set
+	}
+
+	for _, k := range ks.keys() {
+		set.Keys = append(set.Keys, JSONWebKey{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: Algorithm,
+			Kid: k.ID,
+			N:   encodeBigInt(k.PrivateKey.PublicKey.N),
+			E:   encodeBigInt(big.NewInt(int64(k.PrivateKey.PublicKey.E))),
+		})
+	}
+	return set
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
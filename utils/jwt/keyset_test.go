@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func helperGenerateKey(t *testing.T) *Key {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return key
+}
+
+func TestKeySetRotate(t *testing.T) {
+	first := helperGenerateKey(t)
+	second := helperGenerateKey(t)
+	third := helperGenerateKey(t)
+
+	ks := NewKeySet(first)
+	ks.Rotate(second, time.Hour)
+
+	if ks.Current().ID != second.ID {
+		t.Errorf("expected current key %s, got %s", second.ID, ks.Current().ID)
+	}
+
+	// the rotated key is kept during the overlap
+	if _, ok := ks.Lookup(first.ID); !ok {
+		t.Errorf("expected rotated key %s to be kept", first.ID)
+	}
+
+	if got := len(ks.JWKS().Keys); got != 2 {
+		t.Errorf("expected %d keys in JWKS, got %d", 2, got)
+	}
+
+	// the rotated key is removed after the overlap
+	ks.Rotate(third, 0)
+	if _, ok := ks.Lookup(second.ID); ok {
+		t.Errorf("expected rotated key %s to be removed", second.ID)
+	}
+	if _, ok := ks.Lookup(first.ID); !ok {
+		t.Errorf("expected rotated key %s to be kept", first.ID)
+	}
+}
+
+func TestKeySetJWKS(t *testing.T) {
+	key := helperGenerateKey(t)
+
+	set := NewKeySet(key).JWKS()
+	if len(set.Keys) != 1 {
+		t.Fatalf("expected %d key in JWKS, got %d", 1, len(set.Keys))
+	}
+
+	jwk := set.Keys[0]
+	if jwk.Kty != "RSA" || jwk.Alg != Algorithm || jwk.Use != "sig" || jwk.Kid != key.ID {
+		t.Errorf("unexpected JWK %+v", jwk)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if new(big.Int).SetBytes(n).Cmp(key.PrivateKey.PublicKey.N) != 0 {
+		t.Errorf("expected modulus of JWK to be the one of public key")
+	}
+
+	if jwk.E != "AQAB" {
+		t.Errorf("expected exponent %s, got %s", "AQAB", jwk.E)
+	}
+
+	var nilSet *KeySet
+	if keys := nilSet.JWKS().Keys; keys == nil || len(keys) != 0 {
+		t.Errorf("expected empty keys of nil key set, got %v", keys)
+	}
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LogSampler samples the verbose logs of successful events, 1 in every `rate` logs is emitted.
+// The failures are never sampled out.
+type LogSampler struct {
+	rate  uint64
+	count uint64
+}
+
+// NewLogSampler returns the sampler emitting 1 in every `rate` logs,
+// all logs are emitted if rate is less than or equal to 1.
+func NewLogSampler(rate int) *LogSampler {
+	if rate < 1 {
+		rate = 1
+	}
+	return &LogSampler{rate: uint64(rate)}
+}
+
+// Sample reports whether the next log of successful event is emitted.
+// A nil sampler emits all logs.
+func (s *LogSampler) Sample() bool {
+	if s == nil || s.rate <= 1 {
+		return true
+	}
+	return (atomic.AddUint64(&s.count, 1)-1)%s.rate == 0
+}
+
+// Infof logs the error if it is not nil, otherwise logs the message only if it is sampled
+func (s *LogSampler) Infof(entry *log.Entry, err error, format string, args ...interface{}) {
+	if err != nil {
+		entry.Infof("%v", err)
+		return
+	}
+
+	if s.Sample() {
+		entry.Infof(format, args...)
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestLogSampler(t *testing.T) {
+	const total = 1000
+
+	for _, tc := range []struct {
+		rate int
+		want int
+	}{
+		{rate: 0, want: total},
+		{rate: 1, want: total},
+		{rate: 10, want: total / 10},
+		{rate: 3, want: total/3 + 1},
+	} {
+		logger, hook := test.NewNullLogger()
+		s := NewLogSampler(tc.rate)
+
+		for i := 0; i < total; i++ {
+			s.Infof(log.NewEntry(logger), nil, "succeeds")
+		}
+		if got := len(hook.AllEntries()); got != tc.want {
+			t.Errorf("rate %d: expect %d successful logs emitted, but got %d", tc.rate, tc.want, got)
+		}
+
+		hook.Reset()
+		for i := 0; i < total; i++ {
+			s.Infof(log.NewEntry(logger), errors.New("fails"), "succeeds")
+		}
+		if got := len(hook.AllEntries()); got != total {
+			t.Errorf("rate %d: expect all %d failures emitted, but got %d", tc.rate, total, got)
+		}
+		if hook.LastEntry().Message != "fails" {
+			t.Errorf("rate %d: expect the error logged, but got %s", tc.rate, hook.LastEntry().Message)
+		}
+	}
+
+	t.Run("Given a nil sampler", func(t *testing.T) {
+		var s *LogSampler
+		if !s.Sample() {
+			t.Error("expect nil sampler to emit all logs")
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"twreporter.org/go-api/globals"
+)
+
+func TestVerifySignedURL(t *testing.T) {
+	defaultSecret := globals.Conf.App.JwtSecret
+	globals.Conf.App.JwtSecret = testSecret
+	defer func() {
+		globals.Conf.App.JwtSecret = defaultSecret
+	}()
+
+	const path = "/v1/topics/mock-topic/attachments/report.pdf?lang=zh-tw"
+	valid := SignedURL(path, time.Now().Add(time.Hour))
+
+	cases := []struct {
+		name string
+		url  string
+		want error
+	}{
+		{
+			name: "Given a valid signed url",
+			url:  valid,
+			want: nil,
+		},
+		{
+			name: "Given an expired signed url",
+			url:  SignedURL(path, time.Now().Add(-time.Second)),
+			want: ErrSignatureExpired,
+		},
+		{
+			name: "Given a tampered path",
+			url:  strings.Replace(valid, "report.pdf", "secret.pdf", 1),
+			want: ErrSignatureInvalid,
+		},
+		{
+			name: "Given a tampered query",
+			url:  strings.Replace(valid, "lang=zh-tw", "lang=en", 1),
+			want: ErrSignatureInvalid,
+		},
+		{
+			name: "Given a tampered expiry",
+			url:  strings.Replace(SignedURL(path, time.Unix(1591632000, 0)), "expires=1591632000", "expires=4102444800", 1),
+			want: ErrSignatureInvalid,
+		},
+		{
+			name: "Given an unsigned url",
+			url:  path,
+			want: ErrSignatureInvalid,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, _ := url.Parse(tc.url)
+			if err := VerifySignedURL(u); errors.Cause(err) != tc.want {
+				t.Errorf("expected error %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Slugify generates the slug of the title.
+// The title is lowercased, its diacritics are removed, and the runs of spaces and punctuation are replaced by a hyphen.
+// Letters and digits of other scripts, such as Chinese characters, are kept as they are.
+func Slugify(title string) string {
+	var b strings.Builder
+	var hyphen bool
+
+	// NFD decomposes the accented letters into the base letters and the combining marks
+	for _, r := range norm.NFD.String(strings.ToLower(title)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// drop the combining marks, e.g. the acute accent of é
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if hyphen && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+	}
+
+	return norm.NFC.String(b.String())
+}
+
+// UniqueSlug appends `-2`, `-3`, etc. to the slug until it does not exist
+func UniqueSlug(slug string, exists func(string) bool) string {
+	candidate := slug
+	for i := 2; exists(candidate); i++ {
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+	return candidate
+}
@@ -0,0 +1,37 @@
+package utils
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Hello World":                 "hello-world",
+		"  Leading and trailing!  ":   "leading-and-trailing",
+		"Crème brûlée, à la carte":    "creme-brulee-a-la-carte",
+		"multiple---hyphens & spaces": "multiple-hyphens-spaces",
+		"2020 年度報導":                   "2020-年度報導",
+		"!!!":                         "",
+	}
+
+	for title, want := range cases {
+		if got := Slugify(title); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	existing := map[string]bool{
+		"hello-world":   true,
+		"hello-world-2": true,
+	}
+	exists := func(slug string) bool {
+		return existing[slug]
+	}
+
+	if got := UniqueSlug("new-slug", exists); got != "new-slug" {
+		t.Errorf("expect new-slug, but got %s", got)
+	}
+	if got := UniqueSlug("hello-world", exists); got != "hello-world-3" {
+		t.Errorf("expect hello-world-3, but got %s", got)
+	}
+}
@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"fmt"
+	"io/ioutil"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 
 	"twreporter.org/go-api/globals"
+	jwtkeys "twreporter.org/go-api/utils/jwt"
 )
 
 type AuthTokenType int
@@ -23,6 +27,95 @@ const (
 	AccessTokenSubject = "ACCESS_TOKEN"
 )
 
+// signingKeys signs the id/access tokens if `app.jwt_signing_method` is RS256.
+// It is nil if the tokens are signed by HS256.
+var signingKeys *jwtkeys.KeySet
+
+// InitSigningKeys loads the RSA private key from `app.jwt_private_key_path`
+// if `app.jwt_signing_method` is RS256.
+// The keys of `app.jwt_previous_private_key_paths` are rotated at startup, oldest first,
+// so that they verify the tokens signed before the rotation for the token lifetime.
+// A key is generated if the path is not provided, which is only allowed in development.
+func InitSigningKeys() error {
+	var err error
+	var key *jwtkeys.Key
+
+	if globals.Conf.App.JwtSigningMethod != jwtkeys.Algorithm {
+		signingKeys = nil
+		return nil
+	}
+
+	if path := globals.Conf.App.JwtPrivateKeyPath; path != "" {
+		if key, err = readSigningKey(path); err != nil {
+			return err
+		}
+	} else {
+		log.Warn("jwt private key is not provided, generate one for RS256 signing")
+		if key, err = jwtkeys.GenerateKey(); err != nil {
+			return err
+		}
+	}
+
+	var keys *jwtkeys.KeySet
+	overlap := time.Second * time.Duration(globals.Conf.App.JwtExpiration)
+	for _, path := range globals.Conf.App.JwtPreviousPrivateKeyPaths {
+		previous, err := readSigningKey(path)
+		if err != nil {
+			return err
+		}
+		if keys == nil {
+			keys = jwtkeys.NewKeySet(previous)
+		} else {
+			keys.Rotate(previous, overlap)
+		}
+	}
+
+	if keys == nil {
+		keys = jwtkeys.NewKeySet(key)
+	} else {
+		keys.Rotate(key, overlap)
+	}
+
+	signingKeys = keys
+	return nil
+}
+
+func readSigningKey(path string) (*jwtkeys.Key, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("fail to read jwt private key(path: %s)", path))
+	}
+	return jwtkeys.ParseKey(data)
+}
+
+// GetJWKS returns the public keys verifying the id/access tokens.
+// The key set is empty if the tokens are signed by HS256 since the secret could not be published.
+func GetJWKS() jwtkeys.JSONWebKeySet {
+	return signingKeys.JWKS()
+}
+
+// signingSecret is a HS256 secret identified by its configured key ID,
+// which is not derived from the secret so that the kid header reveals nothing about it
+type signingSecret struct {
+	id     string
+	secret []byte
+}
+
+// lookupSigningSecret finds the secret of kid, which is the given secret of `app.jwt_secret_id`
+// or one of `app.jwt_previous_secrets`
+func lookupSigningSecret(kid string, secret string) (signingSecret, bool) {
+	if kid == "" {
+		return signingSecret{}, false
+	}
+	if kid == globals.Conf.App.JwtSecretID {
+		return signingSecret{id: kid, secret: []byte(secret)}, true
+	}
+	if s, ok := globals.Conf.App.JwtPreviousSecrets[kid]; ok && s != "" {
+		return signingSecret{id: kid, secret: []byte(s)}, true
+	}
+	return signingSecret{}, false
+}
+
 // ReporterJWTClaims JWT claims we used
 type ReporterJWTClaims struct {
 	UserID uint   `json:"user_id"`
@@ -89,7 +182,7 @@ func RetrieveV2IDToken(userID uint, email, firstName, lastName string, expiratio
 			Subject:   IDTokenSubject,
 		},
 	}
-	return genToken(claims, globals.Conf.App.JwtSecret)
+	return genUserToken(claims)
 }
 
 func RetrieveV2AccessToken(userID uint, email string, expiration int) (string, error) {
@@ -104,7 +197,7 @@ func RetrieveV2AccessToken(userID uint, email string, expiration int) (string, e
 			Subject:   AccessTokenSubject,
 		},
 	}
-	return genToken(claims, globals.Conf.App.JwtSecret)
+	return genUserToken(claims)
 }
 
 // RetrieveMailServiceAccessToken generate JWT for mail service validation
@@ -121,6 +214,33 @@ func RetrieveMailServiceAccessToken(expiration int) (string, error) {
 	return genToken(claims, secret)
 }
 
+// genUserToken signs the id/access tokens by RS256 with `kid` header if the signing keys are initiated,
+// otherwise, by HS256 with the jwt secret, whose `app.jwt_secret_id` is the `kid` header if it is configured.
+// The signed tokens are then encrypted by EncryptToken.
+func genUserToken(claims jwt.Claims) (string, error) {
+	var err error
+	var token *jwt.Token
+	var tokenString string
+
+	if signingKeys == nil {
+		token = jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		if id := globals.Conf.App.JwtSecretID; id != "" {
+			token.Header["kid"] = id
+		}
+		tokenString, err = token.SignedString([]byte(globals.Conf.App.JwtSecret))
+	} else {
+		key := signingKeys.Current()
+		token = jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = key.ID
+		tokenString, err = token.SignedString(key.PrivateKey)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "internal server error: fail to generate token")
+	}
+
+	return EncryptToken(tokenString)
+}
+
 // genToken - generate jwt token according to user's info
 func genToken(claims jwt.Claims, secret string) (string, error) {
 	const errorWhere = "RetrieveToken"
@@ -0,0 +1,276 @@
+package utils
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+const (
+	// tokenCacheSize is the maximum number of validated tokens kept in the cache
+	tokenCacheSize = 1024
+	// tokenCacheTTL is the longest period a validated token is served from the cache,
+	// the period is also bounded by the `exp` claim of the token
+	tokenCacheTTL = 5 * time.Minute
+	// blockedUserTTL is the period the blocked user is recorded,
+	// which should not be shorter than the age the tokens are trusted without checking the user
+	blockedUserTTL = 5 * time.Minute
+)
+
+// ErrTokenRevoked is returned by ParseToken when the token is in the denylist
+var ErrTokenRevoked = errors.New("token is revoked")
+
+type tokenCacheEntry struct {
+	key       string
+	token     string
+	claims    jwt.Claims
+	expiresAt time.Time
+}
+
+// tokenCache is a LRU cache of validated token to its claims
+type tokenCache struct {
+	mu       sync.Mutex
+	size     int
+	ll       *list.List
+	elements map[string]*list.Element
+}
+
+func newTokenCache(size int) *tokenCache {
+	return &tokenCache{
+		size:     size,
+		ll:       list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+func (tc *tokenCache) get(key string) (jwt.Claims, bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	e, ok := tc.elements[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := e.Value.(*tokenCacheEntry)
+	if !jwt.TimeFunc().Before(entry.expiresAt) {
+		tc.removeElement(e)
+		return nil, false
+	}
+
+	tc.ll.MoveToFront(e)
+	return entry.claims, true
+}
+
+func (tc *tokenCache) add(key, token string, claims jwt.Claims, expiresAt time.Time) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if e, ok := tc.elements[key]; ok {
+		tc.removeElement(e)
+	}
+
+	tc.elements[key] = tc.ll.PushFront(&tokenCacheEntry{
+		key:       key,
+		token:     token,
+		claims:    claims,
+		expiresAt: expiresAt,
+	})
+
+	if tc.ll.Len() > tc.size {
+		tc.removeElement(tc.ll.Back())
+	}
+}
+
+// removeToken removes the entries of the token no matter which secret or claims type it was parsed with
+func (tc *tokenCache) removeToken(token string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	for e := tc.ll.Front(); e != nil; {
+		next := e.Next()
+		if e.Value.(*tokenCacheEntry).token == token {
+			tc.removeElement(e)
+		}
+		e = next
+	}
+}
+
+func (tc *tokenCache) removeElement(e *list.Element) {
+	tc.ll.Remove(e)
+	delete(tc.elements, e.Value.(*tokenCacheEntry).key)
+}
+
+// tokenDenylist stores the revoked tokens until they expire
+type tokenDenylist struct {
+	mu     sync.Mutex
+	tokens map[string]time.Time
+}
+
+func (td *tokenDenylist) add(token string, expiresAt time.Time) {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+
+	td.tokens[token] = expiresAt
+}
+
+func (td *tokenDenylist) has(token string) bool {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+
+	expiresAt, ok := td.tokens[token]
+	if !ok {
+		return false
+	}
+
+	// the token is expired and would be rejected by the validation anyway
+	if !expiresAt.IsZero() && !jwt.TimeFunc().Before(expiresAt) {
+		delete(td.tokens, token)
+		return false
+	}
+
+	return true
+}
+
+// userDenylist stores the users blocked recently along with the time they are blocked
+type userDenylist struct {
+	mu    sync.Mutex
+	users map[string]time.Time
+}
+
+func (ud *userDenylist) add(userID string) {
+	ud.mu.Lock()
+	defer ud.mu.Unlock()
+
+	ud.users[userID] = jwt.TimeFunc()
+}
+
+func (ud *userDenylist) has(userID string) bool {
+	ud.mu.Lock()
+	defer ud.mu.Unlock()
+
+	blockedAt, ok := ud.users[userID]
+	if !ok {
+		return false
+	}
+
+	// the tokens issued before the user is blocked are old enough to be checked anyway
+	if jwt.TimeFunc().Sub(blockedAt) >= blockedUserTTL {
+		delete(ud.users, userID)
+		return false
+	}
+
+	return true
+}
+
+var (
+	parsedTokens  = newTokenCache(tokenCacheSize)
+	revokedTokens = &tokenDenylist{tokens: make(map[string]time.Time)}
+	blockedUsers  = &userDenylist{users: make(map[string]time.Time)}
+)
+
+// getTokenExpiration reads `exp` claim of the token without verifying the signature.
+// Zero time is returned if `exp` claim is not provided.
+func getTokenExpiration(tokenString string) time.Time {
+	var claims = jwt.MapClaims{}
+
+	if _, _, err := new(jwt.Parser).ParseUnverified(tokenString, claims); err != nil {
+		return time.Time{}
+	}
+
+	if exp, ok := claims["exp"].(float64); ok {
+		return time.Unix(int64(exp), 0)
+	}
+	return time.Time{}
+}
+
+// ParseToken verifies the HS256 signed token by the secret, or the RS256 signed token by the signing keys,
+// and validates its claims.
+// The HS256 signed token with `kid` header is verified by the secret or one of `app.jwt_previous_secrets` of `kid`,
+// so the tokens signed before the secret is rotated are still accepted.
+// The validated claims are cached until the token expires(at most `tokenCacheTTL`),
+// so the same token seen repeatedly will not be verified again.
+// `claims` is used to decode the token, and the returned claims are of the same type.
+// Callers should not modify the returned claims since they are shared by the cache.
+func ParseToken(tokenString string, claims jwt.Claims, secret string) (jwt.Claims, error) {
+	var err error
+	var token *jwt.Token
+
+	if revokedTokens.has(tokenString) {
+		return nil, errors.WithStack(ErrTokenRevoked)
+	}
+
+	key := fmt.Sprintf("%s.%T.%s", secret, claims, tokenString)
+	if cached, ok := parsedTokens.get(key); ok {
+		return cached, nil
+	}
+
+	if token, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// RS256 signed tokens are verified by the public key of `kid` in the signing keys
+		if signingKeys != nil && token.Method == jwt.SigningMethodRS256 {
+			kid, _ := token.Header["kid"].(string)
+			if publicKey, ok := signingKeys.Lookup(kid); ok {
+				return publicKey, nil
+			}
+			return nil, errors.New(fmt.Sprintf("signing key %s is not found", kid))
+		}
+
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New(fmt.Sprintf("expected %s signing method but token specified %s", jwt.SigningMethodHS256.Alg(), token.Header["alg"]))
+		}
+
+		// the tokens signed before `kid` header is introduced are verified by the secret
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return []byte(secret), nil
+		}
+		if key, ok := lookupSigningSecret(kid, secret); ok {
+			return key.secret, nil
+		}
+		return nil, errors.New(fmt.Sprintf("signing secret %s is not found", kid))
+	}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if !token.Valid {
+		return nil, errors.New("token is invalid")
+	}
+
+	expiresAt := jwt.TimeFunc().Add(tokenCacheTTL)
+	if exp := getTokenExpiration(tokenString); !exp.IsZero() && exp.Before(expiresAt) {
+		expiresAt = exp
+	}
+	parsedTokens.add(key, tokenString, token.Claims, expiresAt)
+
+	return token.Claims, nil
+}
+
+// RevokeToken puts the token into the denylist, e.g. when the user logs out.
+// The revoked token is rejected by ParseToken even if it is cached.
+// The encrypted token is revoked by its signed token.
+// The denylist is local to the process and is lost on restart,
+// so the other instances keep accepting the token until it expires.
+func RevokeToken(tokenString string) {
+	if decrypted, err := DecryptToken(tokenString); err == nil {
+		tokenString = decrypted
+	}
+	revokedTokens.add(tokenString, getTokenExpiration(tokenString))
+	parsedTokens.removeToken(tokenString)
+}
+
+// MarkUserBlocked records the user is just blocked,
+// so the young tokens of the user, which are trusted without checking the user, are checked again.
+// The record is local to the process like the token denylist,
+// so the other instances keep trusting the young tokens until they are old enough to be checked.
+func MarkUserBlocked(userID string) {
+	blockedUsers.add(userID)
+}
+
+// IsUserRecentlyBlocked reports whether the user is marked by MarkUserBlocked in `blockedUserTTL`
+func IsUserRecentlyBlocked(userID string) bool {
+	return blockedUsers.has(userID)
+}
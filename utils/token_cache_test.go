@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const testSecret = "test-secret"
+
+func helperGenToken(t *testing.T, expiresAt time.Time) string {
+	token, err := genToken(jwt.MapClaims{
+		"user_id": 1,
+		"exp":     expiresAt.Unix(),
+	}, testSecret)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return token
+}
+
+func helperResetTokenCache() {
+	parsedTokens = newTokenCache(tokenCacheSize)
+	revokedTokens = &tokenDenylist{tokens: make(map[string]time.Time)}
+	jwt.TimeFunc = time.Now
+}
+
+func TestParseTokenCacheHit(t *testing.T) {
+	defer helperResetTokenCache()
+
+	token := helperGenToken(t, time.Now().Add(time.Hour))
+
+	first, err := ParseToken(token, jwt.MapClaims{}, testSecret)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	second, err := ParseToken(token, jwt.MapClaims{}, testSecret)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// the same map is returned if the claims are served from the cache
+	first.(jwt.MapClaims)["cached"] = true
+	if _, ok := second.(jwt.MapClaims)["cached"]; !ok {
+		t.Errorf("expected claims to be served from the cache")
+	}
+
+	if _, err = ParseToken(token, jwt.MapClaims{}, "another-secret"); err == nil {
+		t.Errorf("expected error when the token is parsed with another secret")
+	}
+}
+
+func TestParseTokenExpiryEviction(t *testing.T) {
+	defer helperResetTokenCache()
+
+	expiresAt := time.Now().Add(time.Minute)
+	token := helperGenToken(t, expiresAt)
+
+	if _, err := ParseToken(token, jwt.MapClaims{}, testSecret); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	jwt.TimeFunc = func() time.Time {
+		return expiresAt.Add(time.Second)
+	}
+
+	if _, err := ParseToken(token, jwt.MapClaims{}, testSecret); err == nil {
+		t.Errorf("expected error when the cached token is expired")
+	}
+
+	if parsedTokens.ll.Len() != 0 {
+		t.Errorf("expected the expired token to be evicted, got %d cached tokens", parsedTokens.ll.Len())
+	}
+}
+
+func TestParseTokenRevoked(t *testing.T) {
+	defer helperResetTokenCache()
+
+	token := helperGenToken(t, time.Now().Add(time.Hour))
+
+	if _, err := ParseToken(token, jwt.MapClaims{}, testSecret); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	RevokeToken(token)
+
+	if _, err := ParseToken(token, jwt.MapClaims{}, testSecret); err == nil {
+		t.Errorf("expected revoked token not to be served from the cache")
+	}
+}
+
+func TestTokenCacheEviction(t *testing.T) {
+	cache := newTokenCache(2)
+	expiresAt := time.Now().Add(time.Hour)
+
+	cache.add("a", "a", jwt.MapClaims{}, expiresAt)
+	cache.add("b", "b", jwt.MapClaims{}, expiresAt)
+	cache.get("a")
+	cache.add("c", "c", jwt.MapClaims{}, expiresAt)
+
+	if _, ok := cache.get("b"); ok {
+		t.Errorf("expected the least recently used token to be evicted")
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Errorf("expected the recently used token to be kept")
+	}
+}
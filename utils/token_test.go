@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"twreporter.org/go-api/globals"
+	jwtkeys "twreporter.org/go-api/utils/jwt"
+)
+
+func helperWriteSigningKey(t *testing.T, dir string, name string) (string, *jwtkeys.Key) {
+	pkey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(pkey)})
+	path := filepath.Join(dir, name)
+	if err = ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	key, err := jwtkeys.ParseKey(data)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return path, key
+}
+
+func TestRS256SigningKeys(t *testing.T) {
+	defer helperResetTokenCache()
+
+	dir, err := ioutil.TempDir("", "jwt-keys")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	firstPath, first := helperWriteSigningKey(t, dir, "first.pem")
+	secondPath, second := helperWriteSigningKey(t, dir, "second.pem")
+
+	defaultApp := globals.Conf.App
+	globals.Conf.App.JwtSigningMethod = jwtkeys.Algorithm
+	globals.Conf.App.JwtExpiration = 3600
+	globals.Conf.App.JwtPrivateKeyPath = firstPath
+	defer func() {
+		globals.Conf.App = defaultApp
+		signingKeys = nil
+	}()
+
+	if err := InitSigningKeys(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	token, err := RetrieveV2AccessToken(1, "user@twreporter.org", 60)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if _, err = ParseToken(token, jwt.MapClaims{}, testSecret); err != nil {
+		t.Errorf("expected RS256 token to be verified, got error %v", err)
+	}
+
+	// rotate the key at startup, the token signed by the previous key is still verifiable
+	globals.Conf.App.JwtPrivateKeyPath = secondPath
+	globals.Conf.App.JwtPreviousPrivateKeyPaths = []string{firstPath}
+	if err = InitSigningKeys(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	helperResetTokenCache()
+
+	if _, err = ParseToken(token, jwt.MapClaims{}, testSecret); err != nil {
+		t.Errorf("expected token signed by rotated key to be verified, got error %v", err)
+	}
+
+	if current := signingKeys.Current(); current.ID != second.ID {
+		t.Errorf("expected current key %s, got %s", second.ID, current.ID)
+	}
+
+	jwks := GetJWKS()
+	if got := len(jwks.Keys); got != 2 {
+		t.Fatalf("expected %d keys in JWKS, got %d", 2, got)
+	}
+	if jwks.Keys[0].Kid != second.ID || jwks.Keys[1].Kid != first.ID {
+		t.Errorf("expected kids %s and %s in JWKS, got %s and %s", second.ID, first.ID, jwks.Keys[0].Kid, jwks.Keys[1].Kid)
+	}
+
+	// the previous key could not be read
+	globals.Conf.App.JwtPreviousPrivateKeyPaths = []string{filepath.Join(dir, "missing.pem")}
+	if err = InitSigningKeys(); err == nil {
+		t.Error("expected error reading the missing previous key, got nil")
+	}
+}
+
+func TestHS256SigningSecrets(t *testing.T) {
+	defer helperResetTokenCache()
+
+	defaultApp := globals.Conf.App
+	globals.Conf.App.JwtSigningMethod = jwt.SigningMethodHS256.Alg()
+	globals.Conf.App.JwtSecret = testSecret
+	globals.Conf.App.JwtSecretID = "test-secret-id"
+	globals.Conf.App.JwtPreviousSecrets = nil
+	defer func() {
+		globals.Conf.App = defaultApp
+		signingKeys = nil
+	}()
+
+	if err := InitSigningKeys(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	token, err := RetrieveV2AccessToken(1, "user@twreporter.org", 60)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if kid := parsed.Header["kid"]; kid != "test-secret-id" {
+		t.Errorf("expected kid test-secret-id of the current secret, got %v", kid)
+	}
+
+	if _, err = ParseToken(token, jwt.MapClaims{}, testSecret); err != nil {
+		t.Errorf("expected token signed by the current secret to be verified, got error %v", err)
+	}
+
+	// rotate the secret, the token signed by the previous secret is still verifiable
+	const rotatedSecret = "rotated-test-secret"
+	globals.Conf.App.JwtSecret = rotatedSecret
+	globals.Conf.App.JwtSecretID = "rotated-secret-id"
+	globals.Conf.App.JwtPreviousSecrets = map[string]string{"test-secret-id": testSecret}
+	helperResetTokenCache()
+
+	rotatedToken, err := RetrieveV2AccessToken(1, "user@twreporter.org", 60)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err = ParseToken(rotatedToken, jwt.MapClaims{}, rotatedSecret); err != nil {
+		t.Errorf("expected token signed by the rotated secret to be verified, got error %v", err)
+	}
+	if _, err = ParseToken(token, jwt.MapClaims{}, rotatedSecret); err != nil {
+		t.Errorf("expected token signed by the previous secret to be verified, got error %v", err)
+	}
+
+	// the token signed by the previous secret is rejected after the overlap
+	globals.Conf.App.JwtPreviousSecrets = nil
+	helperResetTokenCache()
+
+	if _, err = ParseToken(token, jwt.MapClaims{}, rotatedSecret); err == nil {
+		t.Errorf("expected token signed by the removed secret to be rejected")
+	}
+
+	// the token without kid is verified by the current secret only
+	legacyToken, err := genToken(jwt.MapClaims{"user_id": 1}, rotatedSecret)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err = ParseToken(legacyToken, jwt.MapClaims{}, rotatedSecret); err != nil {
+		t.Errorf("expected token without kid to be verified, got error %v", err)
+	}
+}